@@ -23,6 +23,7 @@ import (
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/mutate/zstdchunked"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
@@ -32,7 +33,7 @@ import (
 func NewCmdAppend(options *[]crane.Option) *cobra.Command {
 	var baseRef, newTag, outFile string
 	var newLayers []string
-	var annotate, ociEmptyBase bool
+	var annotate, ociEmptyBase, zstdChunked bool
 
 	appendCmd := &cobra.Command{
 		Use:   "append",
@@ -43,7 +44,11 @@ provided tarballs.
 
 If the base image is a Windows base image (i.e., its config.OS is "windows"),
 the contents of the tarballs will be modified to be suitable for a Windows
-container image.`,
+container image.
+
+With --zstd-chunked, the new layers are compressed as zstd:chunked so that
+podman and CRI-O can pull them lazily. This requires a base image with OCI
+media types, e.g. one converted with "crane optimize --format zstd:chunked".`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			var base v1.Image
@@ -52,7 +57,7 @@ container image.`,
 			if baseRef == "" {
 				logs.Warn.Printf("base unspecified, using empty image")
 				base = empty.Image
-				if ociEmptyBase {
+				if ociEmptyBase || zstdChunked {
 					base = mutate.MediaType(base, types.OCIManifestSchema1)
 					base = mutate.ConfigMediaType(base, types.OCIConfigJSON)
 				}
@@ -67,6 +72,12 @@ container image.`,
 			if err != nil {
 				return fmt.Errorf("appending %v: %w", newLayers, err)
 			}
+			if zstdChunked {
+				img, err = appendZstdChunked(base, img)
+				if err != nil {
+					return fmt.Errorf("converting %v to zstd:chunked: %w", newLayers, err)
+				}
+			}
 
 			if baseRef != "" && annotate {
 				ref, err := name.ParseReference(baseRef)
@@ -114,9 +125,39 @@ container image.`,
 	appendCmd.Flags().StringVarP(&outFile, "output", "o", "", "Path to new tarball of resulting image")
 	appendCmd.Flags().BoolVar(&annotate, "set-base-image-annotations", false, "If true, annotate the resulting image as being based on the base image")
 	appendCmd.Flags().BoolVar(&ociEmptyBase, "oci-empty-base", false, "If true, empty base image will have OCI media types instead of Docker")
+	appendCmd.Flags().BoolVar(&zstdChunked, "zstd-chunked", false, "If true, compress the new layers as zstd:chunked so they can be lazily pulled")
 
 	appendCmd.MarkFlagsMutuallyExclusive("oci-empty-base", "base")
 	appendCmd.MarkFlagRequired("new_tag")
 	appendCmd.MarkFlagRequired("new_layer")
 	return appendCmd
 }
+
+// appendZstdChunked returns base with the layers that img appended to it
+// converted to zstd:chunked.
+func appendZstdChunked(base, img v1.Image) (v1.Image, error) {
+	mt, err := base.MediaType()
+	if err != nil {
+		return nil, err
+	}
+	if mt != types.OCIManifestSchema1 {
+		return nil, fmt.Errorf("base image has media type %s, but zstd layers require %s", mt, types.OCIManifestSchema1)
+	}
+	old, err := base.Layers()
+	if err != nil {
+		return nil, err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	var adds []v1.Layer
+	for _, l := range layers[len(old):] {
+		l, err := zstdchunked.Layer(l)
+		if err != nil {
+			return nil, err
+		}
+		adds = append(adds, l)
+	}
+	return mutate.AppendLayers(base, adds...)
+}
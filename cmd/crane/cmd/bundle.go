@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdBundle creates a new cobra.Command for the bundle subcommand.
+func NewCmdBundle(options *[]crane.Option) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Save many images to one file and push them elsewhere, e.g. across an air gap",
+		Long: `Save many images to one file and push them elsewhere, e.g. across an air gap.
+
+A bundle is an OCI image layout in a tar archive, holding each blob once and
+the reference each image was saved from.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Usage()
+		},
+	}
+	cmd.AddCommand(newCmdBundleCreate(options), newCmdBundlePush(options))
+	return cmd
+}
+
+func newCmdBundleCreate(options *[]crane.Option) *cobra.Command {
+	var refsFile string
+	cmd := &cobra.Command{
+		Use:   "create BUNDLE [IMAGE...]",
+		Short: "Save images and indexes to a bundle",
+		Long: `Save images and indexes to a bundle.
+
+Images are read from the arguments and from the --refs file, which lists one
+reference per line; blank lines and lines starting with "#" are ignored.
+Indexes are saved whole unless --platform is set.`,
+		Example: `  crane bundle create --refs images.txt images.bundle`,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			refs := args[1:]
+			if refsFile != "" {
+				more, err := readRefs(refsFile)
+				if err != nil {
+					return err
+				}
+				refs = append(refs, more...)
+			}
+			if len(refs) == 0 {
+				return errors.New("no images to bundle, pass some as arguments or with --refs")
+			}
+			return crane.SaveBundle(refs, args[0], *options...)
+		},
+	}
+	cmd.Flags().StringVar(&refsFile, "refs", "", "File listing the images to bundle, one per line, or - for stdin")
+	return cmd
+}
+
+func newCmdBundlePush(options *[]crane.Option) *cobra.Command {
+	return &cobra.Command{
+		Use:   "push BUNDLE DST",
+		Short: "Push the images in a bundle to another registry",
+		Long: `Push the images in a bundle to another registry.
+
+DST is a registry, optionally followed by a repository prefix. Each image keeps
+the repository and tag or digest it was saved from, so with a DST of
+registry.example.com/mirror, gcr.io/project/app:v1 is pushed to
+registry.example.com/mirror/project/app:v1.`,
+		Example: `  crane bundle push images.bundle registry.example.com`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return crane.PushBundle(args[0], args[1], *options...)
+		},
+	}
+}
+
+// readRefs returns the references listed in path, or stdin if it's "-".
+func readRefs(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var refs []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		refs = append(refs, line)
+	}
+	return refs, s.Err()
+}
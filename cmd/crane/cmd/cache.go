@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdCache creates a new cobra.Command for the cache subcommand.
+func NewCmdCache() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and prune a local layer cache",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Usage()
+		},
+	}
+	cmd.AddCommand(newCmdCacheList(), newCmdCachePrune())
+	return cmd
+}
+
+func cacheEntries(path string) ([]cache.Entry, error) {
+	i, ok := cache.NewFilesystemCache(path).(cache.Inspector)
+	if !ok {
+		return nil, errors.New("cache can't be inspected")
+	}
+	entries, err := i.Entries()
+	if err != nil {
+		return nil, err
+	}
+	// Least recently used first.
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+func newCmdCacheList() *cobra.Command {
+	return &cobra.Command{
+		Use:     "ls PATH",
+		Short:   "List the entries in a layer cache, least recently used first",
+		Example: "crane cache ls ~/.cache/crane",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := cacheEntries(args[0])
+			if err != nil {
+				return err
+			}
+			var total int64
+			for _, e := range entries {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%d\t%s\n", e.Digest, e.Size, e.LastUsed.Format(time.RFC3339))
+				total += e.Size
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "%d entries, %d bytes\n", len(entries), total)
+			return nil
+		},
+	}
+}
+
+func newCmdCachePrune() *cobra.Command {
+	var (
+		maxSize   int64
+		olderThan time.Duration
+		dryRun    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "prune PATH",
+		Short: "Remove entries from a layer cache",
+		Long: `Remove entries from a layer cache that were last used more than --older-than ago,
+then the least recently used entries until the cache is no larger than --max-size.`,
+		Example: "crane cache prune --max-size=10000000000 --older-than=168h ~/.cache/crane",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if maxSize <= 0 && olderThan <= 0 {
+				return errors.New("at least one of --max-size or --older-than is required")
+			}
+			path := args[0]
+			entries, err := cacheEntries(path)
+			if err != nil {
+				return err
+			}
+			var total int64
+			for _, e := range entries {
+				total += e.Size
+			}
+
+			c := cache.NewFilesystemCache(path)
+			for _, e := range entries {
+				stale := olderThan > 0 && time.Since(e.LastUsed) > olderThan
+				over := maxSize > 0 && total > maxSize
+				if !stale && !over {
+					// Entries are sorted, so the rest are newer.
+					break
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "pruning: %s\n", e.Digest)
+				if !dryRun {
+					if err := c.Delete(e.Digest); err != nil && !errors.Is(err, cache.ErrNotFound) {
+						return err
+					}
+				}
+				total -= e.Size
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Int64Var(&maxSize, "max-size", 0, "Prune the least recently used entries until the cache is at most this many bytes")
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "Prune entries last used longer ago than this")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the entries that would be pruned without removing them")
+	return cmd
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/lazy"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdCat creates a new cobra.Command for the cat subcommand.
+func NewCmdCat(options *[]crane.Option) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cat IMAGE PATH",
+		Short: "Print a file from an image's filesystem",
+		Long: `Print a file from an image's filesystem.
+
+If every layer is eStargz, only the parts of the layers needed to find and
+read the file are fetched. Otherwise, the layers are read until the file is
+found, as with crane export.`,
+		Example: `  # Print the OS release of an image
+  crane cat ubuntu /etc/os-release`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, file := args[0], strings.TrimPrefix(path.Clean("/"+args[1]), "/")
+			o := crane.GetOptions(*options...)
+			ref, err := name.ParseReference(src, o.Name...)
+			if err != nil {
+				return fmt.Errorf("parsing reference %q: %w", src, err)
+			}
+
+			lz, err := lazy.Remote(cmd.Context(), ref, o.Remote...)
+			if err == nil {
+				f, err := lz.Open(file)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				_, err = io.Copy(cmd.OutOrStdout(), f)
+				return err
+			}
+			if !errors.Is(err, lazy.ErrNotEstargz) {
+				return fmt.Errorf("reading %s: %w", src, err)
+			}
+
+			logs.Progress.Printf("%s isn't eStargz, reading its layers", src)
+			img, err := crane.Pull(src, *options...)
+			if err != nil {
+				return fmt.Errorf("pulling %s: %w", src, err)
+			}
+			return catExtracted(cmd.OutOrStdout(), img, file)
+		},
+	}
+}
+
+// catExtracted copies file from the flattened filesystem of img to w.
+func catExtracted(w io.Writer, img v1.Image, file string) error {
+	rc := mutate.Extract(img)
+	defer rc.Close()
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return &fs.PathError{Op: "open", Path: file, Err: fs.ErrNotExist}
+		}
+		if err != nil {
+			return err
+		}
+		if strings.TrimPrefix(path.Clean("/"+hdr.Name), "/") != file {
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			_, err := io.Copy(w, tr)
+			return err
+		case tar.TypeSymlink, tar.TypeLink:
+			return fmt.Errorf("%s is a link to %s", file, hdr.Linkname)
+		default:
+			return fmt.Errorf("%s is not a regular file", file)
+		}
+	}
+}
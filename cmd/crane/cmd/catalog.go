@@ -15,62 +15,53 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"io"
 	"path"
+	"regexp"
+	"slices"
 
 	"github.com/google/go-containerregistry/pkg/crane"
-	"github.com/google/go-containerregistry/pkg/name"
-	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/spf13/cobra"
 )
 
 // NewCmdCatalog creates a new cobra.Command for the catalog subcommand.
 func NewCmdCatalog(options *[]crane.Option, _ ...string) *cobra.Command {
 	var fullRef bool
+	var prefix, filter string
 	cmd := &cobra.Command{
 		Use:   "catalog REGISTRY",
 		Short: "List the repos in a registry",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			o := crane.GetOptions(*options...)
+			opts := append(slices.Clip(*options), crane.WithRepoPrefix(prefix))
+			if filter != "" {
+				re, err := regexp.Compile(filter)
+				if err != nil {
+					return fmt.Errorf("parsing --filter: %w", err)
+				}
+				opts = append(opts, crane.WithRepoFilter(re))
+			}
 
-			return catalog(cmd.Context(), cmd.OutOrStdout(), args[0], fullRef, o)
+			return catalog(cmd.OutOrStdout(), args[0], fullRef, opts)
 		},
 	}
 	cmd.Flags().BoolVar(&fullRef, "full-ref", false, "(Optional) if true, print the full image reference")
+	cmd.Flags().StringVar(&prefix, "prefix", "", "(Optional) only list the repos whose names start with this")
+	cmd.Flags().StringVar(&filter, "filter", "", "(Optional) only list the repos whose names match this regular expression")
 
 	return cmd
 }
 
-func catalog(ctx context.Context, w io.Writer, src string, fullRef bool, o crane.Options) error {
-	reg, err := name.NewRegistry(src, o.Name...)
-	if err != nil {
-		return fmt.Errorf("parsing reg %q: %w", src, err)
-	}
-
-	puller, err := remote.NewPuller(o.Remote...)
-	if err != nil {
-		return err
-	}
-
-	catalogger, err := puller.Catalogger(ctx, reg)
-	if err != nil {
-		return fmt.Errorf("reading tags for %s: %w", reg, err)
-	}
-
-	for catalogger.HasNext() {
-		repos, err := catalogger.Next(ctx)
+func catalog(w io.Writer, src string, fullRef bool, opts []crane.Option) error {
+	for repo, err := range crane.CatalogIterator(src, opts...) {
 		if err != nil {
-			return err
+			return fmt.Errorf("reading catalog for %s: %w", src, err)
 		}
-		for _, repo := range repos.Repos {
-			if fullRef {
-				fmt.Fprintln(w, path.Join(src, repo))
-			} else {
-				fmt.Fprintln(w, repo)
-			}
+		if fullRef {
+			fmt.Fprintln(w, path.Join(src, repo))
+		} else {
+			fmt.Fprintln(w, repo)
 		}
 	}
 	return nil
@@ -15,9 +15,14 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"os"
 	"runtime"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/spf13/cobra"
 )
 
@@ -25,14 +30,46 @@ import (
 func NewCmdCopy(options *[]crane.Option) *cobra.Command {
 	allTags := false
 	noclobber := false
+	progress := false
+	recompress := false
+	checkpoint := ""
+	resume := false
 	jobs := runtime.GOMAXPROCS(0)
 	cmd := &cobra.Command{
 		Use:     "copy SRC DST",
 		Aliases: []string{"cp"},
 		Short:   "Efficiently copy a remote image from src to dst while retaining the digest value",
 		Args:    cobra.ExactArgs(2),
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			opts := append(*options, crane.WithJobs(jobs), crane.WithNoClobber(noclobber))
+			if recompress {
+				opts = append(opts, crane.WithRecompression())
+			}
+			if resume && checkpoint == "" {
+				return errors.New("--resume requires --checkpoint")
+			}
+			if checkpoint != "" {
+				if !allTags {
+					return errors.New("--checkpoint requires --all-tags")
+				}
+				if !resume {
+					if err := os.Remove(checkpoint); err != nil && !errors.Is(err, os.ErrNotExist) {
+						return err
+					}
+				}
+				opts = append(opts, crane.WithCheckpoint(checkpoint))
+			}
+			if progress {
+				var last time.Time
+				opts = append(opts, crane.WithProgress(func(u v1.Update) {
+					// Print at most once a second, and when done.
+					if time.Since(last) < time.Second && u.Complete != u.Total {
+						return
+					}
+					last = time.Now()
+					fmt.Fprintf(cmd.ErrOrStderr(), "copied %d of %d bytes\n", u.Complete, u.Total)
+				}))
+			}
 			src, dst := args[0], args[1]
 			if allTags {
 				return crane.CopyRepository(src, dst, opts...)
@@ -44,6 +81,10 @@ func NewCmdCopy(options *[]crane.Option) *cobra.Command {
 
 	cmd.Flags().BoolVarP(&allTags, "all-tags", "a", false, "(Optional) if true, copy all tags from SRC to DST")
 	cmd.Flags().BoolVarP(&noclobber, "no-clobber", "n", false, "(Optional) if true, avoid overwriting existing tags in DST")
+	cmd.Flags().BoolVar(&progress, "progress", false, "(Optional) if true, print how many bytes have been copied to stderr as the copy goes")
+	cmd.Flags().BoolVar(&recompress, "allow-recompression", false, "(Optional) if true, allow copying from sources whose layers have to be compressed on the way, e.g. tarball:// files from docker save, which changes their digests")
+	cmd.Flags().StringVar(&checkpoint, "checkpoint", "", "(Optional) with --all-tags, the path of a file to record copied tags in, so that an interrupted copy can be continued with --resume")
+	cmd.Flags().BoolVar(&resume, "resume", false, "(Optional) if true, skip the tags that --checkpoint records as copied, if they haven't changed in SRC, rather than starting over")
 	cmd.Flags().IntVarP(&jobs, "jobs", "j", 0, "(Optional) The maximum number of concurrent copies, defaults to GOMAXPROCS")
 
 	return cmd
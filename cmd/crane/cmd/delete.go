@@ -21,13 +21,19 @@ import (
 
 // NewCmdDelete creates a new cobra.Command for the delete subcommand.
 func NewCmdDelete(options *[]crane.Option) *cobra.Command {
-	return &cobra.Command{
+	var pruneReferrers bool
+	cmd := &cobra.Command{
 		Use:   "delete IMAGE",
 		Short: "Delete an image reference from its registry",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(_ *cobra.Command, args []string) error {
 			ref := args[0]
+			if pruneReferrers {
+				return crane.DeleteAll(ref, *options...)
+			}
 			return crane.Delete(ref, *options...)
 		},
 	}
+	cmd.Flags().BoolVar(&pruneReferrers, "prune-referrers", false, "(Optional) if true, first delete every artifact that refers to IMAGE, e.g. its signatures and attestations")
+	return cmd
 }
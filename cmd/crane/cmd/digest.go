@@ -47,7 +47,7 @@ func NewCmdDigest(options *[]crane.Option) *cobra.Command {
 				return err
 			}
 			if fullRef {
-				ref, err := name.ParseReference(args[0])
+				ref, err := name.ParseWithPlatform(args[0])
 				if err != nil {
 					return err
 				}
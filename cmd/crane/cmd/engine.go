@@ -0,0 +1,220 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/docker/cli/cli/connhelper"
+	"github.com/google/go-containerregistry/internal/dockercontext"
+	"github.com/google/go-containerregistry/internal/podman"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// defaultDockerSocket is where the docker daemon listens by default.
+const defaultDockerSocket = "unix:///var/run/docker.sock"
+
+// engine talks to the image endpoints of a Docker-compatible engine API.
+//
+// We don't use pkg/v1/daemon, because the docker client would roughly double
+// the size of crane; saving and loading tarballs is all we need.
+type engine struct {
+	client *http.Client
+}
+
+// newEngine returns an engine for the docker daemon, as configured by
+// $DOCKER_HOST or the current docker CLI context, or for Podman if podman is
+// set.
+func newEngine(usePodman bool) (*engine, error) {
+	host, err := engineHost(usePodman)
+	if err != nil {
+		return nil, err
+	}
+
+	var dial func(ctx context.Context, network, addr string) (net.Conn, error)
+	if sock, ok := strings.CutPrefix(host, "unix://"); ok {
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", sock)
+		}
+	} else if helper, err := connhelper.GetConnectionHelper(host); err != nil {
+		return nil, err
+	} else if helper != nil {
+		// This runs `docker system dial-stdio` over ssh.
+		dial = helper.Dialer
+	} else {
+		return nil, fmt.Errorf("unsupported engine host %q: only unix:// and ssh:// are supported", host)
+	}
+	return &engine{
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: dial,
+			},
+		},
+	}, nil
+}
+
+func engineHost(usePodman bool) (string, error) {
+	if usePodman {
+		return podman.Host()
+	}
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		return host, nil
+	}
+	if name := dockercontext.Name(); name != dockercontext.Default {
+		return dockercontext.Host(name)
+	}
+	return defaultDockerSocket, nil
+}
+
+func (e *engine) do(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	// The host is ignored, since we always dial the socket.
+	req, err := http.NewRequestWithContext(ctx, method, "http://engine"+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-tar")
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil || msg.Message == "" {
+			return nil, fmt.Errorf("%s %s: unexpected status %s", method, path, resp.Status)
+		}
+		return nil, fmt.Errorf("%s %s: %s", method, path, msg.Message)
+	}
+	return resp, nil
+}
+
+// image returns ref from the engine. It's saved into a temporary file the
+// first time its contents are read, so that it's only saved once however
+// many of its layers are read.
+func (e *engine) image(ctx context.Context, ref name.Reference) (v1.Image, error) {
+	var (
+		once  sync.Once
+		saved *io.SectionReader
+		err   error
+	)
+	opener := func() (io.ReadCloser, error) {
+		once.Do(func() {
+			saved, err = e.save(ctx, ref)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(io.NewSectionReader(saved, 0, saved.Size())), nil
+	}
+	return tarball.Image(opener, nil)
+}
+
+// save saves ref from the engine into a temporary file.
+func (e *engine) save(ctx context.Context, ref name.Reference) (*io.SectionReader, error) {
+	resp, err := e.do(ctx, http.MethodGet, "/images/get?"+url.Values{"names": {ref.Name()}}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	f, err := os.CreateTemp("", "crane-engine-*.tar")
+	if err != nil {
+		return nil, err
+	}
+	// Unlink the file right away where we can, so nothing is left behind;
+	// elsewhere (i.e. on Windows) it's left to the temp directory's cleanup.
+	os.Remove(f.Name())
+	n, err := io.Copy(f, resp.Body)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("saving %s: %w", ref, err)
+	}
+	return io.NewSectionReader(f, 0, n), nil
+}
+
+// engineSource returns a crane.Opener for images in the engine, so that
+// daemon://REF and podman://REF work wherever crane.Open's schemes do.
+func engineSource(usePodman bool, opt ...name.Option) crane.Opener {
+	return func(ctx context.Context, src string) (partial.WithRawManifest, error) {
+		return daemonImage(ctx, src, usePodman, opt...)
+	}
+}
+
+// daemonImage returns the image src from the docker daemon, or from Podman if
+// usePodman is set.
+func daemonImage(ctx context.Context, src string, usePodman bool, opt ...name.Option) (v1.Image, error) {
+	ref, err := name.ParseReference(src, opt...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing reference %q: %w", src, err)
+	}
+	e, err := newEngine(usePodman)
+	if err != nil {
+		return nil, err
+	}
+	return e.image(ctx, ref)
+}
+
+// load loads images into the engine under the given tags.
+func (e *engine) load(ctx context.Context, images map[name.Tag]v1.Image) error {
+	refs := make(map[name.Reference]v1.Image, len(images))
+	for tag, img := range images {
+		refs[tag] = img
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(tarball.MultiRefWrite(refs, pw))
+	}()
+	resp, err := e.do(ctx, http.MethodPost, "/images/load?quiet=1", pr)
+	if err != nil {
+		pr.CloseWithError(err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Errors during the load are reported in the JSON message stream.
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&msg); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+	}
+}
@@ -19,16 +19,22 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/crane"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/spf13/cobra"
 )
 
 // NewCmdExport creates a new cobra.Command for the export subcommand.
 func NewCmdExport(options *[]crane.Option) *cobra.Command {
-	return &cobra.Command{
+	var include, exclude []string
+	var numericOwner bool
+	var mtime string
+	cmd := &cobra.Command{
 		Use:   "export IMAGE|- TARBALL|-",
 		Short: "Export filesystem of a container image as a tarball",
 		Example: `  # Write tarball to stdout
@@ -38,9 +44,24 @@ func NewCmdExport(options *[]crane.Option) *cobra.Command {
   crane export ubuntu ubuntu.tar
 
   # Read image from stdin
-  crane export - ubuntu.tar`,
+  crane export - ubuntu.tar
+
+  # Write only /etc, without timestamps or user names that vary between builds
+  crane export ubuntu etc.tar --include etc --numeric-owner --mtime 1970-01-01T00:00:00Z`,
 		Args: cobra.RangeArgs(1, 2),
 		RunE: func(_ *cobra.Command, args []string) error {
+			opts := []crane.Option{crane.WithInclude(include...), crane.WithExclude(exclude...)}
+			if numericOwner {
+				opts = append(opts, crane.WithNumericOwner())
+			}
+			if mtime != "" {
+				t, err := time.Parse(time.RFC3339, mtime)
+				if err != nil {
+					return fmt.Errorf("parsing --mtime: %w", err)
+				}
+				opts = append(opts, crane.WithModTime(t))
+			}
+
 			src, dst := args[0], "-"
 			if len(args) > 1 {
 				dst = args[1]
@@ -69,6 +90,11 @@ func NewCmdExport(options *[]crane.Option) *cobra.Command {
 				if err != nil {
 					return fmt.Errorf("reading tarball from stdin: %w", err)
 				}
+			} else if strings.Contains(src, "://") {
+				img, err = crane.OpenImage(src, *options...)
+				if err != nil {
+					return fmt.Errorf("opening %s: %w", src, err)
+				}
 			} else {
 				desc, err := crane.Get(src, *options...)
 				if err != nil {
@@ -87,9 +113,18 @@ func NewCmdExport(options *[]crane.Option) *cobra.Command {
 				}
 			}
 
-			return crane.Export(img, f)
+			if c := crane.GetOptions(*options...).Cache; c != nil {
+				img = cache.Image(img, c)
+			}
+
+			return crane.Export(img, f, opts...)
 		},
 	}
+	cmd.Flags().StringSliceVar(&include, "include", nil, "Only export files that match these patterns, and everything in directories that do")
+	cmd.Flags().StringSliceVar(&exclude, "exclude", nil, "Don't export files that match these patterns, or anything in directories that do")
+	cmd.Flags().BoolVar(&numericOwner, "numeric-owner", false, "Leave user and group names out of the tarball, keeping only uids and gids")
+	cmd.Flags().StringVar(&mtime, "mtime", "", "Set the modification time of every file to this RFC 3339 time, e.g. 1970-01-01T00:00:00Z")
+	return cmd
 }
 
 func openFile(s string) (*os.File, error) {
@@ -24,17 +24,23 @@ import (
 	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/mutate/zstdchunked"
 	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/stream"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/spf13/cobra"
 )
 
 // NewCmdFlatten creates a new cobra.Command for the flatten subcommand.
 func NewCmdFlatten(options *[]crane.Option) *cobra.Command {
-	var dst string
+	var (
+		dst         string
+		zstdChunked bool
+	)
 
 	flattenCmd := &cobra.Command{
 		Use:   "flatten",
@@ -66,7 +72,7 @@ func NewCmdFlatten(options *[]crane.Option) *cobra.Command {
 			}
 			repo := newRef.Context()
 
-			flat, err := flatten(ref, repo, cmd.Parent().Use, o)
+			flat, err := flatten(ref, repo, cmd.Parent().Use, zstdChunked, o)
 			if err != nil {
 				log.Fatalf("flattening %s: %v", ref, err)
 			}
@@ -87,27 +93,35 @@ func NewCmdFlatten(options *[]crane.Option) *cobra.Command {
 		},
 	}
 	flattenCmd.Flags().StringVarP(&dst, "tag", "t", "", "New tag to apply to flattened image. If not provided, push by digest to the original image repository.")
+	flattenCmd.Flags().BoolVar(&zstdChunked, "zstd-chunked", false, "If true, compress the flattened layer as zstd:chunked, with OCI media types, so it can be lazily pulled")
 	return flattenCmd
 }
 
-func flatten(ref name.Reference, repo name.Repository, use string, o crane.Options) (partial.Describable, error) {
+func flatten(ref name.Reference, repo name.Repository, use string, zstdChunked bool, o crane.Options) (partial.Describable, error) {
 	desc, err := remote.Get(ref, o.Remote...)
 	if err != nil {
 		return nil, fmt.Errorf("pulling %s: %w", ref, err)
 	}
 
-	if desc.MediaType.IsIndex() {
+	// Unless --platform is set, flatten every image of an index.
+	if desc.MediaType.IsIndex() && o.Platform == nil {
 		idx, err := desc.ImageIndex()
 		if err != nil {
 			return nil, err
 		}
-		return flattenIndex(idx, repo, use, o)
-	} else if desc.MediaType.IsImage() {
+		if o.Cache != nil {
+			idx = cache.ImageIndex(idx, o.Cache)
+		}
+		return flattenIndex(idx, repo, use, zstdChunked, o)
+	} else if desc.MediaType.IsImage() || desc.MediaType.IsIndex() {
 		img, err := desc.Image()
 		if err != nil {
 			return nil, err
 		}
-		return flattenImage(img, repo, use, o)
+		if o.Cache != nil {
+			img = cache.Image(img, o.Cache)
+		}
+		return flattenImage(img, repo, use, zstdChunked, o)
 	}
 
 	return nil, fmt.Errorf("can't flatten %s", desc.MediaType)
@@ -123,7 +137,7 @@ func push(flat partial.Describable, ref name.Reference, o crane.Options) error {
 	return fmt.Errorf("can't push %T", flat)
 }
 
-func flattenIndex(old v1.ImageIndex, repo name.Repository, use string, o crane.Options) (partial.Describable, error) {
+func flattenIndex(old v1.ImageIndex, repo name.Repository, use string, zstdChunked bool, o crane.Options) (partial.Describable, error) {
 	m, err := old.IndexManifest()
 	if err != nil {
 		return nil, err
@@ -151,7 +165,7 @@ func flattenIndex(old v1.ImageIndex, repo name.Repository, use string, o crane.O
 			}
 		}
 
-		flattened, err := flattenChild(m, repo, use, o)
+		flattened, err := flattenChild(m, repo, use, zstdChunked, o)
 		if err != nil {
 			return nil, err
 		}
@@ -163,6 +177,12 @@ func flattenIndex(old v1.ImageIndex, repo name.Repository, use string, o crane.O
 		if err != nil {
 			return nil, err
 		}
+		if zstdChunked {
+			desc.MediaType, err = flattened.MediaType()
+			if err != nil {
+				return nil, err
+			}
+		}
 		adds = append(adds, mutate.IndexAddendum{
 			Add:        flattened,
 			Descriptor: *desc,
@@ -181,23 +201,26 @@ func flattenIndex(old v1.ImageIndex, repo name.Repository, use string, o crane.O
 	if err != nil {
 		return nil, err
 	}
+	if zstdChunked {
+		mt = types.OCIImageIndex
+	}
 	idx = mutate.IndexMediaType(idx, mt)
 
 	return idx, nil
 }
 
-func flattenChild(old partial.Describable, repo name.Repository, use string, o crane.Options) (partial.Describable, error) {
+func flattenChild(old partial.Describable, repo name.Repository, use string, zstdChunked bool, o crane.Options) (partial.Describable, error) {
 	if idx, ok := old.(v1.ImageIndex); ok {
-		return flattenIndex(idx, repo, use, o)
+		return flattenIndex(idx, repo, use, zstdChunked, o)
 	} else if img, ok := old.(v1.Image); ok {
-		return flattenImage(img, repo, use, o)
+		return flattenImage(img, repo, use, zstdChunked, o)
 	}
 
 	logs.Warn.Printf("can't flatten %T, skipping", old)
 	return old, nil
 }
 
-func flattenImage(old v1.Image, repo name.Repository, use string, o crane.Options) (partial.Describable, error) {
+func flattenImage(old v1.Image, repo name.Repository, use string, zstdChunked bool, o crane.Options) (partial.Describable, error) {
 	digest, err := old.Digest()
 	if err != nil {
 		return nil, fmt.Errorf("getting old digest: %w", err)
@@ -227,8 +250,20 @@ func flattenImage(old v1.Image, repo name.Repository, use string, o crane.Option
 		return nil, fmt.Errorf("mutating config: %w", err)
 	}
 
-	// TODO: Make compression configurable?
-	layer := stream.NewLayer(mutate.Extract(old), stream.WithCompressionLevel(gzip.BestCompression))
+	var layer v1.Layer
+	if zstdChunked {
+		// Docker manifests can't refer to zstd layers.
+		img = mutate.ConfigMediaType(mutate.MediaType(img, types.OCIManifestSchema1), types.OCIConfigJSON)
+		rc := mutate.Extract(old)
+		defer rc.Close()
+		layer, err = zstdchunked.NewLayer(rc)
+		if err != nil {
+			return nil, fmt.Errorf("compressing layer: %w", err)
+		}
+	} else {
+		// TODO: Make compression configurable?
+		layer = stream.NewLayer(mutate.Extract(old), stream.WithCompressionLevel(gzip.BestCompression))
+	}
 	if err := remote.WriteLayer(repo, layer, o.Remote...); err != nil {
 		return nil, fmt.Errorf("uploading layer: %w", err)
 	}
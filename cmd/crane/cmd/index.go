@@ -41,7 +41,7 @@ func NewCmdIndex(options *[]crane.Option) *cobra.Command {
 			cmd.Usage()
 		},
 	}
-	cmd.AddCommand(NewCmdIndexFilter(options), NewCmdIndexAppend(options))
+	cmd.AddCommand(NewCmdIndexFilter(options), NewCmdIndexAppend(options), NewCmdIndexAssemble(options))
 	return cmd
 }
 
@@ -279,6 +279,52 @@ The platform for appended manifests is inferred from the config file or omitted
 	return cmd
 }
 
+// NewCmdIndexAssemble creates a new cobra.Command for the index assemble subcommand.
+func NewCmdIndexAssemble(options *[]crane.Option) *cobra.Command {
+	var newTag string
+	var images, annotations map[string]string
+
+	cmd := &cobra.Command{
+		Use:   "assemble",
+		Short: "Assemble a multi-platform index from per-platform images.",
+		Long: `This sub-command pushes an index of per-platform images, and the images themselves.
+
+Each image is given as PLATFORM=SOURCE, where SOURCE is an OCI image layout directory,
+a tarball as written by "crane pull" or "docker save", or a reference. Indexes are
+resolved to their image for PLATFORM, which must agree with the image's config.`,
+		Example: `  # Assemble the outputs of a cross-compiling build
+  crane index assemble -t example.com/app:v1 \
+    -i linux/amd64=out/amd64.tar \
+    -i linux/arm64/v8=out/arm64 \
+    -i windows/amd64:10.0.17763.6189=example.com/app:v1-windows \
+    -a org.opencontainers.image.revision=$(git rev-parse HEAD)`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			o := crane.GetOptions(*options...)
+			ref, err := name.ParseReference(newTag, o.Name...)
+			if err != nil {
+				return fmt.Errorf("parsing reference %s: %w", newTag, err)
+			}
+			if err := validateKeyVals(annotations); err != nil {
+				return err
+			}
+			digest, err := crane.AssembleIndex(newTag, images, append(*options, crane.WithIndexAnnotations(annotations))...)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), ref.Context().Digest(digest))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&newTag, "tag", "t", "", "Tag to apply to resulting index")
+	cmd.Flags().StringToStringVarP(&images, "image", "i", nil, "Images to assemble, as PLATFORM=SOURCE")
+	cmd.Flags().StringToStringVarP(&annotations, "annotation", "a", nil, "Annotations to set on the index")
+	cmd.MarkFlagRequired("tag")
+	cmd.MarkFlagRequired("image")
+
+	return cmd
+}
+
 func filterIndex(idx v1.ImageIndex, platforms []v1.Platform) v1.ImageIndex {
 	matcher := not(satisfiesPlatforms(platforms))
 	return mutate.RemoveManifests(idx, matcher)
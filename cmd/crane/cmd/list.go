@@ -15,66 +15,70 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"io"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
-	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/spf13/cobra"
 )
 
 // NewCmdList creates a new cobra.Command for the ls subcommand.
 func NewCmdList(options *[]crane.Option) *cobra.Command {
 	var fullRef, omitDigestTags bool
+	var pageSize, limit int
+	var last, filter string
 	cmd := &cobra.Command{
 		Use:   "ls REPO",
 		Short: "List the tags in a repo",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			o := crane.GetOptions(*options...)
+			opts := append(slices.Clip(*options), crane.WithLast(last), crane.WithLimit(limit))
+			if cmd.Flags().Changed("page-size") {
+				opts = append(opts, crane.WithPageSize(pageSize))
+			}
+			if filter != "" {
+				re, err := regexp.Compile(filter)
+				if err != nil {
+					return fmt.Errorf("parsing --filter: %w", err)
+				}
+				opts = append(opts, crane.WithTagFilter(re))
+			}
 
-			return list(cmd.Context(), cmd.OutOrStdout(), args[0], fullRef, omitDigestTags, o)
+			return list(cmd.OutOrStdout(), args[0], fullRef, omitDigestTags, opts)
 		},
 	}
 	cmd.Flags().BoolVar(&fullRef, "full-ref", false, "(Optional) if true, print the full image reference")
 	cmd.Flags().BoolVarP(&omitDigestTags, "omit-digest-tags", "O", false, "(Optional), if true, omit digest tags (e.g., ':sha256-...')")
+	cmd.Flags().IntVar(&pageSize, "page-size", 0, "(Optional) how many tags to ask the registry for at a time, 1000 by default")
+	cmd.Flags().StringVar(&last, "last", "", "(Optional) only list the tags that sort after this one")
+	cmd.Flags().IntVar(&limit, "limit", 0, "(Optional) stop after listing this many tags")
+	cmd.Flags().StringVar(&filter, "filter", "", "(Optional) only list the tags that match this regular expression")
 	return cmd
 }
 
-func list(ctx context.Context, w io.Writer, src string, fullRef, omitDigestTags bool, o crane.Options) error {
+func list(w io.Writer, src string, fullRef, omitDigestTags bool, opts []crane.Option) error {
+	o := crane.GetOptions(opts...)
 	repo, err := name.NewRepository(src, o.Name...)
 	if err != nil {
 		return fmt.Errorf("parsing repo %q: %w", src, err)
 	}
 
-	puller, err := remote.NewPuller(o.Remote...)
-	if err != nil {
-		return err
-	}
-
-	lister, err := puller.Lister(ctx, repo)
-	if err != nil {
-		return fmt.Errorf("reading tags for %s: %w", repo, err)
-	}
-
-	for lister.HasNext() {
-		tags, err := lister.Next(ctx)
+	for tag, err := range crane.IterTags(src, opts...) {
 		if err != nil {
-			return err
+			return fmt.Errorf("reading tags for %s: %w", repo, err)
+		}
+		if omitDigestTags && strings.HasPrefix(tag, "sha256-") {
+			continue
 		}
-		for _, tag := range tags.Tags {
-			if omitDigestTags && strings.HasPrefix(tag, "sha256-") {
-				continue
-			}
 
-			if fullRef {
-				fmt.Fprintln(w, repo.Tag(tag))
-			} else {
-				fmt.Fprintln(w, tag)
-			}
+		if fullRef {
+			fmt.Fprintln(w, repo.Tag(tag))
+		} else {
+			fmt.Fprintln(w, tag)
 		}
 	}
 	return nil
@@ -21,8 +21,6 @@ import (
 
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
-	v1 "github.com/google/go-containerregistry/pkg/v1"
-	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/spf13/cobra"
 )
 
@@ -73,84 +71,48 @@ func NewCmdMutate(options *[]crane.Option) *cobra.Command {
 					return fmt.Errorf("appending %v: %w", newLayers, err)
 				}
 			}
-			cfg, err := img.ConfigFile()
-			if err != nil {
-				return err
-			}
-			cfg = cfg.DeepCopy()
-
-			// Set labels.
-			if cfg.Config.Labels == nil {
-				cfg.Config.Labels = map[string]string{}
-			}
-
 			if err := validateKeyVals(labels); err != nil {
 				return err
 			}
-
-			for k, v := range labels {
-				cfg.Config.Labels[k] = v
-			}
-
 			if err := validateKeyVals(annotations); err != nil {
 				return err
 			}
-
-			// set envvars if specified
-			if err := setEnvVars(cfg, envVars); err != nil {
-				return err
+			edits := []crane.ConfigEdit{
+				crane.SetLabels(labels),
+				crane.SetAnnotations(annotations),
+			}
+			for _, e := range envVars.values {
+				edits = append(edits, crane.SetEnv(e.key, e.value))
 			}
-
-			// Set entrypoint.
 			if len(entrypoint) > 0 {
-				cfg.Config.Entrypoint = entrypoint
-				cfg.Config.Cmd = nil // This matches Docker's behavior.
+				edits = append(edits, crane.SetEntrypoint(entrypoint...))
 			}
-
-			// Set cmd.
 			if len(cmd) > 0 {
-				cfg.Config.Cmd = cmd
+				edits = append(edits, crane.SetCmd(cmd...))
 			}
-
-			// Set user.
 			if len(user) > 0 {
-				cfg.Config.User = user
+				edits = append(edits, crane.SetUser(user))
 			}
-
-			// Set workdir.
 			if len(workdir) > 0 {
-				cfg.Config.WorkingDir = workdir
+				edits = append(edits, crane.SetWorkdir(workdir))
 			}
-
-			// Set ports
 			if len(ports) > 0 {
-				portMap := make(map[string]struct{})
-				for _, port := range ports {
-					portMap[port] = struct{}{}
-				}
-				cfg.Config.ExposedPorts = portMap
+				edits = append(edits, crane.SetExposedPorts(ports...))
 			}
-
-			// Set platform
 			if len(newPlatform) > 0 {
 				platform, err := parsePlatform(newPlatform)
 				if err != nil {
 					return err
 				}
-				cfg.OS = platform.OS
-				cfg.Architecture = platform.Architecture
-				cfg.Variant = platform.Variant
-				cfg.OSVersion = platform.OSVersion
+				edits = append(edits, crane.SetPlatform(platform))
 			}
 
 			// Mutate and write image.
-			img, err = mutate.ConfigFile(img, cfg)
+			img, err = crane.MutateImage(img, edits...)
 			if err != nil {
-				return fmt.Errorf("mutating config: %w", err)
+				return err
 			}
 
-			img = mutate.Annotations(img, annotations).(v1.Image)
-
 			// If the new ref isn't provided, write over the original image.
 			// If that ref was provided by digest (e.g., output from
 			// another crane command), then strip that and push the
@@ -211,51 +173,6 @@ func validateKeyVals(kvPairs map[string]string) error {
 	return nil
 }
 
-// setEnvVars override envvars in a config
-func setEnvVars(cfg *v1.ConfigFile, envVars keyToValue) error {
-	eMap := envVars.Map()
-	newEnv := make([]string, 0, len(cfg.Config.Env))
-	isWindows := cfg.OS == "windows"
-
-	// Keep the old values.
-	for _, old := range cfg.Config.Env {
-		oldKey, _, ok := strings.Cut(old, "=")
-		if !ok {
-			return fmt.Errorf("invalid key value pair in config: %s", old)
-		}
-
-		if v, ok := eMap[oldKey]; ok {
-			// Override in place to keep ordering of original env.
-			newEnv = append(newEnv, oldKey+"="+v)
-
-			// Remove this from eMap so we don't add it twice.
-			delete(eMap, oldKey)
-		} else {
-			newEnv = append(newEnv, old)
-		}
-	}
-
-	// Append the new values.
-	for _, e := range envVars.values {
-		k, v := e.key, e.value
-
-		if _, ok := eMap[k]; !ok {
-			// If we come across a value not in eMap, it means we replaced the
-			// old env in-place and deleted it from eMap, so we can skip adding.
-			continue
-		}
-
-		if isWindows {
-			k = strings.ToUpper(k)
-		}
-
-		newEnv = append(newEnv, fmt.Sprintf("%s=%s", k, v))
-	}
-
-	cfg.Config.Env = newEnv
-	return nil
-}
-
 type env struct {
 	key   string
 	value string
@@ -264,7 +181,6 @@ type env struct {
 type keyToValue struct {
 	values  []env
 	changed bool
-	mapped  map[string]string
 }
 
 func (o *keyToValue) Set(val string) error {
@@ -275,11 +191,9 @@ func (o *keyToValue) Set(val string) error {
 
 	if !o.changed {
 		o.values = []env{}
-		o.mapped = map[string]string{}
 	}
 
 	o.values = append(o.values, env{before, after})
-	o.mapped[before] = after
 	o.changed = true
 
 	return nil
@@ -296,7 +210,3 @@ func (o *keyToValue) String() string {
 	}
 	return strings.Join(ss, ",")
 }
-
-func (o *keyToValue) Map() map[string]string {
-	return o.mapped
-}
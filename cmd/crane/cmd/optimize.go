@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/logs"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate/estargz"
+	"github.com/google/go-containerregistry/pkg/v1/mutate/zstdchunked"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdOptimize creates a new cobra.Command for the optimize subcommand.
+func NewCmdOptimize(options *[]crane.Option) *cobra.Command {
+	var (
+		prioritize []string
+		profile    string
+		chunkSize  int
+		level      int
+		format     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "optimize SRC DST",
+		Short: "Convert an image's layers to eStargz or zstd:chunked so it can be lazily pulled",
+		Example: `  # Prefetch the files the entrypoint reads, listed one per line in startup.txt
+  crane optimize ubuntu registry.example.com/ubuntu:estargz --profile startup.txt
+
+  # Convert to zstd:chunked, for podman and CRI-O
+  crane optimize ubuntu registry.example.com/ubuntu:zstd --format zstd:chunked`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, dst := args[0], args[1]
+
+			var convert func(v1.Image) (v1.Image, error)
+			switch format {
+			case "estargz":
+				opts := []estargz.Option{estargz.WithPrioritizedFiles(prioritize...)}
+				if profile != "" {
+					f, err := os.Open(profile)
+					if err != nil {
+						return err
+					}
+					defer f.Close()
+					files, err := estargz.ReadProfile(f)
+					if err != nil {
+						return err
+					}
+					opts = append(opts, estargz.WithPrioritizedFiles(files...))
+				}
+				if chunkSize > 0 {
+					opts = append(opts, estargz.WithChunkSize(chunkSize))
+				}
+				if cmd.Flags().Changed("compression-level") {
+					opts = append(opts, estargz.WithCompressionLevel(level))
+				}
+				convert = func(img v1.Image) (v1.Image, error) {
+					return estargz.Image(img, opts...)
+				}
+			case "zstd:chunked":
+				for _, f := range []string{"prioritize", "profile", "chunk-size"} {
+					if cmd.Flags().Changed(f) {
+						return fmt.Errorf("--%s is only supported with --format estargz", f)
+					}
+				}
+				var opts []zstdchunked.Option
+				if cmd.Flags().Changed("compression-level") {
+					opts = append(opts, zstdchunked.WithCompressionLevel(level))
+				}
+				convert = func(img v1.Image) (v1.Image, error) {
+					return zstdchunked.Image(img, opts...)
+				}
+			default:
+				return fmt.Errorf("unsupported --format %q, must be estargz or zstd:chunked", format)
+			}
+
+			img, err := crane.Pull(src, *options...)
+			if err != nil {
+				return fmt.Errorf("pulling %s: %w", src, err)
+			}
+			logs.Progress.Printf("Converting %s to %s", src, format)
+			img, err = convert(img)
+			if err != nil {
+				return fmt.Errorf("converting %s: %w", src, err)
+			}
+			if err := crane.Push(img, dst, *options...); err != nil {
+				return fmt.Errorf("pushing %s: %w", dst, err)
+			}
+			d, err := img.Digest()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), d)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&prioritize, "prioritize", nil, "Absolute paths of files to place first in each layer, in the order they are accessed")
+	cmd.Flags().StringVar(&profile, "profile", "", "File listing paths to prioritize, one per line, in the order they are accessed")
+	cmd.Flags().IntVar(&chunkSize, "chunk-size", 0, "Size of the chunks files are split into for lazy fetching; defaults to the estargz default")
+	cmd.Flags().IntVar(&level, "compression-level", 0, "Compression level of the converted layers: gzip for estargz, zstd for zstd:chunked")
+	cmd.Flags().StringVar(&format, "format", "estargz", "Layer format to convert to: estargz or zstd:chunked")
+
+	return cmd
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdPin creates a new cobra.Command for the pin subcommand.
+func NewCmdPin(options *[]crane.Option) *cobra.Command {
+	var (
+		write    bool
+		lockfile string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pin FILE...",
+		Short: "Pin the image references in Dockerfiles, Kubernetes YAML and other files to digests",
+		Long: `Pin the image references in Dockerfiles, Kubernetes YAML and other files to digests.
+
+References are found in Dockerfile FROM instructions and YAML "image:" fields,
+and each tag is resolved to a digest, e.g. "alpine:3.20" is pinned as
+"alpine:3.20@sha256:...". References that are already pinned, build stages and
+references that use variables are left alone.
+
+By default, the pinned files are printed to stdout.`,
+		Example: `  # Pin the base images in a Dockerfile, in place
+  crane pin -w Dockerfile
+
+  # Record the digests of the images a deployment uses
+  crane pin --lockfile images.lock.json deploy/*.yaml`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files := make([][]byte, len(args))
+			var refs []string
+			for i, path := range args {
+				b, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				files[i] = b
+				refs = append(refs, crane.FindReferences(b)...)
+			}
+
+			logs.Progress.Printf("Resolving %d references", len(refs))
+			digests, err := crane.ResolveDigests(refs, *options...)
+			if err != nil {
+				return fmt.Errorf("resolving digests: %w", err)
+			}
+
+			if lockfile != "" {
+				b, err := json.MarshalIndent(digests, "", "  ")
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(lockfile, append(b, '\n'), 0o644); err != nil {
+					return err
+				}
+			}
+			for i, path := range args {
+				pinned := crane.Pin(files[i], digests)
+				switch {
+				case write:
+					fi, err := os.Stat(path)
+					if err != nil {
+						return err
+					}
+					if err := os.WriteFile(path, pinned, fi.Mode()); err != nil {
+						return err
+					}
+				case lockfile == "":
+					if _, err := cmd.OutOrStdout().Write(pinned); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&write, "write", "w", false, "Rewrite the files in place, rather than printing them")
+	cmd.Flags().StringVar(&lockfile, "lockfile", "", "Write the digests, keyed by reference, to this JSON file instead of printing the pinned files")
+
+	return cmd
+}
@@ -15,9 +15,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/cache"
@@ -30,21 +33,45 @@ import (
 // NewCmdPull creates a new cobra.Command for the pull subcommand.
 func NewCmdPull(options *[]crane.Option) *cobra.Command {
 	var (
-		cachePath, format string
-		annotateRef       bool
+		cachePath, format   string
+		resumeDir           string
+		annotateRef, podman bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "pull IMAGE TARBALL",
 		Short: "Pull remote images by reference and store their contents locally",
-		Args:  cobra.MinimumNArgs(2),
-		RunE: func(_ *cobra.Command, args []string) error {
+		Long:  `With --format=daemon, images are loaded into the local docker daemon (or Podman, with --podman) under their tags instead, and TARBALL is omitted.`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
 			imageMap := map[string]v1.Image{}
 			indexMap := map[string]v1.ImageIndex{}
-			srcList, path := args[:len(args)-1], args[len(args)-1]
-			o := crane.GetOptions(*options...)
+			var srcList []string
+			var path string
+			if podman && format != "daemon" {
+				return errors.New("--podman requires --format=daemon")
+			}
+			if format == "daemon" {
+				srcList = args
+			} else if len(args) < 2 {
+				return fmt.Errorf("requires at least 2 arg(s), only received %d", len(args))
+			} else {
+				srcList, path = args[:len(args)-1], args[len(args)-1]
+			}
+			opts := slices.Clip(*options)
+			if resumeDir != "" {
+				opts = append(opts, crane.WithResumeDir(resumeDir))
+			}
+			o := crane.GetOptions(opts...)
+			c := o.Cache
+			if cachePath != "" {
+				c = cache.NewFilesystemCache(cachePath)
+			}
+			if i, ok := c.(cache.Inspector); ok {
+				defer func() { logs.Progress.Printf("cache: %v", i.Stats()) }()
+			}
 			for _, src := range srcList {
-				ref, err := name.ParseReference(src, o.Name...)
+				ref, err := name.ParseWithPlatform(src, o.Name...)
 				if err != nil {
 					return fmt.Errorf("parsing reference %q: %w", src, err)
 				}
@@ -69,8 +96,8 @@ func NewCmdPull(options *[]crane.Option) *cobra.Command {
 				if err != nil {
 					return err
 				}
-				if cachePath != "" {
-					img = cache.Image(img, cache.NewFilesystemCache(cachePath))
+				if c != nil {
+					img = cache.Image(img, c)
 				}
 				imageMap[src] = img
 			}
@@ -96,7 +123,7 @@ func NewCmdPull(options *[]crane.Option) *cobra.Command {
 				for ref, img := range imageMap {
 					opts := []layout.Option{}
 					if annotateRef {
-						parsed, err := name.ParseReference(ref, o.Name...)
+						parsed, err := name.ParseWithPlatform(ref, o.Name...)
 						if err != nil {
 							return err
 						}
@@ -112,7 +139,7 @@ func NewCmdPull(options *[]crane.Option) *cobra.Command {
 				for ref, idx := range indexMap {
 					opts := []layout.Option{}
 					if annotateRef {
-						parsed, err := name.ParseReference(ref, o.Name...)
+						parsed, err := name.ParseWithPlatform(ref, o.Name...)
 						if err != nil {
 							return err
 						}
@@ -124,15 +151,40 @@ func NewCmdPull(options *[]crane.Option) *cobra.Command {
 						return err
 					}
 				}
+			case "daemon":
+				e, err := newEngine(podman)
+				if err != nil {
+					return err
+				}
+				tagMap := make(map[name.Tag]v1.Image, len(imageMap))
+				for src, img := range imageMap {
+					ref, err := name.ParseWithPlatform(src, o.Name...)
+					if err != nil {
+						return err
+					}
+					if pr, ok := ref.(name.PlatformReference); ok {
+						ref = pr.Reference
+					}
+					tag, ok := ref.(name.Tag)
+					if !ok {
+						return fmt.Errorf("--format=daemon requires a tag: %s", src)
+					}
+					tagMap[tag] = img
+				}
+				if err := e.load(cmd.Context(), tagMap); err != nil {
+					return fmt.Errorf("loading images into daemon: %w", err)
+				}
 			default:
-				return fmt.Errorf("unexpected --format: %q (valid values are: tarball, legacy, and oci)", format)
+				return fmt.Errorf("unexpected --format: %q (valid values are: tarball, legacy, oci, and daemon)", format)
 			}
 			return nil
 		},
 	}
 	cmd.Flags().StringVarP(&cachePath, "cache_path", "c", "", "Path to cache image layers")
-	cmd.Flags().StringVar(&format, "format", "tarball", fmt.Sprintf("Format in which to save images (%q, %q, or %q)", "tarball", "legacy", "oci"))
+	cmd.Flags().StringVar(&resumeDir, "resume-dir", "", "Path to keep partially downloaded layers in, so that interrupted pulls resume where they stopped")
+	cmd.Flags().StringVar(&format, "format", "tarball", fmt.Sprintf("Format in which to save images (%q, %q, %q, or %q)", "tarball", "legacy", "oci", "daemon"))
 	cmd.Flags().BoolVar(&annotateRef, "annotate-ref", false, "Preserves image reference used to pull as an annotation when used with --format=oci")
+	cmd.Flags().BoolVar(&podman, "podman", false, "Use Podman's Docker-compatible socket instead of the docker daemon with --format=daemon")
 
 	return cmd
 }
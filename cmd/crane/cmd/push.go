@@ -15,12 +15,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/daemon/buildkit"
 	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
@@ -29,22 +31,37 @@ import (
 
 // NewCmdPush creates a new cobra.Command for the push subcommand.
 func NewCmdPush(options *[]crane.Option) *cobra.Command {
-	index := false
+	index, fromDaemon, podman := false, false, false
 	imageRefs := ""
 	cmd := &cobra.Command{
 		Use:   "push PATH IMAGE",
 		Short: "Push local image contents to a remote registry",
-		Long:  `If the PATH is a directory, it will be read as an OCI image layout. Otherwise, PATH is assumed to be a docker-style tarball.`,
-		Args:  cobra.ExactArgs(2),
+		Long: `If the PATH is a directory, it will be read as an OCI image layout. If PATH is "-", a tarball of an OCI image layout is read from stdin, e.g. from "docker buildx build -o type=oci,dest=-". Otherwise, PATH is assumed to be a docker-style tarball or a tarball of an OCI image layout, e.g. from "skopeo copy IMAGE oci-archive:PATH".
+
+With --daemon, PATH is instead a reference to an image in the local docker daemon (or Podman, with --podman).`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			path, tag := args[0], args[1]
+			o := crane.GetOptions(*options...)
 
-			img, err := loadImage(path, index)
-			if err != nil {
+			var (
+				img partial.WithRawManifest
+				err error
+			)
+			if podman && !fromDaemon {
+				return errors.New("--podman requires --daemon")
+			}
+			if fromDaemon {
+				if index {
+					return errors.New("--index can't be used with --daemon")
+				}
+				if img, err = daemonImage(cmd.Context(), path, podman, o.Name...); err != nil {
+					return fmt.Errorf("reading %s from daemon: %w", path, err)
+				}
+			} else if img, err = loadImage(path, index); err != nil {
 				return err
 			}
 
-			o := crane.GetOptions(*options...)
 			ref, err := name.ParseReference(tag, o.Name...)
 			if err != nil {
 				return err
@@ -84,18 +101,38 @@ func NewCmdPush(options *[]crane.Option) *cobra.Command {
 	}
 	cmd.Flags().BoolVar(&index, "index", false, "push a collection of images as a single index, currently required if PATH contains multiple images")
 	cmd.Flags().StringVar(&imageRefs, "image-refs", "", "path to file where a list of the published image references will be written")
+	cmd.Flags().BoolVar(&fromDaemon, "daemon", false, "read PATH as a reference to an image in the local docker daemon")
+	cmd.Flags().BoolVar(&podman, "podman", false, "use Podman's Docker-compatible socket instead of the docker daemon with --daemon")
 	return cmd
 }
 
 func loadImage(path string, index bool) (partial.WithRawManifest, error) {
+	if path == "-" {
+		l, err := buildkit.Index(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("loading stdin as OCI layout tarball: %w", err)
+		}
+		return fromLayout(l, index)
+	}
+
 	stat, err := os.Stat(path)
 	if err != nil {
 		return nil, err
 	}
 
 	if !stat.IsDir() {
+		// OCI image layout archives are pushed like layouts, when they hold
+		// an index or --index is set.
+		if index {
+			if l, err := crane.LoadIndex(path); err == nil {
+				return l, nil
+			}
+		}
 		img, err := crane.Load(path)
 		if err != nil {
+			if l, lerr := crane.LoadIndex(path); lerr == nil {
+				return fromLayout(l, index)
+			}
 			return nil, fmt.Errorf("loading %s as tarball: %w", path, err)
 		}
 		return img, nil
@@ -105,7 +142,12 @@ func loadImage(path string, index bool) (partial.WithRawManifest, error) {
 	if err != nil {
 		return nil, fmt.Errorf("loading %s as OCI layout: %w", path, err)
 	}
+	return fromLayout(l, index)
+}
 
+// fromLayout returns the only entry of the OCI layout l, or l itself if index
+// is set.
+func fromLayout(l v1.ImageIndex, index bool) (partial.WithRawManifest, error) {
 	if index {
 		return l, nil
 	}
@@ -15,6 +15,7 @@
 package cmd
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"net/http"
@@ -24,11 +25,15 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/docker/cli/cli/config"
 	"github.com/google/go-containerregistry/internal/cmd"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/authn/azure"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/spf13/cobra"
 )
@@ -38,7 +43,11 @@ const (
 	short = "Crane is a tool for managing container images"
 )
 
-var Root = New(use, short, []crane.Option{})
+// Root is the crane command. It authenticates with the docker config file's
+// credentials, falling back to ambient Azure credentials for ACR.
+var Root = New(use, short, []crane.Option{
+	crane.WithAuthFromKeychain(authn.NewMultiKeychain(authn.DefaultKeychain, azure.Keychain)),
+})
 
 // New returns a top-level command for crane. This is mostly exposed
 // to share code with gcrane.
@@ -46,6 +55,8 @@ func New(use, short string, options []crane.Option) *cobra.Command {
 	verbose := false
 	insecure := false
 	ndlayers := false
+	cacheDir := ""
+	timeout := time.Duration(0)
 	platform := &platformValue{}
 
 	wt := &warnTransport{}
@@ -57,6 +68,13 @@ func New(use, short string, options []crane.Option) *cobra.Command {
 		DisableAutoGenTag: true,
 		SilenceUsage:      true,
 		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+			if timeout > 0 {
+				// Bound the whole command, which may be several crane
+				// operations, rather than each with crane.WithTimeout.
+				ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+				context.AfterFunc(ctx, cancel)
+				cmd.SetContext(ctx)
+			}
 			options = append(options, crane.WithContext(cmd.Context()))
 			// TODO(jonjohnsonjr): crane.Verbose option?
 			if verbose {
@@ -68,6 +86,9 @@ func New(use, short string, options []crane.Option) *cobra.Command {
 			if ndlayers {
 				options = append(options, crane.WithNondistributable())
 			}
+			if cacheDir != "" {
+				options = append(options, crane.WithCache(cache.NewFilesystemCache(cacheDir)))
+			}
 			if Version != "" {
 				binary := "crane"
 				if len(os.Args[0]) != 0 {
@@ -77,6 +98,10 @@ func New(use, short string, options []crane.Option) *cobra.Command {
 			}
 
 			options = append(options, crane.WithPlatform(platform.platform))
+			options = append(options,
+				crane.WithSource("daemon", engineSource(false)),
+				crane.WithSource("podman", engineSource(true)),
+			)
 
 			transport := remote.DefaultTransport.(*http.Transport).Clone()
 			transport.TLSClientConfig = &tls.Config{
@@ -111,6 +136,9 @@ func New(use, short string, options []crane.Option) *cobra.Command {
 		NewCmdAppend(&options),
 		NewCmdAuth(options, "crane", "auth"),
 		NewCmdBlob(&options),
+		NewCmdBundle(&options),
+		NewCmdCache(),
+		NewCmdCat(&options),
 		NewCmdCatalog(&options, "crane"),
 		NewCmdConfig(&options),
 		NewCmdCopy(&options),
@@ -123,9 +151,12 @@ func New(use, short string, options []crane.Option) *cobra.Command {
 		NewCmdList(&options),
 		NewCmdManifest(&options),
 		NewCmdMutate(&options),
+		NewCmdOptimize(&options),
+		NewCmdPin(&options),
 		NewCmdPull(&options),
 		NewCmdPush(&options),
 		NewCmdRebase(&options),
+		NewCmdSync(&options),
 		NewCmdTag(&options),
 		NewCmdValidate(&options),
 		NewCmdVersion(),
@@ -136,7 +167,9 @@ func New(use, short string, options []crane.Option) *cobra.Command {
 	root.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable debug logs")
 	root.PersistentFlags().BoolVar(&insecure, "insecure", false, "Allow image references to be fetched without TLS")
 	root.PersistentFlags().BoolVar(&ndlayers, "allow-nondistributable-artifacts", false, "Allow pushing non-distributable (foreign) layers")
-	root.PersistentFlags().Var(platform, "platform", "Specifies the platform in the form os/arch[/variant][:osversion] (e.g. linux/amd64).")
+	root.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "Cache layers of images read from registries in this directory, e.g. for pull, export, copy and flatten")
+	root.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Fail if the command takes longer than this, e.g. 5m (default no limit)")
+	root.PersistentFlags().Var(platform, "platform", "Specifies the platform in the form os/arch[/variant][:osversion] (e.g. linux/amd64), or all. With all, commands that can work on a whole index do, and the rest use the host's platform.")
 
 	return root
 }
@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/reposync"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdSync creates a new cobra.Command for the sync subcommand.
+func NewCmdSync(options *[]crane.Option) *cobra.Command {
+	var (
+		overwrite, deleteExtraneous, dryRun bool
+		tagRegex                            string
+		jobs                                int
+	)
+	cmd := &cobra.Command{
+		Use:   "sync SRC DST [SRC DST...]",
+		Short: "Replicate the tags of each SRC repository into its DST repository",
+		Long: `Replicate the tags of each SRC repository into its DST repository.
+
+By default, only tags missing from DST are copied. With --overwrite, tags in DST that
+point at a different digest are overwritten too, and with --delete, tags in DST that
+aren't in SRC are deleted.`,
+		Example: `  # Mirror the release tags of two repositories
+  crane sync --overwrite --delete --tag-regex '^v[0-9.]+$' \
+    ghcr.io/example/app registry.example.com/app \
+    ghcr.io/example/db registry.example.com/db`,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) == 0 || len(args)%2 != 0 {
+				return fmt.Errorf("requires SRC DST pairs, received %d args", len(args))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o := crane.GetOptions(*options...)
+
+			var pairs []reposync.Pair
+			for i := 0; i < len(args); i += 2 {
+				src, err := name.NewRepository(args[i], o.Name...)
+				if err != nil {
+					return err
+				}
+				dst, err := name.NewRepository(args[i+1], o.Name...)
+				if err != nil {
+					return err
+				}
+				pairs = append(pairs, reposync.Pair{Src: src, Dst: dst})
+			}
+
+			policy := reposync.CopyIfMissing
+			if overwrite {
+				policy |= reposync.OverwriteOnDigestChange
+			}
+			if deleteExtraneous {
+				policy |= reposync.DeleteExtraneous
+			}
+			opts := []reposync.Option{
+				reposync.WithRemoteOptions(o.Remote...),
+				reposync.WithPolicy(policy),
+				reposync.WithJobs(jobs),
+			}
+			if tagRegex != "" {
+				re, err := regexp.Compile(tagRegex)
+				if err != nil {
+					return fmt.Errorf("parsing --tag-regex: %w", err)
+				}
+				opts = append(opts, reposync.WithTagRegexp(re))
+			}
+
+			ctx := cmd.Context()
+			plan, err := reposync.NewPlan(ctx, pairs, opts...)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				for _, a := range plan.Actions {
+					fmt.Fprintln(cmd.OutOrStdout(), a)
+				}
+				return nil
+			}
+
+			updates := make(chan reposync.Update)
+			done := make(chan struct{})
+			opts = append(opts, reposync.WithProgress(updates))
+			go func() {
+				defer close(done)
+				for u := range updates {
+					if u.Err != nil {
+						logs.Warn.Printf("[%d/%d] %v", u.Complete, u.Total, u.Err)
+					} else {
+						logs.Progress.Printf("[%d/%d] %s", u.Complete, u.Total, u.Action)
+					}
+				}
+			}()
+			_, err = reposync.Execute(ctx, plan, opts...)
+			<-done
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&overwrite, "overwrite", false, "(Optional) if true, overwrite tags in DST that point at a different digest than in SRC")
+	cmd.Flags().BoolVar(&deleteExtraneous, "delete", false, "(Optional) if true, delete tags in DST that aren't in SRC")
+	cmd.Flags().StringVar(&tagRegex, "tag-regex", "", "(Optional) only sync tags matching this regular expression")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "(Optional) if true, print the planned actions without executing them")
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", 0, "(Optional) The maximum number of concurrent copies, defaults to GOMAXPROCS")
+
+	return cmd
+}
@@ -27,6 +27,7 @@ func TestDeps(t *testing.T) {
 	depcheck.AssertNoDependency(t, map[string][]string{
 		"github.com/google/go-containerregistry/cmd/crane": {
 			"github.com/google/go-containerregistry/pkg/v1/daemon",
+			"github.com/docker/docker/client",
 		},
 	})
 }
@@ -15,6 +15,8 @@
 package cmd
 
 import (
+	"fmt"
+	"regexp"
 	"runtime"
 
 	"github.com/google/go-containerregistry/pkg/gcrane"
@@ -25,6 +27,10 @@ import (
 func NewCmdCopy() *cobra.Command {
 	recursive := false
 	jobs := 1
+	var include, exclude []string
+	tagRegex := ""
+	checkpoint := ""
+	resume := false
 	cmd := &cobra.Command{
 		Use:     "copy SRC DST",
 		Aliases: []string{"cp"},
@@ -34,7 +40,26 @@ func NewCmdCopy() *cobra.Command {
 			src, dst := args[0], args[1]
 			ctx := cc.Context()
 			if recursive {
-				return gcrane.CopyRepository(ctx, src, dst, gcrane.WithJobs(jobs), gcrane.WithUserAgent(userAgent()), gcrane.WithContext(ctx))
+				opts := []gcrane.Option{
+					gcrane.WithJobs(jobs),
+					gcrane.WithUserAgent(userAgent()),
+					gcrane.WithContext(ctx),
+					gcrane.WithInclude(include...),
+					gcrane.WithExclude(exclude...),
+					gcrane.WithCheckpoint(checkpoint),
+					gcrane.WithResume(resume),
+				}
+				if tagRegex != "" {
+					re, err := regexp.Compile(tagRegex)
+					if err != nil {
+						return fmt.Errorf("parsing --tag-regex: %w", err)
+					}
+					opts = append(opts, gcrane.WithTagRegexp(re))
+				}
+				return gcrane.CopyRepository(ctx, src, dst, opts...)
+			}
+			if len(include) != 0 || len(exclude) != 0 || tagRegex != "" || checkpoint != "" || resume {
+				return fmt.Errorf("--include, --exclude, --tag-regex, --checkpoint and --resume require --recursive")
 			}
 			return gcrane.Copy(src, dst, gcrane.WithUserAgent(userAgent()), gcrane.WithContext(ctx))
 		},
@@ -42,6 +67,11 @@ func NewCmdCopy() *cobra.Command {
 
 	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Whether to recurse through repos")
 	cmd.Flags().IntVarP(&jobs, "jobs", "j", runtime.GOMAXPROCS(0), "The maximum number of concurrent copies")
+	cmd.Flags().StringSliceVar(&include, "include", nil, "With -r, only copy repos whose path relative to SRC matches one of these globs")
+	cmd.Flags().StringSliceVar(&exclude, "exclude", nil, "With -r, skip repos whose path relative to SRC matches one of these globs")
+	cmd.Flags().StringVar(&checkpoint, "checkpoint", "", "With -r, record each copied image in this file")
+	cmd.Flags().BoolVar(&resume, "resume", false, "With -r, skip the images already recorded in the --checkpoint file")
+	cmd.Flags().StringVar(&tagRegex, "tag-regex", "", "With -r, only copy tags matching this regular expression, and images with such a tag")
 
 	return cmd
 }
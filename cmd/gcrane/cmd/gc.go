@@ -16,32 +16,78 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/gcrane"
+	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/google"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	remotegc "github.com/google/go-containerregistry/pkg/v1/remote/gc"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/spf13/cobra"
 )
 
 // NewCmdGc creates a new cobra.Command for the gc subcommand.
 func NewCmdGc() *cobra.Command {
-	recursive := false
+	var (
+		recursive, untagged, tagged, dryRun bool
+		olderThan                           age
+	)
 	cmd := &cobra.Command{
-		Use:   "gc",
-		Short: "List images that are not tagged",
-		Args:  cobra.ExactArgs(1),
+		Use:   "gc REPO",
+		Short: "Delete images that are untagged or stale",
+		Long: `Delete images that are untagged (--untagged), uploaded longer ago than --older-than, or both.
+Tagged images are only deleted with --include-tagged, and are untagged before they are deleted.
+Images that are reachable from an image that is kept, as a child of an index or as a referrer,
+are never deleted.
+
+Without --untagged or --older-than, untagged images are printed but not deleted, as with --untagged --dry-run.`,
+		Example: `  # Delete untagged images older than 30 days in every repo under gcr.io/my-project
+  gcrane gc gcr.io/my-project --recursive --untagged --older-than 30d
+
+  # Delete every image older than a year, tagged or not
+  gcrane gc gcr.io/my-project/app --older-than 365d --include-tagged`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cc *cobra.Command, args []string) error {
-			return gc(cc.Context(), args[0], recursive)
+			o := gcOptions{
+				untagged:  untagged,
+				tagged:    tagged,
+				olderThan: time.Duration(olderThan),
+				dryRun:    dryRun,
+			}
+			if untagged && tagged {
+				return errors.New("--untagged and --include-tagged are mutually exclusive")
+			}
+			if !untagged && olderThan == 0 {
+				o.untagged, o.dryRun = true, true
+			}
+			return gc(cc.Context(), args[0], recursive, o)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Whether to recurse through repos")
+	cmd.Flags().BoolVar(&untagged, "untagged", false, "Delete images without tags")
+	cmd.Flags().BoolVar(&tagged, "include-tagged", false, "Also untag and delete tagged images selected by --older-than")
+	cmd.Flags().Var(&olderThan, "older-than", "Delete images uploaded longer ago than this, e.g. 30d or 12h")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the images that would be deleted without deleting them")
 
 	return cmd
 }
 
-func gc(ctx context.Context, root string, recursive bool) error {
+type gcOptions struct {
+	untagged  bool
+	tagged    bool
+	olderThan time.Duration
+	dryRun    bool
+}
+
+func gc(ctx context.Context, root string, recursive bool, o gcOptions) error {
 	repo, err := name.NewRepository(root)
 	if err != nil {
 		return err
@@ -52,25 +98,125 @@ func gc(ctx context.Context, root string, recursive bool) error {
 		google.WithUserAgent(userAgent()),
 		google.WithContext(ctx),
 	}
+	ropts := []remote.Option{
+		remote.WithAuthFromKeychain(gcrane.Keychain),
+		remote.WithUserAgent(userAgent()),
+		remote.WithContext(ctx),
+	}
+
+	walkFn := func(repo name.Repository, tags *google.Tags, err error) error {
+		if err != nil {
+			return err
+		}
+		return collect(ctx, repo, tags, o, ropts)
+	}
 
 	if recursive {
-		return google.Walk(repo, printUntaggedImages, opts...)
+		return google.Walk(repo, walkFn, opts...)
 	}
 
 	tags, err := google.List(repo, opts...)
-	return printUntaggedImages(repo, tags, err)
+	return walkFn(repo, tags, err)
 }
 
-func printUntaggedImages(repo name.Repository, tags *google.Tags, err error) error {
+// collect deletes, or prints with dryRun, the images in repo selected by o.
+func collect(ctx context.Context, repo name.Repository, tags *google.Tags, o gcOptions, ropts []remote.Option) error {
+	cutoff := time.Now().Add(-o.olderThan)
+	selected := func(m google.ManifestInfo) bool {
+		if len(m.Tags) != 0 && !o.tagged {
+			return false
+		}
+		if o.olderThan != 0 && !m.Uploaded.Before(cutoff) {
+			return false
+		}
+		return true
+	}
+
+	var doomed []string
+	var kept []name.Reference
+	for digest, m := range tags.Manifests {
+		if selected(m) {
+			doomed = append(doomed, digest)
+		} else {
+			kept = append(kept, repo.Digest(digest))
+		}
+	}
+	if len(doomed) == 0 {
+		return nil
+	}
+
+	// Keep anything a kept image refers to, however indirectly: the
+	// children of kept indexes, their referrers, and so on.
+	plan, err := remotegc.Mark(ctx, repo, kept, ropts...)
 	if err != nil {
 		return err
 	}
+	reachable := map[string]bool{}
+	for _, h := range plan.Keep {
+		reachable[h.String()] = true
+	}
+
+	// Delete indexes before the manifests they refer to, which the registry
+	// would otherwise refuse to delete.
+	sort.Slice(doomed, func(i, j int) bool {
+		ii := types.MediaType(tags.Manifests[doomed[i]].MediaType).IsIndex()
+		ij := types.MediaType(tags.Manifests[doomed[j]].MediaType).IsIndex()
+		if ii != ij {
+			return ii
+		}
+		return doomed[i] < doomed[j]
+	})
 
-	for digest, manifest := range tags.Manifests {
-		if len(manifest.Tags) == 0 {
-			fmt.Printf("%s@%s\n", repo, digest)
+	for _, digest := range doomed {
+		if reachable[digest] {
+			logs.Progress.Printf("Keeping %s@%s, which is reachable from a kept image", repo, digest)
+			continue
+		}
+		ref := repo.Digest(digest)
+		fmt.Println(ref)
+		if o.dryRun {
+			continue
+		}
+		for _, tag := range tags.Manifests[digest].Tags {
+			if err := remote.Delete(repo.Tag(tag), ropts...); err != nil {
+				return fmt.Errorf("untagging %s: %w", repo.Tag(tag), err)
+			}
 		}
+		if err := remote.Delete(ref, ropts...); err != nil {
+			return fmt.Errorf("deleting %s: %w", ref, err)
+		}
+	}
+
+	return nil
+}
+
+// age is a time.Duration flag that also accepts a number of days, e.g. "30d".
+type age time.Duration
+
+func (a *age) String() string {
+	if *a == 0 {
+		return ""
 	}
+	return time.Duration(*a).String()
+}
 
+func (a *age) Set(s string) error {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return fmt.Errorf("parsing %q: %w", s, err)
+		}
+		*a = age(n * float64(24*time.Hour))
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*a = age(d)
 	return nil
 }
+
+func (a *age) Type() string {
+	return "duration"
+}
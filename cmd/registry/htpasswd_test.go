@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHtpasswd(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The {SHA} entry is `htpasswd -s` for "password".
+	h, err := parseHtpasswd(strings.NewReader("# comment\n\nalice:" + string(hash) + "\nbob:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := httptest.NewServer(h.wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+	defer s.Close()
+
+	for _, tc := range []struct {
+		user, password string
+		want           int
+	}{
+		{"alice", "hunter2", http.StatusOK},
+		{"bob", "password", http.StatusOK},
+		{"alice", "password", http.StatusUnauthorized},
+		{"carol", "hunter2", http.StatusUnauthorized},
+		{"", "", http.StatusUnauthorized},
+	} {
+		req, err := http.NewRequest(http.MethodGet, s.URL+"/v2/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.user != "" {
+			req.SetBasicAuth(tc.user, tc.password)
+		}
+		resp, err := s.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s:%s: got %d, want %d", tc.user, tc.password, resp.StatusCode, tc.want)
+		}
+		if resp.StatusCode == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+			t.Error("missing WWW-Authenticate challenge")
+		}
+	}
+
+	if _, err := parseHtpasswd(strings.NewReader("mallory:$apr1$abc$def\n")); err == nil {
+		t.Error("want error for unsupported hash")
+	}
+}
@@ -25,22 +25,65 @@ import (
 	"github.com/google/go-containerregistry/pkg/registry"
 )
 
-var port = flag.Int("port", 1338, "port to run registry on")
+var (
+	port          = flag.Int("port", 1338, "port to run registry on, if --address isn't set")
+	address       = flag.String("address", "", "address to listen on, e.g. :5000 (default localhost:<port>)")
+	tlsCert       = flag.String("tls-cert", "", "path to a TLS certificate to serve with, requires --tls-key")
+	tlsKey        = flag.String("tls-key", "", "path to the TLS key for --tls-cert")
+	storageDir    = flag.String("storage-dir", "", "directory to persist blobs and manifests in (default in-memory)")
+	authHtpasswd  = flag.String("auth-htpasswd", "", "path to an htpasswd file (bcrypt) to require basic auth with")
+	proxyUpstream = flag.String("proxy-upstream", "", "URL of a registry to act as a pull-through cache of, e.g. https://registry-1.docker.io")
+	metrics       = flag.Bool("metrics", false, "serve Prometheus metrics at "+registry.MetricsPath)
+)
 
 func main() {
 	flag.Parse()
 
-	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", *port))
+	if (*tlsCert == "") != (*tlsKey == "") {
+		log.Fatal("--tls-cert and --tls-key must be set together")
+	}
+
+	var opts []registry.Option
+	if *storageDir != "" {
+		opts = append(opts, registry.WithDiskStorage(*storageDir))
+	} else {
+		opts = append(opts, registry.WithWarning(.01, "Congratulations! You've won a lifetime's supply of free image pulls from this in-memory registry!"))
+	}
+	if *metrics {
+		opts = append(opts, registry.WithMetrics())
+	}
+	if *proxyUpstream != "" {
+		u, err := newUpstream(*proxyUpstream)
+		if err != nil {
+			log.Fatalf("--proxy-upstream: %v", err)
+		}
+		opts = append(opts, registry.WithProxy(u))
+	}
+
+	handler := registry.New(opts...)
+	if *authHtpasswd != "" {
+		h, err := readHtpasswd(*authHtpasswd)
+		if err != nil {
+			log.Fatalf("--auth-htpasswd: %v", err)
+		}
+		handler = h.wrap(handler)
+	}
+
+	addr := *address
+	if addr == "" {
+		addr = fmt.Sprintf("localhost:%d", *port)
+	}
+	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		log.Fatal(err)
 	}
-	porti := listener.Addr().(*net.TCPAddr).Port
-	log.Printf("serving on port %d", porti)
+	log.Printf("serving on %s", listener.Addr())
 	s := &http.Server{
 		ReadHeaderTimeout: 5 * time.Second, // prevent slowloris, quiet linter
-		Handler: registry.New(
-			registry.WithWarning(.01, "Congratulations! You've won a lifetime's supply of free image pulls from this in-memory registry!"),
-		),
+		Handler:           handler,
+	}
+	if *tlsCert != "" {
+		log.Fatal(s.ServeTLS(listener, *tlsCert, *tlsKey))
 	}
 	log.Fatal(s.Serve(listener))
 }
@@ -0,0 +1,99 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// pullerUpstream implements registry.Upstream with a remote.Puller, which
+// requests pull scopes for each repository it's asked about.
+type pullerUpstream struct {
+	reg    name.Registry
+	puller *remote.Puller
+}
+
+// newUpstream parses raw and returns an upstream that authenticates to it
+// with credentials from the default keychain.
+func newUpstream(raw string) (*pullerUpstream, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("want a URL like https://registry.example.com, got %q", raw)
+	}
+	var opts []name.Option
+	if u.Scheme == "http" {
+		opts = append(opts, name.Insecure)
+	}
+	reg, err := name.NewRegistry(u.Host, opts...)
+	if err != nil {
+		return nil, err
+	}
+	puller, err := remote.NewPuller(remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return nil, err
+	}
+	return &pullerUpstream{reg: reg, puller: puller}, nil
+}
+
+// Manifest implements registry.Upstream.
+func (p *pullerUpstream) Manifest(ctx context.Context, repo, target string) ([]byte, types.MediaType, error) {
+	var ref name.Reference = p.reg.Repo(repo).Tag(target)
+	if _, err := v1.NewHash(target); err == nil {
+		ref = p.reg.Repo(repo).Digest(target)
+	}
+	desc, err := p.puller.Get(ctx, ref)
+	if err != nil {
+		return nil, "", notExist(err)
+	}
+	return desc.Manifest, desc.MediaType, nil
+}
+
+// Blob implements registry.Upstream.
+func (p *pullerUpstream) Blob(ctx context.Context, repo string, h v1.Hash) (io.ReadCloser, error) {
+	l, err := p.puller.Layer(ctx, p.reg.Repo(repo).Digest(h.String()))
+	if err != nil {
+		return nil, notExist(err)
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		return nil, notExist(err)
+	}
+	return rc, nil
+}
+
+// notExist wraps err in fs.ErrNotExist if it's a 404 from the registry.
+func notExist(err error) error {
+	var terr *transport.Error
+	if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+	return err
+}
@@ -37,6 +37,16 @@ go get -u ./...
 go mod tidy -compat=1.18
 go mod download
 
+cd ${PROJECT_ROOT}/pkg/authn/aws
+go get -u ./...
+go mod tidy -compat=1.18
+go mod download
+
+cd ${PROJECT_ROOT}/pkg/v1/ctr
+go get -u ./...
+go mod tidy -compat=1.18
+go mod download
+
 cd ${PROJECT_ROOT}/cmd/krane
 go get -u ./...
 go mod tidy -compat=1.18
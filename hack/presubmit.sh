@@ -56,3 +56,11 @@ go build ./...
 pushd ${PROJECT_ROOT}/pkg/authn/kubernetes
 trap popd EXIT
 go test ./...
+
+pushd ${PROJECT_ROOT}/pkg/authn/aws
+trap popd EXIT
+go test ./...
+
+pushd ${PROJECT_ROOT}/pkg/v1/ctr
+trap popd EXIT
+go test ./...
@@ -20,9 +20,9 @@ import (
 	"bytes"
 	"io"
 
-	"github.com/google/go-containerregistry/internal/gzip"
-	"github.com/google/go-containerregistry/internal/zstd"
+	"github.com/google/go-containerregistry/internal/and"
 	"github.com/google/go-containerregistry/pkg/compression"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 )
 
 // Opener represents e.g. opening a file.
@@ -44,27 +44,118 @@ func GetCompression(opener Opener) (compression.Compression, error) {
 	return cp, nil
 }
 
-// PeekCompression detects whether the input stream is compressed and which algorithm is used.
+// PeekCompression detects whether the input stream is compressed and which
+// format is used, by checking for the magic header of each registered
+// format.
 //
 // If r implements Peek, we will use that directly, otherwise a small number
-// of bytes are buffered to Peek at the gzip/zstd header, and the returned
+// of bytes are buffered to Peek at the header, and the returned
 // PeekReader can be used as a replacement for the consumed input io.Reader.
 func PeekCompression(r io.Reader) (compression.Compression, PeekReader, error) {
 	pr := intoPeekReader(r)
 
-	if isGZip, _, err := checkHeader(pr, gzip.MagicHeader); err != nil {
-		return compression.None, pr, err
-	} else if isGZip {
-		return compression.GZip, pr, nil
+	for _, c := range compression.Codecs() {
+		// Only check each format once, rather than for every implementation.
+		if c.Magic() == nil || c.Format() != c.Compression() {
+			continue
+		}
+		if ok, _, err := checkHeader(pr, c.Magic()); err != nil {
+			return compression.None, pr, err
+		} else if ok {
+			return c.Format(), pr, nil
+		}
 	}
 
-	if isZStd, _, err := checkHeader(pr, zstd.MagicHeader); err != nil {
-		return compression.None, pr, err
-	} else if isZStd {
-		return compression.ZStd, pr, nil
+	return compression.None, pr, nil
+}
+
+// ForMediaType returns the compression format of layers of type mt,
+// defaulting to gzip.
+func ForMediaType(mt types.MediaType) compression.Compression {
+	switch mt {
+	case types.OCILayerZStd:
+		return compression.ZStd
+	case types.OCIUncompressedLayer, types.OCIUncompressedRestrictedLayer, types.DockerUncompressedLayer:
+		return compression.None
 	}
+	return compression.GZip
+}
 
-	return compression.None, pr, nil
+// ReadCloserLevel reads uncompressed input data from the io.ReadCloser and
+// returns an io.ReadCloser from which data compressed by c at level may be
+// read.
+func ReadCloserLevel(r io.ReadCloser, c compression.Codec, level int) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	// For highly compressible layers, compressors will output a very small
+	// number of bytes per Write(). This is normally fine, but when pushing
+	// to a registry, we want to ensure that we're taking full advantage of
+	// the available bandwidth instead of sending tons of tiny writes over
+	// the wire.
+	// 64K ought to be small enough for anybody.
+	bw := bufio.NewWriterSize(pw, 2<<16)
+
+	go func() {
+		defer r.Close()
+
+		zw, err := c.NewWriter(bw, level)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(zw, r); err != nil {
+			zw.Close()
+			pw.CloseWithError(err)
+			return
+		}
+		// Close the compressor to flush it and write any trailers.
+		if err := zw.Close(); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(bw.Flush())
+	}()
+
+	return pr
+}
+
+// UnzipReadCloser reads data compressed by c from the io.ReadCloser and
+// returns an io.ReadCloser from which uncompressed data may be read.
+func UnzipReadCloser(r io.ReadCloser, c compression.Codec) (io.ReadCloser, error) {
+	zr, err := c.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &and.ReadCloser{
+		Reader: zr,
+		CloseFunc: func() error {
+			// If decompression failed, this returns the same error as the
+			// read, which mustn't stop us closing r.
+			zr.Close()
+			return r.Close()
+		},
+	}, nil
+}
+
+// Decompress returns an io.ReadCloser of the uncompressed contents of r,
+// whichever registered format it's compressed with, if any.
+func Decompress(r io.ReadCloser) (io.ReadCloser, error) {
+	cp, pr, err := PeekCompression(r)
+	if err != nil {
+		return nil, err
+	}
+	prc := &and.ReadCloser{
+		Reader:    pr,
+		CloseFunc: r.Close,
+	}
+	if cp == compression.None {
+		return prc, nil
+	}
+	c, err := compression.Lookup(cp)
+	if err != nil {
+		return nil, err
+	}
+	return UnzipReadCloser(prc, c)
 }
 
 // PeekReader is an io.Reader that also implements Peek a la bufio.Reader.
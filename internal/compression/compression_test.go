@@ -21,7 +21,7 @@ import (
 
 	"github.com/google/go-containerregistry/internal/and"
 	"github.com/google/go-containerregistry/internal/gzip"
-	"github.com/google/go-containerregistry/internal/zstd"
+	"github.com/google/go-containerregistry/pkg/compression/zstd"
 )
 
 type Compressor = func(rc io.ReadCloser) io.ReadCloser
@@ -0,0 +1,100 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dockercontext resolves docker CLI contexts, which say how to reach
+// a docker daemon.
+package dockercontext
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/cli/cli/config"
+)
+
+// Default is the name of the context that uses $DOCKER_HOST, or the local
+// daemon if that's unset.
+const Default = "default"
+
+// configDir returns the docker CLI's config directory.
+//
+// We don't use config.Dir, which caches $DOCKER_CONFIG the first time it's
+// called.
+func configDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker")
+}
+
+// Name returns the name of the current context, in the same order of
+// preference as the docker CLI:
+//   - Default, if $DOCKER_HOST is set,
+//   - $DOCKER_CONTEXT,
+//   - the currentContext in the CLI's config file,
+//   - Default.
+func Name() string {
+	if os.Getenv("DOCKER_HOST") != "" {
+		return Default
+	}
+	if name := os.Getenv("DOCKER_CONTEXT"); name != "" {
+		return name
+	}
+	if cf, err := config.Load(configDir()); err == nil && cf.CurrentContext != "" {
+		return cf.CurrentContext
+	}
+	return Default
+}
+
+// StoreDir returns the directory of the CLI's context store.
+func StoreDir() string {
+	return filepath.Join(configDir(), "contexts")
+}
+
+// Host returns the address of the daemon that the context called name uses,
+// e.g. unix:///var/run/docker.sock or ssh://user@host.
+//
+// This reads the context's metadata itself, rather than with the CLI's
+// context store, to keep the store's dependencies out of crane.
+func Host(name string) (string, error) {
+	sum := sha256.Sum256([]byte(name))
+	b, err := os.ReadFile(filepath.Join(StoreDir(), "meta", hex.EncodeToString(sum[:]), "meta.json"))
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("context %q does not exist", name)
+	} else if err != nil {
+		return "", err
+	}
+	var meta struct {
+		Endpoints struct {
+			Docker struct {
+				Host string
+			} `json:"docker"`
+		}
+	}
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return "", fmt.Errorf("context %q: %w", name, err)
+	}
+	if meta.Endpoints.Docker.Host == "" {
+		return "", fmt.Errorf("context %q has no docker endpoint", name)
+	}
+	return meta.Endpoints.Docker.Host, nil
+}
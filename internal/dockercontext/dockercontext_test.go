@@ -0,0 +1,82 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dockercontext
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeContext adds a context called name that uses host to the store in dir.
+func writeContext(t *testing.T, dir, name, host string) {
+	t.Helper()
+	sum := sha256.Sum256([]byte(name))
+	p := filepath.Join(dir, "contexts", "meta", hex.EncodeToString(sum[:]), "meta.json")
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	meta := `{"Name":"` + name + `","Metadata":{},"Endpoints":{"docker":{"Host":"` + host + `","SkipTLSVerify":false}}}`
+	if err := os.WriteFile(p, []byte(meta), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContexts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("DOCKER_CONTEXT", "")
+
+	writeContext(t, dir, "remote", "ssh://me@example.com")
+	writeContext(t, dir, "other", "unix:///other.sock")
+
+	if got := Name(); got != Default {
+		t.Errorf("Name() without config: got %q, want %q", got, Default)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"currentContext":"remote"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Name(), "remote"; got != want {
+		t.Errorf("Name() from config: got %q, want %q", got, want)
+	}
+
+	t.Setenv("DOCKER_CONTEXT", "other")
+	if got, want := Name(), "other"; got != want {
+		t.Errorf("Name() from DOCKER_CONTEXT: got %q, want %q", got, want)
+	}
+
+	t.Setenv("DOCKER_HOST", "unix:///env.sock")
+	if got := Name(); got != Default {
+		t.Errorf("Name() with DOCKER_HOST: got %q, want %q", got, Default)
+	}
+
+	for name, want := range map[string]string{
+		"remote": "ssh://me@example.com",
+		"other":  "unix:///other.sock",
+	} {
+		if got, err := Host(name); err != nil {
+			t.Errorf("Host(%q): %v", name, err)
+		} else if got != want {
+			t.Errorf("Host(%q): got %q, want %q", name, got, want)
+		}
+	}
+	if _, err := Host("missing"); err == nil {
+		t.Error("Host(missing): want error")
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package podman finds the socket of Podman's Docker-compatible API.
+package podman
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RootfulSocket is where a system-wide podman.socket listens.
+const RootfulSocket = "/run/podman/podman.sock"
+
+// Host returns the address of Podman's Docker-compatible API socket.
+//
+// In order, it uses:
+//   - $CONTAINER_HOST, if it's a unix:// address,
+//   - the rootless socket at $XDG_RUNTIME_DIR/podman/podman.sock,
+//   - the rootful socket at /run/podman/podman.sock.
+func Host() (string, error) {
+	if host := os.Getenv("CONTAINER_HOST"); host != "" {
+		if !strings.HasPrefix(host, "unix://") {
+			return "", fmt.Errorf("unsupported CONTAINER_HOST %q: only unix:// sockets are supported", host)
+		}
+		return host, nil
+	}
+
+	var candidates []string
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		candidates = append(candidates, filepath.Join(dir, "podman", "podman.sock"))
+	}
+	candidates = append(candidates, RootfulSocket)
+	for _, sock := range candidates {
+		if fi, err := os.Stat(sock); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			return "unix://" + sock, nil
+		}
+	}
+	return "", errors.New("could not find podman socket in " + strings.Join(candidates, " or ") + ", is podman.socket running?")
+}
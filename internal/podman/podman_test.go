@@ -0,0 +1,64 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podman
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHost(t *testing.T) {
+	if _, err := os.Stat(RootfulSocket); err == nil {
+		t.Skipf("%s exists", RootfulSocket)
+	}
+
+	dir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	t.Setenv("CONTAINER_HOST", "")
+
+	if _, err := Host(); err == nil {
+		t.Error("Host(): want error without a socket")
+	}
+
+	sock := filepath.Join(dir, "podman", "podman.sock")
+	if err := os.MkdirAll(filepath.Dir(sock), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if got, err := Host(); err != nil {
+		t.Errorf("Host(): %v", err)
+	} else if want := "unix://" + sock; got != want {
+		t.Errorf("Host(): got %q, want %q", got, want)
+	}
+
+	t.Setenv("CONTAINER_HOST", "unix:///elsewhere.sock")
+	if got, err := Host(); err != nil {
+		t.Errorf("Host(): %v", err)
+	} else if want := "unix:///elsewhere.sock"; got != want {
+		t.Errorf("Host(): got %q, want %q", got, want)
+	}
+
+	t.Setenv("CONTAINER_HOST", "ssh://core@localhost:22/run/podman/podman.sock")
+	if _, err := Host(); err == nil {
+		t.Error("Host(): want error for ssh:// CONTAINER_HOST")
+	}
+}
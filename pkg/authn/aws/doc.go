@@ -0,0 +1,22 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package aws provides an authn.Keychain for Amazon Elastic Container
+// Registry (ECR) that uses the AWS SDK to mint registry tokens from ambient
+// credentials (environment, shared config, instance or task role) without
+// requiring the docker-credential-ecr-login binary.
+//
+// This package lives in its own module so that users of go-containerregistry
+// don't pull in the AWS SDK unless they need it.
+package aws
@@ -0,0 +1,203 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecrpublic"
+	"github.com/google/go-containerregistry/pkg/authn"
+)
+
+const (
+	// ecrPublicHostname is the hostname of the ECR Public registry.
+	ecrPublicHostname = "public.ecr.aws"
+	// ecrPublicRegion is the only region in which the ECR Public API is served.
+	ecrPublicRegion = "us-east-1"
+	// expiryDelta is how long before a token's expiry we fetch a new one.
+	expiryDelta = 5 * time.Minute
+)
+
+// ecrPattern matches private ECR registry hostnames, capturing the account
+// and region, e.g. 123456789012.dkr.ecr.us-west-2.amazonaws.com.
+var ecrPattern = regexp.MustCompile(`^(\d{12})\.dkr[\.\-]ecr(?:-fips)?\.([a-zA-Z0-9][a-zA-Z0-9-_]*)\.(?:amazonaws\.com(?:\.cn)?|sc2s\.sgov\.gov|c2s\.ic\.gov|cloud\.adc-e\.uk|csp\.hci\.ic\.gov)$`)
+
+// Keychain exports an instance of the ECR Keychain that loads AWS
+// credentials from the default credential chain.
+//
+// This keychain matches on requests for private ECR registries and ECR
+// Public, and resolves to authn.Anonymous for everything else.
+var Keychain authn.Keychain = NewKeychain()
+
+type ecrAPI interface {
+	GetAuthorizationToken(context.Context, *ecr.GetAuthorizationTokenInput, ...func(*ecr.Options)) (*ecr.GetAuthorizationTokenOutput, error)
+}
+
+type ecrPublicAPI interface {
+	GetAuthorizationToken(context.Context, *ecrpublic.GetAuthorizationTokenInput, ...func(*ecrpublic.Options)) (*ecrpublic.GetAuthorizationTokenOutput, error)
+}
+
+// Option configures the ECR Keychain.
+type Option func(*keychain)
+
+// WithConfig uses cfg instead of loading the default AWS config. The
+// region of cfg is ignored in favor of the region of the registry.
+func WithConfig(cfg awsv2.Config) Option {
+	return func(k *keychain) {
+		k.cfg = &cfg
+	}
+}
+
+// NewKeychain returns an ECR Keychain configured by opts.
+func NewKeychain(opts ...Option) authn.Keychain {
+	k := &keychain{
+		tokens: map[string]*authn.AuthConfig{},
+		expiry: map[string]time.Time{},
+	}
+	for _, opt := range opts {
+		opt(k)
+	}
+	k.newECR = func(cfg awsv2.Config) ecrAPI { return ecr.NewFromConfig(cfg) }
+	k.newECRPublic = func(cfg awsv2.Config) ecrPublicAPI { return ecrpublic.NewFromConfig(cfg) }
+	return k
+}
+
+type keychain struct {
+	mu  sync.Mutex
+	cfg *awsv2.Config
+
+	// Tokens are good for 12 hours, so cache them by registry.
+	tokens map[string]*authn.AuthConfig
+	expiry map[string]time.Time
+
+	// for testing
+	newECR       func(awsv2.Config) ecrAPI
+	newECRPublic func(awsv2.Config) ecrPublicAPI
+	clock        func() time.Time
+}
+
+// Resolve implements authn.Keychain.
+func (k *keychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	return k.ResolveContext(context.Background(), target)
+}
+
+// ResolveContext implements authn.ContextKeychain.
+func (k *keychain) ResolveContext(ctx context.Context, target authn.Resource) (authn.Authenticator, error) {
+	registry := target.RegistryStr()
+	public := registry == ecrPublicHostname
+	var account, region string
+	if !public {
+		m := ecrPattern.FindStringSubmatch(registry)
+		if m == nil {
+			return authn.Anonymous, nil
+		}
+		account, region = m[1], m[2]
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if cfg, ok := k.tokens[registry]; ok && k.now().Add(expiryDelta).Before(k.expiry[registry]) {
+		return authn.FromConfig(*cfg), nil
+	}
+
+	cfg, err := k.config(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		token     *string
+		expiresAt *time.Time
+	)
+	if public {
+		cfg.Region = ecrPublicRegion
+		out, err := k.newECRPublic(cfg).GetAuthorizationToken(ctx, &ecrpublic.GetAuthorizationTokenInput{})
+		if err != nil {
+			return nil, fmt.Errorf("getting ECR Public authorization token: %w", err)
+		}
+		if out.AuthorizationData == nil {
+			return nil, errors.New("no authorization data in ECR Public response")
+		}
+		token, expiresAt = out.AuthorizationData.AuthorizationToken, out.AuthorizationData.ExpiresAt
+	} else {
+		cfg.Region = region
+		out, err := k.newECR(cfg).GetAuthorizationToken(ctx, &ecr.GetAuthorizationTokenInput{
+			RegistryIds: []string{account},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("getting ECR authorization token for %s: %w", registry, err)
+		}
+		if len(out.AuthorizationData) == 0 {
+			return nil, fmt.Errorf("no authorization data in ECR response for %s", registry)
+		}
+		token, expiresAt = out.AuthorizationData[0].AuthorizationToken, out.AuthorizationData[0].ExpiresAt
+	}
+
+	auth, err := decodeToken(awsv2.ToString(token))
+	if err != nil {
+		return nil, err
+	}
+	k.tokens[registry] = auth
+	k.expiry[registry] = awsv2.ToTime(expiresAt)
+
+	return authn.FromConfig(*auth), nil
+}
+
+func (k *keychain) config(ctx context.Context) (awsv2.Config, error) {
+	if k.cfg != nil {
+		return k.cfg.Copy(), nil
+	}
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return awsv2.Config{}, fmt.Errorf("loading AWS config: %w", err)
+	}
+	k.cfg = &cfg
+	return cfg.Copy(), nil
+}
+
+func (k *keychain) now() time.Time {
+	if k.clock == nil {
+		return time.Now()
+	}
+	return k.clock()
+}
+
+// decodeToken turns an ECR authorization token, which is base64(user:password),
+// into an AuthConfig.
+func decodeToken(token string) (*authn.AuthConfig, error) {
+	decoded, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("decoding ECR authorization token: %w", err)
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return nil, errors.New("ECR authorization token must be formatted as base64(username:password)")
+	}
+	return &authn.AuthConfig{
+		Username: username,
+		Password: password,
+	}, nil
+}
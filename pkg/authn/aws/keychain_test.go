@@ -0,0 +1,128 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	ecrtypes "github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/aws/aws-sdk-go-v2/service/ecrpublic"
+	ecrpublictypes "github.com/aws/aws-sdk-go-v2/service/ecrpublic/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+type fakeECR struct {
+	region string
+	calls  *int
+}
+
+func (f fakeECR) GetAuthorizationToken(_ context.Context, in *ecr.GetAuthorizationTokenInput, _ ...func(*ecr.Options)) (*ecr.GetAuthorizationTokenOutput, error) {
+	*f.calls++
+	token := base64.StdEncoding.EncodeToString([]byte("AWS:" + in.RegistryIds[0] + "-" + f.region))
+	return &ecr.GetAuthorizationTokenOutput{
+		AuthorizationData: []ecrtypes.AuthorizationData{{
+			AuthorizationToken: awsv2.String(token),
+			ExpiresAt:          awsv2.Time(time.Now().Add(12 * time.Hour)),
+		}},
+	}, nil
+}
+
+type fakeECRPublic struct {
+	region string
+}
+
+func (f fakeECRPublic) GetAuthorizationToken(context.Context, *ecrpublic.GetAuthorizationTokenInput, ...func(*ecrpublic.Options)) (*ecrpublic.GetAuthorizationTokenOutput, error) {
+	token := base64.StdEncoding.EncodeToString([]byte("AWS:public-" + f.region))
+	return &ecrpublic.GetAuthorizationTokenOutput{
+		AuthorizationData: &ecrpublictypes.AuthorizationData{
+			AuthorizationToken: awsv2.String(token),
+			ExpiresAt:          awsv2.Time(time.Now().Add(12 * time.Hour)),
+		},
+	}, nil
+}
+
+func TestKeychain(t *testing.T) {
+	calls := 0
+	k := NewKeychain(WithConfig(awsv2.Config{})).(*keychain)
+	k.newECR = func(cfg awsv2.Config) ecrAPI { return fakeECR{region: cfg.Region, calls: &calls} }
+	k.newECRPublic = func(cfg awsv2.Config) ecrPublicAPI { return fakeECRPublic{region: cfg.Region} }
+
+	for _, tc := range []struct {
+		ref  string
+		want *authn.AuthConfig
+	}{{
+		ref:  "gcr.io/foo/bar",
+		want: &authn.AuthConfig{},
+	}, {
+		ref:  "123456789012.dkr.ecr.us-west-2.amazonaws.com/foo",
+		want: &authn.AuthConfig{Username: "AWS", Password: "123456789012-us-west-2"},
+	}, {
+		ref:  "123456789012.dkr.ecr-fips.us-gov-west-1.amazonaws.com/foo",
+		want: &authn.AuthConfig{Username: "AWS", Password: "123456789012-us-gov-west-1"},
+	}, {
+		ref:  "210987654321.dkr.ecr.cn-north-1.amazonaws.com.cn/foo",
+		want: &authn.AuthConfig{Username: "AWS", Password: "210987654321-cn-north-1"},
+	}, {
+		ref:  "public.ecr.aws/foo/bar",
+		want: &authn.AuthConfig{Username: "AWS", Password: "public-us-east-1"},
+	}, {
+		ref:  "123456789012.dkr.ecr.us-west-2.amazonaws.com.evil.com/foo",
+		want: &authn.AuthConfig{},
+	}} {
+		t.Run(tc.ref, func(t *testing.T) {
+			ref, err := name.ParseReference(tc.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			auth, err := k.Resolve(ref.Context())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := auth.Authorization()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Username != tc.want.Username || got.Password != tc.want.Password {
+				t.Errorf("Authorization() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+
+	// Tokens should be cached until they are close to expiring.
+	ref, err := name.NewRepository("123456789012.dkr.ecr.us-west-2.amazonaws.com/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := calls
+	if _, err := k.Resolve(ref); err != nil {
+		t.Fatal(err)
+	}
+	if calls != before {
+		t.Errorf("expected cached token, got %d new calls", calls-before)
+	}
+	k.clock = func() time.Time { return time.Now().Add(12 * time.Hour) }
+	if _, err := k.Resolve(ref); err != nil {
+		t.Fatal(err)
+	}
+	if calls != before+1 {
+		t.Errorf("expected refreshed token, got %d new calls", calls-before)
+	}
+}
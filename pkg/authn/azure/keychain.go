@@ -0,0 +1,305 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package azure provides a keychain for Azure Container Registry that
+// exchanges ambient Azure AD credentials for ACR refresh tokens.
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/logs"
+)
+
+const (
+	// acrUsername is the username ACR expects alongside a refresh token.
+	acrUsername = "00000000-0000-0000-0000-000000000000"
+
+	// armResource is the audience of the AAD token that ACR accepts in exchange
+	// for a refresh token.
+	armResource = "https://management.azure.com/"
+
+	defaultIMDSEndpoint  = "http://169.254.169.254/metadata/identity/oauth2/token"
+	defaultAuthorityHost = "https://login.microsoftonline.com/"
+
+	// ACR refresh tokens are valid for three hours, but the exchange response
+	// doesn't say so. Refresh well before then.
+	refreshTokenLifetime = 2 * time.Hour
+
+	// imdsTimeout bounds how long we wait for the instance metadata service,
+	// which doesn't exist outside of Azure.
+	imdsTimeout = 2 * time.Second
+
+	// noAADBackoff is how long we resolve to Anonymous without retrying after
+	// failing to get an Azure AD token, so that every Resolve off Azure doesn't
+	// wait for imdsTimeout.
+	noAADBackoff = 5 * time.Minute
+)
+
+var acrSuffixes = []string{
+	".azurecr.io",
+	".azurecr.cn",
+	".azurecr.de",
+	".azurecr.us",
+}
+
+// Keychain exports an instance of the ACR Keychain.
+//
+// This keychain matches on requests for *.azurecr.io (and the sovereign cloud
+// equivalents) and exchanges an Azure AD token for an ACR refresh token. The
+// Azure AD token comes from workload identity, if $AZURE_FEDERATED_TOKEN_FILE,
+// $AZURE_CLIENT_ID and $AZURE_TENANT_ID are set, otherwise from the managed
+// identity of the instance, using $AZURE_CLIENT_ID to select a user-assigned
+// identity if set.
+//
+// If no Azure AD token can be obtained, e.g. off Azure, the keychain logs a
+// warning, resolves to Anonymous and doesn't try again for five minutes.
+var Keychain authn.Keychain = &acrKeychain{
+	client:        http.DefaultClient,
+	imdsEndpoint:  defaultIMDSEndpoint,
+	scheme:        "https",
+	refreshTokens: map[string]refreshToken{},
+}
+
+type refreshToken struct {
+	token  string
+	expiry time.Time
+}
+
+type acrKeychain struct {
+	// mu guards refreshTokens and noAADUntil. It isn't held during network
+	// calls.
+	mu sync.Mutex
+
+	client       *http.Client
+	imdsEndpoint string
+	// scheme is used to talk to the registry's /oauth2/exchange endpoint.
+	scheme string
+
+	refreshTokens map[string]refreshToken
+
+	// noAADUntil is when we'll next try to get an Azure AD token after
+	// failing to.
+	noAADUntil time.Time
+}
+
+// Resolve implements authn.Keychain.
+func (k *acrKeychain) Resolve(r authn.Resource) (authn.Authenticator, error) {
+	return k.ResolveContext(context.Background(), r)
+}
+
+// ResolveContext implements authn.ContextKeychain.
+func (k *acrKeychain) ResolveContext(ctx context.Context, r authn.Resource) (authn.Authenticator, error) {
+	registry := r.RegistryStr()
+	if !isACR(registry) {
+		return authn.Anonymous, nil
+	}
+
+	k.mu.Lock()
+	rt, ok := k.refreshTokens[registry]
+	noAAD := time.Now().Before(k.noAADUntil)
+	k.mu.Unlock()
+	if ok && time.Now().Before(rt.expiry) {
+		return fromRefreshToken(rt.token), nil
+	}
+	if noAAD {
+		return authn.Anonymous, nil
+	}
+
+	// Don't hold the lock across network calls, so that callers for other
+	// registries, or with a cached token, don't wait on a slow one.
+	aadToken, tenant, err := k.aadToken(ctx)
+	if err != nil {
+		logs.Warn.Printf("azure: unable to get an Azure AD token for %s, falling back to anonymous for %v: %v", registry, noAADBackoff, err)
+		k.mu.Lock()
+		k.noAADUntil = time.Now().Add(noAADBackoff)
+		k.mu.Unlock()
+		return authn.Anonymous, nil
+	}
+
+	token, err := k.exchange(ctx, registry, tenant, aadToken)
+	if err != nil {
+		return nil, err
+	}
+	k.mu.Lock()
+	k.refreshTokens[registry] = refreshToken{
+		token:  token,
+		expiry: time.Now().Add(refreshTokenLifetime),
+	}
+	k.mu.Unlock()
+
+	return fromRefreshToken(token), nil
+}
+
+func isACR(registry string) bool {
+	host := registry
+	if i := strings.LastIndex(host, ":"); i != -1 {
+		host = host[:i]
+	}
+	for _, suffix := range acrSuffixes {
+		if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func fromRefreshToken(token string) authn.Authenticator {
+	return authn.FromConfig(authn.AuthConfig{
+		Username:      acrUsername,
+		IdentityToken: token,
+	})
+}
+
+type aadTokenResponse struct {
+	AccessToken string `json:"access_token"`
+}
+
+// aadToken returns an Azure AD access token for the ARM audience, along with
+// the tenant it was issued for, if known.
+func (k *acrKeychain) aadToken(ctx context.Context) (string, string, error) {
+	tokenFile := os.Getenv("AZURE_FEDERATED_TOKEN_FILE")
+	clientID := os.Getenv("AZURE_CLIENT_ID")
+	tenant := os.Getenv("AZURE_TENANT_ID")
+	if tokenFile != "" && clientID != "" && tenant != "" {
+		token, err := k.workloadIdentityToken(ctx, tokenFile, clientID, tenant)
+		return token, tenant, err
+	}
+
+	token, err := k.managedIdentityToken(ctx, clientID)
+	return token, tenant, err
+}
+
+// https://learn.microsoft.com/en-us/entra/identity-platform/v2-oauth2-client-creds-grant-flow#third-case-access-token-request-with-a-federated-credential
+func (k *acrKeychain) workloadIdentityToken(ctx context.Context, tokenFile, clientID, tenant string) (string, error) {
+	assertion, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", err
+	}
+
+	authority := os.Getenv("AZURE_AUTHORITY_HOST")
+	if authority == "" {
+		authority = defaultAuthorityHost
+	}
+	u, err := url.JoinPath(authority, tenant, "oauth2/v2.0/token")
+	if err != nil {
+		return "", err
+	}
+
+	v := url.Values{}
+	v.Set("grant_type", "client_credentials")
+	v.Set("client_id", clientID)
+	v.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+	v.Set("client_assertion", strings.TrimSpace(string(assertion)))
+	v.Set("scope", armResource+".default")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(v.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp aadTokenResponse
+	if err := k.do(req, &resp); err != nil {
+		return "", err
+	}
+	return resp.AccessToken, nil
+}
+
+// https://learn.microsoft.com/en-us/entra/identity/managed-identities-azure-resources/how-to-use-vm-token#get-a-token-using-http
+func (k *acrKeychain) managedIdentityToken(ctx context.Context, clientID string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, imdsTimeout)
+	defer cancel()
+
+	u, err := url.Parse(k.imdsEndpoint)
+	if err != nil {
+		return "", err
+	}
+	v := url.Values{}
+	v.Set("api-version", "2018-02-01")
+	v.Set("resource", armResource)
+	if clientID != "" {
+		v.Set("client_id", clientID)
+	}
+	u.RawQuery = v.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata", "true")
+
+	var resp aadTokenResponse
+	if err := k.do(req, &resp); err != nil {
+		return "", err
+	}
+	return resp.AccessToken, nil
+}
+
+// https://github.com/Azure/acr/blob/main/docs/AAD-OAuth.md#calling-post-oauth2exchange-to-get-an-acr-refresh-token
+func (k *acrKeychain) exchange(ctx context.Context, registry, tenant, aadToken string) (string, error) {
+	v := url.Values{}
+	v.Set("grant_type", "access_token")
+	v.Set("service", registry)
+	v.Set("access_token", aadToken)
+	if tenant != "" {
+		v.Set("tenant", tenant)
+	}
+
+	u := url.URL{Scheme: k.scheme, Host: registry, Path: "/oauth2/exchange"}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(v.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := k.do(req, &resp); err != nil {
+		return "", fmt.Errorf("exchanging Azure AD token for %s refresh token: %w", registry, err)
+	}
+	if resp.RefreshToken == "" {
+		return "", errors.New("no refresh_token in ACR exchange response")
+	}
+	return resp.RefreshToken, nil
+}
+
+func (k *acrKeychain) do(req *http.Request, v any) error {
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s: %s", resp.StatusCode, req.URL.Host, b)
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azure
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// redirect sends every request to the test server, regardless of host.
+type redirect struct {
+	target *url.URL
+}
+
+func (r redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = r.target.Scheme
+	req.URL.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newTestKeychain(t *testing.T, handler http.Handler) *acrKeychain {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &acrKeychain{
+		client:        &http.Client{Transport: redirect{u}},
+		imdsEndpoint:  defaultIMDSEndpoint,
+		scheme:        "http",
+		refreshTokens: map[string]refreshToken{},
+	}
+}
+
+func resolve(t *testing.T, kc authn.Keychain, ref string) *authn.AuthConfig {
+	t.Helper()
+	repo, err := name.NewRepository(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := kc.Resolve(repo)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	cfg, err := auth.Authorization()
+	if err != nil {
+		t.Fatalf("Authorization: %v", err)
+	}
+	return cfg
+}
+
+func exchangeHandler(t *testing.T, wantAADToken string, exchanges *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if got := r.Form.Get("access_token"); got != wantAADToken {
+			t.Errorf("access_token = %q, want %q", got, wantAADToken)
+		}
+		if got, want := r.Form.Get("service"), "example.azurecr.io"; got != want {
+			t.Errorf("service = %q, want %q", got, want)
+		}
+		*exchanges++
+		fmt.Fprintf(w, `{"refresh_token": "refresh-%d"}`, *exchanges)
+	}
+}
+
+// TestManagedIdentity checks that the keychain exchanges an IMDS token for an
+// ACR refresh token, and caches it.
+func TestManagedIdentity(t *testing.T) {
+	os.Unsetenv("AZURE_FEDERATED_TOKEN_FILE")
+	t.Setenv("AZURE_CLIENT_ID", "my-client")
+
+	exchanges := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metadata/identity/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" {
+			t.Errorf("missing Metadata header")
+		}
+		if got, want := r.URL.Query().Get("client_id"), "my-client"; got != want {
+			t.Errorf("client_id = %q, want %q", got, want)
+		}
+		fmt.Fprint(w, `{"access_token": "imds-token"}`)
+	})
+	mux.HandleFunc("/oauth2/exchange", exchangeHandler(t, "imds-token", &exchanges))
+	kc := newTestKeychain(t, mux)
+
+	for i := 0; i < 2; i++ {
+		got := resolve(t, kc, "example.azurecr.io/my/repo")
+		if got.Username != acrUsername {
+			t.Errorf("Got username %q, want %q", got.Username, acrUsername)
+		}
+		if got.IdentityToken != "refresh-1" {
+			t.Errorf("Got identity token %q, want refresh-1", got.IdentityToken)
+		}
+	}
+	if exchanges != 1 {
+		t.Errorf("Got %d exchanges, want 1", exchanges)
+	}
+}
+
+// TestWorkloadIdentity checks that the keychain uses a federated token when
+// workload identity is configured.
+func TestWorkloadIdentity(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("federated-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", tokenFile)
+	t.Setenv("AZURE_CLIENT_ID", "my-client")
+	t.Setenv("AZURE_TENANT_ID", "my-tenant")
+	t.Setenv("AZURE_AUTHORITY_HOST", "https://login.example.com/")
+
+	exchanges := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/my-tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := r.Form.Get("client_assertion"), "federated-token"; got != want {
+			t.Errorf("client_assertion = %q, want %q", got, want)
+		}
+		if got, want := r.Form.Get("client_id"), "my-client"; got != want {
+			t.Errorf("client_id = %q, want %q", got, want)
+		}
+		fmt.Fprint(w, `{"access_token": "aad-token"}`)
+	})
+	mux.HandleFunc("/oauth2/exchange", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.FormValue("tenant"), "my-tenant"; got != want {
+			t.Errorf("tenant = %q, want %q", got, want)
+		}
+		exchangeHandler(t, "aad-token", &exchanges)(w, r)
+	})
+	kc := newTestKeychain(t, mux)
+
+	if got := resolve(t, kc, "example.azurecr.io/my/repo"); got.IdentityToken != "refresh-1" {
+		t.Errorf("Got identity token %q, want refresh-1", got.IdentityToken)
+	}
+}
+
+// TestNoIdentity checks that the keychain resolves to Anonymous when no Azure
+// AD token is available, and doesn't ask again for every Resolve.
+func TestNoIdentity(t *testing.T) {
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "")
+	probes := 0
+	kc := newTestKeychain(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		probes++
+		http.NotFound(w, nil)
+	}))
+
+	for _, ref := range []string{"example.azurecr.io/my/repo", "example.azurecr.io/other", "other.azurecr.io/my/repo"} {
+		if got := resolve(t, kc, ref); *got != (authn.AuthConfig{}) {
+			t.Errorf("Got %+v, want anonymous", got)
+		}
+	}
+	if probes != 1 {
+		t.Errorf("Got %d IMDS requests, want 1", probes)
+	}
+}
+
+// TestCachedDuringSlowRequest checks that a slow token request for one
+// registry doesn't hold up registries that already have a token.
+func TestCachedDuringSlowRequest(t *testing.T) {
+	os.Unsetenv("AZURE_FEDERATED_TOKEN_FILE")
+	started, release := make(chan struct{}), make(chan struct{})
+	kc := newTestKeychain(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-release
+		http.NotFound(w, nil)
+	}))
+	kc.refreshTokens["example.azurecr.io"] = refreshToken{token: "cached", expiry: time.Now().Add(time.Hour)}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		kc.Resolve(mustRepo(t, "slow.azurecr.io/my/repo"))
+	}()
+	<-started
+
+	resolved := make(chan *authn.AuthConfig)
+	go func() { resolved <- resolve(t, kc, "example.azurecr.io/my/repo") }()
+	select {
+	case got := <-resolved:
+		if got.IdentityToken != "cached" {
+			t.Errorf("Got identity token %q, want cached", got.IdentityToken)
+		}
+	case <-time.After(time.Second):
+		t.Error("Resolve with a cached token waited on another registry")
+	}
+	close(release)
+	<-done
+}
+
+// TestNotACR checks that the keychain doesn't resolve for other registries.
+func TestNotACR(t *testing.T) {
+	for _, ref := range []string{
+		"gcr.io/my/repo",
+		"azurecr.io/my/repo",
+		"example.azurecr.io.evil.com/my/repo",
+	} {
+		got, err := Keychain.Resolve(mustRepo(t, ref))
+		if err != nil {
+			t.Fatalf("Resolve(%q): %v", ref, err)
+		}
+		if got != authn.Anonymous {
+			t.Errorf("Resolve(%q) = %v, want Anonymous", ref, got)
+		}
+	}
+}
+
+func mustRepo(t *testing.T, ref string) name.Repository {
+	t.Helper()
+	repo, err := name.NewRepository(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cache provides a cache of registry bearer tokens that can be shared
+// between transports, so that Pullers, Pushers and separate operations against
+// the same registry don't each perform their own token exchange.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+)
+
+// ExpiryDelta is the most a token is refreshed ahead of its expiry, so that
+// callers refresh tokens before registries start rejecting them. Short-lived
+// tokens are refreshed after 90% of their lifetime instead, so that a token
+// that lives for less than ExpiryDelta is still used.
+const ExpiryDelta = 10 * time.Second
+
+// RefreshTime returns when a token that expires at expiry, and was valid for
+// lifetime when it was issued, should be refreshed. A zero lifetime means it
+// isn't known, and the full ExpiryDelta is used. It returns the zero time if
+// expiry is zero, i.e. the token doesn't expire.
+func RefreshTime(expiry time.Time, lifetime time.Duration) time.Time {
+	if expiry.IsZero() {
+		return time.Time{}
+	}
+	if lifetime == 0 {
+		return expiry.Add(-ExpiryDelta)
+	}
+	return expiry.Add(-min(ExpiryDelta, lifetime/10))
+}
+
+// Key identifies a bearer token issued by a token service.
+type Key struct {
+	// Registry is the registry the token is sent to, e.g. "gcr.io".
+	Registry string
+	// Service is the service parameter of the token request.
+	Service string
+	// Scope is the sorted, space-separated list of scopes of the token.
+	Scope string
+	// Identity is a fingerprint of the credentials that were exchanged for the
+	// token, so that tokens are never shared between different credentials.
+	Identity string
+}
+
+// NewKey returns a Key for a token issued for the given registry, service and
+// scopes, in exchange for the credentials with the given Identity.
+func NewKey(registry, service string, scopes []string, identity string) Key {
+	sorted := append([]string{}, scopes...)
+	sort.Strings(sorted)
+	return Key{
+		Registry: registry,
+		Service:  service,
+		Scope:    strings.Join(sorted, " "),
+		Identity: identity,
+	}
+}
+
+// Identity returns a fingerprint of auth that doesn't reveal its secrets.
+func Identity(auth *authn.AuthConfig) string {
+	if auth == nil {
+		return ""
+	}
+	h := sha256.New()
+	for _, s := range []string{auth.Username, auth.Password, auth.Auth, auth.IdentityToken, auth.RegistryToken} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Token is a cached bearer token.
+type Token struct {
+	// Token is sent to the registry as a bearer token.
+	Token string
+	// RefreshToken, if set, can be exchanged for a new Token.
+	RefreshToken string
+	// Expiry is when Token stops being valid.
+	Expiry time.Time
+	// Lifetime is how long Token was valid for when it was issued, if known.
+	// It determines how early the cache stops returning Token, see
+	// RefreshTime.
+	Lifetime time.Duration
+}
+
+// Cache stores bearer tokens. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the token stored for key, if it isn't about to expire.
+	Get(key Key) (*Token, bool)
+
+	// Set stores tok for key.
+	Set(key Key, tok *Token)
+}
+
+// New returns an in-memory Cache.
+func New() Cache {
+	return &memory{tokens: map[Key]*Token{}}
+}
+
+type memory struct {
+	sync.Mutex
+	tokens map[Key]*Token
+
+	// for testing
+	clock func() time.Time
+}
+
+// Get implements Cache.
+func (m *memory) Get(key Key) (*Token, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	tok, ok := m.tokens[key]
+	if !ok {
+		return nil, false
+	}
+	if !m.now().Before(RefreshTime(tok.Expiry, tok.Lifetime)) {
+		delete(m.tokens, key)
+		return nil, false
+	}
+	return tok, true
+}
+
+// Set implements Cache.
+func (m *memory) Set(key Key, tok *Token) {
+	m.Lock()
+	defer m.Unlock()
+	m.tokens[key] = tok
+}
+
+func (m *memory) now() time.Time {
+	if m.clock == nil {
+		return time.Now()
+	}
+	return m.clock()
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+)
+
+func TestNewKey(t *testing.T) {
+	id := Identity(&authn.AuthConfig{Username: "foo", Password: "bar"})
+	a := NewKey("gcr.io", "gcr.io", []string{"repository:a:pull", "repository:b:pull"}, id)
+	b := NewKey("gcr.io", "gcr.io", []string{"repository:b:pull", "repository:a:pull"}, id)
+	if a != b {
+		t.Errorf("scope order should not matter: %v != %v", a, b)
+	}
+
+	other := Identity(&authn.AuthConfig{Username: "foo", Password: "baz"})
+	if id == other {
+		t.Errorf("different credentials should have different identities")
+	}
+	// Make sure we can't collide by moving characters between fields.
+	if Identity(&authn.AuthConfig{Username: "ab", Password: "c"}) == Identity(&authn.AuthConfig{Username: "a", Password: "bc"}) {
+		t.Errorf("identities should not collide")
+	}
+}
+
+func TestMemory(t *testing.T) {
+	now := time.Now()
+	c := New().(*memory)
+	c.clock = func() time.Time { return now }
+
+	key := NewKey("gcr.io", "gcr.io", []string{"repository:a:pull"}, "")
+	if _, ok := c.Get(key); ok {
+		t.Fatal("Get() on empty cache returned a token")
+	}
+
+	c.Set(key, &Token{Token: "foo", Expiry: now.Add(time.Minute)})
+	tok, ok := c.Get(key)
+	if !ok {
+		t.Fatal("Get() missed")
+	}
+	if tok.Token != "foo" {
+		t.Errorf("Get() = %q, want %q", tok.Token, "foo")
+	}
+
+	// Tokens that are about to expire are treated as a miss.
+	c.clock = func() time.Time { return now.Add(time.Minute - ExpiryDelta/2) }
+	if _, ok := c.Get(key); ok {
+		t.Error("Get() returned an expiring token")
+	}
+
+	// Tokens that live for less than ExpiryDelta are still returned for
+	// most of their lifetime.
+	c.clock = func() time.Time { return now }
+	c.Set(key, &Token{Token: "short", Expiry: now.Add(5 * time.Second), Lifetime: 5 * time.Second})
+	c.clock = func() time.Time { return now.Add(4 * time.Second) }
+	if _, ok := c.Get(key); !ok {
+		t.Error("Get() missed a short-lived token")
+	}
+	c.clock = func() time.Time { return now.Add(4600 * time.Millisecond) }
+	if _, ok := c.Get(key); ok {
+		t.Error("Get() returned an expiring short-lived token")
+	}
+}
+
+func TestRefreshTime(t *testing.T) {
+	expiry := time.Now()
+	for _, tc := range []struct {
+		lifetime time.Duration
+		want     time.Duration
+	}{
+		{5 * time.Second, 500 * time.Millisecond},
+		{10 * time.Second, time.Second},
+		{60 * time.Second, 6 * time.Second},
+		{300 * time.Second, ExpiryDelta},
+		{time.Hour, ExpiryDelta},
+	} {
+		if got := expiry.Sub(RefreshTime(expiry, tc.lifetime)); got != tc.want {
+			t.Errorf("RefreshTime(%v): refreshed %v early, want %v", tc.lifetime, got, tc.want)
+		}
+	}
+	if got := expiry.Sub(RefreshTime(expiry, 0)); got != ExpiryDelta {
+		t.Errorf("RefreshTime(0): refreshed %v early, want %v", got, ExpiryDelta)
+	}
+	if got := RefreshTime(time.Time{}, time.Minute); !got.IsZero() {
+		t.Errorf("RefreshTime(zero): got %v, want zero", got)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+type envKeychain struct {
+	prefix string
+}
+
+// Assert that our env keychain implements Keychain.
+var _ (Keychain) = (*envKeychain)(nil)
+
+// NewEnvKeychain returns a Keychain that reads credentials from environment
+// variables, which is convenient for CI systems where writing a Docker config
+// file is awkward.
+//
+// For a registry like "gcr.io" and a prefix of "CRANE", credentials are read
+// from CRANE_AUTH_GCR_IO_USERNAME and CRANE_AUTH_GCR_IO_PASSWORD. Any character
+// in the registry that is not a letter or a digit is replaced with "_", so
+// "localhost:5000" becomes CRANE_AUTH_LOCALHOST_5000_USERNAME.
+//
+// If those aren't set, CRANE_REGISTRY_AUTH may contain a JSON blob in the same
+// format as the "auths" section of a Docker config file, e.g.:
+//
+//	{"auths": {"gcr.io": {"username": "foo", "password": "bar"}}}
+//
+// If no credentials are found, the keychain resolves to Anonymous.
+func NewEnvKeychain(prefix string) Keychain {
+	return &envKeychain{prefix: prefix}
+}
+
+// Resolve implements Keychain.
+func (ek *envKeychain) Resolve(target Resource) (Authenticator, error) {
+	return ek.ResolveContext(context.Background(), target)
+}
+
+// ResolveContext implements ContextKeychain.
+func (ek *envKeychain) ResolveContext(_ context.Context, target Resource) (Authenticator, error) {
+	reg := envName(target.RegistryStr())
+	username := os.Getenv(ek.key("AUTH", reg, "USERNAME"))
+	password := os.Getenv(ek.key("AUTH", reg, "PASSWORD"))
+	if username != "" || password != "" {
+		return FromConfig(AuthConfig{Username: username, Password: password}), nil
+	}
+
+	blob := os.Getenv(ek.key("REGISTRY_AUTH"))
+	if blob == "" {
+		return Anonymous, nil
+	}
+
+	var cf struct {
+		Auths map[string]AuthConfig `json:"auths"`
+	}
+	if err := json.Unmarshal([]byte(blob), &cf); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ek.key("REGISTRY_AUTH"), err)
+	}
+
+	// Match the lookup order of the default keychain.
+	for _, key := range []string{
+		target.String(),
+		target.RegistryStr(),
+	} {
+		if key == name.DefaultRegistry {
+			key = DefaultAuthKey
+		}
+		if cfg, ok := cf.Auths[key]; ok {
+			return FromConfig(cfg), nil
+		}
+	}
+
+	return Anonymous, nil
+}
+
+// key joins the keychain's prefix and parts with "_".
+func (ek *envKeychain) key(parts ...string) string {
+	if ek.prefix != "" {
+		parts = append([]string{ek.prefix}, parts...)
+	}
+	return strings.Join(parts, "_")
+}
+
+// envName converts a registry hostname into a form that can be used as part of
+// an environment variable name.
+func envName(registry string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, registry)
+}
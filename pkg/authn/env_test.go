@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+func TestEnvKeychain(t *testing.T) {
+	localhost, err := name.NewRegistry("localhost:5000", name.WeakValidation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		desc    string
+		env     map[string]string
+		target  Resource
+		want    *AuthConfig
+		wantErr bool
+	}{{
+		desc:   "nothing set",
+		target: testRegistry,
+		want:   &AuthConfig{},
+	}, {
+		desc: "username and password",
+		env: map[string]string{
+			"CRANE_AUTH_TEST_IO_USERNAME": "foo",
+			"CRANE_AUTH_TEST_IO_PASSWORD": "bar",
+		},
+		target: testRepo,
+		want:   &AuthConfig{Username: "foo", Password: "bar"},
+	}, {
+		desc: "registry with port",
+		env: map[string]string{
+			"CRANE_AUTH_LOCALHOST_5000_USERNAME": "foo",
+			"CRANE_AUTH_LOCALHOST_5000_PASSWORD": "bar",
+		},
+		target: localhost,
+		want:   &AuthConfig{Username: "foo", Password: "bar"},
+	}, {
+		desc: "json blob",
+		env: map[string]string{
+			"CRANE_REGISTRY_AUTH": `{"auths": {"test.io": {"auth": "Zm9vOmJhcg=="}}}`,
+		},
+		target: testRepo,
+		want:   &AuthConfig{Username: "foo", Password: "bar", Auth: "Zm9vOmJhcg=="},
+	}, {
+		desc: "json blob repository match",
+		env: map[string]string{
+			"CRANE_REGISTRY_AUTH": `{"auths": {"test.io": {"username": "foo", "password": "bar"}, "test.io/my-repo": {"username": "baz", "password": "quux"}}}`,
+		},
+		target: testRepo,
+		want:   &AuthConfig{Username: "baz", Password: "quux", Auth: "YmF6OnF1dXg="},
+	}, {
+		desc: "json blob dockerhub",
+		env: map[string]string{
+			"CRANE_REGISTRY_AUTH": `{"auths": {"https://index.docker.io/v1/": {"username": "foo", "password": "bar"}}}`,
+		},
+		target: defaultRegistry,
+		want:   &AuthConfig{Username: "foo", Password: "bar", Auth: "Zm9vOmJhcg=="},
+	}, {
+		desc: "variables take precedence",
+		env: map[string]string{
+			"CRANE_AUTH_TEST_IO_USERNAME": "foo",
+			"CRANE_AUTH_TEST_IO_PASSWORD": "bar",
+			"CRANE_REGISTRY_AUTH":         `{"auths": {"test.io": {"username": "baz", "password": "quux"}}}`,
+		},
+		target: testRegistry,
+		want:   &AuthConfig{Username: "foo", Password: "bar"},
+	}, {
+		desc: "invalid json",
+		env: map[string]string{
+			"CRANE_REGISTRY_AUTH": `{`,
+		},
+		target:  testRegistry,
+		wantErr: true,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			auth, err := NewEnvKeychain("CRANE").Resolve(tc.target)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Resolve() = %v, wantErr: %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			got, err := auth.Authorization()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Authorization() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
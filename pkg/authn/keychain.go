@@ -90,7 +90,7 @@ func (dk *defaultKeychain) ResolveContext(_ context.Context, target Resource) (A
 
 	// Podman users may have their container registry auth configured in a
 	// different location, that Docker packages aren't aware of.
-	// If the Docker config file isn't found, we'll fallback to look where
+	// We look in the Docker config file first, then fall back to look where
 	// Podman configures it, and parse that as a Docker auth config instead.
 
 	// First, check $HOME/.docker/config.json
@@ -105,46 +105,80 @@ func (dk *defaultKeychain) ResolveContext(_ context.Context, target Resource) (A
 	}
 	// If either of those locations are found, load it using Docker's
 	// config.Load, which may fail if the config can't be parsed.
-	//
-	// If neither was found, look for Podman's auth at
-	// $REGISTRY_AUTH_FILE or $XDG_RUNTIME_DIR/containers/auth.json
-	// and attempt to load it as a Docker config.
-	//
-	// If neither are found, fallback to Anonymous.
-	var cf *configfile.ConfigFile
 	if foundDockerConfig {
-		cf, err = config.Load(os.Getenv("DOCKER_CONFIG"))
+		cf, err := config.Load(os.Getenv("DOCKER_CONFIG"))
 		if err != nil {
 			return nil, err
 		}
-	} else if fileExists(os.Getenv("REGISTRY_AUTH_FILE")) {
-		f, err := os.Open(os.Getenv("REGISTRY_AUTH_FILE"))
-		if err != nil {
-			return nil, err
+		if auth, err := resolveConfigFile(cf, target); err != nil || auth != Anonymous {
+			return auth, err
 		}
-		defer f.Close()
-		cf, err = config.LoadFromReader(f)
-		if err != nil {
-			return nil, err
+	}
+
+	// If the Docker config didn't have credentials for target, look for
+	// Podman's auth and attempt to load it as a Docker config.
+	//
+	// If none are found, fallback to Anonymous.
+	for _, p := range podmanAuthFiles(home) {
+		if !fileExists(p) {
+			continue
 		}
-	} else if fileExists(filepath.Join(os.Getenv("XDG_RUNTIME_DIR"), "containers/auth.json")) {
-		f, err := os.Open(filepath.Join(os.Getenv("XDG_RUNTIME_DIR"), "containers/auth.json"))
+		cf, err := loadConfigFile(p)
 		if err != nil {
 			return nil, err
 		}
-		defer f.Close()
-		cf, err = config.LoadFromReader(f)
-		if err != nil {
-			return nil, err
+		if auth, err := resolveConfigFile(cf, target); err != nil || auth != Anonymous {
+			return auth, err
 		}
-	} else {
-		return Anonymous, nil
 	}
 
+	return Anonymous, nil
+}
+
+// systemAuthFile is the system-wide containers auth file, a variable for testing.
+var systemAuthFile = "/etc/containers/auth.json"
+
+// podmanAuthFiles returns the paths where Podman (and other tools built on
+// containers/image) store credentials, in order of precedence.
+//
+// See: https://github.com/containers/image/blob/main/docs/containers-auth.json.5.md
+func podmanAuthFiles(home string) []string {
+	// $REGISTRY_AUTH_FILE overrides all the other locations.
+	if p := os.Getenv("REGISTRY_AUTH_FILE"); p != "" {
+		return []string{p}
+	}
+
+	paths := []string{}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		paths = append(paths, filepath.Join(dir, "containers/auth.json"))
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		paths = append(paths, filepath.Join(dir, "containers/auth.json"))
+	} else if home != "" {
+		paths = append(paths, filepath.Join(home, ".config/containers/auth.json"))
+	}
+	return append(paths, systemAuthFile)
+}
+
+func loadConfigFile(path string) (*configfile.ConfigFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return config.LoadFromReader(f)
+}
+
+// resolveConfigFile looks up the credentials for target in cf, returning
+// Anonymous if there are none.
+func resolveConfigFile(cf *configfile.ConfigFile, target Resource) (Authenticator, error) {
 	// See:
 	// https://github.com/google/ko/issues/90
 	// https://github.com/moby/moby/blob/fc01c2b481097a6057bec3cd1ab2d7b4488c50c4/registry/config.go#L397-L404
-	var cfg, empty types.AuthConfig
+	var (
+		cfg, empty types.AuthConfig
+		err        error
+	)
 	for _, key := range []string{
 		target.String(),
 		target.RegistryStr(),
@@ -201,6 +201,60 @@ func TestPodmanConfig(t *testing.T) {
 	}
 }
 
+func TestPodmanFallback(t *testing.T) {
+	tmpdir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", filepath.Join(tmpdir, "runtime"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpdir, "config"))
+	t.Setenv("REGISTRY_AUTH_FILE", "")
+
+	oldSystemAuthFile := systemAuthFile
+	systemAuthFile = filepath.Join(tmpdir, "etc", "auth.json")
+	defer func() { systemAuthFile = oldSystemAuthFile }()
+
+	// A Docker config without credentials for test.io shouldn't hide
+	// credentials configured for Podman.
+	cd := setupConfigFile(t, `{"auths": {"other.io": {"auth": "Zm9vOmJhcg=="}}}`)
+	defer os.RemoveAll(filepath.Dir(cd))
+
+	resolve := func() *AuthConfig {
+		t.Helper()
+		auth, err := DefaultKeychain.Resolve(testRegistry)
+		if err != nil {
+			t.Fatalf("Resolve() = %v", err)
+		}
+		got, err := auth.Authorization()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if got, want := resolve(), (&AuthConfig{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// /etc/containers/auth.json is consulted last.
+	writeConfig(t, filepath.Dir(systemAuthFile), "auth.json",
+		fmt.Sprintf(`{"auths": {"test.io": {"auth": %q}}}`, encode("system-foo", "system-bar")))
+	if got, want := resolve(), (&AuthConfig{Username: "system-foo", Password: "system-bar"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// $XDG_CONFIG_HOME/containers/auth.json is preferred over the system file.
+	writeConfig(t, filepath.Join(tmpdir, "config", "containers"), "auth.json",
+		fmt.Sprintf(`{"auths": {"test.io": {"auth": %q}}}`, encode("config-foo", "config-bar")))
+	if got, want := resolve(), (&AuthConfig{Username: "config-foo", Password: "config-bar"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// $XDG_RUNTIME_DIR/containers/auth.json is preferred over both.
+	writeConfig(t, filepath.Join(tmpdir, "runtime", "containers"), "auth.json",
+		fmt.Sprintf(`{"auths": {"test.io": {"auth": %q}}}`, encode("runtime-foo", "runtime-bar")))
+	if got, want := resolve(), (&AuthConfig{Username: "runtime-foo", Password: "runtime-bar"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
 func encode(user, pass string) string {
 	delimited := fmt.Sprintf("%s:%s", user, pass)
 	return base64.StdEncoding.EncodeToString([]byte(delimited))
@@ -14,7 +14,12 @@
 
 package authn
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/logs"
+)
 
 type multiKeychain struct {
 	keychains []Keychain
@@ -45,3 +50,101 @@ func (mk *multiKeychain) ResolveContext(ctx context.Context, target Resource) (A
 	}
 	return Anonymous, nil
 }
+
+type parallelKeychain struct {
+	keychains []Keychain
+	timeout   time.Duration
+}
+
+// Assert that our parallel keychain implements Keychain.
+var _ (Keychain) = (*parallelKeychain)(nil)
+
+// NewParallelKeychain is like NewMultiKeychain, but resolves credentials from
+// all keychains concurrently, giving each of them at most timeout to respond.
+//
+// Keychains keep the same precedence as in NewMultiKeychain: the result of an
+// earlier keychain is preferred, but a keychain that errors or doesn't respond
+// in time is skipped instead of stalling (or failing) resolution, so a single
+// hung credential helper doesn't block the others. If no keychain returns
+// credentials, the first error encountered is returned, if any.
+//
+// A timeout of zero or less means no timeout. Keychains that don't implement
+// ContextKeychain can't be interrupted, so they may keep running in the
+// background after they time out.
+func NewParallelKeychain(timeout time.Duration, kcs ...Keychain) Keychain {
+	return &parallelKeychain{keychains: kcs, timeout: timeout}
+}
+
+// Resolve implements Keychain.
+func (pk *parallelKeychain) Resolve(target Resource) (Authenticator, error) {
+	return pk.ResolveContext(context.Background(), target)
+}
+
+type keychainResult struct {
+	auth Authenticator
+	err  error
+}
+
+// ResolveContext implements ContextKeychain.
+func (pk *parallelKeychain) ResolveContext(ctx context.Context, target Resource) (Authenticator, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered so that abandoned goroutines don't leak forever.
+	results := make([]chan keychainResult, len(pk.keychains))
+	for i, kc := range pk.keychains {
+		results[i] = make(chan keychainResult, 1)
+		go func(kc Keychain, ch chan<- keychainResult) {
+			auth, err := Resolve(ctx, kc, target)
+			ch <- keychainResult{auth, err}
+		}(kc, results[i])
+	}
+
+	var deadline <-chan time.Time
+	if pk.timeout > 0 {
+		timer := time.NewTimer(pk.timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	var firstErr error
+	for i, ch := range results {
+		select {
+		case res := <-ch:
+			if res.err != nil {
+				logs.Debug.Printf("keychain %d failed to resolve %s: %v", i, target, res.err)
+				if firstErr == nil {
+					firstErr = res.err
+				}
+				continue
+			}
+			if res.auth != Anonymous {
+				return res.auth, nil
+			}
+		case <-deadline:
+			// Every keychain started at the same time, so once we hit the
+			// deadline, anything still running has timed out. Take whatever
+			// has already finished, in order.
+			for j := i; j < len(results); j++ {
+				select {
+				case res := <-results[j]:
+					if res.err == nil && res.auth != Anonymous {
+						return res.auth, nil
+					}
+				default:
+					logs.Warn.Printf("keychain %d timed out resolving %s after %s", j, target, pk.timeout)
+				}
+			}
+			if firstErr != nil {
+				return nil, firstErr
+			}
+			return Anonymous, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return Anonymous, nil
+}
@@ -15,7 +15,10 @@
 package authn
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 )
@@ -96,3 +99,114 @@ func (fk fixedKeychain) Resolve(target Resource) (Authenticator, error) {
 	}
 	return Anonymous, nil
 }
+
+func TestParallelKeychain(t *testing.T) {
+	one := &Basic{Username: "one", Password: "secret"}
+	two := &Basic{Username: "two", Password: "secret"}
+
+	regOne, _ := name.NewRegistry("one.gcr.io", name.StrictValidation)
+	regTwo, _ := name.NewRegistry("two.gcr.io", name.StrictValidation)
+	regThree, _ := name.NewRegistry("three.gcr.io", name.StrictValidation)
+
+	hung := hungKeychain{}
+	failing := errKeychain{errors.New("oops")}
+
+	tests := []struct {
+		name    string
+		reg     name.Registry
+		kc      Keychain
+		want    Authenticator
+		wantErr bool
+	}{{
+		name: "match first keychain",
+		reg:  regOne,
+		kc: NewParallelKeychain(time.Second,
+			fixedKeychain{regOne: one},
+			fixedKeychain{regOne: two},
+		),
+		want: one,
+	}, {
+		name: "earlier keychain wins even if slower",
+		reg:  regOne,
+		kc: NewParallelKeychain(time.Second,
+			slowKeychain{fixedKeychain{regOne: one}, 50 * time.Millisecond},
+			fixedKeychain{regOne: two},
+		),
+		want: one,
+	}, {
+		name: "skip hung keychain",
+		reg:  regTwo,
+		kc: NewParallelKeychain(50*time.Millisecond,
+			hung,
+			fixedKeychain{regTwo: two},
+		),
+		want: two,
+	}, {
+		name: "skip failing keychain",
+		reg:  regTwo,
+		kc: NewParallelKeychain(time.Second,
+			failing,
+			fixedKeychain{regTwo: two},
+		),
+		want: two,
+	}, {
+		name: "match no keychain",
+		reg:  regThree,
+		kc: NewParallelKeychain(50*time.Millisecond,
+			hung,
+			fixedKeychain{regOne: one},
+		),
+		want: Anonymous,
+	}, {
+		name: "error if nothing matches",
+		reg:  regThree,
+		kc: NewParallelKeychain(time.Second,
+			failing,
+			fixedKeychain{regOne: one},
+		),
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.kc.Resolve(test.reg)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Resolve() = %v, wantErr %t", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("Resolve() = %v, wanted %v", got, test.want)
+			}
+		})
+	}
+}
+
+type hungKeychain struct{}
+
+// ResolveContext blocks until ctx is cancelled.
+func (hungKeychain) ResolveContext(ctx context.Context, _ Resource) (Authenticator, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// Resolve implements Keychain.
+func (hk hungKeychain) Resolve(target Resource) (Authenticator, error) {
+	return hk.ResolveContext(context.Background(), target)
+}
+
+type slowKeychain struct {
+	Keychain
+	delay time.Duration
+}
+
+// Resolve implements Keychain.
+func (sk slowKeychain) Resolve(target Resource) (Authenticator, error) {
+	time.Sleep(sk.delay)
+	return sk.Keychain.Resolve(target)
+}
+
+type errKeychain struct{ err error }
+
+// Resolve implements Keychain.
+func (ek errKeychain) Resolve(Resource) (Authenticator, error) {
+	return nil, ek.err
+}
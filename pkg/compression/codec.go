@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultLevel selects a codec's default compression level.
+const DefaultLevel = -1
+
+// Codec reads and writes a compression format.
+type Codec interface {
+	// Compression is the name the codec is registered as.
+	Compression() Compression
+
+	// Format is the format the codec reads and writes, which determines
+	// the media types of the layers it compresses. Codecs that are
+	// alternative implementations of a format, e.g. PGZip, return that
+	// format rather than their own name.
+	Format() Compression
+
+	// Magic is the header that identifies the format, or nil for None.
+	Magic() []byte
+
+	// NewReader returns a reader of the decompressed contents of r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+
+	// NewWriter returns a writer that compresses what's written to it into
+	// w at the given codec-specific level, or DefaultLevel. Closing it
+	// flushes it, but doesn't close w.
+	NewWriter(w io.Writer, level int) (io.WriteCloser, error)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[Compression]Codec{}
+)
+
+// Register makes a codec available by its name, for Lookup, and by its
+// magic header, for detecting compressed layers. It panics if a codec with
+// the same name is already registered.
+func Register(c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	if c == nil {
+		panic("compression: Register codec is nil")
+	}
+	if _, dup := codecs[c.Compression()]; dup {
+		panic(fmt.Sprintf("compression: Register called twice for %s", c.Compression()))
+	}
+	codecs[c.Compression()] = c
+}
+
+// Lookup returns the codec registered as c.
+func Lookup(c Compression) (Codec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	if codec, ok := codecs[c]; ok {
+		return codec, nil
+	}
+	return nil, fmt.Errorf("unknown compression %q", c)
+}
+
+// Codecs returns the registered codecs, sorted by name.
+func Codecs() []Codec {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	cs := make([]Codec, 0, len(codecs))
+	for _, c := range codecs {
+		cs = append(cs, c)
+	}
+	slices.SortFunc(cs, func(a, b Codec) int {
+		return strings.Compare(string(a.Compression()), string(b.Compression()))
+	})
+	return cs
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/compression"
+)
+
+func TestCodecs(t *testing.T) {
+	want := bytes.Repeat([]byte("compress me "), 1000)
+	for _, c := range compression.Codecs() {
+		t.Run(string(c.Compression()), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := c.NewWriter(&buf, compression.DefaultLevel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write(want); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(buf.Bytes(), c.Magic()) {
+				t.Errorf("output doesn't start with %x", c.Magic())
+			}
+
+			r, err := c.NewReader(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Error("round trip changed the contents")
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, want := range []compression.Compression{compression.None, compression.GZip, compression.ZStd} {
+		c, err := compression.Lookup(want)
+		if err != nil {
+			t.Fatalf("Lookup(%s) = %v", want, err)
+		}
+		if c.Compression() != want {
+			t.Errorf("Lookup(%s).Compression() = %s", want, c.Compression())
+		}
+	}
+	if c, err := compression.Lookup("lz4"); err == nil {
+		t.Errorf("Lookup(lz4) = %v, want error", c)
+	}
+	if c, err := compression.Lookup(compression.PGZip); err == nil {
+		t.Errorf("Lookup(pgzip) without importing pkg/compression/pgzip = %v, want error", c)
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	c, err := compression.Lookup(compression.GZip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() didn't panic on a duplicate")
+		}
+	}()
+	compression.Register(c)
+}
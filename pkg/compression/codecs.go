@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"compress/gzip"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/compression/zstd"
+)
+
+func init() {
+	Register(noneCodec{})
+	Register(gzipCodec{})
+	Register(zstdCodec{})
+}
+
+type noneCodec struct{}
+
+func (noneCodec) Compression() Compression { return None }
+func (noneCodec) Format() Compression      { return None }
+func (noneCodec) Magic() []byte            { return nil }
+
+func (noneCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(r), nil
+}
+
+func (noneCodec) NewWriter(w io.Writer, _ int) (io.WriteCloser, error) {
+	return nopWriteCloser{w}, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+var gzipMagic = []byte{'\x1f', '\x8b'}
+
+type gzipCodec struct{}
+
+func (gzipCodec) Compression() Compression { return GZip }
+func (gzipCodec) Format() Compression      { return GZip }
+func (gzipCodec) Magic() []byte            { return gzipMagic }
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+func (gzipCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, level)
+}
+
+type zstdCodec struct{}
+
+func (zstdCodec) Compression() Compression { return ZStd }
+func (zstdCodec) Format() Compression      { return ZStd }
+func (zstdCodec) Magic() []byte            { return zstd.MagicHeader }
+
+func (zstdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zstd.UnzipReadCloser(io.NopCloser(r))
+}
+
+func (zstdCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level == DefaultLevel {
+		// zstd's own default, rather than the fastest level.
+		level = 3
+	}
+	return zstd.WriteCloserLevel(w, level)
+}
@@ -12,7 +12,8 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package compression abstracts over gzip and zstd.
+// Package compression abstracts over compression formats, e.g. gzip and
+// zstd, with a registry of codecs that implement them.
 package compression
 
 // Compression is an enumeration of the supported compression algorithms
@@ -23,4 +24,8 @@ const (
 	None Compression = "none"
 	GZip Compression = "gzip"
 	ZStd Compression = "zstd"
+
+	// PGZip is gzip, compressed in parallel. Its codec is only registered
+	// by importing pkg/compression/pgzip.
+	PGZip Compression = "pgzip"
 )
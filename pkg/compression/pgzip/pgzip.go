@@ -0,0 +1,46 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pgzip registers the compression.PGZip codec, which writes gzip,
+// compressing blocks in parallel. That's faster for large layers, at the
+// cost of memory and of a dependency that most users of compression don't
+// need, so it's only available to programs that import this package:
+//
+//	import _ "github.com/google/go-containerregistry/pkg/compression/pgzip"
+package pgzip
+
+import (
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/compression"
+	"github.com/klauspost/pgzip"
+)
+
+func init() {
+	compression.Register(codec{})
+}
+
+type codec struct{}
+
+func (codec) Compression() compression.Compression { return compression.PGZip }
+func (codec) Format() compression.Compression      { return compression.GZip }
+func (codec) Magic() []byte                        { return []byte{'\x1f', '\x8b'} }
+
+func (codec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return pgzip.NewReader(r)
+}
+
+func (codec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	return pgzip.NewWriterLevel(w, level)
+}
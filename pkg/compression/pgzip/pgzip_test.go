@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgzip_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/compression"
+	_ "github.com/google/go-containerregistry/pkg/compression/pgzip"
+)
+
+func TestCodec(t *testing.T) {
+	c, err := compression.Lookup(compression.PGZip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Format() != compression.GZip {
+		t.Errorf("Format() = %s, want gzip", c.Format())
+	}
+
+	want := bytes.Repeat([]byte("hello pgzip "), 1<<12)
+	var buf bytes.Buffer
+	w, err := c.NewWriter(&buf, compression.DefaultLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(want); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// What it writes is plain gzip.
+	zr, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("gzip.NewReader() read something other than what was written")
+	}
+}
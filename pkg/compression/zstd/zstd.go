@@ -12,12 +12,15 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package zstd provides helper functions for interacting with zstd streams.
+// Package zstd provides helper functions for interacting with zstd streams,
+// which behave like the ones this module uses for its own zstd layers, so
+// that other layer implementations can match it.
 package zstd
 
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"io"
 
 	"github.com/google/go-containerregistry/internal/and"
@@ -82,6 +85,19 @@ func ReadCloserLevel(r io.ReadCloser, level int) io.ReadCloser {
 	return pr
 }
 
+// WriteCloser returns an io.WriteCloser that compresses what's written to it
+// into w. This uses zstd level 1 for the compression. Closing it flushes it
+// and writes the zstd trailers, but doesn't close w.
+func WriteCloser(w io.Writer) (io.WriteCloser, error) {
+	return WriteCloserLevel(w, 1)
+}
+
+// WriteCloserLevel is like WriteCloser, but compresses at the given zstd
+// level, from 1 (fastest) to 22 (smallest).
+func WriteCloserLevel(w io.Writer, level int) (io.WriteCloser, error) {
+	return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+}
+
 // UnzipReadCloser reads compressed input data from the io.ReadCloser and
 // returns an io.ReadCloser from which uncompressed data may be read.
 func UnzipReadCloser(r io.ReadCloser) (io.ReadCloser, error) {
@@ -114,3 +130,28 @@ func Is(r io.Reader) (bool, error) {
 	}
 	return bytes.Equal(magicHeader, MagicHeader), nil
 }
+
+// PeekReader is an io.Reader that also implements Peek a la bufio.Reader.
+type PeekReader interface {
+	io.Reader
+	Peek(n int) ([]byte, error)
+}
+
+// Peek detects whether the input stream is compressed without consuming it.
+//
+// If r implements Peek, it is used directly. Otherwise, the header is
+// buffered, and the returned PeekReader must be read instead of r.
+func Peek(r io.Reader) (bool, PeekReader, error) {
+	pr, ok := r.(PeekReader)
+	if !ok {
+		pr = bufio.NewReader(r)
+	}
+	header, err := pr.Peek(len(MagicHeader))
+	if errors.Is(err, io.EOF) {
+		return false, pr, nil
+	}
+	if err != nil {
+		return false, pr, err
+	}
+	return bytes.Equal(header, MagicHeader), pr, nil
+}
@@ -94,3 +94,63 @@ func TestReadErrors(t *testing.T) {
 		t.Error("ReadCloser: expected errRead, got", err)
 	}
 }
+
+func TestWriteCloser(t *testing.T) {
+	want := "This is the input string."
+	var buf bytes.Buffer
+	w, err := WriteCloserLevel(&buf, 19)
+	if err != nil {
+		t.Fatal("WriteCloserLevel() =", err)
+	}
+	if _, err := io.WriteString(w, want); err != nil {
+		t.Fatal("WriteString() =", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("Close() =", err)
+	}
+
+	unzipped, err := UnzipReadCloser(io.NopCloser(&buf))
+	if err != nil {
+		t.Fatal("UnzipReadCloser() =", err)
+	}
+	defer unzipped.Close()
+	b, err := io.ReadAll(unzipped)
+	if err != nil {
+		t.Fatal("ReadAll() =", err)
+	}
+	if got := string(b); got != want {
+		t.Errorf("ReadAll(); got %q, want %q", got, want)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	for _, test := range []struct {
+		in  []byte
+		out bool
+	}{
+		{[]byte{}, false},
+		{[]byte{'\x28', '\xb5'}, false},
+		{[]byte{'\x00', '\x00', '\x00', '\x00', '\x00'}, false},
+		{[]byte{'\x28', '\xb5', '\x2f', '\xfd', '\x1b'}, true},
+	} {
+		got, pr, err := Peek(bytes.NewReader(test.in))
+		if err != nil {
+			t.Fatal("Peek() =", err)
+		}
+		if got != test.out {
+			t.Errorf("Peek(%v); got %v, wanted %v", test.in, got, test.out)
+		}
+		// Peeking must not consume the input.
+		b, err := io.ReadAll(pr)
+		if err != nil {
+			t.Fatal("ReadAll() =", err)
+		}
+		if !bytes.Equal(b, test.in) {
+			t.Errorf("ReadAll(); got %v, wanted %v", b, test.in)
+		}
+	}
+
+	if _, _, err := Peek(failReader{}); err != errRead {
+		t.Error("Peek: expected errRead, got", err)
+	}
+}
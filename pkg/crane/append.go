@@ -36,6 +36,18 @@ func isWindows(img v1.Image) (bool, error) {
 	return cfg != nil && cfg.OS == "windows", nil
 }
 
+// baseLayerType returns the media type of layers appended to base.
+func baseLayerType(base v1.Image) (types.MediaType, error) {
+	baseMediaType, err := base.MediaType()
+	if err != nil {
+		return "", fmt.Errorf("getting base image media type: %w", err)
+	}
+	if baseMediaType == types.OCIManifestSchema1 {
+		return types.OCILayer, nil
+	}
+	return types.DockerLayer, nil
+}
+
 // Append reads a layer from path and appends it the the v1.Image base.
 //
 // If the base image is a Windows base image (i.e., its config.OS is
@@ -51,14 +63,9 @@ func Append(base v1.Image, paths ...string) (v1.Image, error) {
 		return nil, fmt.Errorf("getting base image: %w", err)
 	}
 
-	baseMediaType, err := base.MediaType()
+	layerType, err := baseLayerType(base)
 	if err != nil {
-		return nil, fmt.Errorf("getting base image media type: %w", err)
-	}
-
-	layerType := types.DockerLayer
-	if baseMediaType == types.OCIManifestSchema1 {
-		layerType = types.OCILayer
+		return nil, err
 	}
 
 	layers := make([]v1.Layer, 0, len(paths))
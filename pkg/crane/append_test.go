@@ -15,7 +15,12 @@
 package crane_test
 
 import (
+	"archive/tar"
+	"errors"
+	"io"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
@@ -96,3 +101,100 @@ func TestAppendWithZstd(t *testing.T) {
 		t.Errorf("MediaType(): want %q, got %q", want, got)
 	}
 }
+
+func TestAppendFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bin/app":        {Data: []byte("app"), Mode: 0o700},
+		"etc/app.conf":   {Data: []byte("conf"), Mode: 0o600},
+		"etc/app.conf~":  {Data: []byte("backup")},
+		".git/HEAD":      {Data: []byte("ref")},
+		"docs/README.md": {Data: []byte("docs")},
+	}
+	mtime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	opts := []crane.Option{
+		crane.WithExclude(".git", "*~", "docs/*"),
+		crane.WithOwner(1000, 1001),
+		crane.WithMode(0o644, 0o755),
+		crane.WithModTime(mtime),
+		crane.WithDestination("/opt"),
+	}
+	img, err := crane.AppendFS(base, fsys, opts...)
+	if err != nil {
+		t.Fatalf("crane.AppendFS(): %v", err)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatalf("img.Layers(): %v", err)
+	}
+	if got, want := len(layers), 1; got != want {
+		t.Fatalf("len(layers): want %d, got %d", want, got)
+	}
+	if mt, err := layers[0].MediaType(); err != nil {
+		t.Fatal(err)
+	} else if got, want := mt, types.OCILayer; got != want {
+		t.Errorf("MediaType(): want %q, got %q", want, got)
+	}
+
+	rc, err := layers[0].Uncompressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	tr := tar.NewReader(rc)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		if hdr.Uid != 1000 || hdr.Gid != 1001 {
+			t.Errorf("%s: owner %d:%d, want 1000:1001", hdr.Name, hdr.Uid, hdr.Gid)
+		}
+		if !hdr.ModTime.Equal(mtime) {
+			t.Errorf("%s: mtime %v, want %v", hdr.Name, hdr.ModTime, mtime)
+		}
+		want := int64(0o644)
+		if hdr.Typeflag == tar.TypeDir {
+			want = 0o755
+		}
+		if hdr.Mode != want {
+			t.Errorf("%s: mode %o, want %o", hdr.Name, hdr.Mode, want)
+		}
+	}
+	want := []string{"opt/bin/", "opt/bin/app", "opt/docs/", "opt/etc/", "opt/etc/app.conf"}
+	if len(names) != len(want) {
+		t.Fatalf("files: want %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("files: want %v, got %v", want, names)
+			break
+		}
+	}
+
+	// The same contents make the same layer.
+	again, err := crane.AppendFS(base, fsys, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d1, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := again.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d1 != d2 {
+		t.Errorf("digests differ: %s != %s", d1, d2)
+	}
+
+	if _, err := crane.AppendFS(base, fsys, crane.WithExclude("[")); err == nil {
+		t.Error("crane.AppendFS() with a bad pattern: want error, got nil")
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/internal/windows"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// AppendFS appends a layer holding the files in fsys to the v1.Image base,
+// without writing a tarball to disk first. Use WithExclude, WithOwner,
+// WithMode, WithModTime and WithDestination to control what goes in the
+// layer and how.
+//
+// fsys is walked each time the layer is read, so it mustn't change until
+// the image has been written. Symbolic links are only supported if fsys
+// has a ReadLink method, like fs.ReadLinkFS. Reading the layer fails once
+// WithContext's context is done.
+//
+// As with Append, if base is a Windows image, the layer is modified to be
+// suitable for a Windows container image.
+func AppendFS(base v1.Image, fsys fs.FS, opt ...Option) (v1.Image, error) {
+	if base == nil {
+		return nil, fmt.Errorf("invalid argument: base")
+	}
+	o := makeOptions(opt...)
+
+	win, err := isWindows(base)
+	if err != nil {
+		return nil, fmt.Errorf("getting base image: %w", err)
+	}
+	layerType, err := baseLayerType(base)
+	if err != nil {
+		return nil, err
+	}
+
+	layer, err := fsLayer(o.ctx, fsys, o.fs, layerType)
+	if err != nil {
+		return nil, err
+	}
+	if win {
+		layer, err = windows.Windows(layer)
+		if err != nil {
+			return nil, fmt.Errorf("converting for Windows: %w", err)
+		}
+	}
+	return mutate.AppendLayers(base, layer)
+}
+
+func fsLayer(ctx context.Context, fsys fs.FS, o fsOptions, layerType types.MediaType) (v1.Layer, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	return tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(writeFS(ctx, pw, fsys, o))
+		}()
+		return pr, nil
+	}, tarball.WithMediaType(layerType))
+}
+
+// validate checks that the include and exclude patterns are well-formed.
+func (o fsOptions) validate() error {
+	for _, p := range slices.Concat(o.exclude, o.include) {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// writeFS writes the files in fsys to w as a tar, in lexical order,
+// until ctx is done.
+func writeFS(ctx context.Context, w io.Writer, fsys fs.FS, o fsOptions) error {
+	tw := tar.NewWriter(w)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if p == "." {
+			return nil
+		}
+		if o.excludes(p) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := o.header(fsys, p, info)
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil
+		}
+		f, err := fsys.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, &ctxReader{ctx, f})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+func (o fsOptions) excludes(p string) bool {
+	return matchAny(o.exclude, p)
+}
+
+// matchAny reports whether p matches any of patterns, as WithExclude
+// describes.
+func matchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		name := p
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(p)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// header returns the tar header for the file at p in fsys.
+func (o fsOptions) header(fsys fs.FS, p string, info fs.FileInfo) (*tar.Header, error) {
+	hdr := &tar.Header{
+		Name:    path.Join(strings.TrimPrefix(o.dest, "/"), p),
+		Mode:    int64(info.Mode().Perm()),
+		ModTime: info.ModTime(),
+	}
+	switch mode := info.Mode(); {
+	case mode.IsRegular():
+		hdr.Typeflag = tar.TypeReg
+		hdr.Size = info.Size()
+		if o.fileMode != 0 {
+			hdr.Mode = int64(o.fileMode)
+		}
+	case mode.IsDir():
+		hdr.Typeflag = tar.TypeDir
+		hdr.Name += "/"
+		if o.dirMode != 0 {
+			hdr.Mode = int64(o.dirMode)
+		}
+	case mode&fs.ModeSymlink != 0:
+		rl, ok := fsys.(interface{ ReadLink(string) (string, error) })
+		if !ok {
+			return nil, &fs.PathError{Op: "readlink", Path: p, Err: errors.ErrUnsupported}
+		}
+		target, err := rl.ReadLink(p)
+		if err != nil {
+			return nil, err
+		}
+		hdr.Typeflag = tar.TypeSymlink
+		hdr.Linkname = target
+	default:
+		return nil, fmt.Errorf("%s: unsupported file type %s", p, mode.Type())
+	}
+	if o.owner != nil {
+		hdr.Uid, hdr.Gid = o.owner[0], o.owner[1]
+	}
+	if o.modTime != nil {
+		hdr.ModTime = *o.modTime
+	}
+	return hdr, nil
+}
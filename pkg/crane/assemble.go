@@ -0,0 +1,199 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// AssembleIndex builds an index of per-platform images and pushes it, and
+// the images, to dst, returning the digest of the index.
+//
+// sources maps each platform, e.g. "linux/arm64/v8", to where its image is:
+// an OCI image layout directory, a tarball as written by Save or "docker
+// save", or a reference. An index, whether in a registry or a layout, is
+// resolved to its image for the platform.
+//
+// Each image's descriptor has the platform it's keyed by, with the variant,
+// OS version and OS features filled in from its config file if the key
+// leaves them out. It's an error for the OS or architecture of the config
+// file to disagree with the key.
+//
+// The index is a Docker manifest list if every image is a Docker image and
+// no annotations are set with WithIndexAnnotations, and an OCI image index
+// otherwise.
+func AssembleIndex(dst string, sources map[string]string, opt ...Option) (string, error) {
+	o := makeOptions(opt...)
+	ref, err := name.ParseReference(dst, o.Name...)
+	if err != nil {
+		return "", fmt.Errorf("parsing reference %q: %w", dst, err)
+	}
+
+	// Sort by platform, so that the same sources make the same index.
+	keys := make([]string, 0, len(sources))
+	for k := range sources {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	docker := len(o.annotations) == 0
+	adds := make([]mutate.IndexAddendum, 0, len(keys))
+	for _, k := range keys {
+		p, err := v1.ParsePlatform(k)
+		if err != nil {
+			return "", fmt.Errorf("parsing platform %q: %w", k, err)
+		}
+		src := sources[k]
+		img, err := o.loadPlatform(src, *p)
+		if err != nil {
+			return "", fmt.Errorf("loading %s for %s: %w", src, k, err)
+		}
+		desc, err := partial.Descriptor(img)
+		if err != nil {
+			return "", err
+		}
+		if desc.Platform, err = platformFor(img, *p); err != nil {
+			return "", fmt.Errorf("%s for %s: %w", src, k, err)
+		}
+		docker = docker && desc.MediaType == types.DockerManifestSchema2
+		adds = append(adds, mutate.IndexAddendum{Add: img, Descriptor: *desc})
+	}
+
+	var idx v1.ImageIndex = empty.Index
+	if docker {
+		idx = mutate.IndexMediaType(idx, types.DockerManifestList)
+	}
+	idx = mutate.AppendManifests(idx, adds...)
+	if len(o.annotations) != 0 {
+		idx = mutate.Annotations(idx, o.annotations).(v1.ImageIndex)
+	}
+	if err := remote.WriteIndex(ref, idx, o.Remote...); err != nil {
+		return "", fmt.Errorf("pushing index %s: %w", dst, err)
+	}
+	d, err := idx.Digest()
+	if err != nil {
+		return "", err
+	}
+	return d.String(), nil
+}
+
+// loadPlatform returns the image for p from src, which is a layout, a
+// tarball or a reference.
+func (o *Options) loadPlatform(src string, p v1.Platform) (v1.Image, error) {
+	if fi, err := os.Stat(src); err == nil {
+		if !fi.IsDir() {
+			return tarball.ImageFromPath(src, nil)
+		}
+		idx, err := layout.ImageIndexFromPath(src)
+		if err != nil {
+			return nil, err
+		}
+		return imageForPlatform(idx, p)
+	}
+	ref, err := name.ParseReference(src, o.Name...)
+	if err != nil {
+		return nil, fmt.Errorf("%q is neither a file nor a reference: %w", src, err)
+	}
+	img, err := remote.Image(ref, append(slices.Clip(o.Remote), remote.WithPlatform(p))...)
+	if err != nil {
+		return nil, err
+	}
+	return o.cachedImage(img), nil
+}
+
+// imageForPlatform returns the image in idx, or the indexes it refers to,
+// whose descriptor satisfies p, or its only image if none of them say.
+func imageForPlatform(idx v1.ImageIndex, p v1.Platform) (v1.Image, error) {
+	var all []v1.Image
+	var matches []v1.Image
+	var walk func(idx v1.ImageIndex) error
+	walk = func(idx v1.ImageIndex) error {
+		im, err := idx.IndexManifest()
+		if err != nil {
+			return err
+		}
+		for _, desc := range im.Manifests {
+			switch {
+			case desc.MediaType.IsIndex():
+				child, err := idx.ImageIndex(desc.Digest)
+				if err != nil {
+					return err
+				}
+				if err := walk(child); err != nil {
+					return err
+				}
+			case desc.MediaType.IsImage():
+				img, err := idx.Image(desc.Digest)
+				if err != nil {
+					return err
+				}
+				all = append(all, img)
+				if desc.Platform != nil && desc.Platform.Satisfies(p) {
+					matches = append(matches, img)
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(idx); err != nil {
+		return nil, err
+	}
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) > 1:
+		return nil, fmt.Errorf("%d images match platform %s", len(matches), p)
+	case len(all) == 1:
+		return all[0], nil
+	}
+	return nil, fmt.Errorf("no image for platform %s", p)
+}
+
+// platformFor returns p, with anything it leaves out that img's config file
+// has filled in.
+func platformFor(img v1.Image, p v1.Platform) (*v1.Platform, error) {
+	cf, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	if cf.OS != "" && cf.OS != p.OS {
+		return nil, fmt.Errorf("image is for OS %q", cf.OS)
+	}
+	if cf.Architecture != "" && cf.Architecture != p.Architecture {
+		return nil, fmt.Errorf("image is for architecture %q", cf.Architecture)
+	}
+	if p.Variant == "" {
+		p.Variant = cf.Variant
+	}
+	if p.OSVersion == "" {
+		p.OSVersion = cf.OSVersion
+	}
+	if len(p.OSFeatures) == 0 {
+		p.OSFeatures = cf.OSFeatures
+	}
+	return &p, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func platformImage(t *testing.T, os, arch, variant string) v1.Image {
+	t.Helper()
+	img, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf = cf.DeepCopy()
+	cf.OS, cf.Architecture, cf.Variant = os, arch, variant
+	img, err = mutate.ConfigFile(img, cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestAssembleIndex(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	// linux/amd64 from a tarball.
+	tarPath := filepath.Join(dir, "amd64.tar")
+	if err := crane.Save(platformImage(t, "linux", "amd64", ""), "example.com/app:amd64", tarPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// linux/arm64 from a layout that has other platforms too.
+	layoutPath := filepath.Join(dir, "layout")
+	p, err := layout.Write(layoutPath, empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pl := range []v1.Platform{{OS: "linux", Architecture: "arm64", Variant: "v8"}, {OS: "linux", Architecture: "s390x"}} {
+		if err := p.AppendImage(platformImage(t, pl.OS, pl.Architecture, pl.Variant), layout.WithPlatform(pl)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// linux/riscv64 from an index in a registry.
+	riscv := platformImage(t, "linux", "riscv64", "")
+	src := u.Host + "/src:latest"
+	tag, err := name.NewTag(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(tag, mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: platformImage(t, "linux", "amd64", ""), Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: riscv, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "riscv64"}}},
+	)); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := u.Host + "/app:latest"
+	sources := map[string]string{
+		"linux/amd64":   tarPath,
+		"linux/arm64":   layoutPath,
+		"linux/riscv64": src,
+	}
+	annotations := map[string]string{"org.opencontainers.image.revision": "abc123"}
+	d, err := crane.AssembleIndex(dst, sources, crane.WithIndexAnnotations(annotations))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := name.ParseReference(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := remote.Index(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := idx.Digest(); err != nil || got.String() != d {
+		t.Errorf("Digest: got %v, %v, want %s", got, err, d)
+	}
+	if im.MediaType != types.OCIImageIndex {
+		t.Errorf("MediaType: got %s, want %s", im.MediaType, types.OCIImageIndex)
+	}
+	if diff := cmp.Diff(annotations, im.Annotations); diff != "" {
+		t.Errorf("annotations (-want +got):\n%s", diff)
+	}
+	var got []v1.Platform
+	for _, desc := range im.Manifests {
+		got = append(got, *desc.Platform)
+	}
+	want := []v1.Platform{
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm64", Variant: "v8"},
+		{OS: "linux", Architecture: "riscv64"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("platforms (-want +got):\n%s", diff)
+	}
+	rd, err := riscv.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if im.Manifests[2].Digest != rd {
+		t.Errorf("linux/riscv64: got %s, want %s", im.Manifests[2].Digest, rd)
+	}
+
+	// Without annotations, Docker images make a Docker manifest list.
+	if _, err := crane.AssembleIndex(dst, map[string]string{"linux/amd64": tarPath}); err != nil {
+		t.Fatal(err)
+	}
+	desc, err := remote.Head(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.MediaType != types.DockerManifestList {
+		t.Errorf("MediaType: got %s, want %s", desc.MediaType, types.DockerManifestList)
+	}
+
+	// The platform has to agree with the image.
+	if _, err := crane.AssembleIndex(dst, map[string]string{"linux/arm64": tarPath}); err == nil {
+		t.Error("AssembleIndex: expected an error for an amd64 image keyed by linux/arm64")
+	}
+}
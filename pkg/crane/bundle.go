@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/bundle"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/sync/errgroup"
+)
+
+// SaveBundle pulls the images and indexes that srcs refer to and writes them
+// to a bundle at path, which PushBundle can push elsewhere; see
+// bundle.Write.
+//
+// Indexes are saved whole, unless a platform is set with WithPlatform or in a
+// reference, e.g. ubuntu@linux/arm64, in which case only that platform's
+// image is saved.
+func SaveBundle(srcs []string, path string, opt ...Option) error {
+	o := makeOptions(opt...)
+	refs := make([]name.Reference, len(srcs))
+	for i, src := range srcs {
+		ref, err := name.ParseWithPlatform(src, o.Name...)
+		if err != nil {
+			return fmt.Errorf("parsing reference %q: %w", src, err)
+		}
+		refs[i] = ref
+	}
+
+	puller, err := remote.NewPuller(o.Remote...)
+	if err != nil {
+		return err
+	}
+	ts := make([]remote.Taggable, len(refs))
+	// Not errgroup.WithContext: what's fetched is read after Wait returns.
+	var g errgroup.Group
+	g.SetLimit(o.jobs)
+	for i, ref := range refs {
+		g.Go(func() error {
+			logs.Progress.Printf("Fetching %v", ref)
+			desc, err := puller.Get(o.ctx, ref)
+			if err != nil {
+				return fmt.Errorf("fetching %v: %w", ref, err)
+			}
+			if _, ok := ref.(name.PlatformReference); ok || o.Platform != nil || !desc.MediaType.IsIndex() {
+				img, err := desc.Image()
+				if err != nil {
+					return err
+				}
+				ts[i] = o.cachedImage(img)
+				return nil
+			}
+			idx, err := desc.ImageIndex()
+			if err != nil {
+				return err
+			}
+			if o.Cache != nil {
+				idx = cache.ImageIndex(idx, o.Cache)
+			}
+			ts[i] = idx
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	m := make(map[name.Reference]remote.Taggable, len(refs))
+	for i, ref := range refs {
+		if pr, ok := ref.(name.PlatformReference); ok {
+			ref = pr.Reference
+		}
+		m[ref] = ts[i]
+	}
+	logs.Progress.Printf("Writing %s", path)
+	return bundle.WriteToFile(path, m)
+}
+
+// PushBundle pushes the images and indexes in the bundle at path to dst, a
+// registry optionally followed by a repository prefix. Each keeps the
+// repository and tag or digest it was saved from, so with a dst of
+// "registry.example.com/mirror", gcr.io/project/app:v1 is pushed to
+// registry.example.com/mirror/project/app:v1.
+//
+// Blobs that several images share are only pushed once.
+func PushBundle(path, dst string, opt ...Option) error {
+	o := makeOptions(opt...)
+	registry, prefix, _ := strings.Cut(dst, "/")
+	if _, err := name.NewRegistry(registry, o.Name...); err != nil {
+		return fmt.Errorf("parsing registry %q: %w", registry, err)
+	}
+
+	b, err := bundle.Open(path)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	rename := func(ref name.Reference) (name.Reference, error) {
+		dst, err := name.ChangeRegistry(ref, registry, o.Name...)
+		if err != nil {
+			return nil, err
+		}
+		if prefix != "" {
+			dst, err = name.PrefixRepository(dst, prefix, o.Name...)
+			if err != nil {
+				return nil, err
+			}
+		}
+		logs.Progress.Printf("Pushing %v to %v", ref, dst)
+		return dst, nil
+	}
+	return bundle.Push(b, rename, o.Remote...)
+}
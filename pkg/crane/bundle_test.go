@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestBundle(t *testing.T) {
+	src := httptest.NewServer(registry.New())
+	defer src.Close()
+	dst := httptest.NewServer(registry.New())
+	defer dst.Close()
+	su, err := url.Parse(src.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	du, err := url.Parse(dst.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := random.Index(1024, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgRef := su.Host + "/project/app:v1"
+	idxRef := su.Host + "/project/multi:latest"
+	if err := crane.Push(img, imgRef); err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag(idxRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(tag, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "images.bundle")
+	if err := crane.SaveBundle([]string{imgRef, idxRef}, path); err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.PushBundle(path, du.Host+"/mirror"); err != nil {
+		t.Fatal(err)
+	}
+
+	for ref, want := range map[string]partial.Describable{
+		du.Host + "/mirror/project/app:v1":       img,
+		du.Host + "/mirror/project/multi:latest": idx,
+	} {
+		got, err := crane.Digest(ref)
+		if err != nil {
+			t.Fatalf("Digest(%s): %v", ref, err)
+		}
+		d, err := want.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != d.String() {
+			t.Errorf("%s: got %s, want %s", ref, got, d)
+		}
+	}
+}
@@ -15,21 +15,75 @@
 package crane
 
 import (
-	"context"
+	"fmt"
+	"iter"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 )
 
-// Catalog returns the repositories in a registry's catalog.
+// Catalog returns the repositories in a registry's catalog. WithPageSize,
+// WithLimit, WithRepoPrefix and WithRepoFilter bound how many are listed.
 func Catalog(src string, opt ...Option) (res []string, err error) {
-	o := makeOptions(opt...)
-	reg, err := name.NewRegistry(src, o.Name...)
-	if err != nil {
-		return nil, err
+	res = []string{}
+	for repo, err := range CatalogIterator(src, opt...) {
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, repo)
 	}
+	return res, nil
+}
 
-	// This context gets overridden by remote.WithContext, which is set by
-	// crane.WithContext.
-	return remote.Catalog(context.Background(), reg, o.Remote...)
+// CatalogIterator returns an iterator over the repositories in a registry's
+// catalog, as Catalog does, that only fetches the next page from the
+// registry once those before it have been consumed. If listing fails or the
+// context set by WithContext is done, the error is yielded, and iteration
+// stops.
+func CatalogIterator(src string, opt ...Option) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		o := makeOptions(opt...)
+		reg, err := name.NewRegistry(src, o.Name...)
+		if err != nil {
+			yield("", fmt.Errorf("parsing reg %q: %w", src, err))
+			return
+		}
+		puller, err := remote.NewPuller(o.Remote...)
+		if err != nil {
+			yield("", err)
+			return
+		}
+		catalogger, err := puller.Catalogger(o.ctx, reg)
+		if err != nil {
+			yield("", err)
+			return
+		}
+		n := 0
+		for catalogger.HasNext() {
+			page, err := catalogger.Next(o.ctx)
+			if err != nil {
+				yield("", err)
+				return
+			}
+			for _, repo := range page.Repos {
+				if err := o.ctx.Err(); err != nil {
+					yield("", err)
+					return
+				}
+				if !strings.HasPrefix(repo, o.list.repoPrefix) {
+					continue
+				}
+				if o.list.repoFilter != nil && !o.list.repoFilter.MatchString(repo) {
+					continue
+				}
+				if !yield(repo, nil) {
+					return
+				}
+				if n++; o.list.limit > 0 && n >= o.list.limit {
+					return
+				}
+			}
+		}
+	}
 }
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// checkpoint records the tags that CopyRepository has copied, one
+// "DST DIGEST" line each, so that an interrupted copy can skip them.
+type checkpoint struct {
+	mu   sync.Mutex
+	f    *os.File
+	done map[string]string
+}
+
+func openCheckpoint(path string) (*checkpoint, error) {
+	c := &checkpoint{done: map[string]string{}}
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		// A line cut short by an interruption doesn't count.
+		dst, digest, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		if _, err := v1.NewHash(digest); err != nil {
+			continue
+		}
+		c.done[dst] = digest
+	}
+	if c.f, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// copied reports whether dst has been copied from a source with digest h.
+func (c *checkpoint) copied(dst string, h v1.Hash) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done[dst] == h.String()
+}
+
+func (c *checkpoint) record(dst string, h v1.Hash) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(c.f, "%s %s\n", dst, h); err != nil {
+		return err
+	}
+	if err := c.f.Sync(); err != nil {
+		return err
+	}
+	c.done[dst] = h.String()
+	return nil
+}
+
+func (c *checkpoint) Close() error {
+	return c.f.Close()
+}
@@ -14,6 +14,8 @@
 
 package crane
 
+import v1 "github.com/google/go-containerregistry/pkg/v1"
+
 // Config returns the config file for the remote image ref.
 func Config(ref string, opt ...Option) ([]byte, error) {
 	i, _, err := getImage(ref, opt...)
@@ -22,3 +24,14 @@ func Config(ref string, opt ...Option) ([]byte, error) {
 	}
 	return i.RawConfigFile()
 }
+
+// ConfigFile returns the parsed config file for the remote image ref. If ref
+// is an index, the config file of the image for WithPlatform, or the
+// default platform, is returned.
+func ConfigFile(ref string, opt ...Option) (*v1.ConfigFile, error) {
+	i, _, err := getImage(ref, opt...)
+	if err != nil {
+		return nil, err
+	}
+	return i.ConfigFile()
+}
@@ -26,12 +26,28 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-// Copy copies a remote image or index from src to dst.
-func Copy(src, dst string, opt ...Option) error {
+// Copy copies a remote image or index from src to dst. src may also start
+// with any scheme that Open accepts, to copy from elsewhere.
+//
+// Layers are copied byte for byte, as they're stored, and checked against
+// their digests on the way; they're never decompressed or compressed anew,
+// so dst has src's digest. Sources whose layers aren't stored compressed,
+// like the tarballs that "docker save" writes, can only be copied by
+// compressing them, which Copy refuses to do unless WithRecompression is
+// set.
+//
+// Use WithProgress to follow how far it's got, and WithRetryBackoff to
+// control how blob uploads that fail transiently are retried.
+func Copy(src, dst string, opt ...Option) (rerr error) {
 	o := makeOptions(opt...)
-	srcRef, err := name.ParseReference(src, o.Name...)
-	if err != nil {
-		return fmt.Errorf("parsing reference %q: %w", src, err)
+	var srcRef name.Reference
+	if !local(src) {
+		_, ref := cutScheme(src)
+		r, err := name.ParseWithPlatform(ref, o.Name...)
+		if err != nil {
+			return fmt.Errorf("parsing reference %q: %w", src, err)
+		}
+		srcRef = r
 	}
 
 	dstRef, err := name.ParseReference(dst, o.Name...)
@@ -63,11 +79,32 @@ func Copy(src, dst string, opt ...Option) error {
 		}
 	}
 
-	pusher, err := remote.NewPusher(o.Remote...)
+	opts, done := o.withProgress()
+	defer func() { done(rerr) }()
+	pusher, err := remote.NewPusher(opts...)
 	if err != nil {
 		return err
 	}
 
+	if srcRef == nil {
+		stored, err := storedBlobs(src)
+		if err != nil {
+			return err
+		}
+		if !stored && !o.recompress {
+			return fmt.Errorf("copying %s would compress its layers anew, changing their digests; use WithRecompression to allow it", src)
+		}
+		logs.Progress.Printf("Copying from %v to %v", src, dstRef)
+		t, err := openLocal(src, o, opt...)
+		if err != nil {
+			return err
+		}
+		if stored {
+			t = verified(t)
+		}
+		return pusher.Push(o.ctx, dstRef, t)
+	}
+
 	logs.Progress.Printf("Copying from %v to %v", srcRef, dstRef)
 	desc, err := puller.Get(o.ctx, srcRef)
 	if err != nil {
@@ -75,7 +112,11 @@ func Copy(src, dst string, opt ...Option) error {
 	}
 
 	if o.Platform == nil {
-		return pusher.Push(o.ctx, dstRef, desc)
+		t, err := o.cached(desc)
+		if err != nil {
+			return err
+		}
+		return pusher.Push(o.ctx, dstRef, t)
 	}
 
 	// If platform is explicitly set, don't copy the whole index, just the appropriate image.
@@ -83,11 +124,20 @@ func Copy(src, dst string, opt ...Option) error {
 	if err != nil {
 		return err
 	}
-	return pusher.Push(o.ctx, dstRef, img)
+	return pusher.Push(o.ctx, dstRef, o.cachedImage(img))
 }
 
 // CopyRepository copies every tag from src to dst.
-func CopyRepository(src, dst string, opt ...Option) error {
+//
+// Tags are copied concurrently, up to WithJobs at a time, and share one
+// remote.Pusher. So each distinct blob is checked for and uploaded only
+// once however many images have it, and the blobs of different images are
+// uploaded at the same time.
+//
+// With WithCheckpoint, tags are recorded as they're copied, and tags that
+// were copied by an earlier, interrupted call are skipped if the source
+// still has the same digest.
+func CopyRepository(src, dst string, opt ...Option) (rerr error) {
 	o := makeOptions(opt...)
 
 	srcRepo, err := name.NewRepository(src, o.Name...)
@@ -126,11 +176,21 @@ func CopyRepository(src, dst string, opt ...Option) error {
 		}
 	}
 
-	pusher, err := remote.NewPusher(o.Remote...)
+	opts, done := o.withProgress()
+	defer func() { done(rerr) }()
+	pusher, err := remote.NewPusher(opts...)
 	if err != nil {
 		return err
 	}
 
+	var cp *checkpoint
+	if o.checkpoint != "" {
+		if cp, err = openCheckpoint(o.checkpoint); err != nil {
+			return fmt.Errorf("opening checkpoint: %w", err)
+		}
+		defer cp.Close()
+	}
+
 	lister, err := puller.Lister(o.ctx, srcRepo)
 	if err != nil {
 		return err
@@ -142,6 +202,8 @@ func CopyRepository(src, dst string, opt ...Option) error {
 	for lister.HasNext() {
 		tags, err := lister.Next(ctx)
 		if err != nil {
+			// Let the copies that started finish before reporting.
+			_ = g.Wait()
 			return err
 		}
 
@@ -165,14 +227,36 @@ func CopyRepository(src, dst string, opt ...Option) error {
 					return fmt.Errorf("failed to parse tag: %w", err)
 				}
 
+				if cp != nil {
+					head, err := puller.Head(ctx, srcTag)
+					if err != nil {
+						return err
+					}
+					if cp.copied(dstTag.String(), head.Digest) {
+						logs.Progress.Printf("Skipping %s, already copied", tag)
+						return nil
+					}
+				}
+
 				logs.Progress.Printf("Fetching %s", srcTag)
 				desc, err := puller.Get(ctx, srcTag)
 				if err != nil {
 					return err
 				}
 
+				t, err := o.cached(desc)
+				if err != nil {
+					return err
+				}
+
 				logs.Progress.Printf("Pushing %s", dstTag)
-				return pusher.Push(ctx, dstTag, desc)
+				if err := pusher.Push(ctx, dstTag, t); err != nil {
+					return err
+				}
+				if cp != nil {
+					return cp.record(dstTag.String(), desc.Digest)
+				}
+				return nil
 			})
 		}
 	}
@@ -17,6 +17,7 @@ package crane_test
 import (
 	"archive/tar"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -25,19 +26,30 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/bundle"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
 	"github.com/google/go-containerregistry/pkg/v1/compare"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
 )
 
 // TODO(jonjohnsonjr): Test crane.Copy failures.
@@ -264,6 +276,183 @@ func TestCraneCopyIndex(t *testing.T) {
 	}
 }
 
+func TestCraneCopyProgress(t *testing.T) {
+	src := httptest.NewServer(registry.New())
+	defer src.Close()
+	dst := httptest.NewServer(registry.New())
+	defer dst.Close()
+	su, err := url.Parse(src.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	du, err := url.Parse(dst.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := random.Index(1024, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(su.Host + "/test/crane")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(ref, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	var updates []v1.Update
+	if err := crane.Copy(ref.String(), du.Host+"/test/copy", crane.WithProgress(func(u v1.Update) {
+		updates = append(updates, u)
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) < 2 {
+		t.Fatalf("got %d updates, want several", len(updates))
+	}
+	for i := 1; i < len(updates); i++ {
+		if updates[i].Complete < updates[i-1].Complete {
+			t.Errorf("update %d went backwards: %+v after %+v", i, updates[i], updates[i-1])
+		}
+	}
+	last := updates[len(updates)-1]
+	if last.Error != nil || last.Total == 0 || last.Complete != last.Total {
+		t.Errorf("last update: got %+v, want a complete copy", last)
+	}
+
+	// A failed copy reports its error.
+	var failed v1.Update
+	if err := crane.Copy(su.Host+"/test/missing", du.Host+"/test/copy", crane.WithProgress(func(u v1.Update) {
+		failed = u
+	})); err == nil {
+		t.Error("Copy: expected an error copying a missing image")
+	}
+	if failed.Error == nil {
+		t.Error("last update of a failed copy has no error")
+	}
+}
+
+func TestCraneCopyRetry(t *testing.T) {
+	src := httptest.NewServer(registry.New())
+	defer src.Close()
+	su, err := url.Parse(src.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first blob upload's connection is dropped partway through.
+	reg := registry.New()
+	var dropped bool
+	dst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch && !dropped {
+			dropped = true
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer dst.Close()
+	du, err := url.Parse(dst.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srcRef := su.Host + "/test/crane"
+	if err := crane.Push(img, srcRef); err != nil {
+		t.Fatal(err)
+	}
+
+	backoff := remote.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 3}
+	if err := crane.Copy(srcRef, du.Host+"/test/copy", crane.WithJobs(1), crane.WithRetryBackoff(backoff)); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if !dropped {
+		t.Error("no upload was dropped")
+	}
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := crane.Digest(du.Host + "/test/copy"); err != nil || got != d.String() {
+		t.Errorf("Digest: got %q, %v, want %s", got, err, d)
+	}
+}
+
+func TestCraneWithCache(t *testing.T) {
+	// Set up a fake registry.
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := fmt.Sprintf("%s/test/crane", u.Host)
+	dst := fmt.Sprintf("%s/test/crane/copy", u.Host)
+
+	idx, err := random.Index(1024, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(ref, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	opt := crane.WithCache(cache.NewFilesystemCache(dir))
+
+	// Copying through the cache populates it and retains the digest.
+	if err := crane.Copy(src, dst, opt); err != nil {
+		t.Fatal(err)
+	}
+	d, err := crane.Digest(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp, err := crane.Digest(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != cp {
+		t.Errorf("Copied Digest(): %v != %v", d, cp)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Errorf("Copy didn't populate the cache")
+	}
+
+	// Pulling an image uses the cache.
+	im, err := idx.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := crane.Pull(fmt.Sprintf("%s@%s", src, im.Manifests[0].Digest), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Image(img); err != nil {
+		t.Errorf("validate.Image: %v", err)
+	}
+}
+
 func TestWithPlatform(t *testing.T) {
 	// Set up a fake registry with a platform-specific image.
 	s := httptest.NewServer(registry.New())
@@ -328,6 +517,23 @@ func TestWithPlatform(t *testing.T) {
 		t.Errorf("Manifest(%q) != Manifest(%q): (\n\n%s\n\n!=\n\n%s\n\n)", dst, src, string(got), string(want))
 	}
 
+	// The platform can be part of the reference instead.
+	qualified := src + "@linux/arm"
+	if got, err := crane.Manifest(qualified); err != nil {
+		t.Fatal(err)
+	} else if string(got) != string(want) {
+		t.Errorf("Manifest(%q) != Manifest(%q): (\n\n%s\n\n!=\n\n%s\n\n)", qualified, src, string(got), string(want))
+	}
+	dst2 := path.Join(u.Host, "dst2")
+	if err := crane.Copy(qualified, dst2); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := crane.Manifest(dst2); err != nil {
+		t.Fatal(err)
+	} else if string(got) != string(want) {
+		t.Errorf("Manifest(%q) != Manifest(%q): (\n\n%s\n\n!=\n\n%s\n\n)", dst2, qualified, string(got), string(want))
+	}
+
 	arch := "real fake doors"
 
 	// Now do a fake platform, should fail
@@ -379,6 +585,85 @@ func TestCraneTarball(t *testing.T) {
 	}
 }
 
+func TestCraneOCIArchive(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	// An archive of an image.
+	img, err := random.Image(1024, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgPath := filepath.Join(dir, "image.tar")
+	if err := bundle.WriteToFile(imgPath, map[name.Reference]remote.Taggable{name.MustParseReference("test/crane:image"): img}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := crane.Load(imgPath)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if d, err := got.Digest(); err != nil {
+		t.Fatal(err)
+	} else if d != digest {
+		t.Errorf("digest mismatch: %v != %v", d, digest)
+	}
+
+	// An archive of an index, from which Load picks a platform.
+	amd64 := platformImage(t, "linux", "amd64", "")
+	arm64 := platformImage(t, "linux", "arm64", "")
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex),
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+	)
+	idxPath := filepath.Join(dir, "index.tar")
+	if err := bundle.WriteToFile(idxPath, map[name.Reference]remote.Taggable{name.MustParseReference("test/crane:index"): idx}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		opt  []crane.Option
+		want v1.Image
+	}{
+		{want: amd64},
+		{opt: []crane.Option{crane.WithPlatform(&v1.Platform{OS: "linux", Architecture: "arm64"})}, want: arm64},
+	} {
+		got, err := crane.Load(idxPath, tc.opt...)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		d, err := got.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := tc.want.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d != want {
+			t.Errorf("digest mismatch: %v != %v", d, want)
+		}
+	}
+
+	l, err := crane.LoadIndex(idxPath)
+	if err != nil {
+		t.Fatalf("LoadIndex: %v", err)
+	}
+	m, err := l.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := idx.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Manifests) != 1 || m.Manifests[0].Digest != want {
+		t.Errorf("LoadIndex: got %v, want one entry %s", m.Manifests, want)
+	}
+}
+
 func TestCraneSaveLegacy(t *testing.T) {
 	t.Parallel()
 	// Write an image as a legacy tarball.
@@ -569,3 +854,715 @@ func TestBadInputs(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigFileAndManifestStruct(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := fmt.Sprintf("%s/test/crane", u.Host)
+
+	amd64 := platformImage(t, "linux", "amd64", "")
+	arm64 := platformImage(t, "linux", "arm64", "")
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex),
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+	)
+	ref, err := name.ParseReference(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(ref, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		opt  []crane.Option
+		want v1.Image
+	}{
+		{want: amd64},
+		{opt: []crane.Option{crane.WithPlatform(&v1.Platform{OS: "linux", Architecture: "arm64"})}, want: arm64},
+	} {
+		wantCfg, err := tc.want.ConfigFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := crane.ConfigFile(src, tc.opt...)
+		if err != nil {
+			t.Fatalf("ConfigFile: %v", err)
+		}
+		if cfg.Architecture != wantCfg.Architecture {
+			t.Errorf("ConfigFile: got architecture %q, want %q", cfg.Architecture, wantCfg.Architecture)
+		}
+
+		wantManifest, err := tc.want.Manifest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := crane.ManifestStruct(src, tc.opt...)
+		if err != nil {
+			t.Fatalf("ManifestStruct: %v", err)
+		}
+		if m.Config.Digest != wantManifest.Config.Digest {
+			t.Errorf("ManifestStruct: got config %s, want %s", m.Config.Digest, wantManifest.Config.Digest)
+		}
+	}
+}
+
+func TestListTagsOptions(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := fmt.Sprintf("%s/test/crane", u.Host)
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"v1", "v2", "v3", "latest", "dev"} {
+		if err := crane.Push(img, repo+":"+tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		opt  []crane.Option
+		want []string
+	}{{
+		name: "all",
+		want: []string{"dev", "latest", "v1", "v2", "v3"},
+	}, {
+		name: "pages",
+		opt:  []crane.Option{crane.WithPageSize(2)},
+		want: []string{"dev", "latest", "v1", "v2", "v3"},
+	}, {
+		name: "last",
+		opt:  []crane.Option{crane.WithPageSize(2), crane.WithLast("latest")},
+		want: []string{"v1", "v2", "v3"},
+	}, {
+		name: "limit",
+		opt:  []crane.Option{crane.WithPageSize(1), crane.WithLimit(3)},
+		want: []string{"dev", "latest", "v1"},
+	}, {
+		name: "filter",
+		opt:  []crane.Option{crane.WithPageSize(2), crane.WithTagFilter(regexp.MustCompile(`^v\d+$`)), crane.WithLimit(2)},
+		want: []string{"v1", "v2"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := crane.ListTags(repo, tc.opt...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ListTags() (-want +got) = %s", diff)
+			}
+		})
+	}
+
+	// Breaking out of the loop stops iteration.
+	var got []string
+	for tag, err := range crane.IterTags(repo, crane.WithPageSize(1)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, tag)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if diff := cmp.Diff([]string{"dev", "latest"}, got); diff != "" {
+		t.Errorf("IterTags() (-want +got) = %s", diff)
+	}
+
+	for _, err := range crane.IterTags("bad/repo!") {
+		if err == nil {
+			t.Error("IterTags() of a bad repo: want error, got nil")
+		}
+	}
+}
+
+func TestCatalogIterator(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, repo := range []string{"team/app", "team/db", "team/web", "other/app"} {
+		if err := crane.Push(img, fmt.Sprintf("%s/%s:latest", u.Host, repo)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		opt  []crane.Option
+		want []string
+	}{{
+		name: "all",
+		want: []string{"other/app", "team/app", "team/db", "team/web"},
+	}, {
+		name: "prefix",
+		opt:  []crane.Option{crane.WithPageSize(1), crane.WithRepoPrefix("team/")},
+		want: []string{"team/app", "team/db", "team/web"},
+	}, {
+		name: "filter and limit",
+		opt:  []crane.Option{crane.WithPageSize(2), crane.WithRepoFilter(regexp.MustCompile(`/(app|web)$`)), crane.WithLimit(2)},
+		want: []string{"other/app", "team/app"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := crane.Catalog(u.Host, tc.opt...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Catalog() (-want +got) = %s", diff)
+			}
+		})
+	}
+
+	// Cancelling the context stops iteration with its error.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got []string
+	var gotErr error
+	for repo, err := range crane.CatalogIterator(u.Host, crane.WithContext(ctx), crane.WithPageSize(10)) {
+		if err != nil {
+			gotErr = err
+			continue
+		}
+		got = append(got, repo)
+		cancel()
+	}
+	if len(got) != 1 || !errors.Is(gotErr, context.Canceled) {
+		t.Errorf("CatalogIterator() after cancel: got %v, %v; want one repo and %v", got, gotErr, context.Canceled)
+	}
+}
+
+func TestDeleteAll(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(u.Host + "/test/crane")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An image, a signature of it, and an attestation of the signature.
+	img, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(repo.Tag("latest"), img); err != nil {
+		t.Fatal(err)
+	}
+	imgs := []v1.Image{img}
+	for _, artifactType := range []types.MediaType{"application/vnd.dev.sigstore.bundle", "application/vnd.in-toto+json"} {
+		desc, err := partial.Descriptor(imgs[len(imgs)-1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		art := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+		art = mutate.ConfigMediaType(art, artifactType)
+		art = mutate.Subject(art, *desc).(v1.Image)
+		d, err := art.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := remote.Write(repo.Digest(d.String()), art); err != nil {
+			t.Fatal(err)
+		}
+		imgs = append(imgs, art)
+	}
+	// An unrelated image that should survive.
+	other, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(repo.Tag("other"), other); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := crane.DeleteAll(repo.Tag("latest").String()); err != nil {
+		t.Fatalf("DeleteAll: %v", err)
+	}
+	for i, img := range imgs {
+		d, err := img.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := crane.Head(repo.Digest(d.String()).String()); err == nil {
+			t.Errorf("manifest %d (%s) still exists", i, d)
+		}
+	}
+	if _, err := crane.Head(repo.Tag("other").String()); err != nil {
+		t.Errorf("unrelated image was deleted: %v", err)
+	}
+}
+
+func TestMutateConfig(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := fmt.Sprintf("%s/test/crane:latest", u.Host)
+
+	img, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err = crane.MutateImage(img, crane.SetEnv("PATH", "/bin"), crane.SetCmd("sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(img, src); err != nil {
+		t.Fatal(err)
+	}
+
+	digest, err := crane.MutateConfig(src, []crane.ConfigEdit{
+		crane.SetEnv("PATH", "/usr/bin"),
+		crane.SetEnv("HOME", "/root"),
+		crane.SetLabels(map[string]string{"org.example": "yes"}),
+		crane.SetEntrypoint("/app"),
+		crane.SetUser("nobody"),
+		crane.SetWorkdir("/work"),
+		crane.SetExposedPorts("80/tcp"),
+		crane.SetAnnotations(map[string]string{"note": "mutated"}),
+	})
+	if err != nil {
+		t.Fatalf("MutateConfig(): %v", err)
+	}
+	if got, err := crane.Digest(src); err != nil {
+		t.Fatal(err)
+	} else if got != digest {
+		t.Errorf("Digest(%s): got %s, want %s", src, got, digest)
+	}
+
+	cfg, err := crane.ConfigFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := v1.Config{
+		Env:          []string{"PATH=/usr/bin", "HOME=/root"},
+		Labels:       map[string]string{"org.example": "yes"},
+		Entrypoint:   []string{"/app"},
+		User:         "nobody",
+		WorkingDir:   "/work",
+		ExposedPorts: map[string]struct{}{"80/tcp": {}},
+	}
+	if diff := cmp.Diff(want, cfg.Config); diff != "" {
+		t.Errorf("Config (-want +got): %s", diff)
+	}
+	m, err := crane.ManifestStruct(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Annotations["note"]; got != "mutated" {
+		t.Errorf("annotation note: got %q, want %q", got, "mutated")
+	}
+
+	// By digest, the edited image is pushed by digest too.
+	byDigest := fmt.Sprintf("%s/test/crane@%s", u.Host, digest)
+	got, err := crane.MutateConfig(byDigest, []crane.ConfigEdit{crane.SetUser("root")})
+	if err != nil {
+		t.Fatalf("MutateConfig(%s): %v", byDigest, err)
+	}
+	if _, err := crane.Head(fmt.Sprintf("%s/test/crane@%s", u.Host, got)); err != nil {
+		t.Errorf("Head(mutated by digest): %v", err)
+	}
+	if d, err := crane.Digest(src); err != nil {
+		t.Fatal(err)
+	} else if d != digest {
+		t.Errorf("MutateConfig(%s) moved tag latest to %s", byDigest, d)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := fmt.Sprintf("%s/test/crane:src", u.Host)
+	dst := fmt.Sprintf("%s/test/crane:dst", u.Host)
+
+	img, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(img, src); err != nil {
+		t.Fatal(err)
+	}
+	tmp := t.TempDir()
+	if err := crane.PullLayout(src, filepath.Join(tmp, "layout")); err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Save(img, src, filepath.Join(tmp, "image.tar")); err != nil {
+		t.Fatal(err)
+	}
+	// Paths with colons in them.
+	if err := crane.PullLayout(src, filepath.Join(tmp, "lay:out")); err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Save(img, src, filepath.Join(tmp, "image:v1.tar")); err != nil {
+		t.Fatal(err)
+	}
+
+	opener := func(_ context.Context, ref string) (partial.WithRawManifest, error) {
+		if ref != "mine" {
+			return nil, fmt.Errorf("unexpected ref %q", ref)
+		}
+		return img, nil
+	}
+	opts := []crane.Option{crane.WithSource("custom", opener)}
+	for _, ref := range []string{
+		src,
+		"docker://" + src,
+		"oci://" + filepath.Join(tmp, "layout"),
+		"oci://" + filepath.Join(tmp, "layout") + ":" + src,
+		"tarball://" + filepath.Join(tmp, "image.tar"),
+		"tarball://" + filepath.Join(tmp, "image.tar") + ":" + src,
+		"oci://" + filepath.Join(tmp, "lay:out"),
+		"oci://" + filepath.Join(tmp, "lay:out") + ":" + src,
+		"tarball://" + filepath.Join(tmp, "image:v1.tar"),
+		"tarball://" + filepath.Join(tmp, "image:v1.tar") + ":" + src,
+		"custom://mine",
+	} {
+		t.Run(ref, func(t *testing.T) {
+			got, err := crane.OpenImage(ref, opts...)
+			if err != nil {
+				t.Fatalf("OpenImage(): %v", err)
+			}
+			if d, err := got.Digest(); err != nil {
+				t.Fatal(err)
+			} else if d != want {
+				t.Errorf("OpenImage(): got %s, want %s", d, want)
+			}
+			if d, err := crane.Digest(ref, opts...); err != nil {
+				t.Fatalf("Digest(): %v", err)
+			} else if d != want.String() {
+				t.Errorf("Digest(): got %s, want %s", d, want)
+			}
+		})
+	}
+
+	if err := crane.Copy("oci://"+filepath.Join(tmp, "layout"), dst); err != nil {
+		t.Fatalf("Copy(): %v", err)
+	}
+	if d, err := crane.Digest(dst); err != nil {
+		t.Fatal(err)
+	} else if d != want.String() {
+		t.Errorf("Copy(): got %s, want %s", d, want)
+	}
+
+	for _, ref := range []string{
+		"oci://" + filepath.Join(tmp, "layout") + ":missing",
+		"oci://" + filepath.Join(tmp, "lay:out") + ":missing",
+		"nope://" + src,
+	} {
+		if _, err := crane.Open(ref); err == nil {
+			t.Errorf("Open(%s): want error, got nil", ref)
+		}
+	}
+}
+
+func TestHostPlatform(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := fmt.Sprintf("%s/test/crane:multi", u.Host)
+
+	host := crane.HostPlatform()
+	other := host
+	other.Architecture = "other"
+	var adds []mutate.IndexAddendum
+	for _, p := range []v1.Platform{other, host} {
+		img, err := random.Image(100, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		adds = append(adds, mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: &p}})
+	}
+	idx := mutate.AppendManifests(empty.Index, adds...)
+	ref, err := name.ParseReference(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(ref, idx); err != nil {
+		t.Fatal(err)
+	}
+	want, err := adds[1].Add.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := crane.Pull(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := img.Digest(); err != nil {
+		t.Fatal(err)
+	} else if got != want {
+		t.Errorf("Pull(): got %s, want the %s image %s", got, host, want)
+	}
+	img, err = crane.OpenImage(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := img.Digest(); err != nil {
+		t.Fatal(err)
+	} else if got != want {
+		t.Errorf("OpenImage(): got %s, want the %s image %s", got, host, want)
+	}
+}
+
+func TestCopyStoredBlobs(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := fmt.Sprintf("%s/test/crane:src", u.Host)
+	dst := fmt.Sprintf("%s/test/crane:dst", u.Host)
+
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(img, src); err != nil {
+		t.Fatal(err)
+	}
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "layout")
+	if err := crane.PullLayout(src, path); err != nil {
+		t.Fatal(err)
+	}
+
+	// A layout's blobs are copied as they're stored.
+	if err := crane.Copy("oci://"+path, dst); err != nil {
+		t.Fatalf("Copy(): %v", err)
+	}
+	if got, err := crane.Digest(dst); err != nil {
+		t.Fatal(err)
+	} else if got != want.String() {
+		t.Errorf("Copy(): got %s, want %s", got, want)
+	}
+
+	// ...and checked against their digests.
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := layers[0].Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := filepath.Join(path, "blobs", h.Algorithm, h.Hex)
+	b, err := os.ReadFile(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[0] ^= 0xff
+	if err := os.WriteFile(blob, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Use another registry, which doesn't have the blob already.
+	s2 := httptest.NewServer(registry.New())
+	defer s2.Close()
+	if err := crane.Copy("oci://"+path, strings.TrimPrefix(s2.URL, "http://")+"/test/crane:dst"); err == nil || !strings.Contains(err.Error(), "error verifying") {
+		t.Errorf("Copy() of a corrupt layout: want a verification error, got %v", err)
+	}
+
+	// A docker save tarball's layers have to be compressed.
+	tarball := "tarball://" + filepath.Join(tmp, "image.tar")
+	if err := crane.Save(img, src, strings.TrimPrefix(tarball, "tarball://")); err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Copy(tarball, dst); err == nil {
+		t.Error("Copy() of a docker save tarball: want error, got nil")
+	}
+	if err := crane.Copy(tarball, dst, crane.WithRecompression()); err != nil {
+		t.Errorf("Copy() of a docker save tarball with WithRecompression: %v", err)
+	}
+}
+
+func TestCopyRepositorySharesBlobs(t *testing.T) {
+	src := httptest.NewServer(registry.New())
+	defer src.Close()
+
+	// Count the requests for each blob that dst gets.
+	var mu sync.Mutex
+	heads, puts := map[string]int{}, map[string]int{}
+	reg := registry.New()
+	dst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		switch {
+		case r.Method == http.MethodHead && strings.Contains(r.URL.Path, "/blobs/"):
+			heads[path.Base(r.URL.Path)]++
+		case r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/blobs/uploads/"):
+			puts[r.URL.Query().Get("digest")]++
+		}
+		mu.Unlock()
+		reg.ServeHTTP(w, r)
+	}))
+	defer dst.Close()
+	srcRepo := strings.TrimPrefix(src.URL, "http://") + "/test/crane"
+	dstRepo := strings.TrimPrefix(dst.URL, "http://") + "/test/crane"
+
+	// Ten images on the same base.
+	base, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 10 {
+		l, err := random.Layer(1024, types.DockerLayer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := mutate.AppendLayers(base, l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := crane.Push(img, fmt.Sprintf("%s:%d", srcRepo, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := crane.CopyRepository(srcRepo, dstRepo); err != nil {
+		t.Fatalf("CopyRepository(): %v", err)
+	}
+	for digest, n := range heads {
+		if n > 1 {
+			t.Errorf("blob %s checked for %d times, want once", digest, n)
+		}
+	}
+	for digest, n := range puts {
+		if n > 1 {
+			t.Errorf("blob %s uploaded %d times, want once", digest, n)
+		}
+	}
+	// 3 shared layers, and a layer and a config for each image.
+	if got, want := len(puts), 3+2*10; got != want {
+		t.Errorf("uploaded %d blobs, want %d", got, want)
+	}
+}
+
+func TestCopyRepositoryCheckpoint(t *testing.T) {
+	// Count the manifests that are fetched from src.
+	var (
+		mu      sync.Mutex
+		fetched []string
+	)
+	srcReg := registry.New()
+	src := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/manifests/") {
+			mu.Lock()
+			fetched = append(fetched, path.Base(r.URL.Path))
+			mu.Unlock()
+		}
+		srcReg.ServeHTTP(w, r)
+	}))
+	defer src.Close()
+	dst := httptest.NewServer(registry.New())
+	defer dst.Close()
+	srcRepo := strings.TrimPrefix(src.URL, "http://") + "/test/crane"
+	dstRepo := strings.TrimPrefix(dst.URL, "http://") + "/test/crane"
+
+	for i := range 3 {
+		img, err := random.Image(1024, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := crane.Push(img, fmt.Sprintf("%s:%d", srcRepo, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint")
+	if err := crane.CopyRepository(srcRepo, dstRepo, crane.WithCheckpoint(checkpoint)); err != nil {
+		t.Fatalf("CopyRepository(): %v", err)
+	}
+	if len(fetched) != 3 {
+		t.Errorf("fetched %v, want 3 tags", fetched)
+	}
+
+	// Copying again only copies what changed since.
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(img, srcRepo+":1"); err != nil {
+		t.Fatal(err)
+	}
+	fetched = nil
+	if err := crane.CopyRepository(srcRepo, dstRepo, crane.WithCheckpoint(checkpoint)); err != nil {
+		t.Fatalf("CopyRepository(): %v", err)
+	}
+	if !slices.Equal(fetched, []string{"1"}) {
+		t.Errorf("fetched %v, want [1]", fetched)
+	}
+	want, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := crane.Digest(dstRepo + ":1"); err != nil {
+		t.Fatal(err)
+	} else if got != want.String() {
+		t.Errorf("Digest(): got %s, want %s", got, want)
+	}
+}
+
+func TestPullPolicy(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	src := strings.TrimPrefix(s.URL, "http://") + "/test/crane:policy"
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(img, src); err != nil {
+		t.Fatal(err)
+	}
+
+	var verified int
+	verify := crane.WithVerify(func(context.Context, name.Reference, v1.Descriptor) error {
+		verified++
+		return nil
+	})
+	if _, err := crane.Pull(src, verify, crane.WithPullPolicy(remote.MaxLayerSize(512))); err == nil {
+		t.Error("Pull(): want a policy violation, got nil")
+	}
+	if _, err := crane.Pull(src, verify, crane.WithPullPolicy(remote.MaxLayerSize(4096))); err != nil {
+		t.Errorf("Pull(): %v", err)
+	}
+	if verified != 2 {
+		t.Errorf("WithVerify: ran %d times, want 2", verified)
+	}
+}
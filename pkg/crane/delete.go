@@ -15,10 +15,18 @@
 package crane
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
+	"strings"
 
+	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/referrers"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 )
 
 // Delete deletes the remote reference at src.
@@ -31,3 +39,66 @@ func Delete(src string, opt ...Option) error {
 
 	return remote.Delete(ref, o.Remote...)
 }
+
+// DeleteAll deletes the manifest that the remote reference src refers to,
+// by digest, after deleting every manifest that refers to it, directly or
+// through other referrers: its signatures, SBOMs, attestations and so on.
+// Referrers are deleted before the manifests they refer to, so that an
+// interrupted DeleteAll can be run again. Tags that registries without the
+// referrers API list referrers with are deleted too.
+func DeleteAll(src string, opt ...Option) error {
+	o := makeOptions(opt...)
+	ref, err := name.ParseReference(src, o.Name...)
+	if err != nil {
+		return fmt.Errorf("parsing reference %q: %w", src, err)
+	}
+	desc, err := remote.Head(ref, o.Remote...)
+	if err != nil {
+		return err
+	}
+
+	repo := ref.Context()
+	var nodes []referrers.Node
+	subjects := []v1.Hash{desc.Digest}
+	if err := referrers.Walk(o.ctx, referrers.Remote(repo, o.Remote...), desc.Digest, func(n referrers.Node) error {
+		nodes = append(nodes, n)
+		subjects = append(subjects, n.Digest)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("listing referrers of %s: %w", ref, err)
+	}
+
+	// Walk visits referrers before their own referrers, so delete them in
+	// reverse.
+	for _, n := range slices.Backward(nodes) {
+		logs.Progress.Printf("Deleting referrer %s of %s", n.Digest, n.Subject)
+		if err := deleteIfExists(repo.Digest(n.Digest.String()), o); err != nil {
+			return err
+		}
+	}
+	for _, h := range subjects {
+		tag := repo.Tag(strings.Replace(h.String(), ":", "-", 1))
+		if err := deleteIfExists(tag, o); err != nil {
+			return err
+		}
+	}
+	if err := remote.Delete(repo.Digest(desc.Digest.String()), o.Remote...); err != nil {
+		return err
+	}
+	// Registries usually delete the tags of a manifest with it, but not all
+	// do.
+	if _, ok := ref.(name.Tag); ok {
+		return deleteIfExists(ref, o)
+	}
+	return nil
+}
+
+// deleteIfExists deletes ref, unless it doesn't exist.
+func deleteIfExists(ref name.Reference, o Options) error {
+	err := remote.Delete(ref, o.Remote...)
+	var terr *transport.Error
+	if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
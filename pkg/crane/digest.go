@@ -14,13 +14,27 @@
 
 package crane
 
-import "github.com/google/go-containerregistry/pkg/logs"
+import (
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+)
 
 // Digest returns the sha256 hash of the remote image at ref.
 func Digest(ref string, opt ...Option) (string, error) {
 	o := makeOptions(opt...)
+	if local(ref) {
+		t, err := openLocal(ref, o, opt...)
+		if err != nil {
+			return "", err
+		}
+		digest, err := partial.Digest(t)
+		if err != nil {
+			return "", err
+		}
+		return digest.String(), nil
+	}
 	if o.Platform != nil {
-		desc, err := getManifest(ref, opt...)
+		desc, err := getManifest(ref, o.nested(opt)...)
 		if err != nil {
 			return "", err
 		}
@@ -39,10 +53,10 @@ func Digest(ref string, opt ...Option) (string, error) {
 		}
 		return digest.String(), nil
 	}
-	desc, err := Head(ref, opt...)
+	desc, err := Head(ref, o.nested(opt)...)
 	if err != nil {
 		logs.Warn.Printf("HEAD request failed, falling back on GET: %v", err)
-		rdesc, err := getManifest(ref, opt...)
+		rdesc, err := getManifest(ref, o.nested(opt)...)
 		if err != nil {
 			return "", err
 		}
@@ -15,7 +15,12 @@
 package crane
 
 import (
+	"archive/tar"
+	"errors"
 	"io"
+	"path"
+	"strings"
+	"time"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
@@ -24,7 +29,16 @@ import (
 // Export writes the filesystem contents (as a tarball) of img to w.
 // If img has a single layer, just write the (uncompressed) contents to w so
 // that this "just works" for images that just wrap a single blob.
-func Export(img v1.Image, w io.Writer) error {
+//
+// WithExclude, WithInclude, WithOwner, WithNumericOwner and WithModTime
+// change what's written, except for such single blobs, which aren't
+// tarballs. Export stops with the context's error if WithContext's context
+// is cancelled.
+func Export(img v1.Image, w io.Writer, opt ...Option) error {
+	o := makeOptions(opt...)
+	if err := o.fs.validate(); err != nil {
+		return err
+	}
 	layers, err := img.Layers()
 	if err != nil {
 		return err
@@ -44,11 +58,91 @@ func Export(img v1.Image, w io.Writer) error {
 			if err != nil {
 				return err
 			}
-			_, err = io.Copy(w, rc)
+			defer rc.Close()
+			_, err = io.Copy(w, &ctxReader{o.ctx, rc})
 			return err
 		}
 	}
-	fs := mutate.Extract(img)
-	_, err = io.Copy(w, fs)
-	return err
+	rc := mutate.Extract(img)
+	defer rc.Close()
+	fs := &ctxReader{o.ctx, rc}
+	if !o.fs.rewrites() {
+		_, err = io.Copy(w, fs)
+		return err
+	}
+	return o.fs.rewrite(w, fs)
+}
+
+// ExportIndex writes the filesystem contents of the image in idx for
+// WithPlatform, HostPlatform by default, to w, as Export does.
+func ExportIndex(idx v1.ImageIndex, w io.Writer, opt ...Option) error {
+	o := makeOptions(opt...)
+	img, err := imageForPlatform(idx, o.platform())
+	if err != nil {
+		return err
+	}
+	return Export(o.cachedImage(img), w, o.nested(opt)...)
+}
+
+// rewrites reports whether any of the options that change what Export
+// writes are set.
+func (o fsOptions) rewrites() bool {
+	return len(o.exclude) > 0 || len(o.include) > 0 || o.numeric || o.owner != nil || o.modTime != nil
+}
+
+// rewrite copies the tarball r to w, leaving out and changing files as o
+// says.
+func (o fsOptions) rewrite(w io.Writer, r io.Reader) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if !o.keeps(hdr.Name) {
+			continue
+		}
+		// A hard link to a file that's left out would be dangling.
+		if hdr.Typeflag == tar.TypeLink && !o.keeps(hdr.Linkname) {
+			continue
+		}
+		if o.numeric {
+			hdr.Uname, hdr.Gname = "", ""
+		}
+		if o.owner != nil {
+			hdr.Uid, hdr.Gid = o.owner[0], o.owner[1]
+		}
+		if o.modTime != nil {
+			hdr.ModTime = *o.modTime
+			hdr.AccessTime, hdr.ChangeTime = time.Time{}, time.Time{}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// keeps reports whether the file name is written, given the include and
+// exclude patterns that it or any of its parents match.
+func (o fsOptions) keeps(name string) bool {
+	included := len(o.include) == 0
+	p := strings.TrimPrefix(path.Clean("/"+name), "/")
+	for p != "." && p != "" {
+		if o.excludes(p) {
+			return false
+		}
+		if matchAny(o.include, p) {
+			included = true
+		}
+		p = path.Dir(p)
+	}
+	return included
 }
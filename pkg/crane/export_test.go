@@ -15,12 +15,20 @@
 package crane
 
 import (
+	"archive/tar"
 	"bytes"
+	"context"
+	"errors"
+	"io"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 )
 
@@ -39,3 +47,131 @@ func TestExport(t *testing.T) {
 		t.Errorf("got: %s\nwant: %s", got, want)
 	}
 }
+
+// tarLayer returns a layer of the given files, owned by "user".
+func tarLayer(t *testing.T, files ...string) v1.Layer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    f,
+			Size:    int64(len(f)),
+			Mode:    0o644,
+			Uid:     1000,
+			Uname:   "user",
+			ModTime: time.Now(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(f)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l, err := tarball.LayerFromReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// exported returns the headers of the files in the tarball b.
+func exported(t *testing.T, b []byte) []*tar.Header {
+	t.Helper()
+	var hdrs []*tar.Header
+	tr := tar.NewReader(bytes.NewReader(b))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return hdrs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		hdrs = append(hdrs, hdr)
+	}
+}
+
+func TestExportOptions(t *testing.T) {
+	img, err := mutate.AppendLayers(empty.Image,
+		tarLayer(t, "etc/app.conf", "etc/app.log", "usr/bin/app", "etcetera"),
+		tarLayer(t, "etc/other.conf"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	if err := Export(img, &buf,
+		WithInclude("etc"),
+		WithExclude("*.log"),
+		WithNumericOwner(),
+		WithModTime(mtime),
+	); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"etc/app.conf": true, "etc/other.conf": true}
+	hdrs := exported(t, buf.Bytes())
+	if len(hdrs) != len(want) {
+		t.Errorf("got %d files, want %v", len(hdrs), want)
+	}
+	for _, hdr := range hdrs {
+		if !want[hdr.Name] {
+			t.Errorf("unexpected file %s", hdr.Name)
+		}
+		if hdr.Uname != "" || hdr.Uid != 1000 {
+			t.Errorf("%s: owner %q (%d), want only uid 1000", hdr.Name, hdr.Uname, hdr.Uid)
+		}
+		if !hdr.ModTime.Equal(mtime) {
+			t.Errorf("%s: mtime %v, want %v", hdr.Name, hdr.ModTime, mtime)
+		}
+	}
+
+	if err := Export(img, io.Discard, WithInclude("[")); err == nil {
+		t.Error("Export() with a bad pattern: want error, got nil")
+	}
+}
+
+func TestExportCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	img, err := mutate.AppendLayers(empty.Image, tarLayer(t, "etc/app.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Export(img, io.Discard, WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Export(): want %v, got %v", context.Canceled, err)
+	}
+
+	// AppendFS reads the layer once up front, to digest it.
+	if _, err := AppendFS(empty.Image, fstest.MapFS{"app": {Data: []byte("app")}}, WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("AppendFS(): want %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestExportIndex(t *testing.T) {
+	var adds []mutate.IndexAddendum
+	for _, arch := range []string{"amd64", "arm64"} {
+		img, err := mutate.AppendLayers(empty.Image, tarLayer(t, arch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		adds = append(adds, mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: arch}}})
+	}
+	idx := mutate.AppendManifests(empty.Index, adds...)
+
+	for _, arch := range []string{"amd64", "arm64"} {
+		var buf bytes.Buffer
+		if err := ExportIndex(idx, &buf, WithPlatform(&v1.Platform{OS: "linux", Architecture: arch})); err != nil {
+			t.Fatal(err)
+		}
+		hdrs := exported(t, buf.Bytes())
+		if len(hdrs) != 1 || hdrs[0].Name != arch {
+			t.Errorf("ExportIndex(%s): got %v", arch, hdrs)
+		}
+	}
+}
@@ -23,8 +23,13 @@ import (
 )
 
 func getImage(r string, opt ...Option) (v1.Image, name.Reference, error) {
+	if local(r) {
+		img, err := OpenImage(r, opt...)
+		return img, nil, err
+	}
+	_, r = cutScheme(r)
 	o := makeOptions(opt...)
-	ref, err := name.ParseReference(r, o.Name...)
+	ref, err := name.ParseWithPlatform(r, o.Name...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("parsing reference %q: %w", r, err)
 	}
@@ -32,12 +37,13 @@ func getImage(r string, opt ...Option) (v1.Image, name.Reference, error) {
 	if err != nil {
 		return nil, nil, fmt.Errorf("reading image %q: %w", ref, err)
 	}
-	return img, ref, nil
+	return o.cachedImage(img), ref, nil
 }
 
 func getManifest(r string, opt ...Option) (*remote.Descriptor, error) {
+	_, r = cutScheme(r)
 	o := makeOptions(opt...)
-	ref, err := name.ParseReference(r, o.Name...)
+	ref, err := name.ParseWithPlatform(r, o.Name...)
 	if err != nil {
 		return nil, fmt.Errorf("parsing reference %q: %w", r, err)
 	}
@@ -52,8 +58,9 @@ func Get(r string, opt ...Option) (*remote.Descriptor, error) {
 // Head performs a HEAD request for a manifest and returns a content descriptor
 // based on the registry's response.
 func Head(r string, opt ...Option) (*v1.Descriptor, error) {
+	_, r = cutScheme(r)
 	o := makeOptions(opt...)
-	ref, err := name.ParseReference(r, o.Name...)
+	ref, err := name.ParseWithPlatform(r, o.Name...)
 	if err != nil {
 		return nil, err
 	}
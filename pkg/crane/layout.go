@@ -0,0 +1,147 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// PullLayout pulls src into the OCI image layout at path, creating the
+// layout if it doesn't exist. The entry is annotated with src's name, as
+// with "crane pull --format=oci --annotate-ref", and replaces any entry
+// already annotated with it.
+//
+// If src is an index, the whole index is pulled, unless WithPlatform is
+// set or src is qualified with a platform, in which case only that
+// platform's image is.
+func PullLayout(src, path string, opt ...Option) error {
+	o := makeOptions(opt...)
+	ref, err := name.ParseWithPlatform(src, o.Name...)
+	if err != nil {
+		return fmt.Errorf("parsing reference %q: %w", src, err)
+	}
+	refName := ref.Name()
+	_, platformRef := ref.(name.PlatformReference)
+
+	desc, err := remote.Get(ref, o.Remote...)
+	if err != nil {
+		return err
+	}
+
+	p, err := layout.FromPath(path)
+	if errors.Is(err, os.ErrNotExist) {
+		p, err = layout.Write(path, empty.Index)
+	}
+	if err != nil {
+		return err
+	}
+
+	annotations := layout.WithAnnotations(map[string]string{
+		specsv1.AnnotationRefName: refName,
+	})
+	matcher := match.Annotation(specsv1.AnnotationRefName, refName)
+
+	if desc.MediaType.IsIndex() && o.Platform == nil && !platformRef {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return err
+		}
+		return p.ReplaceIndex(o.cachedIndex(idx), matcher, annotations)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return err
+	}
+	return p.ReplaceImage(o.cachedImage(img), matcher, annotations)
+}
+
+// PushLayout pushes an entry of the OCI image layout at path, an image or
+// an index, to dst.
+//
+// The entry is the one WithLayoutMatcher selects, if it's set. Otherwise,
+// it's the layout's only entry or, if it has several, the one annotated
+// with dst's name or tag, e.g. by PullLayout. It's an error for anything
+// but exactly one entry to be selected.
+//
+// Blobs are pushed as the layout describes them, each read from disk once,
+// unless WithBlobVerification is set.
+func PushLayout(path, dst string, opt ...Option) error {
+	o := makeOptions(opt...)
+	ref, err := name.ParseReference(dst, o.Name...)
+	if err != nil {
+		return fmt.Errorf("parsing reference %q: %w", dst, err)
+	}
+	idx, err := layout.ImageIndexFromPath(path)
+	if err != nil {
+		return fmt.Errorf("loading %s as OCI layout: %w", path, err)
+	}
+	m, err := idx.IndexManifest()
+	if err != nil {
+		return err
+	}
+
+	matcher := o.layoutMatcher
+	if matcher == nil && len(m.Manifests) != 1 {
+		names := map[string]bool{ref.Name(): true}
+		if tag, ok := ref.(name.Tag); ok {
+			names[tag.TagStr()] = true
+		}
+		matcher = func(desc v1.Descriptor) bool {
+			return names[desc.Annotations[specsv1.AnnotationRefName]]
+		}
+	}
+	var found []v1.Descriptor
+	for _, desc := range m.Manifests {
+		if matcher == nil || matcher(desc) {
+			found = append(found, desc)
+		}
+	}
+	if len(found) != 1 {
+		return fmt.Errorf("%d entries of layout %s match, want 1", len(found), path)
+	}
+
+	desc := found[0]
+	switch {
+	case desc.MediaType.IsImage():
+		img, err := idx.Image(desc.Digest)
+		if err != nil {
+			return err
+		}
+		if o.verify {
+			img = verifiedImage{img}
+		}
+		return remote.Write(ref, img, o.Remote...)
+	case desc.MediaType.IsIndex():
+		ii, err := idx.ImageIndex(desc.Digest)
+		if err != nil {
+			return err
+		}
+		if o.verify {
+			ii = verifiedIndex{ii}
+		}
+		return remote.WriteIndex(ref, ii, o.Remote...)
+	}
+	return fmt.Errorf("layout entry %s is not an image or index (mediaType: %q)", desc.Digest, desc.MediaType)
+}
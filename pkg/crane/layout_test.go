@@ -0,0 +1,258 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestPullPushLayout(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := fmt.Sprintf("%s/test/app", u.Host)
+
+	amd64 := platformImage(t, "linux", "amd64", "")
+	arm64 := platformImage(t, "linux", "arm64", "")
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex),
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+	)
+	multi, err := name.ParseReference(repo + ":multi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(multi, idx); err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Tag(repo+":multi", "arm"); err != nil {
+		t.Fatal(err)
+	}
+	single := platformImage(t, "linux", "s390x", "")
+	if err := crane.Push(single, repo+":single"); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "layout")
+	for _, pull := range []struct {
+		src string
+		opt []crane.Option
+	}{
+		{src: repo + ":multi"},
+		{src: repo + ":single"},
+		// Pulling again replaces the entry.
+		{src: repo + ":single"},
+		{src: repo + ":arm", opt: []crane.Option{crane.WithPlatform(&v1.Platform{OS: "linux", Architecture: "arm64"})}},
+	} {
+		if err := crane.PullLayout(pull.src, path, pull.opt...); err != nil {
+			t.Fatalf("PullLayout(%q): %v", pull.src, err)
+		}
+	}
+
+	l, err := layout.ImageIndexFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := l.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]v1.Hash{}
+	for _, e := range []struct {
+		tag string
+		d   func() (v1.Hash, error)
+	}{
+		{"multi", idx.Digest},
+		{"single", single.Digest},
+		{"arm", arm64.Digest},
+	} {
+		d, err := e.d()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[repo+":"+e.tag] = d
+	}
+	if len(m.Manifests) != len(want) {
+		t.Fatalf("layout has %d entries, want %d", len(m.Manifests), len(want))
+	}
+	for _, desc := range m.Manifests {
+		ref := desc.Annotations[specsv1.AnnotationRefName]
+		if desc.Digest != want[ref] {
+			t.Errorf("layout entry %q: got %s, want %s", ref, desc.Digest, want[ref])
+		}
+	}
+
+	// By default, the entry annotated with the destination's name is pushed.
+	if err := crane.PushLayout(path, repo+":single"); err != nil {
+		t.Fatalf("PushLayout(): %v", err)
+	}
+	// A matcher can select any entry.
+	if err := crane.PushLayout(path, repo+":copy", crane.WithLayoutMatcher(match.Annotation(specsv1.AnnotationRefName, repo+":multi"))); err != nil {
+		t.Fatalf("PushLayout(): %v", err)
+	}
+	d, err := crane.Digest(repo + ":copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != want[repo+":multi"].String() {
+		t.Errorf("pushed %s, want %s", d, want[repo+":multi"])
+	}
+	// Nothing is annotated with this.
+	if err := crane.PushLayout(path, repo+":other"); err == nil {
+		t.Error("PushLayout() with no matching entry: want error, got nil")
+	}
+}
+
+func TestPushLayoutBlobVerification(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := fmt.Sprintf("%s/test/app", u.Host)
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "layout")
+	p, err := layout.Write(path, empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AppendImage(img); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := crane.PushLayout(path, repo+":verified", crane.WithBlobVerification()); err != nil {
+		t.Fatalf("PushLayout(): %v", err)
+	}
+
+	// Corrupt the layer, and push it to a registry that doesn't have it yet.
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := layers[0].Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := filepath.Join(path, "blobs", h.Algorithm, h.Hex)
+	b, err := os.ReadFile(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[0] ^= 0xff
+	if err := os.WriteFile(blob, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s2 := httptest.NewServer(registry.New())
+	defer s2.Close()
+	if err := crane.PushLayout(path, strings.TrimPrefix(s2.URL, "http://")+"/test/app:corrupt", crane.WithBlobVerification()); err == nil || !strings.Contains(err.Error(), "error verifying") {
+		t.Errorf("PushLayout() of a corrupt layout: want a verification error, got %v", err)
+	}
+}
+
+func TestPullLayoutIncremental(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		blobs []string
+	)
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/blobs/") {
+			mu.Lock()
+			blobs = append(blobs, path.Base(r.URL.Path))
+			mu.Unlock()
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := fmt.Sprintf("%s/test/app:latest", u.Host)
+
+	img, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(img, ref); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "layout")
+	if err := crane.PullLayout(ref, dir); err != nil {
+		t.Fatalf("PullLayout(): %v", err)
+	}
+
+	// Only the new layer and config are fetched when the tag moves on.
+	l, err := random.Layer(1024, types.DockerLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err = mutate.AppendLayers(img, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(img, ref); err != nil {
+		t.Fatal(err)
+	}
+	blobs = nil
+	if err := crane.PullLayout(ref, dir); err != nil {
+		t.Fatalf("PullLayout(): %v", err)
+	}
+	ld, err := l.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cd, err := img.ConfigName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(blobs)
+	want := []string{ld.String(), cd.String()}
+	slices.Sort(want)
+	if !slices.Equal(blobs, want) {
+		t.Errorf("fetched blobs %v, want %v", blobs, want)
+	}
+}
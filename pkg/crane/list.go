@@ -16,18 +16,69 @@ package crane
 
 import (
 	"fmt"
+	"iter"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 )
 
-// ListTags returns the tags in repository src.
+// ListTags returns the tags in repository src. WithPageSize, WithLast,
+// WithLimit and WithTagFilter bound how many are listed.
 func ListTags(src string, opt ...Option) ([]string, error) {
-	o := makeOptions(opt...)
-	repo, err := name.NewRepository(src, o.Name...)
-	if err != nil {
-		return nil, fmt.Errorf("parsing repo %q: %w", src, err)
+	tags := []string{}
+	for tag, err := range IterTags(src, opt...) {
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
 	}
+	return tags, nil
+}
 
-	return remote.List(repo, o.Remote...)
+// IterTags returns an iterator over the tags in repository src, as ListTags
+// does, that only fetches the next page of tags from the registry once
+// those before it have been consumed. If listing fails or the context set
+// by WithContext is done, the error is yielded, and iteration stops.
+func IterTags(src string, opt ...Option) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		o := makeOptions(opt...)
+		repo, err := name.NewRepository(src, o.Name...)
+		if err != nil {
+			yield("", fmt.Errorf("parsing repo %q: %w", src, err))
+			return
+		}
+		puller, err := remote.NewPuller(o.Remote...)
+		if err != nil {
+			yield("", err)
+			return
+		}
+		lister, err := puller.Lister(o.ctx, repo)
+		if err != nil {
+			yield("", err)
+			return
+		}
+		n := 0
+		for lister.HasNext() {
+			page, err := lister.Next(o.ctx)
+			if err != nil {
+				yield("", err)
+				return
+			}
+			for _, tag := range page.Tags {
+				if err := o.ctx.Err(); err != nil {
+					yield("", err)
+					return
+				}
+				if o.list.tagFilter != nil && !o.list.tagFilter.MatchString(tag) {
+					continue
+				}
+				if !yield(tag, nil) {
+					return
+				}
+				if n++; o.list.limit > 0 && n >= o.list.limit {
+					return
+				}
+			}
+		}
+	}
 }
@@ -14,13 +14,22 @@
 
 package crane
 
+import v1 "github.com/google/go-containerregistry/pkg/v1"
+
 // Manifest returns the manifest for the remote image or index ref.
 func Manifest(ref string, opt ...Option) ([]byte, error) {
-	desc, err := getManifest(ref, opt...)
+	o := makeOptions(opt...)
+	if local(ref) {
+		t, err := openLocal(ref, o, opt...)
+		if err != nil {
+			return nil, err
+		}
+		return t.RawManifest()
+	}
+	desc, err := getManifest(ref, o.nested(opt)...)
 	if err != nil {
 		return nil, err
 	}
-	o := makeOptions(opt...)
 	if o.Platform != nil {
 		img, err := desc.Image()
 		if err != nil {
@@ -30,3 +39,14 @@ func Manifest(ref string, opt ...Option) ([]byte, error) {
 	}
 	return desc.Manifest, nil
 }
+
+// ManifestStruct returns the parsed manifest for the remote image ref. If
+// ref is an index, the manifest of the image for WithPlatform, or the
+// default platform, is returned.
+func ManifestStruct(ref string, opt ...Option) (*v1.Manifest, error) {
+	i, _, err := getImage(ref, opt...)
+	if err != nil {
+		return nil, err
+	}
+	return i.Manifest()
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+// A ConfigEdit changes the config file of an image, or the annotations of
+// its manifest, for MutateImage and MutateConfig. annotations is empty to
+// begin with; what's added to it is added to the manifest's annotations.
+type ConfigEdit func(cfg *v1.ConfigFile, annotations map[string]string) error
+
+// SetEnv sets the environment variable key to value, in place if it's
+// already set. On Windows images, new keys are upper-cased.
+func SetEnv(key, value string) ConfigEdit {
+	return func(cfg *v1.ConfigFile, _ map[string]string) error {
+		for i, kv := range cfg.Config.Env {
+			k, _, ok := strings.Cut(kv, "=")
+			if !ok {
+				return fmt.Errorf("invalid key value pair in config: %s", kv)
+			}
+			if k == key {
+				cfg.Config.Env[i] = key + "=" + value
+				return nil
+			}
+		}
+		if cfg.OS == "windows" {
+			key = strings.ToUpper(key)
+		}
+		cfg.Config.Env = append(cfg.Config.Env, key+"="+value)
+		return nil
+	}
+}
+
+// SetLabels adds labels to the config, replacing any with the same keys.
+func SetLabels(labels map[string]string) ConfigEdit {
+	return func(cfg *v1.ConfigFile, _ map[string]string) error {
+		if cfg.Config.Labels == nil {
+			cfg.Config.Labels = map[string]string{}
+		}
+		maps.Copy(cfg.Config.Labels, labels)
+		return nil
+	}
+}
+
+// SetAnnotations adds annotations to the manifest, replacing any with the
+// same keys.
+func SetAnnotations(annotations map[string]string) ConfigEdit {
+	return func(_ *v1.ConfigFile, anns map[string]string) error {
+		maps.Copy(anns, annotations)
+		return nil
+	}
+}
+
+// SetEntrypoint sets the entrypoint and, as Docker does, clears the cmd.
+func SetEntrypoint(entrypoint ...string) ConfigEdit {
+	return func(cfg *v1.ConfigFile, _ map[string]string) error {
+		cfg.Config.Entrypoint = entrypoint
+		cfg.Config.Cmd = nil
+		return nil
+	}
+}
+
+// SetCmd sets the cmd.
+func SetCmd(cmd ...string) ConfigEdit {
+	return func(cfg *v1.ConfigFile, _ map[string]string) error {
+		cfg.Config.Cmd = cmd
+		return nil
+	}
+}
+
+// SetUser sets the user.
+func SetUser(user string) ConfigEdit {
+	return func(cfg *v1.ConfigFile, _ map[string]string) error {
+		cfg.Config.User = user
+		return nil
+	}
+}
+
+// SetWorkdir sets the working directory.
+func SetWorkdir(dir string) ConfigEdit {
+	return func(cfg *v1.ConfigFile, _ map[string]string) error {
+		cfg.Config.WorkingDir = dir
+		return nil
+	}
+}
+
+// SetExposedPorts replaces the exposed ports, e.g. "80/tcp".
+func SetExposedPorts(ports ...string) ConfigEdit {
+	return func(cfg *v1.ConfigFile, _ map[string]string) error {
+		cfg.Config.ExposedPorts = make(map[string]struct{}, len(ports))
+		for _, port := range ports {
+			cfg.Config.ExposedPorts[port] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// SetPlatform sets the platform the image is for.
+func SetPlatform(platform *v1.Platform) ConfigEdit {
+	return func(cfg *v1.ConfigFile, _ map[string]string) error {
+		if platform == nil {
+			return errors.New("setting platform: platform is nil")
+		}
+		cfg.OS = platform.OS
+		cfg.Architecture = platform.Architecture
+		cfg.Variant = platform.Variant
+		cfg.OSVersion = platform.OSVersion
+		return nil
+	}
+}
+
+// MutateImage returns img with edits made to its config file and manifest,
+// in order.
+func MutateImage(img v1.Image, edits ...ConfigEdit) (v1.Image, error) {
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	cfg = cfg.DeepCopy()
+	annotations := map[string]string{}
+	for _, edit := range edits {
+		if err := edit(cfg, annotations); err != nil {
+			return nil, err
+		}
+	}
+	img, err = mutate.ConfigFile(img, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("mutating config: %w", err)
+	}
+	if len(annotations) != 0 {
+		img = mutate.Annotations(img, annotations).(v1.Image)
+	}
+	return img, nil
+}
+
+// MutateConfig makes edits to the remote image ref, as "crane mutate" does,
+// and returns the digest of the edited image. It's pushed to ref if that's
+// a tag, and by digest to ref's repository otherwise. If ref is an index,
+// the image for WithPlatform, or the default platform, is edited, and
+// replaces the index if ref is a tag.
+func MutateConfig(ref string, edits []ConfigEdit, opt ...Option) (string, error) {
+	o := makeOptions(opt...)
+	r, err := name.ParseWithPlatform(ref, o.Name...)
+	if err != nil {
+		return "", fmt.Errorf("parsing reference %q: %w", ref, err)
+	}
+	if pr, ok := r.(name.PlatformReference); ok {
+		r = pr.Reference
+	}
+	img, err := Pull(ref, o.nested(opt)...)
+	if err != nil {
+		return "", fmt.Errorf("pulling %s: %w", ref, err)
+	}
+	img, err = MutateImage(img, edits...)
+	if err != nil {
+		return "", err
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return "", fmt.Errorf("digesting new image: %w", err)
+	}
+	dst := r.String()
+	if _, ok := r.(name.Digest); ok {
+		dst = r.Context().Digest(digest.String()).String()
+	}
+	if err := Push(img, dst, o.nested(opt)...); err != nil {
+		return "", fmt.Errorf("pushing %s: %w", dst, err)
+	}
+	return digest.String(), nil
+}
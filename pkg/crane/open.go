@@ -0,0 +1,211 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// An Opener reads the image or index that ref refers to, for Open, from a
+// source that crane doesn't know about itself; see WithSource.
+type Opener func(ctx context.Context, ref string) (partial.WithRawManifest, error)
+
+// Open returns the image or index that src refers to, as a v1.Image or a
+// v1.ImageIndex. src may start with a scheme that says where to read it
+// from, as skopeo's transports do:
+//
+//   - docker://REF, or just REF, is read from a registry. Only the image
+//     for WithPlatform is read from an index if that's set, as with Pull.
+//   - oci://PATH[:NAME] is read from the OCI image layout at PATH: the
+//     entry annotated with NAME, as PullLayout annotates them, its only
+//     entry, or else the layout's whole index.
+//   - tarball://PATH[:TAG] is read from the tarball at PATH, as Load and
+//     LoadTag read it.
+//
+// PATH may contain colons too; NAME and TAG follow the last colon that
+// leaves a PATH that exists.
+//
+// Other schemes are read with the Opener that WithSource sets for them.
+//
+// Pull, Config, ConfigFile, Manifest, ManifestStruct, Digest and Copy's
+// src accept the same schemes.
+func Open(src string, opt ...Option) (partial.WithRawManifest, error) {
+	o := makeOptions(opt...)
+	scheme, ref := cutScheme(src)
+	switch scheme {
+	case "docker":
+		return openRemote(ref, o)
+	case "oci":
+		path, entry := cutPath(ref)
+		return openLayout(path, entry)
+	case "tarball":
+		path, tag := cutPath(ref)
+		return LoadTag(path, tag, o.nested(opt)...)
+	}
+	if open, ok := o.sources[scheme]; ok {
+		return open(o.ctx, ref)
+	}
+	return nil, fmt.Errorf("unsupported scheme %q in %q", scheme, src)
+}
+
+// OpenImage returns the image that src refers to, as Open does. If that's
+// an index, the image for WithPlatform, HostPlatform by default, is read
+// from it.
+func OpenImage(src string, opt ...Option) (v1.Image, error) {
+	o := makeOptions(opt...)
+	t, err := Open(src, o.nested(opt)...)
+	if err != nil {
+		return nil, err
+	}
+	switch t := t.(type) {
+	case v1.Image:
+		return t, nil
+	case v1.ImageIndex:
+		img, err := imageForPlatform(t, o.platform())
+		if err != nil {
+			return nil, err
+		}
+		return o.cachedImage(img), nil
+	}
+	return nil, fmt.Errorf("%s is not an image or index (%T)", src, t)
+}
+
+// cutScheme splits the scheme off src, returning "docker" if it has none.
+func cutScheme(src string) (scheme, ref string) {
+	if scheme, ref, ok := strings.Cut(src, "://"); ok {
+		return scheme, ref
+	}
+	return "docker", src
+}
+
+// cutPath splits ref, PATH[:NAME], into PATH and NAME. Both may contain
+// colons, so it splits on the last colon that leaves a PATH that exists,
+// or else on the last colon.
+func cutPath(ref string) (path, name string) {
+	if _, err := os.Stat(ref); err == nil {
+		return ref, ""
+	}
+	for i := strings.LastIndex(ref, ":"); i >= 0; i = strings.LastIndex(ref[:i], ":") {
+		if _, err := os.Stat(ref[:i]); err == nil {
+			return ref[:i], ref[i+1:]
+		}
+	}
+	if i := strings.LastIndex(ref, ":"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// local reports whether src is read from somewhere other than a registry.
+func local(src string) bool {
+	scheme, _ := cutScheme(src)
+	return scheme != "docker"
+}
+
+// storedBlobs reports whether Open reads the layers of src as they're
+// stored, rather than compressing them as they're read, as it has to for
+// tarballs that "docker save" writes, for example. It can't tell for
+// sources that WithSource adds, so it reports false for those.
+func storedBlobs(src string) (bool, error) {
+	scheme, ref := cutScheme(src)
+	switch scheme {
+	case "docker", "oci":
+		return true, nil
+	case "tarball":
+		path, tag, _ := strings.Cut(ref, ":")
+		if tag != "" {
+			return false, nil
+		}
+		oci, docker, err := archiveFormat(path)
+		return oci && !docker, err
+	}
+	return false, nil
+}
+
+// openLocal opens src, which local reports is local, as Open does, or as
+// OpenImage does if WithPlatform is set, to match what's read from a
+// registry.
+func openLocal(src string, o Options, opt ...Option) (partial.WithRawManifest, error) {
+	if o.Platform != nil {
+		return OpenImage(src, o.nested(opt)...)
+	}
+	return Open(src, o.nested(opt)...)
+}
+
+func openRemote(src string, o Options) (partial.WithRawManifest, error) {
+	ref, err := name.ParseWithPlatform(src, o.Name...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing reference %q: %w", src, err)
+	}
+	_, platformRef := ref.(name.PlatformReference)
+	desc, err := remote.Get(ref, o.Remote...)
+	if err != nil {
+		return nil, err
+	}
+	if desc.MediaType.IsIndex() && o.Platform == nil && !platformRef {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return nil, err
+		}
+		return o.cachedIndex(idx), nil
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return nil, err
+	}
+	return o.cachedImage(img), nil
+}
+
+func openLayout(path, entry string) (partial.WithRawManifest, error) {
+	idx, err := layout.ImageIndexFromPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s as OCI layout: %w", path, err)
+	}
+	m, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	var found []v1.Descriptor
+	for _, desc := range m.Manifests {
+		if entry == "" || desc.Annotations[specsv1.AnnotationRefName] == entry {
+			found = append(found, desc)
+		}
+	}
+	switch {
+	case entry == "" && len(found) != 1:
+		return idx, nil
+	case len(found) != 1:
+		return nil, fmt.Errorf("%d entries of layout %s are annotated %q, want 1", len(found), path, entry)
+	}
+
+	desc := found[0]
+	switch {
+	case desc.MediaType.IsImage():
+		return idx.Image(desc.Digest)
+	case desc.MediaType.IsIndex():
+		return idx.ImageIndex(desc.Digest)
+	}
+	return nil, fmt.Errorf("layout entry %s is not an image or index (mediaType: %q)", desc.Digest, desc.MediaType)
+}
@@ -17,11 +17,18 @@ package crane
 import (
 	"context"
 	"crypto/tls"
+	"io/fs"
 	"net/http"
+	"regexp"
+	"runtime"
+	"slices"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/match"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 )
 
@@ -32,12 +39,48 @@ type Options struct {
 	Platform  *v1.Platform
 	Keychain  authn.Keychain
 	Transport http.RoundTripper
+	Cache     cache.Cache
 
-	auth      authn.Authenticator
-	insecure  bool
-	jobs      int
-	noclobber bool
-	ctx       context.Context
+	auth       authn.Authenticator
+	insecure   bool
+	jobs       int
+	noclobber  bool
+	recompress bool
+	verify     bool
+	checkpoint string
+	ctx        context.Context
+	timeout    time.Duration
+
+	verifiers    []remote.VerifyFunc
+	pullPolicies []remote.Policy
+
+	annotations map[string]string
+	progress    func(v1.Update)
+	fs          fsOptions
+
+	layoutMatcher match.Matcher
+	list          listOptions
+	sources       map[string]Opener
+}
+
+// listOptions are the options for listing tags and catalogs.
+type listOptions struct {
+	limit      int
+	tagFilter  *regexp.Regexp
+	repoPrefix string
+	repoFilter *regexp.Regexp
+}
+
+// fsOptions are the options for AppendFS and Export.
+type fsOptions struct {
+	exclude  []string
+	include  []string
+	numeric  bool
+	owner    *[2]int // uid, gid
+	fileMode fs.FileMode
+	dirMode  fs.FileMode
+	modTime  *time.Time
+	dest     string
 }
 
 // GetOptions exposes the underlying []remote.Option, []name.Option, and
@@ -48,6 +91,16 @@ func GetOptions(opts ...Option) Options {
 	return makeOptions(opts...)
 }
 
+// nested returns opt for a crane operation that's part of the one o is for,
+// e.g. Open calling LoadTag, so that it shares o's WithTimeout deadline
+// rather than starting its own.
+func (o Options) nested(opt []Option) []Option {
+	if o.timeout == 0 {
+		return opt
+	}
+	return append(slices.Clip(opt), WithContext(o.ctx))
+}
+
 func makeOptions(opts ...Option) Options {
 	opt := Options{
 		Remote: []remote.Option{
@@ -62,6 +115,19 @@ func makeOptions(opts ...Option) Options {
 		o(&opt)
 	}
 
+	if opt.Platform == nil {
+		opt.Remote = append(opt.Remote, remote.WithPlatform(HostPlatform()))
+	}
+
+	if opt.timeout > 0 {
+		ctx, cancel := context.WithTimeout(opt.ctx, opt.timeout)
+		// The operation outlives makeOptions, so nothing can cancel the
+		// context when it's done; release it once it expires instead.
+		context.AfterFunc(ctx, cancel)
+		opt.ctx = ctx
+		opt.Remote = append(opt.Remote, remote.WithContext(ctx))
+	}
+
 	// Allow for untrusted certificates if the user
 	// passed Insecure but no custom transport.
 	if opt.insecure && opt.Transport == nil {
@@ -75,6 +141,22 @@ func makeOptions(opts ...Option) Options {
 		opt.Transport = remote.DefaultTransport
 	}
 
+	verifiers := slices.Clip(opt.verifiers)
+	if len(opt.pullPolicies) != 0 {
+		// Policies read what's pulled with every other option.
+		verifiers = append(verifiers, remote.PullPolicy(opt.pullPolicies, slices.Clip(opt.Remote)...))
+	}
+	if len(verifiers) != 0 {
+		opt.Remote = append(opt.Remote, remote.WithVerify(func(ctx context.Context, ref name.Reference, desc v1.Descriptor) error {
+			for _, verify := range verifiers {
+				if err := verify(ctx, ref, desc); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+	}
+
 	return opt
 }
 
@@ -99,7 +181,10 @@ func Insecure(o *Options) {
 	o.insecure = true
 }
 
-// WithPlatform is an Option to specify the platform.
+// WithPlatform is an Option to specify the platform. Operations that can
+// work on a whole index, like Copy, only work on the image for platform
+// if it's set. Those that need an image, like Pull, read the one for
+// HostPlatform from an index if it isn't.
 func WithPlatform(platform *v1.Platform) Option {
 	return func(o *Options) {
 		if platform != nil {
@@ -142,6 +227,32 @@ func WithUserAgent(ua string) Option {
 	}
 }
 
+// WithVerify is a functional option that runs verify on every manifest that
+// is pulled by reference, failing the pull if it returns an error; see
+// remote.WithVerify. It can be given more than once, and with
+// WithPullPolicy, to run them all.
+func WithVerify(verify remote.VerifyFunc) Option {
+	return func(o *Options) {
+		o.verifiers = append(o.verifiers, verify)
+	}
+}
+
+// WithPushPolicy is a functional option that runs policies on every image
+// or index before it's pushed; see remote.WithPushPolicy.
+func WithPushPolicy(policies ...remote.Policy) Option {
+	return func(o *Options) {
+		o.Remote = append(o.Remote, remote.WithPushPolicy(policies...))
+	}
+}
+
+// WithPullPolicy is a functional option that runs policies on every image
+// or index that's pulled, before it's returned; see remote.PullPolicy.
+func WithPullPolicy(policies ...remote.Policy) Option {
+	return func(o *Options) {
+		o.pullPolicies = append(o.pullPolicies, policies...)
+	}
+}
+
 // WithNondistributable is an option that allows pushing non-distributable
 // layers.
 func WithNondistributable() Option {
@@ -151,6 +262,10 @@ func WithNondistributable() Option {
 }
 
 // WithContext is a functional option for setting the context.
+//
+// Cancelling it aborts registry requests in flight, including layer
+// uploads and downloads, as well as Export and reading the layers that
+// AppendFS makes.
 func WithContext(ctx context.Context) Option {
 	return func(o *Options) {
 		o.ctx = ctx
@@ -158,6 +273,68 @@ func WithContext(ctx context.Context) Option {
 	}
 }
 
+// WithTimeout bounds each crane operation, e.g. a Pull, Push or Copy, to d
+// from when it's called, however many requests it makes, on top of
+// WithContext's context and any timeouts of the transport. The operation
+// fails with context.DeadlineExceeded when d runs out. Operations that
+// call others, like MutateConfig's Pull and Push, share one deadline.
+//
+// Images and layers that an operation returns, like Pull's, are read with
+// the same deadline, so they must be read within d too.
+func WithTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.timeout = d
+	}
+}
+
+// WithResumeDir keeps blobs in dir as they're pulled, so that interrupted
+// downloads are resumed rather than restarted; see remote.WithResumeDir.
+func WithResumeDir(dir string) Option {
+	return func(o *Options) {
+		o.Remote = append(o.Remote, remote.WithResumeDir(dir))
+	}
+}
+
+// WithSource makes Open, and the functions that accept the same schemes,
+// read references that start with scheme:// with open, e.g. from a local
+// daemon.
+func WithSource(scheme string, open Opener) Option {
+	return func(o *Options) {
+		if o.sources == nil {
+			o.sources = map[string]Opener{}
+		}
+		o.sources[scheme] = open
+	}
+}
+
+// WithRecompression lets Copy copy sources whose layers have to be
+// compressed on the way, like the tarballs that "docker save" writes.
+// Their digests are then computed as they're copied, rather than copied.
+func WithRecompression() Option {
+	return func(o *Options) {
+		o.recompress = true
+	}
+}
+
+// WithBlobVerification makes PushLayout check each blob against its digest
+// as it's pushed. Otherwise, the digests and sizes in the layout's
+// descriptors are trusted, and blobs are uploaded straight from their files.
+func WithBlobVerification() Option {
+	return func(o *Options) {
+		o.verify = true
+	}
+}
+
+// WithCheckpoint makes CopyRepository record each tag it copies in the file
+// at path, and skip the tags already recorded there whose source is
+// unchanged, so that a copy that was interrupted can pick up where it
+// stopped. Remove the file to start over.
+func WithCheckpoint(path string) Option {
+	return func(o *Options) {
+		o.checkpoint = path
+	}
+}
+
 // WithJobs sets the number of concurrent jobs to run.
 //
 // The default number of jobs is GOMAXPROCS.
@@ -170,9 +347,261 @@ func WithJobs(jobs int) Option {
 	}
 }
 
+// WithProgress calls f as Copy and CopyRepository upload blobs, with the
+// number of bytes they've found to upload so far and how many of those
+// they've uploaded. When the copy is done, f is called one last time, with
+// the error it failed with, if any.
+//
+// f is only called from one goroutine at a time, but it holds up the copy,
+// so it should return quickly.
+func WithProgress(f func(v1.Update)) Option {
+	return func(o *Options) {
+		o.progress = f
+	}
+}
+
+// WithRetryBackoff sets how many times, and how far apart, each blob upload
+// and other registry request that fails transiently is tried; see
+// remote.WithRetryBackoff. Blobs are retried one at a time, so a flaky
+// upload of one layer doesn't restart the rest of a copy.
+//
+// By default, each is tried three times, one second and then three seconds
+// apart.
+func WithRetryBackoff(backoff remote.Backoff) Option {
+	return func(o *Options) {
+		o.Remote = append(o.Remote, remote.WithRetryBackoff(backoff))
+	}
+}
+
+// WithIndexAnnotations sets annotations on the index that AssembleIndex
+// builds.
+func WithIndexAnnotations(annotations map[string]string) Option {
+	return func(o *Options) {
+		o.annotations = annotations
+	}
+}
+
+// WithExclude leaves files that match any of patterns out of the layers
+// that AppendFS builds and the tarballs that Export writes. Patterns use
+// path.Match syntax and are matched against paths relative to the root of
+// the fs.FS or image; patterns without a "/" also match any file with that
+// base name. Excluding a directory excludes everything in it.
+func WithExclude(patterns ...string) Option {
+	return func(o *Options) {
+		o.fs.exclude = append(o.fs.exclude, patterns...)
+	}
+}
+
+// WithInclude leaves everything but the files that match any of patterns,
+// and everything in the directories that do, out of the tarballs that
+// Export writes. Patterns are matched as with WithExclude, which takes
+// precedence.
+func WithInclude(patterns ...string) Option {
+	return func(o *Options) {
+		o.fs.include = append(o.fs.include, patterns...)
+	}
+}
+
+// WithNumericOwner leaves user and group names out of the tarballs that
+// Export writes, so that only the uid and gid of each file are kept, like
+// tar's --numeric-owner.
+func WithNumericOwner() Option {
+	return func(o *Options) {
+		o.fs.numeric = true
+	}
+}
+
+// WithOwner sets the uid and gid of every file in the layers that AppendFS
+// builds and the tarballs that Export writes. By default, AppendFS's files
+// are owned by root and Export's keep the owners they have in the image.
+func WithOwner(uid, gid int) Option {
+	return func(o *Options) {
+		o.fs.owner = &[2]int{uid, gid}
+	}
+}
+
+// WithMode sets the permissions of every regular file and directory in the
+// layers that AppendFS builds. A zero mode leaves the permissions the fs.FS
+// reports as they are.
+func WithMode(file, dir fs.FileMode) Option {
+	return func(o *Options) {
+		o.fs.fileMode = file.Perm()
+		o.fs.dirMode = dir.Perm()
+	}
+}
+
+// WithModTime sets the modification time of every file in the layers that
+// AppendFS builds and the tarballs that Export writes, so that they're
+// reproducible no matter when their files were written.
+func WithModTime(t time.Time) Option {
+	return func(o *Options) {
+		o.fs.modTime = &t
+	}
+}
+
+// WithDestination sets the directory in the image that AppendFS puts the
+// files of the fs.FS in. By default, it's the root.
+func WithDestination(dir string) Option {
+	return func(o *Options) {
+		o.fs.dest = dir
+	}
+}
+
+// WithLayoutMatcher selects the entry of an OCI image layout that
+// PushLayout pushes, e.g. with match.Annotation or match.Digests.
+func WithLayoutMatcher(m match.Matcher) Option {
+	return func(o *Options) {
+		o.layoutMatcher = m
+	}
+}
+
+// WithPageSize sets how many tags or repositories ListTags, IterTags,
+// Catalog and CatalogIterator ask the registry for at a time; see
+// remote.WithPageSize.
+func WithPageSize(size int) Option {
+	return func(o *Options) {
+		o.Remote = append(o.Remote, remote.WithPageSize(size))
+	}
+}
+
+// WithLast makes ListTags and IterTags only list the tags that sort after
+// tag, e.g. the last tag of a previous listing; see remote.WithLast.
+func WithLast(tag string) Option {
+	return func(o *Options) {
+		o.Remote = append(o.Remote, remote.WithLast(tag))
+	}
+}
+
+// WithLimit makes ListTags, IterTags, Catalog and CatalogIterator stop after
+// listing n tags or repositories, without fetching any more pages. Zero means
+// no limit.
+func WithLimit(n int) Option {
+	return func(o *Options) {
+		o.list.limit = n
+	}
+}
+
+// WithTagFilter makes ListTags and IterTags only list the tags that match
+// re. The registry still returns every tag, so this bounds memory, not how
+// many pages are fetched.
+func WithTagFilter(re *regexp.Regexp) Option {
+	return func(o *Options) {
+		o.list.tagFilter = re
+	}
+}
+
+// WithRepoPrefix makes Catalog and CatalogIterator only list the
+// repositories whose names start with prefix, e.g. "team/".
+func WithRepoPrefix(prefix string) Option {
+	return func(o *Options) {
+		o.list.repoPrefix = prefix
+	}
+}
+
+// WithRepoFilter makes Catalog and CatalogIterator only list the
+// repositories whose names match re. As with WithTagFilter, the registry
+// still returns every repository.
+func WithRepoFilter(re *regexp.Regexp) Option {
+	return func(o *Options) {
+		o.list.repoFilter = re
+	}
+}
+
 // WithNoClobber modifies behavior to avoid overwriting existing tags, if possible.
 func WithNoClobber(noclobber bool) Option {
 	return func(o *Options) {
 		o.noclobber = noclobber
 	}
 }
+
+// WithCache caches the layers of images that are read from a registry, e.g.
+// by Pull and Copy, in c, so that layers shared by several images are only
+// downloaded once. If c is a cache.BlobCache, manifests and config files of
+// the images' children are cached too.
+func WithCache(c cache.Cache) Option {
+	return func(o *Options) {
+		o.Cache = c
+	}
+}
+
+// cached wraps desc with o.Cache, if set, for pushing. Only images and
+// indexes are cached; anything else is returned as is.
+func (o *Options) cached(desc *remote.Descriptor) (remote.Taggable, error) {
+	if o.Cache == nil || desc.MediaType.IsSchema1() {
+		return desc, nil
+	}
+	if desc.MediaType.IsIndex() {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return nil, err
+		}
+		return cache.ImageIndex(idx, o.Cache), nil
+	}
+	if desc.MediaType.IsImage() {
+		img, err := desc.Image()
+		if err != nil {
+			return nil, err
+		}
+		return cache.Image(img, o.Cache), nil
+	}
+	return desc, nil
+}
+
+// cachedImage wraps img with o.Cache, if set.
+func (o *Options) cachedImage(img v1.Image) v1.Image {
+	if o.Cache == nil {
+		return img
+	}
+	return cache.Image(img, o.Cache)
+}
+
+// platform returns o.Platform, or HostPlatform if it isn't set.
+func (o *Options) platform() v1.Platform {
+	if o.Platform == nil {
+		return HostPlatform()
+	}
+	return *o.Platform
+}
+
+// HostPlatform returns the platform that crane reads images for from an
+// index when WithPlatform isn't set: the one it's running on, except that
+// on macOS, where containers run in a Linux VM, it's linux.
+func HostPlatform() v1.Platform {
+	p := v1.Platform{OS: runtime.GOOS, Architecture: runtime.GOARCH}
+	if p.OS == "darwin" {
+		p.OS = "linux"
+	}
+	return p
+}
+
+// cachedIndex wraps idx with o.Cache, if set.
+func (o *Options) cachedIndex(idx v1.ImageIndex) v1.ImageIndex {
+	if o.Cache == nil {
+		return idx
+	}
+	return cache.ImageIndex(idx, o.Cache)
+}
+
+// withProgress returns o.Remote, reporting progress to o.progress if it's
+// set, and a func that must be called with the result of the copy when
+// nothing more will be uploaded.
+func (o *Options) withProgress() ([]remote.Option, func(error)) {
+	if o.progress == nil {
+		return o.Remote, func(error) {}
+	}
+	updates := make(chan v1.Update, 64)
+	done := make(chan struct{})
+	var last v1.Update
+	go func() {
+		defer close(done)
+		for u := range updates {
+			last = u
+			o.progress(u)
+		}
+	}()
+	return append(slices.Clip(o.Remote), remote.WithProgress(updates)), func(err error) {
+		close(updates)
+		<-done
+		o.progress(v1.Update{Total: last.Total, Complete: last.Complete, Error: err})
+	}
+}
@@ -15,9 +15,13 @@
 package crane
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 )
@@ -56,3 +60,31 @@ func TestInsecureTransport(t *testing.T) {
 		t.Errorf("got: %t\nwant: %t", got, want)
 	}
 }
+
+func TestWithTimeout(t *testing.T) {
+	// A registry that never answers.
+	s := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer s.Close()
+	ref := strings.TrimPrefix(s.URL, "http://") + "/test:latest"
+
+	start := time.Now()
+	if _, err := Digest(ref, WithTimeout(100*time.Millisecond)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Digest(): want %v, got %v", context.DeadlineExceeded, err)
+	}
+	if took := time.Since(start); took > 10*time.Second {
+		t.Errorf("Digest() took %v, want about 100ms", took)
+	}
+}
+
+func TestWithTimeoutNested(t *testing.T) {
+	o := makeOptions(WithTimeout(time.Minute))
+	want, _ := o.ctx.Deadline()
+
+	time.Sleep(10 * time.Millisecond)
+	nested := makeOptions(o.nested([]Option{WithTimeout(time.Minute)})...)
+	if got, _ := nested.ctx.Deadline(); !got.Equal(want) {
+		t.Errorf("nested deadline: got %v, want %v", got, want)
+	}
+}
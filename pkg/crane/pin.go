@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	// FROM [--platform=...] IMAGE [AS NAME], in Dockerfiles.
+	fromRE = regexp.MustCompile(`(?im)^[ \t]*FROM[ \t]+(?:--\S+[ \t]+)*([^\s#]+)(?:[ \t]+AS[ \t]+(\S+))?`)
+	// image: IMAGE, in Kubernetes, compose and similar YAML.
+	imageRE = regexp.MustCompile(`(?m)^[ \t]*(?:-[ \t]+)?image:[ \t]*["']?([^\s"'#]+)`)
+)
+
+// reference is a reference to an image found in a file, at data[start:end].
+type reference struct {
+	ref        string
+	start, end int
+}
+
+// findReferences returns the references to images in data that could be
+// pinned: not already by digest, and not a build stage, scratch or a
+// template.
+func findReferences(data []byte) []reference {
+	var refs []reference
+	stages := map[string]bool{"scratch": true}
+	for _, m := range fromRE.FindAllSubmatchIndex(data, -1) {
+		refs = append(refs, reference{string(data[m[2]:m[3]]), m[2], m[3]})
+		if m[4] >= 0 {
+			stages[strings.ToLower(string(data[m[4]:m[5]]))] = true
+		}
+	}
+	for _, m := range imageRE.FindAllSubmatchIndex(data, -1) {
+		refs = append(refs, reference{string(data[m[2]:m[3]]), m[2], m[3]})
+	}
+	slices.SortFunc(refs, func(a, b reference) int { return a.start - b.start })
+
+	return slices.DeleteFunc(refs, func(r reference) bool {
+		if stages[strings.ToLower(r.ref)] || strings.ContainsAny(r.ref, "${}@") {
+			return true
+		}
+		_, err := name.ParseReference(r.ref)
+		return err != nil
+	})
+}
+
+// FindReferences returns the image references in data, a Dockerfile,
+// Kubernetes YAML or similar text file, that aren't pinned to a digest, in
+// the order they first appear.
+//
+// References are found in Dockerfile FROM instructions and YAML "image:"
+// fields. Build stages, scratch and references that use variables are
+// skipped.
+func FindReferences(data []byte) []string {
+	var refs []string
+	for _, r := range findReferences(data) {
+		if !slices.Contains(refs, r.ref) {
+			refs = append(refs, r.ref)
+		}
+	}
+	return refs
+}
+
+// Pin returns data with each reference that FindReferences finds and that
+// has a digest in digests pinned to that digest, e.g. "ubuntu:24.04"
+// becomes "ubuntu:24.04@sha256:...".
+func Pin(data []byte, digests map[string]string) []byte {
+	var buf bytes.Buffer
+	last := 0
+	for _, r := range findReferences(data) {
+		d, ok := digests[r.ref]
+		if !ok {
+			continue
+		}
+		buf.Write(data[last:r.end])
+		buf.WriteString("@" + d)
+		last = r.end
+	}
+	buf.Write(data[last:])
+	return buf.Bytes()
+}
+
+// ResolveDigests returns the digest of the image each of refs refers to,
+// keyed by ref. The digests are fetched concurrently, honoring WithJobs;
+// refs that already include a digest don't need to be fetched.
+//
+// As with Digest, if a platform is set, references to an index resolve to
+// the digest of the image for that platform.
+func ResolveDigests(refs []string, opt ...Option) (map[string]string, error) {
+	o := makeOptions(opt...)
+	digests := make(map[string]string, len(refs))
+	var fetch []string
+	for _, ref := range refs {
+		if _, ok := digests[ref]; ok {
+			continue
+		}
+		r, err := name.ParseReference(ref, o.Name...)
+		if err != nil {
+			return nil, err
+		}
+		if d, ok := r.(name.Digest); ok && o.Platform == nil {
+			digests[ref] = d.DigestStr()
+			continue
+		}
+		digests[ref] = ""
+		fetch = append(fetch, ref)
+	}
+
+	fetched := make([]string, len(fetch))
+	g, ctx := errgroup.WithContext(o.ctx)
+	g.SetLimit(o.jobs)
+	for i, ref := range fetch {
+		g.Go(func() error {
+			d, err := Digest(ref, append(slices.Clip(opt), WithContext(ctx))...)
+			if err != nil {
+				return err
+			}
+			fetched[i] = d
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	for i, ref := range fetch {
+		digests[ref] = fetched[i]
+	}
+	return digests, nil
+}
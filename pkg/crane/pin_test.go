@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+const dockerfile = `# syntax=docker/dockerfile:1
+FROM --platform=$BUILDPLATFORM golang:1.23 AS build
+FROM build AS test
+from ${BASE}
+FROM gcr.io/distroless/static@sha256:0000000000000000000000000000000000000000000000000000000000000000
+FROM scratch
+FROM alpine:3.20
+COPY --from=build /app /app
+`
+
+const manifests = `apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - name: app
+    image: "registry.example.com/app:v1"  # pinned by CI
+  - image: golang:1.23
+    name: sidecar
+`
+
+func TestFindReferences(t *testing.T) {
+	for _, tc := range []struct {
+		name, data string
+		want       []string
+	}{
+		{"dockerfile", dockerfile, []string{"golang:1.23", "alpine:3.20"}},
+		{"yaml", manifests, []string{"registry.example.com/app:v1", "golang:1.23"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := crane.FindReferences([]byte(tc.data)); !slices.Equal(got, tc.want) {
+				t.Errorf("FindReferences: got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPin(t *testing.T) {
+	got := crane.Pin([]byte(manifests), map[string]string{
+		"registry.example.com/app:v1": "sha256:a",
+		"golang:1.23":                 "sha256:b",
+	})
+	want := `apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - name: app
+    image: "registry.example.com/app:v1@sha256:a"  # pinned by CI
+  - image: golang:1.23@sha256:b
+    name: sidecar
+`
+	if string(got) != want {
+		t.Errorf("Pin: got\n%s\nwant\n%s", got, want)
+	}
+	if refs := crane.FindReferences(got); len(refs) != 0 {
+		t.Errorf("FindReferences after Pin: got %q, want none", refs)
+	}
+}
+
+func TestResolveDigests(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{}
+	var refs []string
+	for i := range 3 {
+		img, err := random.Image(100, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ref := fmt.Sprintf("%s/repo:%d", u.Host, i)
+		if err := crane.Push(img, ref); err != nil {
+			t.Fatal(err)
+		}
+		d, err := img.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[ref] = d.String()
+		refs = append(refs, ref, ref)
+	}
+	pinned := fmt.Sprintf("%s/repo@%s", u.Host, want[refs[0]])
+	want[pinned] = want[refs[0]]
+	refs = append(refs, pinned)
+
+	got, err := crane.ResolveDigests(refs, crane.WithJobs(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Errorf("ResolveDigests: got %d digests, want %d", len(got), len(want))
+	}
+	for ref, d := range want {
+		if got[ref] != d {
+			t.Errorf("ResolveDigests(%s): got %q, want %q", ref, got[ref], d)
+		}
+	}
+
+	if _, err := crane.ResolveDigests([]string{u.Host + "/repo:missing"}); err == nil {
+		t.Error("ResolveDigests: expected an error for a missing tag")
+	}
+}
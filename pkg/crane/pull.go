@@ -33,14 +33,26 @@ import (
 const iWasADigestTag = "i-was-a-digest"
 
 // Pull returns a v1.Image of the remote image src.
+//
+// If src is qualified with a platform, e.g. ubuntu@linux/arm64, that platform's
+// image is pulled from an index, see name.ParseWithPlatform. src may also
+// start with any scheme that Open accepts.
 func Pull(src string, opt ...Option) (v1.Image, error) {
+	if local(src) {
+		return OpenImage(src, opt...)
+	}
+	_, src = cutScheme(src)
 	o := makeOptions(opt...)
-	ref, err := name.ParseReference(src, o.Name...)
+	ref, err := name.ParseWithPlatform(src, o.Name...)
 	if err != nil {
 		return nil, fmt.Errorf("parsing reference %q: %w", src, err)
 	}
 
-	return remote.Image(ref, o.Remote...)
+	img, err := remote.Image(ref, o.Remote...)
+	if err != nil {
+		return nil, err
+	}
+	return o.cachedImage(img), nil
 }
 
 // Save writes the v1.Image img as a tarball at path with tag src.
@@ -55,10 +67,13 @@ func MultiSave(imgMap map[string]v1.Image, path string, opt ...Option) error {
 	tagToImage := map[name.Tag]v1.Image{}
 
 	for src, img := range imgMap {
-		ref, err := name.ParseReference(src, o.Name...)
+		ref, err := name.ParseWithPlatform(src, o.Name...)
 		if err != nil {
 			return fmt.Errorf("parsing ref %q: %w", src, err)
 		}
+		if pr, ok := ref.(name.PlatformReference); ok {
+			ref = pr.Reference
+		}
 
 		// WriteToFile wants a tag to write to the tarball, but we might have
 		// been given a digest.
@@ -100,10 +115,13 @@ func MultiSaveLegacy(imgMap map[string]v1.Image, path string) error {
 	refToImage := map[name.Reference]v1.Image{}
 
 	for src, img := range imgMap {
-		ref, err := name.ParseReference(src)
+		ref, err := name.ParseWithPlatform(src)
 		if err != nil {
 			return fmt.Errorf("parsing ref %q: %w", src, err)
 		}
+		if pr, ok := ref.(name.PlatformReference); ok {
+			ref = pr.Reference
+		}
 		refToImage[ref] = img
 	}
 
@@ -15,27 +15,47 @@
 package crane
 
 import (
+	"archive/tar"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/bundle"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 )
 
 // Load reads the tarball at path as a v1.Image.
+//
+// The tarball is either one that "docker save" writes or an OCI image
+// layout archive, e.g. from "docker buildx build -o type=oci" or "skopeo
+// copy oci-archive:". If an OCI archive's only entry is an index, the image
+// for WithPlatform, HostPlatform by default, is read from it; use LoadIndex
+// to read the whole index.
 func Load(path string, opt ...Option) (v1.Image, error) {
 	return LoadTag(path, "", opt...)
 }
 
 // LoadTag reads a tag from the tarball at path as a v1.Image.
-// If tag is "", will attempt to read the tarball as a single image.
+// If tag is "", will attempt to read the tarball as a single image, as Load
+// does.
 func LoadTag(path, tag string, opt ...Option) (v1.Image, error) {
+	o := makeOptions(opt...)
 	if tag == "" {
+		oci, docker, err := archiveFormat(path)
+		if err != nil {
+			return nil, err
+		}
+		if oci && !docker {
+			return loadOCIArchive(path, o)
+		}
 		return tarball.ImageFromPath(path, nil)
 	}
 
-	o := makeOptions(opt...)
 	t, err := name.NewTag(tag, o.Name...)
 	if err != nil {
 		return nil, fmt.Errorf("parsing tag %q: %w", tag, err)
@@ -43,6 +63,73 @@ func LoadTag(path, tag string, opt ...Option) (v1.Image, error) {
 	return tarball.ImageFromPath(path, &t)
 }
 
+// LoadIndex reads the OCI image layout archive at path, returning its
+// index.json as an index, like layout.ImageIndexFromPath does for a layout
+// on disk. Blobs are read from the file as they're needed, so it mustn't
+// change while the index is used.
+func LoadIndex(path string, _ ...Option) (v1.ImageIndex, error) {
+	b, err := bundle.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return b.Index()
+}
+
+// archiveFormat reports whether the tarball file holds an OCI image layout,
+// and whether it has the manifest.json that "docker save" writes. Newer
+// versions of docker write both.
+func archiveFormat(file string) (oci, docker bool, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return false, false, err
+	}
+	defer f.Close()
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return oci, docker, nil
+		}
+		if err != nil {
+			return false, false, err
+		}
+		switch path.Clean(hdr.Name) {
+		case "index.json":
+			oci = true
+		case "manifest.json":
+			docker = true
+		}
+	}
+}
+
+// loadOCIArchive reads the only image in the OCI image layout archive at
+// path, or the image for o.Platform if its only entry is an index.
+func loadOCIArchive(path string, o Options) (v1.Image, error) {
+	idx, err := LoadIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Manifests) != 1 {
+		return nil, fmt.Errorf("OCI archive %s contains %d entries, want 1", path, len(m.Manifests))
+	}
+	desc := m.Manifests[0]
+	switch {
+	case desc.MediaType.IsImage():
+		return idx.Image(desc.Digest)
+	case desc.MediaType.IsIndex():
+		child, err := idx.ImageIndex(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		return imageForPlatform(child, o.platform())
+	}
+	return nil, fmt.Errorf("OCI archive %s contains non-image (mediaType: %q)", path, desc.MediaType)
+}
+
 // Push pushes the v1.Image img to a registry as dst.
 func Push(img v1.Image, dst string, opt ...Option) error {
 	o := makeOptions(opt...)
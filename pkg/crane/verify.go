@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"io"
+
+	"github.com/google/go-containerregistry/internal/verify"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+)
+
+// verified wraps the image or index t so that the compressed contents of
+// its layers are checked against their digests and sizes as they're read.
+func verified(t partial.WithRawManifest) partial.WithRawManifest {
+	switch t := t.(type) {
+	case v1.Image:
+		return verifiedImage{t}
+	case v1.ImageIndex:
+		return verifiedIndex{t}
+	}
+	return t
+}
+
+// index lets verifiedIndex embed v1.ImageIndex and still override its
+// ImageIndex method.
+type index = v1.ImageIndex
+
+type verifiedIndex struct {
+	index
+}
+
+func (i verifiedIndex) Image(h v1.Hash) (v1.Image, error) {
+	img, err := i.index.Image(h)
+	if err != nil {
+		return nil, err
+	}
+	return verifiedImage{img}, nil
+}
+
+func (i verifiedIndex) ImageIndex(h v1.Hash) (v1.ImageIndex, error) {
+	idx, err := i.index.ImageIndex(h)
+	if err != nil {
+		return nil, err
+	}
+	return verifiedIndex{idx}, nil
+}
+
+type verifiedImage struct {
+	v1.Image
+}
+
+func (i verifiedImage) Layers() ([]v1.Layer, error) {
+	layers, err := i.Image.Layers()
+	if err != nil {
+		return nil, err
+	}
+	for j, l := range layers {
+		layers[j] = verifiedLayer{l}
+	}
+	return layers, nil
+}
+
+func (i verifiedImage) LayerByDigest(h v1.Hash) (v1.Layer, error) {
+	l, err := i.Image.LayerByDigest(h)
+	if err != nil {
+		return nil, err
+	}
+	return verifiedLayer{l}, nil
+}
+
+type verifiedLayer struct {
+	v1.Layer
+}
+
+func (l verifiedLayer) Compressed() (io.ReadCloser, error) {
+	digest, err := l.Digest()
+	if err != nil {
+		return nil, err
+	}
+	size, err := l.Size()
+	if err != nil {
+		return nil, err
+	}
+	rc, err := l.Layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	return verify.ReadCloser(rc, size, digest)
+}
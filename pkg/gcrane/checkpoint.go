@@ -0,0 +1,110 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcrane
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// checkpoint records the images that a recursive copy has finished copying,
+// one "src@digest dst tags" per line, so that a later copy can skip them. The
+// destination and tags are part of the key, so a checkpoint is only honored
+// by a copy to the same place with the same tags.
+type checkpoint struct {
+	mu   sync.Mutex
+	f    *os.File
+	done map[string]struct{}
+}
+
+// openCheckpoint opens the checkpoint file at path. If resume is true, the
+// images it already lists are loaded and new ones are appended; otherwise the
+// file is truncated.
+func openCheckpoint(path string, resume bool) (*checkpoint, error) {
+	c := &checkpoint{done: map[string]struct{}{}}
+
+	flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if resume {
+		b, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		// A line without a trailing newline was cut short by a crash, so
+		// drop it and copy that image again.
+		if i := bytes.LastIndexByte(b, '\n'); i+1 < len(b) {
+			if err := os.Truncate(path, int64(i+1)); err != nil {
+				return nil, err
+			}
+			b = b[:i+1]
+		}
+		s := bufio.NewScanner(bytes.NewReader(b))
+		for s.Scan() {
+			c.done[s.Text()] = struct{}{}
+		}
+		if err := s.Err(); err != nil {
+			return nil, fmt.Errorf("reading checkpoint %s: %w", path, err)
+		}
+	} else {
+		flag |= os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(path, flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	c.f = f
+	return c, nil
+}
+
+func checkpointKey(t task) string {
+	tags := slices.Clone(t.manifest.Tags)
+	slices.Sort(tags)
+	return fmt.Sprintf("%s@%s %s %s", t.oldRepo, t.digest, t.newRepo, strings.Join(tags, ","))
+}
+
+// has reports whether t was already copied.
+func (c *checkpoint) has(t task) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.done[checkpointKey(t)]
+	return ok
+}
+
+// record notes that t has been copied.
+func (c *checkpoint) record(t task) error {
+	key := checkpointKey(t)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.done[key]; ok {
+		return nil
+	}
+	if _, err := io.WriteString(c.f, key+"\n"); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	c.done[key] = struct{}{}
+	return nil
+}
+
+func (c *checkpoint) Close() error {
+	return c.f.Close()
+}
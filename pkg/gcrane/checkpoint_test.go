@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcrane
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/google"
+)
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	src := name.MustParseReference("registry.example.com/foo").Context()
+	dst := name.MustParseReference("registry.example.com/bar").Context()
+	mk := func(digest string, tags ...string) task {
+		return task{digest: digest, manifest: google.ManifestInfo{Tags: tags}, oldRepo: src, newRepo: dst}
+	}
+
+	c, err := openCheckpoint(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tk := range []task{mk("sha256:a"), mk("sha256:b", "v2", "v1"), mk("sha256:a")} {
+		if err := c.record(tk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash part of the way through writing a line.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("registry.example.com/foo@sha256:c registry.example.com/bar "); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	c, err = openCheckpoint(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		task task
+		want bool
+	}{
+		{mk("sha256:a"), true},
+		{mk("sha256:b", "v1", "v2"), true},
+		{mk("sha256:c"), false},
+		// A different set of tags or a different destination is a
+		// different copy.
+		{mk("sha256:a", "latest"), false},
+		{mk("sha256:b", "v1"), false},
+		{task{digest: "sha256:a", oldRepo: src, newRepo: src}, false},
+	} {
+		if got := c.has(tc.task); got != tc.want {
+			t.Errorf("has(%s): got %t, want %t", checkpointKey(tc.task), got, tc.want)
+		}
+	}
+	if err := c.record(mk("sha256:c")); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "registry.example.com/foo@sha256:a registry.example.com/bar \n" +
+		"registry.example.com/foo@sha256:b registry.example.com/bar v1,v2\n" +
+		"registry.example.com/foo@sha256:c registry.example.com/bar \n"
+	if got := string(b); got != want {
+		t.Errorf("checkpoint: got %q, want %q", got, want)
+	}
+
+	// Without resuming, the checkpoint starts over.
+	c, err = openCheckpoint(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c.has(mk("sha256:a")) {
+		t.Errorf("has(sha256:a) after starting over")
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Size() != 0 {
+		t.Errorf("checkpoint was not truncated: %v, %v", fi, err)
+	}
+}
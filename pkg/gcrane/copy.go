@@ -19,6 +19,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"path"
+	"slices"
 	"strings"
 	"time"
 
@@ -27,8 +29,9 @@ import (
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/reposync"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/google"
-	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"golang.org/x/sync/errgroup"
 )
@@ -87,6 +90,9 @@ type copier struct {
 
 	tasks chan task
 	opt   *options
+
+	// checkpoint is nil unless WithCheckpoint was given.
+	checkpoint *checkpoint
 }
 
 func newCopier(src, dst string, o *options) (*copier, error) {
@@ -103,7 +109,15 @@ func newCopier(src, dst string, o *options) (*copier, error) {
 	// A queue of size 2*jobs should keep each goroutine busy.
 	tasks := make(chan task, o.jobs*2)
 
-	return &copier{srcRepo, dstRepo, tasks, o}, nil
+	c := &copier{srcRepo: srcRepo, dstRepo: dstRepo, tasks: tasks, opt: o}
+	if o.checkpoint != "" {
+		if c.checkpoint, err = openCheckpoint(o.checkpoint, o.resume); err != nil {
+			return nil, fmt.Errorf("opening checkpoint: %w", err)
+		}
+	} else if o.resume {
+		return nil, errors.New("resuming a copy requires a checkpoint")
+	}
+	return c, nil
 }
 
 // recursiveCopy copies images from repo src to repo dst.
@@ -112,9 +126,16 @@ func recursiveCopy(ctx context.Context, src, dst string, o *options) error {
 	if err != nil {
 		return err
 	}
+	if c.checkpoint != nil {
+		defer c.checkpoint.Close()
+	}
 
 	g, ctx := errgroup.WithContext(ctx)
 	walkFn := func(repo name.Repository, tags *google.Tags, err error) error {
+		if !c.included(repo) {
+			logs.Progress.Printf("Skipping %s", repo)
+			return nil
+		}
 		if err != nil {
 			logs.Warn.Printf("failed walkFn for repo %s: %v", repo, err)
 			// If we hit an error when listing the repo, try re-listing with backoff.
@@ -149,13 +170,14 @@ func recursiveCopy(ctx context.Context, src, dst string, o *options) error {
 	for i := 0; i < o.jobs; i++ {
 		g.Go(func() error {
 			for task := range c.tasks {
-				// If we hit an error when trying to copy the images,
-				// retry with backoff.
-				if err := backoffErrors(GCRBackoff(), func() error {
-					return c.copyImages(ctx, task)
-				}); err != nil {
+				if err := c.copyImages(ctx, task); err != nil {
 					return fmt.Errorf("failed to copy %q: %w", task.digest, err)
 				}
+				if c.checkpoint != nil {
+					if err := c.checkpoint.record(task); err != nil {
+						return err
+					}
+				}
 			}
 			return nil
 		})
@@ -174,7 +196,7 @@ func (c *copier) copyRepo(ctx context.Context, oldRepo name.Repository, tags *go
 	}
 
 	// Figure out what we actually need to copy.
-	want := tags.Manifests
+	want := c.filterTags(tags.Manifests)
 	have := make(map[string]google.ManifestInfo)
 	haveTags, err := google.List(newRepo, c.opt.google...)
 	if err != nil {
@@ -184,7 +206,7 @@ func (c *copier) copyRepo(ctx context.Context, oldRepo name.Repository, tags *go
 		// This is a 404 code, so we just need to copy everything.
 		logs.Warn.Printf("failed to list %s: %v", newRepo, err)
 	} else {
-		have = haveTags.Manifests
+		have = c.filterTags(haveTags.Manifests)
 	}
 	need := diffImages(want, have)
 
@@ -196,6 +218,9 @@ func (c *copier) copyRepo(ctx context.Context, oldRepo name.Repository, tags *go
 			oldRepo:  oldRepo,
 			newRepo:  newRepo,
 		}
+		if c.checkpoint != nil && c.checkpoint.has(t) {
+			continue
+		}
 		select {
 		case c.tasks <- t:
 		case <-ctx.Done():
@@ -206,50 +231,26 @@ func (c *copier) copyRepo(ctx context.Context, oldRepo name.Repository, tags *go
 	return nil
 }
 
-// copyImages starts a goroutine for each tag that points to the image
-// oldRepo@digest, or just copies the image by digest if there are no tags.
-func (c *copier) copyImages(_ context.Context, t task) error {
-	// We only have to explicitly copy by digest if there are no tags pointing to this manifest.
-	if len(t.manifest.Tags) == 0 {
-		srcImg := fmt.Sprintf("%s@%s", t.oldRepo, t.digest)
-		dstImg := fmt.Sprintf("%s@%s", t.newRepo, t.digest)
-
-		return crane.Copy(srcImg, dstImg, c.opt.crane...)
-	}
-
-	// We only need to push the whole image once.
-	tag := t.manifest.Tags[0]
-	srcImg := fmt.Sprintf("%s:%s", t.oldRepo, tag)
-	dstImg := fmt.Sprintf("%s:%s", t.newRepo, tag)
-
-	if err := crane.Copy(srcImg, dstImg, c.opt.crane...); err != nil {
-		return err
-	}
-
-	if len(t.manifest.Tags) <= 1 {
-		// If there's only one tag, we're done.
-		return nil
-	}
-
-	// Add the rest of the tags.
-	srcRef, err := name.ParseReference(srcImg)
+// copyImages copies the image oldRepo@digest to each tag that points to it,
+// or just by digest if there are no tags, retrying with GCRBackoff.
+func (c *copier) copyImages(ctx context.Context, t task) error {
+	h, err := v1.NewHash(t.digest)
 	if err != nil {
 		return err
 	}
-	desc, err := remote.Get(srcRef, c.opt.remote...)
-	if err != nil {
-		return err
+	p := &reposync.Plan{}
+	if len(t.manifest.Tags) == 0 {
+		p.Actions = append(p.Actions, reposync.Action{Op: reposync.Copy, Src: t.oldRepo.Digest(t.digest), Dst: t.newRepo.Digest(t.digest), Digest: h})
 	}
-
-	for _, tag := range t.manifest.Tags[1:] {
-		dstImg := t.newRepo.Tag(tag)
-
-		if err := remote.Tag(dstImg, desc, c.opt.remote...); err != nil {
-			return err
-		}
+	for _, tag := range t.manifest.Tags {
+		p.Actions = append(p.Actions, reposync.Action{Op: reposync.Copy, Src: t.oldRepo.Tag(tag), Dst: t.newRepo.Tag(tag), Digest: h})
 	}
-
-	return nil
+	// Copy the tags one at a time, so that only the first pushes blobs.
+	_, err = reposync.Execute(ctx, p,
+		reposync.WithRemoteOptions(c.opt.remote...),
+		reposync.WithJobs(1),
+		reposync.WithRetryBackoff(GCRBackoff()))
+	return err
 }
 
 // Retry temporary errors, 429, and 500+ with backoff.
@@ -298,6 +299,55 @@ func (c *copier) rename(repo name.Repository) (name.Repository, error) {
 	return name.NewRepository(replaced, name.StrictValidation)
 }
 
+// included reports whether repo passes the include and exclude patterns,
+// which are matched against its path relative to c.srcRepo and each of that
+// path's parents, e.g.:
+//
+// $ gcrane cp -r gcr.io/foo gcr.io/baz --exclude scratch
+//
+// included("gcr.io/foo/scratch/bar") == false
+func (c *copier) included(repo name.Repository) bool {
+	rel := strings.TrimPrefix(repo.RepositoryStr(), c.srcRepo.RepositoryStr())
+	rel = strings.TrimPrefix(rel, "/")
+
+	matches := func(patterns []string) bool {
+		for p := rel; p != "" && p != "."; p = path.Dir(p) {
+			for _, pattern := range patterns {
+				if ok, _ := path.Match(pattern, p); ok {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if matches(c.opt.exclude) {
+		return false
+	}
+	return len(c.opt.include) == 0 || matches(c.opt.include)
+}
+
+// filterTags returns the manifests with at least one tag matching the tag
+// regexp, keeping only the matching tags, or manifests itself if there is no
+// tag regexp.
+func (c *copier) filterTags(manifests map[string]google.ManifestInfo) map[string]google.ManifestInfo {
+	if c.opt.tagRegexp == nil {
+		return manifests
+	}
+	filtered := make(map[string]google.ManifestInfo)
+	for digest, m := range manifests {
+		tags := slices.DeleteFunc(slices.Clone(m.Tags), func(tag string) bool {
+			return !c.opt.tagRegexp.MatchString(tag)
+		})
+		if len(tags) == 0 {
+			continue
+		}
+		m.Tags = tags
+		filtered[digest] = m
+	}
+	return filtered
+}
+
 // diffImages returns a map of digests to google.ManifestInfos for images or
 // tags that are present in "want" but not in "have".
 func diffImages(want, have map[string]google.ManifestInfo) map[string]google.ManifestInfo {
@@ -25,6 +25,8 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -197,6 +199,46 @@ func TestCopy(t *testing.T) {
 	if err := CopyRepository(context.Background(), src, dst); err != nil {
 		t.Fatal(err)
 	}
+
+	// Resuming from a checkpoint that lists every image copies nothing.
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint")
+	resumed := path.Join(u.Host, "test/gcrane/resumed")
+	if err := CopyRepository(context.Background(), src, resumed, WithCheckpoint(checkpoint)); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(checkpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Count(string(b), "\n"), 3; got != want {
+		t.Errorf("checkpoint has %d images, want %d:\n%s", got, want, b)
+	}
+	resumedRepo, err := name.NewRepository(resumed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumedDigest := resumedRepo.Digest(d.String())
+	if err := remote.Delete(resumedDigest); err != nil {
+		t.Fatal(err)
+	}
+	if err := CopyRepository(context.Background(), src, resumed, WithCheckpoint(checkpoint), WithResume(true)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := remote.Head(resumedDigest); err == nil {
+		t.Errorf("resumed copy copied %s", resumedDigest)
+	}
+
+	// The checkpoint is only honored for the same destination.
+	other, err := name.NewRepository(path.Join(u.Host, "test/gcrane/other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CopyRepository(context.Background(), src, other.String(), WithCheckpoint(checkpoint), WithResume(true)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := remote.Head(other.Digest(d.String())); err != nil {
+		t.Errorf("resumed copy to another destination skipped %s: %v", other.Digest(d.String()), err)
+	}
 }
 
 func TestRename(t *testing.T) {
@@ -216,6 +258,70 @@ func TestRename(t *testing.T) {
 	}
 }
 
+func TestIncluded(t *testing.T) {
+	cases := []struct {
+		include, exclude []string
+		repo             string
+		want             bool
+	}{{
+		repo: "registry.example.com/foo/sub/repo",
+		want: true,
+	}, {
+		exclude: []string{"sub"},
+		repo:    "registry.example.com/foo/sub/repo",
+		want:    false,
+	}, {
+		exclude: []string{"scratch-*"},
+		repo:    "registry.example.com/foo/sub/repo",
+		want:    true,
+	}, {
+		include: []string{"sub/*"},
+		repo:    "registry.example.com/foo/sub/repo",
+		want:    true,
+	}, {
+		include: []string{"sub/*"},
+		repo:    "registry.example.com/foo/other",
+		want:    false,
+	}, {
+		include: []string{"sub"},
+		exclude: []string{"sub/repo"},
+		repo:    "registry.example.com/foo/sub/repo",
+		want:    false,
+	}}
+
+	for _, tc := range cases {
+		c := copier{
+			srcRepo: name.MustParseReference("registry.example.com/foo").Context(),
+			opt:     makeOptions(WithInclude(tc.include...), WithExclude(tc.exclude...)),
+		}
+		repo, err := name.NewRepository(tc.repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.included(repo); got != tc.want {
+			t.Errorf("included(%q) with include=%v exclude=%v: got %t, want %t", tc.repo, tc.include, tc.exclude, got, tc.want)
+		}
+	}
+}
+
+func TestFilterTags(t *testing.T) {
+	c := copier{opt: makeOptions(WithTagRegexp(regexp.MustCompile(`^v\d+\.\d+\.\d+$`)))}
+	manifests := map[string]google.ManifestInfo{
+		"a": {Tags: []string{"latest", "v1.2.3"}},
+		"b": {Tags: []string{"dev"}},
+		"c": {},
+	}
+	want := map[string]google.ManifestInfo{
+		"a": {Tags: []string{"v1.2.3"}},
+	}
+	if diff := cmp.Diff(want, c.filterTags(manifests)); diff != "" {
+		t.Errorf("filterTags: (-want +got)\n%s", diff)
+	}
+	if got := manifests["a"].Tags; len(got) != 2 {
+		t.Errorf("filterTags modified its input: %v", got)
+	}
+}
+
 func TestSubtractStringLists(t *testing.T) {
 	cases := []struct {
 		minuend    []string
@@ -17,6 +17,7 @@ package gcrane
 import (
 	"context"
 	"net/http"
+	"regexp"
 	"runtime"
 
 	"github.com/google/go-containerregistry/pkg/authn"
@@ -33,6 +34,12 @@ type options struct {
 	remote []remote.Option
 	google []google.Option
 	crane  []crane.Option
+
+	include, exclude []string
+	tagRegexp        *regexp.Regexp
+
+	checkpoint string
+	resume     bool
 }
 
 func makeOptions(opts ...Option) *options {
@@ -65,6 +72,53 @@ func WithJobs(jobs int) Option {
 	}
 }
 
+// WithInclude is a functional option for CopyRepository that only copies
+// repositories whose path, relative to the source repository, matches one of
+// the given path.Match patterns. A pattern that matches a repository also
+// matches everything below it.
+func WithInclude(patterns ...string) Option {
+	return func(o *options) {
+		o.include = append(o.include, patterns...)
+	}
+}
+
+// WithExclude is a functional option for CopyRepository that skips
+// repositories whose path, relative to the source repository, matches one of
+// the given path.Match patterns. A pattern that matches a repository also
+// matches everything below it. Exclusions take precedence over WithInclude.
+func WithExclude(patterns ...string) Option {
+	return func(o *options) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
+
+// WithTagRegexp is a functional option for CopyRepository that only copies
+// tags matching re, and only images with at least one such tag.
+func WithTagRegexp(re *regexp.Regexp) Option {
+	return func(o *options) {
+		o.tagRegexp = re
+	}
+}
+
+// WithCheckpoint is a functional option for CopyRepository that records each
+// image it finishes copying in the file at path, so that a copy that fails
+// part of the way through can be resumed with WithResume.
+func WithCheckpoint(path string) Option {
+	return func(o *options) {
+		o.checkpoint = path
+	}
+}
+
+// WithResume is a functional option for CopyRepository that skips the images
+// already recorded in the WithCheckpoint file, rather than starting over. An
+// image is only skipped if it was recorded as copied to the same destination
+// with the same tags.
+func WithResume(resume bool) Option {
+	return func(o *options) {
+		o.resume = resume
+	}
+}
+
 // WithTransport is a functional option for overriding the default transport
 // for remote operations.
 func WithTransport(t http.RoundTripper) Option {
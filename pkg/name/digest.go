@@ -81,6 +81,7 @@ func (d *Digest) UnmarshalJSON(data []byte) error {
 
 // NewDigest returns a new Digest representing the given name.
 func NewDigest(name string, opts ...Option) (Digest, error) {
+	opt := makeOptions(opts...)
 	// Split on "@"
 	parts := strings.Split(name, digestDelim)
 	if len(parts) != 2 {
@@ -88,22 +89,27 @@ func NewDigest(name string, opts ...Option) (Digest, error) {
 	}
 	base := parts[0]
 	dig := parts[1]
-	prefix := digest.Canonical.String() + ":"
-	if !strings.HasPrefix(dig, prefix) {
-		return Digest{}, newErrBadName("unsupported digest algorithm: %s", dig)
-	}
-	hex := strings.TrimPrefix(dig, prefix)
-	if err := digest.Canonical.Validate(hex); err != nil {
-		return Digest{}, err
+	if opt.oci {
+		if err := checkOCIDigest(dig); err != nil {
+			return Digest{}, err
+		}
+	} else {
+		prefix := digest.Canonical.String() + ":"
+		if !strings.HasPrefix(dig, prefix) {
+			return Digest{}, newErrBadName("unsupported digest algorithm: %s", dig)
+		}
+		hex := strings.TrimPrefix(dig, prefix)
+		if err := digest.Canonical.Validate(hex); err != nil {
+			return Digest{}, err
+		}
 	}
 
-	tag, err := NewTag(base, opts...)
-	if err == nil {
-		base = tag.Repository.Name()
-	}
-
-	repo, err := NewRepository(base, opts...)
-	if err != nil {
+	// Any tag is dropped, but the repository must not be parsed twice, or it
+	// would be rewritten twice.
+	var repo Repository
+	if tag, err := NewTag(base, opts...); err == nil {
+		repo = tag.Repository
+	} else if repo, err = NewRepository(base, opts...); err != nil {
 		return Digest{}, err
 	}
 	return Digest{
@@ -26,7 +26,8 @@
 // any host that looks like localhost (localhost, 127.0.0.1, ::1), ends in
 // ".local", or is in the "private" address space per RFC 1918. For everything
 // else, we assume https only. To override this heuristic, use the Insecure
-// option.
+// option, or InsecureRegistries to only do so for some registries, e.g. those
+// in the docker daemon's configuration, see mirrorconfig.DockerInsecureRegistries.
 //
 // Image references with a digest signal to us that we should verify the content
 // of the image matches the digest. E.g. when pulling a Digest reference, we'll
@@ -39,4 +40,13 @@
 // "latest". To disable this defaulting, use the StrictValidation option. This
 // is useful e.g. to only allow image references that explicitly set a tag or
 // digest, so that you don't accidentally pull "latest".
+//
+// References are parsed with docker's grammar, which is more permissive than
+// the OCI distribution spec's, e.g. in where separators may appear in tags. To
+// only accept references that are valid per the spec, use the StrictOCI option.
+//
+// To pull through a mirror, or from a registry that a repository has moved to,
+// use the WithRewrites option, with Rewrites that may be read from the docker
+// daemon's configuration with mirrorconfig.DockerMirrors, or from containerd's
+// with mirrorconfig.ContainerdHosts.
 package name
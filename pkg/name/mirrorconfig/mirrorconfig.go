@@ -0,0 +1,213 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mirrorconfig reads registry mirror and insecure registry settings
+// from docker and containerd configuration files, for use with name.Option.
+package mirrorconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+const (
+	// ContainerdHostsDir is where containerd looks for registry hosts
+	// configuration by default.
+	ContainerdHostsDir = "/etc/containerd/certs.d"
+
+	// DockerDaemonConfig is where the docker daemon's configuration lives by
+	// default.
+	DockerDaemonConfig = "/etc/docker/daemon.json"
+)
+
+// DockerMirrors returns name.Rewrites for the "registry-mirrors" of Docker Hub
+// in the docker daemon configuration at path, see DockerDaemonConfig.
+func DockerMirrors(path string) ([]name.Rewrite, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		RegistryMirrors []string `json:"registry-mirrors"`
+	}
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	rules := make([]name.Rewrite, 0, len(config.RegistryMirrors))
+	for _, mirror := range config.RegistryMirrors {
+		u, err := url.Parse(mirror)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if strings.Trim(u.Path, "/") != "" {
+			return nil, fmt.Errorf("parsing %s: registry mirror %q must not have a path", path, mirror)
+		}
+		rules = append(rules, name.Rewrite{
+			From:     name.DefaultRegistry,
+			To:       u.Host,
+			Insecure: u.Scheme == "http",
+		})
+	}
+	return rules, nil
+}
+
+// DockerInsecureRegistries returns the "insecure-registries" in the docker
+// daemon configuration at path, see DockerDaemonConfig, for use with the
+// name.InsecureRegistries Option.
+func DockerInsecureRegistries(path string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		InsecureRegistries []string `json:"insecure-registries"`
+	}
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	registries := make([]string, len(config.InsecureRegistries))
+	for i, r := range config.InsecureRegistries {
+		// Docker tolerates a scheme, which we don't.
+		if _, host, ok := strings.Cut(r, "://"); ok {
+			r = host
+		}
+		registries[i] = strings.TrimSuffix(r, "/")
+	}
+	return registries, nil
+}
+
+// hostsFile is the subset of containerd's hosts.toml that can be expressed as
+// name.Rewrites.
+// https://github.com/containerd/containerd/blob/main/docs/hosts.md
+type hostsFile struct {
+	Server string                `toml:"server"`
+	Host   map[string]hostConfig `toml:"host"`
+}
+
+type hostConfig struct {
+	Capabilities []string `toml:"capabilities"`
+	OverridePath bool     `toml:"override_path"`
+}
+
+// ContainerdHosts returns name.Rewrites for the registry hosts configured under
+// dir, see ContainerdHostsDir.
+//
+// Each registry is rewritten to the first of its hosts that can be pulled
+// from, or otherwise to its server. Hosts that serve the registry API under a
+// path can't be expressed as a reference, so are skipped, as is the _default
+// configuration, which applies to every registry.
+func ContainerdHosts(dir string) ([]name.Rewrite, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []name.Rewrite
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == "_default" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name(), "hosts.toml")
+		rule, ok, err := containerdHost(entry.Name(), path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func containerdHost(registry, path string) (name.Rewrite, bool, error) {
+	var hosts hostsFile
+	md, err := toml.DecodeFile(path, &hosts)
+	if err != nil {
+		return name.Rewrite{}, false, err
+	}
+
+	// The hosts are tried in the order they're written, which the map loses.
+	for _, key := range md.Keys() {
+		if len(key) != 2 || key[0] != "host" {
+			continue
+		}
+		host := hosts.Host[key[1]]
+		if host.Capabilities != nil && !slices.Contains(host.Capabilities, "pull") {
+			continue
+		}
+		if rule, ok := hostRewrite(registry, key[1], host.OverridePath); ok {
+			return rule, true, nil
+		}
+	}
+
+	if hosts.Server == "" {
+		return name.Rewrite{}, false, nil
+	}
+	rule, ok := hostRewrite(registry, hosts.Server, false)
+	if !ok {
+		return name.Rewrite{}, false, nil
+	}
+	// containerd's upstream for docker.io is registry-1.docker.io, which we
+	// already talk to for index.docker.io.
+	if canonicalPrefix(rule.From) == canonicalPrefix(rule.To) ||
+		(canonicalPrefix(rule.From) == name.DefaultRegistry && rule.To == "registry-1.docker.io") {
+		return name.Rewrite{}, false, nil
+	}
+	return rule, true, nil
+}
+
+// hostRewrite returns a name.Rewrite of registry to the host at u, if it
+// serves the registry API at /v2, where references expect it.
+func hostRewrite(registry, u string, overridePath bool) (name.Rewrite, bool) {
+	if !strings.Contains(u, "://") {
+		u = "https://" + u
+	}
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Host == "" {
+		return name.Rewrite{}, false
+	}
+	// Without override_path, containerd appends /v2 to the path.
+	switch p := strings.TrimSuffix(parsed.Path, "/"); {
+	case p == "/v2":
+	case p == "" && !overridePath:
+	default:
+		return name.Rewrite{}, false
+	}
+	return name.Rewrite{
+		From:     registry,
+		To:       parsed.Host,
+		Insecure: parsed.Scheme == "http",
+	}, true
+}
+
+// canonicalPrefix spells Docker Hub the way name.NewRegistry does.
+func canonicalPrefix(prefix string) string {
+	if reg, _, _ := strings.Cut(prefix, "/"); reg == "docker.io" {
+		return name.DefaultRegistry + strings.TrimPrefix(prefix, reg)
+	}
+	return prefix
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirrorconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+func TestDockerMirrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.json")
+	if err := os.WriteFile(path, []byte(`{
+  "debug": true,
+  "registry-mirrors": ["https://mirror.gcr.io", "http://10.0.0.1:5000/"]
+}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := DockerMirrors(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []name.Rewrite{
+		{From: name.DefaultRegistry, To: "mirror.gcr.io"},
+		{From: name.DefaultRegistry, To: "10.0.0.1:5000", Insecure: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DockerMirrors(): got %+v, want %+v", got, want)
+	}
+}
+
+func TestDockerInsecureRegistries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.json")
+	if err := os.WriteFile(path, []byte(`{
+  "insecure-registries": ["localhost:5000", "http://registry.internal/", "100.64.0.0/10"]
+}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := DockerInsecureRegistries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"localhost:5000", "registry.internal", "100.64.0.0/10"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DockerInsecureRegistries(): got %v, want %v", got, want)
+	}
+}
+
+func TestContainerdHosts(t *testing.T) {
+	dir := t.TempDir()
+	for registry, hosts := range map[string]string{
+		// The first host that can be pulled from wins.
+		"docker.io": `
+server = "https://registry-1.docker.io"
+
+[host."https://push-only.example.com"]
+  capabilities = ["push"]
+
+[host."https://zz.example.com"]
+  capabilities = ["pull", "resolve"]
+
+[host."https://aa.example.com"]
+`,
+		// Falls back to the server.
+		"gcr.io": `
+server = "http://gcr-proxy.internal:5000"
+
+[host."https://proxy.example.com/prefix"]
+`,
+		"quay.io": `
+[host."https://quay-proxy.example.com/v2"]
+  override_path = true
+`,
+		// Nothing to rewrite.
+		"ghcr.io":    `server = "https://ghcr.io"`,
+		"_default":   `[host."https://everything.example.com"]`,
+		"empty.test": ``,
+	} {
+		if err := os.MkdirAll(filepath.Join(dir, registry), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, registry, "hosts.toml"), []byte(hosts), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "no-hosts.test"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ContainerdHosts(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []name.Rewrite{
+		{From: "docker.io", To: "zz.example.com"},
+		{From: "gcr.io", To: "gcr-proxy.internal:5000", Insecure: true},
+		{From: "quay.io", To: "quay-proxy.example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ContainerdHosts(): got %+v, want %+v", got, want)
+	}
+
+	ref, err := name.ParseReference("ubuntu", name.WithRewrites(got...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ref.Name(), "zz.example.com/library/ubuntu:latest"; got != want {
+		t.Errorf("ParseReference(): got %q, want %q", got, want)
+	}
+}
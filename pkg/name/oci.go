@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package name
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The grammar of the OCI distribution spec.
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pulling-manifests
+var (
+	ociRepository = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+	ociTag        = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`)
+
+	// https://github.com/opencontainers/image-spec/blob/main/descriptor.md#digests
+	ociDigestAlgorithm = regexp.MustCompile(`^[a-z0-9]+([+._-][a-z0-9]+)*$`)
+	ociDigestEncoded   = regexp.MustCompile(`^[a-zA-Z0-9=_-]+$`)
+)
+
+// ociDigestLengths are the lengths of the encoded part of the digests of the
+// registered algorithms.
+var ociDigestLengths = map[string]int{
+	"sha256": 64,
+	"sha512": 128,
+}
+
+// The length limit that the spec says many clients impose on the
+// registry and repository together.
+const ociMaxNameLength = 255
+
+// StrictOCI is an Option that validates references exactly as the OCI
+// distribution spec defines them, rather than as docker does.
+//
+// It implies StrictValidation, so nothing is defaulted, including the
+// implicit "library/" namespace of Docker Hub. Repositories and tags must
+// match the spec's grammar, and digests must use one of the registered
+// algorithms, sha256 or sha512.
+func StrictOCI(opts *options) {
+	opts.strict = true
+	opts.oci = true
+}
+
+func checkOCIRepository(registry, repository string) error {
+	if !ociRepository.MatchString(repository) {
+		return newErrBadName("repository must match %s: %s", ociRepository, repository)
+	}
+	if n := len(registry) + len(regRepoDelimiter) + len(repository); n > ociMaxNameLength {
+		return newErrBadName("registry and repository must be at most %d characters in length, got %d", ociMaxNameLength, n)
+	}
+	return nil
+}
+
+func checkOCITag(tag string) error {
+	if !ociTag.MatchString(tag) {
+		return newErrBadName("tag must match %s: %s", ociTag, tag)
+	}
+	return nil
+}
+
+func checkOCIDigest(dig string) error {
+	alg, encoded, ok := strings.Cut(dig, ":")
+	if !ok || !ociDigestAlgorithm.MatchString(alg) || !ociDigestEncoded.MatchString(encoded) {
+		return newErrBadName("digest must be of the form algorithm:encoded: %s", dig)
+	}
+	n, ok := ociDigestLengths[alg]
+	if !ok {
+		return newErrBadName("unsupported digest algorithm: %s", dig)
+	}
+	if len(encoded) != n || strings.Trim(encoded, "0123456789abcdef") != "" {
+		return newErrBadName("%s digests must be %d lowercase hex characters: %s", alg, n, dig)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package name
+
+import (
+	"strings"
+	"testing"
+)
+
+var goodStrictOCINames = []string{
+	"gcr.io/g-convoy/hello-world:latest",
+	"gcr.io/project-id/with-nums:v2",
+	"gcr.io/project-id/image:_underscore.leads",
+	"gcr.io/project__id/double--dash:latest",
+	"index.docker.io/library/ubuntu:22.04",
+	"domain.with.port:9001/image:latest",
+	"gcr.io/project-id/image@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	"gcr.io/project-id/image@sha512:" + strings.Repeat("ab", 64),
+}
+
+var badStrictOCINames = []string{
+	// Docker Hub defaulting.
+	"ubuntu:22.04",
+	"library/ubuntu:22.04",
+	// Repository grammar.
+	"gcr.io/project-id/trailing-:latest",
+	"gcr.io/project-id/-leading:latest",
+	"gcr.io/project-id/image/:latest",
+	"gcr.io/project___id/image:latest",
+	"gcr.io/project-id/" + strings.Repeat("a", 250) + ":latest",
+	// Tag grammar.
+	"gcr.io/project-id/image:.leading-period",
+	"gcr.io/project-id/image:-leading-dash",
+	"gcr.io/project-id/image",
+	// Digest algorithms.
+	"gcr.io/project-id/image@sha384:" + strings.Repeat("ab", 48),
+	"gcr.io/project-id/image@sha512:" + strings.Repeat("ab", 32),
+	"gcr.io/project-id/image@sha512:" + strings.Repeat("AB", 64),
+}
+
+func TestStrictOCI(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range goodStrictOCINames {
+		ref, err := ParseReference(name, StrictOCI)
+		if err != nil {
+			t.Errorf("ParseReference(%q, StrictOCI): %v", name, err)
+			continue
+		}
+		if ref.Name() != name {
+			t.Errorf("ParseReference(%q, StrictOCI).Name(): got %q", name, ref.Name())
+		}
+	}
+
+	for _, name := range badStrictOCINames {
+		if ref, err := ParseReference(name, StrictOCI); err == nil {
+			t.Errorf("ParseReference(%q, StrictOCI): expected error, got %v", name, ref)
+		}
+	}
+}
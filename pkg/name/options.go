@@ -27,9 +27,12 @@ const (
 
 type options struct {
 	strict          bool // weak by default
+	oci             bool // docker's grammar by default
 	insecure        bool // secure by default
+	insecureHosts   []string
 	defaultRegistry string
 	defaultTag      string
+	rewrites        []Rewrite
 }
 
 func makeOptions(opts ...Option) options {
@@ -64,6 +67,18 @@ func Insecure(opts *options) {
 	opts.insecure = true
 }
 
+// InsecureRegistries is an Option that allows image references in the given
+// registries to be fetched without TLS, as the Insecure Option does for every
+// registry. Registries are given as host[:port], which must match exactly, or
+// as CIDRs, which match any registry whose host is an IP address in them, e.g.
+//
+//	name.InsecureRegistries("localhost:5000", "10.0.0.0/8")
+func InsecureRegistries(registries ...string) Option {
+	return func(opts *options) {
+		opts.insecureHosts = append(opts.insecureHosts, registries...)
+	}
+}
+
 // OptionFn is a function that returns an option.
 type OptionFn func() Option
 
@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package name
+
+import (
+	"regexp"
+	"strings"
+)
+
+// os/arch[/variant][:osversion], as v1.ParsePlatform parses them.
+var platformRe = regexp.MustCompile(`^[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+(/[a-zA-Z0-9_.-]+)?(:[a-zA-Z0-9_.-]+)?$`)
+
+// PlatformReference is a Reference qualified with the platform of the image
+// that it refers to, in case it refers to an index, e.g. ubuntu:22.04@linux/arm64.
+//
+// The registry knows nothing of platforms, so everything but String is that
+// of the underlying Reference; pkg/v1/remote resolves the platform.
+type PlatformReference struct {
+	Reference
+	platform string
+}
+
+// Ensure PlatformReference implements Reference
+var _ Reference = (*PlatformReference)(nil)
+
+// ForPlatform returns ref qualified with platform, in the form
+// os/arch[/variant][:osversion].
+func ForPlatform(ref Reference, platform string) (PlatformReference, error) {
+	if !platformRe.MatchString(platform) {
+		return PlatformReference{}, newErrBadName("platform must be of the form os/arch[/variant][:osversion]: %s", platform)
+	}
+	return PlatformReference{Reference: ref, platform: platform}, nil
+}
+
+// Platform returns the platform component of the PlatformReference, in the
+// form os/arch[/variant][:osversion].
+func (r PlatformReference) Platform() string {
+	return r.platform
+}
+
+// String returns the reference and its platform, e.g. ubuntu:22.04@linux/arm64.
+func (r PlatformReference) String() string {
+	return r.Reference.String() + digestDelim + r.platform
+}
+
+// ParseWithPlatform parses the string as a reference, like ParseReference,
+// which may be qualified with a platform after an "@", e.g.
+// ubuntu:22.04@linux/arm64 or ubuntu@sha256:...@linux/arm64. Qualified
+// references are returned as a PlatformReference.
+//
+// Platforms are told apart from digests by their "/", which digests can't
+// contain.
+func ParseWithPlatform(s string, opts ...Option) (Reference, error) {
+	i := strings.LastIndex(s, digestDelim)
+	if i == -1 || !strings.Contains(s[i+1:], "/") {
+		return ParseReference(s, opts...)
+	}
+	ref, err := ParseReference(s[:i], opts...)
+	if err != nil {
+		return nil, err
+	}
+	pr, err := ForPlatform(ref, s[i+1:])
+	if err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
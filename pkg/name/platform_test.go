@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package name
+
+import (
+	"testing"
+)
+
+func TestParseWithPlatform(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in       string
+		name     string
+		platform string
+	}{
+		{"ubuntu:22.04", "index.docker.io/library/ubuntu:22.04", ""},
+		{"ubuntu:22.04@linux/arm64", "index.docker.io/library/ubuntu:22.04", "linux/arm64"},
+		{"ubuntu@linux/arm/v7", "index.docker.io/library/ubuntu:latest", "linux/arm/v7"},
+		{"gcr.io/project/app@" + testDigest, "gcr.io/project/app@" + testDigest, ""},
+		{"gcr.io/project/app@" + testDigest + "@windows/amd64:10.0.17763.1234", "gcr.io/project/app@" + testDigest, "windows/amd64:10.0.17763.1234"},
+		{"localhost:5000/app:v1@linux/amd64", "localhost:5000/app:v1", "linux/amd64"},
+	} {
+		ref, err := ParseWithPlatform(tc.in)
+		if err != nil {
+			t.Errorf("ParseWithPlatform(%q): %v", tc.in, err)
+			continue
+		}
+		if got := ref.Name(); got != tc.name {
+			t.Errorf("ParseWithPlatform(%q).Name(): got %q, want %q", tc.in, got, tc.name)
+		}
+		if got := ref.String(); got != tc.in {
+			t.Errorf("ParseWithPlatform(%q).String(): got %q", tc.in, got)
+		}
+		pr, ok := ref.(PlatformReference)
+		if ok != (tc.platform != "") {
+			t.Errorf("ParseWithPlatform(%q): got %T", tc.in, ref)
+			continue
+		}
+		if ok && pr.Platform() != tc.platform {
+			t.Errorf("ParseWithPlatform(%q).Platform(): got %q, want %q", tc.in, pr.Platform(), tc.platform)
+		}
+	}
+
+	for _, in := range []string{
+		"ubuntu@linux",
+		"ubuntu@linux/arm64/v8/extra",
+		"ubuntu@linux/arm 64",
+		"ubuntu:22.04@sha256:nope@linux/arm64",
+		"Ubuntu@linux/arm64",
+	} {
+		if ref, err := ParseWithPlatform(in); err == nil {
+			t.Errorf("ParseWithPlatform(%q): expected error, got %v", in, ref)
+		}
+	}
+}
@@ -130,7 +130,29 @@ func NewRegistry(name string, opts ...Option) (Registry, error) {
 		name = DefaultRegistry
 	}
 
-	return Registry{registry: name, insecure: opt.insecure}, nil
+	return Registry{registry: name, insecure: opt.insecure || isInsecureRegistry(name, opt.insecureHosts)}, nil
+}
+
+// isInsecureRegistry returns whether name is one of registries, or has an IP
+// address in one of the CIDRs among them, see InsecureRegistries.
+func isInsecureRegistry(name string, registries []string) bool {
+	host := name
+	if h, _, err := net.SplitHostPort(name); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	for _, r := range registries {
+		if r == defaultRegistryAlias {
+			r = DefaultRegistry
+		}
+		if r == name {
+			return true
+		}
+		if _, block, err := net.ParseCIDR(r); err == nil && ip != nil && block.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
 
 // NewInsecureRegistry returns an Insecure Registry based on the given name.
@@ -250,3 +250,26 @@ func TestRegistryInsecureScheme(t *testing.T) {
 		t.Errorf("scheme(%v); got %v, want http", reg, got)
 	}
 }
+
+func TestInsecureRegistries(t *testing.T) {
+	t.Parallel()
+	opt := InsecureRegistries("registry.example.com:5000", "docker.io", "100.64.0.0/10", "2001:db8::/32")
+
+	for domain, want := range map[string]string{
+		"registry.example.com:5000": "http",
+		"registry.example.com":      "https",
+		"":                          "http",
+		"100.64.1.2:5000":           "http",
+		"100.128.0.1":               "https",
+		"[2001:db8::1]:5000":        "http",
+		"gcr.io":                    "https",
+	} {
+		reg, err := NewRegistry(domain, opt)
+		if err != nil {
+			t.Fatalf("NewRegistry(%s) = %v", domain, err)
+		}
+		if got := reg.Scheme(); got != want {
+			t.Errorf("scheme(%v); got %v, want %v", reg, got, want)
+		}
+	}
+}
@@ -94,9 +94,17 @@ func NewRepository(name string, opts ...Option) (Repository, error) {
 	if err != nil {
 		return Repository{}, err
 	}
+	if opt.oci {
+		if err := checkOCIRepository(registry, repo); err != nil {
+			return Repository{}, err
+		}
+	}
 	if hasImplicitNamespace(repo, reg) && opt.strict {
 		return Repository{}, newErrBadName("strict validation requires the full repository path (missing 'library')")
 	}
+	if len(opt.rewrites) != 0 {
+		return rewrite(Repository{reg, repo}, opt)
+	}
 	return Repository{reg, repo}, nil
 }
 
@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package name
+
+import (
+	"strings"
+)
+
+// Rewrite maps the repositories under one prefix to another, e.g. to pull
+// from a mirror of Docker Hub:
+//
+//	Rewrite{From: "docker.io", To: "mirror.gcr.io"}
+//
+// or to rename a repository that has moved:
+//
+//	Rewrite{From: "gcr.io/old-project/app", To: "us-docker.pkg.dev/new-project/images/app"}
+type Rewrite struct {
+	// From is a registry, optionally followed by a repository prefix, which
+	// only matches whole path components. Docker Hub may be called
+	// "docker.io", but its official images are under "docker.io/library".
+	From string
+
+	// To replaces From in matching repositories.
+	To string
+
+	// Insecure allows the repositories that are rewritten to be fetched
+	// without TLS, see the Insecure Option.
+	Insecure bool
+}
+
+// WithRewrites is an Option that maps parsed references through the given
+// rules. The rule with the longest matching From wins, and the first of those
+// if several are equally long, so mirrors may be given in order of preference.
+//
+// References are only rewritten once, and the result must be valid under the
+// other Options, e.g. StrictValidation.
+func WithRewrites(rules ...Rewrite) Option {
+	return func(opts *options) {
+		opts.rewrites = append(opts.rewrites, rules...)
+	}
+}
+
+// rewrite applies the first of the longest rules in opt that matches repo.
+func rewrite(repo Repository, opt options) (Repository, error) {
+	name := repo.Name()
+	var (
+		match *Rewrite
+		from  string
+	)
+	for i, rule := range opt.rewrites {
+		prefix := canonicalPrefix(rule.From)
+		if name != prefix && !strings.HasPrefix(name, prefix+regRepoDelimiter) {
+			continue
+		}
+		if match == nil || len(prefix) > len(from) {
+			match, from = &opt.rewrites[i], prefix
+		}
+	}
+	if match == nil {
+		return repo, nil
+	}
+
+	opt.rewrites = nil
+	if match.Insecure {
+		opt.insecure = true
+	}
+	return NewRepository(match.To+strings.TrimPrefix(name, from), func(opts *options) {
+		*opts = opt
+	})
+}
+
+// canonicalPrefix spells Docker Hub the way NewRegistry does.
+func canonicalPrefix(prefix string) string {
+	if reg, _, _ := strings.Cut(prefix, regRepoDelimiter); reg == defaultRegistryAlias {
+		return DefaultRegistry + strings.TrimPrefix(prefix, reg)
+	}
+	return prefix
+}
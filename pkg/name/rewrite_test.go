@@ -0,0 +1,65 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package name
+
+import (
+	"testing"
+)
+
+func TestWithRewrites(t *testing.T) {
+	t.Parallel()
+
+	rules := WithRewrites(
+		Rewrite{From: "docker.io", To: "mirror.gcr.io"},
+		Rewrite{From: "docker.io", To: "unused.example.com"},
+		Rewrite{From: "docker.io/library/golang", To: "harbor.example.com/golang", Insecure: true},
+		Rewrite{From: "gcr.io/old", To: "gcr.io/new"},
+		Rewrite{From: "gcr.io/new", To: "gcr.io/newer"},
+	)
+
+	for _, tc := range []struct {
+		in, want, scheme string
+	}{
+		{"ubuntu", "mirror.gcr.io/library/ubuntu:latest", "https"},
+		{"docker.io/foo/bar:v1", "mirror.gcr.io/foo/bar:v1", "https"},
+		{"golang:1.22", "harbor.example.com/golang:1.22", "http"},
+		{"golang-ish:1.22", "mirror.gcr.io/library/golang-ish:1.22", "https"},
+		{"gcr.io/old/app:v1", "gcr.io/new/app:v1", "https"},
+		{"gcr.io/older/app:v1", "gcr.io/older/app:v1", "https"},
+		{"gcr.io/old@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", "gcr.io/new@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", "https"},
+		{"gcr.io/old:v1@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", "gcr.io/new@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", "https"},
+	} {
+		ref, err := ParseReference(tc.in, rules)
+		if err != nil {
+			t.Errorf("ParseReference(%q): %v", tc.in, err)
+			continue
+		}
+		if got := ref.Name(); got != tc.want {
+			t.Errorf("ParseReference(%q).Name(): got %q, want %q", tc.in, got, tc.want)
+		}
+		if got := ref.Context().Scheme(); got != tc.scheme {
+			t.Errorf("ParseReference(%q).Context().Scheme(): got %q, want %q", tc.in, got, tc.scheme)
+		}
+	}
+}
+
+func TestWithRewritesInvalid(t *testing.T) {
+	t.Parallel()
+
+	rules := WithRewrites(Rewrite{From: "gcr.io/project", To: "gcr.io/Invalid"})
+	if ref, err := ParseReference("gcr.io/project/app:v1", rules); err == nil {
+		t.Errorf("ParseReference(): expected error, got %v", ref)
+	}
+}
@@ -90,6 +90,11 @@ func NewTag(name string, opts ...Option) (Tag, error) {
 		if err := checkTag(tag); err != nil {
 			return Tag{}, err
 		}
+		if opt.oci {
+			if err := checkOCITag(tag); err != nil {
+				return Tag{}, err
+			}
+		}
 	}
 
 	if tag == "" {
@@ -0,0 +1,92 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package name
+
+import (
+	"fmt"
+	"path"
+)
+
+// ChangeRegistry returns a reference to the same repository and tag or digest
+// as ref, but in registry, e.g. to copy gcr.io/project/app:v1 to
+// registry.example.com/project/app:v1.
+//
+// Images from Docker Hub keep their implicit namespace, so ubuntu becomes
+// registry.example.com/library/ubuntu:latest.
+func ChangeRegistry(ref Reference, registry string, opts ...Option) (Reference, error) {
+	reg, err := NewRegistry(registry, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return transform(ref, reg, ref.Context().RepositoryStr(), nil, opts...)
+}
+
+// PrefixRepository returns a reference to the same tag or digest as ref, in
+// the same registry, but with prefix prepended to its repository, e.g. to copy
+// gcr.io/project/app:v1 to gcr.io/mirror/project/app:v1.
+func PrefixRepository(ref Reference, prefix string, opts ...Option) (Reference, error) {
+	repo := ref.Context()
+	return transform(ref, repo.Registry, path.Join(prefix, repo.RepositoryStr()), nil, opts...)
+}
+
+// MapTag returns a reference to the tag that f maps the tag of ref to, in the
+// same repository, e.g. to copy gcr.io/project/app:v1 to
+// gcr.io/project/app:v1-amd64.
+//
+// References by digest are returned unchanged.
+func MapTag(ref Reference, f func(tag string) string, opts ...Option) (Reference, error) {
+	switch ref.(type) {
+	case Digest, *Digest:
+		return ref, nil
+	}
+	repo := ref.Context()
+	return transform(ref, repo.Registry, repo.RepositoryStr(), f, opts...)
+}
+
+// transform returns a reference like ref, to repo in reg, and with its tag
+// mapped through f if it has one. The result is validated as NewTag and
+// NewDigest validate the references they parse.
+func transform(ref Reference, reg Registry, repo string, f func(string) string, opts ...Option) (Reference, error) {
+	opt := makeOptions(opts...)
+	if err := checkRepository(repo); err != nil {
+		return nil, err
+	}
+	if opt.oci {
+		if err := checkOCIRepository(reg.RegistryStr(), repo); err != nil {
+			return nil, err
+		}
+	}
+
+	r := Repository{Registry: reg, repository: repo}
+	switch ref.(type) {
+	case Tag, *Tag:
+		tag := ref.Identifier()
+		if f != nil {
+			tag = f(tag)
+		}
+		if err := checkTag(tag); err != nil {
+			return nil, err
+		}
+		if opt.oci {
+			if err := checkOCITag(tag); err != nil {
+				return nil, err
+			}
+		}
+		return r.Tag(tag), nil
+	case Digest, *Digest:
+		return r.Digest(ref.Identifier()), nil
+	}
+	return nil, fmt.Errorf("unsupported reference type %T", ref)
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package name
+
+import (
+	"testing"
+)
+
+const testDigest = "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func TestTransform(t *testing.T) {
+	t.Parallel()
+
+	suffix := func(tag string) string {
+		return tag + "-amd64"
+	}
+	for _, tc := range []struct {
+		name    string
+		in      string
+		fn      func(Reference) (Reference, error)
+		want    string
+		wantErr bool
+	}{{
+		name: "change registry",
+		in:   "gcr.io/project/app:v1",
+		fn: func(ref Reference) (Reference, error) {
+			return ChangeRegistry(ref, "registry.example.com")
+		},
+		want: "registry.example.com/project/app:v1",
+	}, {
+		name: "change registry of docker hub image",
+		in:   "ubuntu",
+		fn: func(ref Reference) (Reference, error) {
+			return ChangeRegistry(ref, "localhost:5000")
+		},
+		want: "localhost:5000/library/ubuntu:latest",
+	}, {
+		name: "change registry of digest",
+		in:   "gcr.io/project/app@" + testDigest,
+		fn: func(ref Reference) (Reference, error) {
+			return ChangeRegistry(ref, "docker.io")
+		},
+		want: "index.docker.io/project/app@" + testDigest,
+	}, {
+		name: "change to invalid registry",
+		in:   "gcr.io/project/app:v1",
+		fn: func(ref Reference) (Reference, error) {
+			return ChangeRegistry(ref, "bad registry")
+		},
+		wantErr: true,
+	}, {
+		name: "prefix repository",
+		in:   "gcr.io/project/app:v1",
+		fn: func(ref Reference) (Reference, error) {
+			return PrefixRepository(ref, "mirror/")
+		},
+		want: "gcr.io/mirror/project/app:v1",
+	}, {
+		name: "prefix repository with invalid characters",
+		in:   "gcr.io/project/app:v1",
+		fn: func(ref Reference) (Reference, error) {
+			return PrefixRepository(ref, "Mirror")
+		},
+		wantErr: true,
+	}, {
+		name: "map tag",
+		in:   "gcr.io/project/app:v1",
+		fn: func(ref Reference) (Reference, error) {
+			return MapTag(ref, suffix)
+		},
+		want: "gcr.io/project/app:v1-amd64",
+	}, {
+		name: "map tag of digest",
+		in:   "gcr.io/project/app@" + testDigest,
+		fn: func(ref Reference) (Reference, error) {
+			return MapTag(ref, suffix)
+		},
+		want: "gcr.io/project/app@" + testDigest,
+	}, {
+		name: "map tag to invalid tag",
+		in:   "gcr.io/project/app:v1",
+		fn: func(ref Reference) (Reference, error) {
+			return MapTag(ref, func(string) string { return "no spaces" })
+		},
+		wantErr: true,
+	}, {
+		name: "chained",
+		in:   "gcr.io/project/app:v1",
+		fn: func(ref Reference) (Reference, error) {
+			ref, err := ChangeRegistry(ref, "registry.example.com")
+			if err != nil {
+				return nil, err
+			}
+			ref, err = PrefixRepository(ref, "mirror")
+			if err != nil {
+				return nil, err
+			}
+			return MapTag(ref, suffix)
+		},
+		want: "registry.example.com/mirror/project/app:v1-amd64",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, err := ParseReference(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tc.fn(ref)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got err %v, wantErr %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got.Name() != tc.want {
+				t.Errorf("got %q, want %q", got.Name(), tc.want)
+			}
+			if got.String() != tc.want {
+				t.Errorf("String(): got %q, want %q", got.String(), tc.want)
+			}
+			// The result should round trip.
+			if parsed, err := ParseReference(got.Name()); err != nil {
+				t.Errorf("ParseReference(%q): %v", got.Name(), err)
+			} else if parsed.Name() != got.Name() {
+				t.Errorf("ParseReference(%q): got %q", got.Name(), parsed.Name())
+			}
+		})
+	}
+}
@@ -82,6 +82,14 @@ type BlobDeleteHandler interface {
 	Delete(ctx context.Context, repo string, h v1.Hash) error
 }
 
+// BlobListHandler is an extension interface representing a blob storage
+// backend that can enumerate its contents, which is needed for garbage
+// collection.
+type BlobListHandler interface {
+	// List returns the digests of all stored blobs.
+	List(ctx context.Context) ([]v1.Hash, error)
+}
+
 // redirectError represents a signal that the blob handler doesn't have the blob
 // contents, but that those contents are at another location which registry
 // clients should redirect to.
@@ -160,6 +168,21 @@ func (m *memHandler) Delete(_ context.Context, _ string, h v1.Hash) error {
 	return nil
 }
 
+func (m *memHandler) List(_ context.Context) ([]v1.Hash, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	hs := make([]v1.Hash, 0, len(m.m))
+	for k := range m.m {
+		h, err := v1.NewHash(k)
+		if err != nil {
+			return nil, err
+		}
+		hs = append(hs, h)
+	}
+	return hs, nil
+}
+
 // blobs
 type blobs struct {
 	blobHandler BlobHandler
@@ -241,6 +264,10 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 		return nil
 
 	case http.MethodGet:
+		if service == "uploads" {
+			return b.uploadStatus(resp, elem, target)
+		}
+
 		h, err := v1.NewHash(target)
 		if err != nil {
 			return &regError{
@@ -379,6 +406,9 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 					log.Printf("Digest mismatch: %v", err)
 					return regErrDigestMismatch
 				}
+				if errors.Is(err, errQuotaExceeded) {
+					return regErrQuotaExceeded
+				}
 				return regErrInternal(err)
 			}
 			resp.Header().Set("Docker-Content-Digest", h.String())
@@ -387,7 +417,11 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 		}
 
 		id := fmt.Sprint(rand.Int63())
+		b.lock.Lock()
+		b.uploads[id] = []byte{}
+		b.lock.Unlock()
 		resp.Header().Set("Location", "/"+path.Join("v2", path.Join(elem[1:len(elem)-2]...), "blobs/uploads", id))
+		resp.Header().Set("Docker-Upload-UUID", id)
 		resp.Header().Set("Range", "0-0")
 		resp.WriteHeader(http.StatusAccepted)
 		return nil
@@ -401,8 +435,15 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 			}
 		}
 
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		committed, ok := b.uploads[target]
+		if !ok {
+			return regErrBlobUploadUnknown
+		}
+
+		start, end := 0, 0
 		if contentRange != "" {
-			start, end := 0, 0
 			if _, err := fmt.Sscanf(contentRange, "%d-%d", &start, &end); err != nil {
 				return &regError{
 					Status:  http.StatusRequestedRangeNotSatisfiable,
@@ -410,27 +451,14 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 					Message: "We don't understand your Content-Range",
 				}
 			}
-			b.lock.Lock()
-			defer b.lock.Unlock()
-			if start != len(b.uploads[target]) {
+			if start != len(committed) {
 				return &regError{
 					Status:  http.StatusRequestedRangeNotSatisfiable,
 					Code:    "BLOB_UPLOAD_UNKNOWN",
 					Message: "Your content range doesn't match what we have",
 				}
 			}
-			l := bytes.NewBuffer(b.uploads[target])
-			io.Copy(l, req.Body)
-			b.uploads[target] = l.Bytes()
-			resp.Header().Set("Location", "/"+path.Join("v2", path.Join(elem[1:len(elem)-3]...), "blobs/uploads", target))
-			resp.Header().Set("Range", fmt.Sprintf("0-%d", len(l.Bytes())-1))
-			resp.WriteHeader(http.StatusNoContent)
-			return nil
-		}
-
-		b.lock.Lock()
-		defer b.lock.Unlock()
-		if _, ok := b.uploads[target]; ok {
+		} else if len(committed) != 0 {
 			return &regError{
 				Status:  http.StatusBadRequest,
 				Code:    "BLOB_UPLOAD_INVALID",
@@ -438,12 +466,31 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 			}
 		}
 
+		// Only commit the chunk once we've read all of it, so that the
+		// upload stays at the offset we've reported, and the client can
+		// retry the chunk from there if it didn't get through.
 		l := &bytes.Buffer{}
-		io.Copy(l, req.Body)
+		if _, err := io.Copy(l, req.Body); err != nil {
+			return &regError{
+				Status:  http.StatusBadRequest,
+				Code:    "BLOB_UPLOAD_INVALID",
+				Message: fmt.Sprintf("reading chunk: %v", err),
+			}
+		}
+
+		// The range is inclusive, so a chunk of n bytes ends at start+n-1.
+		if contentRange != "" && end != start+l.Len()-1 {
+			return &regError{
+				Status:  http.StatusRequestedRangeNotSatisfiable,
+				Code:    "BLOB_UPLOAD_INVALID",
+				Message: fmt.Sprintf("Content-Range %s doesn't match the %d bytes sent", contentRange, l.Len()),
+			}
+		}
 
-		b.uploads[target] = l.Bytes()
+		b.uploads[target] = append(committed, l.Bytes()...)
 		resp.Header().Set("Location", "/"+path.Join("v2", path.Join(elem[1:len(elem)-3]...), "blobs/uploads", target))
-		resp.Header().Set("Range", fmt.Sprintf("0-%d", len(l.Bytes())-1))
+		resp.Header().Set("Docker-Upload-UUID", target)
+		resp.Header().Set("Range", uploadRange(len(b.uploads[target])))
 		resp.WriteHeader(http.StatusNoContent)
 		return nil
 
@@ -500,6 +547,9 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 				log.Printf("Digest mismatch: %v", err)
 				return regErrDigestMismatch
 			}
+			if errors.Is(err, errQuotaExceeded) {
+				return regErrQuotaExceeded
+			}
 			return regErrInternal(err)
 		}
 
@@ -509,6 +559,18 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 		return nil
 
 	case http.MethodDelete:
+		// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#canceling-an-upload
+		if service == "uploads" {
+			b.lock.Lock()
+			defer b.lock.Unlock()
+			if _, ok := b.uploads[target]; !ok {
+				return regErrBlobUploadUnknown
+			}
+			delete(b.uploads, target)
+			resp.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+
 		bdh, ok := b.blobHandler.(BlobDeleteHandler)
 		if !ok {
 			return regErrUnsupported
@@ -523,6 +585,9 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 			}
 		}
 		if err := bdh.Delete(req.Context(), repo, h); err != nil {
+			if errors.Is(err, errNotFound) {
+				return regErrBlobUnknown
+			}
 			return regErrInternal(err)
 		}
 		resp.WriteHeader(http.StatusAccepted)
@@ -536,3 +601,30 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 		}
 	}
 }
+
+// uploadStatus reports how much of the upload session id we have received.
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#getting-the-upload-status
+func (b *blobs) uploadStatus(resp http.ResponseWriter, elem []string, id string) *regError {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	l, ok := b.uploads[id]
+	if !ok {
+		return regErrBlobUploadUnknown
+	}
+	resp.Header().Set("Location", "/"+path.Join("v2", path.Join(elem[1:len(elem)-3]...), "blobs/uploads", id))
+	resp.Header().Set("Docker-Upload-UUID", id)
+	resp.Header().Set("Range", uploadRange(len(l)))
+	resp.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// uploadRange returns the value of the Range header that reports having
+// received n bytes of an upload. The range is inclusive, so an empty upload
+// is reported as 0-0, like the initial POST.
+func uploadRange(n int) string {
+	if n == 0 {
+		return "0-0"
+	}
+	return fmt.Sprintf("0-%d", n-1)
+}
@@ -69,3 +69,29 @@ func (m *diskHandler) Put(_ context.Context, _ string, h v1.Hash, rc io.ReadClos
 func (m *diskHandler) Delete(_ context.Context, _ string, h v1.Hash) error {
 	return os.Remove(m.blobHashPath(h))
 }
+func (m *diskHandler) List(_ context.Context) ([]v1.Hash, error) {
+	algs, err := os.ReadDir(m.dir)
+	if err != nil {
+		return nil, err
+	}
+	var hs []v1.Hash
+	for _, alg := range algs {
+		// Skip in-progress uploads.
+		if !alg.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(m.dir, alg.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			h, err := v1.NewHash(alg.Name() + ":" + e.Name())
+			if err != nil {
+				// Not a blob, e.g. a temp file from writeFileAtomic.
+				continue
+			}
+			hs = append(hs, h)
+		}
+	}
+	return hs, nil
+}
@@ -15,13 +15,16 @@
 package registry_test
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
 	"github.com/google/go-containerregistry/pkg/v1/random"
@@ -82,3 +85,125 @@ func TestDiskPush(t *testing.T) {
 		t.Logf("Found %s", dig)
 	}
 }
+
+func TestDiskStorage(t *testing.T) {
+	dir := t.TempDir()
+	srv := httptest.NewServer(registry.New(registry.WithDiskStorage(dir)))
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	tag, err := name.NewTag(host + "/foo/bar:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := random.Index(1024, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(tag, idx); err != nil {
+		t.Fatalf("remote.WriteIndex: %v", err)
+	}
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgTag := tag.Context().Tag("img")
+	if err := remote.Write(imgTag, img); err != nil {
+		t.Fatalf("remote.Write: %v", err)
+	}
+	if err := remote.Delete(imgTag); err != nil {
+		t.Fatalf("remote.Delete: %v", err)
+	}
+	srv.Close()
+
+	// Start a new registry on the same directory, which should serve the
+	// same contents.
+	srv = httptest.NewServer(registry.New(registry.WithDiskStorage(dir)))
+	defer srv.Close()
+	host = strings.TrimPrefix(srv.URL, "http://")
+
+	tag, err = name.NewTag(host + "/foo/bar:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := remote.Index(tag)
+	if err != nil {
+		t.Fatalf("remote.Index: %v", err)
+	}
+	if err := validate.Index(got); err != nil {
+		t.Fatalf("validate.Index: %v", err)
+	}
+	wantDigest, err := idx.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotDigest, err := got.Digest(); err != nil {
+		t.Fatal(err)
+	} else if gotDigest != wantDigest {
+		t.Errorf("digest = %s, want %s", gotDigest, wantDigest)
+	}
+
+	tags, err := remote.List(tag.Context())
+	if err != nil {
+		t.Fatalf("remote.List: %v", err)
+	}
+	if want := []string{"latest"}; !cmp.Equal(tags, want) {
+		t.Errorf("tags = %v, want %v", tags, want)
+	}
+
+	// The deleted tag's image is still available by digest.
+	imgDigest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := remote.Image(tag.Context().Digest(imgDigest.String())); err != nil {
+		t.Errorf("remote.Image(by digest): %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "repositories", "foo", "bar", "index.json")); err != nil {
+		t.Errorf("os.Stat(index.json): %v", err)
+	}
+}
+
+func TestDiskStoragePathTraversal(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "storage")
+	srv := httptest.NewServer(registry.New(registry.WithDiskStorage(dir)))
+	defer srv.Close()
+
+	for _, repo := range []string{
+		"../../../escaped",
+		"foo/../../../../escaped",
+		"foo/./bar",
+		"foo//bar",
+		"Foo",
+	} {
+		req, err := http.NewRequest(http.MethodPut, srv.URL+"/v2/"+repo+"/manifests/latest", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("PUT %q: got status %d, want %d", repo, resp.StatusCode, http.StatusBadRequest)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(parent, "escaped")); !os.IsNotExist(err) {
+		t.Errorf("os.Stat(escaped): got %v, want not exist", err)
+	}
+
+	// The store rejects names that would escape dir when used directly, too.
+	store := registry.NewDiskManifestStore(dir)
+	if err := store.Save(context.Background(), "../escaped", map[string]registry.Manifest{
+		"latest": {ContentType: "application/vnd.oci.image.manifest.v1+json", Blob: []byte("{}")},
+	}); err == nil {
+		t.Error("Save(../escaped): got nil error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escaped")); !os.IsNotExist(err) {
+		t.Errorf("os.Stat(escaped): got %v, want not exist", err)
+	}
+}
@@ -28,6 +28,8 @@ func TestDeps(t *testing.T) {
 		"github.com/google/go-containerregistry/pkg/registry": append(
 			depcheck.StdlibPackages(),
 			"github.com/google/go-containerregistry/internal/httptest",
+			"github.com/google/go-containerregistry/pkg/name",
+			"github.com/opencontainers/go-digest",
 			"github.com/google/go-containerregistry/pkg/v1",
 			"github.com/google/go-containerregistry/pkg/v1/types",
 
@@ -60,6 +60,12 @@ var regErrBlobUnknown = &regError{
 	Message: "Unknown blob",
 }
 
+var regErrBlobUploadUnknown = &regError{
+	Status:  http.StatusNotFound,
+	Code:    "BLOB_UPLOAD_UNKNOWN",
+	Message: "Unknown upload session",
+}
+
 var regErrUnsupported = &regError{
 	Status:  http.StatusMethodNotAllowed,
 	Code:    "UNSUPPORTED",
@@ -0,0 +1,272 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoint identifies a part of the registry API that a Fault applies to.
+type Endpoint string
+
+// The endpoints of the registry API.
+const (
+	EndpointAll       Endpoint = ""
+	EndpointBase      Endpoint = "base"
+	EndpointManifests Endpoint = "manifests"
+	EndpointBlobs     Endpoint = "blobs"
+	EndpointUploads   Endpoint = "uploads"
+	EndpointTags      Endpoint = "tags"
+	EndpointCatalog   Endpoint = "catalog"
+	EndpointReferrers Endpoint = "referrers"
+)
+
+// Fault describes a failure to inject into the registry's responses, to test
+// how clients handle misbehaving registries. See WithFault.
+type Fault struct {
+	// Endpoint restricts the fault to one endpoint. The zero value,
+	// EndpointAll, matches every request.
+	Endpoint Endpoint
+
+	// Method restricts the fault to one HTTP method, e.g. "GET". The empty
+	// string matches every method.
+	Method string
+
+	// Probability is the chance that a matching request is affected, from 0
+	// to 1. Use 1 to affect every matching request.
+	Probability float64
+
+	// Delay delays the response by the given duration.
+	Delay time.Duration
+
+	// Status, if set, fails the request with the given status code, e.g.
+	// http.StatusInternalServerError or http.StatusTooManyRequests, instead
+	// of handling it.
+	Status int
+
+	// RetryAfter, if set along with Status, is sent in the Retry-After
+	// header, rounded up to whole seconds.
+	RetryAfter time.Duration
+
+	// Truncate cuts off the response body halfway through, after having
+	// sent the full Content-Length. For requests with a body, e.g. blob
+	// uploads, it cuts off the request body halfway through instead, as if
+	// the client had been disconnected, whether or not the request has a
+	// Content-Length.
+	Truncate bool
+
+	// CorruptBody flips a bit of the response body, so that it no longer
+	// matches its digest.
+	CorruptBody bool
+}
+
+// WithFault injects f into the registry's responses. It can be passed
+// multiple times, in which case every matching Fault applies.
+//
+// Whether a Fault applies is decided randomly according to its Probability;
+// use WithFaultSeed to make that sequence of decisions reproducible.
+func WithFault(f Fault) Option {
+	return func(r *registry) {
+		if r.faults == nil {
+			r.faults = &faults{rnd: rand.New(rand.NewSource(time.Now().UnixNano()))} //nolint:gosec
+		}
+		r.faults.faults = append(r.faults.faults, f)
+	}
+}
+
+// WithFaultSeed seeds the random decisions of whether to apply a Fault, see
+// WithFault.
+func WithFaultSeed(seed int64) Option {
+	return func(r *registry) {
+		if r.faults == nil {
+			r.faults = &faults{}
+		}
+		r.faults.rnd = rand.New(rand.NewSource(seed)) //nolint:gosec
+	}
+}
+
+type faults struct {
+	faults []Fault
+
+	lock sync.Mutex
+	rnd  *rand.Rand
+}
+
+// roll returns whether an event with the given probability happens.
+func (f *faults) roll(p float64) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.rnd.Float64() < p
+}
+
+// inject applies the faults matching req. It returns the ResponseWriter to
+// handle req with, or an error to fail it with.
+func (f *faults) inject(resp http.ResponseWriter, req *http.Request) (http.ResponseWriter, *regError) {
+	ep := endpoint(req)
+	for _, fault := range f.faults {
+		if fault.Endpoint != EndpointAll && fault.Endpoint != ep {
+			continue
+		}
+		if fault.Method != "" && fault.Method != req.Method {
+			continue
+		}
+		if !f.roll(fault.Probability) {
+			continue
+		}
+
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-req.Context().Done():
+			}
+		}
+		if fault.Status != 0 {
+			if fault.RetryAfter > 0 {
+				resp.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(fault.RetryAfter.Seconds()))))
+			}
+			return resp, &regError{
+				Status:  fault.Status,
+				Code:    faultCode(fault.Status),
+				Message: fmt.Sprintf("injected fault: %s", http.StatusText(fault.Status)),
+			}
+		}
+		truncate := fault.Truncate
+		if truncate && req.Body != nil && req.Body != http.NoBody {
+			req.Body = &truncatedBody{rc: req.Body}
+			truncate = false
+		}
+		if truncate || fault.CorruptBody {
+			resp = &faultyWriter{
+				ResponseWriter: resp,
+				truncate:       truncate,
+				corrupt:        fault.CorruptBody,
+			}
+		}
+	}
+	return resp, nil
+}
+
+// faultCode returns the error code that a registry would use for status.
+func faultCode(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return "UNAUTHORIZED"
+	case http.StatusForbidden:
+		return "DENIED"
+	case http.StatusTooManyRequests:
+		return "TOOMANYREQUESTS"
+	case http.StatusServiceUnavailable:
+		return "UNAVAILABLE"
+	}
+	return "UNKNOWN"
+}
+
+// endpoint returns the Endpoint that handles req.
+func endpoint(req *http.Request) Endpoint {
+	switch {
+	case isBlob(req):
+		if strings.Contains(req.URL.Path, "/blobs/uploads") {
+			return EndpointUploads
+		}
+		return EndpointBlobs
+	case isManifest(req):
+		return EndpointManifests
+	case isTags(req):
+		return EndpointTags
+	case isCatalog(req):
+		return EndpointCatalog
+	case isReferrers(req):
+		return EndpointReferrers
+	}
+	return EndpointBase
+}
+
+// faultyWriter mangles the response body written through it.
+type faultyWriter struct {
+	http.ResponseWriter
+	truncate, corrupt bool
+
+	// remaining is how many more bytes we'll write if truncating.
+	remaining int64
+	started   bool
+}
+
+func (w *faultyWriter) WriteHeader(code int) {
+	if !w.started {
+		w.started = true
+		w.remaining = -1
+		if n, err := strconv.ParseInt(w.Header().Get("Content-Length"), 10, 64); err == nil {
+			w.remaining = n / 2
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *faultyWriter) Write(b []byte) (int, error) {
+	if !w.started {
+		w.WriteHeader(http.StatusOK)
+	}
+	n := len(b)
+	if w.corrupt && len(b) > 0 {
+		b = append([]byte{b[0] ^ 1}, b[1:]...)
+		w.corrupt = false
+	}
+	if w.truncate && w.remaining >= 0 {
+		if int64(len(b)) > w.remaining {
+			b = b[:w.remaining]
+		}
+		w.remaining -= int64(len(b))
+	}
+	if _, err := w.ResponseWriter.Write(b); err != nil {
+		return 0, err
+	}
+	// Pretend we wrote everything, so that handlers carry on as normal.
+	return n, nil
+}
+
+// truncatedBody returns the first half of a request body, and then fails as
+// if the client had been disconnected.
+type truncatedBody struct {
+	rc   io.ReadCloser
+	half *bytes.Reader
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.half == nil {
+		// Without a Content-Length, we have to read the whole body to know
+		// where halfway is.
+		all, err := io.ReadAll(b.rc)
+		if err != nil {
+			return 0, err
+		}
+		b.half = bytes.NewReader(all[:len(all)/2])
+	}
+	n, err := b.half.Read(p)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (b *truncatedBody) Close() error { return b.rc.Close() }
@@ -0,0 +1,219 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+)
+
+// sha256("foo")
+const fooDigest = "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+// faultServer starts a registry with the given faults, which only apply to
+// GET requests so that we can push a blob containing "foo".
+func faultServer(t *testing.T, opts ...registry.Option) *httptest.Server {
+	t.Helper()
+	s := httptest.NewServer(registry.New(opts...))
+	t.Cleanup(s.Close)
+	resp, err := http.Post(s.URL+"/v2/foo/blobs/uploads/?digest="+fooDigest, "", strings.NewReader("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("pushing blob: %s", resp.Status)
+	}
+	return s
+}
+
+func TestFaultStatus(t *testing.T) {
+	s := faultServer(t, registry.WithFault(registry.Fault{
+		Endpoint:    registry.EndpointBlobs,
+		Method:      http.MethodGet,
+		Probability: 1,
+		Status:      http.StatusTooManyRequests,
+		RetryAfter:  1500 * time.Millisecond,
+	}))
+
+	resp, err := http.Get(s.URL + "/v2/foo/blobs/" + fooDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("got %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+	if got, want := resp.Header.Get("Retry-After"), "2"; got != want {
+		t.Errorf("Retry-After: got %q, want %q", got, want)
+	}
+
+	// Other endpoints are unaffected.
+	resp, err = http.Get(s.URL + "/v2/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("/v2/: got %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestFaultBody(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		fault registry.Fault
+		check func(t *testing.T, body []byte, err error)
+	}{{
+		name:  "truncate",
+		fault: registry.Fault{Truncate: true},
+		check: func(t *testing.T, body []byte, err error) {
+			if err == nil {
+				t.Errorf("got %q, want error", body)
+			}
+		},
+	}, {
+		name:  "corrupt",
+		fault: registry.Fault{CorruptBody: true},
+		check: func(t *testing.T, body []byte, err error) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(body) != 3 || string(body) == "foo" {
+				t.Errorf("got %q, want corrupted foo", body)
+			}
+		},
+	}, {
+		name:  "delay",
+		fault: registry.Fault{Delay: 50 * time.Millisecond},
+		check: func(t *testing.T, body []byte, err error) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != "foo" {
+				t.Errorf("got %q, want foo", body)
+			}
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fault.Method = http.MethodGet
+			tc.fault.Probability = 1
+			s := faultServer(t, registry.WithFault(tc.fault))
+
+			start := time.Now()
+			resp, err := http.Get(s.URL + "/v2/foo/blobs/" + fooDigest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if elapsed := time.Since(start); elapsed < tc.fault.Delay {
+				t.Errorf("took %v, want at least %v", elapsed, tc.fault.Delay)
+			}
+			tc.check(t, body, err)
+		})
+	}
+}
+
+func TestFaultSeed(t *testing.T) {
+	statuses := func() []int {
+		s := faultServer(t, registry.WithFaultSeed(42), registry.WithFault(registry.Fault{
+			Method:      http.MethodGet,
+			Probability: 0.5,
+			Status:      http.StatusInternalServerError,
+		}))
+		var got []int
+		for i := 0; i < 20; i++ {
+			resp, err := http.Get(s.URL + "/v2/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			got = append(got, resp.StatusCode)
+		}
+		return got
+	}
+
+	first, second := statuses(), statuses()
+	var failed int
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("same seed gave different results: %v vs %v", first, second)
+		}
+		if first[i] == http.StatusInternalServerError {
+			failed++
+		}
+	}
+	if failed == 0 || failed == len(first) {
+		t.Errorf("want some but not all requests to fail, got %v", first)
+	}
+}
+
+func TestFaultTruncateUpload(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.WithFault(registry.Fault{
+		Endpoint:    registry.EndpointUploads,
+		Method:      http.MethodPatch,
+		Probability: 1,
+		Truncate:    true,
+	})))
+	defer s.Close()
+
+	for _, tc := range []struct {
+		name string
+		body io.Reader
+	}{
+		{"content-length", strings.NewReader("foobar")},
+		// Hide the length, so that the body is sent chunked.
+		{"chunked", io.MultiReader(strings.NewReader("foobar"))},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(s.URL+"/v2/foo/blobs/uploads/", "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			loc := s.URL + resp.Header.Get("Location")
+
+			req, err := http.NewRequest(http.MethodPatch, loc, tc.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err = http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("PATCH: got %d, want %d", resp.StatusCode, http.StatusBadRequest)
+			}
+
+			// Nothing from the interrupted chunk was kept.
+			resp, err = http.Get(loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if got, want := resp.Header.Get("Range"), "0-0"; got != want {
+				t.Errorf("Range: got %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// GCPath is the path of the garbage collection endpoint enabled by
+// WithGarbageCollection.
+const GCPath = "/admin/gc"
+
+// GCPolicy configures what garbage collection deletes.
+type GCPolicy struct {
+	// DeleteUntagged deletes manifests that aren't reachable from any tag.
+	// Manifests are reachable from a tag if they are tagged, are children of
+	// a reachable index, or refer to a reachable manifest as their subject.
+	DeleteUntagged bool
+}
+
+// GCResult reports what garbage collection deleted.
+type GCResult struct {
+	// Manifests are the deleted manifests, as repo@digest.
+	Manifests []string `json:"manifests"`
+	// Blobs are the deleted blobs.
+	Blobs []v1.Hash `json:"blobs"`
+}
+
+// WithGarbageCollection enables the GCPath endpoint, which deletes content
+// according to p when it receives a POST, responding with a GCResult.
+//
+// Blobs that aren't referenced by any manifest are always deleted. This
+// includes blobs that have been uploaded for a manifest that hasn't been
+// pushed yet, so garbage collection shouldn't run concurrently with pushes.
+//
+// Blobs can only be deleted if the BlobHandler implements BlobListHandler and
+// BlobDeleteHandler, which the in-memory and disk handlers do.
+func WithGarbageCollection(p GCPolicy) Option {
+	return func(r *registry) {
+		r.gc = &p
+	}
+}
+
+func (r *registry) handleGC(resp http.ResponseWriter, req *http.Request) *regError {
+	if req.Method != http.MethodPost {
+		return &regError{
+			Status:  http.StatusMethodNotAllowed,
+			Code:    "UNSUPPORTED",
+			Message: "garbage collection must be triggered with POST",
+		}
+	}
+	res, err := r.collect(req.Context(), *r.gc)
+	if err != nil {
+		return regErrInternal(err)
+	}
+	msg, _ := json.Marshal(res)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", fmt.Sprint(len(msg)))
+	resp.WriteHeader(http.StatusOK)
+	resp.Write(msg)
+	return nil
+}
+
+// collect deletes unreachable manifests (depending on p) and then any blobs
+// that aren't referenced by the remaining manifests.
+func (r *registry) collect(ctx context.Context, p GCPolicy) (*GCResult, error) {
+	res := &GCResult{
+		Manifests: []string{},
+		Blobs:     []v1.Hash{},
+	}
+
+	m := &r.manifests
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if p.DeleteUntagged {
+		for repo, c := range m.manifests {
+			reachable := reachableManifests(c)
+			var deleted bool
+			for key := range c {
+				if _, err := v1.NewHash(key); err != nil {
+					// Tags are always reachable.
+					continue
+				}
+				if !reachable[key] {
+					delete(c, key)
+					res.Manifests = append(res.Manifests, repo+"@"+key)
+					deleted = true
+				}
+			}
+			if len(c) == 0 {
+				delete(m.manifests, repo)
+			}
+			if deleted {
+				if err := m.save(repo); err != nil {
+					return nil, err
+				}
+			}
+		}
+		sort.Strings(res.Manifests)
+	}
+
+	blh, ok := r.blobs.blobHandler.(BlobListHandler)
+	if !ok {
+		return res, nil
+	}
+	bdh, ok := r.blobs.blobHandler.(BlobDeleteHandler)
+	if !ok {
+		return res, nil
+	}
+
+	referenced := map[v1.Hash]bool{}
+	for _, c := range m.manifests {
+		for _, mf := range c {
+			// Manifests share storage with blobs on disk.
+			h, _, err := v1.SHA256(bytes.NewReader(mf.blob))
+			if err != nil {
+				return nil, err
+			}
+			referenced[h] = true
+			for _, d := range blobReferences(mf.blob) {
+				referenced[d] = true
+			}
+		}
+	}
+
+	hs, err := blh.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(hs, func(i, j int) bool { return hs[i].String() < hs[j].String() })
+	for _, h := range hs {
+		if referenced[h] {
+			continue
+		}
+		// Blob storage isn't scoped to a repository.
+		if err := bdh.Delete(ctx, "", h); err != nil {
+			return nil, err
+		}
+		res.Blobs = append(res.Blobs, h)
+	}
+	return res, nil
+}
+
+// gcManifest holds the fields of an image manifest or index that reference
+// other content.
+type gcManifest struct {
+	Config    *v1.Descriptor  `json:"config,omitempty"`
+	Layers    []v1.Descriptor `json:"layers,omitempty"`
+	Manifests []v1.Descriptor `json:"manifests,omitempty"`
+	Subject   *v1.Descriptor  `json:"subject,omitempty"`
+}
+
+// blobReferences returns the digests of the blobs that the manifest in b
+// references.
+func blobReferences(b []byte) []v1.Hash {
+	var gm gcManifest
+	if err := json.Unmarshal(b, &gm); err != nil {
+		return nil
+	}
+	var hs []v1.Hash
+	if gm.Config != nil {
+		hs = append(hs, gm.Config.Digest)
+	}
+	for _, l := range gm.Layers {
+		hs = append(hs, l.Digest)
+	}
+	return hs
+}
+
+// reachableManifests returns the digests of the manifests in c that are
+// reachable from a tag, see GCPolicy.DeleteUntagged.
+func reachableManifests(c map[string]manifest) map[string]bool {
+	// Index the manifests we have by digest, along with what they point to.
+	parsed := map[string]gcManifest{}
+	for key, mf := range c {
+		if _, err := v1.NewHash(key); err != nil {
+			continue
+		}
+		var gm gcManifest
+		json.Unmarshal(mf.blob, &gm) //nolint:errcheck
+		parsed[key] = gm
+	}
+
+	reachable := map[string]bool{}
+	var queue []string
+	mark := func(d string) {
+		if !reachable[d] {
+			reachable[d] = true
+			queue = append(queue, d)
+		}
+	}
+	for key, mf := range c {
+		if _, err := v1.NewHash(key); err == nil {
+			continue
+		}
+		h, _, err := v1.SHA256(bytes.NewReader(mf.blob))
+		if err != nil {
+			continue
+		}
+		mark(h.String())
+	}
+
+	// Mark children and referrers until we stop finding new manifests.
+	for len(queue) > 0 {
+		for len(queue) > 0 {
+			d := queue[0]
+			queue = queue[1:]
+			for _, child := range parsed[d].Manifests {
+				mark(child.Digest.String())
+			}
+		}
+		for d, gm := range parsed {
+			if gm.Subject != nil && reachable[gm.Subject.Digest.String()] {
+				mark(d)
+			}
+		}
+	}
+	return reachable
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+func TestGarbageCollection(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []registry.Option
+	}{{
+		name: "memory",
+	}, {
+		name: "disk",
+		opts: []registry.Option{registry.WithDiskStorage(t.TempDir())},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append(tc.opts, registry.WithGarbageCollection(registry.GCPolicy{DeleteUntagged: true}))
+			s := httptest.NewServer(registry.New(opts...))
+			defer s.Close()
+
+			repo, err := name.NewRepository(strings.TrimPrefix(s.URL, "http://") + "/foo")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			keep, err := random.Image(1024, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := remote.Write(repo.Tag("keep"), keep); err != nil {
+				t.Fatal(err)
+			}
+			idx, err := random.Index(1024, 1, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := remote.WriteIndex(repo.Tag("idx"), idx); err != nil {
+				t.Fatal(err)
+			}
+			drop, err := random.Image(1024, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := remote.Write(repo.Tag("drop"), drop); err != nil {
+				t.Fatal(err)
+			}
+			if err := remote.Delete(repo.Tag("drop")); err != nil {
+				t.Fatal(err)
+			}
+			orphan, err := random.Layer(1024, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := remote.WriteLayer(repo, orphan); err != nil {
+				t.Fatal(err)
+			}
+
+			if resp, err := http.Get(s.URL + registry.GCPath); err != nil {
+				t.Fatal(err)
+			} else if resp.StatusCode != http.StatusMethodNotAllowed {
+				t.Errorf("GET %s: got %d, want %d", registry.GCPath, resp.StatusCode, http.StatusMethodNotAllowed)
+			}
+
+			resp, err := http.Post(s.URL+registry.GCPath, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("POST %s: %s", registry.GCPath, resp.Status)
+			}
+			var res registry.GCResult
+			if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+
+			dropDigest, err := drop.Digest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"foo@" + dropDigest.String()}; len(res.Manifests) != 1 || res.Manifests[0] != want[0] {
+				t.Errorf("deleted manifests: got %v, want %v", res.Manifests, want)
+			}
+			// Two layers and a config for drop, and the orphan.
+			if len(res.Blobs) < 4 {
+				t.Errorf("deleted blobs: got %v, want at least 4", res.Blobs)
+			}
+
+			if _, err := remote.Image(repo.Digest(dropDigest.String())); err == nil {
+				t.Error("dropped image is still available")
+			}
+			od, err := orphan.Digest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp, err := http.Head(s.URL + "/v2/foo/blobs/" + od.String()); err != nil {
+				t.Fatal(err)
+			} else if resp.StatusCode != http.StatusNotFound {
+				t.Errorf("orphan blob: got %d, want %d", resp.StatusCode, http.StatusNotFound)
+			}
+
+			img, err := remote.Image(repo.Tag("keep"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := validate.Image(img); err != nil {
+				t.Errorf("validate.Image: %v", err)
+			}
+			ridx, err := remote.Index(repo.Tag("idx"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := validate.Index(ridx); err != nil {
+				t.Errorf("validate.Index: %v", err)
+			}
+		})
+	}
+}
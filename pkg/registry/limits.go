@@ -0,0 +1,276 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// WithReadOnly rejects every request that would modify the registry, e.g.
+// to serve a fixed set of images that was loaded with WithDiskStorage.
+func WithReadOnly() Option {
+	return func(r *registry) {
+		r.readOnly = true
+	}
+}
+
+var regErrReadOnly = &regError{
+	Status:  http.StatusMethodNotAllowed,
+	Code:    "UNSUPPORTED",
+	Message: "registry is in read-only mode",
+}
+
+// isWrite returns whether req would modify the registry.
+func isWrite(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// WithQuota limits the total size of stored blobs and manifests to maxBytes.
+//
+// When an upload takes the registry over its quota, blobs that no manifest
+// refers to are evicted, least recently used first. If that doesn't free up
+// enough space, the upload is rejected.
+//
+// Blobs that have been uploaded for a manifest that hasn't been pushed yet
+// aren't referenced either, but they are the most recently used, so they're
+// only evicted if nothing else is left.
+//
+// The BlobHandler must implement BlobStore to enforce a quota.
+func WithQuota(maxBytes int64) Option {
+	return func(r *registry) {
+		r.quota = maxBytes
+	}
+}
+
+// errQuotaExceeded is returned by quotaBlobHandler.Put when a blob doesn't fit.
+var errQuotaExceeded = errors.New("storage quota exceeded")
+
+var regErrQuotaExceeded = &regError{
+	Status:  http.StatusForbidden,
+	Code:    "DENIED",
+	Message: "storage quota exceeded",
+}
+
+// quotaBlobHandler is a BlobHandler that enforces a storage quota, see
+// WithQuota.
+type quotaBlobHandler struct {
+	inner BlobStore
+	max   int64
+
+	// The manifests we count towards the quota and check references against.
+	manifests *manifests
+
+	lock     sync.Mutex
+	accessed map[v1.Hash]time.Time
+
+	// sizes and total are the sizes of the blobs we have, and their sum. They
+	// are populated from inner the first time they're needed, and kept up to
+	// date from then on.
+	loaded bool
+	sizes  map[v1.Hash]int64
+	total  int64
+}
+
+func (q *quotaBlobHandler) touch(h v1.Hash) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.accessed[h] = time.Now()
+}
+
+// load populates sizes and total with the blobs that were stored before we
+// started tracking them, e.g. by a previous registry using WithDiskStorage.
+// It must be called with q.lock held.
+func (q *quotaBlobHandler) load(ctx context.Context) error {
+	if q.loaded {
+		return nil
+	}
+	hs, err := q.inner.List(ctx)
+	if err != nil {
+		return err
+	}
+	q.sizes = make(map[v1.Hash]int64, len(hs))
+	for _, h := range hs {
+		size, err := q.inner.Stat(ctx, "", h)
+		if err != nil {
+			continue
+		}
+		q.sizes[h] = size
+		q.total += size
+	}
+	q.loaded = true
+	return nil
+}
+
+// Get implements BlobHandler.
+func (q *quotaBlobHandler) Get(ctx context.Context, repo string, h v1.Hash) (io.ReadCloser, error) {
+	rc, err := q.inner.Get(ctx, repo, h)
+	if err == nil {
+		q.touch(h)
+	}
+	return rc, err
+}
+
+// Stat implements BlobStatHandler.
+func (q *quotaBlobHandler) Stat(ctx context.Context, repo string, h v1.Hash) (int64, error) {
+	size, err := q.inner.Stat(ctx, repo, h)
+	if err == nil {
+		q.touch(h)
+	}
+	return size, err
+}
+
+// Delete implements BlobDeleteHandler.
+func (q *quotaBlobHandler) Delete(ctx context.Context, repo string, h v1.Hash) error {
+	if err := q.inner.Delete(ctx, repo, h); err != nil {
+		return err
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	delete(q.accessed, h)
+	if size, ok := q.sizes[h]; ok {
+		q.total -= size
+		delete(q.sizes, h)
+	}
+	return nil
+}
+
+// List implements BlobListHandler.
+func (q *quotaBlobHandler) List(ctx context.Context) ([]v1.Hash, error) {
+	return q.inner.List(ctx)
+}
+
+// Put implements BlobPutHandler.
+func (q *quotaBlobHandler) Put(ctx context.Context, repo string, h v1.Hash, rc io.ReadCloser) error {
+	if err := q.inner.Put(ctx, repo, h, rc); err != nil {
+		return err
+	}
+	size, err := q.inner.Stat(ctx, repo, h)
+	if err != nil {
+		return err
+	}
+
+	q.lock.Lock()
+	err = q.load(ctx)
+	if err == nil {
+		// load may have already counted h.
+		q.total += size - q.sizes[h]
+		q.sizes[h] = size
+		q.accessed[h] = time.Now()
+	}
+	q.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := q.enforce(ctx, h); err != nil {
+		// Don't keep what we couldn't make room for.
+		if derr := q.Delete(ctx, repo, h); derr != nil {
+			return derr
+		}
+		return err
+	}
+	return nil
+}
+
+type manifestSize struct {
+	digest v1.Hash
+	size   int64
+}
+
+// enforce evicts blobs that no manifest refers to until we're within the
+// quota, without evicting the blob h that was just stored.
+func (q *quotaBlobHandler) enforce(ctx context.Context, h v1.Hash) error {
+	m := q.manifests
+	m.lock.RLock()
+	// Manifests count towards the quota too, and they and anything that any
+	// of them refers to, tagged or not, are off limits.
+	var manifests []manifestSize
+	referenced := map[v1.Hash]bool{}
+	for _, c := range m.manifests {
+		seen := map[v1.Hash]bool{}
+		for _, mf := range c {
+			d, _, err := v1.SHA256(bytes.NewReader(mf.blob))
+			if err != nil {
+				m.lock.RUnlock()
+				return err
+			}
+			// Manifests share storage with blobs on disk.
+			referenced[d] = true
+			if seen[d] {
+				continue
+			}
+			seen[d] = true
+			manifests = append(manifests, manifestSize{d, int64(len(mf.blob))})
+			for _, ref := range blobReferences(mf.blob) {
+				referenced[ref] = true
+			}
+		}
+	}
+	m.lock.RUnlock()
+
+	q.lock.Lock()
+	total := q.total
+	for _, ms := range manifests {
+		// Don't count manifests that are stored as blobs twice.
+		if _, ok := q.sizes[ms.digest]; !ok {
+			total += ms.size
+		}
+	}
+	if total <= q.max {
+		q.lock.Unlock()
+		return nil
+	}
+	var candidates []v1.Hash
+	for bh := range q.sizes {
+		if bh != h && !referenced[bh] {
+			candidates = append(candidates, bh)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return q.accessed[candidates[i]].Before(q.accessed[candidates[j]])
+	})
+	q.lock.Unlock()
+
+	for _, bh := range candidates {
+		if total <= q.max {
+			break
+		}
+		q.lock.Lock()
+		size := q.sizes[bh]
+		q.lock.Unlock()
+		if err := q.Delete(ctx, "", bh); err != nil {
+			return err
+		}
+		total -= size
+	}
+
+	if total > q.max {
+		return errQuotaExceeded
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+)
+
+func TestReadOnly(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.WithReadOnly()))
+	defer s.Close()
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/v2/", http.StatusOK},
+		{http.MethodGet, "/v2/foo/manifests/latest", http.StatusNotFound},
+		{http.MethodPost, "/v2/foo/blobs/uploads/", http.StatusMethodNotAllowed},
+		{http.MethodPut, "/v2/foo/manifests/latest", http.StatusMethodNotAllowed},
+		{http.MethodDelete, "/v2/foo/manifests/latest", http.StatusMethodNotAllowed},
+	} {
+		req, err := http.NewRequest(tc.method, s.URL+tc.path, strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := s.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s %s: got %d, want %d", tc.method, tc.path, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestQuota(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.WithQuota(2500)))
+	defer s.Close()
+
+	push := func(b []byte, want int) string {
+		t.Helper()
+		digest := fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+		resp, err := http.Post(s.URL+"/v2/foo/blobs/uploads/?digest="+digest, "", bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("pushing %d bytes: got %d, want %d", len(b), resp.StatusCode, want)
+		}
+		return digest
+	}
+	exists := func(digest string) bool {
+		t.Helper()
+		resp, err := http.Head(s.URL + "/v2/foo/blobs/" + digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}
+
+	old := push(bytes.Repeat([]byte{'x'}, 1000), http.StatusCreated)
+	config := push(bytes.Repeat([]byte{'y'}, 1000), http.StatusCreated)
+
+	// Tag a manifest that refers to config, so that it can't be evicted.
+	mf := fmt.Sprintf(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v1+json","size":1000,"digest":%q},"layers":[]}`, config)
+	req, err := http.NewRequest(http.MethodPut, s.URL+"/v2/foo/manifests/keep", strings.NewReader(mf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("pushing manifest: %s", resp.Status)
+	}
+
+	// This goes over the quota, so the untagged blob is evicted.
+	recent := push(bytes.Repeat([]byte{'z'}, 1000), http.StatusCreated)
+	if exists(old) {
+		t.Error("untagged blob was not evicted")
+	}
+	if !exists(config) || !exists(recent) {
+		t.Error("tagged or new blob was evicted")
+	}
+
+	// This can't fit even after evicting everything that isn't tagged.
+	big := push(bytes.Repeat([]byte{'w'}, 2000), http.StatusForbidden)
+	if exists(big) {
+		t.Error("rejected blob was stored")
+	}
+	if !exists(config) {
+		t.Error("tagged blob was evicted")
+	}
+}
+
+func TestQuotaUntaggedManifest(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.WithQuota(1500)))
+	defer s.Close()
+
+	push := func(b []byte) (string, int) {
+		t.Helper()
+		digest := fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+		resp, err := http.Post(s.URL+"/v2/foo/blobs/uploads/?digest="+digest, "", bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return digest, resp.StatusCode
+	}
+
+	config, code := push(bytes.Repeat([]byte{'y'}, 1000))
+	if code != http.StatusCreated {
+		t.Fatalf("pushing config: got %d", code)
+	}
+
+	// Push a manifest that refers to config by digest only.
+	mf := fmt.Sprintf(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v1+json","size":1000,"digest":%q},"layers":[]}`, config)
+	mfDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(mf)))
+	req, err := http.NewRequest(http.MethodPut, s.URL+"/v2/foo/manifests/"+mfDigest, strings.NewReader(mf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("pushing manifest: %s", resp.Status)
+	}
+
+	// The blobs alone would fit, but not along with the manifest, and the
+	// untagged manifest still needs config.
+	if _, code := push(bytes.Repeat([]byte{'z'}, 400)); code != http.StatusForbidden {
+		t.Errorf("pushing over quota: got %d, want %d", code, http.StatusForbidden)
+	}
+	resp, err = http.Head(s.URL + "/v2/foo/blobs/" + config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Error("blob referenced by an untagged manifest was evicted")
+	}
+}
+
+func TestQuotaDiskStorage(t *testing.T) {
+	dir := t.TempDir()
+	s := httptest.NewServer(registry.New(registry.WithDiskStorage(dir), registry.WithQuota(2400)))
+	defer s.Close()
+
+	push := func(b []byte) string {
+		t.Helper()
+		digest := fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+		resp, err := http.Post(s.URL+"/v2/foo/blobs/uploads/?digest="+digest, "", bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("pushing %d bytes: got %d", len(b), resp.StatusCode)
+		}
+		return digest
+	}
+
+	config := push(bytes.Repeat([]byte{'y'}, 1000))
+	mf := fmt.Sprintf(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v1+json","size":1000,"digest":%q},"layers":[]}`, config)
+	req, err := http.NewRequest(http.MethodPut, s.URL+"/v2/foo/manifests/keep", strings.NewReader(mf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("pushing manifest: %s", resp.Status)
+	}
+
+	// After a restart, the manifest is found alongside the blobs on disk.
+	// Counting it once, this fits, and the manifest must not be evicted to
+	// make room.
+	s.Close()
+	s = httptest.NewServer(registry.New(registry.WithDiskStorage(dir), registry.WithQuota(2400)))
+	defer s.Close()
+	push(bytes.Repeat([]byte{'z'}, 1000))
+
+	// A registry started on the same directory still has the manifest.
+	s2 := httptest.NewServer(registry.New(registry.WithDiskStorage(dir)))
+	defer s2.Close()
+	resp, err = http.Get(s2.URL + "/v2/foo/manifests/keep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("manifest after restart: got %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
@@ -21,11 +21,14 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"path"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
 
+	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 )
@@ -49,6 +52,15 @@ type manifests struct {
 	manifests map[string]map[string]manifest
 	lock      sync.RWMutex
 	log       *log.Logger
+
+	// If set, manifests are persisted to store, see WithManifestStore.
+	store ManifestStore
+
+	// Whether we serve the referrers API, see WithReferrersSupport.
+	referrersEnabled bool
+
+	// If set, we pull manifests we don't have from upstream, see WithProxy.
+	upstream Upstream
 }
 
 func isManifest(req *http.Request) bool {
@@ -89,6 +101,20 @@ func isReferrers(req *http.Request) bool {
 	return elems[len(elems)-2] == "referrers"
 }
 
+// checkRepository returns an error if repo isn't a valid repository name.
+// Repository names are used as paths by WithDiskStorage, so this also rejects
+// names with empty, "." or ".." components that name.NewRepository allows.
+func checkRepository(repo string) *regError {
+	if _, err := name.NewRepository(repo); err != nil || path.Clean("/"+repo) != "/"+repo {
+		return &regError{
+			Status:  http.StatusBadRequest,
+			Code:    "NAME_INVALID",
+			Message: fmt.Sprintf("invalid repository name %q", repo),
+		}
+	}
+	return nil
+}
+
 // https://github.com/opencontainers/distribution-spec/blob/master/spec.md#pulling-an-image-manifest
 // https://github.com/opencontainers/distribution-spec/blob/master/spec.md#pushing-an-image
 func (m *manifests) handle(resp http.ResponseWriter, req *http.Request) *regError {
@@ -96,6 +122,13 @@ func (m *manifests) handle(resp http.ResponseWriter, req *http.Request) *regErro
 	elem = elem[1:]
 	target := elem[len(elem)-1]
 	repo := strings.Join(elem[1:len(elem)-2], "/")
+	if err := checkRepository(repo); err != nil {
+		return err
+	}
+
+	if m.upstream != nil && (req.Method == http.MethodGet || req.Method == http.MethodHead) {
+		m.pull(req.Context(), repo, target)
+	}
 
 	switch req.Method {
 	case http.MethodGet:
@@ -215,6 +248,15 @@ func (m *manifests) handle(resp http.ResponseWriter, req *http.Request) *regErro
 		// See https://docs.docker.com/engine/reference/commandline/pull/#pull-an-image-by-digest-immutable-identifier.
 		m.manifests[repo][digest] = mf
 		m.manifests[repo][target] = mf
+		if err := m.save(repo); err != nil {
+			return regErrInternal(err)
+		}
+		// Let clients know that we process the subject field, so that they
+		// don't need to maintain the referrers tag schema themselves.
+		// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pushing-manifests-with-subject
+		if subject, ok := subjectDigest(mf.blob); ok && m.referrersEnabled {
+			resp.Header().Set("OCI-Subject", subject.String())
+		}
 		resp.Header().Set("Docker-Content-Digest", digest)
 		resp.WriteHeader(http.StatusCreated)
 		return nil
@@ -240,6 +282,9 @@ func (m *manifests) handle(resp http.ResponseWriter, req *http.Request) *regErro
 		}
 
 		delete(m.manifests[repo], target)
+		if err := m.save(repo); err != nil {
+			return regErrInternal(err)
+		}
 		resp.WriteHeader(http.StatusAccepted)
 		return nil
 
@@ -256,6 +301,9 @@ func (m *manifests) handleTags(resp http.ResponseWriter, req *http.Request) *reg
 	elem := strings.Split(req.URL.Path, "/")
 	elem = elem[1:]
 	repo := strings.Join(elem[1:len(elem)-2], "/")
+	if err := checkRepository(repo); err != nil {
+		return err
+	}
 
 	if req.Method == "GET" {
 		m.lock.RLock()
@@ -281,15 +329,10 @@ func (m *manifests) handleTags(resp http.ResponseWriter, req *http.Request) *reg
 		// https://github.com/opencontainers/distribution-spec/blob/b505e9cc53ec499edbd9c1be32298388921bb705/detail.md#tags-paginated
 		// Offset using last query parameter.
 		if last := req.URL.Query().Get("last"); last != "" {
-			for i, t := range tags {
-				if t > last {
-					tags = tags[i:]
-					break
-				}
-			}
+			tags = tags[sort.Search(len(tags), func(i int) bool { return tags[i] > last }):]
 		}
 
-		// Limit using n query parameter.
+		// Limit using n query parameter, linking to the next page if there is one.
 		if ns := req.URL.Query().Get("n"); ns != "" {
 			if n, err := strconv.Atoi(ns); err != nil {
 				return &regError{
@@ -299,6 +342,10 @@ func (m *manifests) handleTags(resp http.ResponseWriter, req *http.Request) *reg
 				}
 			} else if n < len(tags) {
 				tags = tags[:n]
+				if n > 0 {
+					next := url.Values{"n": {ns}, "last": {tags[n-1]}}
+					resp.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, req.URL.Path, next.Encode()))
+				}
 			}
 		}
 
@@ -333,17 +380,23 @@ func (m *manifests) handleCatalog(resp http.ResponseWriter, req *http.Request) *
 		m.lock.RLock()
 		defer m.lock.RUnlock()
 
-		var repos []string
-		countRepos := 0
-		// TODO: implement pagination
+		repos := make([]string, 0, len(m.manifests))
 		for key := range m.manifests {
-			if countRepos >= n {
-				break
-			}
-			countRepos++
-
 			repos = append(repos, key)
 		}
+		sort.Strings(repos)
+
+		// Paginate like tags/list, with the last and n query parameters.
+		if last := query.Get("last"); last != "" {
+			repos = repos[sort.Search(len(repos), func(i int) bool { return repos[i] > last }):]
+		}
+		if n >= 0 && n < len(repos) {
+			repos = repos[:n]
+			if n > 0 {
+				next := url.Values{"n": {strconv.Itoa(n)}, "last": {repos[n-1]}}
+				resp.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, req.URL.Path, next.Encode()))
+			}
+		}
 
 		repositoriesToList := catalog{
 			Repos: repos,
@@ -363,7 +416,7 @@ func (m *manifests) handleCatalog(resp http.ResponseWriter, req *http.Request) *
 	}
 }
 
-// TODO: implement handling of artifactType querystring
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#listing-referrers
 func (m *manifests) handleReferrers(resp http.ResponseWriter, req *http.Request) *regError {
 	// Ensure this is a GET request
 	if req.Method != "GET" {
@@ -378,6 +431,9 @@ func (m *manifests) handleReferrers(resp http.ResponseWriter, req *http.Request)
 	elem = elem[1:]
 	target := elem[len(elem)-1]
 	repo := strings.Join(elem[1:len(elem)-2], "/")
+	if err := checkRepository(repo); err != nil {
+		return err
+	}
 
 	// Validate that incoming target is a valid digest
 	if _, err := v1.NewHash(target); err != nil {
@@ -400,6 +456,8 @@ func (m *manifests) handleReferrers(resp http.ResponseWriter, req *http.Request)
 		}
 	}
 
+	artifactType := req.URL.Query().Get("artifactType")
+
 	im := v1.IndexManifest{
 		SchemaVersion: 2,
 		MediaType:     types.OCIImageIndex,
@@ -410,35 +468,75 @@ func (m *manifests) handleReferrers(resp http.ResponseWriter, req *http.Request)
 		if err != nil {
 			continue
 		}
-		var refPointer struct {
-			Subject *v1.Descriptor `json:"subject"`
-		}
-		json.Unmarshal(manifest.blob, &refPointer)
-		if refPointer.Subject == nil {
+		desc, ok := referrerDescriptor(manifest, h, target)
+		if !ok {
 			continue
 		}
-		referenceDigest := refPointer.Subject.Digest
-		if referenceDigest.String() != target {
+		if artifactType != "" && desc.ArtifactType != artifactType {
 			continue
 		}
-		// At this point, we know the current digest references the target
-		var imageAsArtifact struct {
-			Config struct {
-				MediaType string `json:"mediaType"`
-			} `json:"config"`
-		}
-		json.Unmarshal(manifest.blob, &imageAsArtifact)
-		im.Manifests = append(im.Manifests, v1.Descriptor{
-			MediaType:    types.MediaType(manifest.contentType),
-			Size:         int64(len(manifest.blob)),
-			Digest:       h,
-			ArtifactType: imageAsArtifact.Config.MediaType,
-		})
+		im.Manifests = append(im.Manifests, desc)
 	}
+	// Map iteration order is random, but our responses shouldn't be.
+	sort.Slice(im.Manifests, func(i, j int) bool {
+		return im.Manifests[i].Digest.String() < im.Manifests[j].Digest.String()
+	})
+
 	msg, _ := json.Marshal(&im)
+	if artifactType != "" {
+		resp.Header().Set("OCI-Filters-Applied", "artifactType")
+	}
 	resp.Header().Set("Content-Length", fmt.Sprint(len(msg)))
 	resp.Header().Set("Content-Type", string(types.OCIImageIndex))
 	resp.WriteHeader(http.StatusOK)
 	io.Copy(resp, bytes.NewReader([]byte(msg)))
 	return nil
 }
+
+// referrerManifest holds the fields of an image manifest or index that are
+// relevant to the referrers API.
+type referrerManifest struct {
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Subject      *v1.Descriptor    `json:"subject,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	Config       *struct {
+		MediaType string `json:"mediaType"`
+	} `json:"config,omitempty"`
+}
+
+// referrerDescriptor returns the descriptor of mf, which has digest h, for
+// inclusion in the referrers response for target, if mf refers to target.
+func referrerDescriptor(mf manifest, h v1.Hash, target string) (v1.Descriptor, bool) {
+	var rm referrerManifest
+	if err := json.Unmarshal(mf.blob, &rm); err != nil {
+		return v1.Descriptor{}, false
+	}
+	if rm.Subject == nil || rm.Subject.Digest.String() != target {
+		return v1.Descriptor{}, false
+	}
+
+	// The artifactType field takes precedence, falling back to the config
+	// media type for image manifests.
+	// https://github.com/opencontainers/image-spec/blob/main/manifest.md#guidelines-for-artifact-usage
+	artifactType := rm.ArtifactType
+	if artifactType == "" && rm.Config != nil {
+		artifactType = rm.Config.MediaType
+	}
+	return v1.Descriptor{
+		MediaType:    types.MediaType(mf.contentType),
+		Size:         int64(len(mf.blob)),
+		Digest:       h,
+		ArtifactType: artifactType,
+		Annotations:  rm.Annotations,
+	}, true
+}
+
+// subjectDigest returns the digest of the subject of the manifest in b, if it
+// has one.
+func subjectDigest(b []byte) (v1.Hash, bool) {
+	var rm referrerManifest
+	if err := json.Unmarshal(b, &rm); err != nil || rm.Subject == nil {
+		return v1.Hash{}, false
+	}
+	return rm.Subject.Digest, true
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// The on-disk structure used by WithDiskStorage resembles an OCI image layout
+// per repository, with a blob store that is shared between repositories:
+//
+//	<dir>/blobs/<algorithm>/<hex>                 blob and manifest contents
+//	<dir>/repositories/<repo>/index.json          manifests in <repo>
+//
+// Each index.json is an OCI image index with one descriptor per manifest
+// digest, plus one descriptor per tag, annotated with the tag name.
+const (
+	blobsDir        = "blobs"
+	repositoriesDir = "repositories"
+	indexFile       = "index.json"
+)
+
+type diskManifestStore struct {
+	dir string
+}
+
+// NewDiskManifestStore returns a ManifestStore that persists manifests under
+// dir, using the same layout as WithDiskStorage.
+func NewDiskManifestStore(dir string) ManifestStore { return &diskManifestStore{dir: dir} }
+
+// manifestPath returns the path to the contents of the manifest with digest h.
+func (s *diskManifestStore) manifestPath(h v1.Hash) string {
+	return filepath.Join(s.dir, blobsDir, h.Algorithm, h.Hex)
+}
+
+// indexPath returns the path to the index.json of repo, or an error if repo
+// would put it outside of dir.
+func (s *diskManifestStore) indexPath(repo string) (string, error) {
+	root := filepath.Join(s.dir, repositoriesDir)
+	p := filepath.Join(root, filepath.FromSlash(repo), indexFile)
+	if rel, err := filepath.Rel(root, p); err != nil || !filepath.IsLocal(rel) || rel == indexFile {
+		return "", fmt.Errorf("invalid repository name %q", repo)
+	}
+	return p, nil
+}
+
+// Repositories implements ManifestStore.
+func (s *diskManifestStore) Repositories(_ context.Context) ([]string, error) {
+	var repos []string
+	root := filepath.Join(s.dir, repositoriesDir)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != indexFile {
+			return nil
+		}
+		rel, err := filepath.Rel(root, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		repos = append(repos, filepath.ToSlash(rel))
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return repos, err
+}
+
+// Load implements ManifestStore.
+func (s *diskManifestStore) Load(_ context.Context, repo string) (map[string]Manifest, error) {
+	p, err := s.indexPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	im, err := v1.ParseIndexManifest(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	c := make(map[string]Manifest, len(im.Manifests))
+	for _, desc := range im.Manifests {
+		blob, err := os.ReadFile(s.manifestPath(desc.Digest))
+		if err != nil {
+			return nil, err
+		}
+		mf := Manifest{
+			ContentType: string(desc.MediaType),
+			Blob:        blob,
+		}
+		if tag, ok := desc.Annotations[imageRefName]; ok {
+			c[tag] = mf
+		} else {
+			c[desc.Digest.String()] = mf
+		}
+	}
+	return c, nil
+}
+
+const imageRefName = "org.opencontainers.image.ref.name"
+
+// Save implements ManifestStore.
+func (s *diskManifestStore) Save(_ context.Context, repo string, c map[string]Manifest) error {
+	p, err := s.indexPath(repo)
+	if err != nil {
+		return err
+	}
+	if len(c) == 0 {
+		// Other repositories may be nested under this one, so only remove
+		// our index.json, and then any directories that leaves empty.
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		root := filepath.Join(s.dir, repositoriesDir)
+		for dir := filepath.Dir(p); dir != root; dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+		return nil
+	}
+
+	im := v1.IndexManifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIImageIndex,
+		Manifests:     []v1.Descriptor{},
+	}
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		mf := c[key]
+		h, size, err := v1.SHA256(bytes.NewReader(mf.Blob))
+		if err != nil {
+			return err
+		}
+		// Manifest contents are content-addressed, so we only need to write
+		// them once.
+		if _, err := os.Stat(s.manifestPath(h)); errors.Is(err, fs.ErrNotExist) {
+			if err := writeFileAtomic(s.manifestPath(h), mf.Blob); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+		desc := v1.Descriptor{
+			MediaType: types.MediaType(mf.ContentType),
+			Size:      size,
+			Digest:    h,
+		}
+		if key != h.String() {
+			desc.Annotations = map[string]string{imageRefName: key}
+		}
+		im.Manifests = append(im.Manifests, desc)
+	}
+
+	b, err := json.Marshal(im)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(p, b)
+}
+
+// writeFileAtomic writes b to path via a temporary file, so that readers never
+// observe a partially written file.
+func writeFileAtomic(path string, b []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,257 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MetricsPath is the path of the metrics endpoint enabled by WithMetrics.
+const MetricsPath = "/metrics"
+
+// WithMetrics enables the MetricsPath endpoint, which serves metrics about
+// requests, blob traffic and upload sessions in the Prometheus text format.
+//
+// To keep this package free of dependencies, the metrics are rendered by hand
+// rather than with the Prometheus client library.
+func WithMetrics() Option {
+	return func(r *registry) {
+		r.metrics = &metrics{
+			requests:  map[requestKey]int64{},
+			durations: map[durationKey]*histogram{},
+		}
+	}
+}
+
+// durationBuckets are the upper bounds of the request duration histogram, in
+// seconds.
+var durationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type requestKey struct {
+	endpoint Endpoint
+	method   string
+	code     int
+}
+
+type durationKey struct {
+	endpoint Endpoint
+	method   string
+}
+
+type histogram struct {
+	counts []int64 // per bucket, not cumulative
+	sum    float64
+	count  int64
+}
+
+type metrics struct {
+	lock      sync.Mutex
+	requests  map[requestKey]int64
+	durations map[durationKey]*histogram
+
+	blobBytesServed   int64
+	blobBytesReceived int64
+	uploadsStarted    int64
+	uploadsCompleted  int64
+	uploadsCanceled   int64
+}
+
+// countingReader counts the bytes of the request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	r.n += int64(n)
+	return n, err
+}
+
+// observe records the request req, which was received at start.
+func (m *metrics) observe(w *statusRecorder, req *http.Request, body *countingReader, start time.Time) {
+	elapsed := time.Since(start).Seconds()
+	ep := endpoint(req)
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.requests[requestKey{ep, req.Method, status}]++
+	dk := durationKey{ep, req.Method}
+	h, ok := m.durations[dk]
+	if !ok {
+		h = &histogram{counts: make([]int64, len(durationBuckets))}
+		m.durations[dk] = h
+	}
+	for i, le := range durationBuckets {
+		if elapsed <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += elapsed
+	h.count++
+
+	switch ep {
+	case EndpointBlobs:
+		if req.Method == http.MethodGet {
+			m.blobBytesServed += w.written
+		}
+	case EndpointUploads:
+		m.blobBytesReceived += body.n
+		switch {
+		case req.Method == http.MethodPost && status == http.StatusAccepted:
+			m.uploadsStarted++
+		case (req.Method == http.MethodPut || req.Method == http.MethodPost) && status == http.StatusCreated:
+			m.uploadsCompleted++
+		case req.Method == http.MethodDelete && status == http.StatusNoContent:
+			m.uploadsCanceled++
+		}
+	}
+}
+
+func (r *registry) handleMetrics(resp http.ResponseWriter, req *http.Request) *regError {
+	if req.Method != http.MethodGet {
+		return &regError{
+			Status:  http.StatusMethodNotAllowed,
+			Code:    "UNSUPPORTED",
+			Message: "metrics must be fetched with GET",
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	r.metrics.write(buf)
+
+	// Gauges that we compute from the current state of the registry.
+	r.blobs.lock.Lock()
+	active := len(r.blobs.uploads)
+	r.blobs.lock.Unlock()
+	writeMetric(buf, "registry_upload_sessions_active", "gauge", "Upload sessions in progress.")
+	fmt.Fprintf(buf, "registry_upload_sessions_active %d\n", active)
+
+	if stored, ok := r.storedBytes(req); ok {
+		writeMetric(buf, "registry_blob_bytes_stored", "gauge", "Total size of stored blobs, in bytes.")
+		fmt.Fprintf(buf, "registry_blob_bytes_stored %d\n", stored)
+	}
+
+	resp.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	resp.Header().Set("Content-Length", fmt.Sprint(buf.Len()))
+	resp.WriteHeader(http.StatusOK)
+	io.Copy(resp, buf)
+	return nil
+}
+
+// storedBytes returns the total size of stored blobs, if the BlobHandler
+// supports listing and statting them.
+func (r *registry) storedBytes(req *http.Request) (int64, bool) {
+	blh, ok := r.blobs.blobHandler.(BlobListHandler)
+	if !ok {
+		return 0, false
+	}
+	bsh, ok := r.blobs.blobHandler.(BlobStatHandler)
+	if !ok {
+		return 0, false
+	}
+	hs, err := blh.List(req.Context())
+	if err != nil {
+		return 0, false
+	}
+	var total int64
+	for _, h := range hs {
+		size, err := bsh.Stat(req.Context(), "", h)
+		if err != nil {
+			// Deleted since we listed it.
+			continue
+		}
+		total += size
+	}
+	return total, true
+}
+
+func writeMetric(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// write renders the counters and histograms in m.
+func (m *metrics) write(w io.Writer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	writeMetric(w, "registry_http_requests_total", "counter", "Requests by endpoint, method and status code.")
+	rks := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		rks = append(rks, k)
+	}
+	sort.Slice(rks, func(i, j int) bool {
+		if rks[i].endpoint != rks[j].endpoint {
+			return rks[i].endpoint < rks[j].endpoint
+		}
+		if rks[i].method != rks[j].method {
+			return rks[i].method < rks[j].method
+		}
+		return rks[i].code < rks[j].code
+	})
+	for _, k := range rks {
+		fmt.Fprintf(w, "registry_http_requests_total{endpoint=%q,method=%q,code=\"%d\"} %d\n", k.endpoint, k.method, k.code, m.requests[k])
+	}
+
+	writeMetric(w, "registry_http_request_duration_seconds", "histogram", "Request latencies by endpoint and method.")
+	dks := make([]durationKey, 0, len(m.durations))
+	for k := range m.durations {
+		dks = append(dks, k)
+	}
+	sort.Slice(dks, func(i, j int) bool {
+		if dks[i].endpoint != dks[j].endpoint {
+			return dks[i].endpoint < dks[j].endpoint
+		}
+		return dks[i].method < dks[j].method
+	})
+	for _, k := range dks {
+		h := m.durations[k]
+		var cumulative int64
+		for i, le := range durationBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "registry_http_request_duration_seconds_bucket{endpoint=%q,method=%q,le=%q} %d\n", k.endpoint, k.method, strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "registry_http_request_duration_seconds_bucket{endpoint=%q,method=%q,le=\"+Inf\"} %d\n", k.endpoint, k.method, h.count)
+		fmt.Fprintf(w, "registry_http_request_duration_seconds_sum{endpoint=%q,method=%q} %s\n", k.endpoint, k.method, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(w, "registry_http_request_duration_seconds_count{endpoint=%q,method=%q} %d\n", k.endpoint, k.method, h.count)
+	}
+
+	for _, c := range []struct {
+		name, help string
+		value      int64
+	}{
+		{"registry_blob_bytes_served_total", "Bytes of blob contents served.", m.blobBytesServed},
+		{"registry_blob_bytes_received_total", "Bytes of blob contents received in uploads.", m.blobBytesReceived},
+		{"registry_upload_sessions_started_total", "Upload sessions started.", m.uploadsStarted},
+		{"registry_upload_sessions_completed_total", "Uploads completed, including monolithic uploads.", m.uploadsCompleted},
+		{"registry_upload_sessions_canceled_total", "Upload sessions canceled.", m.uploadsCanceled},
+	} {
+		writeMetric(w, c.name, "counter", c.help)
+		fmt.Fprintf(w, "%s %d\n", c.name, c.value)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+)
+
+func TestMetrics(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.WithMetrics()))
+	defer s.Close()
+
+	do := func(method, path, body string) {
+		t.Helper()
+		req, err := http.NewRequest(method, s.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := s.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	do(http.MethodPost, "/v2/foo/blobs/uploads/?digest="+fooDigest, "foo")
+	do(http.MethodGet, "/v2/foo/blobs/"+fooDigest, "")
+	do(http.MethodGet, "/v2/foo/blobs/"+fooDigest, "")
+	do(http.MethodGet, "/v2/foo/manifests/latest", "")
+	do(http.MethodPost, "/v2/foo/blobs/uploads/", "")
+
+	resp, err := http.Get(s.URL + registry.MetricsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: %s", registry.MetricsPath, resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+
+	for _, want := range []string{
+		`registry_http_requests_total{endpoint="uploads",method="POST",code="201"} 1`,
+		`registry_http_requests_total{endpoint="uploads",method="POST",code="202"} 1`,
+		`registry_http_requests_total{endpoint="blobs",method="GET",code="200"} 2`,
+		`registry_http_requests_total{endpoint="manifests",method="GET",code="404"} 1`,
+		`registry_http_request_duration_seconds_count{endpoint="blobs",method="GET"} 2`,
+		`registry_http_request_duration_seconds_bucket{endpoint="blobs",method="GET",le="+Inf"} 2`,
+		"registry_blob_bytes_served_total 6",
+		"registry_blob_bytes_received_total 3",
+		"registry_upload_sessions_started_total 1",
+		"registry_upload_sessions_completed_total 1",
+		"registry_upload_sessions_active 1",
+		"registry_blob_bytes_stored 3",
+		"# TYPE registry_http_request_duration_seconds histogram",
+	} {
+		if !strings.Contains(got, want+"\n") {
+			t.Errorf("metrics missing %q:\n%s", want, got)
+		}
+	}
+}
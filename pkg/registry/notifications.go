@@ -0,0 +1,282 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Actions reported in Event.Action.
+const (
+	EventActionPush   = "push"
+	EventActionPull   = "pull"
+	EventActionDelete = "delete"
+)
+
+// EventsMediaType is the media type of the envelope sent to webhooks.
+const EventsMediaType = "application/vnd.docker.distribution.events.v1+json"
+
+// Event describes something that happened to a manifest or blob, modelled
+// after the notifications sent by the Docker distribution registry.
+// https://distribution.github.io/distribution/about/notifications/
+type Event struct {
+	ID        string       `json:"id"`
+	Timestamp time.Time    `json:"timestamp"`
+	Action    string       `json:"action"`
+	Target    EventTarget  `json:"target"`
+	Request   EventRequest `json:"request"`
+}
+
+// EventTarget describes the manifest or blob that an Event is about.
+type EventTarget struct {
+	MediaType  string  `json:"mediaType,omitempty"`
+	Size       int64   `json:"size,omitempty"`
+	Digest     v1.Hash `json:"digest"`
+	Repository string  `json:"repository"`
+	URL        string  `json:"url,omitempty"`
+	Tag        string  `json:"tag,omitempty"`
+}
+
+// EventRequest describes the request that caused an Event.
+type EventRequest struct {
+	ID        string `json:"id"`
+	Addr      string `json:"addr,omitempty"`
+	Host      string `json:"host,omitempty"`
+	Method    string `json:"method"`
+	UserAgent string `json:"useragent,omitempty"`
+}
+
+// WithNotifier calls f with every Event, after the response to the request
+// causing it has been written.
+//
+// Events are delivered synchronously, in order, so that tests can make
+// assertions about them as soon as the request completes.
+func WithNotifier(f func(Event)) Option {
+	return func(r *registry) {
+		r.notifiers = append(r.notifiers, f)
+	}
+}
+
+// webhookQueueSize is how many events WithWebhook holds while waiting for a
+// slow endpoint, beyond which further events are dropped.
+const webhookQueueSize = 1024
+
+// WithWebhook POSTs every Event to endpoint, in an envelope of the form
+// {"events": [...]} with the EventsMediaType content type.
+//
+// Events are queued and sent in the background, so that a slow or
+// unreachable endpoint doesn't hold up the registry's responses. Delivery is
+// best effort: if the queue is full or the POST fails, the events are logged
+// and dropped.
+func WithWebhook(endpoint string) Option {
+	return func(r *registry) {
+		client := &http.Client{Timeout: 10 * time.Second}
+		queue := make(chan Event, webhookQueueSize)
+		go func() {
+			for e := range queue {
+				// Send whatever else has queued up while we were busy
+				// along with e.
+				events := []Event{e}
+				for len(events) < cap(queue) && len(queue) > 0 {
+					events = append(events, <-queue)
+				}
+				if err := sendEvents(client, endpoint, events...); err != nil {
+					r.log.Printf("failed to notify %s of %d events: %v", endpoint, len(events), err)
+				}
+			}
+		}()
+		r.notifiers = append(r.notifiers, func(e Event) {
+			select {
+			case queue <- e:
+			default:
+				r.log.Printf("dropping %s event for %s: queue for %s is full", e.Action, e.Target.URL, endpoint)
+			}
+		})
+	}
+}
+
+func sendEvents(client *http.Client, endpoint string, events ...Event) error {
+	b, err := json.Marshal(struct {
+		Events []Event `json:"events"`
+	}{events})
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(endpoint, EventsMediaType, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+// statusRecorder remembers the status code and the size of the body written
+// to a ResponseWriter. It passes Flush and ReadFrom through, so that wrapping
+// doesn't stop blobs being streamed or sent with sendfile.
+type statusRecorder struct {
+	http.ResponseWriter
+	status  int
+	written int64
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.written += int64(n)
+	return n, err
+}
+
+func (s *statusRecorder) ReadFrom(r io.Reader) (int64, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	var n int64
+	var err error
+	if rf, ok := s.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		// Hide s's own ReadFrom from io.Copy.
+		n, err = io.Copy(struct{ io.Writer }{s.ResponseWriter}, r)
+	}
+	s.written += n
+	return n, err
+}
+
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		if s.status == 0 {
+			s.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying ResponseWriter.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// event returns the Event for a successful request, if there is one.
+//
+// This works from the request and response headers rather than from within
+// the handlers, so that notifiers are never called while holding locks.
+func (r *registry) event(rec *statusRecorder, req *http.Request) (Event, bool) {
+	var action string
+	switch {
+	case req.Method == http.MethodGet && (rec.status == http.StatusOK || rec.status == http.StatusPartialContent):
+		action = EventActionPull
+	case (req.Method == http.MethodPut || req.Method == http.MethodPost) && rec.status == http.StatusCreated:
+		action = EventActionPush
+	case req.Method == http.MethodDelete && rec.status == http.StatusAccepted:
+		action = EventActionDelete
+	default:
+		return Event{}, false
+	}
+
+	elem := strings.Split(req.URL.Path, "/")[1:]
+	target := elem[len(elem)-1]
+	header := rec.Header()
+
+	var t EventTarget
+	switch {
+	case isManifest(req):
+		t.Repository = strings.Join(elem[1:len(elem)-2], "/")
+		t.URL = req.URL.Path
+		if action == EventActionPush {
+			t.MediaType = req.Header.Get("Content-Type")
+			t.Size = req.ContentLength
+		} else {
+			t.MediaType = header.Get("Content-Type")
+			t.Size, _ = strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+		}
+		if h, err := v1.NewHash(target); err == nil {
+			t.Digest = h
+		} else {
+			t.Tag = target
+			t.Digest, _ = v1.NewHash(header.Get("Docker-Content-Digest"))
+		}
+
+	case isBlob(req):
+		if action == EventActionPush {
+			// Uploads finish at .../blobs/uploads/<id>, we want .../blobs/<digest>.
+			t.Repository = strings.Join(elem[1:len(elem)-3], "/")
+			h, err := v1.NewHash(header.Get("Docker-Content-Digest"))
+			if err != nil {
+				return Event{}, false
+			}
+			t.Digest = h
+			if bsh, ok := r.blobs.blobHandler.(BlobStatHandler); ok {
+				t.Size, _ = bsh.Stat(req.Context(), t.Repository, h)
+			}
+		} else {
+			t.Repository = strings.Join(elem[1:len(elem)-2], "/")
+			h, err := v1.NewHash(target)
+			if err != nil {
+				return Event{}, false
+			}
+			t.Digest = h
+			if rec.status == http.StatusOK {
+				t.Size, _ = strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+			}
+		}
+		t.URL = "/v2/" + t.Repository + "/blobs/" + t.Digest.String()
+
+	default:
+		return Event{}, false
+	}
+
+	return Event{
+		ID:        randomID(),
+		Timestamp: time.Now().UTC(),
+		Action:    action,
+		Target:    t,
+		Request: EventRequest{
+			ID:        randomID(),
+			Addr:      req.RemoteAddr,
+			Host:      req.Host,
+			Method:    req.Method,
+			UserAgent: req.UserAgent(),
+		},
+	}, true
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b) //nolint:errcheck
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestNotifications(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		hooked  []registry.Event
+		invalid bool
+	)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("Content-Type") != registry.EventsMediaType {
+			invalid = true
+		}
+		var env struct {
+			Events []registry.Event `json:"events"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&env); err != nil {
+			invalid = true
+		}
+		hooked = append(hooked, env.Events...)
+	}))
+	defer hook.Close()
+
+	var events []registry.Event
+	s := httptest.NewServer(registry.New(
+		registry.WithNotifier(func(e registry.Event) { events = append(events, e) }),
+		registry.WithWebhook(hook.URL),
+	))
+	defer s.Close()
+
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(strings.TrimPrefix(s.URL, "http://") + "/foo/bar:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatalf("remote.Write: %v", err)
+	}
+
+	// Two layers and a config blob, then the manifest.
+	var pushed []registry.Event
+	for _, e := range events {
+		if e.Action == registry.EventActionPush {
+			pushed = append(pushed, e)
+		}
+	}
+	if len(pushed) != 4 {
+		t.Fatalf("got %d push events, want 4: %+v", len(pushed), events)
+	}
+	for _, e := range pushed[:3] {
+		if e.Target.Repository != "foo/bar" || e.Target.Size == 0 {
+			t.Errorf("unexpected blob push event: %+v", e)
+		}
+	}
+	if mf := pushed[3]; mf.Target.Tag != "latest" || mf.Target.Digest != d || mf.Target.MediaType == "" {
+		t.Errorf("unexpected manifest push event: %+v", mf)
+	}
+
+	events = nil
+	rimg, err := remote.Image(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rimg.RawConfigFile(); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Action != registry.EventActionPull || events[1].Action != registry.EventActionPull {
+		t.Fatalf("want manifest and config pull events, got %+v", events)
+	}
+	if events[0].Target.Digest != d || events[0].Target.Tag != "latest" {
+		t.Errorf("unexpected manifest pull event: %+v", events[0])
+	}
+
+	events = nil
+	if err := remote.Delete(ref.Context().Digest(d.String())); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Action != registry.EventActionDelete || events[0].Target.Digest != d {
+		t.Fatalf("want delete event, got %+v", events)
+	}
+
+	// Webhooks are delivered in the background, so give them a moment.
+	want := 4 + 2 + 1
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		got := len(hooked)
+		mu.Unlock()
+		if got >= want || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if invalid {
+		t.Error("webhook received an invalid request")
+	}
+	if len(hooked) != want {
+		t.Errorf("webhook got %d events, want %d", len(hooked), want)
+	}
+}
+
+func TestWebhookSlow(t *testing.T) {
+	release := make(chan struct{})
+	hook := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	defer hook.Close()
+	defer close(release)
+
+	s := httptest.NewServer(registry.New(registry.WithWebhook(hook.URL)))
+	defer s.Close()
+
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(strings.TrimPrefix(s.URL, "http://") + "/foo/bar:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A webhook that doesn't respond doesn't hold up pushes.
+	done := make(chan error)
+	go func() { done <- remote.Write(ref, img) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("remote.Write: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("remote.Write blocked on the webhook")
+	}
+}
+
+// readerFromRecorder is an httptest.ResponseRecorder that can ReadFrom, as
+// the server's ResponseWriter does, and notes when it's asked to.
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+	readFrom bool
+}
+
+func (r *readerFromRecorder) ReadFrom(src io.Reader) (int64, error) {
+	r.readFrom = true
+	return io.Copy(r.ResponseRecorder, src)
+}
+
+func TestNotificationsReadFrom(t *testing.T) {
+	var events []registry.Event
+	h := registry.New(
+		registry.WithDiskStorage(t.TempDir()),
+		registry.WithNotifier(func(e registry.Event) { events = append(events, e) }),
+	)
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	layer, err := random.Layer(1024, types.OCIUncompressedLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := layer.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(strings.TrimPrefix(s.URL, "http://") + "/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteLayer(repo, layer); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v2/foo/blobs/"+d.String(), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET blob: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if !w.readFrom {
+		t.Error("GET blob: ResponseWriter's ReadFrom wasn't used")
+	}
+	if len(events) == 0 || events[len(events)-1].Action != registry.EventActionPull {
+		t.Errorf("GET blob: want a pull event, got %v", events)
+	}
+}
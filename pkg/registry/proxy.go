@@ -0,0 +1,191 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/google/go-containerregistry/internal/verify"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Upstream is a registry that we pull through from, see WithProxy.
+//
+// Implementations are responsible for authenticating to the registry with
+// whatever scopes repo needs, e.g. by using a remote.Puller.
+//
+// If the registry doesn't have what was asked for, the returned error should
+// wrap fs.ErrNotExist.
+type Upstream interface {
+	// Manifest returns the manifest for target, a tag or digest, in repo,
+	// along with its media type.
+	Manifest(ctx context.Context, repo, target string) ([]byte, types.MediaType, error)
+
+	// Blob returns the contents of the blob h in repo.
+	Blob(ctx context.Context, repo string, h v1.Hash) (io.ReadCloser, error)
+}
+
+// fetchManifest fetches the manifest for target in repo from upstream.
+func (m *manifests) fetchManifest(ctx context.Context, repo, target string) (*manifest, error) {
+	b, mt, err := m.upstream.Manifest(ctx, repo, target)
+	if err != nil {
+		return nil, err
+	}
+	// Don't trust upstream to return the manifest we asked for by digest.
+	if want, err := v1.NewHash(target); err == nil {
+		got, _, err := v1.SHA256(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		if got != want {
+			return nil, fmt.Errorf("upstream manifest digest mismatch: got %s, want %s", got, want)
+		}
+	}
+	return &manifest{
+		contentType: string(mt),
+		blob:        b,
+	}, nil
+}
+
+// pull makes sure that the manifest for target in repo is stored locally,
+// fetching it from upstream if necessary.
+//
+// Manifests referenced by digest are immutable, so we only fetch them if we
+// don't have them. Tags can move, so we always check upstream for those,
+// falling back to what we have if upstream is unavailable.
+func (m *manifests) pull(ctx context.Context, repo, target string) {
+	_, isDigest := v1.NewHash(target)
+	if isDigest == nil {
+		m.lock.RLock()
+		_, ok := m.manifests[repo][target]
+		m.lock.RUnlock()
+		if ok {
+			return
+		}
+	}
+
+	mf, err := m.fetchManifest(ctx, repo, target)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			m.log.Printf("failed to pull manifest %s/%s from upstream: %v", repo, target, err)
+		}
+		return
+	}
+
+	h, _, _ := v1.SHA256(bytes.NewReader(mf.blob))
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.manifests[repo]; !ok {
+		m.manifests[repo] = make(map[string]manifest, 2)
+	}
+	m.manifests[repo][h.String()] = *mf
+	m.manifests[repo][target] = *mf
+	if err := m.save(repo); err != nil {
+		m.log.Printf("failed to save manifest %s/%s pulled from upstream: %v", repo, target, err)
+	}
+}
+
+// proxyBlobHandler is a BlobHandler that fetches blobs it doesn't have from
+// upstream, storing them in the inner BlobHandler if it supports writes.
+type proxyBlobHandler struct {
+	inner    BlobHandler
+	upstream Upstream
+}
+
+// Get implements BlobHandler.
+func (p *proxyBlobHandler) Get(ctx context.Context, repo string, h v1.Hash) (io.ReadCloser, error) {
+	rc, err := p.inner.Get(ctx, repo, h)
+	if !errors.Is(err, errNotFound) && !errors.Is(err, fs.ErrNotExist) {
+		return rc, err
+	}
+	if err := p.pull(ctx, repo, h); errors.Is(err, errUnsupported) {
+		return p.fetch(ctx, repo, h)
+	} else if err != nil {
+		return nil, err
+	}
+	return p.inner.Get(ctx, repo, h)
+}
+
+// Stat implements BlobStatHandler.
+func (p *proxyBlobHandler) Stat(ctx context.Context, repo string, h v1.Hash) (int64, error) {
+	if bsh, ok := p.inner.(BlobStatHandler); ok {
+		size, err := bsh.Stat(ctx, repo, h)
+		if !errors.Is(err, errNotFound) {
+			return size, err
+		}
+	} else if rc, err := p.inner.Get(ctx, repo, h); err == nil {
+		defer rc.Close()
+		return io.Copy(io.Discard, rc)
+	}
+
+	rc, err := p.Get(ctx, repo, h)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	return io.Copy(io.Discard, rc)
+}
+
+// Put implements BlobPutHandler.
+func (p *proxyBlobHandler) Put(ctx context.Context, repo string, h v1.Hash, rc io.ReadCloser) error {
+	bph, ok := p.inner.(BlobPutHandler)
+	if !ok {
+		return errUnsupported
+	}
+	return bph.Put(ctx, repo, h, rc)
+}
+
+// Delete implements BlobDeleteHandler.
+func (p *proxyBlobHandler) Delete(ctx context.Context, repo string, h v1.Hash) error {
+	bdh, ok := p.inner.(BlobDeleteHandler)
+	if !ok {
+		return errUnsupported
+	}
+	return bdh.Delete(ctx, repo, h)
+}
+
+// errUnsupported means the inner BlobHandler doesn't support an operation.
+var errUnsupported = errors.New("unsupported by blob handler")
+
+// fetch returns the verified contents of the blob h in repo from upstream.
+func (p *proxyBlobHandler) fetch(ctx context.Context, repo string, h v1.Hash) (io.ReadCloser, error) {
+	rc, err := p.upstream.Blob(ctx, repo, h)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return verify.ReadCloser(rc, verify.SizeUnknown, h)
+}
+
+// pull stores the blob h in repo from upstream in the inner BlobHandler.
+func (p *proxyBlobHandler) pull(ctx context.Context, repo string, h v1.Hash) error {
+	if _, ok := p.inner.(BlobPutHandler); !ok {
+		return errUnsupported
+	}
+	rc, err := p.fetch(ctx, repo, h)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return p.Put(ctx, repo, h, rc)
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+// pullerUpstream implements registry.Upstream with a remote.Puller.
+type pullerUpstream struct {
+	reg    name.Registry
+	puller *remote.Puller
+}
+
+func (p *pullerUpstream) Manifest(ctx context.Context, repo, target string) ([]byte, types.MediaType, error) {
+	var ref name.Reference = p.reg.Repo(repo).Tag(target)
+	if _, err := v1.NewHash(target); err == nil {
+		ref = p.reg.Repo(repo).Digest(target)
+	}
+	desc, err := p.puller.Get(ctx, ref)
+	if err != nil {
+		return nil, "", notExist(err)
+	}
+	return desc.Manifest, desc.MediaType, nil
+}
+
+func (p *pullerUpstream) Blob(ctx context.Context, repo string, h v1.Hash) (io.ReadCloser, error) {
+	l, err := p.puller.Layer(ctx, p.reg.Repo(repo).Digest(h.String()))
+	if err != nil {
+		return nil, notExist(err)
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		return nil, notExist(err)
+	}
+	return rc, nil
+}
+
+func notExist(err error) error {
+	var terr *transport.Error
+	if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+	return err
+}
+
+func TestProxy(t *testing.T) {
+	up := httptest.NewServer(registry.New())
+	defer up.Close()
+
+	img, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upRef, err := name.ParseReference(strings.TrimPrefix(up.URL, "http://") + "/foo/bar:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(upRef, img); err != nil {
+		t.Fatalf("remote.Write: %v", err)
+	}
+
+	puller, err := remote.NewPuller()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &pullerUpstream{reg: upRef.Context().Registry, puller: puller}
+	proxy := httptest.NewServer(registry.New(registry.WithProxy(u)))
+	defer proxy.Close()
+
+	ref, err := name.ParseReference(strings.TrimPrefix(proxy.URL, "http://") + "/foo/bar:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := remote.Image(ref)
+	if err != nil {
+		t.Fatalf("remote.Image: %v", err)
+	}
+	if err := validate.Image(got); err != nil {
+		t.Fatalf("validate.Image: %v", err)
+	}
+
+	// Once upstream is gone, we should still be able to serve what we pulled,
+	// both by tag and by digest.
+	up.Close()
+
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []name.Reference{ref, ref.Context().Digest(d.String())} {
+		got, err := remote.Image(r)
+		if err != nil {
+			t.Fatalf("remote.Image(%s): %v", r, err)
+		}
+		if err := validate.Image(got); err != nil {
+			t.Fatalf("validate.Image(%s): %v", r, err)
+		}
+	}
+
+	// Things upstream never had are still missing.
+	if _, err := remote.Image(ref.Context().Tag("missing")); err == nil {
+		t.Error("remote.Image(missing) succeeded, want error")
+	}
+}
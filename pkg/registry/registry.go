@@ -24,11 +24,16 @@
 package registry
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math/rand"
 	"net/http"
 	"os"
+	"path/filepath"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 )
 
 type registry struct {
@@ -37,6 +42,12 @@ type registry struct {
 	manifests        manifests
 	referrersEnabled bool
 	warnings         map[float64]string
+	notifiers        []func(Event)
+	gc               *GCPolicy
+	faults           *faults
+	metrics          *metrics
+	readOnly         bool
+	quota            int64
 }
 
 // https://docs.docker.com/registry/spec/api/#api-version-check
@@ -51,6 +62,15 @@ func (r *registry) v2(resp http.ResponseWriter, req *http.Request) *regError {
 		}
 	}
 
+	if r.readOnly && isWrite(req) {
+		return regErrReadOnly
+	}
+	if r.gc != nil && req.URL.Path == GCPath {
+		return r.handleGC(resp, req)
+	}
+	if r.metrics != nil && req.URL.Path == MetricsPath {
+		return r.handleMetrics(resp, req)
+	}
 	if isBlob(req) {
 		return r.blobs.handle(resp, req)
 	}
@@ -79,12 +99,34 @@ func (r *registry) v2(resp http.ResponseWriter, req *http.Request) *regError {
 }
 
 func (r *registry) root(resp http.ResponseWriter, req *http.Request) {
-	if rerr := r.v2(resp, req); rerr != nil {
+	rec := &statusRecorder{ResponseWriter: resp}
+	if r.metrics != nil {
+		body := &countingReader{ReadCloser: req.Body}
+		req.Body = body
+		defer r.metrics.observe(rec, req, body, time.Now())
+	}
+	var w http.ResponseWriter = rec
+	var rerr *regError
+	if r.faults != nil {
+		w, rerr = r.faults.inject(w, req)
+	}
+	if rerr == nil {
+		rerr = r.v2(w, req)
+	}
+	if rerr != nil {
 		r.log.Printf("%s %s %d %s %s", req.Method, req.URL, rerr.Status, rerr.Code, rerr.Message)
-		rerr.Write(resp)
+		rerr.Write(rec)
 		return
 	}
 	r.log.Printf("%s %s", req.Method, req.URL)
+	if len(r.notifiers) == 0 {
+		return
+	}
+	if e, ok := r.event(rec, req); ok {
+		for _, n := range r.notifiers {
+			n(e)
+		}
+	}
 }
 
 // New returns a handler which implements the docker registry protocol.
@@ -105,6 +147,29 @@ func New(opts ...Option) http.Handler {
 	for _, o := range opts {
 		o(r)
 	}
+	if r.manifests.upstream != nil {
+		r.blobs.blobHandler = &proxyBlobHandler{
+			inner:    r.blobs.blobHandler,
+			upstream: r.manifests.upstream,
+		}
+	}
+	if r.quota > 0 {
+		if bs, ok := r.blobs.blobHandler.(BlobStore); ok {
+			r.blobs.blobHandler = &quotaBlobHandler{
+				inner:     bs,
+				max:       r.quota,
+				manifests: &r.manifests,
+				accessed:  map[v1.Hash]time.Time{},
+			}
+		} else {
+			r.log.Printf("not enforcing quota: %T does not implement BlobStore", r.blobs.blobHandler)
+		}
+	}
+	if r.manifests.store != nil {
+		if err := r.manifests.load(context.Background()); err != nil {
+			r.log.Printf("failed to load manifests: %v", err)
+		}
+	}
 	return http.HandlerFunc(r.root)
 }
 
@@ -125,6 +190,7 @@ func Logger(l *log.Logger) Option {
 func WithReferrersSupport(enabled bool) Option {
 	return func(r *registry) {
 		r.referrersEnabled = enabled
+		r.manifests.referrersEnabled = enabled
 	}
 }
 
@@ -142,3 +208,32 @@ func WithBlobHandler(h BlobHandler) Option {
 		r.blobs.blobHandler = h
 	}
 }
+
+// WithDiskStorage persists blobs and manifests under dir, so that a registry
+// created with the same dir serves everything that was pushed to a previous
+// one, e.g. across restarts of the registry binary.
+//
+// The layout of dir resembles an OCI image layout: contents are stored under
+// dir/blobs/<algorithm>/<hex>, and the manifests and tags of each repository
+// are recorded in dir/repositories/<repo>/index.json.
+func WithDiskStorage(dir string) Option {
+	return func(r *registry) {
+		if err := os.MkdirAll(filepath.Join(dir, blobsDir), os.ModePerm); err != nil {
+			r.log.Printf("failed to create %s: %v", dir, err)
+		}
+		r.blobs.blobHandler = NewDiskBlobHandler(filepath.Join(dir, blobsDir))
+		r.manifests.store = NewDiskManifestStore(dir)
+	}
+}
+
+// WithProxy makes the registry a pull-through cache of u.
+//
+// Manifests and blobs that aren't found locally are fetched from u,
+// stored, and served, so the registry acts as a minimal mirror. Tags are
+// checked against u on every request, falling back to the local copy if u is
+// unavailable.
+func WithProxy(u Upstream) Option {
+	return func(r *registry) {
+		r.manifests.upstream = u
+	}
+}
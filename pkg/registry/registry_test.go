@@ -191,6 +191,12 @@ func TestCalls(t *testing.T) {
 			URL:         "/v2/foo/blobs/sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
 			Code:        http.StatusAccepted,
 		},
+		{
+			Description: "DELETE missing blob",
+			Method:      "DELETE",
+			URL:         "/v2/foo/blobs/sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+			Code:        http.StatusNotFound,
+		},
 		{
 			Description: "blob url with no container",
 			Method:      "GET",
@@ -251,6 +257,7 @@ func TestCalls(t *testing.T) {
 			Description: "stream upload",
 			Method:      "PATCH",
 			URL:         "/v2/foo/blobs/uploads/1",
+			BlobStream:  map[string]string{"1": ""},
 			Code:        http.StatusNoContent,
 			Body:        "foo",
 			Header: map[string]string{
@@ -274,6 +281,37 @@ func TestCalls(t *testing.T) {
 			Code:        http.StatusCreated,
 			Header:      map[string]string{"Docker-Content-Digest": "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"},
 		},
+		{
+			Description: "upload status",
+			Method:      "GET",
+			URL:         "/v2/foo/blobs/uploads/1",
+			BlobStream:  map[string]string{"1": "foo"},
+			Code:        http.StatusNoContent,
+			Header: map[string]string{
+				"Range":              "0-2",
+				"Location":           "/v2/foo/blobs/uploads/1",
+				"Docker-Upload-UUID": "1",
+			},
+		},
+		{
+			Description: "upload status unknown",
+			Method:      "GET",
+			URL:         "/v2/foo/blobs/uploads/1",
+			Code:        http.StatusNotFound,
+		},
+		{
+			Description: "cancel upload",
+			Method:      "DELETE",
+			URL:         "/v2/foo/blobs/uploads/1",
+			BlobStream:  map[string]string{"1": "foo"},
+			Code:        http.StatusNoContent,
+		},
+		{
+			Description: "cancel upload unknown",
+			Method:      "DELETE",
+			URL:         "/v2/foo/blobs/uploads/1",
+			Code:        http.StatusNotFound,
+		},
 		{
 			Description: "get missing manifest",
 			Method:      "GET",
@@ -371,7 +409,8 @@ func TestCalls(t *testing.T) {
 			Description:   "Chunk upload start",
 			Method:        "PATCH",
 			URL:           "/v2/foo/blobs/uploads/1",
-			RequestHeader: map[string]string{"Content-Range": "0-3"},
+			BlobStream:    map[string]string{"1": ""},
+			RequestHeader: map[string]string{"Content-Range": "0-2"},
 			Code:          http.StatusNoContent,
 			Body:          "foo",
 			Header: map[string]string{
@@ -383,6 +422,7 @@ func TestCalls(t *testing.T) {
 			Description:   "Chunk upload bad content range",
 			Method:        "PATCH",
 			URL:           "/v2/foo/blobs/uploads/1",
+			BlobStream:    map[string]string{"1": ""},
 			RequestHeader: map[string]string{"Content-Range": "0-bar"},
 			Code:          http.StatusRequestedRangeNotSatisfiable,
 			Body:          "foo",
@@ -397,11 +437,35 @@ func TestCalls(t *testing.T) {
 			Body:          "bar",
 		},
 		{
-			Description:   "Chunk upload after previous data",
+			Description:   "Chunk upload length doesn't match range",
 			Method:        "PATCH",
 			URL:           "/v2/foo/blobs/uploads/1",
 			BlobStream:    map[string]string{"1": "foo"},
 			RequestHeader: map[string]string{"Content-Range": "3-6"},
+			Code:          http.StatusRequestedRangeNotSatisfiable,
+			Body:          "bar",
+		},
+		{
+			Description:   "Chunk upload to unknown session",
+			Method:        "PATCH",
+			URL:           "/v2/foo/blobs/uploads/1",
+			RequestHeader: map[string]string{"Content-Range": "0-2"},
+			Code:          http.StatusNotFound,
+			Body:          "foo",
+		},
+		{
+			Description: "stream upload to unknown session",
+			Method:      "PATCH",
+			URL:         "/v2/foo/blobs/uploads/1",
+			Code:        http.StatusNotFound,
+			Body:        "foo",
+		},
+		{
+			Description:   "Chunk upload after previous data",
+			Method:        "PATCH",
+			URL:           "/v2/foo/blobs/uploads/1",
+			BlobStream:    map[string]string{"1": "foo"},
+			RequestHeader: map[string]string{"Content-Range": "3-5"},
 			Code:          http.StatusNoContent,
 			Body:          "bar",
 			Header: map[string]string{
@@ -511,6 +575,44 @@ func TestCalls(t *testing.T) {
 				"Content-Type": "application/vnd.oci.image.index.v1+json",
 			},
 		},
+		{
+			Description: "fetch references, filtered by artifactType",
+			Method:      "GET",
+			URL:         "/v2/foo/referrers/sha256:" + sha256String("foo") + "?artifactType=application/vnd.example.sbom",
+			Code:        http.StatusOK,
+			Manifests: map[string]string{
+				"foo/manifests/image": "foo",
+				"foo/manifests/sbom":  "{\"artifactType\": \"application/vnd.example.sbom\", \"subject\": {\"digest\": \"sha256:" + sha256String("foo") + "\"}}",
+				"foo/manifests/sig":   "{\"config\": {\"mediaType\": \"application/vnd.example.sig\"}, \"subject\": {\"digest\": \"sha256:" + sha256String("foo") + "\"}}",
+			},
+			Header: map[string]string{
+				"Content-Type":        "application/vnd.oci.image.index.v1+json",
+				"OCI-Filters-Applied": "artifactType",
+			},
+			Want: `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[{"mediaType":"","size":` + fmt.Sprint(len("{\"artifactType\": \"application/vnd.example.sbom\", \"subject\": {\"digest\": \"sha256:"+sha256String("foo")+"\"}}")) + `,"digest":"sha256:` + sha256String("{\"artifactType\": \"application/vnd.example.sbom\", \"subject\": {\"digest\": \"sha256:"+sha256String("foo")+"\"}}") + `","artifactType":"application/vnd.example.sbom"}]}`,
+		},
+		{
+			Description: "fetch references, artifactType from config",
+			Method:      "GET",
+			URL:         "/v2/foo/referrers/sha256:" + sha256String("foo") + "?artifactType=application/vnd.example.sig",
+			Code:        http.StatusOK,
+			Manifests: map[string]string{
+				"foo/manifests/image": "foo",
+				"foo/manifests/sbom":  "{\"artifactType\": \"application/vnd.example.sbom\", \"subject\": {\"digest\": \"sha256:" + sha256String("foo") + "\"}}",
+				"foo/manifests/sig":   "{\"config\": {\"mediaType\": \"application/vnd.example.sig\"}, \"subject\": {\"digest\": \"sha256:" + sha256String("foo") + "\"}}",
+			},
+			Want: `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[{"mediaType":"","size":` + fmt.Sprint(len("{\"config\": {\"mediaType\": \"application/vnd.example.sig\"}, \"subject\": {\"digest\": \"sha256:"+sha256String("foo")+"\"}}")) + `,"digest":"sha256:` + sha256String("{\"config\": {\"mediaType\": \"application/vnd.example.sig\"}, \"subject\": {\"digest\": \"sha256:"+sha256String("foo")+"\"}}") + `","artifactType":"application/vnd.example.sig"}]}`,
+		},
+		{
+			Description: "push manifest with subject",
+			Method:      "PUT",
+			URL:         "/v2/foo/manifests/sig",
+			Body:        "{\"config\": {\"mediaType\": \"application/vnd.example.sig\"}, \"subject\": {\"digest\": \"sha256:" + sha256String("foo") + "\"}}",
+			Code:        http.StatusCreated,
+			Header: map[string]string{
+				"OCI-Subject": "sha256:" + sha256String("foo"),
+			},
+		},
 		{
 			Description: "fetch references, missing repo",
 			Method:      "GET",
@@ -587,10 +689,30 @@ func TestCalls(t *testing.T) {
 				}
 			}
 
+			// Upload sessions get random IDs, so start one for each that the
+			// test case streams to, and substitute its ID for the name used
+			// in the test case.
+			uploads := map[string]string{}
+			subst := func(v string) string {
+				for upload, id := range uploads {
+					if v == upload {
+						return id
+					}
+					v = strings.Replace(v, "/blobs/uploads/"+upload, "/blobs/uploads/"+id, 1)
+				}
+				return v
+			}
 			for upload, contents := range tc.BlobStream {
-				u, err := url.Parse(fmt.Sprintf("%s/v2/foo/blobs/uploads/%s", s.URL, upload))
+				resp, err := s.Client().Post(s.URL+"/v2/foo/blobs/uploads/", "", nil)
 				if err != nil {
-					t.Fatalf("Error parsing %q: %v", s.URL+tc.URL, err)
+					t.Fatalf("Error starting upload: %v", err)
+				}
+				resp.Body.Close()
+				uploads[upload] = resp.Header.Get("Docker-Upload-UUID")
+
+				u, err := url.Parse(s.URL + resp.Header.Get("Location"))
+				if err != nil {
+					t.Fatalf("Error parsing %q: %v", resp.Header.Get("Location"), err)
 				}
 				req := &http.Request{
 					Method: "PATCH",
@@ -598,7 +720,7 @@ func TestCalls(t *testing.T) {
 					Body:   io.NopCloser(strings.NewReader(contents)),
 				}
 				t.Log(req.Method, req.URL)
-				resp, err := s.Client().Do(req)
+				resp, err = s.Client().Do(req)
 				if err != nil {
 					t.Fatalf("Error streaming blob: %v", err)
 				}
@@ -609,7 +731,7 @@ func TestCalls(t *testing.T) {
 
 			}
 
-			u, err := url.Parse(s.URL + tc.URL)
+			u, err := url.Parse(s.URL + subst(tc.URL))
 			if err != nil {
 				t.Fatalf("Error parsing %q: %v", s.URL+tc.URL, err)
 			}
@@ -637,6 +759,7 @@ func TestCalls(t *testing.T) {
 			}
 
 			for k, v := range tc.Header {
+				v = subst(v)
 				r := resp.Header.Get(k)
 				if r != v {
 					t.Errorf("Incorrect header %q received, got %q, want %q", k, r, v)
@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+)
+
+// BlobStore is a blob storage backend that supports every operation the
+// registry uses, e.g. one backed by S3, GCS or minio.
+//
+// The registry only requires a BlobHandler, see WithBlobHandler, but it can
+// only support pushes, deletes and garbage collection if the BlobHandler
+// implements the corresponding extension interfaces.
+type BlobStore interface {
+	BlobHandler
+	BlobStatHandler
+	BlobPutHandler
+	BlobDeleteHandler
+	BlobListHandler
+}
+
+// Manifest is the contents and media type of a stored manifest.
+type Manifest struct {
+	ContentType string
+	Blob        []byte
+}
+
+// ManifestStore is a manifest storage backend, e.g. one backed by S3, GCS or
+// minio.
+//
+// The registry serves manifests from memory. It loads every repository from
+// the ManifestStore when it is created, and saves a repository every time
+// its manifests or tags change, so the ManifestStore only needs to handle
+// whole repositories.
+type ManifestStore interface {
+	// Repositories returns the names of all stored repositories.
+	Repositories(ctx context.Context) ([]string, error)
+
+	// Load returns the manifests in repo, keyed by tag or digest.
+	Load(ctx context.Context, repo string) (map[string]Manifest, error)
+
+	// Save replaces the manifests in repo, keyed by tag or digest. Saving no
+	// manifests deletes repo.
+	Save(ctx context.Context, repo string, c map[string]Manifest) error
+}
+
+// WithManifestStore persists manifests to s, see ManifestStore.
+func WithManifestStore(s ManifestStore) Option {
+	return func(r *registry) {
+		r.manifests.store = s
+	}
+}
+
+// load reads all the repositories in m.store into memory.
+func (m *manifests) load(ctx context.Context) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	repos, err := m.store.Repositories(ctx)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		stored, err := m.store.Load(ctx, repo)
+		if err != nil {
+			return err
+		}
+		c := make(map[string]manifest, len(stored))
+		for key, mf := range stored {
+			c[key] = manifest{
+				contentType: mf.ContentType,
+				blob:        mf.Blob,
+			}
+		}
+		m.manifests[repo] = c
+	}
+	return nil
+}
+
+// save persists the manifests in repo to m.store, if set.
+// The caller must hold m.lock.
+func (m *manifests) save(repo string) error {
+	if m.store == nil {
+		return nil
+	}
+	c := make(map[string]Manifest, len(m.manifests[repo]))
+	for key, mf := range m.manifests[repo] {
+		c[key] = Manifest{
+			ContentType: mf.contentType,
+			Blob:        mf.blob,
+		}
+	}
+	return m.store.Save(context.Background(), repo, c)
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+// mapStore is a ManifestStore that stands in for a remote backend.
+type mapStore struct {
+	sync.Mutex
+	repos map[string]map[string]registry.Manifest
+}
+
+func (s *mapStore) Repositories(_ context.Context) ([]string, error) {
+	s.Lock()
+	defer s.Unlock()
+	var repos []string
+	for repo := range s.repos {
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+func (s *mapStore) Load(_ context.Context, repo string) (map[string]registry.Manifest, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.repos[repo], nil
+}
+
+func (s *mapStore) Save(_ context.Context, repo string, c map[string]registry.Manifest) error {
+	s.Lock()
+	defer s.Unlock()
+	if len(c) == 0 {
+		delete(s.repos, repo)
+	} else {
+		s.repos[repo] = c
+	}
+	return nil
+}
+
+func TestStores(t *testing.T) {
+	for _, bh := range []registry.BlobHandler{
+		registry.NewInMemoryBlobHandler(),
+		registry.NewDiskBlobHandler(t.TempDir()),
+	} {
+		if _, ok := bh.(registry.BlobStore); !ok {
+			t.Errorf("%T does not implement BlobStore", bh)
+		}
+	}
+
+	blobs := registry.NewInMemoryBlobHandler()
+	ms := &mapStore{repos: map[string]map[string]registry.Manifest{}}
+	s := httptest.NewServer(registry.New(registry.WithBlobHandler(blobs), registry.WithManifestStore(ms)))
+	ref, err := name.ParseReference(strings.TrimPrefix(s.URL, "http://") + "/foo/bar:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	if got := len(ms.repos["foo/bar"]); got != 2 {
+		t.Errorf("stored %d manifests, want a digest and a tag", got)
+	}
+
+	// A new registry backed by the same stores serves the same image.
+	s = httptest.NewServer(registry.New(registry.WithBlobHandler(blobs), registry.WithManifestStore(ms)))
+	defer s.Close()
+	ref, err = name.ParseReference(strings.TrimPrefix(s.URL, "http://") + "/foo/bar:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := remote.Image(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Image(got); err != nil {
+		t.Errorf("validate.Image: %v", err)
+	}
+}
+
+func TestDiskManifestStoreNested(t *testing.T) {
+	ctx := context.Background()
+	store := registry.NewDiskManifestStore(t.TempDir())
+	c := map[string]registry.Manifest{
+		"latest": {ContentType: "application/vnd.oci.image.manifest.v1+json", Blob: []byte("{}")},
+	}
+	for _, repo := range []string{"a", "a/b"} {
+		if err := store.Save(ctx, repo, c); err != nil {
+			t.Fatalf("Save(%q): %v", repo, err)
+		}
+	}
+
+	// Emptying a repository leaves the ones nested under it alone.
+	if err := store.Save(ctx, "a", nil); err != nil {
+		t.Fatalf("Save(a, nil): %v", err)
+	}
+	if _, err := store.Load(ctx, "a/b"); err != nil {
+		t.Errorf("Load(a/b): %v", err)
+	}
+	repos, err := store.Repositories(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a/b"}; !slices.Equal(repos, want) {
+		t.Errorf("Repositories() = %v, want %v", repos, want)
+	}
+
+	if err := store.Save(ctx, "a/b", nil); err != nil {
+		t.Fatalf("Save(a/b, nil): %v", err)
+	}
+	if repos, err := store.Repositories(ctx); err != nil {
+		t.Fatal(err)
+	} else if len(repos) != 0 {
+		t.Errorf("Repositories() = %v, want none", repos)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+)
+
+func TestResumableUpload(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+
+	do := func(method, path, contentRange, body string, want int) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, s.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contentRange != "" {
+			req.Header.Set("Content-Range", contentRange)
+		}
+		resp, err := s.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != want {
+			b, _ := io.ReadAll(resp.Body)
+			t.Fatalf("%s %s: got %d, want %d: %s", method, path, resp.StatusCode, want, b)
+		}
+		return resp
+	}
+	wantRange := func(resp *http.Response, want string) {
+		t.Helper()
+		if got := resp.Header.Get("Range"); got != want {
+			t.Errorf("Range: got %q, want %q", got, want)
+		}
+	}
+
+	loc := do(http.MethodPost, "/v2/foo/blobs/uploads/", "", "", http.StatusAccepted).Header.Get("Location")
+
+	// A fresh session has nothing.
+	wantRange(do(http.MethodGet, loc, "", "", http.StatusNoContent), "0-0")
+
+	wantRange(do(http.MethodPatch, loc, "0-2", "foo", http.StatusNoContent), "0-2")
+	wantRange(do(http.MethodGet, loc, "", "", http.StatusNoContent), "0-2")
+
+	// Chunks that skip ahead or overlap are rejected, and don't change what
+	// we have.
+	do(http.MethodPatch, loc, "4-6", "bar", http.StatusRequestedRangeNotSatisfiable)
+	do(http.MethodPatch, loc, "1-3", "bar", http.StatusRequestedRangeNotSatisfiable)
+	// So are chunks whose length doesn't match their range, which is
+	// inclusive.
+	do(http.MethodPatch, loc, "3-6", "bar", http.StatusRequestedRangeNotSatisfiable)
+	wantRange(do(http.MethodGet, loc, "", "", http.StatusNoContent), "0-2")
+
+	// Resume from where the status says we are.
+	wantRange(do(http.MethodPatch, loc, "3-5", "bar", http.StatusNoContent), "0-5")
+
+	// sha256("foobar")
+	digest := "sha256:c3ab8ff13720e8ad9047dd39466b3c8974e592c2fa383d4a3960714caef0c4f2"
+	do(http.MethodPut, loc+"?digest="+digest, "", "", http.StatusCreated)
+	do(http.MethodGet, loc, "", "", http.StatusNotFound)
+
+	resp := do(http.MethodGet, "/v2/foo/blobs/"+digest, "", "", http.StatusOK)
+	if got := resp.Header.Get("Docker-Content-Digest"); got != digest {
+		t.Errorf("Docker-Content-Digest: got %q, want %q", got, digest)
+	}
+
+	// Sessions we didn't start can't be written to.
+	do(http.MethodPatch, "/v2/foo/blobs/uploads/unknown", "", "foo", http.StatusNotFound)
+	do(http.MethodPatch, "/v2/foo/blobs/uploads/unknown", "0-2", "foo", http.StatusNotFound)
+
+	// Canceled sessions are gone.
+	loc = do(http.MethodPost, "/v2/foo/blobs/uploads/", "", "", http.StatusAccepted).Header.Get("Location")
+	do(http.MethodDelete, loc, "", "", http.StatusNoContent)
+	do(http.MethodGet, loc, "", "", http.StatusNotFound)
+}
+
+// TestRetryInterruptedChunk checks that a chunk whose body is cut off isn't
+// kept, so that retrying it doesn't duplicate what got through.
+func TestRetryInterruptedChunk(t *testing.T) {
+	reg := registry.New()
+	serve := func(method, target, contentRange string, body io.Reader, want int) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, target, body)
+		// Don't tell the registry how much to expect, as with a chunked
+		// request.
+		req.ContentLength = -1
+		if contentRange != "" {
+			req.Header.Set("Content-Range", contentRange)
+		}
+		rec := httptest.NewRecorder()
+		reg.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Fatalf("%s %s: got %d, want %d: %s", method, target, rec.Code, want, rec.Body)
+		}
+		return rec
+	}
+
+	for _, contentRange := range []string{"", "0-2"} {
+		loc := serve(http.MethodPost, "/v2/foo/blobs/uploads/", "", nil, http.StatusAccepted).Header().Get("Location")
+
+		interrupted := io.MultiReader(strings.NewReader("fo"), iotest.ErrReader(io.ErrUnexpectedEOF))
+		serve(http.MethodPatch, loc, contentRange, interrupted, http.StatusBadRequest)
+		if got, want := serve(http.MethodGet, loc, "", nil, http.StatusNoContent).Header().Get("Range"), "0-0"; got != want {
+			t.Errorf("Range after interrupted chunk: got %q, want %q", got, want)
+		}
+
+		serve(http.MethodPatch, loc, contentRange, strings.NewReader("foo"), http.StatusNoContent)
+		// sha256("foo")
+		serve(http.MethodPut, loc+"?digest=sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae", "", nil, http.StatusCreated)
+	}
+}
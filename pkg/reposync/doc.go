@@ -0,0 +1,22 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reposync replicates tags from source repositories to destination
+// repositories.
+//
+// Sync works in two steps: NewPlan lists the source and destination tags and
+// decides, according to a Policy, what to copy, overwrite or delete; Execute
+// carries out a Plan with bounded concurrency and retries, reporting progress
+// as it goes. Callers that don't need to inspect the Plan can call Sync.
+package reposync
@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reposync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/google/go-containerregistry/internal/retry"
+	"github.com/google/go-containerregistry/pkg/logs"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"golang.org/x/sync/errgroup"
+)
+
+// Update is sent on the WithProgress channel as each action finishes.
+type Update struct {
+	Action Action
+	// Err is the error the action failed with, if any.
+	Err error
+
+	// Complete is the number of actions finished so far, out of Total.
+	Complete int
+	Total    int
+}
+
+// Result is the outcome of an action.
+type Result struct {
+	Action Action
+	Err    error
+}
+
+// Report is the outcome of executing a Plan, with a Result per action in the
+// order of the Plan.
+type Report struct {
+	Results []Result
+}
+
+// Failed returns the results of the actions that failed.
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Execute carries out the actions in p. It keeps going when an action fails;
+// the returned error joins every failure, and the Report has the outcome of
+// each action.
+func Execute(ctx context.Context, p *Plan, opts ...Option) (*Report, error) {
+	o := makeOptions(opts...)
+	if o.updates != nil {
+		defer close(o.updates)
+	}
+
+	puller, err := remote.NewPuller(o.remote...)
+	if err != nil {
+		return nil, err
+	}
+	pusher, err := remote.NewPusher(o.remote...)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{Results: make([]Result, len(p.Actions))}
+	var complete atomic.Int64
+
+	var g errgroup.Group
+	g.SetLimit(o.jobs)
+	for i, a := range p.Actions {
+		g.Go(func() error {
+			err := backoffErrors(o.backoff, func() error {
+				return execute(ctx, puller, pusher, a)
+			})
+			if err != nil {
+				err = fmt.Errorf("%s: %w", a, err)
+			}
+			r.Results[i] = Result{Action: a, Err: err}
+			n := complete.Add(1)
+			if o.updates != nil {
+				select {
+				case o.updates <- Update{Action: a, Err: err, Complete: int(n), Total: len(p.Actions)}:
+				case <-ctx.Done():
+				}
+			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	var errs []error
+	for _, res := range r.Failed() {
+		errs = append(errs, res.Err)
+	}
+	return r, errors.Join(errs...)
+}
+
+// Sync plans and executes the replication of pairs.
+func Sync(ctx context.Context, pairs []Pair, opts ...Option) (*Report, error) {
+	p, err := NewPlan(ctx, pairs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return Execute(ctx, p, opts...)
+}
+
+func execute(ctx context.Context, puller *remote.Puller, pusher *remote.Pusher, a Action) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	switch a.Op {
+	case Copy, Overwrite:
+		if a.Digest == (v1.Hash{}) {
+			return errors.New("no digest to copy")
+		}
+		src := a.Src.Context().Digest(a.Digest.String())
+		logs.Progress.Printf("Copying %s to %s", src, a.Dst)
+		desc, err := puller.Get(ctx, src)
+		if err != nil {
+			return err
+		}
+		return pusher.Push(ctx, a.Dst, desc)
+	case Delete:
+		logs.Progress.Printf("Deleting %s", a.Dst)
+		return pusher.Delete(ctx, a.Dst)
+	default:
+		return fmt.Errorf("unknown op %q", a.Op)
+	}
+}
+
+// backoffErrors retries f on temporary errors, 429s and 5xxs.
+func backoffErrors(bo retry.Backoff, f func() error) error {
+	p := func(err error) bool {
+		if err == nil {
+			return false
+		}
+		if retry.IsTemporary(err) {
+			return true
+		}
+		var terr *transport.Error
+		if errors.As(err, &terr) {
+			return terr.StatusCode == http.StatusTooManyRequests || terr.StatusCode >= 500
+		}
+		return false
+	}
+	return retry.Retry(f, p, bo)
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reposync
+
+import (
+	"regexp"
+	"runtime"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// Option is a functional option for Plan, Execute and Sync.
+type Option func(*options)
+
+type options struct {
+	remote   []remote.Option
+	policy   Policy
+	selector func(tag string) bool
+	jobs     int
+	backoff  remote.Backoff
+	updates  chan<- Update
+}
+
+func makeOptions(opts ...Option) *options {
+	o := &options{
+		policy: CopyIfMissing,
+		jobs:   runtime.GOMAXPROCS(0),
+		backoff: remote.Backoff{
+			Duration: 1 * time.Second,
+			Factor:   3.0,
+			Jitter:   0.1,
+			Steps:    3,
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithRemoteOptions is a functional option for passing remote.Options, such
+// as credentials or a transport, to every registry operation.
+func WithRemoteOptions(opts ...remote.Option) Option {
+	return func(o *options) {
+		o.remote = append(o.remote, opts...)
+	}
+}
+
+// WithPolicy sets the Policy that decides what is replicated.
+//
+// The default policy is CopyIfMissing.
+func WithPolicy(p Policy) Option {
+	return func(o *options) {
+		o.policy = p
+	}
+}
+
+// WithTagSelector only replicates the tags for which selected returns true.
+// Unselected tags in the destination are never deleted.
+func WithTagSelector(selected func(tag string) bool) Option {
+	return func(o *options) {
+		o.selector = selected
+	}
+}
+
+// WithTagRegexp only replicates the tags matching re; see WithTagSelector.
+func WithTagRegexp(re *regexp.Regexp) Option {
+	return WithTagSelector(re.MatchString)
+}
+
+// WithJobs sets the maximum number of actions executed concurrently.
+//
+// The default number of jobs is GOMAXPROCS.
+func WithJobs(jobs int) Option {
+	return func(o *options) {
+		if jobs > 0 {
+			o.jobs = jobs
+		}
+	}
+}
+
+// WithRetryBackoff sets the backoff for retrying actions that fail with a
+// temporary error, a 429 or a 5xx.
+func WithRetryBackoff(backoff remote.Backoff) Option {
+	return func(o *options) {
+		o.backoff = backoff
+	}
+}
+
+// WithProgress sends an Update on updates as each action finishes. Execute
+// closes updates when it returns.
+func WithProgress(updates chan<- Update) Option {
+	return func(o *options) {
+		o.updates = updates
+	}
+}
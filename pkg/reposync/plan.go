@@ -0,0 +1,197 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reposync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// Pair is a source repository to replicate into a destination repository.
+type Pair struct {
+	Src name.Repository
+	Dst name.Repository
+}
+
+// Policy decides what Plan does with each tag. Policies can be combined with
+// |, e.g. OverwriteOnDigestChange|DeleteExtraneous mirrors the source.
+type Policy uint
+
+const (
+	// CopyIfMissing copies source tags that don't exist in the destination.
+	CopyIfMissing Policy = 1 << iota
+
+	// OverwriteOnDigestChange also copies source tags that exist in the
+	// destination but point at a different digest.
+	OverwriteOnDigestChange
+
+	// DeleteExtraneous deletes destination tags that don't exist in the
+	// source.
+	DeleteExtraneous
+)
+
+// Op is the operation an Action performs.
+type Op string
+
+// The operations a Plan can contain.
+const (
+	Copy      Op = "copy"
+	Overwrite Op = "overwrite"
+	Delete    Op = "delete"
+)
+
+// Action is a single step of a Plan.
+type Action struct {
+	Op Op
+
+	// Src is the reference being copied; it's nil for Delete.
+	Src name.Reference
+	// Dst is the reference to write or delete. Copying to a digest pushes
+	// the image without tagging it.
+	Dst name.Reference
+
+	// Digest is what Src pointed at when planning. Copy and Overwrite copy
+	// Digest from Src's repository, so a tag that moves after planning
+	// doesn't change what's copied.
+	Digest v1.Hash
+}
+
+func (a Action) String() string {
+	if a.Op == Delete {
+		return fmt.Sprintf("%s %s", a.Op, a.Dst)
+	}
+	return fmt.Sprintf("%s %s -> %s", a.Op, a.Src, a.Dst)
+}
+
+// Plan is the list of actions that replicate a set of Pairs.
+type Plan struct {
+	Actions []Action
+}
+
+// NewPlan lists the tags of every pair and returns the actions that bring
+// each destination in line with its source according to the Policy.
+func NewPlan(ctx context.Context, pairs []Pair, opts ...Option) (*Plan, error) {
+	o := makeOptions(opts...)
+	puller, err := remote.NewPuller(o.remote...)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plan{}
+	for _, pair := range pairs {
+		actions, err := o.plan(ctx, puller, pair)
+		if err != nil {
+			return nil, fmt.Errorf("planning %s -> %s: %w", pair.Src, pair.Dst, err)
+		}
+		p.Actions = append(p.Actions, actions...)
+	}
+	return p, nil
+}
+
+func (o *options) plan(ctx context.Context, puller *remote.Puller, pair Pair) ([]Action, error) {
+	srcTags, err := o.list(ctx, puller, pair.Src)
+	if err != nil {
+		return nil, err
+	}
+	dstTags, err := puller.List(ctx, pair.Dst)
+	if err != nil {
+		// Some registries create repository on first push, so listing tags will fail.
+		if !isNotFound(err) {
+			return nil, err
+		}
+		dstTags = nil
+	}
+	dstTags = o.filter(dstTags)
+
+	have := map[string]bool{}
+	for _, tag := range dstTags {
+		have[tag] = true
+	}
+
+	var actions []Action
+	for _, tag := range srcTags {
+		src, dst := pair.Src.Tag(tag), pair.Dst.Tag(tag)
+		if have[tag] && o.policy&OverwriteOnDigestChange == 0 {
+			continue
+		}
+		if !have[tag] && o.policy&(CopyIfMissing|OverwriteOnDigestChange) == 0 {
+			continue
+		}
+		sd, err := puller.Head(ctx, src)
+		if err != nil {
+			return nil, err
+		}
+		if !have[tag] {
+			actions = append(actions, Action{Op: Copy, Src: src, Dst: dst, Digest: sd.Digest})
+			continue
+		}
+		dd, err := puller.Head(ctx, dst)
+		if err != nil {
+			return nil, err
+		}
+		if sd.Digest != dd.Digest {
+			actions = append(actions, Action{Op: Overwrite, Src: src, Dst: dst, Digest: sd.Digest})
+		}
+	}
+
+	if o.policy&DeleteExtraneous != 0 {
+		want := map[string]bool{}
+		for _, tag := range srcTags {
+			want[tag] = true
+		}
+		for _, tag := range dstTags {
+			if !want[tag] {
+				actions = append(actions, Action{Op: Delete, Dst: pair.Dst.Tag(tag)})
+			}
+		}
+	}
+	return actions, nil
+}
+
+// list returns the selected tags in repo, sorted.
+func (o *options) list(ctx context.Context, puller *remote.Puller, repo name.Repository) ([]string, error) {
+	tags, err := puller.List(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return o.filter(tags), nil
+}
+
+func (o *options) filter(tags []string) []string {
+	var selected []string
+	for _, tag := range tags {
+		if o.selector == nil || o.selector(tag) {
+			selected = append(selected, tag)
+		}
+	}
+	sort.Strings(selected)
+	return selected
+}
+
+func isNotFound(err error) bool {
+	var terr *transport.Error
+	if errors.As(err, &terr) {
+		return terr.StatusCode == http.StatusNotFound || terr.StatusCode == http.StatusForbidden
+	}
+	return false
+}
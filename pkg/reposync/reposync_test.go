@@ -0,0 +1,243 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reposync
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// setup returns a src repo tagged a, b and c, and a dst repo where b matches,
+// c differs and d doesn't exist in src.
+func setup(t *testing.T) (src, dst name.Repository, digests map[string]v1.Hash) {
+	t.Helper()
+	s := httptest.NewServer(registry.New())
+	t.Cleanup(s.Close)
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err = name.NewRepository(u.Host + "/src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err = name.NewRepository(u.Host + "/dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digests = map[string]v1.Hash{}
+	write := func(ref name.Tag) v1.Image {
+		img, err := random.Image(256, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := remote.Write(ref, img); err != nil {
+			t.Fatal(err)
+		}
+		return img
+	}
+	for _, tag := range []string{"a", "b", "c"} {
+		d, err := write(src.Tag(tag)).Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		digests[tag] = d
+	}
+	b, err := remote.Image(src.Tag("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(dst.Tag("b"), b); err != nil {
+		t.Fatal(err)
+	}
+	write(dst.Tag("c"))
+	write(dst.Tag("d"))
+	return src, dst, digests
+}
+
+func ops(p *Plan) []string {
+	var s []string
+	for _, a := range p.Actions {
+		s = append(s, fmt.Sprintf("%s %s", a.Op, a.Dst.Identifier()))
+	}
+	return s
+}
+
+func TestPlan(t *testing.T) {
+	src, dst, digests := setup(t)
+	pairs := []Pair{{Src: src, Dst: dst}}
+
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		want []string
+	}{{
+		name: "default",
+		want: []string{"copy a"},
+	}, {
+		name: "overwrite",
+		opts: []Option{WithPolicy(OverwriteOnDigestChange)},
+		want: []string{"copy a", "overwrite c"},
+	}, {
+		name: "mirror",
+		opts: []Option{WithPolicy(OverwriteOnDigestChange | DeleteExtraneous)},
+		want: []string{"copy a", "overwrite c", "delete d"},
+	}, {
+		name: "delete only",
+		opts: []Option{WithPolicy(DeleteExtraneous)},
+		want: []string{"delete d"},
+	}, {
+		name: "selector",
+		opts: []Option{WithPolicy(OverwriteOnDigestChange | DeleteExtraneous), WithTagRegexp(regexp.MustCompile(`^[cd]$`))},
+		want: []string{"overwrite c", "delete d"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewPlan(context.Background(), pairs, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, ops(p)); diff != "" {
+				t.Errorf("NewPlan (-want +got):\n%s", diff)
+			}
+			for _, a := range p.Actions {
+				if a.Op != Delete && a.Digest != digests[a.Src.Identifier()] {
+					t.Errorf("%s: got digest %s, want %s", a, a.Digest, digests[a.Src.Identifier()])
+				}
+			}
+		})
+	}
+}
+
+func TestSync(t *testing.T) {
+	src, dst, digests := setup(t)
+
+	updates := make(chan Update, 10)
+	r, err := Sync(context.Background(), []Pair{{Src: src, Dst: dst}},
+		WithPolicy(OverwriteOnDigestChange|DeleteExtraneous),
+		WithJobs(2),
+		WithProgress(updates))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Results) != 3 || len(r.Failed()) != 0 {
+		t.Errorf("Report: %+v", r)
+	}
+
+	var n int
+	for u := range updates {
+		n++
+		if u.Total != 3 || u.Err != nil {
+			t.Errorf("Update: %+v", u)
+		}
+	}
+	if n != 3 {
+		t.Errorf("got %d updates, want 3", n)
+	}
+
+	tags, err := remote.List(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(tags)
+	if diff := cmp.Diff([]string{"a", "b", "c"}, tags); diff != "" {
+		t.Errorf("dst tags (-want +got):\n%s", diff)
+	}
+	for _, tag := range tags {
+		desc, err := remote.Head(dst.Tag(tag))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if desc.Digest != digests[tag] {
+			t.Errorf("%s: got %s, want %s", tag, desc.Digest, digests[tag])
+		}
+	}
+
+	// Nothing is left to do.
+	p, err := NewPlan(context.Background(), []Pair{{Src: src, Dst: dst}}, WithPolicy(OverwriteOnDigestChange|DeleteExtraneous))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Actions) != 0 {
+		t.Errorf("NewPlan after Sync: %v", ops(p))
+	}
+}
+
+func TestExecuteDigest(t *testing.T) {
+	src, dst, digests := setup(t)
+	p, err := NewPlan(context.Background(), []Pair{{Src: src, Dst: dst}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Move a after planning; what it pointed at when planning is copied.
+	b, err := remote.Image(src.Tag("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(src.Tag("a"), b); err != nil {
+		t.Fatal(err)
+	}
+	// Copying to a digest pushes the image without tagging it.
+	p.Actions = append(p.Actions, Action{Op: Copy, Src: src.Tag("c"), Dst: dst.Digest(digests["c"].String()), Digest: digests["c"]})
+	if _, err := Execute(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+
+	for ref, want := range map[name.Reference]v1.Hash{
+		dst.Tag("a"):                      digests["a"],
+		dst.Digest(digests["c"].String()): digests["c"],
+	} {
+		desc, err := remote.Head(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if desc.Digest != want {
+			t.Errorf("%s: got %s, want %s", ref, desc.Digest, want)
+		}
+	}
+}
+
+func TestExecuteFailure(t *testing.T) {
+	src, dst, digests := setup(t)
+	missing := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("0", 64)}
+	p := &Plan{Actions: []Action{
+		{Op: Copy, Src: src.Tag("missing"), Dst: dst.Tag("missing"), Digest: missing},
+		{Op: Copy, Src: src.Tag("a"), Dst: dst.Tag("a"), Digest: digests["a"]},
+	}}
+	r, err := Execute(context.Background(), p)
+	if err == nil {
+		t.Fatal("Execute: expected error")
+	}
+	if failed := r.Failed(); len(failed) != 1 || failed[0].Action != p.Actions[0] {
+		t.Errorf("Failed: %+v", failed)
+	}
+	if _, err := remote.Head(dst.Tag("a")); err != nil {
+		t.Errorf("a was not copied after an earlier failure: %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package baseimage detects the image that another was built on, by
+// comparing their layers, and records it in the OCI base image annotations
+// that `crane rebase` reads.
+package baseimage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrNoMatch is returned when none of the candidates is a base of the image.
+var ErrNoMatch = errors.New("no candidate is a base of the image")
+
+// Candidate is an image that might be the base of another.
+type Candidate struct {
+	// Ref is what the candidate is recorded as, if it's a tag.
+	Ref   name.Reference
+	Image v1.Image
+}
+
+// Match is the base image of an image.
+type Match struct {
+	Candidate
+
+	// Digest is the digest of the candidate's manifest.
+	Digest v1.Hash
+	// Layers is how many layers the image shares with its base.
+	Layers int
+}
+
+// Detect returns the candidate whose layers are the longest prefix of img's
+// layers: that is, its most specific base. Ties go to the first candidate.
+// Candidates without layers are never a match.
+func Detect(img v1.Image, candidates ...Candidate) (*Match, error) {
+	want, err := digests(img)
+	if err != nil {
+		return nil, err
+	}
+	var best *Match
+	for _, c := range candidates {
+		got, err := digests(c.Image)
+		if err != nil {
+			return nil, fmt.Errorf("candidate %v: %w", c.Ref, err)
+		}
+		if len(got) == 0 || len(got) > len(want) || (best != nil && len(got) <= best.Layers) {
+			continue
+		}
+		if !slices.Equal(got, want[:len(got)]) {
+			continue
+		}
+		d, err := c.Image.Digest()
+		if err != nil {
+			return nil, fmt.Errorf("candidate %v: %w", c.Ref, err)
+		}
+		best = &Match{Candidate: c, Digest: d, Layers: len(got)}
+	}
+	if best == nil {
+		return nil, ErrNoMatch
+	}
+	return best, nil
+}
+
+// Remote is like Detect, but fetches the candidates from a registry. Refs
+// to indexes are resolved to the image for img's platform.
+func Remote(ctx context.Context, img v1.Image, refs []name.Reference, options ...remote.Option) (*Match, error) {
+	cf, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, remote.WithContext(ctx))
+	if p := cf.Platform(); p != nil {
+		options = append(options, remote.WithPlatform(*p))
+	}
+	puller, err := remote.NewPuller(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]Candidate, len(refs))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(4)
+	for i, ref := range refs {
+		g.Go(func() error {
+			desc, err := puller.Get(ctx, ref)
+			if err != nil {
+				return err
+			}
+			base, err := desc.Image()
+			if err != nil {
+				return fmt.Errorf("candidate %v: %w", ref, err)
+			}
+			candidates[i] = Candidate{Ref: ref, Image: base}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return Detect(img, candidates...)
+}
+
+// Annotate returns img with the OCI base image annotations for m. As with
+// `crane append --set-base-image-annotations`, the base's name is only
+// recorded if it's a tag.
+func Annotate(img v1.Image, m *Match) v1.Image {
+	anns := map[string]string{
+		specsv1.AnnotationBaseImageDigest: m.Digest.String(),
+	}
+	if _, ok := m.Ref.(name.Tag); ok {
+		anns[specsv1.AnnotationBaseImageName] = m.Ref.Name()
+	}
+	return mutate.Annotations(img, anns).(v1.Image)
+}
+
+func digests(img v1.Image) ([]v1.Hash, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	ds := make([]v1.Hash, len(layers))
+	for i, l := range layers {
+		if ds[i], err = l.Digest(); err != nil {
+			return nil, err
+		}
+	}
+	return ds, nil
+}
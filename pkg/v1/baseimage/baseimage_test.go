@@ -0,0 +1,135 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baseimage
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func appendRandom(t *testing.T, base v1.Image, n int) v1.Image {
+	t.Helper()
+	top, err := random.Image(100, int64(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := top.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := mutate.AppendLayers(base, layers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestDetect(t *testing.T) {
+	distro := appendRandom(t, empty.Image, 2)
+	runtime := appendRandom(t, distro, 1)
+	app := appendRandom(t, runtime, 2)
+	other := appendRandom(t, empty.Image, 1)
+
+	osRef := name.MustParseReference("example.com/os:latest")
+	runtimeRef := name.MustParseReference("example.com/runtime:1")
+
+	m, err := Detect(app,
+		Candidate{Ref: name.MustParseReference("example.com/other"), Image: other},
+		Candidate{Ref: osRef, Image: distro},
+		Candidate{Ref: runtimeRef, Image: runtime},
+		Candidate{Ref: name.MustParseReference("example.com/empty"), Image: empty.Image},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Ref != runtimeRef || m.Layers != 3 {
+		t.Errorf("Detect: got %v with %d layers, want %v with 3", m.Ref, m.Layers, runtimeRef)
+	}
+	want, err := runtime.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Digest != want {
+		t.Errorf("Digest: got %v, want %v", m.Digest, want)
+	}
+
+	mf, err := Annotate(app, m).Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mf.Annotations[specsv1.AnnotationBaseImageDigest]; got != want.String() {
+		t.Errorf("%s: got %q, want %q", specsv1.AnnotationBaseImageDigest, got, want)
+	}
+	if got := mf.Annotations[specsv1.AnnotationBaseImageName]; got != runtimeRef.Name() {
+		t.Errorf("%s: got %q, want %q", specsv1.AnnotationBaseImageName, got, runtimeRef.Name())
+	}
+
+	if _, err := Detect(app, Candidate{Ref: osRef, Image: other}); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Detect: got %v, want ErrNoMatch", err)
+	}
+}
+
+func TestRemote(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	distro := appendRandom(t, empty.Image, 2)
+	runtime := appendRandom(t, distro, 1)
+	app := appendRandom(t, runtime, 1)
+
+	var refs []name.Reference
+	for tag, img := range map[string]v1.Image{"os": distro, "runtime": runtime} {
+		ref, err := name.ParseReference(u.Host + "/base:" + tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := remote.Write(ref, img); err != nil {
+			t.Fatal(err)
+		}
+		refs = append(refs, ref)
+	}
+
+	m, err := Remote(context.Background(), app, refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.Ref.Identifier(), "runtime"; got != want {
+		t.Errorf("Remote: got %q, want %q", got, want)
+	}
+
+	missing, err := name.ParseReference(u.Host + "/base:missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Remote(context.Background(), app, append(refs, missing)); err == nil {
+		t.Error("Remote: expected an error for a missing candidate")
+	}
+}
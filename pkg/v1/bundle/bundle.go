@@ -0,0 +1,237 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// Entry is an image or index in a bundle.
+type Entry struct {
+	// Ref is the reference it was saved from, or nil if the bundle doesn't
+	// record one.
+	Ref name.Reference
+	v1.Descriptor
+}
+
+// Bundle is a bundle opened for reading. Blobs are read from it as they're
+// needed, so it must stay open while its images are used.
+type Bundle struct {
+	ra     io.ReaderAt
+	closer io.Closer
+
+	blobs    map[v1.Hash]*io.SectionReader
+	entries  []Entry
+	rawIndex []byte
+}
+
+var _ partial.BlobProvider = (*Bundle)(nil)
+
+// Open opens the bundle at path.
+func Open(path string) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	b, err := New(f, fi.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("reading bundle %s: %w", path, err)
+	}
+	b.closer = f
+	return b, nil
+}
+
+// New reads the bundle of the given size in ra. Only the tar headers and
+// index.json are read up front.
+func New(ra io.ReaderAt, size int64) (*Bundle, error) {
+	b := &Bundle{ra: ra, blobs: map[v1.Hash]*io.SectionReader{}}
+	sr := io.NewSectionReader(ra, 0, size)
+	tr := tar.NewReader(sr)
+	var index []byte
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		p := path.Clean(hdr.Name)
+		if p == "index.json" {
+			index, err = io.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		dir, hex := path.Split(p)
+		alg, ok := cutBlobs(dir)
+		if !ok {
+			continue
+		}
+		h, err := v1.NewHash(alg + ":" + hex)
+		if err != nil {
+			continue
+		}
+		// The tar reader has read up to the contents of this file.
+		off, err := sr.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		b.blobs[h] = io.NewSectionReader(ra, off, hdr.Size)
+	}
+	if index == nil {
+		return nil, errors.New("no index.json")
+	}
+	b.rawIndex = index
+	var im v1.IndexManifest
+	if err := json.Unmarshal(index, &im); err != nil {
+		return nil, fmt.Errorf("parsing index.json: %w", err)
+	}
+	for _, desc := range im.Manifests {
+		e := Entry{Descriptor: desc}
+		if s, ok := desc.Annotations[RefAnnotation]; ok {
+			ref, err := name.ParseReference(s)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", RefAnnotation, err)
+			}
+			e.Ref = ref
+		}
+		b.entries = append(b.entries, e)
+	}
+	return b, nil
+}
+
+// cutBlobs returns alg for the directory "blobs/<alg>/".
+func cutBlobs(dir string) (string, bool) {
+	parent, alg := path.Split(path.Clean(dir))
+	return alg, parent == "blobs/" && alg != ""
+}
+
+// Entries returns the images and indexes in b, in the order index.json lists
+// them.
+func (b *Bundle) Entries() []Entry {
+	return b.entries
+}
+
+// Get implements partial.BlobProvider.
+func (b *Bundle) Get(h v1.Hash) (io.ReadCloser, error) {
+	sr, ok := b.blobs[h]
+	if !ok {
+		return nil, fmt.Errorf("blob %s isn't in the bundle", h)
+	}
+	return io.NopCloser(io.NewSectionReader(sr, 0, sr.Size())), nil
+}
+
+// Image returns the image with the manifest digest h.
+func (b *Bundle) Image(h v1.Hash) (v1.Image, error) {
+	return partial.ImageFromBlobs(b, h)
+}
+
+// ImageIndex returns the index with the manifest digest h.
+func (b *Bundle) ImageIndex(h v1.Hash) (v1.ImageIndex, error) {
+	return partial.IndexFromBlobs(b, h)
+}
+
+// Index returns b's index.json as an index, like layout.ImageIndexFromPath,
+// so that any bundle or other OCI image layout archive can be read like a
+// layout on disk.
+func (b *Bundle) Index() (v1.ImageIndex, error) {
+	h, _, err := v1.SHA256(bytes.NewReader(b.rawIndex))
+	if err != nil {
+		return nil, err
+	}
+	return partial.IndexFromBlobs(withIndex{b, h}, h)
+}
+
+// withIndex serves index.json as a blob, alongside b's blobs.
+type withIndex struct {
+	*Bundle
+	h v1.Hash
+}
+
+func (w withIndex) Get(h v1.Hash) (io.ReadCloser, error) {
+	if h == w.h {
+		return io.NopCloser(bytes.NewReader(w.rawIndex)), nil
+	}
+	return w.Bundle.Get(h)
+}
+
+// Taggable returns the image or index e refers to.
+func (b *Bundle) Taggable(e Entry) (remote.Taggable, error) {
+	if e.MediaType.IsIndex() {
+		return b.ImageIndex(e.Digest)
+	}
+	if e.MediaType.IsImage() {
+		return b.Image(e.Digest)
+	}
+	return nil, fmt.Errorf("%s has unexpected media type %s", e.Digest, e.MediaType)
+}
+
+// Close closes the file that Open opened. It's a no-op for bundles from New.
+func (b *Bundle) Close() error {
+	if b.closer == nil {
+		return nil
+	}
+	return b.closer.Close()
+}
+
+// Push pushes every image and index in b, with remote.MultiWrite so that each
+// blob is only uploaded once. Each is pushed to the reference that rename
+// returns for the reference it was saved from, or to that reference itself
+// if rename is nil.
+func Push(b *Bundle, rename func(name.Reference) (name.Reference, error), options ...remote.Option) error {
+	m := map[name.Reference]remote.Taggable{}
+	for _, e := range b.entries {
+		if e.Ref == nil {
+			return fmt.Errorf("%s has no %s annotation to push it to", e.Digest, RefAnnotation)
+		}
+		ref := e.Ref
+		if rename != nil {
+			var err error
+			ref, err = rename(ref)
+			if err != nil {
+				return err
+			}
+		}
+		t, err := b.Taggable(e)
+		if err != nil {
+			return err
+		}
+		m[ref] = t
+	}
+	return remote.MultiWrite(m, options...)
+}
@@ -0,0 +1,251 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+func mustRef(t *testing.T, s string) name.Reference {
+	t.Helper()
+	ref, err := name.ParseReference(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+// images returns two images that share a layer, and an index.
+func images(t *testing.T) map[name.Reference]remote.Taggable {
+	t.Helper()
+	base, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := random.Layer(1024, "application/vnd.oci.image.layer.v1.tar+gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := mutate.AppendLayers(base, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := random.Index(512, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := base.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[name.Reference]remote.Taggable{
+		mustRef(t, "gcr.io/example/base@"+d.String()): base,
+		mustRef(t, "gcr.io/example/app:v1"):           app,
+		mustRef(t, "ghcr.io/example/multi:latest"):    idx,
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	m := images(t)
+	var buf bytes.Buffer
+	if err := Write(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every blob is written once.
+	names := map[string]bool{}
+	tr := tar.NewReader(bytes.NewReader(buf.Bytes()))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names[hdr.Name] {
+			t.Errorf("%s written twice", hdr.Name)
+		}
+		names[hdr.Name] = true
+	}
+
+	b, err := New(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := b.Entries()
+	if len(entries) != len(m) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(m))
+	}
+	for _, e := range entries {
+		want, ok := m[e.Ref]
+		if !ok {
+			t.Errorf("unexpected ref %v", e.Ref)
+			continue
+		}
+		got, err := b.Taggable(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch got := got.(type) {
+		case v1.Image:
+			if err := validate.Image(got); err != nil {
+				t.Errorf("validate.Image(%s): %v", e.Ref, err)
+			}
+		case v1.ImageIndex:
+			if err := validate.Index(got); err != nil {
+				t.Errorf("validate.Index(%s): %v", e.Ref, err)
+			}
+		}
+		wantDigest, err := want.(partial.Describable).Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Digest != wantDigest {
+			t.Errorf("%s: got digest %s, want %s", e.Ref, e.Digest, wantDigest)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	m := images(t)
+	var buf bytes.Buffer
+	if err := Write(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	b, err := New(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := b.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Index(idx); err != nil {
+		t.Errorf("validate.Index(): %v", err)
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(im.Manifests), len(m); got != want {
+		t.Errorf("got %d manifests, want %d", got, want)
+	}
+}
+
+func TestExtractedLayout(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, images(t)); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := filepath.Join(dir, hdr.Name)
+		if hdr.Typeflag == tar.TypeDir {
+			if err := os.MkdirAll(p, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := validate.Layout(dir); err != nil {
+		t.Errorf("validate.Layout: %v", err)
+	}
+}
+
+func TestPush(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := images(t)
+	path := filepath.Join(t.TempDir(), "images.bundle")
+	if err := WriteToFile(path, m); err != nil {
+		t.Fatal(err)
+	}
+	b, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	rename := func(ref name.Reference) (name.Reference, error) {
+		return name.ChangeRegistry(ref, u.Host)
+	}
+	if err := Push(b, rename); err != nil {
+		t.Fatal(err)
+	}
+	for ref := range m {
+		dst, err := rename(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc, err := remote.Head(dst)
+		if err != nil {
+			t.Fatalf("Head(%s): %v", dst, err)
+		}
+		want, err := m[ref].(partial.Describable).Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if desc.Digest != want {
+			t.Errorf("%s: got digest %s, want %s", dst, desc.Digest, want)
+		}
+	}
+}
+
+func TestWriteUnbundleable(t *testing.T) {
+	m := map[name.Reference]remote.Taggable{
+		mustRef(t, "gcr.io/example/app:v1"): &remote.Descriptor{},
+	}
+	if err := Write(io.Discard, m); err == nil {
+		t.Error("Write: expected error for a remote.Descriptor")
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bundle reads and writes bundles: single files that hold many
+// images and indexes, and the references they were saved from, so that
+// they can be carried to an air-gapped network and pushed there in bulk.
+//
+// A bundle is an OCI image layout in a tar archive, so extracting it with tar
+// gives a layout that pkg/v1/layout can read. Each blob is stored once, no
+// matter how many images share it, and index.json lists a descriptor for
+// each reference, annotated with that reference as containerd's "ctr import"
+// expects.
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// RefAnnotation is the annotation on the descriptors in a bundle's
+// index.json that holds the reference each was saved from.
+const RefAnnotation = "io.containerd.image.name"
+
+const layoutFile = `{"imageLayoutVersion": "1.0.0"}`
+
+// Write writes a bundle of the images and indexes in m, which must each be a
+// v1.Image or v1.ImageIndex, to w.
+//
+// The oci-layout file and index.json come first, so that a bundle can be
+// read as it's streamed, followed by every blob, manifests last. Layers that
+// aren't distributable aren't included, as remote.Write doesn't push them
+// by default.
+func Write(w io.Writer, m map[name.Reference]remote.Taggable) error {
+	// Sort the references, so that the same images make the same bundle.
+	refs := make([]name.Reference, 0, len(m))
+	for ref := range m {
+		refs = append(refs, ref)
+	}
+	slices.SortFunc(refs, func(a, b name.Reference) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	index := v1.IndexManifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIImageIndex,
+	}
+	for _, ref := range refs {
+		d, ok := m[ref].(partial.Describable)
+		if !ok {
+			return fmt.Errorf("%s: can't bundle %T, which is neither an image nor an index", ref, m[ref])
+		}
+		desc, err := partial.Descriptor(d)
+		if err != nil {
+			return fmt.Errorf("%s: %w", ref, err)
+		}
+		annotations := map[string]string{RefAnnotation: ref.Name()}
+		if tag, ok := ref.(name.Tag); ok {
+			annotations[specsv1.AnnotationRefName] = tag.TagStr()
+		}
+		index.Manifests = append(index.Manifests, v1.Descriptor{
+			MediaType:    desc.MediaType,
+			Size:         desc.Size,
+			Digest:       desc.Digest,
+			ArtifactType: desc.ArtifactType,
+			Annotations:  annotations,
+		})
+	}
+	b, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	bw := &writer{tw: tar.NewWriter(w), seen: map[v1.Hash]bool{}}
+	if err := bw.file("oci-layout", []byte(layoutFile)); err != nil {
+		return err
+	}
+	if err := bw.file("index.json", b); err != nil {
+		return err
+	}
+	if err := bw.dir("blobs/"); err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if err := bw.write(m[ref]); err != nil {
+			return fmt.Errorf("%s: %w", ref, err)
+		}
+	}
+	return bw.tw.Close()
+}
+
+// WriteToFile writes a bundle of the images and indexes in m to a file at
+// path; see Write.
+func WriteToFile(path string, m map[name.Reference]remote.Taggable) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := Write(f, m); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writer writes the blobs of images and indexes to a tar, each only once.
+type writer struct {
+	tw   *tar.Writer
+	seen map[v1.Hash]bool
+	// dirs are the directories under blobs/ that have been written.
+	dirs []string
+}
+
+func (w *writer) write(t remote.Taggable) error {
+	switch t := t.(type) {
+	case v1.Image:
+		return w.image(t)
+	case v1.ImageIndex:
+		return w.index(t)
+	}
+	return fmt.Errorf("can't bundle %T, which is neither an image nor an index", t)
+}
+
+func (w *writer) index(ii v1.ImageIndex) error {
+	h, err := ii.Digest()
+	if err != nil {
+		return err
+	}
+	if w.seen[h] {
+		return nil
+	}
+	im, err := ii.IndexManifest()
+	if err != nil {
+		return err
+	}
+	for _, desc := range im.Manifests {
+		switch {
+		case desc.MediaType.IsIndex():
+			child, err := ii.ImageIndex(desc.Digest)
+			if err != nil {
+				return err
+			}
+			if err := w.index(child); err != nil {
+				return err
+			}
+		case desc.MediaType.IsImage():
+			child, err := ii.Image(desc.Digest)
+			if err != nil {
+				return err
+			}
+			if err := w.image(child); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("can't bundle %s, which has media type %s", desc.Digest, desc.MediaType)
+		}
+	}
+	return w.manifest(h, ii)
+}
+
+func (w *writer) image(img v1.Image) error {
+	h, err := img.Digest()
+	if err != nil {
+		return err
+	}
+	if w.seen[h] {
+		return nil
+	}
+	ls, err := img.Layers()
+	if err != nil {
+		return err
+	}
+	for _, l := range ls {
+		if err := w.layer(l); err != nil {
+			return err
+		}
+	}
+	m, err := img.Manifest()
+	if err != nil {
+		return err
+	}
+	if !w.seen[m.Config.Digest] {
+		b, err := img.RawConfigFile()
+		if err != nil {
+			return err
+		}
+		if err := w.blob(m.Config.Digest, int64(len(b)), bytes.NewReader(b)); err != nil {
+			return err
+		}
+	}
+	return w.manifest(h, img)
+}
+
+func (w *writer) layer(l v1.Layer) error {
+	mt, err := l.MediaType()
+	if err != nil {
+		return err
+	}
+	if !mt.IsDistributable() {
+		return nil
+	}
+	h, err := l.Digest()
+	if err != nil {
+		return err
+	}
+	if w.seen[h] {
+		return nil
+	}
+	size, err := l.Size()
+	if err != nil {
+		return err
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return w.blob(h, size, rc)
+}
+
+func (w *writer) manifest(h v1.Hash, t remote.Taggable) error {
+	b, err := t.RawManifest()
+	if err != nil {
+		return err
+	}
+	return w.blob(h, int64(len(b)), bytes.NewReader(b))
+}
+
+func (w *writer) blob(h v1.Hash, size int64, r io.Reader) error {
+	dir := path.Join("blobs", h.Algorithm) + "/"
+	if !slices.Contains(w.dirs, dir) {
+		if err := w.dir(dir); err != nil {
+			return err
+		}
+		w.dirs = append(w.dirs, dir)
+	}
+	if err := w.tw.WriteHeader(&tar.Header{
+		Name:     dir + h.Hex,
+		Typeflag: tar.TypeReg,
+		Mode:     0o644,
+		Size:     size,
+	}); err != nil {
+		return err
+	}
+	if _, err := io.Copy(w.tw, r); err != nil {
+		return fmt.Errorf("writing blob %s: %w", h, err)
+	}
+	w.seen[h] = true
+	return nil
+}
+
+func (w *writer) file(name string, b []byte) error {
+	if err := w.tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Typeflag: tar.TypeReg,
+		Mode:     0o644,
+		Size:     int64(len(b)),
+	}); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(b)
+	return err
+}
+
+func (w *writer) dir(name string) error {
+	return w.tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Typeflag: tar.TypeDir,
+		Mode:     0o755,
+	})
+}
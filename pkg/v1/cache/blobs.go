@@ -0,0 +1,270 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/logs"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// BlobCache is implemented by Caches that can also cache small blobs, such as
+// manifests and config files, by digest.
+//
+// When the Cache passed to Image or ImageIndex is a BlobCache, the manifests
+// and config files of the image and its children are served from it, so
+// traversing the same index again doesn't fetch them.
+type BlobCache interface {
+	// PutBlob writes the blob b, whose digest is h, to the cache.
+	PutBlob(h v1.Hash, b []byte) error
+
+	// GetBlob returns the blob with the given digest, or ErrNotFound if no
+	// such blob was found. Callers verify the digest of what's returned.
+	GetBlob(h v1.Hash) ([]byte, error)
+}
+
+// getBlob returns the blob h from bc if it's there and intact, or else
+// fetches it and writes it to bc.
+func getBlob(bc BlobCache, h v1.Hash, fetch func() ([]byte, error)) ([]byte, error) {
+	b, err := bc.GetBlob(h)
+	if err == nil {
+		got, _, err := v1.SHA256(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		if got == h {
+			return b, nil
+		}
+		logs.Warn.Printf("Blob %s in cache has digest %s, refetching", h, got)
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	if b, err = fetch(); err != nil {
+		return nil, err
+	}
+	if err := bc.PutBlob(h, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// blobImage is a v1.Image with a known digest whose manifest and config file
+// are served from a BlobCache. The underlying image is only opened when
+// something else is needed, e.g. its layers.
+type blobImage struct {
+	bc     BlobCache
+	digest v1.Hash
+	open   func() (v1.Image, error)
+
+	manifest func() ([]byte, error)
+}
+
+func newBlobImage(bc BlobCache, h v1.Hash, open func() (v1.Image, error)) *blobImage {
+	i := &blobImage{
+		bc:     bc,
+		digest: h,
+		open:   sync.OnceValues(open),
+	}
+	i.manifest = sync.OnceValues(func() ([]byte, error) {
+		return getBlob(bc, h, func() ([]byte, error) {
+			img, err := i.open()
+			if err != nil {
+				return nil, err
+			}
+			return img.RawManifest()
+		})
+	})
+	return i
+}
+
+func (i *blobImage) RawManifest() ([]byte, error) { return i.manifest() }
+func (i *blobImage) Digest() (v1.Hash, error)     { return i.digest, nil }
+
+func (i *blobImage) Manifest() (*v1.Manifest, error) {
+	return partial.Manifest(i)
+}
+
+func (i *blobImage) Size() (int64, error) {
+	b, err := i.RawManifest()
+	if err != nil {
+		return -1, err
+	}
+	return int64(len(b)), nil
+}
+
+func (i *blobImage) MediaType() (types.MediaType, error) {
+	mt, err := manifestMediaType(i.RawManifest)
+	if err != nil || mt != "" {
+		return mt, err
+	}
+	img, err := i.open()
+	if err != nil {
+		return "", err
+	}
+	return img.MediaType()
+}
+
+func (i *blobImage) ConfigName() (v1.Hash, error) {
+	m, err := i.Manifest()
+	if err != nil {
+		return v1.Hash{}, err
+	}
+	return m.Config.Digest, nil
+}
+
+func (i *blobImage) RawConfigFile() ([]byte, error) {
+	h, err := i.ConfigName()
+	if err != nil {
+		return nil, err
+	}
+	return getBlob(i.bc, h, func() ([]byte, error) {
+		img, err := i.open()
+		if err != nil {
+			return nil, err
+		}
+		return img.RawConfigFile()
+	})
+}
+
+func (i *blobImage) ConfigFile() (*v1.ConfigFile, error) {
+	return partial.ConfigFile(i)
+}
+
+func (i *blobImage) Layers() ([]v1.Layer, error) {
+	img, err := i.open()
+	if err != nil {
+		return nil, err
+	}
+	return img.Layers()
+}
+
+func (i *blobImage) LayerByDigest(h v1.Hash) (v1.Layer, error) {
+	img, err := i.open()
+	if err != nil {
+		return nil, err
+	}
+	return img.LayerByDigest(h)
+}
+
+func (i *blobImage) LayerByDiffID(h v1.Hash) (v1.Layer, error) {
+	img, err := i.open()
+	if err != nil {
+		return nil, err
+	}
+	return img.LayerByDiffID(h)
+}
+
+// blobIndex is the v1.ImageIndex counterpart of blobImage.
+type blobIndex struct {
+	bc     BlobCache
+	c      Cache
+	digest v1.Hash
+	open   func() (v1.ImageIndex, error)
+
+	manifest func() ([]byte, error)
+}
+
+func newBlobIndex(bc BlobCache, c Cache, h v1.Hash, open func() (v1.ImageIndex, error)) *blobIndex {
+	ii := &blobIndex{
+		bc:     bc,
+		c:      c,
+		digest: h,
+		open:   sync.OnceValues(open),
+	}
+	ii.manifest = sync.OnceValues(func() ([]byte, error) {
+		return getBlob(bc, h, func() ([]byte, error) {
+			idx, err := ii.open()
+			if err != nil {
+				return nil, err
+			}
+			return idx.RawManifest()
+		})
+	})
+	return ii
+}
+
+func (ii *blobIndex) RawManifest() ([]byte, error) { return ii.manifest() }
+func (ii *blobIndex) Digest() (v1.Hash, error)     { return ii.digest, nil }
+
+func (ii *blobIndex) IndexManifest() (*v1.IndexManifest, error) {
+	b, err := ii.RawManifest()
+	if err != nil {
+		return nil, err
+	}
+	return v1.ParseIndexManifest(bytes.NewReader(b))
+}
+
+func (ii *blobIndex) Size() (int64, error) {
+	b, err := ii.RawManifest()
+	if err != nil {
+		return -1, err
+	}
+	return int64(len(b)), nil
+}
+
+func (ii *blobIndex) MediaType() (types.MediaType, error) {
+	mt, err := manifestMediaType(ii.RawManifest)
+	if err != nil || mt != "" {
+		return mt, err
+	}
+	idx, err := ii.open()
+	if err != nil {
+		return "", err
+	}
+	return idx.MediaType()
+}
+
+func (ii *blobIndex) Image(h v1.Hash) (v1.Image, error) {
+	return Image(newBlobImage(ii.bc, h, func() (v1.Image, error) {
+		idx, err := ii.open()
+		if err != nil {
+			return nil, err
+		}
+		return idx.Image(h)
+	}), ii.c), nil
+}
+
+func (ii *blobIndex) ImageIndex(h v1.Hash) (v1.ImageIndex, error) {
+	return newBlobIndex(ii.bc, ii.c, h, func() (v1.ImageIndex, error) {
+		idx, err := ii.open()
+		if err != nil {
+			return nil, err
+		}
+		return idx.ImageIndex(h)
+	}), nil
+}
+
+// manifestMediaType returns the mediaType field of the manifest, which may be
+// empty.
+func manifestMediaType(raw func() ([]byte, error)) (types.MediaType, error) {
+	b, err := raw()
+	if err != nil {
+		return "", err
+	}
+	var m struct {
+		MediaType types.MediaType `json:"mediaType"`
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return "", err
+	}
+	return m.MediaType, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"os"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+// countingIndex counts how many children of an index are opened.
+type countingIndex struct {
+	inner  v1.ImageIndex
+	opened *int
+}
+
+func (ii *countingIndex) MediaType() (types.MediaType, error)       { return ii.inner.MediaType() }
+func (ii *countingIndex) Digest() (v1.Hash, error)                  { return ii.inner.Digest() }
+func (ii *countingIndex) Size() (int64, error)                      { return ii.inner.Size() }
+func (ii *countingIndex) IndexManifest() (*v1.IndexManifest, error) { return ii.inner.IndexManifest() }
+func (ii *countingIndex) RawManifest() ([]byte, error)              { return ii.inner.RawManifest() }
+
+func (ii *countingIndex) ImageIndex(h v1.Hash) (v1.ImageIndex, error) {
+	return ii.inner.ImageIndex(h)
+}
+
+func (ii *countingIndex) Image(h v1.Hash) (v1.Image, error) {
+	*ii.opened++
+	return ii.inner.Image(h)
+}
+
+func TestBlobCache(t *testing.T) {
+	dir := t.TempDir()
+	c := NewFilesystemCache(dir)
+
+	idx, err := random.Index(1024, 2, 3)
+	if err != nil {
+		t.Fatalf("random.Index: %v", err)
+	}
+	opened := 0
+	counted := &countingIndex{inner: idx, opened: &opened}
+
+	traverse := func() {
+		t.Helper()
+		ii := ImageIndex(counted, c)
+		im, err := ii.IndexManifest()
+		if err != nil {
+			t.Fatalf("IndexManifest: %v", err)
+		}
+		for _, desc := range im.Manifests {
+			img, err := ii.Image(desc.Digest)
+			if err != nil {
+				t.Fatalf("Image: %v", err)
+			}
+			if _, err := img.ConfigFile(); err != nil {
+				t.Fatalf("ConfigFile: %v", err)
+			}
+			if _, err := img.Manifest(); err != nil {
+				t.Fatalf("Manifest: %v", err)
+			}
+		}
+	}
+
+	traverse()
+	if got, want := opened, 3; got != want {
+		t.Fatalf("first traversal opened %d images, want %d", got, want)
+	}
+	traverse()
+	if got, want := opened, 3; got != want {
+		t.Errorf("second traversal opened %d more images, want 0", got-want)
+	}
+
+	// Corrupt a cached manifest; it's refetched rather than served.
+	im, err := idx.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := im.Manifests[0].Digest
+	if err := os.WriteFile(cachepath(dir, h), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	img, err := ImageIndex(counted, c).Image(h)
+	if err != nil {
+		t.Fatalf("Image: %v", err)
+	}
+	if err := validate.Image(img); err != nil {
+		t.Errorf("validate.Image: %v", err)
+	}
+	if got, want := opened, 4; got != want {
+		t.Errorf("corrupt manifest opened %d more images, want 1", got-3)
+	}
+}
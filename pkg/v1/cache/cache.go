@@ -21,6 +21,7 @@ import (
 
 	"github.com/google/go-containerregistry/pkg/logs"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 )
 
@@ -131,6 +132,25 @@ func (l *lazyLayer) DiffID() (v1.Hash, error)            { return l.inner.DiffID
 func (l *lazyLayer) Digest() (v1.Hash, error)            { return l.inner.Digest() }
 func (l *lazyLayer) MediaType() (types.MediaType, error) { return l.inner.MediaType() }
 
+func (i *image) RawConfigFile() ([]byte, error) {
+	bc, ok := i.c.(BlobCache)
+	if !ok {
+		return i.Image.RawConfigFile()
+	}
+	h, err := i.Image.ConfigName()
+	if err != nil {
+		return nil, err
+	}
+	return getBlob(bc, h, i.Image.RawConfigFile)
+}
+
+func (i *image) ConfigFile() (*v1.ConfigFile, error) {
+	if _, ok := i.c.(BlobCache); !ok {
+		return i.Image.ConfigFile()
+	}
+	return partial.ConfigFile(i)
+}
+
 func (i *image) LayerByDigest(h v1.Hash) (v1.Layer, error) {
 	l, err := i.c.Get(h)
 	if errors.Is(err, ErrNotFound) {
@@ -178,6 +198,11 @@ func (ii *imageIndex) IndexManifest() (*v1.IndexManifest, error) { return ii.inn
 func (ii *imageIndex) RawManifest() ([]byte, error)              { return ii.inner.RawManifest() }
 
 func (ii *imageIndex) Image(h v1.Hash) (v1.Image, error) {
+	if bc, ok := ii.c.(BlobCache); ok {
+		return Image(newBlobImage(bc, h, func() (v1.Image, error) {
+			return ii.inner.Image(h)
+		}), ii.c), nil
+	}
 	i, err := ii.inner.Image(h)
 	if err != nil {
 		return nil, err
@@ -186,6 +211,11 @@ func (ii *imageIndex) Image(h v1.Hash) (v1.Image, error) {
 }
 
 func (ii *imageIndex) ImageIndex(h v1.Hash) (v1.ImageIndex, error) {
+	if bc, ok := ii.c.(BlobCache); ok {
+		return newBlobIndex(bc, ii.c, h, func() (v1.ImageIndex, error) {
+			return ii.inner.ImageIndex(h)
+		}), nil
+	}
 	idx, err := ii.inner.ImageIndex(h)
 	if err != nil {
 		return nil, err
@@ -21,18 +21,57 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 )
 
+// The filesystem cache may be shared by several processes. Entries are written
+// to temporary files and renamed into place once complete, so readers never
+// see a partially-written entry, and evictions are serialized with a lock file.
+const (
+	tempPrefix = ".tmp-"
+	lockName   = ".lock"
+
+	// staleAge is how old a temporary or lock file must be before it's assumed
+	// to have been left behind by a process that died.
+	staleAge = time.Hour
+)
+
 type fscache struct {
-	path string
+	path    string
+	maxSize int64
+
+	// evictMu serializes evictions within this process.
+	evictMu sync.Mutex
+
+	counters counters
+}
+
+// Option is a functional option for NewFilesystemCache.
+type Option func(*fscache)
+
+// WithMaxSize bounds the total size of the files in the cache. When a write
+// would exceed it, the least recently used files are evicted until it doesn't.
+//
+// A file's modification time records when it was last used; Get updates it.
+func WithMaxSize(bytes int64) Option {
+	return func(fs *fscache) {
+		fs.maxSize = bytes
+	}
 }
 
 // NewFilesystemCache returns a Cache implementation backed by files.
-func NewFilesystemCache(path string) Cache {
-	return &fscache{path}
+func NewFilesystemCache(path string, opts ...Option) Cache {
+	fs := &fscache{path: path}
+	for _, o := range opts {
+		o(fs)
+	}
+	return fs
 }
 
 func (fs *fscache) Put(l v1.Layer) (v1.Layer, error) {
@@ -46,6 +85,7 @@ func (fs *fscache) Put(l v1.Layer) (v1.Layer, error) {
 	}
 	return &layer{
 		Layer:  l,
+		fs:     fs,
 		path:   fs.path,
 		digest: digest,
 		diffID: diffID,
@@ -54,15 +94,21 @@ func (fs *fscache) Put(l v1.Layer) (v1.Layer, error) {
 
 type layer struct {
 	v1.Layer
+	fs             *fscache
 	path           string
 	digest, diffID v1.Hash
 }
 
-func (l *layer) create(h v1.Hash) (io.WriteCloser, error) {
+func (l *layer) create(h v1.Hash) (*pending, error) {
 	if err := os.MkdirAll(l.path, 0700); err != nil {
 		return nil, err
 	}
-	return os.Create(cachepath(l.path, h))
+	dst := cachepath(l.path, h)
+	f, err := os.CreateTemp(l.path, tempPrefix+filepath.Base(dst)+"-*")
+	if err != nil {
+		return nil, err
+	}
+	return &pending{File: f, dst: dst}, nil
 }
 
 func (l *layer) Compressed() (io.ReadCloser, error) {
@@ -72,12 +118,10 @@ func (l *layer) Compressed() (io.ReadCloser, error) {
 	}
 	rc, err := l.Layer.Compressed()
 	if err != nil {
+		f.commit(false)
 		return nil, err
 	}
-	return &readcloser{
-		t:      io.TeeReader(rc, f),
-		closes: []func() error{rc.Close, f.Close},
-	}, nil
+	return l.tee(rc, f), nil
 }
 
 func (l *layer) Uncompressed() (io.ReadCloser, error) {
@@ -87,21 +131,59 @@ func (l *layer) Uncompressed() (io.ReadCloser, error) {
 	}
 	rc, err := l.Layer.Uncompressed()
 	if err != nil {
+		f.commit(false)
 		return nil, err
 	}
-	return &readcloser{
-		t:      io.TeeReader(rc, f),
-		closes: []func() error{rc.Close, f.Close},
-	}, nil
+	return l.tee(rc, f), nil
+}
+
+// tee returns a ReadCloser that writes what it reads from rc to f, and
+// commits f to the cache on Close if rc was read to the end.
+func (l *layer) tee(rc io.ReadCloser, f *pending) *readcloser {
+	r := &readcloser{t: io.TeeReader(rc, f)}
+	r.closes = []func() error{
+		rc.Close,
+		func() error { return f.commit(r.eof) },
+		l.fs.evict,
+	}
+	return r
+}
+
+// pending is a cache entry that is being written to a temporary file.
+type pending struct {
+	*os.File
+	dst string
+}
+
+// commit closes the temporary file and, if complete, atomically renames it to
+// its destination. Otherwise, it's removed.
+func (p *pending) commit(complete bool) error {
+	if err := p.File.Close(); err != nil {
+		os.Remove(p.Name())
+		return err
+	}
+	if !complete {
+		return os.Remove(p.Name())
+	}
+	if err := os.Rename(p.Name(), p.dst); err != nil {
+		os.Remove(p.Name())
+		return err
+	}
+	return nil
 }
 
 type readcloser struct {
 	t      io.Reader
 	closes []func() error
+	eof    bool
 }
 
 func (rc *readcloser) Read(b []byte) (int, error) {
-	return rc.t.Read(b)
+	n, err := rc.t.Read(b)
+	if err == io.EOF {
+		rc.eof = true
+	}
+	return n, err
 }
 
 func (rc *readcloser) Close() error {
@@ -120,16 +202,30 @@ func (rc *readcloser) Close() error {
 func (fs *fscache) Get(h v1.Hash) (v1.Layer, error) {
 	l, err := tarball.LayerFromFile(cachepath(fs.path, h))
 	if os.IsNotExist(err) {
+		fs.counters.misses.Add(1)
 		return nil, ErrNotFound
 	}
+	if err == nil && fs.maxSize > 0 {
+		// Mark the file as recently used so it's evicted last.
+		now := time.Now()
+		if err := os.Chtimes(cachepath(fs.path, h), now, now); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
 	if errors.Is(err, io.ErrUnexpectedEOF) {
 		// Delete and return ErrNotFound because the layer was incomplete.
-		if err := fs.Delete(h); err != nil {
+		// Another process may have beaten us to it.
+		if err := fs.Delete(h); err != nil && !errors.Is(err, ErrNotFound) {
 			return nil, err
 		}
+		fs.counters.misses.Add(1)
 		return nil, ErrNotFound
 	}
-	return l, err
+	if err != nil {
+		return nil, err
+	}
+	fs.counters.hits.Add(1)
+	return &servedLayer{Layer: l, served: &fs.counters.served}, nil
 }
 
 func (fs *fscache) Delete(h v1.Hash) error {
@@ -140,6 +236,182 @@ func (fs *fscache) Delete(h v1.Hash) error {
 	return err
 }
 
+func (fs *fscache) PutBlob(h v1.Hash, b []byte) error {
+	if err := os.MkdirAll(fs.path, 0700); err != nil {
+		return err
+	}
+	dst := cachepath(fs.path, h)
+	f, err := os.CreateTemp(fs.path, tempPrefix+filepath.Base(dst)+"-*")
+	if err != nil {
+		return err
+	}
+	p := &pending{File: f, dst: dst}
+	if _, err := f.Write(b); err != nil {
+		p.commit(false)
+		return err
+	}
+	if err := p.commit(true); err != nil {
+		return err
+	}
+	return fs.evict()
+}
+
+func (fs *fscache) GetBlob(h v1.Hash) ([]byte, error) {
+	b, err := os.ReadFile(cachepath(fs.path, h))
+	if os.IsNotExist(err) {
+		fs.counters.misses.Add(1)
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	fs.counters.hits.Add(1)
+	fs.counters.served.Add(int64(len(b)))
+	if fs.maxSize > 0 {
+		// Mark the file as recently used so it's evicted last.
+		now := time.Now()
+		if err := os.Chtimes(cachepath(fs.path, h), now, now); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// evict removes the least recently used files from the cache until their total
+// size is within maxSize.
+func (fs *fscache) evict() error {
+	if fs.maxSize <= 0 {
+		return nil
+	}
+	fs.evictMu.Lock()
+	defer fs.evictMu.Unlock()
+
+	unlock, ok, err := fs.lock()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// Another process is evicting; it'll see our write.
+		return nil
+	}
+	defer unlock()
+
+	entries, err := os.ReadDir(fs.path)
+	if err != nil {
+		return err
+	}
+	var (
+		files []os.FileInfo
+		total int64
+	)
+	for _, e := range entries {
+		if !e.Type().IsRegular() || e.Name() == lockName {
+			continue
+		}
+		fi, err := e.Info()
+		if os.IsNotExist(err) {
+			// Someone else evicted it.
+			continue
+		} else if err != nil {
+			return err
+		}
+		if strings.HasPrefix(fi.Name(), tempPrefix) {
+			// Clean up after writers that died, but leave the others be.
+			if time.Since(fi.ModTime()) > staleAge {
+				if err := os.Remove(filepath.Join(fs.path, fi.Name())); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			}
+			continue
+		}
+		files = append(files, fi)
+		total += fi.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	for _, fi := range files {
+		if total <= fs.maxSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(fs.path, fi.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		fs.counters.evictions.Add(1)
+		total -= fi.Size()
+	}
+	return nil
+}
+
+func (fs *fscache) Stats() Stats {
+	return fs.counters.stats()
+}
+
+// Entries lists the files in the cache. LastUsed is the time the entry was
+// last written or, with WithMaxSize, read.
+func (fs *fscache) Entries() ([]Entry, error) {
+	des, err := os.ReadDir(fs.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(des))
+	for _, de := range des {
+		if !de.Type().IsRegular() || strings.HasPrefix(de.Name(), ".") {
+			continue
+		}
+		h, err := v1.NewHash(cachename(de.Name()))
+		if err != nil {
+			// Not ours.
+			continue
+		}
+		fi, err := de.Info()
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{
+			Digest:   h,
+			Size:     fi.Size(),
+			LastUsed: fi.ModTime(),
+		})
+	}
+	return entries, nil
+}
+
+// lock tries to take the cache's lock file, which is shared by every process
+// using the cache. It returns false if another process holds it. Lock files
+// older than staleAge are assumed to be left over from a process that died.
+func (fs *fscache) lock() (func(), bool, error) {
+	p := filepath.Join(fs.path, lockName)
+	for range 2 {
+		f, err := os.OpenFile(p, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(p) }, true, nil
+		}
+		if !os.IsExist(err) {
+			return nil, false, err
+		}
+		fi, err := os.Stat(p)
+		if os.IsNotExist(err) {
+			// Released while we looked; try again.
+			continue
+		} else if err != nil {
+			return nil, false, err
+		}
+		if time.Since(fi.ModTime()) <= staleAge {
+			return nil, false, nil
+		}
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return nil, false, err
+		}
+	}
+	return nil, false, nil
+}
+
 func cachepath(path string, h v1.Hash) string {
 	var file string
 	if runtime.GOOS == "windows" {
@@ -149,3 +421,11 @@ func cachepath(path string, h v1.Hash) string {
 	}
 	return filepath.Join(path, file)
 }
+
+// cachename is the inverse of cachepath's file name.
+func cachename(file string) string {
+	if runtime.GOOS == "windows" {
+		return strings.Replace(file, "-", ":", 1)
+	}
+	return file
+}
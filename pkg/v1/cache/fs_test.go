@@ -18,9 +18,13 @@ import (
 	"errors"
 	"io"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
@@ -211,3 +215,291 @@ func TestErrUnexpectedEOF(t *testing.T) {
 		t.Errorf("os.Stat(%q): %v", p, err)
 	}
 }
+
+func TestFilesystemCacheMaxSize(t *testing.T) {
+	dir := t.TempDir()
+
+	layers := make([]v1.Layer, 3)
+	for i := range layers {
+		l, err := random.Layer(1000, types.DockerLayer)
+		if err != nil {
+			t.Fatalf("random.Layer: %v", err)
+		}
+		layers[i] = l
+	}
+	size, err := layers[0].Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Leave room for two of the compressed layers.
+	c := NewFilesystemCache(dir, WithMaxSize(2*size+size/2))
+
+	// Advance the mtime of each write so eviction order is deterministic.
+	now := time.Now().Add(-time.Hour)
+	put := func(l v1.Layer) {
+		t.Helper()
+		cl, err := c.Put(l)
+		if err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		rc, err := cl.Compressed()
+		if err != nil {
+			t.Fatalf("Compressed: %v", err)
+		}
+		if _, err := io.Copy(io.Discard, rc); err != nil {
+			t.Fatalf("Error reading contents: %v", err)
+		}
+		if err := rc.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		h, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Minute)
+		if err := os.Chtimes(cachepath(dir, h), now, now); err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+	}
+	has := func(l v1.Layer) bool {
+		h, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = os.Stat(cachepath(dir, h))
+		return err == nil
+	}
+
+	put(layers[0])
+	put(layers[1])
+
+	// Using layers[0] makes layers[1] the least recently used.
+	h0, err := layers[0].Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(h0); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	put(layers[2])
+
+	if !has(layers[0]) {
+		t.Errorf("recently used layer was evicted")
+	}
+	if has(layers[1]) {
+		t.Errorf("least recently used layer was not evicted")
+	}
+	if !has(layers[2]) {
+		t.Errorf("newest layer was evicted")
+	}
+}
+
+func TestFilesystemCacheIncompleteRead(t *testing.T) {
+	dir := t.TempDir()
+
+	l, err := random.Layer(1000, types.DockerLayer)
+	if err != nil {
+		t.Fatalf("random.Layer: %v", err)
+	}
+	c := NewFilesystemCache(dir)
+	cl, err := c.Put(l)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rc, err := cl.Compressed()
+	if err != nil {
+		t.Fatalf("Compressed: %v", err)
+	}
+	if _, err := rc.Read(make([]byte, 10)); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if err := rc.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Nothing, not even a temporary file, should be left behind.
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(dirEntries) != 0 {
+		t.Errorf("Got %d cached files, want 0", len(dirEntries))
+	}
+	h, err := l.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(h); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(%q): %v", h, err)
+	}
+}
+
+func TestFilesystemCacheShared(t *testing.T) {
+	dir := t.TempDir()
+
+	img, err := random.Image(1000, 3)
+	if err != nil {
+		t.Fatalf("random.Image: %v", err)
+	}
+	ls, err := img.Layers()
+	if err != nil {
+		t.Fatalf("Layers: %v", err)
+	}
+
+	// Separate caches over the same directory stand in for separate processes.
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*len(ls))
+	for range 8 {
+		c := NewFilesystemCache(dir, WithMaxSize(1<<30))
+		for _, l := range ls {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				cl, err := c.Put(l)
+				if err != nil {
+					errs <- err
+					return
+				}
+				rc, err := cl.Compressed()
+				if err != nil {
+					errs <- err
+					return
+				}
+				if _, err := io.Copy(io.Discard, rc); err != nil {
+					errs <- err
+				}
+				if err := rc.Close(); err != nil {
+					errs <- err
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if got, want := len(dirEntries), len(ls); got != want {
+		t.Errorf("Got %d cached files, want %d", got, want)
+	}
+	c := NewFilesystemCache(dir)
+	for i, l := range ls {
+		h, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cl, err := c.Get(h)
+		if err != nil {
+			t.Fatalf("Get(layer[%d]): %v", i, err)
+		}
+		got, err := cl.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != h {
+			t.Errorf("layer[%d] digest = %s, want %s", i, got, h)
+		}
+	}
+}
+
+func TestFilesystemCacheStats(t *testing.T) {
+	dir := t.TempDir()
+
+	layers := make([]v1.Layer, 3)
+	for i := range layers {
+		l, err := random.Layer(1000, types.DockerLayer)
+		if err != nil {
+			t.Fatalf("random.Layer: %v", err)
+		}
+		layers[i] = l
+	}
+	size, err := layers[0].Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewFilesystemCache(dir, WithMaxSize(2*size+size/2))
+	base, err := mutate.AppendLayers(empty.Image, layers...)
+	if err != nil {
+		t.Fatalf("AppendLayers: %v", err)
+	}
+	img := Image(base, c)
+
+	read := func() {
+		t.Helper()
+		ls, err := img.Layers()
+		if err != nil {
+			t.Fatalf("Layers: %v", err)
+		}
+		for i, l := range ls {
+			rc, err := l.Compressed()
+			if err != nil {
+				t.Fatalf("layer[%d].Compressed: %v", i, err)
+			}
+			if _, err := io.Copy(io.Discard, rc); err != nil {
+				t.Fatalf("Error reading contents: %v", err)
+			}
+			rc.Close()
+		}
+	}
+	read()
+
+	i, ok := c.(Inspector)
+	if !ok {
+		t.Fatal("filesystem cache doesn't implement Inspector")
+	}
+	entries, err := i.Entries()
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if got, want := len(entries), 2; got != want {
+		t.Errorf("Got %d entries, want %d", got, want)
+	}
+	for _, e := range entries {
+		l, err := base.LayerByDigest(e.Digest)
+		if err != nil {
+			t.Fatalf("LayerByDigest: %v", err)
+		}
+		want, err := l.Size()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Size != want {
+			t.Errorf("Entry %s has size %d, want %d", e.Digest, e.Size, want)
+		}
+	}
+	if got, want := i.Stats(), (Stats{Misses: 3, Evictions: 1}); got != want {
+		t.Errorf("Stats() = %v, want %v", got, want)
+	}
+
+	// Reading the last layer again is a hit.
+	h, err := layers[2].Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, err = layers[2].Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := c.Get(h)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		t.Fatalf("Compressed: %v", err)
+	}
+	if _, err := io.Copy(io.Discard, rc); err != nil {
+		t.Fatalf("Error reading contents: %v", err)
+	}
+	rc.Close()
+	if got, want := i.Stats(), (Stats{Hits: 1, Misses: 3, BytesServed: size, Evictions: 1}); got != want {
+		t.Errorf("Stats() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// errIncomplete aborts the upload of a layer that wasn't read to the end.
+var errIncomplete = errors.New("layer was not read completely")
+
+type regcache struct {
+	repo    name.Repository
+	options []remote.Option
+}
+
+// NewRegistry returns a Cache implementation that stores layers as blobs in
+// repo, keyed by digest, so that builders on different machines can share a
+// cache through any registry.
+//
+// Like the filesystem cache, a layer is stored twice: its compressed contents
+// under its digest, and its uncompressed contents under its diffID. Layers
+// are uploaded as they are read; failing to upload one is logged, but doesn't
+// fail the read.
+//
+// Registries generally don't allow deleting blobs through the API, so Delete
+// always returns an error; use the registry's garbage collection instead.
+func NewRegistry(repo name.Repository, options ...remote.Option) Cache {
+	return &regcache{
+		repo:    repo,
+		options: options,
+	}
+}
+
+func (r *regcache) Put(l v1.Layer) (v1.Layer, error) {
+	digest, err := l.Digest()
+	if err != nil {
+		return nil, err
+	}
+	diffID, err := l.DiffID()
+	if err != nil {
+		return nil, err
+	}
+	return &registryLayer{
+		Layer:  l,
+		r:      r,
+		digest: digest,
+		diffID: diffID,
+	}, nil
+}
+
+func (r *regcache) Get(h v1.Hash) (v1.Layer, error) {
+	l, err := remote.Layer(r.repo.Digest(h.String()), r.options...)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := partial.Exists(l)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return l, nil
+}
+
+func (r *regcache) Delete(v1.Hash) error {
+	return errors.New("deleting blobs from a registry cache is not supported")
+}
+
+func (r *regcache) PutBlob(h v1.Hash, b []byte) error {
+	return remote.WriteLayer(r.repo, static.NewLayer(b, types.OCILayer), r.options...)
+}
+
+func (r *regcache) GetBlob(h v1.Hash) ([]byte, error) {
+	l, err := r.Get(h)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+type registryLayer struct {
+	v1.Layer
+	r              *regcache
+	digest, diffID v1.Hash
+}
+
+func (l *registryLayer) Compressed() (io.ReadCloser, error) {
+	size, err := l.Layer.Size()
+	if err != nil {
+		return nil, err
+	}
+	rc, err := l.Layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	return l.r.upload(rc, l.digest, size), nil
+}
+
+func (l *registryLayer) Uncompressed() (io.ReadCloser, error) {
+	rc, err := l.Layer.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	// The uncompressed size isn't known until it's been read.
+	return l.r.upload(rc, l.diffID, -1), nil
+}
+
+// upload returns a ReadCloser that uploads what it reads from rc as the blob
+// h in the background. The upload is committed on Close if rc was read to
+// the end, and aborted otherwise.
+func (r *regcache) upload(rc io.ReadCloser, h v1.Hash, size int64) io.ReadCloser {
+	pr, pw := io.Pipe()
+	u := &uploader{pw: pw, done: make(chan struct{})}
+	go func() {
+		defer close(u.done)
+		err := remote.WriteLayer(r.repo, &blobLayer{rc: pr, digest: h, size: size}, r.options...)
+		if err != nil {
+			logs.Warn.Printf("failed to cache %s in %s: %v", h, r.repo, err)
+		}
+		// If the blob already existed, nothing read from the pipe; unblock
+		// any writes.
+		pr.CloseWithError(io.ErrClosedPipe)
+	}()
+
+	t := &readcloser{t: io.TeeReader(rc, u)}
+	t.closes = []func() error{
+		rc.Close,
+		func() error {
+			if t.eof {
+				pw.Close()
+			} else {
+				pw.CloseWithError(errIncomplete)
+			}
+			<-u.done
+			return nil
+		},
+	}
+	return t
+}
+
+// uploader forwards writes to pw until it fails, after which writes are
+// dropped, so a failed upload doesn't fail the read that it tees from.
+type uploader struct {
+	pw     *io.PipeWriter
+	failed bool
+	done   chan struct{}
+}
+
+func (u *uploader) Write(b []byte) (int, error) {
+	if !u.failed {
+		if _, err := u.pw.Write(b); err != nil {
+			u.failed = true
+		}
+	}
+	return len(b), nil
+}
+
+// blobLayer is the minimal v1.Layer that remote.WriteLayer needs to upload
+// the contents of rc as the blob digest.
+type blobLayer struct {
+	rc     io.ReadCloser
+	digest v1.Hash
+	size   int64
+
+	once sync.Once
+}
+
+func (b *blobLayer) Digest() (v1.Hash, error) { return b.digest, nil }
+func (b *blobLayer) DiffID() (v1.Hash, error) { return v1.Hash{}, errors.New("unsupported") }
+func (b *blobLayer) Size() (int64, error)     { return b.size, nil }
+
+func (b *blobLayer) MediaType() (types.MediaType, error) {
+	return types.OCILayer, nil
+}
+
+func (b *blobLayer) Compressed() (io.ReadCloser, error) {
+	rc := io.ReadCloser(nil)
+	b.once.Do(func() { rc = b.rc })
+	if rc == nil {
+		// The contents can't be read twice, so retries fail.
+		return nil, errors.New("blob contents were already consumed")
+	}
+	return rc, nil
+}
+
+func (b *blobLayer) Uncompressed() (io.ReadCloser, error) {
+	return nil, errors.New("unsupported")
+}
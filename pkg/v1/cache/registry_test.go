@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func newRegistryCache(t *testing.T) Cache {
+	t.Helper()
+	s := httptest.NewServer(registry.New())
+	t.Cleanup(s.Close)
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(u.Host + "/cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewRegistry(repo)
+}
+
+func TestRegistryCache(t *testing.T) {
+	c := newRegistryCache(t)
+
+	img, err := random.Image(1000, 3)
+	if err != nil {
+		t.Fatalf("random.Image: %v", err)
+	}
+	img = Image(img, c)
+	ls, err := img.Layers()
+	if err != nil {
+		t.Fatalf("Layers: %v", err)
+	}
+
+	for i, l := range ls {
+		digest, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		diffID, err := l.DiffID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Get(digest); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Get(layer[%d]) before reading: %v", i, err)
+		}
+
+		for _, open := range []func() (io.ReadCloser, error){l.Compressed, l.Uncompressed} {
+			rc, err := open()
+			if err != nil {
+				t.Fatalf("layer[%d]: %v", i, err)
+			}
+			if _, err := io.Copy(io.Discard, rc); err != nil {
+				t.Fatalf("Error reading contents: %v", err)
+			}
+			if err := rc.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+		}
+
+		for _, h := range []v1.Hash{digest, diffID} {
+			cl, err := c.Get(h)
+			if err != nil {
+				t.Fatalf("Get(%s): %v", h, err)
+			}
+			got, err := cl.DiffID()
+			if err != nil {
+				t.Fatalf("DiffID: %v", err)
+			}
+			if got != diffID {
+				t.Errorf("Get(%s).DiffID() = %s, want %s", h, got, diffID)
+			}
+		}
+	}
+}
+
+func TestRegistryCacheIncompleteRead(t *testing.T) {
+	c := newRegistryCache(t)
+
+	l, err := random.Layer(1000, types.DockerLayer)
+	if err != nil {
+		t.Fatalf("random.Layer: %v", err)
+	}
+	cl, err := c.Put(l)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rc, err := cl.Compressed()
+	if err != nil {
+		t.Fatalf("Compressed: %v", err)
+	}
+	if _, err := rc.Read(make([]byte, 10)); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if err := rc.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	h, err := l.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(h); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(%q): %v", h, err)
+	}
+}
@@ -18,10 +18,32 @@ import v1 "github.com/google/go-containerregistry/pkg/v1"
 
 // ReadOnly returns a read-only implementation of the given Cache.
 //
-// Put and Delete operations are a no-op.
+// Put, PutBlob and Delete operations are a no-op.
 func ReadOnly(c Cache) Cache { return &ro{Cache: c} }
 
 type ro struct{ Cache }
 
 func (ro) Put(l v1.Layer) (v1.Layer, error) { return l, nil }
 func (ro) Delete(v1.Hash) error             { return nil }
+func (ro) PutBlob(v1.Hash, []byte) error    { return nil }
+
+func (r ro) GetBlob(h v1.Hash) ([]byte, error) {
+	if bc, ok := r.Cache.(BlobCache); ok {
+		return bc.GetBlob(h)
+	}
+	return nil, ErrNotFound
+}
+
+func (r ro) Stats() Stats {
+	if i, ok := r.Cache.(Inspector); ok {
+		return i.Stats()
+	}
+	return Stats{}
+}
+
+func (r ro) Entries() ([]Entry, error) {
+	if i, ok := r.Cache.(Inspector); ok {
+		return i.Entries()
+	}
+	return nil, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Inspector is implemented by Caches that can report on their contents and
+// activity.
+type Inspector interface {
+	// Stats returns counters of the cache's activity since it was created.
+	Stats() Stats
+
+	// Entries lists the layers and blobs in the cache.
+	Entries() ([]Entry, error)
+}
+
+// Stats are counters of a cache's activity.
+type Stats struct {
+	// Hits and Misses count lookups of layers and blobs.
+	Hits, Misses int64
+
+	// BytesServed counts the bytes read from the cache.
+	BytesServed int64
+
+	// Evictions counts the entries removed to stay within the cache's size.
+	Evictions int64
+}
+
+// String implements fmt.Stringer.
+func (s Stats) String() string {
+	return fmt.Sprintf("%d hits, %d misses, %d bytes served, %d evictions", s.Hits, s.Misses, s.BytesServed, s.Evictions)
+}
+
+// Entry describes a layer or blob in a cache.
+type Entry struct {
+	Digest   v1.Hash
+	Size     int64
+	LastUsed time.Time
+}
+
+// counters are the atomic counterpart of Stats.
+type counters struct {
+	hits, misses, served, evictions atomic.Int64
+}
+
+func (c *counters) stats() Stats {
+	return Stats{
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		BytesServed: c.served.Load(),
+		Evictions:   c.evictions.Load(),
+	}
+}
+
+// servedLayer counts the bytes read from a cached layer.
+type servedLayer struct {
+	v1.Layer
+	served *atomic.Int64
+}
+
+func (l *servedLayer) Compressed() (io.ReadCloser, error) {
+	rc, err := l.Layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	return &servedReader{ReadCloser: rc, served: l.served}, nil
+}
+
+func (l *servedLayer) Uncompressed() (io.ReadCloser, error) {
+	rc, err := l.Layer.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	return &servedReader{ReadCloser: rc, served: l.served}, nil
+}
+
+type servedReader struct {
+	io.ReadCloser
+	served *atomic.Int64
+}
+
+func (r *servedReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	r.served.Add(int64(n))
+	return n, err
+}
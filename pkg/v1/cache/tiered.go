@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"errors"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Tiered returns a Cache that stacks the given caches, the first being the
+// top. For example, a writable cache over a prepopulated ReadOnly one lets
+// jobs share a warm base cache while extending it locally:
+//
+//	c := cache.Tiered(
+//		cache.NewFilesystemCache(local),
+//		cache.ReadOnly(cache.NewFilesystemCache(base)),
+//	)
+//
+// Get returns the layer from the topmost cache that has it, and populates
+// the caches above that one as the layer is read. Put writes to every cache,
+// and Delete deletes from every cache.
+func Tiered(caches ...Cache) Cache {
+	return tiered(caches)
+}
+
+type tiered []Cache
+
+func (t tiered) Put(l v1.Layer) (v1.Layer, error) {
+	return put(t, l)
+}
+
+// put wraps l with each of caches, bottom first, so that reading the
+// returned layer populates all of them.
+func put(caches []Cache, l v1.Layer) (v1.Layer, error) {
+	for i := len(caches) - 1; i >= 0; i-- {
+		var err error
+		if l, err = caches[i].Put(l); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (t tiered) Get(h v1.Hash) (v1.Layer, error) {
+	for i, c := range t {
+		l, err := c.Get(h)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		// Promote the layer into the caches above this one.
+		return put(t[:i], l)
+	}
+	return nil, ErrNotFound
+}
+
+// Delete returns ErrNotFound only if none of the caches had the layer.
+func (t tiered) Delete(h v1.Hash) error {
+	found := false
+	for _, c := range t {
+		err := c.Delete(h)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		found = true
+	}
+	if !found {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// PutBlob writes the blob to every cache that is a BlobCache.
+func (t tiered) PutBlob(h v1.Hash, b []byte) error {
+	for _, c := range t {
+		if bc, ok := c.(BlobCache); ok {
+			if err := bc.PutBlob(h, b); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t tiered) GetBlob(h v1.Hash) ([]byte, error) {
+	for i, c := range t {
+		bc, ok := c.(BlobCache)
+		if !ok {
+			continue
+		}
+		b, err := bc.GetBlob(h)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		// Promote the blob into the caches above this one.
+		if err := t[:i].PutBlob(h, b); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+	return nil, ErrNotFound
+}
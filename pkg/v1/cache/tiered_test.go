@@ -0,0 +1,109 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestTiered(t *testing.T) {
+	base := NewFilesystemCache(t.TempDir())
+	top := NewFilesystemCache(t.TempDir())
+	c := Tiered(top, ReadOnly(base))
+
+	warm, err := random.Layer(1000, types.DockerLayer)
+	if err != nil {
+		t.Fatalf("random.Layer: %v", err)
+	}
+	cold, err := random.Layer(1000, types.DockerLayer)
+	if err != nil {
+		t.Fatalf("random.Layer: %v", err)
+	}
+	read := func(l v1.Layer) {
+		t.Helper()
+		rc, err := l.Compressed()
+		if err != nil {
+			t.Fatalf("Compressed: %v", err)
+		}
+		if _, err := io.Copy(io.Discard, rc); err != nil {
+			t.Fatalf("Error reading contents: %v", err)
+		}
+		if err := rc.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	}
+	digest := func(l v1.Layer) v1.Hash {
+		t.Helper()
+		h, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	// Prepopulate the base.
+	bl, err := base.Put(warm)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	read(bl)
+
+	// Put only writes to the writable cache.
+	cl, err := c.Put(cold)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	read(cl)
+	if _, err := top.Get(digest(cold)); err != nil {
+		t.Errorf("top.Get(cold): %v", err)
+	}
+	if _, err := base.Get(digest(cold)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("base.Get(cold): %v", err)
+	}
+
+	// Reading a layer from the base promotes it to the top.
+	if _, err := top.Get(digest(warm)); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("top.Get(warm) before reading: %v", err)
+	}
+	wl, err := c.Get(digest(warm))
+	if err != nil {
+		t.Fatalf("Get(warm): %v", err)
+	}
+	read(wl)
+	if _, err := top.Get(digest(warm)); err != nil {
+		t.Errorf("top.Get(warm): %v", err)
+	}
+
+	// Delete only removes layers from the writable cache.
+	if err := c.Delete(digest(warm)); err != nil {
+		t.Errorf("Delete(warm): %v", err)
+	}
+	if _, err := top.Get(digest(warm)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("top.Get(warm) after Delete: %v", err)
+	}
+	if _, err := c.Get(digest(warm)); err != nil {
+		t.Errorf("Get(warm) after Delete: %v", err)
+	}
+
+	if _, err := c.Get(v1.Hash{Algorithm: "fake", Hex: "not-found"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(not-found): %v", err)
+	}
+}
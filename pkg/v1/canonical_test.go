@@ -0,0 +1,59 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	h := Hash{Algorithm: "sha256", Hex: strings.Repeat("a", 64)}
+	m := &Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		Config: Descriptor{
+			MediaType: types.OCIConfigJSON,
+			Size:      123,
+			Digest:    h,
+		},
+		Annotations: map[string]string{
+			"z": "<last>",
+			"a": "first & foremost",
+		},
+	}
+
+	got, err := MarshalCanonical(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"annotations":{"a":"first & foremost","z":"<last>"},"config":{"digest":"` + h.String() + `","mediaType":"application/vnd.oci.image.config.v1+json","size":123},"layers":null,"mediaType":"application/vnd.oci.image.manifest.v1+json","schemaVersion":2}`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("MarshalCanonical() (-want +got) = %s", diff)
+	}
+
+	// The canonical encoding round-trips.
+	pm, err := ParseManifest(bytes.NewReader(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(m, pm); diff != "" {
+		t.Errorf("ParseManifest(MarshalCanonical()) (-want +got) = %s", diff)
+	}
+}
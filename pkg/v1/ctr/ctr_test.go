@@ -0,0 +1,259 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/containerd/containerd/content/local"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/images"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+	"github.com/opencontainers/go-digest"
+)
+
+// labelStore keeps content labels in memory.
+type labelStore struct {
+	sync.Mutex
+	labels map[digest.Digest]map[string]string
+}
+
+func (s *labelStore) Get(d digest.Digest) (map[string]string, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.labels[d], nil
+}
+
+func (s *labelStore) Set(d digest.Digest, labels map[string]string) error {
+	s.Lock()
+	defer s.Unlock()
+	s.labels[d] = labels
+	return nil
+}
+
+func (s *labelStore) Update(d digest.Digest, update map[string]string) (map[string]string, error) {
+	s.Lock()
+	defer s.Unlock()
+	labels := s.labels[d]
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range update {
+		if v == "" {
+			delete(labels, k)
+		} else {
+			labels[k] = v
+		}
+	}
+	s.labels[d] = labels
+	return labels, nil
+}
+
+// imageStore keeps images in memory.
+type imageStore struct {
+	sync.Mutex
+	images map[string]images.Image
+}
+
+func (s *imageStore) Get(_ context.Context, name string) (images.Image, error) {
+	s.Lock()
+	defer s.Unlock()
+	img, ok := s.images[name]
+	if !ok {
+		return images.Image{}, fmt.Errorf("image %q: %w", name, errdefs.ErrNotFound)
+	}
+	return img, nil
+}
+
+func (s *imageStore) List(_ context.Context, _ ...string) ([]images.Image, error) {
+	s.Lock()
+	defer s.Unlock()
+	var imgs []images.Image
+	for _, img := range s.images {
+		imgs = append(imgs, img)
+	}
+	return imgs, nil
+}
+
+func (s *imageStore) Create(_ context.Context, img images.Image) (images.Image, error) {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.images[img.Name]; ok {
+		return images.Image{}, errdefs.ErrAlreadyExists
+	}
+	s.images[img.Name] = img
+	return img, nil
+}
+
+func (s *imageStore) Update(_ context.Context, img images.Image, _ ...string) (images.Image, error) {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.images[img.Name]; !ok {
+		return images.Image{}, errdefs.ErrNotFound
+	}
+	s.images[img.Name] = img
+	return img, nil
+}
+
+func (s *imageStore) Delete(_ context.Context, name string, _ ...images.DeleteOpt) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.images, name)
+	return nil
+}
+
+func testStores(t *testing.T) (Option, *labelStore, *imageStore) {
+	t.Helper()
+	ls := &labelStore{labels: map[digest.Digest]map[string]string{}}
+	cs, err := local.NewLabeledStore(t.TempDir(), ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	is := &imageStore{images: map[string]images.Image{}}
+	return func(o *options) {
+		o.content = cs
+		o.images = is
+	}, ls, is
+}
+
+func TestImage(t *testing.T) {
+	opt, ls, is := testStores(t)
+
+	img, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag := name.MustParseReference("ubuntu:latest").(name.Tag)
+	if err := Write(tag, img, opt); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, ok := is.images["docker.io/library/ubuntu:latest"]; !ok {
+		t.Errorf("image not stored under its containerd name, got %v", is.images)
+	}
+
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(ls.labels[digest.Digest(d.String())]); got != 4 {
+		t.Errorf("manifest has %d gc labels, want 4 (config and 3 layers)", got)
+	}
+
+	for _, ref := range []name.Reference{tag, tag.Context().Digest(d.String())} {
+		got, err := Image(ref, opt)
+		if err != nil {
+			t.Fatalf("Image(%s): %v", ref, err)
+		}
+		if err := validate.Image(got); err != nil {
+			t.Errorf("validate.Image(%s): %v", ref, err)
+		}
+		gd, err := got.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gd != d {
+			t.Errorf("Image(%s): got digest %s, want %s", ref, gd, d)
+		}
+	}
+
+	// Overwriting a tag works.
+	img2, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(tag, img2, opt); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got, err := Image(tag, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gd, _ := got.Digest(); gd == d {
+		t.Error("tag was not updated")
+	}
+}
+
+func platformImage(t *testing.T, arch string) v1.Image {
+	t.Helper()
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf = cf.DeepCopy()
+	cf.OS = "linux"
+	cf.Architecture = arch
+	img, err = mutate.ConfigFile(img, cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestIndex(t *testing.T) {
+	opt, _, _ := testStores(t)
+
+	amd64 := platformImage(t, "amd64")
+	arm64 := platformImage(t, "arm64")
+	idx := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{
+		Add:        amd64,
+		Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}},
+	}, mutate.IndexAddendum{
+		Add:        arm64,
+		Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}},
+	})
+
+	tag := name.MustParseReference("example.com/multi:latest").(name.Tag)
+	if err := WriteIndex(tag, idx, opt); err != nil {
+		t.Fatalf("WriteIndex: %v", err)
+	}
+
+	got, err := Index(tag, opt)
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if err := validate.Index(got); err != nil {
+		t.Errorf("validate.Index: %v", err)
+	}
+
+	img, err := Image(tag, opt, WithPlatform(v1.Platform{OS: "linux", Architecture: "arm64"}))
+	if err != nil {
+		t.Fatalf("Image: %v", err)
+	}
+	want, err := arm64.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := img.Digest(); err != nil {
+		t.Fatal(err)
+	} else if d != want {
+		t.Errorf("Image: got %s, want the arm64 image %s", d, want)
+	}
+
+	if _, err := Image(tag, opt, WithPlatform(v1.Platform{OS: "windows", Architecture: "amd64"})); err == nil {
+		t.Error("Image: want error for missing platform")
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ctr provides facilities for reading/writing v1.Images and
+// v1.ImageIndexes from/to containerd's image and content stores.
+//
+// Unlike pkg/v1/daemon, this doesn't go through `docker save` and
+// `docker load`, so blobs are read lazily and multi-platform images survive
+// the round trip. This works with containerd directly, and with Docker when it
+// uses the containerd image store, in which case images live in the "moby"
+// namespace.
+//
+// This is a separate module from go-containerregistry, so that the containerd
+// client's dependencies aren't imposed on every user of go-containerregistry.
+package ctr
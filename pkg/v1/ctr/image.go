@@ -0,0 +1,353 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Image reads the image that ref refers to from containerd.
+//
+// If ref refers to a multi-platform index, the image for the platform given
+// by WithPlatform is returned.
+func Image(ref name.Reference, opts ...Option) (v1.Image, error) {
+	o := makeOptions(opts...)
+	s, err := o.stores()
+	if err != nil {
+		return nil, err
+	}
+	desc, err := s.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if desc.MediaType.IsIndex() {
+		idx := &ctrIndex{s: s, desc: *desc}
+		im, err := idx.IndexManifest()
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range im.Manifests {
+			if child.MediaType.IsImage() && child.Platform != nil && child.Platform.Satisfies(o.platform) {
+				return idx.Image(child.Digest)
+			}
+		}
+		return nil, fmt.Errorf("no image found in %s for platform %s", ref, o.platform.String())
+	}
+	if !desc.MediaType.IsImage() {
+		return nil, fmt.Errorf("unexpected media type for %s: %s", ref, desc.MediaType)
+	}
+	return partial.CompressedToImage(&ctrImage{s: s, desc: *desc})
+}
+
+// Index reads the multi-platform index that ref refers to from containerd.
+func Index(ref name.Reference, opts ...Option) (v1.ImageIndex, error) {
+	o := makeOptions(opts...)
+	s, err := o.stores()
+	if err != nil {
+		return nil, err
+	}
+	desc, err := s.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MediaType.IsIndex() {
+		return nil, fmt.Errorf("unexpected media type for %s: %s", ref, desc.MediaType)
+	}
+	return &ctrIndex{s: s, desc: *desc}, nil
+}
+
+// imageName returns the name that containerd uses for ref, which omits the
+// "index." from Docker Hub references.
+func imageName(ref name.Reference) string {
+	n := ref.Name()
+	if ref.Context().RegistryStr() == name.DefaultRegistry {
+		n = "docker.io/" + ref.Context().RepositoryStr() + n[len(ref.Context().Name()):]
+	}
+	return n
+}
+
+// resolve returns the descriptor that ref refers to.
+func (s *stores) resolve(ref name.Reference) (*v1.Descriptor, error) {
+	img, err := s.images.Get(s.ctx, imageName(ref))
+	if err == nil {
+		return fromOCI(img.Target), nil
+	}
+	d, ok := ref.(name.Digest)
+	if !ok || !errdefs.IsNotFound(err) {
+		return nil, err
+	}
+
+	// There's no image with that name, but we can still find the manifest
+	// by its digest.
+	info, err := s.content.Info(s.ctx, digest.Digest(d.DigestStr()))
+	if err != nil {
+		return nil, err
+	}
+	h, err := v1.NewHash(info.Digest.String())
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.bytes(v1.Descriptor{Digest: h, Size: info.Size})
+	if err != nil {
+		return nil, err
+	}
+	var mt struct {
+		MediaType types.MediaType `json:"mediaType"`
+	}
+	if err := json.Unmarshal(b, &mt); err != nil {
+		return nil, err
+	}
+	if mt.MediaType == "" {
+		return nil, errors.New("manifest has no mediaType")
+	}
+	return &v1.Descriptor{MediaType: mt.MediaType, Size: info.Size, Digest: h}, nil
+}
+
+// bytes reads the contents of desc from the content store.
+func (s *stores) bytes(desc v1.Descriptor) ([]byte, error) {
+	return content.ReadBlob(s.ctx, s.content, toOCI(desc))
+}
+
+// open returns a reader for the contents of desc from the content store.
+func (s *stores) open(desc v1.Descriptor) (io.ReadCloser, error) {
+	ra, err := s.content.ReaderAt(s.ctx, toOCI(desc))
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{content.NewReader(ra), ra}, nil
+}
+
+func toOCI(desc v1.Descriptor) ocispec.Descriptor {
+	return ocispec.Descriptor{
+		MediaType:   string(desc.MediaType),
+		Digest:      digest.Digest(desc.Digest.String()),
+		Size:        desc.Size,
+		Annotations: desc.Annotations,
+	}
+}
+
+func fromOCI(desc ocispec.Descriptor) *v1.Descriptor {
+	h, _ := v1.NewHash(desc.Digest.String())
+	return &v1.Descriptor{
+		MediaType:   types.MediaType(desc.MediaType),
+		Digest:      h,
+		Size:        desc.Size,
+		Annotations: desc.Annotations,
+	}
+}
+
+type ctrImage struct {
+	s            *stores
+	desc         v1.Descriptor
+	manifestLock sync.Mutex // Protects rawManifest
+	rawManifest  []byte
+}
+
+var _ partial.CompressedImageCore = (*ctrImage)(nil)
+
+func (i *ctrImage) MediaType() (types.MediaType, error) {
+	return i.desc.MediaType, nil
+}
+
+// Implements WithManifest for partial.Blobset.
+func (i *ctrImage) Manifest() (*v1.Manifest, error) {
+	return partial.Manifest(i)
+}
+
+func (i *ctrImage) RawManifest() ([]byte, error) {
+	i.manifestLock.Lock()
+	defer i.manifestLock.Unlock()
+	if i.rawManifest != nil {
+		return i.rawManifest, nil
+	}
+
+	b, err := i.s.bytes(i.desc)
+	if err != nil {
+		return nil, err
+	}
+
+	i.rawManifest = b
+	return i.rawManifest, nil
+}
+
+func (i *ctrImage) RawConfigFile() ([]byte, error) {
+	manifest, err := i.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	return i.s.bytes(manifest.Config)
+}
+
+func (i *ctrImage) LayerByDigest(h v1.Hash) (partial.CompressedLayer, error) {
+	manifest, err := i.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	if h == manifest.Config.Digest {
+		return &compressedBlob{s: i.s, desc: manifest.Config}, nil
+	}
+	for _, desc := range manifest.Layers {
+		if h == desc.Digest {
+			return &compressedBlob{s: i.s, desc: desc}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could not find layer in image: %s", h)
+}
+
+// Descriptor implements partial.withDescriptor.
+func (i *ctrImage) Descriptor() (*v1.Descriptor, error) {
+	return &i.desc, nil
+}
+
+type compressedBlob struct {
+	s    *stores
+	desc v1.Descriptor
+}
+
+func (b *compressedBlob) Digest() (v1.Hash, error) {
+	return b.desc.Digest, nil
+}
+
+func (b *compressedBlob) Compressed() (io.ReadCloser, error) {
+	return b.s.open(b.desc)
+}
+
+func (b *compressedBlob) Size() (int64, error) {
+	return b.desc.Size, nil
+}
+
+func (b *compressedBlob) MediaType() (types.MediaType, error) {
+	return b.desc.MediaType, nil
+}
+
+// Descriptor implements partial.withDescriptor.
+func (b *compressedBlob) Descriptor() (*v1.Descriptor, error) {
+	return &b.desc, nil
+}
+
+// See partial.Exists.
+func (b *compressedBlob) Exists() (bool, error) {
+	_, err := b.s.content.Info(b.s.ctx, digest.Digest(b.desc.Digest.String()))
+	if errdefs.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+type ctrIndex struct {
+	s         *stores
+	desc      v1.Descriptor
+	indexLock sync.Mutex // Protects rawIndex
+	rawIndex  []byte
+}
+
+var _ v1.ImageIndex = (*ctrIndex)(nil)
+
+func (i *ctrIndex) MediaType() (types.MediaType, error) {
+	return i.desc.MediaType, nil
+}
+
+func (i *ctrIndex) Digest() (v1.Hash, error) {
+	return i.desc.Digest, nil
+}
+
+func (i *ctrIndex) Size() (int64, error) {
+	return i.desc.Size, nil
+}
+
+func (i *ctrIndex) IndexManifest() (*v1.IndexManifest, error) {
+	b, err := i.RawManifest()
+	if err != nil {
+		return nil, err
+	}
+	return v1.ParseIndexManifest(bytes.NewReader(b))
+}
+
+func (i *ctrIndex) RawManifest() ([]byte, error) {
+	i.indexLock.Lock()
+	defer i.indexLock.Unlock()
+	if i.rawIndex != nil {
+		return i.rawIndex, nil
+	}
+
+	b, err := i.s.bytes(i.desc)
+	if err != nil {
+		return nil, err
+	}
+
+	i.rawIndex = b
+	return i.rawIndex, nil
+}
+
+// Descriptor implements partial.withDescriptor.
+func (i *ctrIndex) Descriptor() (*v1.Descriptor, error) {
+	return &i.desc, nil
+}
+
+func (i *ctrIndex) Image(h v1.Hash) (v1.Image, error) {
+	desc, err := i.findDescriptor(h)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MediaType.IsImage() {
+		return nil, fmt.Errorf("unexpected media type for %v: %s", h, desc.MediaType)
+	}
+	return partial.CompressedToImage(&ctrImage{s: i.s, desc: *desc})
+}
+
+func (i *ctrIndex) ImageIndex(h v1.Hash) (v1.ImageIndex, error) {
+	desc, err := i.findDescriptor(h)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MediaType.IsIndex() {
+		return nil, fmt.Errorf("unexpected media type for %v: %s", h, desc.MediaType)
+	}
+	return &ctrIndex{s: i.s, desc: *desc}, nil
+}
+
+func (i *ctrIndex) findDescriptor(h v1.Hash) (*v1.Descriptor, error) {
+	im, err := i.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range im.Manifests {
+		if desc.Digest == h {
+			return &desc, nil
+		}
+	}
+	return nil, fmt.Errorf("could not find descriptor in index: %s", h)
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctr
+
+import (
+	"context"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/namespaces"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	// DefaultAddress is the default address of containerd's socket.
+	DefaultAddress = "/run/containerd/containerd.sock"
+
+	// DefaultNamespace is the containerd namespace that ctr and nerdctl use
+	// by default.
+	DefaultNamespace = "default"
+
+	// MobyNamespace is the containerd namespace that Docker uses for its
+	// images when the containerd image store is enabled.
+	MobyNamespace = "moby"
+)
+
+// Option is a functional option for containerd operations.
+type Option func(*options)
+
+type options struct {
+	ctx       context.Context
+	client    *containerd.Client
+	address   string
+	namespace string
+	platform  v1.Platform
+
+	// For testing.
+	content content.Store
+	images  images.Store
+}
+
+func makeOptions(opts ...Option) *options {
+	o := &options{
+		ctx:       context.Background(),
+		address:   DefaultAddress,
+		namespace: DefaultNamespace,
+		platform: v1.Platform{
+			Architecture: "amd64",
+			OS:           "linux",
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithContext is a functional option to pass through a context.Context.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithClient is a functional option to use the given containerd client,
+// instead of dialing WithAddress.
+//
+// Images returned by Image and Index read their contents lazily, so the
+// client must not be closed while they are in use.
+func WithClient(client *containerd.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithAddress is a functional option to dial containerd at the given socket
+// address, instead of DefaultAddress.
+func WithAddress(address string) Option {
+	return func(o *options) {
+		o.address = address
+	}
+}
+
+// WithNamespace is a functional option to use the given containerd
+// namespace, instead of DefaultNamespace. Use MobyNamespace for Docker.
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithPlatform is a functional option to select the image that Image returns
+// when the reference points to a multi-platform index, instead of linux/amd64.
+func WithPlatform(platform v1.Platform) Option {
+	return func(o *options) {
+		o.platform = platform
+	}
+}
+
+// stores holds everything we need to talk to containerd.
+type stores struct {
+	ctx     context.Context
+	client  *containerd.Client
+	content content.Store
+	images  images.Store
+}
+
+// stores returns the stores that o refers to, dialing containerd if necessary.
+func (o *options) stores() (*stores, error) {
+	ctx := namespaces.WithNamespace(o.ctx, o.namespace)
+	if o.content != nil && o.images != nil {
+		return &stores{ctx: ctx, content: o.content, images: o.images}, nil
+	}
+
+	client := o.client
+	if client == nil {
+		var err error
+		client, err = containerd.New(o.address, containerd.WithDefaultNamespace(o.namespace))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &stores{
+		ctx:     ctx,
+		client:  client,
+		content: client.ContentStore(),
+		images:  client.ImageService(),
+	}, nil
+}
+
+// lease returns a context that protects the content we write from
+// containerd's garbage collection until done is called.
+func (s *stores) lease() (context.Context, func(context.Context) error, error) {
+	if s.client == nil {
+		return s.ctx, func(context.Context) error { return nil }, nil
+	}
+	return s.client.WithLease(s.ctx)
+}
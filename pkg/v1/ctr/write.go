@@ -0,0 +1,216 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/images"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/opencontainers/go-digest"
+)
+
+// Write saves img to containerd as tag.
+//
+// Only the content is written; the image isn't unpacked into a snapshotter,
+// which happens when a container is first created from it.
+func Write(tag name.Tag, img v1.Image, opts ...Option) error {
+	return write(tag, opts, func(ctx context.Context, s *stores) (*v1.Descriptor, error) {
+		return s.writeImage(ctx, img)
+	})
+}
+
+// WriteIndex saves idx, including the images for every platform, to
+// containerd as tag.
+func WriteIndex(tag name.Tag, idx v1.ImageIndex, opts ...Option) error {
+	return write(tag, opts, func(ctx context.Context, s *stores) (*v1.Descriptor, error) {
+		return s.writeIndex(ctx, idx)
+	})
+}
+
+func write(tag name.Tag, opts []Option, f func(context.Context, *stores) (*v1.Descriptor, error)) error {
+	o := makeOptions(opts...)
+	s, err := o.stores()
+	if err != nil {
+		return err
+	}
+	ctx, done, err := s.lease()
+	if err != nil {
+		return err
+	}
+	defer done(ctx) //nolint:errcheck
+
+	desc, err := f(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	img := images.Image{
+		Name:   imageName(tag),
+		Target: toOCI(*desc),
+	}
+	if _, err := s.images.Update(ctx, img, "target"); errdefs.IsNotFound(err) {
+		_, err = s.images.Create(ctx, img)
+		return err
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// gcRef is the prefix of the labels that tell containerd's garbage collector
+// that a blob refers to other content.
+// https://github.com/containerd/containerd/blob/main/docs/garbage-collection.md
+const gcRef = "containerd.io/gc.ref.content."
+
+// writeImage writes the blobs of img, returning the manifest's descriptor.
+func (s *stores) writeImage(ctx context.Context, img v1.Image) (*v1.Descriptor, error) {
+	m, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	labels := map[string]string{}
+
+	cfg, err := img.RawConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.writeBlob(ctx, m.Config, bytes.NewReader(cfg), nil); err != nil {
+		return nil, err
+	}
+	labels[gcRef+"config"] = m.Config.Digest.String()
+
+	for i, desc := range m.Layers {
+		if !desc.MediaType.IsDistributable() {
+			// Foreign layers aren't expected to be available.
+			continue
+		}
+		l, err := img.LayerByDigest(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.writeLayer(ctx, desc, l); err != nil {
+			return nil, err
+		}
+		labels[fmt.Sprintf("%sl.%d", gcRef, i)] = desc.Digest.String()
+	}
+
+	return s.writeManifest(ctx, img, labels)
+}
+
+// writeIndex writes the blobs of idx and its children, returning the index's
+// descriptor.
+func (s *stores) writeIndex(ctx context.Context, idx v1.ImageIndex) (*v1.Descriptor, error) {
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	labels := map[string]string{}
+	for i, desc := range im.Manifests {
+		switch {
+		case desc.MediaType.IsIndex():
+			child, err := idx.ImageIndex(desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := s.writeIndex(ctx, child); err != nil {
+				return nil, err
+			}
+		case desc.MediaType.IsImage():
+			child, err := idx.Image(desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := s.writeImage(ctx, child); err != nil {
+				return nil, err
+			}
+		default:
+			// Skip anything else, e.g. attestations we can't interpret.
+			continue
+		}
+		labels[fmt.Sprintf("%sm.%d", gcRef, i)] = desc.Digest.String()
+	}
+	return s.writeManifest(ctx, idx, labels)
+}
+
+// writeManifest writes the manifest of t with the given labels.
+func (s *stores) writeManifest(ctx context.Context, t partial.Describable, labels map[string]string) (*v1.Descriptor, error) {
+	raw, ok := t.(partial.WithRawManifest)
+	if !ok {
+		return nil, fmt.Errorf("%T has no manifest", t)
+	}
+	b, err := raw.RawManifest()
+	if err != nil {
+		return nil, err
+	}
+	mt, err := t.MediaType()
+	if err != nil {
+		return nil, err
+	}
+	h, size, err := v1.SHA256(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	desc := v1.Descriptor{MediaType: mt, Size: size, Digest: h}
+	if err := s.writeBlob(ctx, desc, bytes.NewReader(b), labels); err != nil {
+		return nil, err
+	}
+	return &desc, nil
+}
+
+// writeLayer writes the compressed contents of l, unless we already have them.
+func (s *stores) writeLayer(ctx context.Context, desc v1.Descriptor, l v1.Layer) error {
+	if _, err := s.content.Info(ctx, digest.Digest(desc.Digest.String())); err == nil {
+		return nil
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return s.writeBlob(ctx, desc, rc, nil)
+}
+
+// writeBlob writes r, which has the given descriptor, to the content store.
+// If the blob already exists, only its labels are updated.
+func (s *stores) writeBlob(ctx context.Context, desc v1.Descriptor, r io.Reader, labels map[string]string) error {
+	dgst := digest.Digest(desc.Digest.String())
+	if _, err := s.content.Info(ctx, dgst); err == nil {
+		if len(labels) == 0 {
+			return nil
+		}
+		fields := make([]string, 0, len(labels))
+		for k := range labels {
+			fields = append(fields, "labels."+k)
+		}
+		_, err := s.content.Update(ctx, content.Info{Digest: dgst, Labels: labels}, fields...)
+		return err
+	} else if !errdefs.IsNotFound(err) {
+		return err
+	}
+
+	var opts []content.Opt
+	if len(labels) != 0 {
+		opts = append(opts, content.WithLabels(labels))
+	}
+	return content.WriteBlob(ctx, s.content, desc.Digest.String(), r, toOCI(desc), opts...)
+}
@@ -0,0 +1,20 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package buildkit reads the images that BuildKit's OCI exporter writes, e.g.
+// with `docker buildx build -o type=oci,dest=-`, from a stream.
+//
+// Unlike the daemon package, it doesn't talk to a docker daemon, so the
+// output of a build can be pushed without saving it to disk first.
+package buildkit
@@ -0,0 +1,262 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildkit
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// blobs holds the contents of an OCI image layout, by digest.
+type blobs map[v1.Hash][]byte
+
+func (b blobs) get(h v1.Hash) ([]byte, error) {
+	blob, ok := b[h]
+	if !ok {
+		return nil, fmt.Errorf("blob %s not found in OCI layout", h)
+	}
+	return blob, nil
+}
+
+// Index reads a tarball of an OCI image layout from r, returning its
+// index.json, like layout.ImageIndexFromPath.
+//
+// The exporter writes index.json last, so the whole tarball is read, and its
+// blobs are held in memory.
+func Index(r io.Reader) (v1.ImageIndex, error) {
+	b := blobs{}
+	var index []byte
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(hdr.Name)
+		switch {
+		case name == "index.json":
+			if index, err = io.ReadAll(tr); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(name, "blobs/"):
+			alg, hex, ok := strings.Cut(strings.TrimPrefix(name, "blobs/"), "/")
+			if !ok {
+				continue
+			}
+			want, err := v1.NewHash(alg + ":" + hex)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			blob, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			if want.Algorithm == "sha256" {
+				got, _, err := v1.SHA256(bytes.NewReader(blob))
+				if err != nil {
+					return nil, err
+				}
+				if got != want {
+					return nil, fmt.Errorf("%s: digest mismatch, got %s", name, got)
+				}
+			}
+			b[want] = blob
+		}
+		// Anything else, e.g. oci-layout and the docker-compatible
+		// manifest.json, isn't needed.
+	}
+	if index == nil {
+		return nil, errors.New("index.json not found in OCI layout")
+	}
+
+	var im struct {
+		MediaType types.MediaType `json:"mediaType"`
+	}
+	if err := json.Unmarshal(index, &im); err != nil {
+		return nil, fmt.Errorf("parsing index.json: %w", err)
+	}
+	mt := im.MediaType
+	if mt == "" {
+		mt = types.OCIImageIndex
+	}
+	return &ociIndex{blobs: b, mediaType: mt, rawIndex: index}, nil
+}
+
+type ociIndex struct {
+	blobs     blobs
+	mediaType types.MediaType
+	rawIndex  []byte
+}
+
+var _ v1.ImageIndex = (*ociIndex)(nil)
+
+func (i *ociIndex) MediaType() (types.MediaType, error) {
+	return i.mediaType, nil
+}
+
+func (i *ociIndex) Digest() (v1.Hash, error) {
+	return partial.Digest(i)
+}
+
+func (i *ociIndex) Size() (int64, error) {
+	return partial.Size(i)
+}
+
+func (i *ociIndex) IndexManifest() (*v1.IndexManifest, error) {
+	return v1.ParseIndexManifest(bytes.NewReader(i.rawIndex))
+}
+
+func (i *ociIndex) RawManifest() ([]byte, error) {
+	return i.rawIndex, nil
+}
+
+func (i *ociIndex) Image(h v1.Hash) (v1.Image, error) {
+	desc, err := i.findDescriptor(h)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MediaType.IsImage() {
+		return nil, fmt.Errorf("unexpected media type for %v: %s", h, desc.MediaType)
+	}
+	raw, err := i.blobs.get(h)
+	if err != nil {
+		return nil, err
+	}
+	return partial.CompressedToImage(&ociImage{blobs: i.blobs, desc: *desc, rawManifest: raw})
+}
+
+func (i *ociIndex) ImageIndex(h v1.Hash) (v1.ImageIndex, error) {
+	desc, err := i.findDescriptor(h)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MediaType.IsIndex() {
+		return nil, fmt.Errorf("unexpected media type for %v: %s", h, desc.MediaType)
+	}
+	raw, err := i.blobs.get(h)
+	if err != nil {
+		return nil, err
+	}
+	return &ociIndex{blobs: i.blobs, mediaType: desc.MediaType, rawIndex: raw}, nil
+}
+
+func (i *ociIndex) findDescriptor(h v1.Hash) (*v1.Descriptor, error) {
+	im, err := i.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range im.Manifests {
+		if desc.Digest == h {
+			return &desc, nil
+		}
+	}
+	return nil, fmt.Errorf("could not find descriptor in index: %s", h)
+}
+
+type ociImage struct {
+	blobs       blobs
+	desc        v1.Descriptor
+	rawManifest []byte
+}
+
+var _ partial.CompressedImageCore = (*ociImage)(nil)
+
+func (i *ociImage) MediaType() (types.MediaType, error) {
+	return i.desc.MediaType, nil
+}
+
+// Implements WithManifest for partial.Blobset.
+func (i *ociImage) Manifest() (*v1.Manifest, error) {
+	return partial.Manifest(i)
+}
+
+func (i *ociImage) RawManifest() ([]byte, error) {
+	return i.rawManifest, nil
+}
+
+func (i *ociImage) RawConfigFile() ([]byte, error) {
+	m, err := i.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	return i.blobs.get(m.Config.Digest)
+}
+
+func (i *ociImage) LayerByDigest(h v1.Hash) (partial.CompressedLayer, error) {
+	m, err := i.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	if h == m.Config.Digest {
+		return &compressedBlob{blobs: i.blobs, desc: m.Config}, nil
+	}
+	for _, desc := range m.Layers {
+		if h == desc.Digest {
+			return &compressedBlob{blobs: i.blobs, desc: desc}, nil
+		}
+	}
+	return nil, fmt.Errorf("could not find layer in image: %s", h)
+}
+
+// Descriptor implements partial.withDescriptor.
+func (i *ociImage) Descriptor() (*v1.Descriptor, error) {
+	return &i.desc, nil
+}
+
+type compressedBlob struct {
+	blobs blobs
+	desc  v1.Descriptor
+}
+
+func (b *compressedBlob) Digest() (v1.Hash, error) {
+	return b.desc.Digest, nil
+}
+
+func (b *compressedBlob) Compressed() (io.ReadCloser, error) {
+	blob, err := b.blobs.get(b.desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(blob)), nil
+}
+
+func (b *compressedBlob) Size() (int64, error) {
+	return b.desc.Size, nil
+}
+
+func (b *compressedBlob) MediaType() (types.MediaType, error) {
+	return b.desc.MediaType, nil
+}
+
+// Descriptor implements partial.withDescriptor.
+func (b *compressedBlob) Descriptor() (*v1.Descriptor, error) {
+	return &b.desc, nil
+}
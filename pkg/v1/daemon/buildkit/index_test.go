@@ -0,0 +1,164 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildkit
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+// layoutTar returns a tarball of the OCI layout in dir, with index.json last
+// like BuildKit writes it.
+func layoutTar(t *testing.T, dir string) *bytes.Buffer {
+	t.Helper()
+	var files []string
+	if err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		files = append(files, filepath.ToSlash(rel))
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return strings.HasPrefix(files[i], "blobs/") && !strings.HasPrefix(files[j], "blobs/")
+	})
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range files {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: f, Mode: 0644, Size: int64(len(b)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestIndex(t *testing.T) {
+	img, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := random.Index(1024, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	p, err := layout.Write(dir, empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AppendImage(img); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AppendIndex(idx); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Index(layoutTar(t, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Index(got); err != nil {
+		t.Errorf("validate.Index: %v", err)
+	}
+
+	want, err := p.ImageIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gd, err := got.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, err := want.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gd != wd {
+		t.Errorf("Digest(): got %s, want %s", gd, wd)
+	}
+
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := got.Image(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Image(child); err != nil {
+		t.Errorf("validate.Image: %v", err)
+	}
+}
+
+func TestIndexErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+	}{{
+		name:  "no index.json",
+		files: map[string]string{"oci-layout": `{"imageLayoutVersion":"1.0.0"}`},
+	}, {
+		name: "digest mismatch",
+		files: map[string]string{
+			"blobs/sha256/0000000000000000000000000000000000000000000000000000000000000000": "oops",
+			"index.json": `{"schemaVersion":2,"manifests":[]}`,
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			for name, body := range tc.files {
+				if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := tw.Write([]byte(body)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Index(&buf); err == nil {
+				t.Error("Index(): want error")
+			}
+		})
+	}
+}
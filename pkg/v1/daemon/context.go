@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"fmt"
+
+	ctxdocker "github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/cli/context/store"
+	"github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/internal/dockercontext"
+)
+
+// WithDockerContext is a functional option to reach the daemon through the
+// docker CLI context called name, as `docker --context` would.
+//
+// By default, the current context is used, i.e. $DOCKER_CONTEXT or the one
+// selected with `docker context use`, unless $DOCKER_HOST is set. Contexts
+// with ssh:// endpoints need ssh and docker to be installed, locally and
+// remotely respectively.
+//
+// It has no effect if WithClient is also used.
+func WithDockerContext(name string) Option {
+	return func(o *options) {
+		o.dockerContext = name
+	}
+}
+
+// contextClient returns a client for the daemon of the context called name.
+func contextClient(name string) (Client, error) {
+	if name == dockercontext.Default {
+		return client.NewClientWithOpts(client.FromEnv)
+	}
+
+	s := store.New(dockercontext.StoreDir(), store.NewConfig(
+		func() any { return &map[string]any{} },
+		store.EndpointTypeGetter(ctxdocker.DockerEndpoint, func() any { return &ctxdocker.EndpointMeta{} }),
+	))
+	meta, err := s.GetMetadata(name)
+	if err != nil {
+		return nil, err
+	}
+	epMeta, err := ctxdocker.EndpointFromContext(meta)
+	if err != nil {
+		return nil, fmt.Errorf("context %q: %w", name, err)
+	}
+	ep, err := ctxdocker.WithTLSData(s, name, epMeta)
+	if err != nil {
+		return nil, fmt.Errorf("context %q: %w", name, err)
+	}
+	opts, err := ep.ClientOpts()
+	if err != nil {
+		return nil, fmt.Errorf("context %q: %w", name, err)
+	}
+	return client.NewClientWithOpts(opts...)
+}
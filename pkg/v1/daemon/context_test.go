@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/client"
+)
+
+func TestContextClient(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+
+	host := "unix://" + filepath.Join(dir, "remote.sock")
+	sum := sha256.Sum256([]byte("remote"))
+	p := filepath.Join(dir, "contexts", "meta", hex.EncodeToString(sum[:]), "meta.json")
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	meta := `{"Name":"remote","Metadata":{},"Endpoints":{"docker":{"Host":"` + host + `","SkipTLSVerify":false}}}`
+	if err := os.WriteFile(p, []byte(meta), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := contextClient("remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.(*client.Client).DaemonHost(); got != host {
+		t.Errorf("DaemonHost(): got %q, want %q", got, host)
+	}
+
+	if _, err := contextClient("missing"); err == nil {
+		t.Error("contextClient(missing): want error")
+	}
+
+	o, err := makeOptions(WithDockerContext("remote"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := o.client.(*client.Client).DaemonHost(); got != host {
+		t.Errorf("WithDockerContext: got %q, want %q", got, host)
+	}
+}
@@ -15,9 +15,9 @@
 package daemon
 
 import (
-	"bytes"
 	"context"
 	"io"
+	"path"
 	"sync"
 	"time"
 
@@ -32,6 +32,7 @@ import (
 
 type image struct {
 	ref          name.Reference
+	tag          *name.Tag // Selects the image in a tarball of several, see SaveAll.
 	opener       *imageOpener
 	tarballImage v1.Image
 	computed     bool
@@ -43,37 +44,58 @@ type image struct {
 }
 
 type imageOpener struct {
-	ref name.Reference
-	ctx context.Context
+	refs []name.Reference
+	ctx  context.Context
 
 	buffered bool
 	client   Client
+	updates  chan<- v1.Update
 
 	once  sync.Once
-	bytes []byte
+	spool *spool
 	err   error
 }
 
 func (i *imageOpener) saveImage() (io.ReadCloser, error) {
-	return i.client.ImageSave(i.ctx, []string{i.ref.Name()})
+	names := make([]string, len(i.refs))
+	for j, ref := range i.refs {
+		names[j] = ref.Name()
+	}
+	rc, err := i.client.ImageSave(i.ctx, names)
+	if err != nil {
+		if i.updates != nil {
+			(&progress{updates: i.updates}).finish(err)
+		}
+		return nil, err
+	}
+	if i.updates != nil {
+		rc = &progressReader{rc: rc, p: &progress{updates: i.updates}}
+	}
+	return rc, nil
 }
 
-func (i *imageOpener) bufferedOpener() (io.ReadCloser, error) {
-	// Store the tarball in memory and return a new reader into the bytes each time we need to access something.
+// spooled returns a spool of the saved image, which is only read from the
+// daemon once.
+func (i *imageOpener) spooled() (*spool, error) {
 	i.once.Do(func() {
-		i.bytes, i.err = func() ([]byte, error) {
+		i.spool, i.err = func() (*spool, error) {
 			rc, err := i.saveImage()
 			if err != nil {
 				return nil, err
 			}
-			defer rc.Close()
-
-			return io.ReadAll(rc)
+			return newSpool(rc)
 		}()
 	})
+	return i.spool, i.err
+}
 
-	// Wrap the bytes in a ReadCloser so it looks like an opened file.
-	return io.NopCloser(bytes.NewReader(i.bytes)), i.err
+func (i *imageOpener) bufferedOpener() (io.ReadCloser, error) {
+	// Spool the tarball to disk and return a new reader into it each time we need to access something.
+	s, err := i.spooled()
+	if err != nil {
+		return nil, err
+	}
+	return s.open()
 }
 
 func (i *imageOpener) opener() tarball.Opener {
@@ -95,9 +117,10 @@ func Image(ref name.Reference, options ...Option) (v1.Image, error) {
 	}
 
 	i := &imageOpener{
-		ref:      ref,
+		refs:     []name.Reference{ref},
 		buffered: o.buffered,
 		client:   o.client,
+		updates:  o.updates,
 		ctx:      o.ctx,
 	}
 
@@ -117,11 +140,55 @@ func Image(ref name.Reference, options ...Option) (v1.Image, error) {
 	return img, nil
 }
 
+// SaveAll provides access to several images from the Docker daemon, which
+// are saved together with a single `docker save`.
+//
+// Layers that the images share are only exported once, and with the default
+// WithBufferedOpener, so is the tarball, however many of the images are read.
+// The images are returned in the same order as tags.
+func SaveAll(tags []name.Tag, options ...Option) ([]v1.Image, error) {
+	o, err := makeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]name.Reference, len(tags))
+	for j, tag := range tags {
+		refs[j] = tag
+	}
+	opener := &imageOpener{
+		refs:     refs,
+		buffered: o.buffered,
+		client:   o.client,
+		updates:  o.updates,
+		ctx:      o.ctx,
+	}
+
+	imgs := make([]v1.Image, len(tags))
+	for j, tag := range tags {
+		tag := tag
+		img := &image{
+			ref:    tag,
+			tag:    &tag,
+			opener: opener,
+		}
+
+		// Eagerly fetch Image ID to ensure it actually exists.
+		id, err := img.ConfigName()
+		if err != nil {
+			return nil, err
+		}
+		img.id = &id
+		imgs[j] = img
+	}
+	return imgs, nil
+}
+
 func (i *image) initialize() error {
 	// Don't re-initialize tarball if already initialized.
 	if i.tarballImage == nil {
 		i.once.Do(func() {
-			i.tarballImage, i.err = tarball.Image(i.opener.opener(), nil)
+			i.tarballImage, i.err = tarball.Image(i.opener.opener(), i.tag)
 		})
 	}
 	return i.err
@@ -189,6 +256,14 @@ func (i *image) ConfigFile() (*v1.ConfigFile, error) {
 }
 
 func (i *image) RawConfigFile() ([]byte, error) {
+	if i.opener.buffered && i.id != nil {
+		// The config is named after the image ID, so we can find it without
+		// waiting for the manifest at the end of the tarball.
+		if b, err := i.spooledConfigFile(*i.id); err == nil {
+			return b, nil
+		}
+	}
+
 	if err := i.initialize(); err != nil {
 		return nil, err
 	}
@@ -198,6 +273,22 @@ func (i *image) RawConfigFile() ([]byte, error) {
 	return i.tarballImage.RawConfigFile()
 }
 
+// spooledConfigFile reads the config named id from the spooled tarball, where
+// it's either <hex>.json, as saved by the classic image store, or an OCI blob,
+// as saved by the containerd image store.
+func (i *image) spooledConfigFile(id v1.Hash) ([]byte, error) {
+	s, err := i.opener.spooled()
+	if err != nil {
+		return nil, err
+	}
+	rc, err := s.file(id.Hex+".json", path.Join("blobs", id.Algorithm, id.Hex))
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
 func (i *image) Digest() (v1.Hash, error) {
 	if err := i.initialize(); err != nil {
 		return v1.Hash{}, err
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -29,7 +30,9 @@ import (
 	"github.com/docker/docker/client"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/compare"
+	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/validate"
 )
@@ -74,9 +77,11 @@ type MockClient struct {
 
 	loadErr  error
 	loadBody io.ReadCloser
+	loaded   io.Writer
 
 	saveErr  error
 	saveBody io.ReadCloser
+	saves    int
 
 	inspectErr  error
 	inspectResp api.InspectResponse
@@ -93,6 +98,7 @@ func (m *MockClient) ImageSave(_ context.Context, _ []string, _ ...client.ImageS
 	if !m.negotiated {
 		return nil, errors.New("you forgot to call NegotiateAPIVersion before calling ImageSave")
 	}
+	m.saves++
 
 	if m.path != "" {
 		return os.Open(m.path)
@@ -211,3 +217,54 @@ func TestImageDefaultClient(t *testing.T) {
 		t.Errorf("Image(): want %v; got %v", wantErr, err)
 	}
 }
+
+func TestSaveAll(t *testing.T) {
+	want := map[name.Reference]v1.Image{}
+	var tags []name.Tag
+	for _, s := range []string{"first:latest", "second:latest"} {
+		img, err := random.Image(1024, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag, err := name.NewTag(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[tag] = img
+		tags = append(tags, tag)
+	}
+	path := filepath.Join(t.TempDir(), "save.tar")
+	if err := tarball.MultiRefWriteToFile(path, want); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &MockClient{
+		path:        path,
+		inspectResp: inspectResp,
+	}
+	imgs, err := SaveAll(tags, WithClient(client))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The mock's inspect response doesn't match these images, so just check
+	// that we got the right one for each tag.
+	for j, img := range imgs {
+		got, err := img.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := want[tags[j]].Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != d {
+			t.Errorf("SaveAll()[%d]: got %s, want %s", j, got, d)
+		}
+		if _, err := img.Layers(); err != nil {
+			t.Errorf("SaveAll()[%d].Layers(): %v", j, err)
+		}
+	}
+	if client.saves != 1 {
+		t.Errorf("saved %d times, want 1", client.saves)
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// WriteIndex saves the images in idx into the daemon as the given tag.
+//
+// Loading every platform requires Docker's containerd image store. With the
+// classic image store, use WithPlatform to load only the image for one
+// platform instead.
+func WriteIndex(tag name.Tag, idx v1.ImageIndex, options ...Option) (string, error) {
+	o, err := makeOptions(options...)
+	if err != nil {
+		return "", err
+	}
+
+	if o.platform != nil {
+		img, err := imageForPlatform(idx, *o.platform)
+		if err != nil {
+			return "", err
+		}
+		return Write(tag, img, options...)
+	}
+
+	entries, err := ociEntries(tag, idx)
+	if err != nil {
+		return "", err
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		var w io.Writer = pw
+		var p *progress
+		if o.updates != nil {
+			p = &progress{updates: o.updates, total: tarSize(entries)}
+			w = &progressWriter{w: pw, p: p}
+		}
+		err := writeOCI(entries, w)
+		if p != nil {
+			p.finish(err)
+		}
+		pw.CloseWithError(err)
+	}()
+
+	// write the index as an OCI image layout tarball, then load it
+	resp, err := o.client.ImageLoad(o.ctx, pr, client.ImageLoadWithQuiet(false))
+	if err != nil {
+		return "", fmt.Errorf("error loading index: %w", err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	response := string(b)
+	if err != nil {
+		return response, fmt.Errorf("error reading load response body: %w", err)
+	}
+	return response, nil
+}
+
+// imageForPlatform returns the image in idx for platform p.
+func imageForPlatform(idx v1.ImageIndex, p v1.Platform) (v1.Image, error) {
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range im.Manifests {
+		if desc.Platform == nil || !desc.Platform.Satisfies(p) {
+			continue
+		}
+		switch {
+		case desc.MediaType.IsImage():
+			return idx.Image(desc.Digest)
+		case desc.MediaType.IsIndex():
+			child, err := idx.ImageIndex(desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+			return imageForPlatform(child, p)
+		}
+	}
+	return nil, fmt.Errorf("no child with platform %s in index", p.String())
+}
+
+// ociEntry is a file in the tarball of an OCI image layout.
+type ociEntry struct {
+	name string
+	size int64
+	open func() (io.ReadCloser, error)
+}
+
+func bytesEntry(name string, b []byte) ociEntry {
+	return ociEntry{
+		name: name,
+		size: int64(len(b)),
+		open: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(b)), nil
+		},
+	}
+}
+
+// ociEntries returns the files in a tarball of an OCI image layout of idx,
+// which is what `docker load` expects for multi-platform images.
+func ociEntries(tag name.Tag, idx v1.ImageIndex) ([]ociEntry, error) {
+	ob := &ociBuilder{written: map[v1.Hash]bool{}}
+	ob.entries = append(ob.entries, bytesEntry("oci-layout", []byte(`{"imageLayoutVersion":"1.0.0"}`)))
+	if err := ob.index(idx); err != nil {
+		return nil, err
+	}
+
+	desc, err := partial.Descriptor(idx)
+	if err != nil {
+		return nil, err
+	}
+	desc.Annotations = map[string]string{
+		// This is how the containerd image store names what it loads.
+		"io.containerd.image.name":          tag.String(),
+		"org.opencontainers.image.ref.name": tag.TagStr(),
+	}
+	b, err := json.Marshal(v1.IndexManifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIImageIndex,
+		Manifests:     []v1.Descriptor{*desc},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(ob.entries, bytesEntry("index.json", b)), nil
+}
+
+// tarSize returns the size of a tarball of entries.
+func tarSize(entries []ociEntry) int64 {
+	const block = 512
+	// Two zero blocks mark the end of the archive.
+	size := int64(2 * block)
+	for _, e := range entries {
+		// Each entry is a header block followed by its padded contents.
+		size += block + (e.size+block-1)/block*block
+	}
+	return size
+}
+
+// writeOCI writes a tarball of entries to w.
+func writeOCI(entries []ociEntry, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	for _, e := range entries {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     e.name,
+			Mode:     0644,
+			Size:     e.size,
+			Typeflag: tar.TypeReg,
+		}); err != nil {
+			return err
+		}
+		rc, err := e.open()
+		if err != nil {
+			return err
+		}
+		n, err := io.Copy(tw, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		if n != e.size {
+			return fmt.Errorf("%s: wrote %d bytes, want %d", e.name, n, e.size)
+		}
+	}
+	return tw.Close()
+}
+
+// ociBuilder collects the blobs of an OCI image layout.
+type ociBuilder struct {
+	entries []ociEntry
+	written map[v1.Hash]bool
+}
+
+func blobPath(h v1.Hash) string {
+	return path.Join("blobs", h.Algorithm, h.Hex)
+}
+
+// blob adds b, unless a blob with the digest h has already been added.
+func (b *ociBuilder) blob(h v1.Hash, raw []byte) {
+	if b.written[h] {
+		return
+	}
+	b.written[h] = true
+	b.entries = append(b.entries, bytesEntry(blobPath(h), raw))
+}
+
+func (b *ociBuilder) index(idx v1.ImageIndex) error {
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return err
+	}
+	for _, desc := range im.Manifests {
+		switch {
+		case desc.MediaType.IsIndex():
+			child, err := idx.ImageIndex(desc.Digest)
+			if err != nil {
+				return err
+			}
+			if err := b.index(child); err != nil {
+				return err
+			}
+		case desc.MediaType.IsImage():
+			img, err := idx.Image(desc.Digest)
+			if err != nil {
+				return err
+			}
+			if err := b.image(img); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unexpected media type for %s: %s", desc.Digest, desc.MediaType)
+		}
+	}
+	h, err := idx.Digest()
+	if err != nil {
+		return err
+	}
+	raw, err := idx.RawManifest()
+	if err != nil {
+		return err
+	}
+	b.blob(h, raw)
+	return nil
+}
+
+func (b *ociBuilder) image(img v1.Image) error {
+	m, err := img.Manifest()
+	if err != nil {
+		return err
+	}
+	cfg, err := img.RawConfigFile()
+	if err != nil {
+		return err
+	}
+	b.blob(m.Config.Digest, cfg)
+
+	for _, desc := range m.Layers {
+		if !desc.MediaType.IsDistributable() || b.written[desc.Digest] {
+			continue
+		}
+		l, err := img.LayerByDigest(desc.Digest)
+		if err != nil {
+			return err
+		}
+		b.written[desc.Digest] = true
+		b.entries = append(b.entries, ociEntry{
+			name: blobPath(desc.Digest),
+			size: desc.Size,
+			open: l.Compressed,
+		})
+	}
+
+	h, err := img.Digest()
+	if err != nil {
+		return err
+	}
+	raw, err := img.RawManifest()
+	if err != nil {
+		return err
+	}
+	b.blob(h, raw)
+	return nil
+}
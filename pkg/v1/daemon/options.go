@@ -20,6 +20,8 @@ import (
 
 	api "github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/internal/dockercontext"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 )
 
 // ImageOption is an alias for Option.
@@ -33,10 +35,15 @@ type options struct {
 	ctx      context.Context
 	client   Client
 	buffered bool
+	podman   bool
+	platform *v1.Platform
+	updates  chan<- v1.Update
+
+	dockerContext string
 }
 
 var defaultClient = func() (Client, error) {
-	return client.NewClientWithOpts(client.FromEnv)
+	return contextClient(dockercontext.Name())
 }
 
 func makeOptions(opts ...Option) (*options, error) {
@@ -49,7 +56,15 @@ func makeOptions(opts ...Option) (*options, error) {
 	}
 
 	if o.client == nil {
-		client, err := defaultClient()
+		newClient := defaultClient
+		if o.podman {
+			newClient = podmanClient
+		} else if o.dockerContext != "" {
+			newClient = func() (Client, error) {
+				return contextClient(o.dockerContext)
+			}
+		}
+		client, err := newClient()
 		if err != nil {
 			return nil, err
 		}
@@ -61,6 +76,9 @@ func makeOptions(opts ...Option) (*options, error) {
 }
 
 // WithBufferedOpener buffers the image.
+//
+// The image is only saved from the daemon once, and spooled to a temporary
+// file as it's read rather than held in memory. This is the default.
 func WithBufferedOpener() Option {
 	return func(o *options) {
 		o.buffered = true
@@ -74,9 +92,35 @@ func WithUnbufferedOpener() Option {
 	}
 }
 
+// WithPlatform is a functional option to make WriteIndex load only the image
+// for platform p, which works with any image store.
+func WithPlatform(p v1.Platform) Option {
+	return func(o *options) {
+		o.platform = &p
+	}
+}
+
+// WithProgress is a functional option to receive updates about the bytes
+// transferred to the daemon by Write and WriteIndex, or from it when Image
+// saves an image.
+//
+// Like tarball.WithProgress, the last update of each transfer has Error set,
+// to io.EOF if it succeeded, and the channel isn't closed. The size of a saved
+// image isn't known in advance, so Total is 0 for those updates.
+//
+// Sending updates to an unbuffered channel will block the transfer, so
+// callers should receive from it concurrently or provide a buffered channel.
+func WithProgress(updates chan<- v1.Update) Option {
+	return func(o *options) {
+		o.updates = updates
+	}
+}
+
 // WithClient is a functional option to allow injecting a docker client.
 //
-// By default, github.com/docker/docker/client.FromEnv is used.
+// By default, a client for the current docker CLI context is used, see
+// WithDockerContext, which is github.com/docker/docker/client.FromEnv unless
+// a context other than "default" is selected.
 func WithClient(client Client) Option {
 	return func(o *options) {
 		o.client = client
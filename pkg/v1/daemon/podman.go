@@ -0,0 +1,51 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/internal/podman"
+)
+
+// WithPodman is a functional option to talk to Podman's Docker-compatible API
+// instead of the docker daemon. The socket is found with PodmanHost.
+//
+// It has no effect if WithClient is also used.
+func WithPodman() Option {
+	return func(o *options) {
+		o.podman = true
+	}
+}
+
+// PodmanHost returns the address of Podman's Docker-compatible API socket.
+//
+// In order, it uses:
+//   - $CONTAINER_HOST, if it's a unix:// address,
+//   - the rootless socket at $XDG_RUNTIME_DIR/podman/podman.sock,
+//   - the rootful socket at /run/podman/podman.sock.
+//
+// The socket is provided by Podman's systemd unit, e.g.
+// `systemctl --user enable --now podman.socket`.
+func PodmanHost() (string, error) {
+	return podman.Host()
+}
+
+var podmanClient = func() (Client, error) {
+	host, err := PodmanHost()
+	if err != nil {
+		return nil, err
+	}
+	return client.NewClientWithOpts(client.WithHost(host))
+}
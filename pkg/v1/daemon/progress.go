@@ -0,0 +1,98 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// progress sends updates about a transfer to or from the daemon, see
+// WithProgress.
+type progress struct {
+	sync.Mutex
+	updates  chan<- v1.Update
+	total    int64
+	complete int64
+	done     bool
+}
+
+func (p *progress) add(n int) {
+	if n == 0 {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	if p.done {
+		return
+	}
+	p.complete += int64(n)
+	p.updates <- v1.Update{
+		Total:    p.total,
+		Complete: p.complete,
+	}
+}
+
+// finish sends the last update, whose Error is io.EOF if err is nil.
+func (p *progress) finish(err error) {
+	p.Lock()
+	defer p.Unlock()
+	if p.done {
+		return
+	}
+	p.done = true
+	if err == nil {
+		err = io.EOF
+	}
+	p.updates <- v1.Update{
+		Total:    p.total,
+		Complete: p.complete,
+		Error:    err,
+	}
+}
+
+type progressWriter struct {
+	w io.Writer
+	p *progress
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.p.add(n)
+	return n, err
+}
+
+type progressReader struct {
+	rc io.ReadCloser
+	p  *progress
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.rc.Read(b)
+	r.p.add(n)
+	if errors.Is(err, io.EOF) {
+		r.p.finish(nil)
+	} else if err != nil {
+		r.p.finish(err)
+	}
+	return n, err
+}
+
+func (r *progressReader) Close() error {
+	return r.rc.Close()
+}
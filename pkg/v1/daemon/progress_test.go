@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+// collect receives updates until the last one, which it returns.
+func collect(t *testing.T, updates <-chan v1.Update) <-chan v1.Update {
+	t.Helper()
+	last := make(chan v1.Update, 1)
+	go func() {
+		var prev int64
+		for u := range updates {
+			if u.Complete < prev {
+				t.Errorf("progress went backwards: %d < %d", u.Complete, prev)
+			}
+			prev = u.Complete
+			if u.Error != nil {
+				last <- u
+				return
+			}
+		}
+	}()
+	return last
+}
+
+func TestWriteProgress(t *testing.T) {
+	img, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag("test_image:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		write func(*MockClient, ...Option) error
+	}{{
+		name: "Write",
+		write: func(c *MockClient, opts ...Option) error {
+			_, err := Write(tag, img, append(opts, WithClient(c))...)
+			return err
+		},
+	}, {
+		name: "WriteIndex",
+		write: func(c *MockClient, opts ...Option) error {
+			idx := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: img})
+			_, err := WriteIndex(tag, idx, append(opts, WithClient(c))...)
+			return err
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var loaded bytes.Buffer
+			client := &MockClient{
+				inspectErr: errors.New("nope"),
+				loadBody:   io.NopCloser(strings.NewReader("Loaded")),
+				loaded:     &loaded,
+			}
+			updates := make(chan v1.Update, 10)
+			last := collect(t, updates)
+			if err := tc.write(client, WithProgress(updates)); err != nil {
+				t.Fatal(err)
+			}
+			u := <-last
+			if !errors.Is(u.Error, io.EOF) {
+				t.Errorf("last update: got error %v, want io.EOF", u.Error)
+			}
+			if want := int64(loaded.Len()); u.Complete != want || u.Total != want {
+				t.Errorf("last update: got %d/%d, want %d/%d", u.Complete, u.Total, want, want)
+			}
+		})
+	}
+
+	t.Run("skip load", func(t *testing.T) {
+		updates := make(chan v1.Update, 1)
+		if _, err := Write(tag, img, WithClient(&MockClient{}), WithProgress(updates)); err != nil {
+			t.Fatal(err)
+		}
+		if u := <-updates; !errors.Is(u.Error, io.EOF) {
+			t.Errorf("got error %v, want io.EOF", u.Error)
+		}
+	})
+}
+
+func TestImageProgress(t *testing.T) {
+	fi, err := os.Stat(imagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates := make(chan v1.Update, 10)
+	last := collect(t, updates)
+	img, err := Image(name.MustParseReference("unused"), WithClient(&MockClient{
+		path:        imagePath,
+		inspectResp: inspectResp,
+	}), WithProgress(updates))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := img.Layers(); err != nil {
+		t.Fatal(err)
+	}
+	u := <-last
+	if !errors.Is(u.Error, io.EOF) {
+		t.Errorf("last update: got error %v, want io.EOF", u.Error)
+	}
+	if u.Complete != fi.Size() {
+		t.Errorf("last update: got %d bytes, want %d", u.Complete, fi.Size())
+	}
+}
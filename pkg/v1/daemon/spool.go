@@ -0,0 +1,240 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// spool copies a tarball into a temporary file as it's needed, indexing its
+// entries as they go by.
+//
+// This means the tarball is only read from the daemon once and is never held
+// in memory, and entries near the front of it can be read before the rest
+// has arrived.
+type spool struct {
+	f *os.File
+
+	mu      sync.Mutex // Protects everything below.
+	src     io.ReadCloser
+	tr      *tar.Reader
+	size    int64 // How much of src is in f.
+	done    bool
+	err     error
+	entries map[string]*tar.Header
+	offsets map[string]int64
+}
+
+func newSpool(src io.ReadCloser) (*spool, error) {
+	f, err := os.CreateTemp("", "daemon-image-*.tar")
+	if err != nil {
+		return nil, err
+	}
+	s := &spool{
+		f:       f,
+		src:     src,
+		entries: map[string]*tar.Header{},
+		offsets: map[string]int64{},
+	}
+	s.tr = tar.NewReader(io.TeeReader(src, writerFunc(s.write)))
+
+	// Unlink the file right away where we can, so nothing is left behind,
+	// otherwise (i.e. on Windows) remove it once we're unreachable.
+	removed := os.Remove(f.Name()) == nil
+	runtime.SetFinalizer(s, func(s *spool) {
+		s.f.Close()
+		if !removed {
+			os.Remove(s.f.Name())
+		}
+	})
+	return s, nil
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+func (s *spool) write(p []byte) (int, error) {
+	n, err := s.f.WriteAt(p, s.size)
+	s.size += int64(n)
+	return n, err
+}
+
+// next spools the next entry of the tarball, including the contents of the
+// previous one. The caller must hold s.mu.
+func (s *spool) next() {
+	hdr, err := s.tr.Next()
+	if errors.Is(err, io.EOF) {
+		// Keep any padding after the end of the archive, so that the spooled
+		// tarball is identical to the original.
+		_, err = io.Copy(io.Discard, io.TeeReader(s.src, writerFunc(s.write)))
+		s.finish(err)
+		return
+	} else if err != nil {
+		s.finish(err)
+		return
+	}
+	// The reader stops right after the header, so this is where the contents
+	// of the entry will be.
+	name := path.Clean(hdr.Name)
+	if _, ok := s.entries[name]; !ok {
+		s.entries[name] = hdr
+		s.offsets[name] = s.size
+	}
+}
+
+func (s *spool) finish(err error) {
+	s.done = true
+	s.err = err
+	s.src.Close()
+}
+
+// fill spools the tarball until it has at least n bytes, or there are no more.
+func (s *spool) fill(n int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.size < n && !s.done {
+		s.next()
+	}
+	if s.size < n {
+		return s.err
+	}
+	return nil
+}
+
+// available returns how much of the tarball has been spooled.
+func (s *spool) available() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// open returns a reader for the whole tarball, which can seek over parts of
+// it that have already been spooled. It implements tarball.Opener.
+func (s *spool) open() (io.ReadCloser, error) {
+	return &spoolReader{s: s}, nil
+}
+
+// file returns a reader for the contents of the first entry found with any of
+// the given names, spooling only as much of the tarball as is necessary to
+// find it.
+func (s *spool) file(names ...string) (io.ReadCloser, error) {
+	name, hdr, off, err := s.lookup(names)
+	if err != nil {
+		return nil, err
+	}
+	switch hdr.Typeflag {
+	case tar.TypeSymlink:
+		return s.file(path.Join(path.Dir(name), hdr.Linkname))
+	case tar.TypeLink:
+		return s.file(hdr.Linkname)
+	}
+	if err := s.fill(off + hdr.Size); err != nil {
+		return nil, err
+	}
+	if s.available() < off+hdr.Size {
+		return nil, fmt.Errorf("file %s truncated in tar", name)
+	}
+	return spoolFile{io.NewSectionReader(s.f, off, hdr.Size), s}, nil
+}
+
+func (s *spool) lookup(names []string) (string, *tar.Header, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		for _, name := range names {
+			name = path.Clean(name)
+			if hdr, ok := s.entries[name]; ok {
+				return name, hdr, s.offsets[name], nil
+			}
+		}
+		if s.done {
+			if s.err != nil {
+				return "", nil, 0, s.err
+			}
+			return "", nil, 0, fmt.Errorf("file %s not found in tar", strings.Join(names, " or "))
+		}
+		s.next()
+	}
+}
+
+// spoolFile keeps the spool, and so its file, alive while it's being read.
+type spoolFile struct {
+	*io.SectionReader
+	s *spool
+}
+
+func (spoolFile) Close() error {
+	return nil
+}
+
+// spoolReader reads the tarball from a spool, waiting for it to be spooled.
+type spoolReader struct {
+	s   *spool
+	pos int64
+}
+
+func (r *spoolReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := r.s.fill(r.pos + 1); err != nil {
+		return 0, err
+	}
+	avail := r.s.available() - r.pos
+	if avail <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > avail {
+		p = p[:avail]
+	}
+	n, err := r.s.f.ReadAt(p, r.pos)
+	r.pos += int64(n)
+	if errors.Is(err, io.EOF) && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Seek lets archive/tar skip over the contents of entries without reading
+// them, but they're still spooled.
+func (r *spoolReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	default:
+		return 0, fmt.Errorf("unsupported whence: %d", whence)
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.pos = offset
+	return r.pos, nil
+}
+
+func (r *spoolReader) Close() error {
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+// countingReader counts how much of a tarball has been read.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) Close() error {
+	return nil
+}
+
+func TestSpoolFile(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range []struct {
+		name string
+		body []byte
+	}{
+		{"config.json", []byte(`{"config":true}`)},
+		{"layer.tar", bytes.Repeat([]byte("x"), 1<<20)},
+		{"link.json", nil},
+		{"manifest.json", []byte(`[]`)},
+	} {
+		hdr := &tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.body))}
+		if f.body == nil {
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = "config.json"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	total := int64(buf.Len())
+
+	src := &countingReader{r: bytes.NewReader(buf.Bytes())}
+	s, err := newSpool(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err := s.file("missing.json", "config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := io.ReadAll(rc); err != nil {
+		t.Fatal(err)
+	} else if string(b) != `{"config":true}` {
+		t.Errorf("config.json: got %q", b)
+	}
+	if src.n >= total/2 {
+		t.Errorf("read %d of %d bytes to find the first file", src.n, total)
+	}
+
+	rc, err = s.file("./link.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := io.ReadAll(rc); err != nil {
+		t.Fatal(err)
+	} else if string(b) != `{"config":true}` {
+		t.Errorf("link.json: got %q", b)
+	}
+
+	if _, err := s.file("missing.json"); err == nil {
+		t.Error("file(missing.json): want error")
+	}
+	if src.n != total {
+		t.Errorf("read %d of %d bytes", src.n, total)
+	}
+
+	// The spooled tarball is identical to the original.
+	rc, err = s.open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := io.ReadAll(rc); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(b, buf.Bytes()) {
+		t.Error("spooled tarball differs from the original")
+	}
+}
+
+func TestSpoolOpener(t *testing.T) {
+	img, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag := name.MustParseReference("example.com/spool:latest").(name.Tag)
+	var buf bytes.Buffer
+	if err := tarball.Write(tag, img, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	src := &countingReader{r: bytes.NewReader(buf.Bytes())}
+	s, err := newSpool(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := tarball.Image(s.open, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Image(got); err != nil {
+		t.Errorf("validate.Image: %v", err)
+	}
+	if src.n != int64(buf.Len()) {
+		t.Errorf("read %d bytes, want the tarball to be read once (%d bytes)", src.n, buf.Len())
+	}
+}
@@ -52,16 +52,20 @@ func Write(tag name.Tag, img v1.Image, options ...Option) (string, error) {
 		// If we already have this tag, we can skip tagging it.
 		for _, have := range resp.RepoTags {
 			if have == want {
-				return "", nil
+				return "", skipped(o, nil)
 			}
 		}
 
-		return "", o.client.ImageTag(o.ctx, id.String(), want)
+		return "", skipped(o, o.client.ImageTag(o.ctx, id.String(), want))
 	}
 
+	var topts []tarball.WriteOption
+	if o.updates != nil {
+		topts = append(topts, tarball.WithProgress(o.updates))
+	}
 	pr, pw := io.Pipe()
 	go func() {
-		pw.CloseWithError(tarball.Write(tag, img, pw))
+		pw.CloseWithError(tarball.Write(tag, img, pw, topts...))
 	}()
 
 	// write the image in docker save format first, then load it
@@ -77,3 +81,12 @@ func Write(tag name.Tag, img v1.Image, options ...Option) (string, error) {
 	}
 	return response, nil
 }
+
+// skipped sends the only progress update, if WithProgress is used, when
+// nothing needed to be loaded.
+func skipped(o *options, err error) error {
+	if o.updates != nil {
+		(&progress{updates: o.updates}).finish(err)
+	}
+	return err
+}
@@ -15,10 +15,14 @@
 package daemon
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -26,8 +30,14 @@ import (
 	"github.com/docker/docker/client"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/compare"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
 )
 
 type errReader struct {
@@ -46,7 +56,11 @@ func (m *MockClient) ImageLoad(ctx context.Context, r io.Reader, _ ...client.Ima
 		return api.LoadResponse{}, fmt.Errorf("ImageLoad: wrong context")
 	}
 
-	_, _ = io.Copy(io.Discard, r)
+	w := m.loaded
+	if w == nil {
+		w = io.Discard
+	}
+	_, _ = io.Copy(w, r)
 	return api.LoadResponse{
 		Body: m.loadBody,
 	}, m.loadErr
@@ -167,3 +181,120 @@ func TestWriteDefaultClient(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func platformImage(t *testing.T, arch string) v1.Image {
+	t.Helper()
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf = cf.DeepCopy()
+	cf.OS = "linux"
+	cf.Architecture = arch
+	img, err = mutate.ConfigFile(img, cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestWriteIndex(t *testing.T) {
+	amd64 := platformImage(t, "amd64")
+	arm64 := platformImage(t, "arm64")
+	idx := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{
+		Add:        amd64,
+		Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}},
+	}, mutate.IndexAddendum{
+		Add:        arm64,
+		Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}},
+	})
+	tag, err := name.NewTag("test_index:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("all platforms", func(t *testing.T) {
+		var loaded bytes.Buffer
+		client := &MockClient{
+			loadBody: io.NopCloser(strings.NewReader("Loaded")),
+			loaded:   &loaded,
+		}
+		if _, err := WriteIndex(tag, idx, WithClient(client)); err != nil {
+			t.Fatalf("WriteIndex: %v", err)
+		}
+
+		// The loaded tarball is an OCI image layout.
+		dir := t.TempDir()
+		tr := tar.NewReader(&loaded)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			p := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+			if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+				t.Fatal(err)
+			}
+			b, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(p, b, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		l, err := layout.ImageIndexFromPath(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		im, err := l.IndexManifest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(im.Manifests) != 1 {
+			t.Fatalf("index.json has %d manifests, want 1", len(im.Manifests))
+		}
+		desc := im.Manifests[0]
+		if got, want := desc.Annotations["io.containerd.image.name"], tag.String(); got != want {
+			t.Errorf("image name: got %q, want %q", got, want)
+		}
+		got, err := l.ImageIndex(desc.Digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := validate.Index(got); err != nil {
+			t.Errorf("validate.Index: %v", err)
+		}
+	})
+
+	t.Run("one platform", func(t *testing.T) {
+		var loaded bytes.Buffer
+		client := &MockClient{
+			inspectErr: errors.New("nope"),
+			loadBody:   io.NopCloser(strings.NewReader("Loaded")),
+			loaded:     &loaded,
+		}
+		if _, err := WriteIndex(tag, idx, WithClient(client), WithPlatform(v1.Platform{OS: "linux", Architecture: "arm64"})); err != nil {
+			t.Fatalf("WriteIndex: %v", err)
+		}
+		got, err := tarball.Image(func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(loaded.Bytes())), nil
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := compare.Images(got, arm64); err != nil {
+			t.Errorf("loaded the wrong image: %v", err)
+		}
+
+		if _, err := WriteIndex(tag, idx, WithClient(client), WithPlatform(v1.Platform{OS: "windows", Architecture: "amd64"})); err == nil {
+			t.Error("WriteIndex: want error for missing platform")
+		}
+	})
+}
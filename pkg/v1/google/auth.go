@@ -30,6 +30,10 @@ import (
 
 const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
 
+// refreshEarly is how long before a token expires that it is replaced, so
+// that a token isn't handed out only to expire during a long request.
+const refreshEarly = 5 * time.Minute
+
 // GetGcloudCmd is exposed so we can test this.
 var GetGcloudCmd = func(ctx context.Context) *exec.Cmd {
 	// This is odd, but basically what docker-credential-gcr does.
@@ -57,7 +61,7 @@ func NewEnvAuthenticator(ctx context.Context) (authn.Authenticator, error) {
 		return nil, err
 	}
 
-	return &tokenSourceAuth{oauth2.ReuseTokenSource(token, ts)}, nil
+	return newRefreshingAuth(token, ts), nil
 }
 
 // NewGcloudAuthenticator returns an oauth2.TokenSource that generates access
@@ -77,7 +81,7 @@ func NewGcloudAuthenticator(ctx context.Context) (authn.Authenticator, error) {
 		return nil, err
 	}
 
-	return &tokenSourceAuth{oauth2.ReuseTokenSource(token, ts)}, nil
+	return newRefreshingAuth(token, ts), nil
 }
 
 // NewJSONKeyAuthenticator returns a Basic authenticator which uses Service Account
@@ -99,7 +103,7 @@ func NewTokenAuthenticator(serviceAccountJSON string, scope string) (authn.Authe
 		return nil, err
 	}
 
-	return &tokenSourceAuth{oauth2.ReuseTokenSource(nil, ts)}, nil
+	return newRefreshingAuth(nil, ts), nil
 }
 
 // NewTokenSourceAuthenticator converts an oauth2.TokenSource into an authn.Authenticator.
@@ -107,6 +111,13 @@ func NewTokenSourceAuthenticator(ts oauth2.TokenSource) authn.Authenticator {
 	return &tokenSourceAuth{ts}
 }
 
+// newRefreshingAuth returns a tokenSourceAuth that reuses token, then tokens
+// from ts, until shortly before each expires, so that long-running operations
+// keep working after the first token expires.
+func newRefreshingAuth(token *oauth2.Token, ts oauth2.TokenSource) *tokenSourceAuth {
+	return &tokenSourceAuth{oauth2.ReuseTokenSourceWithExpiry(token, ts, refreshEarly)}
+}
+
 // tokenSourceAuth turns an oauth2.TokenSource into an authn.Authenticator.
 type tokenSourceAuth struct {
 	oauth2.TokenSource
@@ -25,6 +25,7 @@ import (
 	"os/exec"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/logs"
@@ -271,3 +272,46 @@ func TestNewEnvAuthenticatorFailure(t *testing.T) {
 		t.Errorf("expected err, got nil")
 	}
 }
+
+// countingSource mints a new token, valid for ttl, on every call.
+type countingSource struct {
+	n   int
+	ttl time.Duration
+}
+
+func (cs *countingSource) Token() (*oauth2.Token, error) {
+	cs.n++
+	return &oauth2.Token{
+		AccessToken: fmt.Sprintf("token-%d", cs.n),
+		Expiry:      time.Now().Add(cs.ttl),
+	}, nil
+}
+
+func TestRefreshingAuth(t *testing.T) {
+	for _, tc := range []struct {
+		ttl  time.Duration
+		want string
+	}{{
+		// Plenty of time left, so the first token is reused.
+		ttl:  time.Hour,
+		want: "token-1",
+	}, {
+		// Close enough to expiry that a new token is minted.
+		ttl:  refreshEarly / 2,
+		want: "token-2",
+	}} {
+		ts := &countingSource{ttl: tc.ttl}
+		token, err := ts.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		auth := newRefreshingAuth(token, ts)
+		cfg, err := auth.Authorization()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Password != tc.want {
+			t.Errorf("ttl %s: got %q, want %q", tc.ttl, cfg.Password, tc.want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package google
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"golang.org/x/oauth2"
+	googauth "golang.org/x/oauth2/google"
+)
+
+// iamCredentialsEndpoint is a variable so we can test this.
+var iamCredentialsEndpoint = "https://iamcredentials.googleapis.com"
+
+// impersonationEnvVars name the environment variables that the keychain reads
+// to decide which service account to impersonate, in order of precedence.
+//
+// Their value is a comma-separated list of service account emails where the
+// last entry is the account to impersonate and any preceding entries are
+// delegates, matching gcloud's --impersonate-service-account flag.
+var impersonationEnvVars = []string{
+	"GOOGLE_IMPERSONATE_SERVICE_ACCOUNT",
+	"CLOUDSDK_AUTH_IMPERSONATE_SERVICE_ACCOUNT",
+}
+
+// NewCredentialsAuthenticator returns an authn.Authenticator that generates
+// access tokens from the given credentials JSON.
+//
+// In addition to service account keys, this supports external account
+// credentials (workload identity federation), like those produced by
+// google-github-actions/auth, and impersonated service account credentials.
+//
+// See: https://google.aip.dev/auth/4117
+func NewCredentialsAuthenticator(ctx context.Context, credentialsJSON []byte) (authn.Authenticator, error) {
+	creds, err := googauth.CredentialsFromJSON(ctx, credentialsJSON, cloudPlatformScope)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRefreshingAuth(nil, creds.TokenSource), nil
+}
+
+// NewImpersonatedAuthenticator returns an authn.Authenticator that uses the
+// credentials from base to generate access tokens for the target service
+// account, via the chain of delegates, if any.
+//
+// See: https://cloud.google.com/iam/docs/create-short-lived-credentials-delegated
+func NewImpersonatedAuthenticator(ctx context.Context, base oauth2.TokenSource, target string, delegates ...string) (authn.Authenticator, error) {
+	if target == "" {
+		return nil, errors.New("no service account to impersonate")
+	}
+	ts := &impersonatedSource{
+		ctx:       ctx,
+		base:      base,
+		target:    target,
+		delegates: delegates,
+	}
+
+	// Attempt to fetch a token to ensure we are allowed to impersonate target.
+	token, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	return newRefreshingAuth(token, ts), nil
+}
+
+// impersonationChain returns the service account to impersonate and its
+// delegates, as configured in the environment.
+func impersonationChain() (string, []string) {
+	for _, env := range impersonationEnvVars {
+		v := os.Getenv(env)
+		if v == "" {
+			continue
+		}
+		chain := []string{}
+		for _, sa := range strings.Split(v, ",") {
+			if sa = strings.TrimSpace(sa); sa != "" {
+				chain = append(chain, sa)
+			}
+		}
+		if len(chain) == 0 {
+			continue
+		}
+		return chain[len(chain)-1], chain[:len(chain)-1]
+	}
+	return "", nil
+}
+
+type impersonatedSource struct {
+	ctx       context.Context
+	base      oauth2.TokenSource
+	target    string
+	delegates []string
+}
+
+type generateAccessTokenRequest struct {
+	Delegates []string `json:"delegates,omitempty"`
+	Scope     []string `json:"scope"`
+	Lifetime  string   `json:"lifetime,omitempty"`
+}
+
+type generateAccessTokenResponse struct {
+	AccessToken string `json:"accessToken"`
+	ExpireTime  string `json:"expireTime"`
+}
+
+// Token implements oauth2.TokenSource.
+func (is *impersonatedSource) Token() (*oauth2.Token, error) {
+	delegates := make([]string, 0, len(is.delegates))
+	for _, d := range is.delegates {
+		delegates = append(delegates, serviceAccountName(d))
+	}
+	body, err := json.Marshal(generateAccessTokenRequest{
+		Delegates: delegates,
+		Scope:     []string{cloudPlatformScope},
+		Lifetime:  "3600s",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s/v1/%s:generateAccessToken", iamCredentialsEndpoint, serviceAccountName(is.target))
+	req, err := http.NewRequestWithContext(is.ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := oauth2.NewClient(is.ctx, is.base)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("impersonating %s: %w", is.target, err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("impersonating %s: unexpected status code %d: %s", is.target, resp.StatusCode, b)
+	}
+
+	var out generateAccessTokenResponse
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("impersonating %s: %w", is.target, err)
+	}
+	expiry, err := time.Parse(time.RFC3339, out.ExpireTime)
+	if err != nil {
+		return nil, fmt.Errorf("impersonating %s: failed to parse expiry: %w", is.target, err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: out.AccessToken,
+		Expiry:      expiry,
+	}, nil
+}
+
+func serviceAccountName(sa string) string {
+	if strings.HasPrefix(sa, "projects/") {
+		return sa
+	}
+	return "projects/-/serviceAccounts/" + sa
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestImpersonatedAuthenticator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Path, "/v1/projects/-/serviceAccounts/target@example.iam.gserviceaccount.com:generateAccessToken"; got != want {
+			t.Errorf("path = %q, want %q", got, want)
+		}
+		if got, want := r.Header.Get("Authorization"), "Bearer base-token"; got != want {
+			t.Errorf("Authorization = %q, want %q", got, want)
+		}
+		var req generateAccessTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"projects/-/serviceAccounts/delegate@example.iam.gserviceaccount.com"}; !reflect.DeepEqual(req.Delegates, want) {
+			t.Errorf("delegates = %v, want %v", req.Delegates, want)
+		}
+		fmt.Fprintf(w, `{"accessToken": "impersonated-token", "expireTime": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	old := iamCredentialsEndpoint
+	iamCredentialsEndpoint = server.URL
+	defer func() { iamCredentialsEndpoint = old }()
+
+	base := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "base-token"})
+	auth, err := NewImpersonatedAuthenticator(context.Background(), base, "target@example.iam.gserviceaccount.com", "delegate@example.iam.gserviceaccount.com")
+	if err != nil {
+		t.Fatalf("NewImpersonatedAuthenticator: %v", err)
+	}
+	cfg, err := auth.Authorization()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cfg.Password, "impersonated-token"; got != want {
+		t.Errorf("Password = %q, want %q", got, want)
+	}
+	if got, want := cfg.Username, "_token"; got != want {
+		t.Errorf("Username = %q, want %q", got, want)
+	}
+}
+
+func TestImpersonatedAuthenticatorError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "permission denied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	old := iamCredentialsEndpoint
+	iamCredentialsEndpoint = server.URL
+	defer func() { iamCredentialsEndpoint = old }()
+
+	base := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "base-token"})
+	if _, err := NewImpersonatedAuthenticator(context.Background(), base, "target@example.iam.gserviceaccount.com"); err == nil {
+		t.Error("expected err, got nil")
+	}
+}
+
+func TestImpersonationChain(t *testing.T) {
+	for _, tc := range []struct {
+		google, cloudsdk string
+		wantTarget       string
+		wantDelegates    []string
+	}{{
+		wantDelegates: nil,
+	}, {
+		google:        "a@example.com",
+		wantTarget:    "a@example.com",
+		wantDelegates: []string{},
+	}, {
+		cloudsdk:      "a@example.com, b@example.com,c@example.com",
+		wantTarget:    "c@example.com",
+		wantDelegates: []string{"a@example.com", "b@example.com"},
+	}, {
+		google:        "a@example.com",
+		cloudsdk:      "b@example.com",
+		wantTarget:    "a@example.com",
+		wantDelegates: []string{},
+	}} {
+		t.Setenv("GOOGLE_IMPERSONATE_SERVICE_ACCOUNT", tc.google)
+		t.Setenv("CLOUDSDK_AUTH_IMPERSONATE_SERVICE_ACCOUNT", tc.cloudsdk)
+		target, delegates := impersonationChain()
+		if target != tc.wantTarget {
+			t.Errorf("target = %q, want %q", target, tc.wantTarget)
+		}
+		if !reflect.DeepEqual(delegates, tc.wantDelegates) {
+			t.Errorf("delegates = %v, want %v", delegates, tc.wantDelegates)
+		}
+	}
+}
+
+func TestNewCredentialsAuthenticatorError(t *testing.T) {
+	if _, err := NewCredentialsAuthenticator(context.Background(), []byte(`{"type": "nonsense"}`)); err == nil {
+		t.Error("expected err, got nil")
+	}
+}
@@ -34,7 +34,8 @@ type googleKeychain struct {
 // Resolve implements authn.Keychain a la docker-credential-gcr.
 //
 // This behaves similarly to the GCR credential helper, but reuses tokens until
-// they expire.
+// shortly before they expire, then mints new ones, so that long-running
+// operations outlive any single token.
 //
 // We can't easily add this behavior to our credential helper implementation
 // of authn.Authenticator because the credential helper protocol doesn't include
@@ -64,7 +65,9 @@ func (gk *googleKeychain) ResolveContext(ctx context.Context, target authn.Resou
 	}
 
 	gk.once.Do(func() {
-		gk.auth = resolve(ctx)
+		// The authenticator outlives this call and mints new tokens as old
+		// ones expire, so it mustn't be canceled along with ctx.
+		gk.auth = resolve(context.WithoutCancel(ctx))
 	})
 
 	return gk.auth, nil
@@ -74,13 +77,13 @@ func resolve(ctx context.Context) authn.Authenticator {
 	auth, envErr := NewEnvAuthenticator(ctx)
 	if envErr == nil && auth != authn.Anonymous {
 		logs.Debug.Println("google.Keychain: using Application Default Credentials")
-		return auth
+		return impersonate(ctx, auth)
 	}
 
 	auth, gErr := NewGcloudAuthenticator(ctx)
 	if gErr == nil && auth != authn.Anonymous {
 		logs.Debug.Println("google.Keychain: using gcloud fallback")
-		return auth
+		return impersonate(ctx, auth)
 	}
 
 	logs.Debug.Println("Failed to get any Google credentials, falling back to Anonymous")
@@ -93,6 +96,26 @@ func resolve(ctx context.Context) authn.Authenticator {
 	return authn.Anonymous
 }
 
+// impersonate wraps auth to impersonate the service account chain configured
+// in the environment, if any.
+func impersonate(ctx context.Context, auth authn.Authenticator) authn.Authenticator {
+	target, delegates := impersonationChain()
+	if target == "" {
+		return auth
+	}
+	tsa, ok := auth.(*tokenSourceAuth)
+	if !ok {
+		return auth
+	}
+	impersonated, err := NewImpersonatedAuthenticator(ctx, tsa.TokenSource, target, delegates...)
+	if err != nil {
+		logs.Warn.Printf("google.Keychain: failed to impersonate %s: %v", target, err)
+		return authn.Anonymous
+	}
+	logs.Debug.Printf("google.Keychain: impersonating %s", target)
+	return impersonated
+}
+
 func isGoogle(host string) bool {
 	return host == "gcr.io" ||
 		strings.HasSuffix(host, ".gcr.io") ||
@@ -18,8 +18,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -41,6 +44,13 @@ type lister struct {
 	client    *http.Client
 	ctx       context.Context
 	userAgent string
+
+	// Filters, see options.go.
+	tagRegexp      *regexp.Regexp
+	uploadedBefore time.Time
+	uploadedAfter  time.Time
+	digestPrefix   string
+	manifestFn     ManifestFunc
 }
 
 func newLister(repo name.Repository, options ...Option) (*lister, error) {
@@ -126,8 +136,9 @@ func (l *lister) list(repo name.Repository) (*Tags, error) {
 			return nil, err
 		}
 
-		parsed := Tags{}
-		if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		parsed, gcr, err := l.decode(repo, resp.Body)
+		if err != nil {
+			resp.Body.Close()
 			return nil, err
 		}
 
@@ -135,9 +146,9 @@ func (l *lister) list(repo name.Repository) (*Tags, error) {
 			return nil, err
 		}
 
-		if len(parsed.Manifests) != 0 || len(parsed.Children) != 0 {
+		if gcr {
 			// We're dealing with GCR, just return directly.
-			return &parsed, nil
+			return parsed, nil
 		}
 
 		// This isn't GCR, just append the tags and keep paginating.
@@ -157,6 +168,143 @@ func (l *lister) list(repo name.Repository) (*Tags, error) {
 	return &tags, nil
 }
 
+// decode parses a tags/list response from r, filtering manifests and tags as
+// they are read, so that manifests that don't match are never retained. It
+// returns true if the response is from GCR, i.e. it lists manifests or
+// children.
+func (l *lister) decode(repo name.Repository, r io.Reader) (*Tags, bool, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, false, err
+	}
+
+	tags := &Tags{}
+	gcr := false
+	// The tags of the manifests that matched.
+	matched := map[string]struct{}{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, false, err
+		}
+		switch tok {
+		case "manifest":
+			if err := expectDelim(dec, '{'); err != nil {
+				return nil, false, err
+			}
+			if l.manifestFn == nil {
+				tags.Manifests = map[string]ManifestInfo{}
+			}
+			for dec.More() {
+				gcr = true
+				tok, err := dec.Token()
+				if err != nil {
+					return nil, false, err
+				}
+				digest, ok := tok.(string)
+				if !ok {
+					return nil, false, fmt.Errorf("unexpected manifest key: %v", tok)
+				}
+				var info ManifestInfo
+				if err := dec.Decode(&info); err != nil {
+					return nil, false, err
+				}
+				if !l.matchManifest(digest, info) {
+					continue
+				}
+				for _, t := range info.Tags {
+					matched[t] = struct{}{}
+				}
+				if l.manifestFn != nil {
+					if err := l.manifestFn(repo, digest, info); err != nil {
+						return nil, false, err
+					}
+					continue
+				}
+				tags.Manifests[digest] = info
+			}
+			if err := expectDelim(dec, '}'); err != nil {
+				return nil, false, err
+			}
+		case "child":
+			if err := dec.Decode(&tags.Children); err != nil {
+				return nil, false, err
+			}
+			gcr = gcr || len(tags.Children) != 0
+		case "name":
+			if err := dec.Decode(&tags.Name); err != nil {
+				return nil, false, err
+			}
+		case "tags":
+			if err := dec.Decode(&tags.Tags); err != nil {
+				return nil, false, err
+			}
+		default:
+			var ignored json.RawMessage
+			if err := dec.Decode(&ignored); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, false, err
+	}
+
+	tags.Tags = l.filterTags(tags.Tags, gcr, matched)
+	return tags, gcr, nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if got, ok := tok.(json.Delim); !ok || got != want {
+		return fmt.Errorf("unexpected token %v, expected %v", tok, want)
+	}
+	return nil
+}
+
+// filtersManifests returns true if any filters need manifest information,
+// which only GCR provides.
+func (l *lister) filtersManifests() bool {
+	return l.digestPrefix != "" || !l.uploadedBefore.IsZero() || !l.uploadedAfter.IsZero()
+}
+
+func (l *lister) matchManifest(digest string, info ManifestInfo) bool {
+	if !strings.HasPrefix(digest, l.digestPrefix) {
+		return false
+	}
+	if !l.uploadedBefore.IsZero() && !info.Uploaded.Before(l.uploadedBefore) {
+		return false
+	}
+	if !l.uploadedAfter.IsZero() && !info.Uploaded.After(l.uploadedAfter) {
+		return false
+	}
+	if l.tagRegexp != nil {
+		return slices.ContainsFunc(info.Tags, l.tagRegexp.MatchString)
+	}
+	return true
+}
+
+// filterTags returns the tags that match the tag filter and, for GCR, belong
+// to a manifest that matched.
+func (l *lister) filterTags(tags []string, gcr bool, matched map[string]struct{}) []string {
+	if l.tagRegexp == nil && !(gcr && l.filtersManifests()) {
+		return tags
+	}
+	return slices.DeleteFunc(tags, func(t string) bool {
+		if l.tagRegexp != nil && !l.tagRegexp.MatchString(t) {
+			return true
+		}
+		if gcr && l.filtersManifests() {
+			_, ok := matched[t]
+			return !ok
+		}
+		return false
+	})
+}
+
 // getNextPageURL checks if there is a Link header in a http.Response which
 // contains a link to the next page. If yes it returns the url.URL of the next
 // page otherwise it returns nil.
@@ -271,6 +419,9 @@ type Tags struct {
 }
 
 // List calls /tags/list for the given repository.
+//
+// The filter options, e.g. WithTagRegexp, are applied as the response is
+// read, so manifests that don't match are never kept in memory.
 func List(repo name.Repository, options ...Option) (*Tags, error) {
 	l, err := newLister(repo, options...)
 	if err != nil {
@@ -22,6 +22,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -266,6 +268,101 @@ func TestWalk(t *testing.T) {
 }
 
 // Copied shamelessly from remote.
+func TestListFilters(t *testing.T) {
+	body := []byte(`{"manifest":{` +
+		`"sha256:aaa":{"timeUploadedMs":"1000","tag":["v1","latest"]},` +
+		`"sha256:abb":{"timeUploadedMs":"2000","tag":["v2"]},` +
+		`"sha256:bbb":{"timeUploadedMs":"3000","tag":[]}` +
+		`},"tags":["v1","latest","v2"]}`)
+
+	repoName := "ubuntu"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case fmt.Sprintf("/v2/%s/tags/list", repoName):
+			w.Write(body)
+		default:
+			t.Fatalf("Unexpected path: %v", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse(%v) = %v", server.URL, err)
+	}
+	repo, err := name.NewRepository(fmt.Sprintf("%s/%s", u.Host, repoName), name.WeakValidation)
+	if err != nil {
+		t.Fatalf("name.NewRepository(%v) = %v", repoName, err)
+	}
+
+	for _, tc := range []struct {
+		name          string
+		options       []Option
+		wantManifests []string
+		wantTags      []string
+	}{{
+		name:          "tag regexp",
+		options:       []Option{WithTagRegexp(regexp.MustCompile(`^v\d+$`))},
+		wantManifests: []string{"sha256:aaa", "sha256:abb"},
+		wantTags:      []string{"v1", "v2"},
+	}, {
+		name:          "uploaded before",
+		options:       []Option{WithUploadedBefore(time.UnixMilli(2500))},
+		wantManifests: []string{"sha256:aaa", "sha256:abb"},
+		wantTags:      []string{"v1", "latest", "v2"},
+	}, {
+		name:          "uploaded after",
+		options:       []Option{WithUploadedAfter(time.UnixMilli(1500))},
+		wantManifests: []string{"sha256:abb", "sha256:bbb"},
+		wantTags:      []string{"v2"},
+	}, {
+		name:          "digest prefix",
+		options:       []Option{WithDigestPrefix("sha256:a")},
+		wantManifests: []string{"sha256:aaa", "sha256:abb"},
+		wantTags:      []string{"v1", "latest", "v2"},
+	}, {
+		name:          "combined",
+		options:       []Option{WithDigestPrefix("sha256:a"), WithUploadedAfter(time.UnixMilli(1500))},
+		wantManifests: []string{"sha256:abb"},
+		wantTags:      []string{"v2"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tags, err := List(repo, tc.options...)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			got := []string{}
+			for digest := range tags.Manifests {
+				got = append(got, digest)
+			}
+			sort.Strings(got)
+			if diff := cmp.Diff(tc.wantManifests, got); diff != "" {
+				t.Errorf("List() wrong manifests (-want +got) = %s", diff)
+			}
+			if diff := cmp.Diff(tc.wantTags, tags.Tags); diff != "" {
+				t.Errorf("List() wrong tags (-want +got) = %s", diff)
+			}
+
+			// The same manifests are streamed to a ManifestFunc instead.
+			streamed := []string{}
+			tags, err = List(repo, append(tc.options, WithManifestFunc(func(_ name.Repository, digest string, _ ManifestInfo) error {
+				streamed = append(streamed, digest)
+				return nil
+			}))...)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(tags.Manifests) != 0 {
+				t.Errorf("List() with ManifestFunc kept %d manifests", len(tags.Manifests))
+			}
+			if diff := cmp.Diff(tc.wantManifests, streamed); diff != "" {
+				t.Errorf("ManifestFunc wrong manifests (-want +got) = %s", diff)
+			}
+		})
+	}
+}
+
 func TestCancelledList(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -17,8 +17,11 @@ package google
 import (
 	"context"
 	"net/http"
+	"regexp"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
 )
 
 // WithTransport is a functional option for overriding the default transport
@@ -71,3 +74,62 @@ func WithUserAgent(ua string) Option {
 		return nil
 	}
 }
+
+// WithTagRegexp is a functional option that only lists tags matching re, and
+// only manifests with at least one such tag.
+func WithTagRegexp(re *regexp.Regexp) Option {
+	return func(l *lister) error {
+		l.tagRegexp = re
+		return nil
+	}
+}
+
+// WithUploadedBefore is a functional option that only lists manifests, and
+// their tags, uploaded before t.
+//
+// Only GCR reports when manifests were uploaded; for other registries, this
+// has no effect.
+func WithUploadedBefore(t time.Time) Option {
+	return func(l *lister) error {
+		l.uploadedBefore = t
+		return nil
+	}
+}
+
+// WithUploadedAfter is a functional option that only lists manifests, and
+// their tags, uploaded after t.
+//
+// Only GCR reports when manifests were uploaded; for other registries, this
+// has no effect.
+func WithUploadedAfter(t time.Time) Option {
+	return func(l *lister) error {
+		l.uploadedAfter = t
+		return nil
+	}
+}
+
+// WithDigestPrefix is a functional option that only lists manifests, and
+// their tags, whose digest starts with prefix, e.g. "sha256:abc".
+//
+// Only GCR lists manifests; for other registries, this has no effect.
+func WithDigestPrefix(prefix string) Option {
+	return func(l *lister) error {
+		l.digestPrefix = prefix
+		return nil
+	}
+}
+
+// ManifestFunc is the type of the function called for each manifest listed
+// when using WithManifestFunc. Returning an error stops the listing.
+type ManifestFunc func(repo name.Repository, digest string, info ManifestInfo) error
+
+// WithManifestFunc is a functional option that calls fn for each manifest as
+// it is read, after filtering, instead of collecting them in Tags.Manifests.
+// This keeps memory use constant when listing repositories with very many
+// manifests.
+func WithManifestFunc(fn ManifestFunc) Option {
+	return func(l *lister) error {
+		l.manifestFn = fn
+		return nil
+	}
+}
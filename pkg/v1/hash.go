@@ -23,6 +23,7 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // Hash is an unqualified digest of some content, e.g. sha256:deadbeef
@@ -74,14 +75,36 @@ func (h *Hash) UnmarshalText(text []byte) error {
 	return h.parse(string(text))
 }
 
+var (
+	hashersMu sync.RWMutex
+	hashers   = map[string]func() hash.Hash{
+		"sha256": crypto.SHA256.New,
+	}
+)
+
+// RegisterHasher makes Hasher return hashes from newHash for the named
+// algorithm, e.g. to use a SIMD-accelerated SHA-256 implementation, or to
+// support "sha512" with sha512.New. Digests are computed and
+// verified with Hasher throughout this module, so this should be called
+// before any are, e.g. from an init function.
+func RegisterHasher(name string, newHash func() hash.Hash) {
+	if newHash == nil {
+		panic("v1: RegisterHasher of nil func for " + name)
+	}
+	hashersMu.Lock()
+	defer hashersMu.Unlock()
+	hashers[name] = newHash
+}
+
 // Hasher returns a hash.Hash for the named algorithm (e.g. "sha256")
 func Hasher(name string) (hash.Hash, error) {
-	switch name {
-	case "sha256":
-		return crypto.SHA256.New(), nil
-	default:
+	hashersMu.RLock()
+	newHash, ok := hashers[name]
+	hashersMu.RUnlock()
+	if !ok {
 		return nil, fmt.Errorf("unsupported hash: %q", name)
 	}
+	return newHash(), nil
 }
 
 func (h *Hash) parse(unquoted string) error {
@@ -111,7 +134,10 @@ func (h *Hash) parse(unquoted string) error {
 
 // SHA256 computes the Hash of the provided io.Reader's content.
 func SHA256(r io.Reader) (Hash, int64, error) {
-	hasher := crypto.SHA256.New()
+	hasher, err := Hasher("sha256")
+	if err != nil {
+		return Hash{}, 0, err
+	}
 	n, err := io.Copy(hasher, r)
 	if err != nil {
 		return Hash{}, 0, err
@@ -15,7 +15,11 @@
 package v1
 
 import (
+	"crypto"
+	"crypto/sha512"
 	"encoding/json"
+	"hash"
+	"maps"
 	"strconv"
 	"strings"
 	"testing"
@@ -113,3 +117,39 @@ func TestTextMarshalling(t *testing.T) {
 		t.Errorf("mismatched hash: %s != %s", h, g)
 	}
 }
+
+// countingHash counts the bytes written to it, like a substitute
+// implementation would hash them.
+type countingHash struct {
+	hash.Hash
+	n *int
+}
+
+func (h countingHash) Write(p []byte) (int, error) {
+	*h.n += len(p)
+	return h.Hash.Write(p)
+}
+
+func TestRegisterHasher(t *testing.T) {
+	old := hashers
+	t.Cleanup(func() { hashers = old })
+	hashers = maps.Clone(old)
+
+	var n int
+	RegisterHasher("sha256", func() hash.Hash { return countingHash{crypto.SHA256.New(), &n} })
+	if _, _, err := SHA256(strings.NewReader("asdf")); err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("registered sha256 hashed %d bytes, want 4", n)
+	}
+
+	h := "sha512:" + strings.Repeat("ab", 64)
+	if _, err := NewHash(h); err == nil {
+		t.Errorf("NewHash(%s) before RegisterHasher: want error, got nil", h)
+	}
+	RegisterHasher("sha512", sha512.New)
+	if _, err := NewHash(h); err != nil {
+		t.Errorf("NewHash(%s): %v", h, err)
+	}
+}
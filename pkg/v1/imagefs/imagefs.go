@@ -0,0 +1,288 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package imagefs exposes an image's filesystem as an fs.FS, so that
+// fs.WalkDir, template.ParseFS and the like can read files straight out of
+// an image, without extracting it to disk first.
+//
+// Layers are only read when they're needed: a file in the topmost layer
+// can be read without fetching the layers below it.
+package imagefs
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Option is a functional option for New.
+type Option func(*options)
+
+type options struct {
+	followSymlinks bool
+}
+
+// WithFollowSymlinks sets whether symbolic links are followed, which they
+// are by default. Links are resolved within the image, so that absolute
+// targets are relative to its root and ".." never leaves it.
+//
+// When they aren't followed, Open returns symbolic links themselves, as
+// empty files, and paths through a symbolic link to a directory don't
+// exist.
+func WithFollowSymlinks(follow bool) Option {
+	return func(o *options) {
+		o.followSymlinks = follow
+	}
+}
+
+// FS is an image's filesystem: its layers applied in order, with whiteouts
+// hiding files from the layers below them. It's safe for concurrent use.
+type FS struct {
+	layers []*layer // bottom first
+	follow bool
+}
+
+var (
+	_ fs.FS     = (*FS)(nil)
+	_ fs.StatFS = (*FS)(nil)
+)
+
+// New returns the filesystem of img.
+func New(img v1.Image, opts ...Option) (*FS, error) {
+	ls, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	return FromLayers(ls, opts...), nil
+}
+
+// FromLayers returns the filesystem of ls applied in order, bottom first.
+func FromLayers(ls []v1.Layer, opts ...Option) *FS {
+	o := options{followSymlinks: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	f := &FS{follow: o.followSymlinks}
+	for _, l := range ls {
+		f.layers = append(f.layers, &layer{l: l})
+	}
+	return f
+}
+
+// Open implements fs.FS. Reading a file streams its layer up to it, so
+// reading many files is cheaper with fs.WalkDir than with repeated Opens
+// of files deep in big layers.
+func (f *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	p, e, l, err := f.resolve(name, f.follow)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	info := named{e.stat(), path.Base(name)}
+	if info.IsDir() {
+		return &dir{info: info, read: func() ([]fs.DirEntry, error) {
+			return f.readDir(p)
+		}}, nil
+	}
+	if !info.Mode().IsRegular() {
+		return &file{info: info, open: noContents}, nil
+	}
+	return &file{info: info, open: func() (io.ReadCloser, error) {
+		return l.open(e.content())
+	}}, nil
+}
+
+// Stat implements fs.StatFS.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	_, e, _, err := f.resolve(name, f.follow)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return named{e.stat(), path.Base(name)}, nil
+}
+
+// Lstat is like fs.Stat, but doesn't follow a final symbolic link. It
+// implements fs.ReadLinkFS.
+func (f *FS) Lstat(name string) (fs.FileInfo, error) {
+	e, err := f.lstat("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return named{e.stat(), path.Base(name)}, nil
+}
+
+// ReadLink returns the target of the symbolic link name. It implements
+// fs.ReadLinkFS.
+func (f *FS) ReadLink(name string) (string, error) {
+	e, err := f.lstat("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	if e.hdr.Typeflag != tar.TypeSymlink {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return e.hdr.Linkname, nil
+}
+
+func (f *FS) lstat(op, name string) (*entry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		_, e, _, err := f.resolve(name, f.follow)
+		return e, err
+	}
+	dir, _, _, err := f.resolve(path.Dir(name), f.follow)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	e, _, ok, err := f.lookup(path.Join(dir, path.Base(name)))
+	if err == nil && !ok {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return e, nil
+}
+
+// layer is a v1.Layer, indexed the first time it's needed.
+type layer struct {
+	l v1.Layer
+
+	once sync.Once
+	idx  *index
+	err  error
+}
+
+func (l *layer) index() (*index, error) {
+	l.once.Do(func() {
+		l.idx, l.err = newIndex(l.l)
+	})
+	return l.idx, l.err
+}
+
+// open returns the contents of e, which must be a regular file in l.
+func (l *layer) open(e *entry) (io.ReadCloser, error) {
+	rc, err := l.l.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(rc)
+	for range e.n + 1 {
+		if _, err := tr.Next(); err != nil {
+			rc.Close()
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+	return &readCloser{Reader: tr, Closer: rc}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func noContents() (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+// named renames a fs.FileInfo, to the name it was opened by.
+type named struct {
+	fs.FileInfo
+	name string
+}
+
+func (n named) Name() string { return n.name }
+
+// file is opened lazily, so that Stat doesn't read its layer.
+type file struct {
+	info fs.FileInfo
+	open func() (io.ReadCloser, error)
+
+	rc  io.ReadCloser
+	err error
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *file) Read(p []byte) (int, error) {
+	if f.rc == nil && f.err == nil {
+		f.rc, f.err = f.open()
+	}
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.rc.Read(p)
+}
+
+func (f *file) Close() error {
+	if f.rc == nil {
+		return nil
+	}
+	return f.rc.Close()
+}
+
+// dir reads its entries lazily, so that Stat doesn't read every layer.
+type dir struct {
+	info fs.FileInfo
+	read func() ([]fs.DirEntry, error)
+
+	entries []fs.DirEntry
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dir) Close() error               { return nil }
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: errors.New("is a directory")}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.read != nil {
+		entries, err := d.read()
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.info.Name(), Err: err}
+		}
+		d.entries, d.read = entries, nil
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
@@ -0,0 +1,226 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagefs
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+type tarEntry struct {
+	name, content, link string
+	hard                bool
+}
+
+// testLayer returns a layer with the given files; names ending in "/" are
+// directories.
+func testLayer(t *testing.T, files ...tarEntry) v1.Layer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range files {
+		hdr := &tar.Header{Name: f.name, Mode: 0o644, Typeflag: tar.TypeReg, Size: int64(len(f.content))}
+		switch {
+		case f.hard:
+			hdr.Typeflag, hdr.Linkname = tar.TypeLink, f.link
+		case f.link != "":
+			hdr.Typeflag, hdr.Linkname = tar.TypeSymlink, f.link
+		case f.name[len(f.name)-1] == '/':
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0o755
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(tw, f.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func image(t *testing.T) v1.Image {
+	t.Helper()
+	img, err := mutate.AppendLayers(empty.Image,
+		testLayer(t,
+			tarEntry{name: "etc/"},
+			tarEntry{name: "etc/os-release", content: "ID=test\n"},
+			tarEntry{name: "etc/passwd", content: "root:x:0:0::/root:/bin/sh\n"},
+			tarEntry{name: "etc/shadow", content: "secret"},
+			tarEntry{name: "opt/old/a", content: "a"},
+			tarEntry{name: "usr/bin/sh", content: "#!"},
+			tarEntry{name: "bin", link: "usr/bin"},
+			tarEntry{name: "etc/hard", link: "etc/passwd", hard: true},
+		),
+		testLayer(t,
+			// No entry for etc/ itself.
+			tarEntry{name: "./etc/.wh.shadow"},
+			tarEntry{name: "/etc/hostname", content: "box"},
+			tarEntry{name: "etc/issue", link: "os-release"},
+			tarEntry{name: "etc/release", link: "/etc/../../etc/issue"},
+			tarEntry{name: "opt/.wh..wh..opq"},
+			tarEntry{name: "opt/new", content: "new"},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestFS(t *testing.T) {
+	fsys, err := New(image(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"etc/os-release": "ID=test\n",
+		"etc/hostname":   "box",
+		"etc/issue":      "ID=test\n",
+		"etc/release":    "ID=test\n",
+		"etc/hard":       "root:x:0:0::/root:/bin/sh\n",
+		"bin/sh":         "#!",
+		"opt/new":        "new",
+	} {
+		got, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			t.Errorf("ReadFile(%q): %v", name, err)
+		} else if string(got) != want {
+			t.Errorf("ReadFile(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	for _, name := range []string{"etc/shadow", "etc/.wh.shadow", "opt/old/a", "opt/old", "missing"} {
+		if _, err := fs.Stat(fsys, name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%q): got %v, want fs.ErrNotExist", name, err)
+		}
+	}
+
+	var names []string
+	if err := fs.WalkDir(fsys, ".", func(p string, _ fs.DirEntry, err error) error {
+		names = append(names, p)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".", "bin", "etc", "etc/hard", "etc/hostname", "etc/issue", "etc/os-release", "etc/passwd", "etc/release", "opt", "opt/new", "usr", "usr/bin", "usr/bin/sh"}
+	if !slices.Equal(names, want) {
+		t.Errorf("WalkDir: got %q, want %q", names, want)
+	}
+
+	if target, err := fsys.ReadLink("etc/issue"); err != nil || target != "os-release" {
+		t.Errorf("ReadLink: got %q, %v", target, err)
+	}
+	if fi, err := fsys.Lstat("bin"); err != nil || fi.Mode()&fs.ModeSymlink == 0 {
+		t.Errorf("Lstat: got %v, %v", fi, err)
+	}
+
+	if err := fstest.TestFS(fsys, "etc/os-release", "etc/hard", "usr/bin/sh", "opt/new"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNoFollow(t *testing.T) {
+	fsys, err := New(image(t), WithFollowSymlinks(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := fsys.Open("etc/issue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode()&fs.ModeSymlink == 0 {
+		t.Errorf("Stat: got mode %v, want a symbolic link", fi.Mode())
+	}
+	if b, err := io.ReadAll(f); err != nil || len(b) != 0 {
+		t.Errorf("ReadAll: got %q, %v", b, err)
+	}
+
+	if _, err := fsys.Open("bin/sh"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open(bin/sh): got %v, want fs.ErrNotExist", err)
+	}
+	if _, err := fsys.Lstat("bin/sh"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Lstat(bin/sh): got %v, want fs.ErrNotExist", err)
+	}
+}
+
+type brokenLayer struct {
+	v1.Layer
+}
+
+var errBroken = errors.New("broken")
+
+func (brokenLayer) Uncompressed() (io.ReadCloser, error) {
+	return nil, errBroken
+}
+
+func TestLazy(t *testing.T) {
+	top := testLayer(t, tarEntry{name: "etc/"}, tarEntry{name: "etc/hostname", content: "box"})
+	fsys := FromLayers([]v1.Layer{brokenLayer{top}, top})
+
+	// Nothing below the top layer is needed for these.
+	if got, err := fs.ReadFile(fsys, "etc/hostname"); err != nil || string(got) != "box" {
+		t.Errorf("ReadFile: got %q, %v", got, err)
+	}
+	if _, err := fs.Stat(fsys, "etc"); err != nil {
+		t.Errorf("Stat: %v", err)
+	}
+
+	if _, err := fs.Stat(fsys, "etc/passwd"); !errors.Is(err, errBroken) {
+		t.Errorf("Stat(etc/passwd): got %v, want %v", err, errBroken)
+	}
+	if _, err := fs.ReadDir(fsys, "etc"); !errors.Is(err, errBroken) {
+		t.Errorf("ReadDir(etc): got %v, want %v", err, errBroken)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	fsys, err := New(empty.Image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil || len(entries) != 0 {
+		t.Errorf("ReadDir: got %v, %v", entries, err)
+	}
+	if err := fstest.TestFS(fsys); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagefs
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	opaqueWhiteout = ".wh..wh..opq"
+
+	// maxLinks bounds how many symbolic links are followed to open a file.
+	maxLinks = 255
+)
+
+// entry is a file in a layer.
+type entry struct {
+	hdr *tar.Header
+	// n is the position of hdr in the layer's tar, or -1 for directories
+	// that are only implied by the paths of their children.
+	n int
+	// link is the file a hard link refers to.
+	link     *entry
+	children []string
+}
+
+func implied(p string) *entry {
+	return &entry{
+		hdr: &tar.Header{Typeflag: tar.TypeDir, Name: p + "/", Mode: 0o755},
+		n:   -1,
+	}
+}
+
+// content returns the entry that holds e's contents.
+func (e *entry) content() *entry {
+	if e.link != nil {
+		return e.link
+	}
+	return e
+}
+
+func (e *entry) stat() fs.FileInfo {
+	return e.content().hdr.FileInfo()
+}
+
+// root stands in for the root directory of an image without layers.
+var root = implied("")
+
+// index is the files in a layer, by their paths relative to its root.
+type index struct {
+	entries map[string]*entry
+}
+
+func newIndex(l v1.Layer) (*index, error) {
+	rc, err := l.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	x := &index{entries: map[string]*entry{"": implied("")}}
+	tr := tar.NewReader(rc)
+	for n := 0; ; n++ {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return x, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		e := &entry{hdr: hdr, n: n}
+		if hdr.Typeflag == tar.TypeLink {
+			if t, ok := x.entries[clean(hdr.Linkname)]; ok && t.hdr.Typeflag != tar.TypeDir {
+				e.link = t.content()
+			}
+		}
+		x.add(clean(hdr.Name), e)
+	}
+}
+
+// add adds e at p, replacing any entry already there, and implying any of
+// its parents that aren't.
+func (x *index) add(p string, e *entry) {
+	if old, ok := x.entries[p]; ok {
+		e.children = old.children
+	} else if p != "" {
+		d := x.dir(parent(p))
+		d.children = append(d.children, path.Base(p))
+	}
+	x.entries[p] = e
+}
+
+func (x *index) dir(p string) *entry {
+	if e, ok := x.entries[p]; ok {
+		return e
+	}
+	e := implied(p)
+	x.add(p, e)
+	return e
+}
+
+// hides reports whether x, which doesn't contain p, hides it in the layers
+// below: with a whiteout for it or a parent, an opaque parent, or a parent
+// that isn't a directory.
+func (x *index) hides(p string) bool {
+	for p != "" {
+		dir, base := parent(p), path.Base(p)
+		if _, ok := x.entries[path.Join(dir, whiteoutPrefix+base)]; ok {
+			return true
+		}
+		if _, ok := x.entries[path.Join(dir, opaqueWhiteout)]; ok {
+			return true
+		}
+		if e, ok := x.entries[dir]; ok && e.hdr.Typeflag != tar.TypeDir {
+			return true
+		}
+		p = dir
+	}
+	return false
+}
+
+// lookup returns the entry for p, without following links, from the
+// topmost layer that has it and isn't hidden by a layer above.
+func (f *FS) lookup(p string) (*entry, *layer, bool, error) {
+	if strings.HasPrefix(path.Base(p), whiteoutPrefix) {
+		return nil, nil, false, nil
+	}
+	for i := len(f.layers) - 1; i >= 0; i-- {
+		x, err := f.layers[i].index()
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if e, ok := x.entries[p]; ok {
+			return e, f.layers[i], true, nil
+		}
+		if x.hides(p) {
+			break
+		}
+	}
+	if p == "" {
+		return root, nil, true, nil
+	}
+	return nil, nil, false, nil
+}
+
+// resolve returns the path name refers to, its entry, and the layer that
+// entry is from. If follow is set, symbolic links are followed; otherwise,
+// paths through them don't exist.
+func (f *FS) resolve(name string, follow bool) (string, *entry, *layer, error) {
+	p, rest := "", split(name)
+	links := 0
+	for len(rest) > 0 {
+		c := rest[0]
+		rest = rest[1:]
+		if c == ".." {
+			p = parent(p)
+			continue
+		}
+		next := path.Join(p, c)
+		e, _, ok, err := f.lookup(next)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if !ok {
+			return "", nil, nil, fs.ErrNotExist
+		}
+		if e.hdr.Typeflag == tar.TypeSymlink && (follow || len(rest) > 0) {
+			if !follow {
+				return "", nil, nil, fs.ErrNotExist
+			}
+			if links++; links > maxLinks {
+				return "", nil, nil, errors.New("too many levels of symbolic links")
+			}
+			if path.IsAbs(e.hdr.Linkname) {
+				p = ""
+			}
+			rest = append(split(e.hdr.Linkname), rest...)
+			continue
+		}
+		p = next
+	}
+	e, l, ok, err := f.lookup(p)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if !ok {
+		return "", nil, nil, fs.ErrNotExist
+	}
+	return p, e, l, nil
+}
+
+// readDir returns the merged entries of the directory p, sorted by name.
+func (f *FS) readDir(p string) ([]fs.DirEntry, error) {
+	seen := map[string]bool{}
+	var entries []fs.DirEntry
+	for i := len(f.layers) - 1; i >= 0; i-- {
+		x, err := f.layers[i].index()
+		if err != nil {
+			return nil, err
+		}
+		e, ok := x.entries[p]
+		if !ok {
+			if x.hides(p) {
+				break
+			}
+			continue
+		}
+		if e.hdr.Typeflag != tar.TypeDir {
+			break
+		}
+		opaque := false
+		for _, base := range e.children {
+			if base == opaqueWhiteout {
+				opaque = true
+			} else if hidden, ok := strings.CutPrefix(base, whiteoutPrefix); ok {
+				seen[hidden] = true
+			} else if !seen[base] {
+				seen[base] = true
+				c := x.entries[path.Join(p, base)]
+				entries = append(entries, fs.FileInfoToDirEntry(named{c.stat(), base}))
+			}
+		}
+		if opaque {
+			break
+		}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// clean returns name relative to the root of its layer, without any
+// leading "/" or "./", and with any ".." that would leave the root dropped.
+func clean(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func split(p string) []string {
+	var cs []string
+	for _, c := range strings.Split(p, "/") {
+		if c != "" && c != "." {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+func parent(p string) string {
+	if d := path.Dir(p); d != "." {
+		return d
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// referrerManifest holds the fields of an image manifest or index that are
+// relevant to referrers.
+type referrerManifest struct {
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Subject      *v1.Descriptor    `json:"subject,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	Config       *struct {
+		MediaType string `json:"mediaType"`
+	} `json:"config,omitempty"`
+}
+
+// Referrers returns the descriptors of the manifests in the layout that
+// refer to subject, sorted by digest, as the registry referrers API would.
+// Manifests are found by walking index.json and the indexes it refers to.
+func (l Path) Referrers(subject v1.Hash) ([]v1.Descriptor, error) {
+	idx, err := l.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []v1.Descriptor
+	seen := map[v1.Hash]bool{}
+	queue := slices.Clone(im.Manifests)
+	for len(queue) > 0 {
+		desc := queue[0]
+		queue = queue[1:]
+		if seen[desc.Digest] || !(desc.MediaType.IsImage() || desc.MediaType.IsIndex()) {
+			continue
+		}
+		seen[desc.Digest] = true
+
+		b, err := l.Bytes(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		if desc.MediaType.IsIndex() {
+			child, err := v1.ParseIndexManifest(bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			queue = append(queue, child.Manifests...)
+		}
+
+		var rm referrerManifest
+		if err := json.Unmarshal(b, &rm); err != nil {
+			return nil, err
+		}
+		if rm.Subject == nil || rm.Subject.Digest != subject {
+			continue
+		}
+		// The artifactType field takes precedence, falling back to the
+		// config media type for image manifests.
+		artifactType := rm.ArtifactType
+		if artifactType == "" && rm.Config != nil {
+			artifactType = rm.Config.MediaType
+		}
+		out = append(out, v1.Descriptor{
+			MediaType:    desc.MediaType,
+			Size:         int64(len(b)),
+			Digest:       desc.Digest,
+			ArtifactType: artifactType,
+			Annotations:  rm.Annotations,
+		})
+	}
+	slices.SortFunc(out, func(a, b v1.Descriptor) int {
+		return strings.Compare(a.Digest.String(), b.Digest.String())
+	})
+	return out, nil
+}
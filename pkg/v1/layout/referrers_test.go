@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestReferrers(t *testing.T) {
+	img, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := partial.Descriptor(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), "application/vnd.example.sig")
+	sig = mutate.Annotations(sig, map[string]string{"a": "b"}).(v1.Image)
+	sig = mutate.Subject(sig, *desc).(v1.Image)
+
+	lp, err := Write(t.TempDir(), empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lp.AppendImage(img); err != nil {
+		t.Fatal(err)
+	}
+	// Referrers in nested indexes are found too.
+	if err := lp.AppendIndex(mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: sig})); err != nil {
+		t.Fatal(err)
+	}
+
+	descs, err := lp.Referrers(desc.Digest)
+	if err != nil {
+		t.Fatalf("Referrers() = %v", err)
+	}
+	if len(descs) != 1 {
+		t.Fatalf("Referrers(): got %d descriptors, want 1", len(descs))
+	}
+	sigDigest, err := sig.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := descs[0]; got.Digest != sigDigest || got.ArtifactType != "application/vnd.example.sig" || got.Annotations["a"] != "b" {
+		t.Errorf("Referrers(): got %+v", got)
+	}
+
+	if descs, err := lp.Referrers(sigDigest); err != nil || len(descs) != 0 {
+		t.Errorf("Referrers(sig) = %v, %v; want none", descs, err)
+	}
+}
@@ -213,7 +213,7 @@ func (l Path) writeBlob(hash v1.Hash, size int64, rc io.ReadCloser, renamer func
 
 	// Check if blob already exists and is the correct size
 	file := filepath.Join(dir, hash.Hex)
-	if s, err := os.Stat(file); err == nil && !s.IsDir() && (s.Size() == size || size == -1) {
+	if l.hasBlob(hash, size) {
 		return nil
 	}
 
@@ -304,6 +304,12 @@ func (l Path) writeLayer(layer v1.Layer) error {
 		return err
 	}
 
+	// Skip layers that are already in the layout before opening them, so
+	// that layers which are fetched, e.g. from a registry, aren't.
+	if d.Hex != "" && l.hasBlob(d, s) {
+		return nil
+	}
+
 	r, err := layer.Compressed()
 	if err != nil {
 		return err
@@ -315,6 +321,13 @@ func (l Path) writeLayer(layer v1.Layer) error {
 	return nil
 }
 
+// hasBlob reports whether the blob hash is in the layout with the given
+// size, or with any size if size is -1.
+func (l Path) hasBlob(hash v1.Hash, size int64) bool {
+	s, err := os.Stat(l.path("blobs", hash.Algorithm, hash.Hex))
+	return err == nil && !s.IsDir() && (s.Size() == size || size == -1)
+}
+
 // RemoveBlob removes a file from the blobs directory in the Path
 // at blobs/{hash.Algorithm}/{hash.Hex}
 // It does *not* remove any reference to it from other manifests or indexes, or
@@ -330,7 +343,7 @@ func (l Path) RemoveBlob(hash v1.Hash) error {
 
 // WriteImage writes an image, including its manifest, config and all of its
 // layers, to the blobs directory. If any blob already exists, as determined by
-// the hash filename, does not write it.
+// the hash filename, does not write it, or read it from img.
 // This function does *not* update the `index.json` file. If you want to write the
 // image and also update the `index.json`, call AppendImage(), which wraps this
 // and also updates the `index.json`.
@@ -357,12 +370,14 @@ func (l Path) WriteImage(img v1.Image) error {
 	if err != nil {
 		return err
 	}
-	cfgBlob, err := img.RawConfigFile()
-	if err != nil {
-		return err
-	}
-	if err := l.WriteBlob(cfgName, io.NopCloser(bytes.NewReader(cfgBlob))); err != nil {
-		return err
+	if !l.hasBlob(cfgName, -1) {
+		cfgBlob, err := img.RawConfigFile()
+		if err != nil {
+			return err
+		}
+		if err := l.WriteBlob(cfgName, io.NopCloser(bytes.NewReader(cfgBlob))); err != nil {
+			return err
+		}
 	}
 
 	// Write the img manifest.
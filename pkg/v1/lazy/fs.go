@@ -0,0 +1,319 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lazy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/containerd/stargz-snapshotter/estargz"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	opaqueWhiteout = ".wh..wh..opq"
+
+	// maxLinks bounds how many symbolic links are followed to open a file.
+	maxLinks = 255
+)
+
+// layers is a stack of layers, bottom first.
+type layers []*Layer
+
+// Open implements fs.FS.
+func (ls layers) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	p, e, l, err := ls.resolve(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	info := named{e.Stat(), path.Base(name)}
+	switch e.Type {
+	case "dir":
+		return &dir{info: info, entries: ls.readDir(p)}, nil
+	case "reg":
+		sr, err := l.r.OpenFile(p)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		var r io.Reader = sr
+		if l.verify != nil {
+			r = &verifiedReader{l: l, name: e.Name, sr: sr}
+		}
+		return &file{info: info, r: r}, nil
+	default:
+		return &file{info: info, r: bytes.NewReader(nil)}, nil
+	}
+}
+
+// Lstat is like fs.Stat, but doesn't follow a final symbolic link. It
+// implements fs.ReadLinkFS.
+func (ls layers) Lstat(name string) (fs.FileInfo, error) {
+	e, err := ls.lstat("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return named{e.Stat(), path.Base(name)}, nil
+}
+
+// ReadLink returns the target of the symbolic link name. It implements
+// fs.ReadLinkFS.
+func (ls layers) ReadLink(name string) (string, error) {
+	e, err := ls.lstat("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	if e.Type != "symlink" {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return e.LinkName, nil
+}
+
+func (ls layers) lstat(op, name string) (*estargz.TOCEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		_, e, _, err := ls.resolve(name)
+		return e, err
+	}
+	dir, _, _, err := ls.resolve(path.Dir(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	e, _, ok := ls.lookup(path.Join(dir, path.Base(name)))
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return e, nil
+}
+
+// resolve follows any symbolic links in name, returning the path it refers
+// to, its entry, and the layer that entry is from.
+func (ls layers) resolve(name string) (string, *estargz.TOCEntry, *Layer, error) {
+	p, rest := "", split(name)
+	links := 0
+	for len(rest) > 0 {
+		c := rest[0]
+		rest = rest[1:]
+		if c == ".." {
+			p = parent(p)
+			continue
+		}
+		next := path.Join(p, c)
+		e, _, ok := ls.lookup(next)
+		if !ok {
+			return "", nil, nil, fs.ErrNotExist
+		}
+		if e.Type == "symlink" {
+			if links++; links > maxLinks {
+				return "", nil, nil, errors.New("too many levels of symbolic links")
+			}
+			if path.IsAbs(e.LinkName) {
+				p = ""
+			}
+			rest = append(split(e.LinkName), rest...)
+			continue
+		}
+		p = next
+	}
+	e, l, ok := ls.lookup(p)
+	if !ok {
+		return "", nil, nil, fs.ErrNotExist
+	}
+	return p, e, l, nil
+}
+
+// lookup returns the entry for p, without following links, from the
+// topmost layer that has it and isn't hidden by a layer above.
+func (ls layers) lookup(p string) (*estargz.TOCEntry, *Layer, bool) {
+	for i := len(ls) - 1; i >= 0; i-- {
+		if e, ok := ls[i].r.Lookup(p); ok {
+			return e, ls[i], true
+		}
+		if hides(ls[i].r, p) {
+			break
+		}
+	}
+	return nil, nil, false
+}
+
+// hides reports whether r, which doesn't contain p, hides it in the layers
+// below: with a whiteout for it or a parent, an opaque parent, or a parent
+// that isn't a directory.
+func hides(r *estargz.Reader, p string) bool {
+	for p != "" {
+		dir, base := parent(p), path.Base(p)
+		if _, ok := r.Lookup(path.Join(dir, whiteoutPrefix+base)); ok {
+			return true
+		}
+		if _, ok := r.Lookup(path.Join(dir, opaqueWhiteout)); ok {
+			return true
+		}
+		if e, ok := r.Lookup(dir); ok && e.Type != "dir" {
+			return true
+		}
+		p = dir
+	}
+	return false
+}
+
+// readDir returns the merged entries of the directory p, sorted by name.
+func (ls layers) readDir(p string) []fs.DirEntry {
+	seen := map[string]bool{}
+	var entries []fs.DirEntry
+	for i := len(ls) - 1; i >= 0; i-- {
+		r := ls[i].r
+		e, ok := r.Lookup(p)
+		if !ok {
+			if hides(r, p) {
+				break
+			}
+			continue
+		}
+		if e.Type != "dir" {
+			break
+		}
+		opaque := false
+		e.ForeachChild(func(base string, c *estargz.TOCEntry) bool {
+			if base == opaqueWhiteout {
+				opaque = true
+			} else if hidden, ok := strings.CutPrefix(base, whiteoutPrefix); ok {
+				seen[hidden] = true
+			} else if !seen[base] {
+				seen[base] = true
+				entries = append(entries, fs.FileInfoToDirEntry(c.Stat()))
+			}
+			return true
+		})
+		if opaque {
+			break
+		}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries
+}
+
+func split(p string) []string {
+	var cs []string
+	for _, c := range strings.Split(p, "/") {
+		if c != "" && c != "." {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+func parent(p string) string {
+	if d := path.Dir(p); d != "." {
+		return d
+	}
+	return ""
+}
+
+// named renames a fs.FileInfo, to the name it was opened by.
+type named struct {
+	fs.FileInfo
+	name string
+}
+
+func (n named) Name() string { return n.name }
+
+type file struct {
+	info fs.FileInfo
+	r    io.Reader
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Read(p []byte) (int, error) { return f.r.Read(p) }
+func (f *file) Close() error               { return nil }
+
+type dir struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dir) Close() error               { return nil }
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: errors.New("is a directory")}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+// verifiedReader reads a file a chunk at a time, verifying each chunk
+// against its digest in the TOC before returning any of it.
+type verifiedReader struct {
+	l    *Layer
+	name string
+	sr   *io.SectionReader
+	off  int64
+	buf  bytes.Reader
+}
+
+func (r *verifiedReader) Read(p []byte) (int, error) {
+	if r.buf.Len() == 0 {
+		if r.off >= r.sr.Size() {
+			return 0, io.EOF
+		}
+		ce, ok := r.l.r.ChunkEntryForOffset(r.name, r.off)
+		if !ok {
+			return 0, fmt.Errorf("%s: no chunk at offset %d", r.name, r.off)
+		}
+		chunk := make([]byte, ce.ChunkSize)
+		if _, err := r.sr.ReadAt(chunk, ce.ChunkOffset); err != nil && err != io.EOF {
+			return 0, err
+		}
+		v, err := r.l.verify.Verifier(ce)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := v.Write(chunk); err != nil {
+			return 0, err
+		}
+		if !v.Verified() {
+			return 0, fmt.Errorf("%s: chunk at offset %d doesn't match its digest", r.name, ce.ChunkOffset)
+		}
+		r.buf.Reset(chunk)
+		r.off = ce.ChunkOffset + ce.ChunkSize
+	}
+	return r.buf.Read(p)
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lazy reads files from eStargz images without downloading their
+// layers, by fetching each layer's table of contents and then only the
+// chunks of the files that are read.
+//
+// This makes it cheap to read a handful of files, e.g. to print a config
+// file or scan package metadata, from images that are too big to pull.
+package lazy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/containerd/stargz-snapshotter/estargz"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/opencontainers/go-digest"
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrNotEstargz is returned for layers that don't have an eStargz TOC.
+var ErrNotEstargz = errors.New("not an eStargz layer")
+
+// BlobReader returns a reader for the compressed contents of l, which
+// ideally only fetches the parts that are read, e.g. remote.BlobReaderAt.
+type BlobReader func(l v1.Layer) (*io.SectionReader, error)
+
+// Layer is an eStargz layer whose files can be read individually.
+//
+// If the layer's descriptor has the TOC digest annotation that eStargz
+// builders add, the TOC and every chunk read are verified against it.
+// Otherwise, nothing read is verified.
+type Layer struct {
+	v1.Layer
+
+	r      *estargz.Reader
+	verify estargz.TOCEntryVerifier
+}
+
+var _ fs.FS = (*Layer)(nil)
+
+// NewLayer returns l, reading its TOC and files from sr, which must hold
+// its compressed contents.
+func NewLayer(l v1.Layer, sr *io.SectionReader) (*Layer, error) {
+	desc, err := partial.Descriptor(l)
+	if err != nil {
+		return nil, err
+	}
+	return newLayer(l, sr, desc.Annotations)
+}
+
+func newLayer(l v1.Layer, sr *io.SectionReader, annotations map[string]string) (*Layer, error) {
+	r, err := estargz.Open(sr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotEstargz, err)
+	}
+	lz := &Layer{Layer: l, r: r}
+	if s, ok := annotations[estargz.TOCJSONDigestAnnotation]; ok {
+		d, err := digest.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", estargz.TOCJSONDigestAnnotation, err)
+		}
+		lz.verify, err = r.VerifyTOC(d)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lz, nil
+}
+
+// Open implements fs.FS, for the files in this layer alone. Whiteouts hide
+// files as they would from lower layers, so they don't appear.
+func (l *Layer) Open(name string) (fs.File, error) {
+	return layers{l}.Open(name)
+}
+
+// Lstat implements fs.ReadLinkFS.
+func (l *Layer) Lstat(name string) (fs.FileInfo, error) {
+	return layers{l}.Lstat(name)
+}
+
+// ReadLink implements fs.ReadLinkFS.
+func (l *Layer) ReadLink(name string) (string, error) {
+	return layers{l}.ReadLink(name)
+}
+
+// Image is an image whose filesystem can be read file by file, without
+// pulling its layers, all of which must be eStargz.
+type Image struct {
+	v1.Image
+
+	layers layers
+}
+
+var _ fs.FS = (*Image)(nil)
+
+// NewImage returns img, reading its layers' TOCs and files with blobs. It
+// returns an error wrapping ErrNotEstargz if any layer isn't eStargz.
+func NewImage(img v1.Image, blobs BlobReader) (*Image, error) {
+	// The layers themselves don't necessarily know their annotations.
+	m, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	ls, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	if len(ls) != len(m.Layers) {
+		return nil, fmt.Errorf("manifest has %d layers, image has %d", len(m.Layers), len(ls))
+	}
+	lzs := make(layers, len(ls))
+	var g errgroup.Group
+	g.SetLimit(4)
+	for i, l := range ls {
+		g.Go(func() error {
+			sr, err := blobs(l)
+			if err != nil {
+				return err
+			}
+			lzs[i], err = newLayer(l, sr, m.Layers[i].Annotations)
+			if err != nil {
+				d, _ := l.Digest()
+				return fmt.Errorf("layer %s: %w", d, err)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return &Image{Image: img, layers: lzs}, nil
+}
+
+// Remote returns the image ref refers to, fetching only the parts of its
+// layers that are read.
+func Remote(ctx context.Context, ref name.Reference, options ...remote.Option) (*Image, error) {
+	puller, err := remote.NewPuller(options...)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := puller.Get(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return nil, err
+	}
+	return NewImage(img, func(l v1.Layer) (*io.SectionReader, error) {
+		d, err := l.Digest()
+		if err != nil {
+			return nil, err
+		}
+		return puller.BlobReaderAt(ctx, ref.Context().Digest(d.String()))
+	})
+}
+
+// Open implements fs.FS, for the image's filesystem: its layers applied in
+// order, with whiteouts hiding files from the layers below them. Symbolic
+// links are followed within the image.
+func (i *Image) Open(name string) (fs.File, error) {
+	return i.layers.Open(name)
+}
+
+// Lstat implements fs.ReadLinkFS.
+func (i *Image) Lstat(name string) (fs.FileInfo, error) {
+	return i.layers.Lstat(name)
+}
+
+// ReadLink implements fs.ReadLinkFS.
+func (i *Image) ReadLink(name string) (string, error) {
+	return i.layers.ReadLink(name)
+}
@@ -0,0 +1,256 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lazy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/containerd/stargz-snapshotter/estargz"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/opencontainers/go-digest"
+)
+
+// compressor is estargz's gzip compressor, except that it writes the
+// footer by hand: estargz panics on the footer that newer versions of
+// compress/gzip write.
+type compressor struct {
+	*estargz.GzipCompressor
+}
+
+func (c compressor) WriteTOCAndFooter(w io.Writer, off int64, toc *estargz.JTOC, diffHash hash.Hash) (digest.Digest, error) {
+	tocJSON, err := json.MarshalIndent(toc, "", "\t")
+	if err != nil {
+		return "", err
+	}
+	gz := gzip.NewWriter(w)
+	gw := io.Writer(gz)
+	if diffHash != nil {
+		gw = io.MultiWriter(gz, diffHash)
+	}
+	tw := tar.NewWriter(gw)
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: estargz.TOCTarName, Size: int64(len(tocJSON))}); err != nil {
+		return "", err
+	}
+	if _, err := tw.Write(tocJSON); err != nil {
+		return "", err
+	}
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+	if err := gz.Close(); err != nil {
+		return "", err
+	}
+
+	// A gzip header with the TOC offset in its extra field, then an empty
+	// stored block, CRC and size.
+	footer := []byte{0x1f, 0x8b, 0x08, 0x04, 0, 0, 0, 0, 0, 0xff, 26, 0, 'S', 'G', 22, 0}
+	footer = append(footer, fmt.Sprintf("%016xSTARGZ", off)...)
+	footer = append(footer, 0x01, 0, 0, 0xff, 0xff)
+	footer = binary.LittleEndian.AppendUint64(footer, 0)
+	if len(footer) != estargz.FooterSize {
+		return "", fmt.Errorf("footer is %d bytes", len(footer))
+	}
+	if _, err := w.Write(footer); err != nil {
+		return "", err
+	}
+	return digest.FromBytes(tocJSON), nil
+}
+
+type entry struct {
+	name, content, link string
+}
+
+// layer returns an eStargz layer with the given files, and its TOC digest.
+func layer(t *testing.T, entries ...entry) (v1.Layer, string) {
+	t.Helper()
+	var in bytes.Buffer
+	tw := tar.NewWriter(&in)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Typeflag: tar.TypeReg, Size: int64(len(e.content))}
+		switch {
+		case e.link != "":
+			hdr.Typeflag, hdr.Linkname = tar.TypeSymlink, e.link
+		case e.name[len(e.name)-1] == '/':
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0o755
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(tw, e.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var blob bytes.Buffer
+	w := estargz.NewWriterWithCompressor(&blob, compressor{estargz.NewGzipCompressor()})
+	// Small chunks, so that files span several.
+	w.ChunkSize = 4
+	if err := w.AppendTar(&in); err != nil {
+		t.Fatal(err)
+	}
+	d, err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return static.NewLayer(blob.Bytes(), types.OCILayer), d.String()
+}
+
+func blobs(l v1.Layer) (*io.SectionReader, error) {
+	rc, err := l.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	return io.NewSectionReader(bytes.NewReader(b), 0, int64(len(b))), nil
+}
+
+func image(t *testing.T, layers ...[]entry) *Image {
+	t.Helper()
+	var adds []mutate.Addendum
+	for _, entries := range layers {
+		l, d := layer(t, entries...)
+		adds = append(adds, mutate.Addendum{
+			Layer:       l,
+			Annotations: map[string]string{estargz.TOCJSONDigestAnnotation: d},
+		})
+	}
+	img, err := mutate.Append(empty.Image, adds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lz, err := NewImage(img, blobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lz
+}
+
+func TestImage(t *testing.T) {
+	img := image(t, []entry{
+		{name: "etc/"},
+		{name: "etc/os-release", content: "ID=lower"},
+		{name: "etc/gone", content: "gone"},
+		{name: "var/lib/a", content: "a"},
+		{name: "usr/bin/sh", content: "#!/bin/true"},
+	}, []entry{
+		{name: "etc/os-release", content: "ID=upper"},
+		{name: "etc/.wh.gone"},
+		{name: "var/lib/.wh..wh..opq"},
+		{name: "var/lib/b", content: "b"},
+		{name: "bin", link: "usr/bin"},
+		{name: "etc/shell", link: "/bin/sh"},
+	})
+
+	for name, want := range map[string]string{
+		"etc/os-release": "ID=upper",
+		"var/lib/b":      "b",
+		"bin/sh":         "#!/bin/true",
+		"etc/shell":      "#!/bin/true",
+	} {
+		got, err := fs.ReadFile(img, name)
+		if err != nil {
+			t.Errorf("ReadFile(%s) = %v", name, err)
+		} else if string(got) != want {
+			t.Errorf("ReadFile(%s) = %q, want %q", name, got, want)
+		}
+	}
+	for _, name := range []string{"etc/gone", "var/lib/a", "nope"} {
+		if _, err := fs.Stat(img, name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%s) = %v, want ErrNotExist", name, err)
+		}
+	}
+
+	entries, err := fs.ReadDir(img, "etc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if got, want := fmt.Sprint(names), "[os-release shell]"; got != want {
+		t.Errorf("ReadDir(etc) = %s, want %s", got, want)
+	}
+
+	if err := fstest.TestFS(img, "etc/os-release", "var/lib/b", "usr/bin/sh"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLayer(t *testing.T) {
+	l, _ := layer(t, entry{name: "a", content: "hello"}, entry{name: ".wh.b"})
+	sr, err := blobs(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lz, err := NewLayer(l, sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without a TOC digest, nothing is verified, but files can be read.
+	if lz.verify != nil {
+		t.Error("verifying without a TOC digest")
+	}
+	if err := fstest.TestFS(lz, "a"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewImageErrors(t *testing.T) {
+	t.Run("not estargz", func(t *testing.T) {
+		img, err := random.Image(1024, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewImage(img, blobs); !errors.Is(err, ErrNotEstargz) {
+			t.Errorf("NewImage() = %v, want ErrNotEstargz", err)
+		}
+	})
+	t.Run("wrong TOC digest", func(t *testing.T) {
+		l, _ := layer(t, entry{name: "a", content: "hello"})
+		img, err := mutate.Append(empty.Image, mutate.Addendum{
+			Layer:       l,
+			Annotations: map[string]string{estargz.TOCJSONDigestAnnotation: digest.FromString("nope").String()},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewImage(img, blobs); err == nil {
+			t.Error("NewImage() = nil, want error")
+		}
+	})
+}
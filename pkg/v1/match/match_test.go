@@ -85,6 +85,38 @@ func TestPlatforms(t *testing.T) {
 	}
 }
 
+func TestPlatformFuzzy(t *testing.T) {
+	tests := []struct {
+		desc     v1.Descriptor
+		platform v1.Platform
+		match    bool
+	}{
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "amd64", OS: "linux"}}, v1.Platform{Architecture: "x86_64", OS: "linux"}, true},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "arm64", OS: "linux"}}, v1.Platform{Architecture: "arm64", OS: "linux", Variant: "v8"}, true},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "arm", OS: "linux", Variant: "v8"}}, v1.Platform{Architecture: "aarch64", OS: "linux"}, true},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "arm", OS: "linux", Variant: "v6"}}, v1.Platform{Architecture: "arm", OS: "linux", Variant: "7"}, true},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "arm", OS: "linux", Variant: "v7"}}, v1.Platform{Architecture: "arm", OS: "linux", Variant: "v6"}, false},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "arm", OS: "linux"}}, v1.Platform{Architecture: "arm", OS: "linux", Variant: "v7"}, true},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "amd64", OS: "linux", Variant: "v2"}}, v1.Platform{Architecture: "amd64", OS: "linux"}, true},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "amd64", OS: "linux", Variant: "v3"}}, v1.Platform{Architecture: "amd64", OS: "linux", Variant: "v2"}, false},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "amd64", OS: "linux"}}, v1.Platform{Architecture: "amd64", OS: "linux", Variant: "v3"}, true},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "amd64", OS: "linux", Variant: "v1"}}, v1.Platform{Architecture: "amd64", OS: "linux", Variant: "v2"}, true},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "riscv64", OS: "linux"}}, v1.Platform{Architecture: "riscv64", OS: "linux", Variant: "rva23"}, true},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "riscv64", OS: "linux", Variant: "rva23"}}, v1.Platform{Architecture: "riscv64", OS: "linux", Variant: "rva20"}, false},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "amd64", OS: "windows", OSVersion: "10.0.17763.1234"}}, v1.Platform{Architecture: "amd64", OS: "windows", OSVersion: "10.0.17763.5678"}, true},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "amd64", OS: "windows", OSVersion: "10.0.17763.1234"}}, v1.Platform{Architecture: "amd64", OS: "windows", OSVersion: "10.0.20348.5678"}, false},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "amd64", OS: "linux"}}, v1.Platform{Architecture: "arm64", OS: "linux"}, false},
+		{v1.Descriptor{Platform: &v1.Platform{Architecture: "amd64", OS: "linux"}}, v1.Platform{Architecture: "amd64", OS: "linux", Features: []string{"sse4"}}, false},
+		{v1.Descriptor{}, v1.Platform{Architecture: "amd64", OS: "linux"}, false},
+	}
+	for i, tt := range tests {
+		f := match.PlatformFuzzy(tt.platform)
+		if match := f(tt.desc); match != tt.match {
+			t.Errorf("%d: mismatched, got %v expected %v for desc %#v platform %#v", i, match, tt.match, tt.desc, tt.platform)
+		}
+	}
+}
+
 func TestMediaTypes(t *testing.T) {
 	tests := []struct {
 		desc       v1.Descriptor
@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package match
+
+import (
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// PlatformFuzzy returns a match.Matcher that matches descriptors whose
+// platform a container runtime on the given platform would run, in the manner
+// of containerd's platform matching:
+//
+//   - OS and architecture aliases are normalized, e.g. "aarch64" is "arm64"
+//     and "x86_64" is "amd64".
+//   - arm64 and arm/v8 are interchangeable, and arm without a variant is arm/v7.
+//   - A platform without a variant matches any variant of the same
+//     architecture, while a descriptor without one is for the lowest level of
+//     its architecture, e.g. amd64/v1. Older variants are accepted, e.g.
+//     arm/v7 runs arm/v6 and amd64/v3 runs amd64/v2.
+//   - On Windows, os.version matches if the major, minor and build numbers are
+//     the same; the revision is ignored.
+//
+// Like Platforms, it ignores descriptors that do not have a platform, and the
+// OSFeatures and Features of platform must be a subset of the descriptor's.
+func PlatformFuzzy(platform v1.Platform) Matcher {
+	want := normalizePlatform(platform)
+	return func(desc v1.Descriptor) bool {
+		if desc.Platform == nil {
+			return false
+		}
+		return platformCompatible(normalizePlatform(*desc.Platform), want)
+	}
+}
+
+// normalizePlatform returns p with the OS, architecture and variant in their
+// canonical forms.
+func normalizePlatform(p v1.Platform) v1.Platform {
+	p.OS = strings.ToLower(p.OS)
+	if p.OS == "macos" {
+		p.OS = "darwin"
+	}
+
+	p.Architecture = strings.ToLower(p.Architecture)
+	p.Variant = strings.ToLower(p.Variant)
+	if p.Variant != "" && !strings.HasPrefix(p.Variant, "v") {
+		p.Variant = "v" + p.Variant
+	}
+
+	switch p.Architecture {
+	case "i386":
+		p.Architecture = "386"
+	case "x86_64", "x86-64", "amd64":
+		p.Architecture = "amd64"
+		if p.Variant == "v1" {
+			p.Variant = ""
+		}
+	case "aarch64", "arm64":
+		p.Architecture = "arm64"
+		if p.Variant == "v8" || p.Variant == "v8.0" {
+			p.Variant = ""
+		}
+	case "armhf":
+		p.Architecture, p.Variant = "arm", "v7"
+	case "armel":
+		p.Architecture, p.Variant = "arm", "v6"
+	case "arm":
+		switch p.Variant {
+		case "":
+			p.Variant = "v7"
+		case "v8":
+			p.Architecture, p.Variant = "arm64", ""
+		}
+	}
+	return p
+}
+
+// platformCompatible returns true if a runtime on the normalized platform want
+// can run images for the normalized platform given.
+func platformCompatible(given, want v1.Platform) bool {
+	if !variantCompatible(given.Architecture, given.Variant, want.Variant) {
+		return false
+	}
+	// Variants were compared above, allowing older ones.
+	g, w := given, want
+	g.Variant, w.Variant = "", ""
+	if !g.Compatible(w) {
+		return false
+	}
+	return isSubset(given.OSFeatures, want.OSFeatures) && isSubset(given.Features, want.Features)
+}
+
+// variantOrder lists the variants of architectures whose newer variants can run
+// older ones, oldest first.
+var variantOrder = map[string][]string{
+	"arm":   {"v5", "v6", "v7"},
+	"amd64": {"", "v2", "v3", "v4"},
+}
+
+// variantCompatible returns true if a runtime for the variant want of arch can
+// run images for the variant given.
+//
+// A runtime without a variant runs any variant, but an image without one is
+// for the lowest level of its architecture, e.g. amd64/v1, rather than any.
+func variantCompatible(arch, given, want string) bool {
+	if given == want || want == "" {
+		return true
+	}
+	order, ok := variantOrder[arch]
+	if !ok {
+		// Without an order, all we know is that no variant is the lowest.
+		return given == ""
+	}
+	gi, wi := -1, -1
+	for i, v := range order {
+		if v == given {
+			gi = i
+		}
+		if v == want {
+			wi = i
+		}
+	}
+	return gi != -1 && wi != -1 && gi <= wi
+}
+
+// isSubset returns true if every value in required is in lst.
+func isSubset(lst, required []string) bool {
+	set := make(map[string]struct{}, len(lst))
+	for _, v := range lst {
+		set[v] = struct{}{}
+	}
+	for _, v := range required {
+		if _, ok := set[v]; !ok {
+			return false
+		}
+	}
+	return true
+}
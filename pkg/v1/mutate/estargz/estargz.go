@@ -0,0 +1,178 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package estargz converts layers and images to eStargz, so that they can be
+// pulled lazily by snapshotters that support it.
+//
+// How well lazy pulling performs depends on how the layers are built: the
+// chunk size determines the granularity of fetches, and prioritized files,
+// e.g. those a workload reads on startup according to a profile, are placed
+// at the start of each layer so they can be prefetched.
+package estargz
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/containerd/stargz-snapshotter/estargz"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Option is a functional option for Layer and Image.
+type Option func(*options)
+
+type options struct {
+	chunkSize   int
+	level       *int
+	prioritized []string
+}
+
+// WithChunkSize sets the size of the chunks that files are split into, which
+// is the granularity of lazy fetches. By default, estargz picks the size.
+func WithChunkSize(size int) Option {
+	return func(o *options) {
+		o.chunkSize = size
+	}
+}
+
+// WithCompressionLevel sets the gzip compression level of the layers.
+func WithCompressionLevel(level int) Option {
+	return func(o *options) {
+		o.level = &level
+	}
+}
+
+// WithPrioritizedFiles places the given files, by absolute path, at the start
+// of each layer that contains them, in the given order, so that they can be
+// prefetched. Files that a layer doesn't contain are ignored.
+func WithPrioritizedFiles(files ...string) Option {
+	return func(o *options) {
+		o.prioritized = append(o.prioritized, files...)
+	}
+}
+
+// ReadProfile reads a list of prioritized files, for WithPrioritizedFiles,
+// from r: one path per line, in the order they are accessed. Empty lines and
+// lines starting with "#" are ignored.
+func ReadProfile(r io.Reader) ([]string, error) {
+	var files []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		files = append(files, line)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("reading profile: %w", err)
+	}
+	return files, nil
+}
+
+// Layer returns l converted to eStargz, with the given media type.
+func Layer(l v1.Layer, mt types.MediaType, opts ...Option) (v1.Layer, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	eopts := []estargz.Option{
+		// The profile covers every layer, so each one only has some of it.
+		estargz.WithAllowPrioritizeNotFound(&[]string{}),
+	}
+	if o.chunkSize > 0 {
+		eopts = append(eopts, estargz.WithChunkSize(o.chunkSize))
+	}
+	if len(o.prioritized) > 0 {
+		eopts = append(eopts, estargz.WithPrioritizedFiles(o.prioritized))
+	}
+	// The tarball estargz options are deprecated for direct use; this
+	// package is what they're kept around for.
+	topts := []tarball.LayerOption{
+		tarball.WithMediaType(mt),
+		tarball.WithEstargz,                  //nolint:staticcheck
+		tarball.WithEstargzOptions(eopts...), //nolint:staticcheck
+	}
+	if o.level != nil {
+		topts = append(topts, tarball.WithCompressionLevel(*o.level))
+	}
+	return tarball.LayerFromOpener(l.Uncompressed, topts...)
+}
+
+// Image returns img with each of its layers converted to eStargz, keeping its
+// config and history.
+func Image(img v1.Image, opts ...Option) (v1.Image, error) {
+	mt, err := img.MediaType()
+	if err != nil {
+		return nil, err
+	}
+	lmt, cmt := types.DockerLayer, types.DockerConfigJSON
+	if mt == types.OCIManifestSchema1 {
+		lmt, cmt = types.OCILayer, types.OCIConfigJSON
+	}
+
+	ocfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := ocfg.DeepCopy()
+	cfg.History = nil
+	cfg.RootFS.DiffIDs = nil
+	base, err := mutate.ConfigFile(empty.Image, cfg)
+	if err != nil {
+		return nil, err
+	}
+	base = mutate.ConfigMediaType(mutate.MediaType(base, mt), cmt)
+
+	// Pair each layer with its history; if they don't line up, drop the
+	// history rather than misattribute it.
+	history := ocfg.History
+	nonEmpty := 0
+	for _, h := range history {
+		if !h.EmptyLayer {
+			nonEmpty++
+		}
+	}
+	if nonEmpty != len(layers) {
+		history = make([]v1.History, len(layers))
+	}
+
+	var adds []mutate.Addendum
+	i := 0
+	for _, h := range history {
+		if h.EmptyLayer {
+			adds = append(adds, mutate.Addendum{History: h})
+			continue
+		}
+		l, err := Layer(layers[i], lmt, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("converting layer %d: %w", i, err)
+		}
+		adds = append(adds, mutate.Addendum{Layer: l, History: h, MediaType: lmt})
+		i++
+	}
+	return mutate.Append(base, adds...)
+}
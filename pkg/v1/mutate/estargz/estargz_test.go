@@ -0,0 +1,39 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package estargz
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadProfile(t *testing.T) {
+	profile := `# accessed by the entrypoint
+/usr/bin/app
+
+/etc/app/config.yaml
+  /lib/libc.so.6  
+`
+	got, err := ReadProfile(strings.NewReader(profile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/usr/bin/app", "/etc/app/config.yaml", "/lib/libc.so.6"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ReadProfile (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// LayerSelector reports whether the i'th layer of an image, counting from
+// the bottom, should be selected.
+type LayerSelector func(i int, layer v1.Layer) (bool, error)
+
+// LayerIndices selects the layers at the given indices, counting from the
+// bottom.
+func LayerIndices(indices ...int) LayerSelector {
+	return func(i int, _ v1.Layer) (bool, error) {
+		return slices.Contains(indices, i), nil
+	}
+}
+
+// LayerDigests selects the layers with any of the given digests.
+func LayerDigests(digests ...v1.Hash) LayerSelector {
+	return func(_ int, layer v1.Layer) (bool, error) {
+		d, err := layer.Digest()
+		if err != nil {
+			return false, err
+		}
+		return slices.Contains(digests, d), nil
+	}
+}
+
+// Graft returns base with the layers of donor that sel selects appended to
+// it, in order, along with their history, annotations and URLs. Only the
+// selected layers are copied, so the donor's config is ignored.
+//
+// Grafting OCI layers, e.g. zstd ones, onto an image with a Docker manifest
+// is an error, since Docker manifests can't refer to them. The reverse is
+// allowed, as OCI manifests may use Docker's layer media types.
+func Graft(base, donor v1.Image, sel LayerSelector) (v1.Image, error) {
+	mt, err := base.MediaType()
+	if err != nil {
+		return nil, fmt.Errorf("getting base media type: %w", err)
+	}
+	layers, err := donor.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("getting donor layers: %w", err)
+	}
+	m, err := donor.Manifest()
+	if err != nil {
+		return nil, fmt.Errorf("getting donor manifest: %w", err)
+	}
+	if len(m.Layers) != len(layers) {
+		return nil, fmt.Errorf("donor manifest has %d layers, image has %d", len(m.Layers), len(layers))
+	}
+	cf, err := donor.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("getting donor config: %w", err)
+	}
+
+	// Empty layers only appear in the history, so walk it alongside the
+	// layers, as createAddendums does.
+	var history []v1.History
+	for _, h := range cf.History {
+		if !h.EmptyLayer {
+			history = append(history, h)
+		}
+	}
+
+	var adds []Addendum
+	for i, layer := range layers {
+		ok, err := sel(i, layer)
+		if err != nil {
+			return nil, fmt.Errorf("selecting layer %d: %w", i, err)
+		}
+		if !ok {
+			continue
+		}
+		desc := m.Layers[i]
+		if err := graftable(mt, desc.MediaType); err != nil {
+			return nil, fmt.Errorf("layer %d (%s): %w", i, desc.Digest, err)
+		}
+		add := Addendum{
+			Layer:       layer,
+			URLs:        desc.URLs,
+			Annotations: desc.Annotations,
+		}
+		// History that's missing or malformed is left out, rather than
+		// attributed to the wrong layers.
+		if len(history) == len(layers) {
+			add.History = history[i]
+		}
+		adds = append(adds, add)
+	}
+	return Append(base, adds...)
+}
+
+// graftable returns an error if a layer of media type layer can't be
+// appended to an image with a manifest of media type manifest.
+func graftable(manifest, layer types.MediaType) error {
+	if !layer.IsLayer() {
+		return fmt.Errorf("unsupported layer media type %q", layer)
+	}
+	if manifest == types.DockerManifestSchema2 && strings.Contains(string(layer), types.OCIVendorPrefix) {
+		return fmt.Errorf("can't graft a %q layer onto a %q image", layer, manifest)
+	}
+	return nil
+}
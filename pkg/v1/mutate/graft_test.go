@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutate_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestGraft(t *testing.T) {
+	base, err := random.Image(100, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	donorLayers := make([]v1.Layer, 3)
+	for i := range donorLayers {
+		if donorLayers[i], err = random.Layer(100, types.DockerLayer); err != nil {
+			t.Fatal(err)
+		}
+	}
+	donor, err := mutate.Append(empty.Image,
+		mutate.Addendum{Layer: donorLayers[0], History: v1.History{CreatedBy: "os"}},
+		mutate.Addendum{History: v1.History{CreatedBy: "ENV", EmptyLayer: true}},
+		mutate.Addendum{Layer: donorLayers[1], History: v1.History{CreatedBy: "agent"}, Annotations: map[string]string{"a": "b"}},
+		mutate.Addendum{Layer: donorLayers[2], History: v1.History{CreatedBy: "sidecar"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := donorLayers[2].Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		sel  mutate.LayerSelector
+	}{
+		{"indices", mutate.LayerIndices(1, 2)},
+		{"digests", func(i int, l v1.Layer) (bool, error) {
+			// Selectors can be combined.
+			if ok, err := mutate.LayerIndices(1)(i, l); ok || err != nil {
+				return ok, err
+			}
+			return mutate.LayerDigests(d2)(i, l)
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			img, err := mutate.Graft(base, donor, tc.sel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := layerDigests(t, img)
+			want := append(layerDigests(t, base), layerDigests(t, donor)[1:]...)
+			if !slices.Equal(got, want) {
+				t.Errorf("layers: got %v, want %v", got, want)
+			}
+
+			cf, err := img.ConfigFile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var createdBy []string
+			for _, h := range cf.History[2:] {
+				createdBy = append(createdBy, h.CreatedBy)
+			}
+			if want := []string{"agent", "sidecar"}; !slices.Equal(createdBy, want) {
+				t.Errorf("history: got %v, want %v", createdBy, want)
+			}
+
+			m, err := img.Manifest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.Layers[2].Annotations["a"]; got != "b" {
+				t.Errorf("annotation: got %q, want %q", got, "b")
+			}
+		})
+	}
+}
+
+func TestGraftMediaTypes(t *testing.T) {
+	dockerBase, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ociBase := mutate.MediaType(dockerBase, types.OCIManifestSchema1)
+
+	zstd, err := random.Layer(100, types.OCILayerZStd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ociDonor, err := mutate.AppendLayers(mutate.MediaType(empty.Image, types.OCIManifestSchema1), zstd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := func(int, v1.Layer) (bool, error) { return true, nil }
+
+	if _, err := mutate.Graft(dockerBase, ociDonor, all); err == nil || !strings.Contains(err.Error(), "can't graft") {
+		t.Errorf("Graft(docker, oci): got %v, want an error", err)
+	}
+	img, err := mutate.Graft(ociBase, ociDonor, all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(layerDigests(t, img)); n != 2 {
+		t.Errorf("got %d layers, want 2", n)
+	}
+	// Docker layers are fine in OCI images.
+	if _, err := mutate.Graft(ociBase, dockerBase, all); err != nil {
+		t.Errorf("Graft(oci, docker): %v", err)
+	}
+}
@@ -36,6 +36,8 @@ type image struct {
 	annotations     map[string]string
 	mediaType       *types.MediaType
 	configMediaType *types.MediaType
+	inlineConfig    *int64
+	canonical       bool
 	diffIDMap       map[v1.Hash]v1.Layer
 	digestMap       map[v1.Hash]v1.Layer
 	subject         *v1.Descriptor
@@ -126,7 +128,7 @@ func (i *image) compute() error {
 
 	manifest.Layers = manifestLayers
 
-	rcfg, err := json.Marshal(configFile)
+	rcfg, err := i.marshal(configFile)
 	if err != nil {
 		return err
 	}
@@ -141,6 +143,9 @@ func (i *image) compute() error {
 	if m.Config.Data != nil {
 		manifest.Config.Data = rcfg
 	}
+	if i.inlineConfig != nil {
+		manifest.Config = partial.Inline(manifest.Config, rcfg, *i.inlineConfig)
+	}
 
 	// If the user wants to mutate the media type of the config
 	if i.configMediaType != nil {
@@ -225,7 +230,7 @@ func (i *image) RawConfigFile() ([]byte, error) {
 	if err := i.compute(); err != nil {
 		return nil, err
 	}
-	return json.Marshal(i.configFile)
+	return i.marshal(i.configFile)
 }
 
 // Digest returns the sha256 of this image's manifest.
@@ -257,7 +262,15 @@ func (i *image) RawManifest() ([]byte, error) {
 	if err := i.compute(); err != nil {
 		return nil, err
 	}
-	return json.Marshal(i.manifest)
+	return i.marshal(i.manifest)
+}
+
+// marshal serializes v canonically if requested by CanonicalJSON.
+func (i *image) marshal(v any) ([]byte, error) {
+	if i.canonical {
+		return v1.MarshalCanonical(v)
+	}
+	return json.Marshal(v)
 }
 
 // LayerByDigest returns a Layer for interacting with a particular layer of
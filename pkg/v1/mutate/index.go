@@ -74,6 +74,8 @@ type index struct {
 	indexMap    map[v1.Hash]v1.ImageIndex
 	layerMap    map[v1.Hash]v1.Layer
 	subject     *v1.Descriptor
+	inline      *int64
+	canonical   bool
 
 	sync.Mutex
 }
@@ -137,6 +139,21 @@ func (i *index) compute() error {
 		}
 	}
 
+	if i.inline != nil {
+		for j, desc := range manifests {
+			if desc.Data != nil || desc.Size > *i.inline {
+				continue
+			}
+			b, err := i.childManifest(desc)
+			if err != nil {
+				return err
+			}
+			if b != nil {
+				manifests[j] = partial.Inline(desc, b, *i.inline)
+			}
+		}
+	}
+
 	manifest.Manifests = manifests
 
 	if i.mediaType != nil {
@@ -158,6 +175,33 @@ func (i *index) compute() error {
 	return nil
 }
 
+// childManifest returns the manifest of the image or index that desc
+// describes, or nil if it describes neither.
+func (i *index) childManifest(desc v1.Descriptor) ([]byte, error) {
+	var child partial.WithRawManifest
+	switch {
+	case desc.MediaType.IsImage():
+		if img, ok := i.imageMap[desc.Digest]; ok {
+			child = img
+		} else if img, err := i.base.Image(desc.Digest); err != nil {
+			return nil, err
+		} else {
+			child = img
+		}
+	case desc.MediaType.IsIndex():
+		if idx, ok := i.indexMap[desc.Digest]; ok {
+			child = idx
+		} else if idx, err := i.base.ImageIndex(desc.Digest); err != nil {
+			return nil, err
+		} else {
+			child = idx
+		}
+	default:
+		return nil, nil
+	}
+	return child.RawManifest()
+}
+
 func (i *index) Image(h v1.Hash) (v1.Image, error) {
 	if img, ok := i.imageMap[h]; ok {
 		return img, nil
@@ -208,6 +252,9 @@ func (i *index) RawManifest() ([]byte, error) {
 	if err := i.compute(); err != nil {
 		return nil, err
 	}
+	if i.canonical {
+		return v1.MarshalCanonical(i.manifest)
+	}
 	return json.Marshal(i.manifest)
 }
 
@@ -233,3 +233,51 @@ func TestAppend_ArtifactType(t *testing.T) {
 		})
 	}
 }
+
+func TestInlineManifests(t *testing.T) {
+	idx, err := random.Index(1024, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx = mutate.AppendManifests(idx, mutate.IndexAddendum{Add: img})
+
+	inlined := mutate.InlineManifests(idx, 1<<20)
+	if err := validate.Index(inlined); err != nil {
+		t.Fatalf("validate.Index: %v", err)
+	}
+	m, err := inlined.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, desc := range m.Manifests {
+		child, err := inlined.Image(desc.Digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := child.RawManifest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := partial.Data(desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Data (-want +got) = %s", diff)
+		}
+	}
+
+	m, err = mutate.InlineManifests(idx, 1).IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, desc := range m.Manifests {
+		if desc.Data != nil {
+			t.Errorf("InlineManifests(1) embedded %s", desc.Digest)
+		}
+	}
+}
@@ -26,7 +26,9 @@ import (
 	"strings"
 	"time"
 
+	comp "github.com/google/go-containerregistry/internal/compression"
 	"github.com/google/go-containerregistry/internal/gzip"
+	"github.com/google/go-containerregistry/pkg/compression"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/match"
@@ -448,6 +450,10 @@ func Time(img v1.Image, t time.Time) (v1.Image, error) {
 }
 
 func layerTime(layer v1.Layer, t time.Time) (v1.Layer, error) {
+	mt, err := layer.MediaType()
+	if err != nil {
+		return nil, fmt.Errorf("getting layer media type: %w", err)
+	}
 	layerReader, err := layer.Uncompressed()
 	if err != nil {
 		return nil, fmt.Errorf("getting layer: %w", err)
@@ -496,7 +502,19 @@ func layerTime(layer v1.Layer, t time.Time) (v1.Layer, error) {
 	opener := func() (io.ReadCloser, error) {
 		return gzip.ReadCloser(io.NopCloser(bytes.NewReader(b))), nil
 	}
-	layer, err = tarball.LayerFromOpener(opener)
+	var opts []tarball.LayerOption
+	// Keep zstd layers in zstd, rather than recompressing them.
+	if comp.ForMediaType(mt) == compression.ZStd {
+		codec, err := compression.Lookup(compression.ZStd)
+		if err != nil {
+			return nil, err
+		}
+		opener = func() (io.ReadCloser, error) {
+			return comp.ReadCloserLevel(io.NopCloser(bytes.NewReader(b)), codec, compression.DefaultLevel), nil
+		}
+		opts = append(opts, tarball.WithMediaType(mt))
+	}
+	layer, err = tarball.LayerFromOpener(opener, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("creating layer: %w", err)
 	}
@@ -506,6 +524,9 @@ func layerTime(layer v1.Layer, t time.Time) (v1.Layer, error) {
 
 // Canonical is a helper function to combine Time and configFile
 // to remove any randomness during a docker build.
+//
+// See CanonicalJSON to also make the serialization of the image independent
+// of the Go version.
 func Canonical(img v1.Image) (v1.Image, error) {
 	// Set all timestamps to 0
 	created := time.Time{}
@@ -554,3 +575,46 @@ func IndexMediaType(idx v1.ImageIndex, mt types.MediaType) v1.ImageIndex {
 		mediaType: &mt,
 	}
 }
+
+// InlineConfig embeds the given image's config in the Data field of its
+// manifest's config descriptor, if it's no larger than threshold bytes, so that
+// pulling the image takes one fewer round trip.
+func InlineConfig(img v1.Image, threshold int64) v1.Image {
+	return &image{
+		base:         img,
+		inlineConfig: &threshold,
+	}
+}
+
+// CanonicalJSON serializes the given image's manifest and config as canonical
+// JSON, see v1.MarshalCanonical, so that its digest doesn't change across Go
+// versions.
+func CanonicalJSON(img v1.Image) v1.Image {
+	return &image{
+		base:      img,
+		canonical: true,
+	}
+}
+
+// CanonicalJSONIndex serializes the given index's manifest as canonical JSON,
+// see v1.MarshalCanonical, so that its digest doesn't change across Go
+// versions.
+//
+// The index's children aren't changed; use CanonicalJSON on them as they're
+// added.
+func CanonicalJSONIndex(idx v1.ImageIndex) v1.ImageIndex {
+	return &index{
+		base:      idx,
+		canonical: true,
+	}
+}
+
+// InlineManifests embeds the manifests of the given index's children in the
+// Data fields of their descriptors, if they're no larger than threshold bytes,
+// so that pulling them takes one fewer round trip each.
+func InlineManifests(idx v1.ImageIndex, threshold int64) v1.ImageIndex {
+	return &index{
+		base:   idx,
+		inline: &threshold,
+	}
+}
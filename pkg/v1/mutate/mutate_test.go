@@ -28,12 +28,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-containerregistry/pkg/compression"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/match"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/static"
 	"github.com/google/go-containerregistry/pkg/v1/stream"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
@@ -768,3 +770,135 @@ func (m mockLayer) Compressed() (io.ReadCloser, error) {
 func (m mockLayer) Uncompressed() (io.ReadCloser, error) {
 	return io.NopCloser(strings.NewReader("uncompressed")), nil
 }
+
+func TestInlineConfig(t *testing.T) {
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inlined := mutate.InlineConfig(img, 1<<20)
+	if err := validate.Image(inlined); err != nil {
+		t.Fatalf("validate.Image: %v", err)
+	}
+	m, err := inlined.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := inlined.RawConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := partial.Data(m.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Config.Data (-want +got) = %s", diff)
+	}
+
+	// Later mutations keep the config inlined, and up to date.
+	appended, err := mutate.AppendLayers(inlined, static.NewLayer([]byte("hi"), types.OCILayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err = appended.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := partial.Data(m.Config); err != nil || m.Config.Data == nil {
+		t.Errorf("appended Config.Data = %v, %v", m.Config.Data, err)
+	}
+
+	m, err = mutate.InlineConfig(img, 1).Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Config.Data != nil {
+		t.Errorf("InlineConfig(1) embedded the config")
+	}
+}
+
+func TestTimeKeepsZstd(t *testing.T) {
+	rl, err := random.Layer(1024, types.OCILayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := tarball.LayerFromOpener(rl.Uncompressed, tarball.WithCompression(compression.ZStd), tarball.WithMediaType(types.OCILayerZStd))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := mutate.AppendLayers(mutate.MediaType(empty.Image, types.OCIManifestSchema1), l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err = mutate.Time(img, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt, err := layers[0].MediaType(); err != nil || mt != types.OCILayerZStd {
+		t.Errorf("MediaType() = %s, %v; want %s", mt, err, types.OCILayerZStd)
+	}
+	rc, err := layers[0].Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(rc, magic); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}) {
+		t.Errorf("layer starts with %x, want zstd", magic)
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img = mutate.Annotations(img, map[string]string{"b": "<two>", "a": "one"}).(v1.Image)
+
+	canonical := mutate.CanonicalJSON(img)
+	if err := validate.Image(canonical); err != nil {
+		t.Fatalf("validate.Image: %v", err)
+	}
+
+	m, err := canonical.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := v1.MarshalCanonical(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := canonical.RawManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("RawManifest (-want +got) = %s", diff)
+	}
+
+	cf, err := canonical.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err = v1.MarshalCanonical(cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = canonical.RawConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("RawConfigFile (-want +got) = %s", diff)
+	}
+}
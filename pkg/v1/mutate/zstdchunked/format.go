@@ -0,0 +1,281 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zstdchunked
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/klauspost/compress/zstd"
+	"github.com/vbatts/tar-split/tar/asm"
+	"github.com/vbatts/tar-split/tar/storage"
+)
+
+// Annotations that containers/storage reads from a zstd:chunked layer's
+// descriptor to find its table of contents without reading the footer.
+const (
+	ManifestChecksumAnnotation = "io.github.containers.zstd-chunked.manifest-checksum"
+	ManifestPositionAnnotation = "io.github.containers.zstd-chunked.manifest-position"
+	TarSplitChecksumAnnotation = "io.github.containers.zstd-chunked.tarsplit-checksum"
+	TarSplitPositionAnnotation = "io.github.containers.zstd-chunked.tarsplit-position"
+)
+
+const (
+	skippableFrameMagic = 0x184D2A50
+	manifestTypeCRFS    = 1
+	footerSize          = 64
+)
+
+// footerMagic ends every zstd:chunked layer.
+var footerMagic = []byte{0x47, 0x4e, 0x55, 0x6c, 0x49, 0x6e, 0x55, 0x78}
+
+// TOC is the table of contents embedded in a zstd:chunked layer.
+type TOC struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// Entry describes one file in a TOC. Offset and EndOffset locate the zstd
+// frame holding a regular file's content within the compressed layer.
+type Entry struct {
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Linkname  string            `json:"linkName,omitempty"`
+	Mode      int64             `json:"mode,omitempty"`
+	Size      int64             `json:"size,omitempty"`
+	UID       int               `json:"uid,omitempty"`
+	GID       int               `json:"gid,omitempty"`
+	Uname     string            `json:"userName,omitempty"`
+	Gname     string            `json:"groupName,omitempty"`
+	ModTime   *time.Time        `json:"modtime,omitempty"`
+	Devmajor  int64             `json:"devMajor,omitempty"`
+	Devminor  int64             `json:"devMinor,omitempty"`
+	Xattrs    map[string]string `json:"xattrs,omitempty"`
+	Digest    string            `json:"digest,omitempty"`
+	Offset    int64             `json:"offset,omitempty"`
+	EndOffset int64             `json:"endOffset,omitempty"`
+}
+
+var entryTypes = map[byte]string{
+	tar.TypeReg:     "reg",
+	tar.TypeRegA:    "reg", //nolint:staticcheck // Old tarballs still use it.
+	tar.TypeDir:     "dir",
+	tar.TypeSymlink: "symlink",
+	tar.TypeLink:    "hardlink",
+	tar.TypeChar:    "char",
+	tar.TypeBlock:   "block",
+	tar.TypeFifo:    "fifo",
+}
+
+// rawReader hashes and counts the bytes read through it, keeping them in meta
+// while capture is set. It deliberately hides any io.Seeker so that
+// archive/tar reads, and counts, every byte.
+type rawReader struct {
+	r       io.Reader
+	h       hash.Hash
+	capture bool
+	meta    []byte
+}
+
+func (r *rawReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.h.Write(p[:n])
+	if r.capture {
+		r.meta = append(r.meta, p[:n]...)
+	}
+	return n, err
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// compress writes the uncompressed tarball r to w as a zstd:chunked blob, and
+// returns the tarball's digest and the annotations describing the blob.
+//
+// Tar headers are grouped into frames of their own, and each regular file's
+// content gets its own frame, so that files can be fetched individually. The
+// TOC, the tar-split metadata needed to rebuild the exact tarball, and a
+// footer locating both follow as skippable frames, which plain zstd
+// decompressors ignore. Only the headers of one file are held in memory at a
+// time; contents are streamed.
+func compress(r io.Reader, w io.Writer, level zstd.EncoderLevel) (v1.Hash, map[string]string, error) {
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level), zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return v1.Hash{}, nil, err
+	}
+	defer enc.Close()
+
+	var rawTarSplit bytes.Buffer
+	ts, err := asm.NewInputTarStream(r, storage.NewJSONPacker(&rawTarSplit), storage.NewDiscardFilePutter())
+	if err != nil {
+		return v1.Hash{}, nil, fmt.Errorf("reading tar-split: %w", err)
+	}
+	in := &rawReader{r: ts, h: sha256.New(), capture: true}
+	out := &countingWriter{w: w}
+	toc := TOC{Version: 1}
+
+	flush := func() error {
+		if len(in.meta) == 0 {
+			return nil
+		}
+		_, err := out.Write(enc.EncodeAll(in.meta, nil))
+		in.meta = in.meta[:0]
+		return err
+	}
+
+	tr := tar.NewReader(in)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return v1.Hash{}, nil, fmt.Errorf("reading tar: %w", err)
+		}
+
+		typ, ok := entryTypes[hdr.Typeflag]
+		if !ok {
+			continue
+		}
+		e := Entry{
+			Type:     typ,
+			Name:     hdr.Name,
+			Linkname: hdr.Linkname,
+			Mode:     hdr.Mode,
+			UID:      hdr.Uid,
+			GID:      hdr.Gid,
+			Uname:    hdr.Uname,
+			Gname:    hdr.Gname,
+			Devmajor: hdr.Devmajor,
+			Devminor: hdr.Devminor,
+		}
+		if !hdr.ModTime.IsZero() {
+			mt := hdr.ModTime.UTC()
+			e.ModTime = &mt
+		}
+		for k, v := range hdr.PAXRecords {
+			if xk, ok := strings.CutPrefix(k, "SCHILY.xattr."); ok {
+				if e.Xattrs == nil {
+					e.Xattrs = map[string]string{}
+				}
+				e.Xattrs[xk] = base64.StdEncoding.EncodeToString([]byte(v))
+			}
+		}
+
+		if typ == "reg" && hdr.Size > 0 {
+			// Everything since the end of the previous file's content is
+			// padding and headers.
+			if err := flush(); err != nil {
+				return v1.Hash{}, nil, err
+			}
+			sum := sha256.New()
+			e.Offset = out.n
+			in.capture = false
+			enc.Reset(out)
+			if _, err := io.Copy(io.MultiWriter(enc, sum), tr); err != nil {
+				return v1.Hash{}, nil, fmt.Errorf("reading %s: %w", hdr.Name, err)
+			}
+			if err := enc.Close(); err != nil {
+				return v1.Hash{}, nil, err
+			}
+			in.capture = true
+			e.Size = hdr.Size
+			e.Digest = "sha256:" + hex.EncodeToString(sum.Sum(nil))
+			e.EndOffset = out.n
+		}
+		toc.Entries = append(toc.Entries, e)
+	}
+	// The end-of-archive marker, and anything after it.
+	if _, err := io.Copy(io.Discard, in); err != nil {
+		return v1.Hash{}, nil, fmt.Errorf("reading tar: %w", err)
+	}
+	if err := flush(); err != nil {
+		return v1.Hash{}, nil, err
+	}
+
+	rawTOC, err := json.Marshal(toc)
+	if err != nil {
+		return v1.Hash{}, nil, err
+	}
+	tocZ := enc.EncodeAll(rawTOC, nil)
+	tocOffset, err := writeSkippable(out, tocZ)
+	if err != nil {
+		return v1.Hash{}, nil, err
+	}
+	tsZ := enc.EncodeAll(rawTarSplit.Bytes(), nil)
+	tsOffset, err := writeSkippable(out, tsZ)
+	if err != nil {
+		return v1.Hash{}, nil, err
+	}
+
+	footer := make([]byte, 0, footerSize)
+	for _, v := range []uint64{
+		uint64(tocOffset), uint64(len(tocZ)), uint64(len(rawTOC)), manifestTypeCRFS,
+		uint64(tsOffset), uint64(len(tsZ)), uint64(rawTarSplit.Len()),
+	} {
+		footer = binary.LittleEndian.AppendUint64(footer, v)
+	}
+	footer = append(footer, footerMagic...)
+	if _, err := writeSkippable(out, footer); err != nil {
+		return v1.Hash{}, nil, err
+	}
+
+	annotations := map[string]string{
+		ManifestChecksumAnnotation: digest(tocZ),
+		ManifestPositionAnnotation: fmt.Sprintf("%d:%d:%d:%d", tocOffset, len(tocZ), len(rawTOC), manifestTypeCRFS),
+		TarSplitChecksumAnnotation: digest(tsZ),
+		TarSplitPositionAnnotation: fmt.Sprintf("%d:%d:%d", tsOffset, len(tsZ), rawTarSplit.Len()),
+	}
+	diffID := v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(in.h.Sum(nil))}
+	return diffID, annotations, nil
+}
+
+// writeSkippable writes data to out as a skippable frame, returning the
+// offset of data within out.
+func writeSkippable(out *countingWriter, data []byte) (int64, error) {
+	hdr := binary.LittleEndian.AppendUint32(nil, skippableFrameMagic)
+	hdr = binary.LittleEndian.AppendUint32(hdr, uint32(len(data)))
+	if _, err := out.Write(hdr); err != nil {
+		return 0, err
+	}
+	off := out.n
+	_, err := out.Write(data)
+	return off, err
+}
+
+func digest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,304 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package zstdchunked converts layers and images to zstd:chunked, so that
+// they can be pulled lazily by podman and CRI-O, which fetch only the files
+// they don't already have.
+//
+// A zstd:chunked layer is an ordinary zstd-compressed tarball, so runtimes
+// that don't support lazy pulling can still use it. Its table of contents is
+// embedded in skippable frames, and located by the annotations on the
+// layer's descriptor.
+package zstdchunked
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Option is a functional option for Layer, NewLayer and Image.
+type Option func(*options)
+
+type options struct {
+	level zstd.EncoderLevel
+}
+
+// WithCompressionLevel sets the zstd compression level of the layers, from 1
+// (fastest) to 22 (smallest).
+func WithCompressionLevel(level int) Option {
+	return func(o *options) {
+		o.level = zstd.EncoderLevelFromZstd(level)
+	}
+}
+
+func makeOptions(opts ...Option) *options {
+	o := &options{level: zstd.SpeedDefault}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+type layer struct {
+	// open returns the uncompressed tarball to convert. It's only called
+	// once.
+	open func() (io.ReadCloser, error)
+	// base is the layer being converted, if any, which knows its own
+	// uncompressed contents and DiffID.
+	base  v1.Layer
+	level zstd.EncoderLevel
+
+	once        sync.Once
+	err         error
+	blob        *spooled
+	digest      v1.Hash
+	diffID      v1.Hash
+	annotations map[string]string
+}
+
+var _ v1.Layer = (*layer)(nil)
+
+// Layer returns l converted to zstd:chunked. The conversion happens the first
+// time the layer's compressed contents, digest or size are needed.
+func Layer(l v1.Layer, opts ...Option) (v1.Layer, error) {
+	return &layer{
+		open:  l.Uncompressed,
+		base:  l,
+		level: makeOptions(opts...).level,
+	}, nil
+}
+
+// NewLayer returns a zstd:chunked layer with the contents of the uncompressed
+// tarball r. The conversion happens the first time the layer's compressed
+// contents, digest or size are needed, so r must stay readable until then.
+//
+// The converted layer is spooled to a temporary file, which is removed once
+// the layer is no longer reachable.
+func NewLayer(r io.Reader, opts ...Option) (v1.Layer, error) {
+	return &layer{
+		open:  func() (io.ReadCloser, error) { return io.NopCloser(r), nil },
+		level: makeOptions(opts...).level,
+	}, nil
+}
+
+// convert converts the layer, if it hasn't already.
+func (l *layer) convert() error {
+	l.once.Do(func() {
+		l.err = func() error {
+			rc, err := l.open()
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+
+			blob, err := newSpooled()
+			if err != nil {
+				return err
+			}
+			h := sha256.New()
+			diffID, annotations, err := compress(rc, io.MultiWriter(blob, h), l.level)
+			if err != nil {
+				return err
+			}
+			l.blob = blob
+			l.digest = v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(h.Sum(nil))}
+			l.diffID = diffID
+			l.annotations = annotations
+			return nil
+		}()
+	})
+	return l.err
+}
+
+// Digest implements v1.Layer.
+func (l *layer) Digest() (v1.Hash, error) {
+	if err := l.convert(); err != nil {
+		return v1.Hash{}, err
+	}
+	return l.digest, nil
+}
+
+// DiffID implements v1.Layer.
+func (l *layer) DiffID() (v1.Hash, error) {
+	if l.base != nil {
+		return l.base.DiffID()
+	}
+	if err := l.convert(); err != nil {
+		return v1.Hash{}, err
+	}
+	return l.diffID, nil
+}
+
+// Compressed implements v1.Layer.
+func (l *layer) Compressed() (io.ReadCloser, error) {
+	if err := l.convert(); err != nil {
+		return nil, err
+	}
+	return l.blob.open(), nil
+}
+
+// Uncompressed implements v1.Layer.
+func (l *layer) Uncompressed() (io.ReadCloser, error) {
+	if l.base != nil {
+		return l.base.Uncompressed()
+	}
+	if err := l.convert(); err != nil {
+		return nil, err
+	}
+	// A zstd:chunked layer is still a zstd stream of the tarball.
+	dec, err := zstd.NewReader(l.blob.open())
+	if err != nil {
+		return nil, err
+	}
+	return dec.IOReadCloser(), nil
+}
+
+// Size implements v1.Layer.
+func (l *layer) Size() (int64, error) {
+	if err := l.convert(); err != nil {
+		return 0, err
+	}
+	return l.blob.size, nil
+}
+
+// MediaType implements v1.Layer.
+func (l *layer) MediaType() (types.MediaType, error) {
+	return types.OCILayerZStd, nil
+}
+
+// Descriptor implements partial.withDescriptor, so that the annotations
+// locating the TOC end up in the manifest.
+func (l *layer) Descriptor() (*v1.Descriptor, error) {
+	if err := l.convert(); err != nil {
+		return nil, err
+	}
+	return &v1.Descriptor{
+		MediaType:   types.OCILayerZStd,
+		Size:        l.blob.size,
+		Digest:      l.digest,
+		Annotations: l.annotations,
+	}, nil
+}
+
+// spooled is a converted layer in a temporary file.
+type spooled struct {
+	f    *os.File
+	size int64
+}
+
+func newSpooled() (*spooled, error) {
+	f, err := os.CreateTemp("", "zstdchunked-*")
+	if err != nil {
+		return nil, err
+	}
+	s := &spooled{f: f}
+
+	// Unlink the file right away where we can, so nothing is left behind,
+	// otherwise (i.e. on Windows) remove it once we're unreachable.
+	removed := os.Remove(f.Name()) == nil
+	runtime.SetFinalizer(s, func(s *spooled) {
+		s.f.Close()
+		if !removed {
+			os.Remove(s.f.Name())
+		}
+	})
+	return s, nil
+}
+
+func (s *spooled) Write(p []byte) (int, error) {
+	n, err := s.f.WriteAt(p, s.size)
+	s.size += int64(n)
+	return n, err
+}
+
+// open returns a reader of the spooled contents, which keeps s reachable
+// while it's in use.
+func (s *spooled) open() io.ReadCloser {
+	return &spooledReader{SectionReader: io.NewSectionReader(s.f, 0, s.size), s: s}
+}
+
+type spooledReader struct {
+	*io.SectionReader
+	s *spooled
+}
+
+func (r *spooledReader) Close() error {
+	r.s = nil
+	return nil
+}
+
+// Image returns img with each of its layers converted to zstd:chunked,
+// keeping its config and history. Since Docker manifests can't refer to zstd
+// layers, the result always uses OCI media types.
+func Image(img v1.Image, opts ...Option) (v1.Image, error) {
+	ocfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := ocfg.DeepCopy()
+	cfg.History = nil
+	cfg.RootFS.DiffIDs = nil
+	base, err := mutate.ConfigFile(empty.Image, cfg)
+	if err != nil {
+		return nil, err
+	}
+	base = mutate.ConfigMediaType(mutate.MediaType(base, types.OCIManifestSchema1), types.OCIConfigJSON)
+
+	// Pair each layer with its history; if they don't line up, drop the
+	// history rather than misattribute it.
+	history := ocfg.History
+	nonEmpty := 0
+	for _, h := range history {
+		if !h.EmptyLayer {
+			nonEmpty++
+		}
+	}
+	if nonEmpty != len(layers) {
+		history = make([]v1.History, len(layers))
+	}
+
+	var adds []mutate.Addendum
+	i := 0
+	for _, h := range history {
+		if h.EmptyLayer {
+			adds = append(adds, mutate.Addendum{History: h})
+			continue
+		}
+		// Layers are only converted when they're needed, e.g. as
+		// they're written.
+		l, err := Layer(layers[i], opts...)
+		if err != nil {
+			return nil, fmt.Errorf("converting layer %d: %w", i, err)
+		}
+		adds = append(adds, mutate.Addendum{Layer: l, History: h})
+		i++
+	}
+	return mutate.Append(base, adds...)
+}
@@ -0,0 +1,274 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zstdchunked
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+	"github.com/klauspost/compress/zstd"
+)
+
+func testTar(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, hdr := range []*tar.Header{
+		{Name: "etc/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "etc/hosts", Typeflag: tar.TypeReg, Mode: 0o644, Size: 10},
+		{Name: "etc/empty", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "etc/link", Typeflag: tar.TypeSymlink, Linkname: "hosts"},
+		{
+			Name: "bin/ping", Typeflag: tar.TypeReg, Mode: 0o755, Size: 700,
+			PAXRecords: map[string]string{"SCHILY.xattr.security.capability": "cap"},
+		},
+	} {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(bytes.Repeat([]byte{'x'}, int(hdr.Size))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestNewLayer(t *testing.T) {
+	in := testTar(t)
+	l, err := NewLayer(bytes.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Plain zstd decompressors skip the metadata and see the original tar.
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	got, err := dec.DecodeAll(blob, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, in) {
+		t.Error("decompressed layer doesn't match the input")
+	}
+	if d, err := l.Digest(); err != nil || d.String() != digest(blob) {
+		t.Errorf("Digest() = %v, %v; want %v", d, err, digest(blob))
+	}
+	if d, err := l.DiffID(); err != nil || d.String() != digest(in) {
+		t.Errorf("DiffID() = %v, %v; want %v", d, err, digest(in))
+	}
+	if n, err := l.Size(); err != nil || n != int64(len(blob)) {
+		t.Errorf("Size() = %d, %v; want %d", n, err, len(blob))
+	}
+	rc, err = l.Uncompressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if got, err := io.ReadAll(rc); err != nil || !bytes.Equal(got, in) {
+		t.Errorf("Uncompressed() doesn't match the input: %v", err)
+	}
+
+	// The footer locates the TOC, as do the annotations.
+	footer := blob[len(blob)-footerSize:]
+	if !bytes.Equal(footer[56:], footerMagic) {
+		t.Fatalf("footer magic = %x", footer[56:])
+	}
+	off := binary.LittleEndian.Uint64(footer[0:])
+	size := binary.LittleEndian.Uint64(footer[8:])
+	if typ := binary.LittleEndian.Uint64(footer[24:]); typ != manifestTypeCRFS {
+		t.Errorf("manifest type = %d", typ)
+	}
+	desc, err := l.(*layer).Descriptor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.MediaType != types.OCILayerZStd {
+		t.Errorf("MediaType = %s", desc.MediaType)
+	}
+	pos := desc.Annotations[ManifestPositionAnnotation]
+	if want := fmt.Sprintf("%d:%d:", off, size); pos[:len(want)] != want {
+		t.Errorf("%s = %s, want prefix %s", ManifestPositionAnnotation, pos, want)
+	}
+	if got, want := desc.Annotations[ManifestChecksumAnnotation], digest(blob[off:off+size]); got != want {
+		t.Errorf("%s = %s, want %s", ManifestChecksumAnnotation, got, want)
+	}
+
+	rawTOC, err := dec.DecodeAll(blob[off:off+size], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var toc TOC
+	if err := json.Unmarshal(rawTOC, &toc); err != nil {
+		t.Fatal(err)
+	}
+	if len(toc.Entries) != 5 {
+		t.Fatalf("len(Entries) = %d, want 5", len(toc.Entries))
+	}
+	for _, e := range toc.Entries {
+		if e.Size == 0 {
+			if e.Offset != 0 || e.EndOffset != 0 {
+				t.Errorf("%s: unexpected offsets %d-%d", e.Name, e.Offset, e.EndOffset)
+			}
+			continue
+		}
+		// Each file's frame decompresses on its own.
+		content, err := dec.DecodeAll(blob[e.Offset:e.EndOffset], nil)
+		if err != nil {
+			t.Fatalf("%s: %v", e.Name, err)
+		}
+		if int64(len(content)) != e.Size || digest(content) != e.Digest {
+			t.Errorf("%s: content doesn't match TOC", e.Name)
+		}
+	}
+	if got := toc.Entries[4].Xattrs["security.capability"]; got != "Y2Fw" {
+		t.Errorf("xattr = %q, want base64 of %q", got, "cap")
+	}
+	if got := toc.Entries[3]; got.Type != "symlink" || got.Linkname != "hosts" {
+		t.Errorf("symlink entry = %+v", got)
+	}
+}
+
+// readCounter counts the reads of a tarball.
+type readCounter struct {
+	r     io.Reader
+	reads int
+}
+
+func (r *readCounter) Read(p []byte) (int, error) {
+	r.reads++
+	return r.r.Read(p)
+}
+
+func TestLazy(t *testing.T) {
+	r := &readCounter{r: bytes.NewReader(testTar(t))}
+	l, err := NewLayer(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.reads != 0 {
+		t.Fatalf("NewLayer read the tarball")
+	}
+	if _, err := l.MediaType(); err != nil {
+		t.Fatal(err)
+	}
+	if r.reads != 0 {
+		t.Fatalf("MediaType read the tarball")
+	}
+	d, err := l.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reads := r.reads
+	if reads == 0 {
+		t.Fatalf("Digest didn't read the tarball")
+	}
+	// The conversion only happens once.
+	if again, err := l.Digest(); err != nil || again != d {
+		t.Errorf("Digest() = %v, %v; want %v", again, err, d)
+	}
+	if _, err := l.Compressed(); err != nil {
+		t.Fatal(err)
+	}
+	if r.reads != reads {
+		t.Errorf("Compressed read the tarball again")
+	}
+}
+
+// openCounter counts how many times its layers' contents are opened.
+type openCounter struct {
+	v1.Image
+	opened *int
+}
+
+func (i *openCounter) Layers() ([]v1.Layer, error) {
+	layers, err := i.Image.Layers()
+	if err != nil {
+		return nil, err
+	}
+	for j, l := range layers {
+		layers[j] = &layerOpenCounter{Layer: l, opened: i.opened}
+	}
+	return layers, nil
+}
+
+type layerOpenCounter struct {
+	v1.Layer
+	opened *int
+}
+
+func (l *layerOpenCounter) Uncompressed() (io.ReadCloser, error) {
+	*l.opened++
+	return l.Layer.Uncompressed()
+}
+
+func TestImage(t *testing.T) {
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img = mutate.MediaType(img, types.DockerManifestSchema2)
+
+	opened := 0
+	converted, err := Image(&openCounter{Image: img, opened: &opened})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened != 0 {
+		t.Errorf("Image converted %d layers before they were needed", opened)
+	}
+	// validate only understands gzip layers, so skip their contents.
+	if err := validate.Image(converted, validate.Fast); err != nil {
+		t.Fatalf("validate.Image() = %v", err)
+	}
+	m, err := converted.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.MediaType != types.OCIManifestSchema1 {
+		t.Errorf("MediaType = %s", m.MediaType)
+	}
+	for _, l := range m.Layers {
+		if l.MediaType != types.OCILayerZStd {
+			t.Errorf("layer MediaType = %s", l.MediaType)
+		}
+		if _, ok := l.Annotations[ManifestPositionAnnotation]; !ok {
+			t.Errorf("layer %s is missing %s", l.Digest, ManifestPositionAnnotation)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package partial
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/google/go-containerregistry/internal/verify"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// BlobProvider is a content-addressable store of blobs, e.g. a containerd
+// content store or a database, from which images can be read with
+// ImageFromBlobs and IndexFromBlobs.
+type BlobProvider interface {
+	// Get returns the contents of the blob with the given digest.
+	Get(h v1.Hash) (io.ReadCloser, error)
+}
+
+// ImageFromBlobs returns the v1.Image whose manifest has the digest h in bp.
+//
+// Every blob is verified against its digest as it's read.
+func ImageFromBlobs(bp BlobProvider, h v1.Hash) (v1.Image, error) {
+	b, err := readBlob(bp, h, verify.SizeUnknown)
+	if err != nil {
+		return nil, err
+	}
+	mt, err := manifestMediaType(b, types.OCIManifestSchema1)
+	if err != nil {
+		return nil, err
+	}
+	if !mt.IsImage() {
+		return nil, fmt.Errorf("unexpected media type for image %s: %s", h, mt)
+	}
+	return CompressedToImage(&blobImage{
+		bp:          bp,
+		rawManifest: b,
+		desc: v1.Descriptor{
+			MediaType: mt,
+			Digest:    h,
+			Size:      int64(len(b)),
+		},
+	})
+}
+
+// IndexFromBlobs returns the v1.ImageIndex whose manifest has the digest h in
+// bp, which must have the manifests of its children too.
+//
+// Every blob is verified against its digest as it's read.
+func IndexFromBlobs(bp BlobProvider, h v1.Hash) (v1.ImageIndex, error) {
+	b, err := readBlob(bp, h, verify.SizeUnknown)
+	if err != nil {
+		return nil, err
+	}
+	mt, err := manifestMediaType(b, types.OCIImageIndex)
+	if err != nil {
+		return nil, err
+	}
+	if !mt.IsIndex() {
+		return nil, fmt.Errorf("unexpected media type for index %s: %s", h, mt)
+	}
+	return &blobIndex{
+		bp:          bp,
+		rawManifest: b,
+		desc: v1.Descriptor{
+			MediaType: mt,
+			Digest:    h,
+			Size:      int64(len(b)),
+		},
+	}, nil
+}
+
+// manifestMediaType returns the mediaType that the manifest b claims, or def.
+func manifestMediaType(b []byte, def types.MediaType) (types.MediaType, error) {
+	var m struct {
+		MediaType types.MediaType `json:"mediaType"`
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return "", err
+	}
+	if m.MediaType == "" {
+		return def, nil
+	}
+	return m.MediaType, nil
+}
+
+// openBlob opens the blob with the digest h in bp, verifying that it has that
+// digest, and the given size unless it's verify.SizeUnknown.
+func openBlob(bp BlobProvider, h v1.Hash, size int64) (io.ReadCloser, error) {
+	rc, err := bp.Get(h)
+	if err != nil {
+		return nil, err
+	}
+	return verify.ReadCloser(rc, size, h)
+}
+
+func readBlob(bp BlobProvider, h v1.Hash, size int64) ([]byte, error) {
+	rc, err := openBlob(bp, h, size)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+type blobImage struct {
+	bp          BlobProvider
+	desc        v1.Descriptor
+	rawManifest []byte
+
+	once     sync.Once
+	manifest *v1.Manifest
+	err      error
+}
+
+var _ CompressedImageCore = (*blobImage)(nil)
+
+func (i *blobImage) MediaType() (types.MediaType, error) {
+	return i.desc.MediaType, nil
+}
+
+func (i *blobImage) RawManifest() ([]byte, error) {
+	return i.rawManifest, nil
+}
+
+// Implements WithManifest for partial.Blobset.
+func (i *blobImage) Manifest() (*v1.Manifest, error) {
+	i.once.Do(func() {
+		i.manifest, i.err = Manifest(i)
+	})
+	return i.manifest, i.err
+}
+
+func (i *blobImage) RawConfigFile() ([]byte, error) {
+	m, err := i.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	if b, err := Data(m.Config); err != nil || b != nil {
+		return b, err
+	}
+	return readBlob(i.bp, m.Config.Digest, m.Config.Size)
+}
+
+func (i *blobImage) LayerByDigest(h v1.Hash) (CompressedLayer, error) {
+	desc, err := BlobDescriptor(i, h)
+	if err != nil {
+		return nil, err
+	}
+	return &blobLayer{bp: i.bp, desc: *desc}, nil
+}
+
+// Descriptor implements partial.withDescriptor.
+func (i *blobImage) Descriptor() (*v1.Descriptor, error) {
+	return &i.desc, nil
+}
+
+type blobLayer struct {
+	bp   BlobProvider
+	desc v1.Descriptor
+}
+
+func (l *blobLayer) Digest() (v1.Hash, error) {
+	return l.desc.Digest, nil
+}
+
+func (l *blobLayer) Compressed() (io.ReadCloser, error) {
+	return openBlob(l.bp, l.desc.Digest, l.desc.Size)
+}
+
+func (l *blobLayer) Size() (int64, error) {
+	return l.desc.Size, nil
+}
+
+func (l *blobLayer) MediaType() (types.MediaType, error) {
+	return l.desc.MediaType, nil
+}
+
+// Descriptor implements partial.withDescriptor.
+func (l *blobLayer) Descriptor() (*v1.Descriptor, error) {
+	return &l.desc, nil
+}
+
+type blobIndex struct {
+	bp          BlobProvider
+	desc        v1.Descriptor
+	rawManifest []byte
+}
+
+var _ v1.ImageIndex = (*blobIndex)(nil)
+
+func (i *blobIndex) MediaType() (types.MediaType, error) {
+	return i.desc.MediaType, nil
+}
+
+func (i *blobIndex) Digest() (v1.Hash, error) {
+	return i.desc.Digest, nil
+}
+
+func (i *blobIndex) Size() (int64, error) {
+	return i.desc.Size, nil
+}
+
+func (i *blobIndex) IndexManifest() (*v1.IndexManifest, error) {
+	return v1.ParseIndexManifest(bytes.NewReader(i.rawManifest))
+}
+
+func (i *blobIndex) RawManifest() ([]byte, error) {
+	return i.rawManifest, nil
+}
+
+// Descriptor implements partial.withDescriptor.
+func (i *blobIndex) Descriptor() (*v1.Descriptor, error) {
+	return &i.desc, nil
+}
+
+func (i *blobIndex) Image(h v1.Hash) (v1.Image, error) {
+	desc, b, err := i.child(h)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MediaType.IsImage() {
+		return nil, fmt.Errorf("unexpected media type for %v: %s", h, desc.MediaType)
+	}
+	return CompressedToImage(&blobImage{
+		bp:          i.bp,
+		desc:        *desc,
+		rawManifest: b,
+	})
+}
+
+func (i *blobIndex) ImageIndex(h v1.Hash) (v1.ImageIndex, error) {
+	desc, b, err := i.child(h)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MediaType.IsIndex() {
+		return nil, fmt.Errorf("unexpected media type for %v: %s", h, desc.MediaType)
+	}
+	return &blobIndex{
+		bp:          i.bp,
+		desc:        *desc,
+		rawManifest: b,
+	}, nil
+}
+
+// child returns the descriptor and manifest of the child with digest h.
+func (i *blobIndex) child(h v1.Hash) (*v1.Descriptor, []byte, error) {
+	m, err := i.IndexManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, desc := range m.Manifests {
+		if desc.Digest != h {
+			continue
+		}
+		b, err := Data(desc)
+		if err != nil {
+			return nil, nil, err
+		}
+		if b == nil {
+			if b, err = readBlob(i.bp, desc.Digest, desc.Size); err != nil {
+				return nil, nil, err
+			}
+		}
+		return &desc, b, nil
+	}
+	return nil, nil, fmt.Errorf("could not find descriptor in index: %s", h)
+}
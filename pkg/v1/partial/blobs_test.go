@@ -0,0 +1,142 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package partial_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+type blobs map[v1.Hash][]byte
+
+func (b blobs) Get(h v1.Hash) (io.ReadCloser, error) {
+	blob, ok := b[h]
+	if !ok {
+		return nil, fmt.Errorf("blob %s not found", h)
+	}
+	return io.NopCloser(bytes.NewReader(blob)), nil
+}
+
+func (b blobs) addManifest(t *testing.T, m partial.WithRawManifest) v1.Hash {
+	t.Helper()
+	raw, err := m.RawManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, _, err := v1.SHA256(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[h] = raw
+	return h
+}
+
+func (b blobs) addImage(t *testing.T, img v1.Image) v1.Hash {
+	t.Helper()
+	cfg, err := img.RawConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := img.ConfigName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[name] = cfg
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range layers {
+		d, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rc, err := l.Compressed()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b[d], err = io.ReadAll(rc); err != nil {
+			t.Fatal(err)
+		}
+		rc.Close()
+	}
+	return b.addManifest(t, img)
+}
+
+func TestIndexFromBlobs(t *testing.T) {
+	idx, err := random.Index(1024, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := blobs{}
+	m, err := idx.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, desc := range m.Manifests {
+		img, err := idx.Image(desc.Digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.addImage(t, img)
+	}
+	h := b.addManifest(t, idx)
+
+	got, err := partial.IndexFromBlobs(b, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Index(got); err != nil {
+		t.Errorf("validate.Index() = %v", err)
+	}
+	if d, err := got.Digest(); err != nil || d != h {
+		t.Errorf("Digest() = %v, %v; want %v", d, err, h)
+	}
+	if _, err := partial.ImageFromBlobs(b, h); err == nil {
+		t.Error("ImageFromBlobs(index): expected error")
+	}
+
+	img, err := partial.ImageFromBlobs(b, m.Manifests[0].Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Image(img); err != nil {
+		t.Errorf("validate.Image() = %v", err)
+	}
+	if _, err := partial.IndexFromBlobs(b, m.Manifests[0].Digest); err == nil {
+		t.Error("IndexFromBlobs(image): expected error")
+	}
+
+	// Corrupt blobs are caught when they're read.
+	mf, err := img.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[mf.Layers[0].Digest] = []byte("corrupt")
+	if err := validate.Image(img); err == nil {
+		t.Error("validate.Image(corrupt) = nil")
+	}
+	b[h] = []byte("{}")
+	if _, err := partial.IndexFromBlobs(b, h); err == nil {
+		t.Error("IndexFromBlobs(corrupt) = nil")
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package partial
+
+import (
+	"sync"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// WithCache returns a v1.Image that memoizes the manifest, config file,
+// digests and layer metadata of img, so that calling those accessors
+// repeatedly, e.g. on a remote image, doesn't fetch and parse them each time.
+//
+// Layer contents aren't cached; see pkg/v1/cache for that. Errors are
+// memoized too, so a failed fetch isn't retried.
+func WithCache(img v1.Image) v1.Image {
+	ci := &cachedImage{
+		base:          img,
+		mediaType:     sync.OnceValues(img.MediaType),
+		size:          sync.OnceValues(img.Size),
+		digest:        sync.OnceValues(img.Digest),
+		rawManifest:   sync.OnceValues(img.RawManifest),
+		manifest:      sync.OnceValues(img.Manifest),
+		configName:    sync.OnceValues(img.ConfigName),
+		rawConfigFile: sync.OnceValues(img.RawConfigFile),
+		configFile:    sync.OnceValues(img.ConfigFile),
+		descriptor:    sync.OnceValues(func() (*v1.Descriptor, error) { return Descriptor(img) }),
+		byDigest:      map[v1.Hash]v1.Layer{},
+		byDiffID:      map[v1.Hash]v1.Layer{},
+	}
+	ci.layers = sync.OnceValues(func() ([]v1.Layer, error) {
+		ls, err := img.Layers()
+		if err != nil {
+			return nil, err
+		}
+		cls := make([]v1.Layer, 0, len(ls))
+		for _, l := range ls {
+			cls = append(cls, cacheLayer(l))
+		}
+		return cls, nil
+	})
+	return ci
+}
+
+type cachedImage struct {
+	base v1.Image
+
+	mediaType     func() (types.MediaType, error)
+	size          func() (int64, error)
+	digest        func() (v1.Hash, error)
+	rawManifest   func() ([]byte, error)
+	manifest      func() (*v1.Manifest, error)
+	configName    func() (v1.Hash, error)
+	rawConfigFile func() ([]byte, error)
+	configFile    func() (*v1.ConfigFile, error)
+	descriptor    func() (*v1.Descriptor, error)
+	layers        func() ([]v1.Layer, error)
+
+	sync.Mutex
+	byDigest map[v1.Hash]v1.Layer
+	byDiffID map[v1.Hash]v1.Layer
+}
+
+var _ v1.Image = (*cachedImage)(nil)
+
+// MediaType implements v1.Image.
+func (i *cachedImage) MediaType() (types.MediaType, error) {
+	return i.mediaType()
+}
+
+// Size implements v1.Image.
+func (i *cachedImage) Size() (int64, error) {
+	return i.size()
+}
+
+// Digest implements v1.Image.
+func (i *cachedImage) Digest() (v1.Hash, error) {
+	return i.digest()
+}
+
+// RawManifest implements v1.Image.
+func (i *cachedImage) RawManifest() ([]byte, error) {
+	return i.rawManifest()
+}
+
+// Manifest implements v1.Image.
+func (i *cachedImage) Manifest() (*v1.Manifest, error) {
+	m, err := i.manifest()
+	if err != nil {
+		return nil, err
+	}
+	// Callers may mutate what they get back.
+	return m.DeepCopy(), nil
+}
+
+// ConfigName implements v1.Image.
+func (i *cachedImage) ConfigName() (v1.Hash, error) {
+	return i.configName()
+}
+
+// RawConfigFile implements v1.Image.
+func (i *cachedImage) RawConfigFile() ([]byte, error) {
+	return i.rawConfigFile()
+}
+
+// ConfigFile implements v1.Image.
+func (i *cachedImage) ConfigFile() (*v1.ConfigFile, error) {
+	cf, err := i.configFile()
+	if err != nil {
+		return nil, err
+	}
+	// Callers may mutate what they get back.
+	return cf.DeepCopy(), nil
+}
+
+// Descriptor implements partial.withDescriptor.
+func (i *cachedImage) Descriptor() (*v1.Descriptor, error) {
+	d, err := i.descriptor()
+	if err != nil {
+		return nil, err
+	}
+	cp := *d
+	return &cp, nil
+}
+
+// Layers implements v1.Image.
+func (i *cachedImage) Layers() ([]v1.Layer, error) {
+	ls, err := i.layers()
+	if err != nil {
+		return nil, err
+	}
+	return append([]v1.Layer(nil), ls...), nil
+}
+
+// LayerByDigest implements v1.Image.
+func (i *cachedImage) LayerByDigest(h v1.Hash) (v1.Layer, error) {
+	return i.layerBy(h, i.byDigest, i.base.LayerByDigest)
+}
+
+// LayerByDiffID implements v1.Image.
+func (i *cachedImage) LayerByDiffID(h v1.Hash) (v1.Layer, error) {
+	return i.layerBy(h, i.byDiffID, i.base.LayerByDiffID)
+}
+
+func (i *cachedImage) layerBy(h v1.Hash, cache map[v1.Hash]v1.Layer, get func(v1.Hash) (v1.Layer, error)) (v1.Layer, error) {
+	i.Lock()
+	defer i.Unlock()
+	if l, ok := cache[h]; ok {
+		return l, nil
+	}
+	l, err := get(h)
+	if err != nil {
+		return nil, err
+	}
+	cl := cacheLayer(l)
+	cache[h] = cl
+	return cl, nil
+}
+
+// cacheLayer wraps l to memoize its metadata.
+func cacheLayer(l v1.Layer) v1.Layer {
+	return &cachedLayer{
+		Layer:     l,
+		digest:    sync.OnceValues(l.Digest),
+		diffID:    sync.OnceValues(l.DiffID),
+		size:      sync.OnceValues(l.Size),
+		mediaType: sync.OnceValues(l.MediaType),
+	}
+}
+
+type cachedLayer struct {
+	v1.Layer
+
+	digest    func() (v1.Hash, error)
+	diffID    func() (v1.Hash, error)
+	size      func() (int64, error)
+	mediaType func() (types.MediaType, error)
+}
+
+// Digest implements v1.Layer.
+func (l *cachedLayer) Digest() (v1.Hash, error) {
+	return l.digest()
+}
+
+// DiffID implements v1.Layer.
+func (l *cachedLayer) DiffID() (v1.Hash, error) {
+	return l.diffID()
+}
+
+// Size implements v1.Layer.
+func (l *cachedLayer) Size() (int64, error) {
+	return l.size()
+}
+
+// MediaType implements v1.Layer.
+func (l *cachedLayer) MediaType() (types.MediaType, error) {
+	return l.mediaType()
+}
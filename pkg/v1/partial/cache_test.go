@@ -0,0 +1,96 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package partial_test
+
+import (
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+// countingImage counts calls to the accessors that would fetch from a registry.
+type countingImage struct {
+	v1.Image
+	manifests, configs int
+}
+
+func (i *countingImage) RawManifest() ([]byte, error) {
+	i.manifests++
+	return i.Image.RawManifest()
+}
+
+func (i *countingImage) Manifest() (*v1.Manifest, error) {
+	i.manifests++
+	return i.Image.Manifest()
+}
+
+func (i *countingImage) RawConfigFile() ([]byte, error) {
+	i.configs++
+	return i.Image.RawConfigFile()
+}
+
+func (i *countingImage) ConfigFile() (*v1.ConfigFile, error) {
+	i.configs++
+	return i.Image.ConfigFile()
+}
+
+func TestWithCache(t *testing.T) {
+	rnd, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counting := &countingImage{Image: rnd}
+	img := partial.WithCache(counting)
+
+	if err := validate.Image(img); err != nil {
+		t.Fatalf("validate.Image() = %v", err)
+	}
+	for range 3 {
+		if _, err := img.Manifest(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := img.RawManifest(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := img.ConfigFile(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := img.RawConfigFile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Once each for the raw and parsed forms.
+	if counting.manifests != 2 {
+		t.Errorf("manifest fetched %d times, want 2", counting.manifests)
+	}
+	if counting.configs != 2 {
+		t.Errorf("config fetched %d times, want 2", counting.configs)
+	}
+
+	// Mutating the result doesn't affect the cache.
+	m, err := img.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Layers = nil
+	if m, err := img.Manifest(); err != nil {
+		t.Fatal(err)
+	} else if len(m.Layers) != 3 {
+		t.Errorf("len(Manifest().Layers) = %d, want 3", len(m.Layers))
+	}
+}
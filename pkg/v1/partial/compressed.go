@@ -17,11 +17,7 @@ package partial
 import (
 	"io"
 
-	"github.com/google/go-containerregistry/internal/and"
 	"github.com/google/go-containerregistry/internal/compression"
-	"github.com/google/go-containerregistry/internal/gzip"
-	"github.com/google/go-containerregistry/internal/zstd"
-	comp "github.com/google/go-containerregistry/pkg/compression"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 )
@@ -54,27 +50,9 @@ func (cle *compressedLayerExtender) Uncompressed() (io.ReadCloser, error) {
 		return nil, err
 	}
 
-	// Often, the "compressed" bytes are not actually-compressed.
-	// Peek at the first two bytes to determine whether it's correct to
-	// wrap this with gzip.UnzipReadCloser or zstd.UnzipReadCloser.
-	cp, pr, err := compression.PeekCompression(rc)
-	if err != nil {
-		return nil, err
-	}
-
-	prc := &and.ReadCloser{
-		Reader:    pr,
-		CloseFunc: rc.Close,
-	}
-
-	switch cp {
-	case comp.GZip:
-		return gzip.UnzipReadCloser(prc)
-	case comp.ZStd:
-		return zstd.UnzipReadCloser(prc)
-	default:
-		return prc, nil
-	}
+	// Often, the "compressed" bytes are not actually-compressed, so
+	// Decompress peeks at the header to decide how to decompress them.
+	return compression.Decompress(rc)
 }
 
 // DiffID implements v1.Layer
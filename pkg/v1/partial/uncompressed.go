@@ -19,7 +19,9 @@ import (
 	"io"
 	"sync"
 
+	comp "github.com/google/go-containerregistry/internal/compression"
 	"github.com/google/go-containerregistry/internal/gzip"
+	"github.com/google/go-containerregistry/pkg/compression"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 )
@@ -54,6 +56,14 @@ func (ule *uncompressedLayerExtender) Compressed() (io.ReadCloser, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Layers are gzipped unless their media type calls for zstd.
+	if mt, err := ule.MediaType(); err == nil && comp.ForMediaType(mt) == compression.ZStd {
+		c, err := compression.Lookup(compression.ZStd)
+		if err != nil {
+			return nil, err
+		}
+		return comp.ReadCloserLevel(u, c, 1), nil
+	}
 	return gzip.ReadCloser(u), nil
 }
 
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/google/go-containerregistry/internal/verify"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 )
@@ -231,6 +232,28 @@ func BlobDescriptor(i WithManifest, h v1.Hash) (*v1.Descriptor, error) {
 	return nil, fmt.Errorf("blob %v not found", h)
 }
 
+// Inline returns desc with data, the content it describes, embedded in its
+// Data field if it's no larger than threshold bytes, so that readers needn't
+// fetch it separately. Otherwise desc is returned unchanged.
+func Inline(desc v1.Descriptor, data []byte, threshold int64) v1.Descriptor {
+	if int64(len(data)) <= threshold {
+		desc.Data = data
+	}
+	return desc
+}
+
+// Data returns the content embedded in the Data field of desc, once it's been
+// verified against the Digest and Size of desc, or nil if there is none.
+func Data(desc v1.Descriptor) ([]byte, error) {
+	if desc.Data == nil {
+		return nil, nil
+	}
+	if err := verify.Descriptor(desc); err != nil {
+		return nil, err
+	}
+	return desc.Data, nil
+}
+
 // WithManifestAndConfigFile defines the subset of v1.Image used by these helper methods
 type WithManifestAndConfigFile interface {
 	WithConfigFile
@@ -337,8 +360,8 @@ func Descriptor(d Describable) (*v1.Descriptor, error) {
 			mf, _ := Manifest(wrm)
 			// Failing to parse as a manifest should just be ignored.
 			// The manifest might not be valid, and that's okay.
-			if mf != nil && !mf.Config.MediaType.IsConfig() {
-				desc.ArtifactType = string(mf.Config.MediaType)
+			if mf != nil {
+				desc.ArtifactType = types.ArtifactTypeOf(mf.ArtifactType, mf.Config.MediaType)
 			}
 		}
 	}
@@ -415,6 +438,12 @@ func unwrap(i any) any {
 	if cie, ok := i.(*compressedImageExtender); ok {
 		return unwrap(cie.CompressedImageCore)
 	}
+	if cl, ok := i.(*cachedLayer); ok {
+		return unwrap(cl.Layer)
+	}
+	if ci, ok := i.(*cachedImage); ok {
+		return unwrap(ci.base)
+	}
 	return i
 }
 
@@ -429,8 +458,8 @@ func ArtifactType(w WithManifest) (string, error) {
 	mf, _ := w.Manifest()
 	// Failing to parse as a manifest should just be ignored.
 	// The manifest might not be valid, and that's okay.
-	if mf != nil && !mf.Config.MediaType.IsConfig() {
-		return string(mf.Config.MediaType), nil
+	if mf != nil {
+		return types.ArtifactTypeOf(mf.ArtifactType, mf.Config.MediaType), nil
 	}
 	return "", nil
 }
@@ -244,3 +244,39 @@ func TestExists(t *testing.T) {
 		t.Errorf("Exists() = %t != %t", got, want)
 	}
 }
+
+func TestInlineData(t *testing.T) {
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := img.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := img.RawConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if desc := partial.Inline(m.Config, cfg, m.Config.Size-1); desc.Data != nil {
+		t.Errorf("Inline() below threshold embedded %d bytes", len(desc.Data))
+	}
+	desc := partial.Inline(m.Config, cfg, m.Config.Size)
+	got, err := partial.Data(desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(cfg, got); diff != "" {
+		t.Errorf("Data() (-want +got) = %s", diff)
+	}
+
+	if got, err := partial.Data(m.Config); err != nil || got != nil {
+		t.Errorf("Data() without data = %v, %v", got, err)
+	}
+	desc.Data = append([]byte{}, cfg...)
+	desc.Data[0] ^= 0xff
+	if _, err := partial.Data(desc); err == nil {
+		t.Error("Data() with corrupt data: expected error")
+	}
+}
@@ -103,6 +103,42 @@ func (p Platform) Satisfies(spec Platform) bool {
 		satisfiesList(spec.Features, p.Features)
 }
 
+// Compatible returns true if an image for this Platform can run on a host with
+// the given Platform.
+//
+// OS and Architecture must be the same, as must Variant and OSVersion if both
+// platforms specify them, except that Windows images are compatible with
+// Windows hosts of the same major.minor.build regardless of the revision, e.g.
+// 10.0.17763.1234 runs on 10.0.17763.5678, per
+// https://learn.microsoft.com/en-us/virtualization/windowscontainers/deploy-containers/version-compatibility
+//
+// Features and OSFeatures are not compared; use Satisfies for those.
+func (p Platform) Compatible(host Platform) bool {
+	if p.OS != host.OS || p.Architecture != host.Architecture {
+		return false
+	}
+	if p.Variant != "" && host.Variant != "" && p.Variant != host.Variant {
+		return false
+	}
+	if p.OSVersion == "" || host.OSVersion == "" || p.OSVersion == host.OSVersion {
+		return true
+	}
+	if p.OS != "windows" {
+		return false
+	}
+	return windowsBuild(p.OSVersion) == windowsBuild(host.OSVersion)
+}
+
+// windowsBuild returns the major.minor.build prefix of a Windows os.version,
+// dropping the revision.
+func windowsBuild(version string) string {
+	parts := strings.SplitN(version, ".", 4)
+	if len(parts) > 3 {
+		parts = parts[:3]
+	}
+	return strings.Join(parts, ".")
+}
+
 func satisfies(want, have string) bool {
 	return want == "" || want == have
 }
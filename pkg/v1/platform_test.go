@@ -233,3 +233,51 @@ func TestPlatformSatisfies(t *testing.T) {
 		}
 	}
 }
+
+func TestPlatformCompatible(t *testing.T) {
+	tests := []struct {
+		image, host v1.Platform
+		compatible  bool
+	}{{
+		v1.Platform{Architecture: "amd64", OS: "linux"},
+		v1.Platform{Architecture: "amd64", OS: "linux"},
+		true,
+	}, {
+		v1.Platform{Architecture: "amd64", OS: "linux"},
+		v1.Platform{Architecture: "arm64", OS: "linux"},
+		false,
+	}, {
+		v1.Platform{Architecture: "arm", OS: "linux", Variant: "v7"},
+		v1.Platform{Architecture: "arm", OS: "linux", Variant: "v6"},
+		false,
+	}, {
+		v1.Platform{Architecture: "arm", OS: "linux"},
+		v1.Platform{Architecture: "arm", OS: "linux", Variant: "v6"},
+		true,
+	}, {
+		v1.Platform{Architecture: "amd64", OS: "linux", OSVersion: "5.0"},
+		v1.Platform{Architecture: "amd64", OS: "linux", OSVersion: "5.0.1"},
+		false,
+	}, {
+		v1.Platform{Architecture: "amd64", OS: "windows", OSVersion: "10.0.17763.1234"},
+		v1.Platform{Architecture: "amd64", OS: "windows", OSVersion: "10.0.17763.5678"},
+		true,
+	}, {
+		v1.Platform{Architecture: "amd64", OS: "windows", OSVersion: "10.0.17763"},
+		v1.Platform{Architecture: "amd64", OS: "windows", OSVersion: "10.0.17763.5678"},
+		true,
+	}, {
+		v1.Platform{Architecture: "amd64", OS: "windows", OSVersion: "10.0.17763.1234"},
+		v1.Platform{Architecture: "amd64", OS: "windows", OSVersion: "10.0.20348.1234"},
+		false,
+	}, {
+		v1.Platform{Architecture: "amd64", OS: "windows", OSVersion: "10.0.17763.1234"},
+		v1.Platform{Architecture: "amd64", OS: "windows"},
+		true,
+	}}
+	for i, tt := range tests {
+		if got := tt.image.Compatible(tt.host); got != tt.compatible {
+			t.Errorf("%d: Compatible() = %v, want %v; (-image +host) %s", i, got, tt.compatible, cmp.Diff(tt.image, tt.host))
+		}
+	}
+}
@@ -17,7 +17,6 @@ package random
 import (
 	"archive/tar"
 	"bytes"
-	"crypto"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -84,7 +83,10 @@ func Layer(byteSize int64, mt types.MediaType, options ...Option) (v1.Layer, err
 
 	// Hash the contents as we write it out to the buffer.
 	var b bytes.Buffer
-	hasher := crypto.SHA256.New()
+	hasher, err := v1.Hasher("sha256")
+	if err != nil {
+		return nil, err
+	}
 	mw := io.MultiWriter(&b, hasher)
 
 	// Write a single file with a random name and random contents.
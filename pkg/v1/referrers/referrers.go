@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package referrers walks the graph of manifests that refer to a subject:
+// its signatures, the attestations that refer to those, the SBOMs that refer
+// to them, and so on, in a registry or an OCI layout.
+package referrers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// DefaultMaxDepth is how deep Walk goes, unless WithMaxDepth says otherwise.
+const DefaultMaxDepth = 8
+
+// ErrSkipReferrers can be returned by a WalkFunc to skip the referrers of
+// the node it was called for, like fs.SkipDir.
+var ErrSkipReferrers = errors.New("skip referrers")
+
+// Lister lists the descriptors of the manifests that refer to subject.
+type Lister interface {
+	Referrers(ctx context.Context, subject v1.Hash) ([]v1.Descriptor, error)
+}
+
+type remoteLister struct {
+	repo    name.Repository
+	options []remote.Option
+}
+
+// Remote returns a Lister for the referrers in repo, using the referrers
+// API or, if the registry doesn't support it, the fallback tag schema.
+func Remote(repo name.Repository, options ...remote.Option) Lister {
+	return &remoteLister{repo: repo, options: options}
+}
+
+func (r *remoteLister) Referrers(ctx context.Context, subject v1.Hash) ([]v1.Descriptor, error) {
+	options := append([]remote.Option{remote.WithContext(ctx)}, r.options...)
+	idx, err := remote.Referrers(r.repo.Digest(subject.String()), options...)
+	if err != nil {
+		return nil, err
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	return im.Manifests, nil
+}
+
+type layoutLister struct {
+	path layout.Path
+}
+
+// Layout returns a Lister for the referrers in the OCI layout at p.
+func Layout(p layout.Path) Lister {
+	return layoutLister{path: p}
+}
+
+func (l layoutLister) Referrers(_ context.Context, subject v1.Hash) ([]v1.Descriptor, error) {
+	return l.path.Referrers(subject)
+}
+
+// Node is a manifest that Walk found.
+type Node struct {
+	v1.Descriptor
+
+	// Subject is the manifest this one refers to.
+	Subject v1.Hash
+	// Depth is how far this is from the subject Walk started from: 1 for
+	// its referrers, 2 for theirs, and so on.
+	Depth int
+}
+
+// WalkFunc is called by Walk for each node. If it returns
+// ErrSkipReferrers, the node's referrers aren't walked; any other error
+// stops the walk.
+type WalkFunc func(n Node) error
+
+// Option is a functional option for Walk.
+type Option func(*options)
+
+type options struct {
+	maxDepth int
+}
+
+// WithMaxDepth sets how deep Walk goes. Referrers deeper than that aren't
+// walked, without error.
+func WithMaxDepth(depth int) Option {
+	return func(o *options) {
+		o.maxDepth = depth
+	}
+}
+
+// Walk calls fn for each manifest that refers to subject, directly or
+// through other referrers, depth first, in the order l lists them. Each
+// manifest is only visited once, even if it's reachable more than one way,
+// so cycles that a misbehaving registry reports don't loop forever.
+func Walk(ctx context.Context, l Lister, subject v1.Hash, fn WalkFunc, opts ...Option) error {
+	o := options{maxDepth: DefaultMaxDepth}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	w := &walker{ctx: ctx, l: l, fn: fn, maxDepth: o.maxDepth, seen: map[v1.Hash]bool{subject: true}}
+	return w.walk(subject, 1)
+}
+
+type walker struct {
+	ctx      context.Context
+	l        Lister
+	fn       WalkFunc
+	maxDepth int
+	seen     map[v1.Hash]bool
+}
+
+func (w *walker) walk(subject v1.Hash, depth int) error {
+	if depth > w.maxDepth {
+		return nil
+	}
+	descs, err := w.l.Referrers(w.ctx, subject)
+	if err != nil {
+		return err
+	}
+	for _, desc := range descs {
+		if w.seen[desc.Digest] {
+			continue
+		}
+		w.seen[desc.Digest] = true
+		if err := w.fn(Node{Descriptor: desc, Subject: subject, Depth: depth}); errors.Is(err, ErrSkipReferrers) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := w.walk(desc.Digest, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package referrers
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// chain returns an image, and a signature of it, an attestation of that and
+// an SBOM of that, in that order.
+func chain(t *testing.T) []v1.Image {
+	t.Helper()
+	img, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgs := []v1.Image{img}
+	for _, artifactType := range []types.MediaType{"application/vnd.dev.sigstore.bundle", "application/vnd.in-toto+json", "application/spdx+json"} {
+		desc, err := partial.Descriptor(imgs[len(imgs)-1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		art := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+		art = mutate.ConfigMediaType(art, artifactType)
+		art = mutate.Subject(art, *desc).(v1.Image)
+		imgs = append(imgs, art)
+	}
+	return imgs
+}
+
+func digest(t *testing.T, img v1.Image) v1.Hash {
+	t.Helper()
+	h, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func walk(t *testing.T, l Lister, subject v1.Hash, opts ...Option) []Node {
+	t.Helper()
+	var nodes []Node
+	if err := Walk(context.Background(), l, subject, func(n Node) error {
+		nodes = append(nodes, n)
+		return nil
+	}, opts...); err != nil {
+		t.Fatal(err)
+	}
+	return nodes
+}
+
+func checkChain(t *testing.T, l Lister, imgs []v1.Image) {
+	t.Helper()
+	nodes := walk(t, l, digest(t, imgs[0]))
+	if len(nodes) != 3 {
+		t.Fatalf("Walk: got %d nodes, want 3", len(nodes))
+	}
+	for i, n := range nodes {
+		if want := digest(t, imgs[i+1]); n.Digest != want {
+			t.Errorf("node %d: got %s, want %s", i, n.Digest, want)
+		}
+		if want := digest(t, imgs[i]); n.Subject != want {
+			t.Errorf("node %d: got subject %s, want %s", i, n.Subject, want)
+		}
+		if n.Depth != i+1 {
+			t.Errorf("node %d: got depth %d, want %d", i, n.Depth, i+1)
+		}
+	}
+	if got, want := nodes[2].ArtifactType, "application/spdx+json"; got != want {
+		t.Errorf("ArtifactType: got %q, want %q", got, want)
+	}
+
+	if nodes := walk(t, l, digest(t, imgs[0]), WithMaxDepth(2)); len(nodes) != 2 {
+		t.Errorf("Walk(WithMaxDepth(2)): got %d nodes, want 2", len(nodes))
+	}
+}
+
+func TestLayout(t *testing.T) {
+	imgs := chain(t)
+	p, err := layout.Write(t.TempDir(), empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, img := range imgs {
+		if err := p.AppendImage(img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkChain(t, Layout(p), imgs)
+}
+
+func TestRemote(t *testing.T) {
+	imgs := chain(t)
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(u.Host + "/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, img := range imgs {
+		if err := remote.Write(repo.Digest(digest(t, img).String()), img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkChain(t, Remote(repo), imgs)
+}
+
+// fakeLister lists referrers from a map, which can have cycles.
+type fakeLister map[v1.Hash][]v1.Hash
+
+func (f fakeLister) Referrers(_ context.Context, subject v1.Hash) ([]v1.Descriptor, error) {
+	var descs []v1.Descriptor
+	for _, h := range f[subject] {
+		descs = append(descs, v1.Descriptor{Digest: h})
+	}
+	return descs, nil
+}
+
+func hash(s string) v1.Hash {
+	return v1.Hash{Algorithm: "sha256", Hex: s}
+}
+
+func TestWalk(t *testing.T) {
+	a, b, c, d := hash("a"), hash("b"), hash("c"), hash("d")
+	l := fakeLister{
+		a: {b, c},
+		b: {d, a},
+		c: {b},
+		d: {c},
+	}
+	var got []string
+	for _, n := range walk(t, l, a) {
+		got = append(got, n.Digest.Hex)
+	}
+	if want := []string{"b", "d", "c"}; !slices.Equal(got, want) {
+		t.Errorf("Walk: got %v, want %v", got, want)
+	}
+
+	got = nil
+	if err := Walk(context.Background(), l, a, func(n Node) error {
+		got = append(got, n.Digest.Hex)
+		if n.Digest == b {
+			return ErrSkipReferrers
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("Walk(ErrSkipReferrers): got %v, want %v", got, want)
+	}
+
+	errStop := errors.New("stop")
+	if err := Walk(context.Background(), l, a, func(Node) error { return errStop }); !errors.Is(err, errStop) {
+		t.Errorf("Walk: got %v, want %v", err, errStop)
+	}
+}
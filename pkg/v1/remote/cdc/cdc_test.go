@@ -0,0 +1,191 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func randomBytes(t *testing.T, seed int64, n int) []byte {
+	t.Helper()
+	b := make([]byte, n)
+	if _, err := rand.New(rand.NewSource(seed)).Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func split(t *testing.T, b []byte, avg int) [][]byte {
+	t.Helper()
+	var chunks [][]byte
+	c := newChunker(bytes.NewReader(b), avg)
+	for {
+		chunk, err := c.next()
+		if errors.Is(err, io.EOF) {
+			return chunks
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, bytes.Clone(chunk))
+	}
+}
+
+func TestChunker(t *testing.T) {
+	const avg = 4 << 10
+	b := randomBytes(t, 1, 1<<20)
+	chunks := split(t, b, avg)
+	if got := bytes.Join(chunks, nil); !bytes.Equal(got, b) {
+		t.Fatal("chunks don't add up to the input")
+	}
+	for i, c := range chunks {
+		if len(c) > 4*avg || (len(c) < avg/4 && i != len(chunks)-1) {
+			t.Errorf("chunk %d has %d bytes", i, len(c))
+		}
+	}
+	if n, want := len(chunks), len(b)/avg; n < want/2 || n > want*2 {
+		t.Errorf("got %d chunks, want about %d", n, want)
+	}
+
+	// Inserting bytes only changes the chunks around them.
+	edited := bytes.Join([][]byte{b[:len(b)/2], []byte("hello"), b[len(b)/2:]}, nil)
+	have := map[string]bool{}
+	for _, c := range chunks {
+		have[string(c)] = true
+	}
+	changed := 0
+	for _, c := range split(t, edited, avg) {
+		if !have[string(c)] {
+			changed++
+		}
+	}
+	if changed > 3 {
+		t.Errorf("%d chunks changed, want at most 3", changed)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	reg := httptest.NewServer(registry.New())
+	defer reg.Close()
+	u, err := url.Parse(reg.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bc := cache.NewFilesystemCache(t.TempDir()).(cache.BlobCache)
+	recv := httptest.NewServer(Handler(NewReceiver(bc, Registry())))
+	defer recv.Close()
+	r := NewClient(recv.URL, nil)
+
+	base := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	data := randomBytes(t, 2, 2<<20)
+	for i, edit := range [][]byte{data, bytes.Join([][]byte{data[:1<<20], []byte("rebuilt"), data[1<<20:]}, nil)} {
+		l := static.NewLayer(edit, types.OCIUncompressedLayer)
+		img, err := mutate.AppendLayers(base, l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ref, err := name.ParseReference(u.Host + "/test:latest")
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats, err := Write(context.Background(), ref, img, r)
+		if err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		if stats.Bytes != int64(len(edit)) {
+			t.Errorf("Bytes: got %d, want %d", stats.Bytes, len(edit))
+		}
+		if i == 0 && stats.SentBytes != stats.Bytes {
+			t.Errorf("first Write sent %d of %d bytes, want all of them", stats.SentBytes, stats.Bytes)
+		}
+		if i == 1 && stats.SentBytes > stats.Bytes/10 {
+			t.Errorf("second Write sent %d of %d bytes, want only the changed chunks", stats.SentBytes, stats.Bytes)
+		}
+
+		got, err := remote.Image(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ls, err := got.Layers()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rc, err := ls[0].Compressed()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, edit) {
+			t.Errorf("pushed layer %d doesn't match", i)
+		}
+	}
+}
+
+func TestPutVerifies(t *testing.T) {
+	r := NewReceiver(cache.NewFilesystemCache(t.TempDir()).(cache.BlobCache), nil)
+	h, _, err := v1.SHA256(bytes.NewReader([]byte("chunk")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Put(context.Background(), h, []byte("not the chunk")); err == nil {
+		t.Error("Put: expected an error for a chunk with the wrong digest")
+	}
+}
+
+func TestAssembleMissingChunk(t *testing.T) {
+	reg := httptest.NewServer(registry.New())
+	defer reg.Close()
+	u, err := url.Parse(reg.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReceiver(cache.NewFilesystemCache(t.TempDir()).(cache.BlobCache), Registry())
+	b := []byte("chunk")
+	h, _, err := v1.SHA256(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Assemble(context.Background(), Recipe{
+		Repository: u.Host + "/test",
+		MediaType:  types.OCIUncompressedLayer,
+		Digest:     h,
+		Size:       int64(len(b)),
+		Chunks:     []Chunk{{Digest: h, Size: int64(len(b))}},
+	})
+	if err == nil {
+		t.Error("Assemble: expected an error for a missing chunk")
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cdc is an EXPERIMENTAL transfer mode that copies layers between
+// registries by sending only the parts of them that the far side doesn't
+// already have.
+//
+// Layer blobs are split into chunks with content-defined chunking, so that
+// the chunk boundaries of a layer that was rebuilt with small changes mostly
+// line up with those of the layer it replaces. The sender asks a Receiver
+// near the destination which chunks it's missing, sends only those, and the
+// Receiver reassembles the blob from its cache, verifies its digest and
+// pushes it. Handler and NewClient carry this over HTTP.
+//
+// Chunks are taken from the blobs as they're stored, i.e. compressed, so this
+// only saves much when the compressed layers share runs of bytes, e.g. with
+// zstd:chunked or eStargz layers, or uncompressed ones.
+package cdc
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math/bits"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Chunk is a piece of a blob.
+type Chunk struct {
+	Digest v1.Hash `json:"digest"`
+	Size   int64   `json:"size"`
+}
+
+const (
+	// DefaultChunkSize is the average chunk size.
+	DefaultChunkSize = 64 << 10
+
+	// MaxChunkSize bounds the average chunk size that WithChunkSize allows.
+	// Handler accepts chunks of up to four times this.
+	MaxChunkSize = 4 << 20
+)
+
+// gear is the table of random values that chunk boundaries are found with.
+// It must never change, or chunks won't match those already sent.
+var gear [256]uint64
+
+func init() {
+	// splitmix64, from a fixed seed.
+	x := uint64(0x6a09e667f3bcc909)
+	for i := range gear {
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		gear[i] = z ^ (z >> 31)
+	}
+}
+
+// chunker splits a stream with FastCDC: a gear hash over a sliding window
+// picks boundaries, with a stricter mask before the average size and a looser
+// one after it, so that chunk sizes cluster around the average.
+type chunker struct {
+	r                     *bufio.Reader
+	min, avg, max         int
+	maskStrict, maskLoose uint64
+}
+
+func newChunker(r io.Reader, avg int) *chunker {
+	n := bits.Len(uint(avg)) - 1
+	return &chunker{
+		r:   bufio.NewReaderSize(r, avg*4),
+		min: avg / 4,
+		avg: avg,
+		max: avg * 4,
+		// The high bits of the hash depend on the most bytes.
+		maskStrict: ^uint64(0) << (64 - (n + 1)),
+		maskLoose:  ^uint64(0) << (64 - (n - 1)),
+	}
+}
+
+// next returns the next chunk, which is only valid until the next call, or
+// io.EOF after the last one.
+func (c *chunker) next() ([]byte, error) {
+	p, err := c.r.Peek(c.max)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(p) == 0 {
+		return nil, io.EOF
+	}
+	p = p[:c.cut(p)]
+	if _, err := c.r.Discard(len(p)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// cut returns the length of the chunk at the start of p.
+func (c *chunker) cut(p []byte) int {
+	if len(p) <= c.min {
+		return len(p)
+	}
+	var h uint64
+	i := c.min
+	for end := min(c.avg, len(p)); i < end; i++ {
+		h = h<<1 + gear[p[i]]
+		if h&c.maskStrict == 0 {
+			return i + 1
+		}
+	}
+	for ; i < len(p); i++ {
+		h = h<<1 + gear[p[i]]
+		if h&c.maskLoose == 0 {
+			return i + 1
+		}
+	}
+	return len(p)
+}
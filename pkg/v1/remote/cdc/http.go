@@ -0,0 +1,167 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// maxRecipeSize bounds the size of the requests that Handler decodes as
+// JSON, which is plenty for the recipe of a blob of hundreds of gigabytes.
+const maxRecipeSize = 64 << 20
+
+type digests struct {
+	Digests []v1.Hash `json:"digests"`
+}
+
+// Handler serves r over HTTP, for clients returned by NewClient:
+//
+//	POST /missing           {"digests": [...]} -> {"digests": [...]}
+//	PUT  /chunks/<digest>   the chunk
+//	POST /assemble          a Recipe
+//
+// Use http.StripPrefix to serve it under a prefix. It has no
+// authentication of its own, so it should only be reachable by senders that
+// are trusted to push to the registries it pushes to.
+func Handler(r Receiver) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /missing", func(w http.ResponseWriter, req *http.Request) {
+		var in digests
+		if err := decode(w, req, &in); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		missing, err := r.Missing(req.Context(), in.Digests)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(digests{Digests: missing})
+	})
+	mux.HandleFunc("PUT /chunks/{digest}", func(w http.ResponseWriter, req *http.Request) {
+		h, err := v1.NewHash(req.PathValue("digest"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		b, err := io.ReadAll(http.MaxBytesReader(w, req.Body, 4*MaxChunkSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := r.Put(req.Context(), h, b); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("POST /assemble", func(w http.ResponseWriter, req *http.Request) {
+		var rc Recipe
+		if err := decode(w, req, &rc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := r.Assemble(req.Context(), rc); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	return mux
+}
+
+func decode(w http.ResponseWriter, req *http.Request, v any) error {
+	return json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRecipeSize)).Decode(v)
+}
+
+// NewClient returns a Receiver that talks to a Handler served at url, e.g.
+// "https://receiver.example.com/cdc". If t is nil, http.DefaultTransport is
+// used.
+func NewClient(url string, t http.RoundTripper) Receiver {
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	return &client{url: strings.TrimSuffix(url, "/"), client: &http.Client{Transport: t}}
+}
+
+type client struct {
+	url    string
+	client *http.Client
+}
+
+func (c *client) Missing(ctx context.Context, hs []v1.Hash) ([]v1.Hash, error) {
+	b, err := json.Marshal(digests{Digests: hs})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, http.MethodPost, "/missing", b, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var out digests
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Digests, nil
+}
+
+func (c *client) Put(ctx context.Context, h v1.Hash, b []byte) error {
+	resp, err := c.do(ctx, http.MethodPut, "/chunks/"+h.String(), b, http.StatusCreated)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *client) Assemble(ctx context.Context, rc Recipe) error {
+	b, err := json.Marshal(rc)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, http.MethodPost, "/assemble", b, http.StatusCreated)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// do sends a request, returning an error unless the response has the
+// status want.
+func (c *client) do(ctx context.Context, method, path string, body []byte, want int) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != want {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	return resp, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/google/go-containerregistry/internal/verify"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Recipe describes how to reassemble a blob from chunks.
+type Recipe struct {
+	// Repository is where the blob is pushed.
+	Repository string          `json:"repository"`
+	MediaType  types.MediaType `json:"mediaType"`
+	Digest     v1.Hash         `json:"digest"`
+	Size       int64           `json:"size"`
+	Chunks     []Chunk         `json:"chunks"`
+}
+
+// Receiver is the far side of a transfer, which stores chunks and
+// reassembles blobs from them.
+type Receiver interface {
+	// Missing returns the digests in hs of the chunks the Receiver
+	// doesn't have.
+	Missing(ctx context.Context, hs []v1.Hash) ([]v1.Hash, error)
+
+	// Put stores the chunk b, which must have the digest h.
+	Put(ctx context.Context, h v1.Hash, b []byte) error
+
+	// Assemble reassembles the blob r describes from chunks the Receiver
+	// has, verifies its digest and stores it.
+	Assemble(ctx context.Context, r Recipe) error
+}
+
+// Sink stores the blobs that a Receiver reassembles.
+type Sink func(ctx context.Context, repo name.Repository, l v1.Layer) error
+
+// Registry is a Sink that pushes blobs with remote.WriteLayer.
+func Registry(options ...remote.Option) Sink {
+	return func(ctx context.Context, repo name.Repository, l v1.Layer) error {
+		return remote.WriteLayer(repo, l, append(slices.Clip(options), remote.WithContext(ctx))...)
+	}
+}
+
+// NewReceiver returns a Receiver that keeps chunks in bc, e.g. a filesystem
+// cache, and stores reassembled blobs in sink.
+func NewReceiver(bc cache.BlobCache, sink Sink) Receiver {
+	return &receiver{bc: bc, sink: sink}
+}
+
+type receiver struct {
+	bc   cache.BlobCache
+	sink Sink
+}
+
+func (r *receiver) Missing(_ context.Context, hs []v1.Hash) ([]v1.Hash, error) {
+	var missing []v1.Hash
+	for _, h := range hs {
+		if _, err := r.chunk(h); errors.Is(err, cache.ErrNotFound) {
+			missing = append(missing, h)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return missing, nil
+}
+
+// chunk returns the chunk h, or cache.ErrNotFound if it isn't cached intact.
+func (r *receiver) chunk(h v1.Hash) ([]byte, error) {
+	b, err := r.bc.GetBlob(h)
+	if err != nil {
+		return nil, err
+	}
+	got, _, err := v1.SHA256(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	if got != h {
+		return nil, cache.ErrNotFound
+	}
+	return b, nil
+}
+
+func (r *receiver) Put(_ context.Context, h v1.Hash, b []byte) error {
+	got, _, err := v1.SHA256(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	if got != h {
+		return fmt.Errorf("chunk has digest %s, want %s", got, h)
+	}
+	return r.bc.PutBlob(h, b)
+}
+
+func (r *receiver) Assemble(ctx context.Context, rc Recipe) error {
+	repo, err := name.NewRepository(rc.Repository)
+	if err != nil {
+		return err
+	}
+	l, err := partial.CompressedToLayer(&assembled{r: r, rc: rc})
+	if err != nil {
+		return err
+	}
+	return r.sink(ctx, repo, l)
+}
+
+// assembled is a blob read from its chunks, and verified as it's read.
+type assembled struct {
+	r  *receiver
+	rc Recipe
+}
+
+func (a *assembled) Digest() (v1.Hash, error)            { return a.rc.Digest, nil }
+func (a *assembled) Size() (int64, error)                { return a.rc.Size, nil }
+func (a *assembled) MediaType() (types.MediaType, error) { return a.rc.MediaType, nil }
+
+func (a *assembled) Compressed() (io.ReadCloser, error) {
+	return verify.ReadCloser(io.NopCloser(&chunksReader{r: a.r, chunks: a.rc.Chunks}), a.rc.Size, a.rc.Digest)
+}
+
+// chunksReader reads chunks one after another.
+type chunksReader struct {
+	r      *receiver
+	chunks []Chunk
+	cur    []byte
+}
+
+func (cr *chunksReader) Read(p []byte) (int, error) {
+	for len(cr.cur) == 0 {
+		if len(cr.chunks) == 0 {
+			return 0, io.EOF
+		}
+		b, err := cr.r.chunk(cr.chunks[0].Digest)
+		if err != nil {
+			return 0, fmt.Errorf("chunk %s: %w", cr.chunks[0].Digest, err)
+		}
+		cr.cur, cr.chunks = b, cr.chunks[1:]
+	}
+	n := copy(p, cr.cur)
+	cr.cur = cr.cur[n:]
+	return n, nil
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// batchSize is how many chunks the sender asks about at once.
+const batchSize = 64
+
+// Option is a functional option for Send and Write.
+type Option func(*options)
+
+type options struct {
+	chunkSize int
+	remote    []remote.Option
+}
+
+func makeOptions(opts ...Option) (*options, error) {
+	o := &options{chunkSize: DefaultChunkSize}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.chunkSize < 1<<10 || o.chunkSize > MaxChunkSize || o.chunkSize&(o.chunkSize-1) != 0 {
+		return nil, fmt.Errorf("chunk size %d must be a power of two between 1KiB and %d", o.chunkSize, MaxChunkSize)
+	}
+	return o, nil
+}
+
+// WithChunkSize sets the average chunk size, which must be a power of two.
+// Chunks are between a quarter and four times the average. Smaller chunks
+// find more in common between layers, but cost more round trips.
+//
+// The default is DefaultChunkSize. Chunks only match chunks of the same
+// size, so the size should be the same for every transfer to a Receiver.
+func WithChunkSize(size int) Option {
+	return func(o *options) {
+		o.chunkSize = size
+	}
+}
+
+// WithRemoteOptions is a functional option for passing remote.Options, such
+// as credentials or a transport, to the registry operations of Write.
+func WithRemoteOptions(opts ...remote.Option) Option {
+	return func(o *options) {
+		o.remote = append(o.remote, opts...)
+	}
+}
+
+// Stats reports how much of a transfer was sent.
+type Stats struct {
+	// Chunks and Bytes are the number and total size of the chunks of the
+	// blobs that were transferred.
+	Chunks int
+	Bytes  int64
+	// SentChunks and SentBytes are those the Receiver didn't have.
+	SentChunks int
+	SentBytes  int64
+}
+
+// Add adds the counts in other to s.
+func (s *Stats) Add(other Stats) {
+	s.Chunks += other.Chunks
+	s.Bytes += other.Bytes
+	s.SentChunks += other.SentChunks
+	s.SentBytes += other.SentBytes
+}
+
+// Send transfers the blob of l to repo through r, sending only the chunks
+// that r doesn't have.
+func Send(ctx context.Context, r Receiver, repo name.Repository, l v1.Layer, opts ...Option) (Stats, error) {
+	o, err := makeOptions(opts...)
+	if err != nil {
+		return Stats{}, err
+	}
+	return o.send(ctx, r, repo, l)
+}
+
+func (o *options) send(ctx context.Context, r Receiver, repo name.Repository, l v1.Layer) (Stats, error) {
+	var stats Stats
+	rc := Recipe{Repository: repo.Name()}
+	var err error
+	if rc.Digest, err = l.Digest(); err != nil {
+		return stats, err
+	}
+	if rc.MediaType, err = l.MediaType(); err != nil {
+		return stats, err
+	}
+	blob, err := l.Compressed()
+	if err != nil {
+		return stats, err
+	}
+	defer blob.Close()
+
+	// sent is what r has been asked about for this blob, so that repeated
+	// chunks are only sent once. pending holds the chunks in hs, the batch
+	// that r hasn't been asked about yet.
+	sent := map[v1.Hash]bool{}
+	pending := map[v1.Hash][]byte{}
+	var hs []v1.Hash
+	send := func() error {
+		if len(hs) == 0 {
+			return nil
+		}
+		missing, err := r.Missing(ctx, hs)
+		if err != nil {
+			return err
+		}
+		for _, h := range missing {
+			b, ok := pending[h]
+			if !ok {
+				return fmt.Errorf("receiver is missing %s, which it wasn't asked about", h)
+			}
+			if err := r.Put(ctx, h, b); err != nil {
+				return fmt.Errorf("sending chunk %s: %w", h, err)
+			}
+			stats.SentChunks++
+			stats.SentBytes += int64(len(b))
+		}
+		for _, h := range hs {
+			sent[h] = true
+		}
+		clear(pending)
+		hs = hs[:0]
+		return nil
+	}
+	c := newChunker(blob, o.chunkSize)
+	for {
+		b, err := c.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return stats, err
+		}
+		h, _, err := v1.SHA256(bytes.NewReader(b))
+		if err != nil {
+			return stats, err
+		}
+		rc.Chunks = append(rc.Chunks, Chunk{Digest: h, Size: int64(len(b))})
+		rc.Size += int64(len(b))
+		stats.Chunks++
+		stats.Bytes += int64(len(b))
+		if _, ok := pending[h]; ok || sent[h] {
+			continue
+		}
+		pending[h] = slices.Clone(b)
+		hs = append(hs, h)
+		if len(hs) == batchSize {
+			if err := send(); err != nil {
+				return stats, err
+			}
+		}
+	}
+	if err := send(); err != nil {
+		return stats, err
+	}
+	if err := blob.Close(); err != nil {
+		return stats, err
+	}
+	if err := r.Assemble(ctx, rc); err != nil {
+		return stats, fmt.Errorf("assembling %s: %w", rc.Digest, err)
+	}
+	return stats, nil
+}
+
+// Write pushes img to ref, transferring its layers through r, which must
+// push them to ref's repository, and then pushing its config and manifest
+// directly. Layers that aren't distributable aren't transferred.
+func Write(ctx context.Context, ref name.Reference, img v1.Image, r Receiver, opts ...Option) (Stats, error) {
+	var stats Stats
+	o, err := makeOptions(opts...)
+	if err != nil {
+		return stats, err
+	}
+	ls, err := img.Layers()
+	if err != nil {
+		return stats, err
+	}
+	for _, l := range ls {
+		mt, err := l.MediaType()
+		if err != nil {
+			return stats, err
+		}
+		if !mt.IsDistributable() {
+			continue
+		}
+		s, err := o.send(ctx, r, ref.Context(), l)
+		stats.Add(s)
+		if err != nil {
+			return stats, err
+		}
+	}
+	// The layers are already there, so this only pushes the rest.
+	return stats, remote.Write(ref, img, append(slices.Clip(o.remote), remote.WithContext(ctx))...)
+}
@@ -26,3 +26,13 @@ func Delete(ref name.Reference, options ...Option) error {
 	}
 	return newPusher(o).Delete(o.context, ref)
 }
+
+// DeleteBlob removes the blob ref from the remote registry. Many registries
+// don't support this.
+func DeleteBlob(ref name.Digest, options ...Option) error {
+	o, err := makeOptions(options...)
+	if err != nil {
+		return err
+	}
+	return newPusher(o).DeleteBlob(o.context, ref)
+}
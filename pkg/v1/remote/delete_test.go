@@ -87,3 +87,36 @@ func TestDeleteBadStatus(t *testing.T) {
 		t.Error("Delete() = nil; wanted error")
 	}
 }
+
+func TestDeleteBlob(t *testing.T) {
+	expectedRepo := "write/time"
+	digest := "sha256:deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"
+	blobPath := fmt.Sprintf("/v2/%s/blobs/%s", expectedRepo, digest)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case blobPath:
+			if r.Method != http.MethodDelete {
+				t.Errorf("Method; got %v, want %v", r.Method, http.MethodDelete)
+			}
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			t.Fatalf("Unexpected path: %v", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse(%v) = %v", server.URL, err)
+	}
+	ref, err := name.NewDigest(fmt.Sprintf("%s/%s@%s", u.Host, expectedRepo, digest))
+	if err != nil {
+		t.Fatalf("NewDigest() = %v", err)
+	}
+
+	if err := DeleteBlob(ref); err != nil {
+		t.Errorf("DeleteBlob() = %v", err)
+	}
+}
@@ -26,7 +26,12 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 )
 
@@ -130,6 +135,90 @@ func TestGetImageAsIndex(t *testing.T) {
 	}
 }
 
+func TestGetPlatformReference(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var adds []mutate.IndexAddendum
+	want := map[string]v1.Hash{}
+	for _, arch := range []string{"amd64", "arm64"} {
+		img, err := random.Image(1024, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := img.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[arch] = d
+		adds = append(adds, mutate.IndexAddendum{
+			Add: img,
+			Descriptor: v1.Descriptor{
+				Platform: &v1.Platform{OS: "linux", Architecture: arch},
+			},
+		})
+	}
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex), adds...)
+
+	tag := mustNewTag(t, u.Host+"/repo:latest")
+	if err := WriteIndex(tag, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := name.ParseWithPlatform(tag.String() + "@linux/arm64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := Get(ref)
+	if err != nil {
+		t.Fatalf("Get(%s) = %v", ref, err)
+	}
+	if desc.Digest != want["arm64"] {
+		t.Errorf("Get(%s).Digest = %s, want %s", ref, desc.Digest, want["arm64"])
+	}
+	head, err := Head(ref)
+	if err != nil {
+		t.Fatalf("Head(%s) = %v", ref, err)
+	}
+	if head.Digest != want["arm64"] {
+		t.Errorf("Head(%s).Digest = %s, want %s", ref, head.Digest, want["arm64"])
+	}
+
+	// The reference's platform wins over WithPlatform.
+	img, err := Image(ref, WithPlatform(v1.Platform{OS: "linux", Architecture: "amd64"}))
+	if err != nil {
+		t.Fatalf("Image(%s) = %v", ref, err)
+	}
+	if d, err := img.Digest(); err != nil {
+		t.Fatal(err)
+	} else if d != want["arm64"] {
+		t.Errorf("Image(%s).Digest() = %s, want %s", ref, d, want["arm64"])
+	}
+
+	// arm64/v8 isn't in the index, but arm64 runs on it.
+	fuzzy, err := name.ParseWithPlatform(tag.String() + "@linux/arm64/v8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc, err := Get(fuzzy); err != nil {
+		t.Fatalf("Get(%s) = %v", fuzzy, err)
+	} else if desc.Digest != want["arm64"] {
+		t.Errorf("Get(%s).Digest = %s, want %s", fuzzy, desc.Digest, want["arm64"])
+	}
+
+	missing, err := name.ParseWithPlatform(tag.String() + "@linux/s390x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Get(missing); err == nil {
+		t.Errorf("Get(%s): expected error", missing)
+	}
+}
+
 func TestHeadSchema1(t *testing.T) {
 	expectedRepo := "foo/bar"
 	mediaType := types.DockerManifestSchema1Signed
@@ -255,3 +344,59 @@ func (errTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	}
 	return nil, fmt.Errorf("error reaching %s", req.URL.String())
 }
+
+func TestWithVerify(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag(u.Host + "/test:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(tag, img); err != nil {
+		t.Fatal(err)
+	}
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := tag.Context().Digest(d.String())
+
+	// Only allow pulling by digest.
+	errTag := errors.New("pull by digest")
+	var seen []string
+	verify := WithVerify(func(_ context.Context, ref name.Reference, desc v1.Descriptor) error {
+		seen = append(seen, ref.Identifier())
+		if desc.Digest != d {
+			t.Errorf("verify: got digest %s, want %s", desc.Digest, d)
+		}
+		if _, ok := ref.(name.Digest); !ok {
+			return errTag
+		}
+		return nil
+	})
+
+	if _, err := Image(tag, verify); !errors.Is(err, errTag) {
+		t.Errorf("Image(tag): got %v, want %v", err, errTag)
+	}
+	if _, err := Head(tag, verify); !errors.Is(err, errTag) {
+		t.Errorf("Head(tag): got %v, want %v", err, errTag)
+	}
+	if _, err := Image(digest, verify); err != nil {
+		t.Errorf("Image(digest): %v", err)
+	}
+	if _, err := Head(digest, verify); err != nil {
+		t.Errorf("Head(digest): %v", err)
+	}
+	if diff := cmp.Diff([]string{"latest", "latest", d.String(), d.String()}, seen); diff != "" {
+		t.Errorf("verified (-want +got):\n%s", diff)
+	}
+}
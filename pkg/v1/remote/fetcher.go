@@ -42,6 +42,12 @@ const (
 type fetcher struct {
 	target resource
 	client *http.Client
+
+	// resumeDir is where partial blobs are kept; see WithResumeDir.
+	resumeDir string
+
+	// skipBlobVerification is set by WithInsecureSkipBlobVerification.
+	skipBlobVerification bool
 }
 
 func makeFetcher(ctx context.Context, target resource, o *options) (*fetcher, error) {
@@ -63,13 +69,16 @@ func makeFetcher(ctx context.Context, target resource, o *options) (*fetcher, er
 		reg = repo.Registry
 	}
 
-	tr, err := transport.NewWithContext(ctx, reg, auth, o.transport, []string{target.Scope(transport.PullScope)})
+	tr, err := transport.NewWithContext(ctx, reg, auth, o.transport, []string{target.Scope(transport.PullScope)}, transport.WithTokenCache(o.tokenCache))
 	if err != nil {
 		return nil, err
 	}
 	return &fetcher{
-		target: target,
-		client: &http.Client{Transport: tr},
+		target:    target,
+		client:    &http.Client{Transport: tr},
+		resumeDir: o.resumeDir,
+
+		skipBlobVerification: o.skipBlobVerification,
 	}, nil
 }
 
@@ -165,8 +174,8 @@ func (f *fetcher) fetchManifest(ctx context.Context, ref name.Reference, accepta
 	mf, _ := v1.ParseManifest(bytes.NewReader(manifest))
 	// Failing to parse as a manifest should just be ignored.
 	// The manifest might not be valid, and that's okay.
-	if mf != nil && !mf.Config.MediaType.IsConfig() {
-		artifactType = string(mf.Config.MediaType)
+	if mf != nil {
+		artifactType = types.ArtifactTypeOf(mf.ArtifactType, mf.Config.MediaType)
 	}
 
 	// Do nothing for tags; I give up.
@@ -246,6 +255,9 @@ func (f *fetcher) headManifest(ctx context.Context, ref name.Reference, acceptab
 }
 
 func (f *fetcher) fetchBlob(ctx context.Context, size int64, h v1.Hash) (io.ReadCloser, error) {
+	if f.resumeDir != "" {
+		return f.fetchResumableBlob(ctx, size, h)
+	}
 	u := f.url("blobs", h.String())
 	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
 	if err != nil {
@@ -273,9 +285,35 @@ func (f *fetcher) fetchBlob(ctx context.Context, size int64, h v1.Hash) (io.Read
 		}
 	}
 
+	if f.skipBlobVerification {
+		return unverified(resp.Body, size), nil
+	}
 	return verify.ReadCloser(resp.Body, size, h)
 }
 
+// unverified returns rc as it is, for WithInsecureSkipBlobVerification, but
+// still checks that it has size bytes, if that's known.
+func unverified(rc io.ReadCloser, size int64) io.ReadCloser {
+	if size == verify.SizeUnknown {
+		return rc
+	}
+	return &sizedReader{ReadCloser: rc, want: size}
+}
+
+type sizedReader struct {
+	io.ReadCloser
+	got, want int64
+}
+
+func (r *sizedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.got += int64(n)
+	if r.got > r.want || (err == io.EOF && r.got != r.want) {
+		return n, fmt.Errorf("error verifying size; got %d, want %d", r.got, r.want)
+	}
+	return n, err
+}
+
 func (f *fetcher) headBlob(ctx context.Context, h v1.Hash) (*http.Response, error) {
 	u := f.url("blobs", h.String())
 	req, err := http.NewRequest(http.MethodHead, u.String(), nil)
@@ -0,0 +1,284 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gc garbage collects a repository over the registry API, for
+// registries that don't do it themselves: manifests that aren't reachable
+// from a set of roots are deleted, along with the blobs only they refer to.
+//
+// Registries can't list untagged manifests or blobs, so only what's
+// reachable from the repository's tags is ever collected.
+package gc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// Plan is what Sweep deletes from a repository. Mark returns it without
+// deleting anything, so it doubles as a dry run.
+type Plan struct {
+	Repository name.Repository
+
+	// Keep is the manifests that are reachable from the roots.
+	Keep []v1.Hash
+	// Tags is the tags of manifests that aren't.
+	Tags []string
+	// Manifests is the manifests that aren't reachable, indexes first.
+	Manifests []v1.Hash
+	// Blobs is the blobs that only unreachable manifests refer to.
+	Blobs []v1.Hash
+}
+
+// manifest is what a manifest refers to.
+type manifest struct {
+	index    bool
+	children []v1.Hash
+	blobs    []v1.Hash
+}
+
+type marker struct {
+	ctx       context.Context
+	repo      name.Repository
+	puller    *remote.Puller
+	manifests map[v1.Hash]*manifest
+}
+
+// Mark returns a plan to delete what isn't reachable in repo from roots,
+// which must be in repo. A manifest is reachable if it's a root, a child of
+// an index that's reachable, or a referrer of a manifest that's reachable.
+func Mark(ctx context.Context, repo name.Repository, roots []name.Reference, options ...remote.Option) (*Plan, error) {
+	puller, err := remote.NewPuller(options...)
+	if err != nil {
+		return nil, err
+	}
+	m := &marker{ctx: ctx, repo: repo, puller: puller, manifests: map[v1.Hash]*manifest{}}
+
+	tags, err := puller.List(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("listing tags: %w", err)
+	}
+	tagged := map[string]v1.Hash{}
+	var all []v1.Hash
+	for _, tag := range tags {
+		h, err := m.fetch(repo.Tag(tag))
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s: %w", tag, err)
+		}
+		tagged[tag] = h
+		all = append(all, h)
+	}
+
+	var reachable []v1.Hash
+	for _, root := range roots {
+		if root.Context() != repo {
+			return nil, fmt.Errorf("root %s isn't in %s", root, repo)
+		}
+		h, err := m.fetch(root)
+		if err != nil {
+			return nil, fmt.Errorf("fetching root %s: %w", root, err)
+		}
+		reachable = append(reachable, h)
+	}
+
+	known, err := m.walk(all, nil)
+	if err != nil {
+		return nil, err
+	}
+	keep, err := m.walk(reachable, func(h v1.Hash) ([]v1.Hash, error) {
+		// Referrers are either served by the referrers API, or listed by
+		// an index tagged with the fallback tag, which must be kept too.
+		idx, err := remote.Referrers(repo.Digest(h.String()), append(slices.Clip(options), remote.WithContext(ctx))...)
+		if err != nil {
+			return nil, fmt.Errorf("listing referrers of %s: %w", h, err)
+		}
+		im, err := idx.IndexManifest()
+		if err != nil {
+			return nil, err
+		}
+		var refs []v1.Hash
+		for _, desc := range im.Manifests {
+			refs = append(refs, desc.Digest)
+		}
+		if f, ok := tagged[strings.Replace(h.String(), ":", "-", 1)]; ok {
+			refs = append(refs, f)
+		}
+		return refs, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plan{Repository: repo, Keep: keep}
+	kept, keptBlobs := map[v1.Hash]bool{}, map[v1.Hash]bool{}
+	for _, h := range keep {
+		kept[h] = true
+		for _, b := range m.manifests[h].blobs {
+			keptBlobs[b] = true
+		}
+	}
+	for _, tag := range tags {
+		if !kept[tagged[tag]] {
+			p.Tags = append(p.Tags, tag)
+		}
+	}
+	seen := map[v1.Hash]bool{}
+	for _, h := range known {
+		if kept[h] {
+			continue
+		}
+		p.Manifests = append(p.Manifests, h)
+		for _, b := range m.manifests[h].blobs {
+			if !keptBlobs[b] && !seen[b] {
+				seen[b] = true
+				p.Blobs = append(p.Blobs, b)
+			}
+		}
+	}
+	// Delete indexes before the manifests they refer to.
+	slices.SortStableFunc(p.Manifests, func(a, b v1.Hash) int {
+		switch ia, ib := m.manifests[a].index, m.manifests[b].index; {
+		case ia && !ib:
+			return -1
+		case !ia && ib:
+			return 1
+		}
+		return 0
+	})
+	return p, nil
+}
+
+// walk returns the manifests reachable from roots, by way of index children
+// and, if it's set, more.
+func (m *marker) walk(roots []v1.Hash, more func(v1.Hash) ([]v1.Hash, error)) ([]v1.Hash, error) {
+	var out []v1.Hash
+	seen := map[v1.Hash]bool{}
+	queue := slices.Clone(roots)
+	for len(queue) > 0 {
+		h := queue[0]
+		queue = queue[1:]
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		if _, err := m.fetch(m.repo.Digest(h.String())); err != nil {
+			return nil, fmt.Errorf("fetching %s: %w", h, err)
+		}
+		out = append(out, h)
+		queue = append(queue, m.manifests[h].children...)
+		if more != nil {
+			hs, err := more(h)
+			if err != nil {
+				return nil, err
+			}
+			queue = append(queue, hs...)
+		}
+	}
+	return out, nil
+}
+
+// fetch fetches ref, if it hasn't already, and returns its digest.
+func (m *marker) fetch(ref name.Reference) (v1.Hash, error) {
+	if d, ok := ref.(name.Digest); ok {
+		h, err := v1.NewHash(d.DigestStr())
+		if err != nil {
+			return v1.Hash{}, err
+		}
+		if _, ok := m.manifests[h]; ok {
+			return h, nil
+		}
+	}
+	desc, err := m.puller.Get(m.ctx, ref)
+	if err != nil {
+		return v1.Hash{}, err
+	}
+	if _, ok := m.manifests[desc.Digest]; ok {
+		return desc.Digest, nil
+	}
+
+	mf := &manifest{}
+	switch {
+	case desc.MediaType.IsIndex():
+		im, err := v1.ParseIndexManifest(bytes.NewReader(desc.Manifest))
+		if err != nil {
+			return v1.Hash{}, err
+		}
+		mf.index = true
+		for _, child := range im.Manifests {
+			mf.children = append(mf.children, child.Digest)
+		}
+	case desc.MediaType.IsSchema1():
+		logs.Warn.Printf("not collecting the blobs of schema 1 manifest %s", desc.Digest)
+	default:
+		im, err := v1.ParseManifest(bytes.NewReader(desc.Manifest))
+		if err != nil {
+			return v1.Hash{}, err
+		}
+		mf.blobs = append(mf.blobs, im.Config.Digest)
+		for _, l := range im.Layers {
+			// Foreign layers aren't in the registry.
+			if l.MediaType.IsDistributable() {
+				mf.blobs = append(mf.blobs, l.Digest)
+			}
+		}
+	}
+	m.manifests[desc.Digest] = mf
+	return desc.Digest, nil
+}
+
+// Sweep deletes what p says to: its tags, then its manifests, then its
+// blobs. Things that are already gone are skipped, as are tags, if the
+// registry can't delete them, since deleting their manifests untags them.
+func Sweep(ctx context.Context, p *Plan, options ...remote.Option) error {
+	pusher, err := remote.NewPusher(options...)
+	if err != nil {
+		return err
+	}
+	for _, tag := range p.Tags {
+		err := pusher.Delete(ctx, p.Repository.Tag(tag))
+		if err != nil && !ignorable(err, http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusBadRequest) {
+			return fmt.Errorf("deleting tag %s: %w", tag, err)
+		}
+		logs.Progress.Printf("Deleted tag %s", tag)
+	}
+	for _, h := range p.Manifests {
+		if err := pusher.Delete(ctx, p.Repository.Digest(h.String())); err != nil && !ignorable(err, http.StatusNotFound) {
+			return fmt.Errorf("deleting manifest %s: %w", h, err)
+		}
+		logs.Progress.Printf("Deleted manifest %s", h)
+	}
+	for _, h := range p.Blobs {
+		if err := pusher.DeleteBlob(ctx, p.Repository.Digest(h.String())); err != nil && !ignorable(err, http.StatusNotFound) {
+			return fmt.Errorf("deleting blob %s: %w", h, err)
+		}
+		logs.Progress.Printf("Deleted blob %s", h)
+	}
+	return nil
+}
+
+// ignorable reports whether err is a registry error with one of codes.
+func ignorable(err error, codes ...int) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && slices.Contains(codes, terr.StatusCode)
+}
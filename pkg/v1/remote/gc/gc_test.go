@@ -0,0 +1,157 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func digest(t *testing.T, d interface{ Digest() (v1.Hash, error) }) v1.Hash {
+	t.Helper()
+	h, err := d.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestMarkAndSweep(t *testing.T) {
+	ctx := context.Background()
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(u.Host + "/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared, err := base.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keep, err := mutate.AppendLayers(base, mustLayer(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unique := mustLayer(t)
+	old, err := mutate.AppendLayers(base, unique)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := random.Index(100, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := partial.Descriptor(keep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := random.Image(10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig = mutate.Subject(sig, *desc).(v1.Image)
+
+	for tag, w := range map[string]remote.Taggable{"keep": keep, "old": old, "idx": idx} {
+		if err := remote.Push(repo.Tag(tag), w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := remote.Write(repo.Digest(digest(t, sig).String()), sig); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Mark(ctx, repo, []name.Reference{repo.Tag("keep")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []v1.Hash{digest(t, keep), digest(t, sig)} {
+		if !slices.Contains(p.Keep, h) {
+			t.Errorf("Keep: missing %s", h)
+		}
+	}
+	if want := []string{"idx", "old"}; !slices.Equal(p.Tags, want) {
+		t.Errorf("Tags: got %v, want %v", p.Tags, want)
+	}
+	if len(p.Manifests) != 4 {
+		t.Errorf("Manifests: got %d, want the index, its 2 children and old", len(p.Manifests))
+	} else if p.Manifests[0] != digest(t, idx) {
+		t.Errorf("Manifests: got %s first, want the index", p.Manifests[0])
+	}
+	if slices.Contains(p.Blobs, digest(t, shared[0])) {
+		t.Errorf("Blobs: shared layer %s would be deleted", digest(t, shared[0]))
+	}
+	if !slices.Contains(p.Blobs, digest(t, unique)) {
+		t.Errorf("Blobs: missing %s", digest(t, unique))
+	}
+
+	if err := Sweep(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	tags, err := remote.List(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(tags, "old") || !slices.Contains(tags, "keep") {
+		t.Errorf("tags after Sweep: %v", tags)
+	}
+	if _, err := remote.Head(repo.Digest(digest(t, old).String())); err == nil {
+		t.Error("old still exists after Sweep")
+	}
+	if _, err := remote.Image(repo.Digest(digest(t, keep).String())); err != nil {
+		t.Errorf("keep is gone after Sweep: %v", err)
+	}
+	if _, err := remote.Layer(repo.Digest(digest(t, shared[0]).String())); err != nil {
+		t.Errorf("shared layer is gone after Sweep: %v", err)
+	}
+
+	// Sweeping again is a no-op.
+	if err := Sweep(ctx, p); err != nil {
+		t.Errorf("second Sweep: %v", err)
+	}
+
+	if _, err := Mark(ctx, repo, []name.Reference{repo.Tag("typo")}); err == nil {
+		t.Error("Mark: expected an error for a missing root")
+	}
+}
+
+func mustLayer(t *testing.T) v1.Layer {
+	t.Helper()
+	l, err := random.Layer(100, types.DockerLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
@@ -20,9 +20,9 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/google/go-containerregistry/internal/verify"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/match"
 	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 )
@@ -161,20 +161,16 @@ func (r *remoteIndex) imageByPlatform(platform v1.Platform) (v1.Image, error) {
 	return desc.Image()
 }
 
-// This naively matches the first manifest with matching platform attributes.
-//
-// We should probably use this instead:
-//
-//	github.com/containerd/containerd/platforms
-//
-// But first we'd need to migrate to:
-//
-//	github.com/opencontainers/image-spec/specs-go/v1
+// This matches the first manifest with matching platform attributes, falling
+// back to the first manifest that a runtime on platform could run, as
+// determined by match.PlatformFuzzy, e.g. arm64/v8 for arm64.
 func (r *remoteIndex) childByPlatform(platform v1.Platform) (*Descriptor, error) {
 	index, err := r.IndexManifest()
 	if err != nil {
 		return nil, err
 	}
+	fuzzy := match.PlatformFuzzy(platform)
+	var fallback *v1.Descriptor
 	for _, childDesc := range index.Manifests {
 		// If platform is missing from child descriptor, assume it's amd64/linux.
 		p := defaultPlatform
@@ -185,6 +181,16 @@ func (r *remoteIndex) childByPlatform(platform v1.Platform) (*Descriptor, error)
 		if matchesPlatform(p, platform) {
 			return r.childDescriptor(childDesc, platform)
 		}
+		if fallback == nil {
+			d := childDesc
+			d.Platform = &p
+			if fuzzy(d) {
+				fallback = &childDesc
+			}
+		}
+	}
+	if fallback != nil {
+		return r.childDescriptor(*fallback, platform)
 	}
 	return nil, fmt.Errorf("no child with platform %+v in index %s", platform, r.ref)
 }
@@ -209,12 +215,9 @@ func (r *remoteIndex) childDescriptor(child v1.Descriptor, platform v1.Platform)
 		manifest []byte
 		err      error
 	)
-	if child.Data != nil {
-		if err := verify.Descriptor(child); err != nil {
-			return nil, err
-		}
-		manifest = child.Data
-	} else {
+	if manifest, err = partial.Data(child); err != nil {
+		return nil, err
+	} else if manifest == nil {
 		manifest, _, err = r.fetcher.fetchManifest(r.ctx, ref, []types.MediaType{child.MediaType})
 		if err != nil {
 			return nil, err
@@ -225,8 +228,8 @@ func (r *remoteIndex) childDescriptor(child v1.Descriptor, platform v1.Platform)
 		mf, _ := v1.ParseManifest(bytes.NewReader(manifest))
 		// Failing to parse as a manifest should just be ignored.
 		// The manifest might not be valid, and that's okay.
-		if mf != nil && !mf.Config.MediaType.IsConfig() {
-			child.ArtifactType = string(mf.Config.MediaType)
+		if mf != nil {
+			child.ArtifactType = types.ArtifactTypeOf(mf.ArtifactType, mf.Config.MediaType)
 		}
 	}
 
@@ -243,7 +246,8 @@ func (r *remoteIndex) childDescriptor(child v1.Descriptor, platform v1.Platform)
 // matchesPlatform checks if the given platform matches the required platforms.
 // The given platform matches the required platform if
 // - architecture and OS are identical.
-// - OS version and variant are identical if provided.
+// - variant is identical if provided.
+// - OS version is compatible if provided, see v1.Platform.Compatible.
 // - features and OS features of the required platform are subsets of those of the given platform.
 func matchesPlatform(given, required v1.Platform) bool {
 	// Required fields that must be identical.
@@ -251,8 +255,9 @@ func matchesPlatform(given, required v1.Platform) bool {
 		return false
 	}
 
-	// Optional fields that may be empty, but must be identical if provided.
-	if required.OSVersion != "" && given.OSVersion != required.OSVersion {
+	// Optional fields that may be empty, but must be compatible if provided.
+	// Windows images run on hosts of the same build, whatever the revision.
+	if required.OSVersion != "" && (given.OSVersion == "" || !given.Compatible(required)) {
 		return false
 	}
 	if required.Variant != "" && given.Variant != required.Variant {
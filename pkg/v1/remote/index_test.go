@@ -494,6 +494,34 @@ func TestMatchesPlatform(t *testing.T) {
 			},
 			want: true,
 		},
+		{ // Windows OS versions only need the same build, not revision.
+			// matchesPlatform expected to return true.
+			given: v1.Platform{
+				Architecture: "amd64",
+				OS:           "windows",
+				OSVersion:    "10.0.17763.1234",
+			},
+			required: v1.Platform{
+				Architecture: "amd64",
+				OS:           "windows",
+				OSVersion:    "10.0.17763.5678",
+			},
+			want: true,
+		},
+		{ // Windows OS versions with a different build don't match.
+			// matchesPlatform expected to return false.
+			given: v1.Platform{
+				Architecture: "amd64",
+				OS:           "windows",
+				OSVersion:    "10.0.17763.1234",
+			},
+			required: v1.Platform{
+				Architecture: "amd64",
+				OS:           "windows",
+				OSVersion:    "10.0.20348.1234",
+			},
+			want: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,171 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// inline embeds the blobs that t refers to that are at most threshold bytes,
+// see WithInlineData: an image's config, or the manifests of an index's
+// children. Anything else is returned unchanged.
+func inline(t Taggable, threshold int64) (Taggable, error) {
+	switch t := t.(type) {
+	case v1.Image:
+		return inlineConfig(t, threshold)
+	case v1.ImageIndex:
+		return inlineManifests(t, threshold)
+	}
+	return t, nil
+}
+
+func inlineConfig(img v1.Image, threshold int64) (Taggable, error) {
+	m, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	if m.Config.Data != nil || m.Config.Size > threshold {
+		return img, nil
+	}
+	cfg, err := img.RawConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	m = m.DeepCopy()
+	m.Config = partial.Inline(m.Config, cfg, threshold)
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return &inlinedImage{Image: img, manifest: b}, nil
+}
+
+func inlineManifests(idx v1.ImageIndex, threshold int64) (Taggable, error) {
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	im = im.DeepCopy()
+	var changed bool
+	for i, desc := range im.Manifests {
+		if desc.Data != nil || desc.Size > threshold {
+			continue
+		}
+		var child partial.WithRawManifest
+		switch {
+		case desc.MediaType.IsImage():
+			if child, err = idx.Image(desc.Digest); err != nil {
+				return nil, err
+			}
+		case desc.MediaType.IsIndex():
+			if child, err = idx.ImageIndex(desc.Digest); err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+		b, err := child.RawManifest()
+		if err != nil {
+			return nil, err
+		}
+		im.Manifests[i] = partial.Inline(desc, b, threshold)
+		changed = true
+	}
+	if !changed {
+		return idx, nil
+	}
+	b, err := json.Marshal(im)
+	if err != nil {
+		return nil, err
+	}
+	return &inlinedIndex{base: idx, manifest: b}, nil
+}
+
+// inlinedImage is an image whose manifest has its config embedded.
+type inlinedImage struct {
+	v1.Image
+
+	manifest []byte
+}
+
+// RawManifest implements v1.Image
+func (i *inlinedImage) RawManifest() ([]byte, error) {
+	return i.manifest, nil
+}
+
+// Manifest implements v1.Image
+func (i *inlinedImage) Manifest() (*v1.Manifest, error) {
+	return partial.Manifest(i)
+}
+
+// Digest implements v1.Image
+func (i *inlinedImage) Digest() (v1.Hash, error) {
+	return partial.Digest(i)
+}
+
+// Size implements v1.Image
+func (i *inlinedImage) Size() (int64, error) {
+	return partial.Size(i)
+}
+
+// inlinedIndex is an index whose manifest has its children's manifests
+// embedded.
+//
+// It can't embed v1.ImageIndex, whose ImageIndex method the field would hide.
+type inlinedIndex struct {
+	base v1.ImageIndex
+
+	manifest []byte
+}
+
+// MediaType implements v1.ImageIndex
+func (i *inlinedIndex) MediaType() (types.MediaType, error) {
+	return i.base.MediaType()
+}
+
+// RawManifest implements v1.ImageIndex
+func (i *inlinedIndex) RawManifest() ([]byte, error) {
+	return i.manifest, nil
+}
+
+// IndexManifest implements v1.ImageIndex
+func (i *inlinedIndex) IndexManifest() (*v1.IndexManifest, error) {
+	return v1.ParseIndexManifest(bytes.NewReader(i.manifest))
+}
+
+// Digest implements v1.ImageIndex
+func (i *inlinedIndex) Digest() (v1.Hash, error) {
+	return partial.Digest(i)
+}
+
+// Size implements v1.ImageIndex
+func (i *inlinedIndex) Size() (int64, error) {
+	return partial.Size(i)
+}
+
+// Image implements v1.ImageIndex
+func (i *inlinedIndex) Image(h v1.Hash) (v1.Image, error) {
+	return i.base.Image(h)
+}
+
+// ImageIndex implements v1.ImageIndex
+func (i *inlinedIndex) ImageIndex(h v1.Hash) (v1.ImageIndex, error) {
+	return i.base.ImageIndex(h)
+}
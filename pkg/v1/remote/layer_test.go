@@ -16,8 +16,11 @@ package remote
 
 import (
 	"fmt"
+	"io"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -146,3 +149,68 @@ func TestRemoteLayerDescriptor(t *testing.T) {
 		t.Errorf("Exists() = %t != %t", got, want)
 	}
 }
+
+func TestInsecureSkipBlobVerification(t *testing.T) {
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := layers[0].Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Serve the layer with its first byte flipped.
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasSuffix(r.URL.Path, digest.String()) {
+			reg.ServeHTTP(w, r)
+			return
+		}
+		rec := httptest.NewRecorder()
+		reg.ServeHTTP(rec, r)
+		b := rec.Body.Bytes()
+		b[0] ^= 0xff
+		w.Write(b)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(fmt.Sprintf("%s/some/path:tag", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(opts ...Option) error {
+		img, err := Image(ref, opts...)
+		if err != nil {
+			return err
+		}
+		l, err := img.LayerByDigest(digest)
+		if err != nil {
+			return err
+		}
+		rc, err := l.Compressed()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		_, err = io.Copy(io.Discard, rc)
+		return err
+	}
+	if err := read(); err == nil || !strings.Contains(err.Error(), "error verifying") {
+		t.Errorf("read(): want a verification error, got %v", err)
+	}
+	if err := read(WithInsecureSkipBlobVerification()); err != nil {
+		t.Errorf("read(WithInsecureSkipBlobVerification()): %v", err)
+	}
+}
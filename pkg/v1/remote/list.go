@@ -40,7 +40,7 @@ func List(repo name.Repository, options ...Option) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	return newPuller(o).List(o.context, repo)
+	return newPuller(o).list(o.context, repo, o.pageSize, o.last)
 }
 
 type Tags struct {
@@ -49,16 +49,23 @@ type Tags struct {
 	Next string   `json:"next,omitempty"`
 }
 
-func (f *fetcher) listPage(ctx context.Context, repo name.Repository, next string, pageSize int) (*Tags, error) {
+// listPage fetches the page of tags at next or, if it's empty, the first
+// page of at most pageSize tags after last.
+func (f *fetcher) listPage(ctx context.Context, repo name.Repository, next string, pageSize int, last string) (*Tags, error) {
 	if next == "" {
 		uri := &url.URL{
 			Scheme: repo.Scheme(),
 			Host:   repo.RegistryStr(),
 			Path:   fmt.Sprintf("/v2/%s/tags/list", repo.RepositoryStr()),
 		}
+		var query []string
 		if pageSize > 0 {
-			uri.RawQuery = fmt.Sprintf("n=%d", pageSize)
+			query = append(query, fmt.Sprintf("n=%d", pageSize))
 		}
+		if last != "" {
+			query = append(query, "last="+url.QueryEscape(last))
+		}
+		uri.RawQuery = strings.Join(query, "&")
 		next = uri.String()
 	}
 
@@ -140,7 +147,7 @@ type Lister struct {
 
 func (l *Lister) Next(ctx context.Context) (*Tags, error) {
 	if l.needMore {
-		l.page, l.err = l.f.listPage(ctx, l.repo, l.page.Next, l.pageSize)
+		l.page, l.err = l.f.listPage(ctx, l.repo, l.page.Next, l.pageSize, "")
 	} else {
 		l.needMore = true
 	}
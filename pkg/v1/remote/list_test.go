@@ -25,6 +25,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
 )
 
 func TestList(t *testing.T) {
@@ -86,6 +88,52 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestListPages(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(u.Host + "/test/list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"a", "b", "c", "d", "e"} {
+		if err := Write(repo.Tag(tag), img); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		options []Option
+		want    []string
+	}{{
+		name:    "pages",
+		options: []Option{WithPageSize(2)},
+		want:    []string{"a", "b", "c", "d", "e"},
+	}, {
+		name:    "last",
+		options: []Option{WithPageSize(2), WithLast("b")},
+		want:    []string{"c", "d", "e"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tags, err := List(repo, tc.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, tags); diff != "" {
+				t.Errorf("List() wrong tags (-want +got) = %s", diff)
+			}
+		})
+	}
+}
+
 func TestCancelledList(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RequestStats describes a request that was made to a registry, for
+// WithMetrics.
+type RequestStats struct {
+	// Registry is the host the request was sent to, e.g. "gcr.io", or the
+	// host of a token endpoint or blob storage that the registry uses.
+	Registry string
+	Method   string
+
+	// StatusCode is the response's status, or 0 if there was none, e.g.
+	// because the connection failed, in which case Err says why.
+	StatusCode int
+	Err        error
+
+	// Retries is how many times the request was retried.
+	Retries int
+
+	// BytesSent and BytesReceived count the request and response bodies,
+	// including those of retries.
+	BytesSent     int64
+	BytesReceived int64
+
+	// Latency is how long the response headers took to arrive, and Duration
+	// how long it took until the response body was closed.
+	Latency  time.Duration
+	Duration time.Duration
+}
+
+// WithMetrics calls report with the RequestStats of every request made to
+// registries, once the request is done, so that they can be exported as
+// metrics, e.g. counters of requests, retries and bytes and histograms of
+// latencies, by registry.
+//
+// report may be called concurrently. It isn't called for transports that
+// are passed to WithTransport wrapped in a transport.Wrapper.
+func WithMetrics(report func(RequestStats)) Option {
+	return func(o *options) error {
+		o.metrics = report
+		return nil
+	}
+}
+
+type statsKey struct{}
+
+// requestStats accumulates the stats of a request across its attempts.
+type requestStats struct {
+	attempts atomic.Int64
+	sent     atomic.Int64
+}
+
+// metricsTransport reports the RequestStats of requests. It goes outside the
+// retry transport, so each request is reported once however many times it's
+// attempted, and attemptTransport goes inside it, to count the attempts.
+type metricsTransport struct {
+	inner  http.RoundTripper
+	report func(RequestStats)
+}
+
+func (t *metricsTransport) RoundTrip(in *http.Request) (*http.Response, error) {
+	rs := &requestStats{}
+	start := time.Now()
+	out, err := t.inner.RoundTrip(in.WithContext(context.WithValue(in.Context(), statsKey{}, rs)))
+
+	stats := RequestStats{
+		Registry: in.URL.Host,
+		Method:   in.Method,
+		Err:      err,
+		Latency:  time.Since(start),
+	}
+	if out == nil {
+		stats.Retries = max(int(rs.attempts.Load())-1, 0)
+		stats.BytesSent = rs.sent.Load()
+		stats.Duration = stats.Latency
+		t.report(stats)
+		return out, err
+	}
+	stats.StatusCode = out.StatusCode
+	out.Body = &metricsBody{
+		ReadCloser: out.Body,
+		done: func(received int64) {
+			stats.Retries = max(int(rs.attempts.Load())-1, 0)
+			stats.BytesSent = rs.sent.Load()
+			stats.BytesReceived = received
+			stats.Duration = time.Since(start)
+			t.report(stats)
+		},
+	}
+	return out, err
+}
+
+// metricsBody counts the bytes read from a response body, and reports them
+// when it's closed.
+type metricsBody struct {
+	io.ReadCloser
+	n    int64
+	once sync.Once
+	done func(int64)
+}
+
+func (b *metricsBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *metricsBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.n) })
+	return err
+}
+
+// attemptTransport counts the attempts of requests that metricsTransport
+// reports, and the bytes that each attempt sends.
+type attemptTransport struct {
+	inner http.RoundTripper
+}
+
+func (t *attemptTransport) RoundTrip(in *http.Request) (*http.Response, error) {
+	rs, ok := in.Context().Value(statsKey{}).(*requestStats)
+	if !ok {
+		return t.inner.RoundTrip(in)
+	}
+	rs.attempts.Add(1)
+	if in.Body != nil && in.Body != http.NoBody {
+		in = in.Clone(in.Context())
+		in.Body = &countingBody{ReadCloser: in.Body, n: &rs.sent}
+	}
+	return t.inner.RoundTrip(in)
+}
+
+// countingBody counts the bytes read from a request body. It may be read by
+// another goroutine than the one that reports them.
+type countingBody struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestMetrics(t *testing.T) {
+	layer, err := random.Layer(1024, types.DockerLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := layer.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, err := layer.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fail the first GET of the blob, so that it's retried.
+	var failed bool
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/blobs/") && !failed {
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.NewDigest(fmt.Sprintf("%s/some/path@%s", u.Host, digest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu    sync.Mutex
+		stats []RequestStats
+	)
+	metrics := WithMetrics(func(rs RequestStats) {
+		mu.Lock()
+		defer mu.Unlock()
+		stats = append(stats, rs)
+	})
+	find := func(method string) *RequestStats {
+		for _, rs := range stats {
+			if rs.Method == method && (rs.BytesSent == size || rs.BytesReceived == size) {
+				return &rs
+			}
+		}
+		return nil
+	}
+
+	if err := WriteLayer(ref.Context(), layer, metrics); err != nil {
+		t.Fatal(err)
+	}
+	if rs := find(http.MethodPatch); rs == nil {
+		t.Errorf("no PATCH that sent %d bytes in %+v", size, stats)
+	} else if rs.Registry != u.Host || rs.StatusCode/100 != 2 || rs.Retries != 0 {
+		t.Errorf("PATCH: got %+v", rs)
+	}
+
+	l, err := Layer(ref, metrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(io.Discard, rc); err != nil {
+		t.Fatal(err)
+	}
+	if err := rc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	rs := find(http.MethodGet)
+	if rs == nil {
+		t.Fatalf("no GET that received %d bytes in %+v", size, stats)
+	}
+	if rs.StatusCode != http.StatusOK || rs.Retries != 1 || rs.Err != nil {
+		t.Errorf("GET: got %+v, want a 200 after 1 retry", rs)
+	}
+	if rs.Duration < rs.Latency {
+		t.Errorf("GET: Duration %v < Latency %v", rs.Duration, rs.Latency)
+	}
+}
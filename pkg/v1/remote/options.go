@@ -16,7 +16,9 @@ package remote
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
@@ -25,7 +27,9 @@ import (
 
 	"github.com/google/go-containerregistry/internal/retry"
 	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/authn/cache"
 	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 )
@@ -37,6 +41,7 @@ type options struct {
 	auth                           authn.Authenticator
 	keychain                       authn.Keychain
 	transport                      http.RoundTripper
+	tuning                         []func(*http.Transport)
 	context                        context.Context
 	jobs                           int
 	userAgent                      string
@@ -45,10 +50,18 @@ type options struct {
 	retryBackoff                   Backoff
 	retryPredicate                 retry.Predicate
 	retryStatusCodes               []int
+	tokenCache                     cache.Cache
+	inline                         int64
+	verify                         VerifyFunc
+	resumeDir                      string
+	metrics                        func(RequestStats)
+	skipBlobVerification           bool
+	pushPolicies                   []Policy
 
 	// Only these options can overwrite Reuse()d options.
 	platform v1.Platform
 	pageSize int
+	last     string
 	filter   map[string]string
 
 	// Set by Reuse, we currently store one or the other.
@@ -152,9 +165,22 @@ func makeOptions(opts ...Option) (*options, error) {
 		o.auth = authn.Anonymous
 	}
 
+	if len(o.tuning) != 0 {
+		t, err := tune(o.transport, o.tuning)
+		if err != nil {
+			return nil, err
+		}
+		o.transport = t
+	}
+
 	// transport.Wrapper is a signal that consumers are opt-ing into providing their own transport without any additional wrapping.
 	// This is to allow consumers full control over the transports logic, such as providing retry logic.
 	if _, ok := o.transport.(*transport.Wrapper); !ok {
+		// Count the attempts of requests, underneath the retries.
+		if o.metrics != nil {
+			o.transport = &attemptTransport{inner: o.transport}
+		}
+
 		// Wrap the transport in something that logs requests and responses.
 		// It's expensive to generate the dumps, so skip it if we're writing
 		// to nothing.
@@ -165,6 +191,11 @@ func makeOptions(opts ...Option) (*options, error) {
 		// Wrap the transport in something that can retry network flakes.
 		o.transport = transport.NewRetry(o.transport, transport.WithRetryPredicate(defaultRetryPredicate), transport.WithRetryStatusCodes(o.retryStatusCodes...))
 
+		// Report each request once, however many times it was retried.
+		if o.metrics != nil {
+			o.transport = &metricsTransport{inner: o.transport, report: o.metrics}
+		}
+
 		// Wrap this last to prevent transport.New from double-wrapping.
 		if o.userAgent != "" {
 			o.transport = transport.NewUserAgent(o.transport, o.userAgent)
@@ -187,6 +218,78 @@ func WithTransport(t http.RoundTripper) Option {
 	}
 }
 
+// tune applies tuning to a copy of t, which must be an *http.Transport, so
+// that DefaultTransport and any transport passed to WithTransport are left
+// as they are.
+func tune(t http.RoundTripper, tuning []func(*http.Transport)) (http.RoundTripper, error) {
+	ht, ok := t.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("transport tuning options require an *http.Transport, got %T", t)
+	}
+	ht = ht.Clone()
+	for _, f := range tuning {
+		f(ht)
+	}
+	return ht, nil
+}
+
+// WithMaxConnsPerHost limits the number of connections to each registry,
+// including those in use, like http.Transport.MaxConnsPerHost. Zero means no
+// limit, which is the default.
+//
+// This and the other transport tuning options apply to DefaultTransport,
+// or to the *http.Transport passed to WithTransport.
+func WithMaxConnsPerHost(n int) Option {
+	return func(o *options) error {
+		o.tuning = append(o.tuning, func(t *http.Transport) {
+			t.MaxConnsPerHost = n
+		})
+		return nil
+	}
+}
+
+// WithMaxIdleConnsPerHost sets the number of idle connections to keep open
+// to each registry, like http.Transport.MaxIdleConnsPerHost. Raising it
+// (and MaxIdleConns with it) lets pushes with many jobs reuse connections
+// rather than open new ones. The default is 50.
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(o *options) error {
+		o.tuning = append(o.tuning, func(t *http.Transport) {
+			t.MaxIdleConnsPerHost = n
+			if t.MaxIdleConns != 0 && t.MaxIdleConns < n {
+				t.MaxIdleConns = n
+			}
+		})
+		return nil
+	}
+}
+
+// WithIdleConnTimeout sets how long idle connections are kept open, like
+// http.Transport.IdleConnTimeout. The default is 90 seconds.
+func WithIdleConnTimeout(d time.Duration) Option {
+	return func(o *options) error {
+		o.tuning = append(o.tuning, func(t *http.Transport) {
+			t.IdleConnTimeout = d
+		})
+		return nil
+	}
+}
+
+// WithHTTP2 sets whether HTTP/2 is attempted with registries that support
+// it, which it is by default.
+func WithHTTP2(enabled bool) Option {
+	return func(o *options) error {
+		o.tuning = append(o.tuning, func(t *http.Transport) {
+			t.ForceAttemptHTTP2 = enabled
+			if !enabled {
+				// A non-nil, empty map disables HTTP/2.
+				t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+			}
+		})
+		return nil
+	}
+}
+
 // WithAuth is a functional option for overriding the default authenticator
 // for remote operations.
 // It is an error to use both WithAuth and WithAuthFromKeychain in the same Option set.
@@ -252,6 +355,22 @@ func WithJobs(jobs int) Option {
 	}
 }
 
+// WithInlineData is a functional option for embedding small blobs in the
+// descriptors that refer to them when pushing, so that pulling them takes
+// fewer round trips: an image's config if it's at most threshold bytes, or the
+// manifests of an index's children that are.
+//
+// This changes the digest of what's pushed. It's off by default.
+func WithInlineData(threshold int64) Option {
+	return func(o *options) error {
+		if threshold <= 0 {
+			return errors.New("inline data threshold must be greater than zero")
+		}
+		o.inline = threshold
+		return nil
+	}
+}
+
 // WithUserAgent adds the given string to the User-Agent header for any HTTP
 // requests. This header will also include "go-containerregistry/${version}".
 //
@@ -296,6 +415,16 @@ func WithPageSize(size int) Option {
 	}
 }
 
+// WithLast sets the given tag as the value of parameter 'last' in the first
+// request to list tags, so that only the tags that sort after it are listed.
+// Registries that don't support it list every tag.
+func WithLast(tag string) Option {
+	return func(o *options) error {
+		o.last = tag
+		return nil
+	}
+}
+
 // WithRetryBackoff sets the httpBackoff for retry HTTP operations.
 func WithRetryBackoff(backoff Backoff) Option {
 	return func(o *options) error {
@@ -331,6 +460,51 @@ func WithFilter(key string, value string) Option {
 	}
 }
 
+// WithTokenCache shares bearer tokens through c, so that Pullers, Pushers and
+// separate operations against the same registry with the same credentials
+// reuse each other's tokens instead of each performing a token exchange.
+//
+// Cached tokens are refreshed shortly before they expire.
+func WithTokenCache(c cache.Cache) Option {
+	return func(o *options) error {
+		o.tokenCache = c
+		return nil
+	}
+}
+
+// VerifyFunc is called with each manifest that is pulled, before it's
+// returned, and fails the pull if it returns an error. ref is the reference
+// that was pulled and desc describes what it resolved to.
+type VerifyFunc func(ctx context.Context, ref name.Reference, desc v1.Descriptor) error
+
+// WithVerify runs verify on every manifest that is pulled by reference, e.g.
+// by Get, Head, Image or Index, so that pull policies (signatures, allowed
+// registries, requiring digests) can be enforced by anything built on this
+// package. Children of an index are pinned by digest by their verified
+// parent, so they aren't verified again, and neither are blobs.
+func WithVerify(verify VerifyFunc) Option {
+	return func(o *options) error {
+		o.verify = verify
+		return nil
+	}
+}
+
+// WithInsecureSkipBlobVerification makes pulls return blobs without checking
+// them against their digests, to save hashing them when the registry is
+// trusted and throughput matters, e.g. when priming a mirror in the same
+// datacenter. Their sizes are still checked, and manifests are still
+// verified.
+//
+// This is insecure: a registry, or anything between it and the client, can
+// then serve any content for a digest. Don't use it with registries that
+// aren't trusted.
+func WithInsecureSkipBlobVerification() Option {
+	return func(o *options) error {
+		o.skipBlobVerification = true
+		return nil
+	}
+}
+
 // Reuse takes a Puller or Pusher and reuses it for remote interactions
 // rather than starting from a clean slate. For example, it will reuse token exchanges
 // when possible and avoid sending redundant HEAD requests.
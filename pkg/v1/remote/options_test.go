@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTransportTuning(t *testing.T) {
+	o, err := makeOptions(
+		WithMaxConnsPerHost(8),
+		WithMaxIdleConnsPerHost(200),
+		WithIdleConnTimeout(time.Minute),
+		WithHTTP2(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(o.tuning) != 4 {
+		t.Fatalf("got %d tuning options, want 4", len(o.tuning))
+	}
+
+	rt, err := tune(DefaultTransport, o.tuning)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rt.(*http.Transport)
+	if got.MaxConnsPerHost != 8 {
+		t.Errorf("MaxConnsPerHost = %d, want 8", got.MaxConnsPerHost)
+	}
+	if got.MaxIdleConnsPerHost != 200 {
+		t.Errorf("MaxIdleConnsPerHost = %d, want 200", got.MaxIdleConnsPerHost)
+	}
+	if got.MaxIdleConns != 200 {
+		t.Errorf("MaxIdleConns = %d, want 200", got.MaxIdleConns)
+	}
+	if got.IdleConnTimeout != time.Minute {
+		t.Errorf("IdleConnTimeout = %v, want 1m", got.IdleConnTimeout)
+	}
+	if got.ForceAttemptHTTP2 || got.TLSNextProto == nil {
+		t.Error("HTTP/2 is still enabled")
+	}
+
+	// DefaultTransport itself is left alone.
+	def := DefaultTransport.(*http.Transport)
+	if def.MaxConnsPerHost != 0 || def.MaxIdleConnsPerHost != 50 || !def.ForceAttemptHTTP2 {
+		t.Errorf("DefaultTransport was modified: %+v", def)
+	}
+
+	// Only *http.Transports can be tuned.
+	if _, err := makeOptions(WithTransport(http.NewFileTransport(http.Dir("."))), WithMaxConnsPerHost(1)); err == nil {
+		t.Error("tuning a non-*http.Transport: want error, got nil")
+	}
+}
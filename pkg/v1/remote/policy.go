@@ -0,0 +1,228 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Policy checks an image or index that is about to be pushed, or that is
+// being pulled, and fails the push or pull if it returns an error; see
+// WithPushPolicy and PullPolicy. t is a
+// v1.Image or v1.ImageIndex, except when pushing other Taggables.
+//
+// Policies should report what's wrong with a *PolicyViolation, so that
+// callers can tell violations apart from errors checking them.
+type Policy func(ctx context.Context, ref name.Reference, t Taggable) error
+
+// PolicyViolation is an error that reports an image or index that violates
+// a Policy.
+type PolicyViolation struct {
+	// Ref is what was being pushed or pulled. It's filled in for policies
+	// that don't set it.
+	Ref name.Reference
+	// Policy names the policy that was violated, e.g. "required-labels".
+	Policy string
+	// Reason explains what's wrong.
+	Reason string
+}
+
+func (v *PolicyViolation) Error() string {
+	return fmt.Sprintf("%s violates policy %q: %s", v.Ref, v.Policy, v.Reason)
+}
+
+// WithPushPolicy runs policies on every image or index that is pushed by
+// Write, WriteIndex, Push, Put, Tag, MultiWrite or a Pusher, before anything
+// is uploaded. Only the top-level manifest is checked, so policies that
+// care about the children of an index should check them.
+func WithPushPolicy(policies ...Policy) Option {
+	return func(o *options) error {
+		o.pushPolicies = append(o.pushPolicies, policies...)
+		return nil
+	}
+}
+
+// PullPolicy returns a VerifyFunc, for WithVerify, that runs policies on
+// every image or index that is pulled. What a pull resolves to is read with
+// options, which must not include the returned VerifyFunc itself, and
+// checked as a whole, so an index fails if any of its images do, whichever
+// platform is pulled from it. Unlike most VerifyFuncs, policies see the
+// contents of what's pulled, so they can check its config and layers.
+func PullPolicy(policies []Policy, options ...Option) VerifyFunc {
+	return func(ctx context.Context, ref name.Reference, desc v1.Descriptor) error {
+		options := append(slices.Clip(options), WithContext(ctx))
+		pinned := ref.Context().Digest(desc.Digest.String())
+		var (
+			t   Taggable
+			err error
+		)
+		switch {
+		case desc.MediaType.IsIndex():
+			t, err = Index(pinned, options...)
+		case desc.MediaType.IsImage():
+			t, err = Image(pinned, options...)
+		default:
+			t, err = Get(pinned, options...)
+		}
+		if err != nil {
+			return err
+		}
+		return checkPolicies(ctx, policies, ref, t)
+	}
+}
+
+// checkPolicies runs all of policies, returning any errors they return.
+func checkPolicies(ctx context.Context, policies []Policy, ref name.Reference, t Taggable) error {
+	var errs []error
+	for _, policy := range policies {
+		err := policy(ctx, ref, t)
+		if err == nil {
+			continue
+		}
+		var v *PolicyViolation
+		if errors.As(err, &v) && v.Ref == nil {
+			v.Ref = ref
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// images returns the images that t is, or that it refers to, for policies
+// that check images.
+func images(t Taggable) ([]v1.Image, error) {
+	switch t := t.(type) {
+	case v1.Image:
+		return []v1.Image{t}, nil
+	case v1.ImageIndex:
+		im, err := t.IndexManifest()
+		if err != nil {
+			return nil, err
+		}
+		var imgs []v1.Image
+		for _, desc := range im.Manifests {
+			switch {
+			case desc.MediaType.IsImage():
+				img, err := t.Image(desc.Digest)
+				if err != nil {
+					return nil, err
+				}
+				imgs = append(imgs, img)
+			case desc.MediaType.IsIndex():
+				idx, err := t.ImageIndex(desc.Digest)
+				if err != nil {
+					return nil, err
+				}
+				children, err := images(idx)
+				if err != nil {
+					return nil, err
+				}
+				imgs = append(imgs, children...)
+			}
+		}
+		return imgs, nil
+	}
+	return nil, nil
+}
+
+// RequireLabels is a Policy that requires images, and the images in
+// indexes, to have all of the given labels in their configs.
+func RequireLabels(labels ...string) Policy {
+	return func(_ context.Context, _ name.Reference, t Taggable) error {
+		imgs, err := images(t)
+		if err != nil {
+			return err
+		}
+		for _, img := range imgs {
+			cf, err := img.ConfigFile()
+			if err != nil {
+				return err
+			}
+			var missing []string
+			for _, label := range labels {
+				if _, ok := cf.Config.Labels[label]; !ok {
+					missing = append(missing, label)
+				}
+			}
+			if len(missing) != 0 {
+				return &PolicyViolation{Policy: "required-labels", Reason: "missing labels " + strings.Join(missing, ", ")}
+			}
+		}
+		return nil
+	}
+}
+
+// MaxLayerSize is a Policy that rejects images, and indexes of images, with
+// a compressed layer bigger than size bytes.
+func MaxLayerSize(size int64) Policy {
+	return func(_ context.Context, _ name.Reference, t Taggable) error {
+		imgs, err := images(t)
+		if err != nil {
+			return err
+		}
+		for _, img := range imgs {
+			m, err := img.Manifest()
+			if err != nil {
+				return err
+			}
+			for _, l := range m.Layers {
+				if l.Size > size {
+					return &PolicyViolation{Policy: "max-layer-size", Reason: fmt.Sprintf("layer %s is %d bytes, more than %d", l.Digest, l.Size, size)}
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// DenyBaseImages is a Policy that rejects images whose base image, as
+// recorded by the org.opencontainers.image.base.name annotation, is in one
+// of the given repositories, e.g. "docker.io/library/debian".
+func DenyBaseImages(repos ...name.Repository) Policy {
+	return func(_ context.Context, _ name.Reference, t Taggable) error {
+		imgs, err := images(t)
+		if err != nil {
+			return err
+		}
+		for _, img := range imgs {
+			m, err := img.Manifest()
+			if err != nil {
+				return err
+			}
+			base, ok := m.Annotations[specsv1.AnnotationBaseImageName]
+			if !ok {
+				continue
+			}
+			ref, err := name.ParseReference(base)
+			if err != nil {
+				return &PolicyViolation{Policy: "denied-base-images", Reason: fmt.Sprintf("can't parse base image %q: %v", base, err)}
+			}
+			for _, repo := range repos {
+				if ref.Context().Name() == repo.Name() {
+					return &PolicyViolation{Policy: "denied-base-images", Reason: fmt.Sprintf("base image %s is denied", base)}
+				}
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func policyRepo(t *testing.T) name.Repository {
+	t.Helper()
+	s := httptest.NewServer(registry.New())
+	t.Cleanup(s.Close)
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(u.Host + "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func labeled(t *testing.T, labels map[string]string) v1.Image {
+	t.Helper()
+	img, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf = cf.DeepCopy()
+	cf.Config.Labels = labels
+	img, err = mutate.ConfigFile(img, cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func wantViolation(t *testing.T, err error, ref name.Reference, policy string) {
+	t.Helper()
+	var v *PolicyViolation
+	if !errors.As(err, &v) {
+		t.Fatalf("got %v, want a *PolicyViolation", err)
+	}
+	if v.Policy != policy {
+		t.Errorf("Policy: got %q, want %q", v.Policy, policy)
+	}
+	if v.Ref == nil || v.Ref.String() != ref.String() {
+		t.Errorf("Ref: got %v, want %v", v.Ref, ref)
+	}
+}
+
+func TestPushPolicy(t *testing.T) {
+	repo := policyRepo(t)
+	tag := repo.Tag("latest")
+	policy := WithPushPolicy(RequireLabels("owner"))
+
+	err := Write(tag, labeled(t, nil), policy)
+	wantViolation(t, err, tag, "required-labels")
+	if _, err := Head(tag); err == nil {
+		t.Error("Head: image was pushed despite violating policy")
+	}
+
+	idx := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: labeled(t, nil)})
+	wantViolation(t, WriteIndex(tag, idx, policy), tag, "required-labels")
+
+	if err := Write(tag, labeled(t, map[string]string{"owner": "me"}), policy); err != nil {
+		t.Errorf("Write: %v", err)
+	}
+}
+
+func TestPullPolicy(t *testing.T) {
+	repo := policyRepo(t)
+	tag := repo.Tag("latest")
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(tag, img); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Image(tag, WithVerify(PullPolicy([]Policy{MaxLayerSize(512)})))
+	wantViolation(t, err, tag, "max-layer-size")
+	if _, err := Head(tag, WithVerify(PullPolicy([]Policy{MaxLayerSize(512)}))); err == nil {
+		t.Error("Head: want a violation, got nil")
+	}
+	if _, err := Image(tag, WithVerify(PullPolicy([]Policy{MaxLayerSize(4096)}))); err != nil {
+		t.Errorf("Image: %v", err)
+	}
+}
+
+func TestPullPolicyPlatform(t *testing.T) {
+	repo := policyRepo(t)
+	tag := repo.Tag("latest")
+
+	base, err := name.ParseReference("debian:12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	amd64 := mutate.Annotations(labeled(t, nil), map[string]string{
+		specsv1.AnnotationBaseImageName: base.Name(),
+	}).(v1.Image)
+	arm64 := labeled(t, nil)
+	idx := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+	)
+	if err := WriteIndex(tag, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The index is checked as a whole, whichever platform is pulled.
+	policy := WithVerify(PullPolicy([]Policy{DenyBaseImages(base.Context())}))
+	for _, arch := range []string{"amd64", "arm64"} {
+		_, err = Image(tag, policy, WithPlatform(v1.Platform{OS: "linux", Architecture: arch}))
+		wantViolation(t, err, tag, "denied-base-images")
+	}
+	_, err = Index(tag, policy)
+	wantViolation(t, err, tag, "denied-base-images")
+
+	// Pulled by itself, the arm64 image passes.
+	d, err := arm64.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Image(repo.Digest(d.String()), WithVerify(PullPolicy([]Policy{DenyBaseImages(base.Context())}))); err != nil {
+		t.Errorf("Image(arm64 digest): %v", err)
+	}
+}
@@ -64,13 +64,13 @@ type progressReader struct {
 
 func (r *progressReader) Read(b []byte) (int, error) {
 	n, err := r.rc.Read(b)
-	if err != nil {
-		return n, err
+	// Readers may return the last of their data along with io.EOF.
+	if n > 0 {
+		atomic.AddInt64(r.count, int64(n))
+		// TODO: warn/debug log if sending takes too long, or if sending is blocked while context is canceled.
+		r.progress.complete(int64(n))
 	}
-	atomic.AddInt64(r.count, int64(n))
-	// TODO: warn/debug log if sending takes too long, or if sending is blocked while context is canceled.
-	r.progress.complete(int64(n))
-	return n, nil
+	return n, err
 }
 
 func (r *progressReader) Close() error { return r.rc.Close() }
@@ -16,6 +16,7 @@ package remote
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -81,12 +82,28 @@ func (p *Puller) fetcher(ctx context.Context, target resource) (*fetcher, error)
 
 // Head is like remote.Head, but avoids re-authenticating when possible.
 func (p *Puller) Head(ctx context.Context, ref name.Reference) (*v1.Descriptor, error) {
+	if _, ok := ref.(name.PlatformReference); ok {
+		// Only the index knows the platforms of its children, so we GET it.
+		desc, err := p.get(ctx, ref, allManifestMediaTypes, p.o.platform)
+		if err != nil {
+			return nil, err
+		}
+		return &desc.Descriptor, nil
+	}
+
 	f, err := p.fetcher(ctx, ref.Context())
 	if err != nil {
 		return nil, err
 	}
 
-	return f.headManifest(ctx, ref, allManifestMediaTypes)
+	desc, err := f.headManifest(ctx, ref, allManifestMediaTypes)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.verify(ctx, ref, *desc); err != nil {
+		return nil, err
+	}
+	return desc, nil
 }
 
 // Get is like remote.Get, but avoids re-authenticating when possible.
@@ -95,11 +112,53 @@ func (p *Puller) Get(ctx context.Context, ref name.Reference) (*Descriptor, erro
 }
 
 func (p *Puller) get(ctx context.Context, ref name.Reference, acceptable []types.MediaType, platform v1.Platform) (*Descriptor, error) {
+	if pr, ok := ref.(name.PlatformReference); ok {
+		return p.getPlatform(ctx, pr, acceptable)
+	}
+
 	f, err := p.fetcher(ctx, ref.Context())
 	if err != nil {
 		return nil, err
 	}
-	return f.get(ctx, ref, acceptable, platform)
+	desc, err := f.get(ctx, ref, acceptable, platform)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.verify(ctx, ref, desc.Descriptor); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
+
+// verify runs the WithVerify callback, if any, on what ref resolved to.
+func (p *Puller) verify(ctx context.Context, ref name.Reference, desc v1.Descriptor) error {
+	if p.o.verify == nil {
+		return nil
+	}
+	if err := p.o.verify(ctx, ref, desc); err != nil {
+		return fmt.Errorf("verifying %s: %w", ref, err)
+	}
+	return nil
+}
+
+// getPlatform resolves ref to the one image for its platform, which takes
+// precedence over WithPlatform, if it refers to an index.
+func (p *Puller) getPlatform(ctx context.Context, ref name.PlatformReference, acceptable []types.MediaType) (*Descriptor, error) {
+	platform, err := v1.ParsePlatform(ref.Platform())
+	if err != nil {
+		return nil, err
+	}
+	desc, err := p.get(ctx, ref.Reference, acceptable, *platform)
+	if err != nil {
+		return nil, err
+	}
+	for desc.MediaType.IsIndex() {
+		desc, err = desc.remoteIndex().childByPlatform(*platform)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return desc, nil
 }
 
 // Layer is like remote.Layer, but avoids re-authenticating when possible.
@@ -129,7 +188,11 @@ func (p *Puller) Layer(ctx context.Context, ref name.Digest) (v1.Layer, error) {
 
 // List lists tags in a repo and handles pagination, returning the full list of tags.
 func (p *Puller) List(ctx context.Context, repo name.Repository) ([]string, error) {
-	lister, err := p.Lister(ctx, repo)
+	return p.list(ctx, repo, p.o.pageSize, p.o.last)
+}
+
+func (p *Puller) list(ctx context.Context, repo name.Repository, pageSize int, last string) ([]string, error) {
+	lister, err := p.lister(ctx, repo, pageSize, last)
 	if err != nil {
 		return nil, err
 	}
@@ -148,15 +211,15 @@ func (p *Puller) List(ctx context.Context, repo name.Repository) ([]string, erro
 
 // Lister lists tags in a repo and returns a Lister for paginating through the results.
 func (p *Puller) Lister(ctx context.Context, repo name.Repository) (*Lister, error) {
-	return p.lister(ctx, repo, p.o.pageSize)
+	return p.lister(ctx, repo, p.o.pageSize, p.o.last)
 }
 
-func (p *Puller) lister(ctx context.Context, repo name.Repository, pageSize int) (*Lister, error) {
+func (p *Puller) lister(ctx context.Context, repo name.Repository, pageSize int, last string) (*Lister, error) {
 	f, err := p.fetcher(ctx, repo)
 	if err != nil {
 		return nil, err
 	}
-	page, err := f.listPage(ctx, repo, "", pageSize)
+	page, err := f.listPage(ctx, repo, "", pageSize, last)
 	if err != nil {
 		return nil, err
 	}
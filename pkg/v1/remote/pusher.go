@@ -126,6 +126,13 @@ func (p *Pusher) writer(ctx context.Context, repo name.Repository, o *options) (
 }
 
 func (p *Pusher) Put(ctx context.Context, ref name.Reference, t Taggable) error {
+	t, err := p.inline(t)
+	if err != nil {
+		return err
+	}
+	if err := checkPolicies(ctx, p.o.pushPolicies, ref, t); err != nil {
+		return err
+	}
 	w, err := p.writer(ctx, ref.Context(), p.o)
 	if err != nil {
 		return err
@@ -139,7 +146,21 @@ func (p *Pusher) Put(ctx context.Context, ref name.Reference, t Taggable) error
 	return w.commitManifest(ctx, ref, m)
 }
 
+// Push pushes t to ref, along with everything it refers to.
+//
+// Blobs and child manifests are checked for and uploaded once per
+// repository by digest, however many times they appear in t, e.g. layers
+// that several platform images of an index share, or an image that an
+// index lists twice. This holds across concurrent calls to Push on the
+// same Pusher, which wait for each other's uploads of the same digest.
 func (p *Pusher) Push(ctx context.Context, ref name.Reference, t Taggable) error {
+	t, err := p.inline(t)
+	if err != nil {
+		return err
+	}
+	if err := checkPolicies(ctx, p.o.pushPolicies, ref, t); err != nil {
+		return err
+	}
 	w, err := p.writer(ctx, ref.Context(), p.o)
 	if err != nil {
 		return err
@@ -147,6 +168,14 @@ func (p *Pusher) Push(ctx context.Context, ref name.Reference, t Taggable) error
 	return w.writeManifest(ctx, ref, t)
 }
 
+// inline applies WithInlineData to t, if it was set.
+func (p *Pusher) inline(t Taggable) (Taggable, error) {
+	if p.o.inline == 0 {
+		return t, nil
+	}
+	return inline(t, p.o.inline)
+}
+
 func (p *Pusher) Upload(ctx context.Context, repo name.Repository, l v1.Layer) error {
 	w, err := p.writer(ctx, repo, p.o)
 	if err != nil {
@@ -156,6 +185,20 @@ func (p *Pusher) Upload(ctx context.Context, repo name.Repository, l v1.Layer) e
 }
 
 func (p *Pusher) Delete(ctx context.Context, ref name.Reference) error {
+	return p.delete(ctx, ref, "manifests")
+
+	// TODO(jason): If the manifest had a `subject`, and if the registry
+	// doesn't support Referrers, update the index pointed to by the
+	// subject's fallback tag to remove the descriptor for this manifest.
+}
+
+// DeleteBlob removes the blob ref from the remote registry. Many registries
+// don't support this.
+func (p *Pusher) DeleteBlob(ctx context.Context, ref name.Digest) error {
+	return p.delete(ctx, ref, "blobs")
+}
+
+func (p *Pusher) delete(ctx context.Context, ref name.Reference, kind string) error {
 	w, err := p.writer(ctx, ref.Context(), p.o)
 	if err != nil {
 		return err
@@ -164,7 +207,7 @@ func (p *Pusher) Delete(ctx context.Context, ref name.Reference) error {
 	u := url.URL{
 		Scheme: ref.Context().Registry.Scheme(),
 		Host:   ref.Context().RegistryStr(),
-		Path:   fmt.Sprintf("/v2/%s/manifests/%s", ref.Context().RepositoryStr(), ref.Identifier()),
+		Path:   fmt.Sprintf("/v2/%s/%s/%s", ref.Context().RepositoryStr(), kind, ref.Identifier()),
 	}
 
 	req, err := http.NewRequest(http.MethodDelete, u.String(), nil)
@@ -179,10 +222,6 @@ func (p *Pusher) Delete(ctx context.Context, ref name.Reference) error {
 	defer resp.Body.Close()
 
 	return transport.CheckError(resp, http.StatusOK, http.StatusAccepted)
-
-	// TODO(jason): If the manifest had a `subject`, and if the registry
-	// doesn't support Referrers, update the index pointed to by the
-	// subject's fallback tag to remove the descriptor for this manifest.
 }
 
 type repoWriter struct {
@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/go-containerregistry/internal/redact"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// BlobReaderAt returns a reader for the blob ref that only fetches the parts
+// of it that are read, using range requests.
+//
+// Unlike Layer, the contents can't be verified against the blob's digest,
+// so callers must verify what they read some other way, e.g. against the
+// digests in an eStargz TOC.
+func BlobReaderAt(ref name.Digest, options ...Option) (*io.SectionReader, error) {
+	o, err := makeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	return newPuller(o).BlobReaderAt(o.context, ref)
+}
+
+// BlobReaderAt is like remote.BlobReaderAt, but avoids re-authenticating when possible.
+func (p *Puller) BlobReaderAt(ctx context.Context, ref name.Digest) (*io.SectionReader, error) {
+	f, err := p.fetcher(ctx, ref.Context())
+	if err != nil {
+		return nil, err
+	}
+	h, err := v1.NewHash(ref.Identifier())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.headBlob(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.ContentLength < 0 {
+		return nil, fmt.Errorf("HEAD %s: missing Content-Length", ref)
+	}
+	// We don't want to log binary layers -- this can break terminals.
+	ctx = redact.NewContext(ctx, "omitting binary blobs from logs")
+	return io.NewSectionReader(&blobReaderAt{ctx: ctx, f: f, digest: h}, 0, resp.ContentLength), nil
+}
+
+type blobReaderAt struct {
+	ctx    context.Context
+	f      *fetcher
+	digest v1.Hash
+}
+
+// ReadAt implements io.ReaderAt.
+func (r *blobReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	u := r.f.url("blobs", r.digest.String())
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+
+	resp, err := r.f.client.Do(req)
+	if err != nil {
+		return 0, redact.Error(err)
+	}
+	defer resp.Body.Close()
+
+	if err := transport.CheckError(resp, http.StatusOK, http.StatusPartialContent); err != nil {
+		return 0, err
+	}
+	// Registries that don't support ranges send the whole blob.
+	if resp.StatusCode == http.StatusOK {
+		if _, err := io.CopyN(io.Discard, resp.Body, off); err != nil {
+			return 0, err
+		}
+	}
+	n, err := io.ReadFull(resp.Body, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestBlobReaderAt(t *testing.T) {
+	layer, err := random.Layer(1024, types.DockerLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := layer.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		ranges bool
+	}{{"ranges", true}, {"no ranges", false}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			reg := registry.New()
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if rng := r.Header.Get("Range"); rng != "" {
+					got = append(got, rng)
+					if !tc.ranges {
+						r.Header.Del("Range")
+					}
+				}
+				reg.ServeHTTP(w, r)
+			}))
+			defer s.Close()
+			u, err := url.Parse(s.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, err := name.NewDigest(fmt.Sprintf("%s/some/path@%s", u.Host, digest))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := WriteLayer(ref.Context(), layer); err != nil {
+				t.Fatal(err)
+			}
+
+			sr, err := BlobReaderAt(ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sr.Size() != int64(len(want)) {
+				t.Errorf("Size() = %d, want %d", sr.Size(), len(want))
+			}
+			p := make([]byte, 10)
+			if _, err := sr.ReadAt(p, 100); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(p, want[100:110]) {
+				t.Errorf("ReadAt() = %x, want %x", p, want[100:110])
+			}
+			// Reads past the end are cut short.
+			n, err := sr.ReadAt(p, sr.Size()-4)
+			if n != 4 || err != io.EOF {
+				t.Errorf("ReadAt(end) = %d, %v; want 4, EOF", n, err)
+			}
+			if len(got) != 2 || got[0] != "bytes=100-109" {
+				t.Errorf("Range headers = %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/internal/redact"
+	"github.com/google/go-containerregistry/internal/verify"
+	"github.com/google/go-containerregistry/pkg/logs"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// maxResumes is how many times a blob download that fails partway through
+// is resumed before giving up.
+const maxResumes = 3
+
+// WithResumeDir keeps blobs in dir as they're downloaded, so that a download
+// that's interrupted resumes from where it stopped, with a range request,
+// rather than starting over. Downloads are resumed a few times while they're
+// read, and otherwise when the same blob is fetched again, e.g. by a later
+// pull.
+//
+// Blobs are removed from dir once they've been read in full and verified
+// against their digests, or if they fail verification. Concurrent pulls of
+// the same blob shouldn't share dir.
+func WithResumeDir(dir string) Option {
+	return func(o *options) error {
+		o.resumeDir = dir
+		return nil
+	}
+}
+
+func (f *fetcher) fetchResumableBlob(ctx context.Context, size int64, h v1.Hash) (io.ReadCloser, error) {
+	if err := os.MkdirAll(f.resumeDir, 0o755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(f.resumeDir, fmt.Sprintf("%s-%s.partial", h.Algorithm, h.Hex))
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	r := &resumeReader{
+		ctx:     ctx,
+		f:       f,
+		digest:  h,
+		file:    file,
+		written: fi.Size(),
+	}
+	if r.written != 0 {
+		logs.Progress.Printf("resuming %s from byte %d", h, r.written)
+	}
+	// Open the rest of the blob up front, so that errors like 404s surface
+	// here, as they do without WithResumeDir.
+	if err := r.open(); err != nil {
+		file.Close()
+		if r.written == 0 {
+			os.Remove(path)
+		}
+		return nil, err
+	}
+	if f.skipBlobVerification {
+		return &resumedBlob{ReadCloser: unverified(r, size), r: r, path: path}, nil
+	}
+	rc, err := verify.ReadCloser(r, size, h)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	return &resumedBlob{ReadCloser: rc, r: r, path: path}, nil
+}
+
+// resumeReader reads a blob from its partial copy on disk, and then from
+// the registry, starting where the partial copy ends and appending to it.
+type resumeReader struct {
+	ctx    context.Context
+	f      *fetcher
+	digest v1.Hash
+
+	file    *os.File
+	read    int64 // Bytes of the blob returned from Read.
+	written int64 // Bytes of the blob in file.
+
+	body    io.ReadCloser
+	resumes int
+	failed  bool
+	closed  bool
+}
+
+// open requests the blob from r.written onwards.
+func (r *resumeReader) open() error {
+	resp, err := r.get(r.written != 0)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		resp.Body.Close()
+		if resp.Header.Get("Content-Range") == fmt.Sprintf("bytes */%d", r.written) {
+			// The partial copy is already complete.
+			r.body = io.NopCloser(http.NoBody)
+			return nil
+		}
+		// Otherwise, the registry doesn't understand the range, so fetch the
+		// whole blob and skip what we have.
+		if resp, err = r.get(false); err != nil {
+			return err
+		}
+	}
+	if err := transport.CheckError(resp, http.StatusOK, http.StatusPartialContent); err != nil {
+		resp.Body.Close()
+		return err
+	}
+	// Registries that don't support ranges send the whole blob.
+	if resp.StatusCode == http.StatusOK && r.written != 0 {
+		if _, err := io.CopyN(io.Discard, resp.Body, r.written); err != nil {
+			resp.Body.Close()
+			return err
+		}
+	}
+	r.body = resp.Body
+	return nil
+}
+
+// get requests the blob, from r.written onwards if ranged is set.
+func (r *resumeReader) get(ranged bool) (*http.Response, error) {
+	u := r.f.url("blobs", r.digest.String())
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if ranged {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.written))
+	}
+	resp, err := r.f.client.Do(req)
+	if err != nil {
+		return nil, redact.Error(err)
+	}
+	return resp, nil
+}
+
+// Read implements io.Reader.
+func (r *resumeReader) Read(p []byte) (int, error) {
+	if r.read < r.written {
+		if int64(len(p)) > r.written-r.read {
+			p = p[:r.written-r.read]
+		}
+		n, err := r.file.ReadAt(p, r.read)
+		r.read += int64(n)
+		if err == io.EOF {
+			err = nil
+		}
+		return n, r.fail(err)
+	}
+
+	for {
+		if r.body == nil {
+			if err := r.open(); err != nil {
+				return 0, r.fail(err)
+			}
+		}
+		n, err := r.body.Read(p)
+		if n > 0 {
+			if _, werr := r.file.WriteAt(p[:n], r.written); werr != nil {
+				return 0, r.fail(werr)
+			}
+			r.written += int64(n)
+			r.read += int64(n)
+		}
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+
+		// The download was interrupted; pick it up where it stopped.
+		r.body.Close()
+		r.body = nil
+		if r.ctx.Err() != nil || r.resumes == maxResumes {
+			return n, r.fail(err)
+		}
+		r.resumes++
+		logs.Warn.Printf("resuming %s from byte %d: %v", r.digest, r.written, err)
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// fail records that the download failed, so the partial copy is kept.
+func (r *resumeReader) fail(err error) error {
+	if err != nil {
+		r.failed = true
+	}
+	return err
+}
+
+// Close implements io.Closer.
+func (r *resumeReader) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.body != nil {
+		r.body.Close()
+	}
+	return r.file.Close()
+}
+
+// resumedBlob removes the partial copy of a blob once it's been verified, or
+// has failed verification, and keeps it if the download failed.
+type resumedBlob struct {
+	io.ReadCloser
+	r    *resumeReader
+	path string
+}
+
+// Read implements io.Reader.
+func (b *resumedBlob) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && !b.r.failed {
+		b.r.Close()
+		os.Remove(b.path)
+	}
+	return n, err
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+func TestResumeDir(t *testing.T) {
+	layer, err := random.Layer(1024, types.DockerLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := layer.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Serve the blob, cutting the next cuts responses off halfway.
+	var (
+		cuts   int
+		ranges []string
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "/blobs/") {
+			return
+		}
+		b := want
+		if rng := r.Header.Get("Range"); rng != "" {
+			ranges = append(ranges, rng)
+			off, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+			if err != nil {
+				t.Errorf("Range: %q", rng)
+			}
+			b = b[off:]
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", off, len(want)-1, len(want)))
+			w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+		}
+		if cuts > 0 {
+			cuts--
+			b = b[:len(b)/2]
+		}
+		w.Write(b)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.NewDigest(fmt.Sprintf("%s/some/path@%s", u.Host, digest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	partial := filepath.Join(dir, fmt.Sprintf("%s-%s.partial", digest.Algorithm, digest.Hex))
+
+	fetch := func() ([]byte, error) {
+		l, err := Layer(ref, WithResumeDir(dir))
+		if err != nil {
+			return nil, err
+		}
+		rc, err := l.Compressed()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+
+	// Interrupted downloads are resumed as they're read.
+	cuts = 2
+	got, err := fetch()
+	if err != nil {
+		t.Fatalf("fetch(): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("fetch() returned the wrong blob")
+	}
+	if len(ranges) != 2 {
+		t.Errorf("Range headers = %v, want 2", ranges)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("partial blob was not removed: %v", err)
+	}
+
+	// ...and by the next fetch, when they can't be resumed in time.
+	cuts, ranges = maxResumes+1, nil
+	if _, err := fetch(); err == nil {
+		t.Fatal("fetch(): want error, got nil")
+	}
+	fi, err := os.Stat(partial)
+	if err != nil {
+		t.Fatalf("partial blob was not kept: %v", err)
+	}
+	got, err = fetch()
+	if err != nil {
+		t.Fatalf("fetch(): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("fetch() returned the wrong blob")
+	}
+	if wantRange := fmt.Sprintf("bytes=%d-", fi.Size()); ranges[len(ranges)-1] != wantRange {
+		t.Errorf("Range = %q, want %q", ranges[len(ranges)-1], wantRange)
+	}
+
+	// Partial blobs that turn out to be corrupt are removed.
+	if err := os.WriteFile(partial, bytes.Repeat([]byte{0}, 10), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fetch(); err == nil || !strings.Contains(err.Error(), "error verifying") {
+		t.Errorf("fetch() of a corrupt partial blob: want a verification error, got %v", err)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("corrupt partial blob was not removed: %v", err)
+	}
+}
+
+func TestResumeDirImage(t *testing.T) {
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cut the first response for each layer off halfway.
+	cut := map[string]bool{}
+	var ranges []string
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.Contains(r.URL.Path, "/blobs/") {
+			reg.ServeHTTP(w, r)
+			return
+		}
+		if rng := r.Header.Get("Range"); rng != "" {
+			ranges = append(ranges, rng)
+		}
+		rec := httptest.NewRecorder()
+		reg.ServeHTTP(rec, r)
+		b := rec.Body.Bytes()
+		maps.Copy(w.Header(), rec.Header())
+		w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+		w.WriteHeader(rec.Code)
+		if !cut[r.URL.Path] {
+			cut[r.URL.Path] = true
+			b = b[:len(b)/2]
+		}
+		w.Write(b)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(fmt.Sprintf("%s/some/path:tag", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	got, err := Image(ref, WithResumeDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Image(got); err != nil {
+		t.Errorf("validate.Image(): %v", err)
+	}
+	if len(ranges) == 0 {
+		t.Error("no downloads were resumed")
+	}
+	if entries, err := os.ReadDir(dir); err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 0 {
+		t.Errorf("partial blobs were left behind: %v", entries)
+	}
+}
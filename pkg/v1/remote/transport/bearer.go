@@ -25,11 +25,13 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	authchallenge "github.com/docker/distribution/registry/client/auth/challenge"
 
 	"github.com/google/go-containerregistry/internal/redact"
 	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/authn/cache"
 	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
 )
@@ -74,6 +76,7 @@ func FromToken(reg name.Registry, auth authn.Authenticator, t http.RoundTripper,
 	if tok.Token != "" {
 		bt.bearer.RegistryToken = tok.Token
 	}
+	bt.setRefreshToken(tok)
 	return &Wrapper{bt}, nil
 }
 
@@ -116,8 +119,20 @@ type bearerTransport struct {
 	scopes  []string
 	// Scheme we should use, determined by ping response.
 	scheme string
+	// When the current bearer token expires, if the token service told us.
+	expiry time.Time
+	// When we should stop using the current bearer token and refresh it.
+	refreshAt time.Time
+	// Shares tokens with other transports, if set.
+	cache cache.Cache
+	// Fingerprint of the credentials we were created with, for cache keys.
+	identity string
 }
 
+// defaultTokenLifetime is how long we cache tokens for when the token service
+// doesn't tell us, per https://distribution.github.io/distribution/spec/auth/token/
+const defaultTokenLifetime = 60 * time.Second
+
 var _ http.RoundTripper = (*bearerTransport)(nil)
 
 var portMap = map[string]string{
@@ -151,6 +166,15 @@ func (bt *bearerTransport) RoundTrip(in *http.Request) (*http.Response, error) {
 		return bt.inner.RoundTrip(in)
 	}
 
+	// If the token service told us when our token expires, refresh it before
+	// sending a request that we know will be rejected. This matters for long
+	// pushes where a token can expire between blob uploads.
+	if bt.expired() {
+		if err := bt.refresh(in.Context()); err != nil {
+			return nil, err
+		}
+	}
+
 	res, err := sendRequest()
 	if err != nil {
 		return nil, err
@@ -187,6 +211,15 @@ func (bt *bearerTransport) RoundTrip(in *http.Request) (*http.Response, error) {
 		if err = bt.refresh(in.Context()); err != nil {
 			return nil, err
 		}
+
+		// The first attempt consumed the request body, so rewind it if we can.
+		if in.Body != nil && in.Body != http.NoBody && in.GetBody != nil {
+			body, err := in.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			in.Body = body
+		}
 		return sendRequest()
 	}
 
@@ -210,6 +243,11 @@ func (bt *bearerTransport) refresh(ctx context.Context) error {
 		return nil
 	}
 
+	key, ok := bt.fromCache(auth)
+	if ok {
+		return nil
+	}
+
 	response, err := bt.Refresh(ctx, auth)
 	if err != nil {
 		return err
@@ -220,6 +258,19 @@ func (bt *bearerTransport) refresh(ctx context.Context) error {
 		response.Token = response.AccessToken
 	}
 
+	if bt.cache != nil && response.Token != "" {
+		lifetime := defaultTokenLifetime
+		if response.ExpiresIn > 0 {
+			lifetime = time.Duration(response.ExpiresIn) * time.Second
+		}
+		bt.cache.Set(key, &cache.Token{
+			Token:        response.Token,
+			RefreshToken: response.RefreshToken,
+			Expiry:       time.Now().Add(lifetime),
+			Lifetime:     lifetime,
+		})
+	}
+
 	// Find a token to turn into a Bearer authenticator
 	if response.Token != "" {
 		bt.mx.Lock()
@@ -227,14 +278,75 @@ func (bt *bearerTransport) refresh(ctx context.Context) error {
 		bt.mx.Unlock()
 	}
 
-	// If we obtained a refresh token from the oauth flow, use that for refresh() now.
-	if response.RefreshToken != "" {
+	bt.setRefreshToken(response)
+
+	return nil
+}
+
+// fromCache uses a token from the shared cache, if there is one, and returns
+// the key under which the token for our current scopes should be cached.
+func (bt *bearerTransport) fromCache(auth *authn.AuthConfig) (cache.Key, bool) {
+	if bt.cache == nil {
+		return cache.Key{}, false
+	}
+
+	bt.mx.Lock()
+	defer bt.mx.Unlock()
+
+	// Once we start using a refresh token our credentials change, but
+	// we want to keep sharing tokens with transports that started out with
+	// the same credentials as us.
+	if bt.identity == "" {
+		bt.identity = cache.Identity(auth)
+	}
+	key := cache.NewKey(bt.registry.RegistryStr(), bt.service, bt.scopes, bt.identity)
+
+	tok, ok := bt.cache.Get(key)
+	// If the cached token is the one we already have, it was rejected or
+	// expired, so we need a new one.
+	if !ok || tok.Token == bt.bearer.RegistryToken {
+		return key, false
+	}
+
+	bt.bearer.RegistryToken = tok.Token
+	bt.expiry = tok.Expiry
+	bt.refreshAt = cache.RefreshTime(tok.Expiry, tok.Lifetime)
+	if tok.RefreshToken != "" {
 		bt.basic = authn.FromConfig(authn.AuthConfig{
-			IdentityToken: response.RefreshToken,
+			IdentityToken: tok.RefreshToken,
 		})
 	}
+	return key, true
+}
 
-	return nil
+// setRefreshToken records the lifetime of tok and, if we obtained a refresh
+// token from the oauth flow, uses that for refresh() from now on.
+func (bt *bearerTransport) setRefreshToken(tok *Token) {
+	bt.mx.Lock()
+	defer bt.mx.Unlock()
+
+	if tok.ExpiresIn > 0 {
+		lifetime := time.Duration(tok.ExpiresIn) * time.Second
+		bt.expiry = time.Now().Add(lifetime)
+		bt.refreshAt = cache.RefreshTime(bt.expiry, lifetime)
+	} else {
+		bt.expiry = time.Time{}
+		bt.refreshAt = time.Time{}
+	}
+
+	if tok.RefreshToken != "" {
+		bt.basic = authn.FromConfig(authn.AuthConfig{
+			IdentityToken: tok.RefreshToken,
+		})
+	}
+}
+
+// expired returns true if the token service told us how long our bearer
+// token is valid for and that time has (nearly) passed.
+func (bt *bearerTransport) expired() bool {
+	bt.mx.RLock()
+	defer bt.mx.RUnlock()
+	return !bt.refreshAt.IsZero() && !time.Now().Before(bt.refreshAt)
 }
 
 func (bt *bearerTransport) Refresh(ctx context.Context, auth *authn.AuthConfig) (*Token, error) {
@@ -17,13 +17,16 @@ package transport
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/authn/cache"
 	"github.com/google/go-containerregistry/pkg/name"
 )
 
@@ -559,3 +562,146 @@ func TestInsufficientScope(t *testing.T) {
 		t.Error("didn't refresh insufficient scope")
 	}
 }
+
+func TestBearerTransportExpiredRefreshToken(t *testing.T) {
+	initialToken := "foo"
+	refreshToken := "baz"
+	accessTokens := []string{"first", "second"}
+	exchanges := 0
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost && r.URL.Path == "/token" {
+				if err := r.ParseForm(); err != nil {
+					t.Fatal(err)
+				}
+				want := initialToken
+				if exchanges > 0 {
+					want = refreshToken
+				}
+				if got := r.FormValue("refresh_token"); got != want {
+					t.Errorf("refresh_token: want %s got %s", want, got)
+				}
+				// Expire quickly so that the next request has to refresh.
+				w.Write([]byte(fmt.Sprintf(`{"access_token": %q, "refresh_token": %q, "expires_in": 1}`, accessTokens[exchanges], refreshToken)))
+				exchanges++
+				return
+			}
+
+			hdr := r.Header.Get("Authorization")
+			if exchanges == 0 || hdr != "Bearer "+accessTokens[exchanges-1] {
+				w.Header().Set("WWW-Authenticate", "scope=foo")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			b, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := string(b), "hello"; got != want {
+				t.Errorf("body: got %q, want %q", got, want)
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := name.NewRegistry(u.Host, name.WeakValidation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport := &bearerTransport{
+		inner:    http.DefaultTransport,
+		basic:    authn.FromConfig(authn.AuthConfig{IdentityToken: initialToken}),
+		registry: registry,
+		realm:    server.URL + "/token",
+		scheme:   "http",
+		scopes:   []string{"myscope"},
+		service:  u.Host,
+	}
+	client := http.Client{Transport: transport}
+
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			time.Sleep(time.Second)
+		}
+		res, err := client.Post(fmt.Sprintf("http://%s/v2/foo/bar/blobs/uploads/", u.Host), "text/plain", strings.NewReader("hello"))
+		if err != nil {
+			t.Fatalf("Unexpected error during client.Post: %v", err)
+		}
+		if res.StatusCode != http.StatusAccepted {
+			t.Errorf("client.Post final StatusCode got %v, want: %v", res.StatusCode, http.StatusAccepted)
+		}
+	}
+	if got, want := exchanges, 2; got != want {
+		t.Errorf("token exchanges: got %d, want %d", got, want)
+	}
+	if got, want := transport.bearer.RegistryToken, accessTokens[1]; got != want {
+		t.Errorf("Expected Bearer token to be refreshed, got %v, want %v", got, want)
+	}
+}
+
+func TestBearerTransportTokenCache(t *testing.T) {
+	exchanges := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/token" {
+				exchanges++
+				w.Write([]byte(fmt.Sprintf(`{"token": "token-%d", "expires_in": 300}`, exchanges)))
+				return
+			}
+			if r.URL.Path == "/v2/" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="test"`, r.Host))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer token-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := name.NewRegistry(u.Host, name.Insecure)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tc := cache.New()
+	scopes := []string{"repository:foo/bar:pull"}
+	basic := &authn.Basic{Username: "foo", Password: "bar"}
+	for i := 0; i < 3; i++ {
+		tr, err := NewWithContext(context.Background(), registry, basic, http.DefaultTransport, scopes, WithTokenCache(tc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := http.Client{Transport: tr}
+		res, err := client.Get(fmt.Sprintf("http://%s/v2/foo/bar/manifests/latest", u.Host))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("StatusCode = %d, want %d", res.StatusCode, http.StatusOK)
+		}
+	}
+	if exchanges != 1 {
+		t.Errorf("got %d token exchanges, want 1", exchanges)
+	}
+
+	// Different credentials must not share tokens.
+	other := &authn.Basic{Username: "foo", Password: "baz"}
+	if _, err := NewWithContext(context.Background(), registry, other, http.DefaultTransport, scopes, WithTokenCache(tc)); err != nil {
+		t.Fatal(err)
+	}
+	if exchanges != 2 {
+		t.Errorf("got %d token exchanges, want 2", exchanges)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/google/go-containerregistry/internal/retry"
+	"github.com/google/go-containerregistry/pkg/authn/cache"
 )
 
 // Sleep for 0.1 then 0.3 seconds. This should cover networking blips.
@@ -39,13 +40,15 @@ type retryTransport struct {
 	codes     []int
 }
 
-// Option is a functional option for retryTransport.
+// Option is a functional option for retryTransport and NewWithContext.
 type Option func(*options)
 
 type options struct {
 	backoff   retry.Backoff
 	predicate retry.Predicate
 	codes     []int
+
+	tokenCache cache.Cache
 }
 
 // Backoff is an alias of retry.Backoff to expose this configuration option to consumers of this lib
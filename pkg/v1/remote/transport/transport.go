@@ -20,9 +20,19 @@ import (
 	"strings"
 
 	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/authn/cache"
 	"github.com/google/go-containerregistry/pkg/name"
 )
 
+// WithTokenCache shares bearer tokens between transports through c, so that
+// transports for the same registry, scopes and credentials reuse each other's
+// tokens instead of each performing a token exchange.
+func WithTokenCache(c cache.Cache) Option {
+	return func(o *options) {
+		o.tokenCache = c
+	}
+}
+
 // New returns a new RoundTripper based on the provided RoundTripper that has been
 // setup to authenticate with the remote registry "reg", in the capacity
 // laid out by the specified scopes.
@@ -38,7 +48,7 @@ func New(reg name.Registry, auth authn.Authenticator, t http.RoundTripper, scope
 // In case the RoundTripper is already of the type Wrapper it assumes
 // authentication was already done prior to this call, so it just returns
 // the provided RoundTripper without further action
-func NewWithContext(ctx context.Context, reg name.Registry, auth authn.Authenticator, t http.RoundTripper, scopes []string) (http.RoundTripper, error) {
+func NewWithContext(ctx context.Context, reg name.Registry, auth authn.Authenticator, t http.RoundTripper, scopes []string, opts ...Option) (http.RoundTripper, error) {
 	// When the transport provided is of the type Wrapper this function assumes that the caller already
 	// executed the necessary login and check.
 	switch t.(type) {
@@ -91,6 +101,12 @@ func NewWithContext(ctx context.Context, reg name.Registry, auth authn.Authentic
 	}
 	bt.scopes = scopes
 
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	bt.cache = o.tokenCache
+
 	if err := bt.refresh(ctx); err != nil {
 		return nil, err
 	}
@@ -30,6 +30,7 @@ import (
 	"github.com/google/go-containerregistry/internal/redact"
 	"github.com/google/go-containerregistry/internal/retry"
 	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/authn/cache"
 	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -50,9 +51,10 @@ func Write(ref name.Reference, img v1.Image, options ...Option) (rerr error) {
 
 // writer writes the elements of an image to a remote image reference.
 type writer struct {
-	repo      name.Repository
-	auth      authn.Authenticator
-	transport http.RoundTripper
+	repo       name.Repository
+	auth       authn.Authenticator
+	transport  http.RoundTripper
+	tokenCache cache.Cache
 
 	client *http.Client
 
@@ -76,7 +78,7 @@ func makeWriter(ctx context.Context, repo name.Repository, ls []v1.Layer, o *opt
 		auth = kauth
 	}
 	scopes := scopesForUploadingImage(repo, ls)
-	tr, err := transport.NewWithContext(ctx, repo.Registry, auth, o.transport, scopes)
+	tr, err := transport.NewWithContext(ctx, repo.Registry, auth, o.transport, scopes, transport.WithTokenCache(o.tokenCache))
 	if err != nil {
 		return nil, err
 	}
@@ -86,15 +88,16 @@ func makeWriter(ctx context.Context, repo name.Repository, ls []v1.Layer, o *opt
 		scopeSet[scope] = struct{}{}
 	}
 	return &writer{
-		repo:      repo,
-		client:    &http.Client{Transport: tr},
-		auth:      auth,
-		transport: o.transport,
-		progress:  o.progress,
-		backoff:   o.retryBackoff,
-		predicate: o.retryPredicate,
-		scopes:    scopes,
-		scopeSet:  scopeSet,
+		repo:       repo,
+		client:     &http.Client{Transport: tr},
+		auth:       auth,
+		transport:  o.transport,
+		tokenCache: o.tokenCache,
+		progress:   o.progress,
+		backoff:    o.retryBackoff,
+		predicate:  o.retryPredicate,
+		scopes:     scopes,
+		scopeSet:   scopeSet,
 	}, nil
 }
 
@@ -125,7 +128,7 @@ func (w *writer) maybeUpdateScopes(ctx context.Context, ml *MountableLayer) erro
 		w.scopes = append(w.scopes, scope)
 
 		logs.Debug.Printf("Refreshing token to add scope %q", scope)
-		wt, err := transport.NewWithContext(ctx, w.repo.Registry, w.auth, w.transport, w.scopes)
+		wt, err := transport.NewWithContext(ctx, w.repo.Registry, w.auth, w.transport, w.scopes, transport.WithTokenCache(w.tokenCache))
 		if err != nil {
 			return err
 		}
@@ -251,6 +254,16 @@ func (w *writer) streamBlob(ctx context.Context, layer v1.Layer, streamLocation
 	}
 
 	getBody := layer.Compressed
+	if ra, size, ok := readerAt(layer, blob); ok {
+		// Blobs that support random access (like files in an OCI layout) are
+		// re-sent from the open file on retries and redirects, rather than
+		// being opened and read again.
+		defer blob.Close()
+		blob = io.NopCloser(io.NewSectionReader(ra, 0, size))
+		getBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(io.NewSectionReader(ra, 0, size)), nil
+		}
+	}
 	if w.progress != nil {
 		var count int64
 		blob = &progressReader{rc: blob, progress: w.progress, count: &count}
@@ -296,6 +309,23 @@ func (w *writer) streamBlob(ctx context.Context, layer v1.Layer, streamLocation
 	return w.nextLocation(resp)
 }
 
+// readerAt returns blob as an io.ReaderAt along with the layer's size, if
+// the blob supports random access and the size is known up front.
+func readerAt(layer v1.Layer, blob io.ReadCloser) (io.ReaderAt, int64, bool) {
+	if _, ok := layer.(*stream.Layer); ok {
+		return nil, 0, false
+	}
+	ra, ok := blob.(io.ReaderAt)
+	if !ok {
+		return nil, 0, false
+	}
+	size, err := layer.Size()
+	if err != nil {
+		return nil, 0, false
+	}
+	return ra, size, true
+}
+
 // commitBlob commits this blob by sending a PUT to the location returned from
 // streaming the blob.
 func (w *writer) commitBlob(ctx context.Context, location, digest string) error {
@@ -555,9 +585,10 @@ func (w *writer) commitManifest(ctx context.Context, t Taggable, ref name.Refere
 		return err
 	}
 	var mf struct {
-		MediaType types.MediaType `json:"mediaType"`
-		Subject   *v1.Descriptor  `json:"subject,omitempty"`
-		Config    struct {
+		MediaType    types.MediaType `json:"mediaType"`
+		ArtifactType string          `json:"artifactType,omitempty"`
+		Subject      *v1.Descriptor  `json:"subject,omitempty"`
+		Config       struct {
 			MediaType types.MediaType `json:"mediaType"`
 		} `json:"config"`
 	}
@@ -598,8 +629,14 @@ func (w *writer) commitManifest(ctx context.Context, t Taggable, ref name.Refere
 			if err != nil {
 				return err
 			}
+			// Unlike ArtifactTypeOf, the referrers API uses the config's
+			// mediaType even for images.
+			artifactType := mf.ArtifactType
+			if artifactType == "" {
+				artifactType = string(mf.Config.MediaType)
+			}
 			desc := v1.Descriptor{
-				ArtifactType: string(mf.Config.MediaType),
+				ArtifactType: artifactType,
 				MediaType:    mf.MediaType,
 				Digest:       h,
 				Size:         size,
@@ -648,6 +685,8 @@ func scopesForUploadingImage(repo name.Repository, layers []v1.Layer) []string {
 // WriteIndex pushes the provided ImageIndex to the specified image reference.
 // WriteIndex will attempt to push all of the referenced manifests before
 // attempting to push the ImageIndex, to retain referential integrity.
+// Manifests and blobs that appear more than once in the index are only
+// pushed once; see Pusher.Push.
 func WriteIndex(ref name.Reference, ii v1.ImageIndex, options ...Option) (rerr error) {
 	return Push(ref, ii, options...)
 }
@@ -25,8 +25,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 
@@ -44,6 +48,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/google/go-containerregistry/pkg/v1/validate"
+	"golang.org/x/sync/errgroup"
 )
 
 func mustNewTag(t *testing.T, s string) name.Tag {
@@ -613,6 +618,96 @@ func TestDedupeLayers(t *testing.T) {
 	}
 }
 
+func TestDedupeIndex(t *testing.T) {
+	// Three platform images on the same base, one of them twice, and a
+	// nested index that repeats another.
+	base, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var imgs []v1.Image
+	for range 3 {
+		l, err := random.Layer(1024, types.OCILayer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := mutate.AppendLayers(base, l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		imgs = append(imgs, img)
+	}
+	platform := func(arch string) v1.Descriptor {
+		return v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: arch}}
+	}
+	nested := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: imgs[1], Descriptor: platform("arm64")})
+	idx := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: imgs[0], Descriptor: platform("amd64")},
+		mutate.IndexAddendum{Add: imgs[0], Descriptor: platform("386")},
+		mutate.IndexAddendum{Add: imgs[1], Descriptor: platform("arm64")},
+		mutate.IndexAddendum{Add: imgs[2], Descriptor: platform("s390x")},
+		mutate.IndexAddendum{Add: nested},
+	)
+
+	// Count the requests for each digest.
+	var mu sync.Mutex
+	heads, puts := map[string]int{}, map[string]int{}
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		switch {
+		case r.Method == http.MethodHead && strings.Contains(r.URL.Path, "/blobs/"):
+			heads[path.Base(r.URL.Path)]++
+		case r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/blobs/uploads/"):
+			puts[r.URL.Query().Get("digest")]++
+		case r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/manifests/sha256:"):
+			puts[path.Base(r.URL.Path)]++
+		}
+		mu.Unlock()
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Push it to two tags at once, with the same Pusher.
+	p, err := NewPusher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g errgroup.Group
+	for _, tag := range []string{"a", "b"} {
+		ref, err := name.NewTag(fmt.Sprintf("%s/dedupe/index:%s", u.Host, tag))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Go(func() error {
+			return p.Push(context.Background(), ref, idx)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	for digest, n := range heads {
+		if n > 1 {
+			t.Errorf("blob %s checked for %d times, want once", digest, n)
+		}
+	}
+	for digest, n := range puts {
+		if n > 1 {
+			t.Errorf("%s uploaded %d times, want once", digest, n)
+		}
+	}
+	// 2 shared layers, a layer and a config for each image, and the three
+	// images and the nested index by digest.
+	if got, want := len(puts), 2+2*3+3+1; got != want {
+		t.Errorf("uploaded %d blobs and manifests, want %d", got, want)
+	}
+}
+
 func TestStreamBlob(t *testing.T) {
 	img := setupImage(t)
 	expectedPath := "/vWhatever/I/decide"
@@ -740,6 +835,72 @@ func TestCommitBlob(t *testing.T) {
 	}
 }
 
+// fileLayer serves its compressed contents from a file, like a layout does.
+type fileLayer struct {
+	v1.Layer
+	path  string
+	opens int
+}
+
+func (l *fileLayer) Compressed() (io.ReadCloser, error) {
+	l.opens++
+	return os.Open(l.path)
+}
+
+func TestStreamBlobReaderAt(t *testing.T) {
+	rl, err := random.Layer(1024, types.DockerLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := rl.Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "blob")
+	if err := os.WriteFile(p, want, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l := &fileLayer{Layer: rl, path: p}
+
+	expectedCommitLocation := "https://commit.io/v12/blob"
+	w, closer, err := setupWriter("what/ever", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("ReadAll(Body) = %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: body differs from blob", r.URL.Path)
+		}
+		if r.URL.Path == "/upload" {
+			// Make the client send the body again.
+			http.Redirect(w, r, "/redirected", http.StatusTemporaryRedirect)
+			return
+		}
+		w.Header().Set("Location", expectedCommitLocation)
+		http.Error(w, "Created", http.StatusCreated)
+	}))
+	if err != nil {
+		t.Fatalf("setupWriter() = %v", err)
+	}
+	defer closer.Close()
+
+	streamLocation := w.url("/upload")
+	commitLocation, err := w.streamBlob(context.Background(), l, streamLocation.String())
+	if err != nil {
+		t.Fatalf("streamBlob() = %v", err)
+	}
+	if commitLocation != expectedCommitLocation {
+		t.Errorf("streamBlob(); got %v, want %v", commitLocation, expectedCommitLocation)
+	}
+	if l.opens != 1 {
+		t.Errorf("Compressed() called %d times, want 1", l.opens)
+	}
+}
+
 func TestUploadOne(t *testing.T) {
 	img := setupImage(t)
 	h := mustConfigName(t, img)
@@ -1589,6 +1750,119 @@ func TestNestedIndex(t *testing.T) {
 	}
 }
 
+func TestWriteInlineData(t *testing.T) {
+	var blobGets, manifestGets int
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if strings.Contains(r.URL.Path, "/blobs/") {
+				blobGets++
+			} else if strings.Contains(r.URL.Path, "/manifests/") {
+				manifestGets++
+			}
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.NewTag(fmt.Sprintf("%s/test/inline:latest", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := random.Index(1024, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteIndex(ref, idx, WithInlineData(1<<20)); err != nil {
+		t.Fatal(err)
+	}
+
+	pulled, err := Index(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := pulled.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestGets = 0
+	for _, desc := range m.Manifests {
+		if desc.Data == nil {
+			t.Errorf("manifest %s wasn't inlined", desc.Digest)
+		}
+		img, err := pulled.Image(desc.Digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := img.RawManifest(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if manifestGets != 0 {
+		t.Errorf("fetched %d inlined manifests", manifestGets)
+	}
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(ref, img, WithInlineData(1<<20)); err != nil {
+		t.Fatal(err)
+	}
+	pulledImg, err := Image(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobGets = 0
+	if _, err := pulledImg.ConfigFile(); err != nil {
+		t.Fatal(err)
+	}
+	if blobGets != 0 {
+		t.Errorf("fetched the inlined config %d times", blobGets)
+	}
+
+	// MultiWrite and Put share the Pusher's handling of WithInlineData.
+	mref := ref.Context().Tag("multi")
+	if err := MultiWrite(map[name.Reference]Taggable{mref: idx}, WithInlineData(1<<20)); err != nil {
+		t.Fatal(err)
+	}
+	pref := ref.Context().Tag("put")
+	if err := Put(pref, img, WithInlineData(1<<20)); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []name.Reference{mref, pref} {
+		desc, err := Get(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data []byte
+		if desc.MediaType.IsIndex() {
+			im, err := v1.ParseIndexManifest(bytes.NewReader(desc.Manifest))
+			if err != nil {
+				t.Fatal(err)
+			}
+			data = im.Manifests[0].Data
+		} else {
+			m, err := v1.ParseManifest(bytes.NewReader(desc.Manifest))
+			if err != nil {
+				t.Fatal(err)
+			}
+			data = m.Config.Data
+		}
+		if data == nil {
+			t.Errorf("%s: nothing was inlined", r)
+		}
+	}
+
+	if _, err := makeOptions(WithInlineData(0)); err == nil {
+		t.Error("WithInlineData(0): expected error")
+	}
+}
+
 func BenchmarkWrite(b *testing.B) {
 	// unfortunately the registry _and_ the img have caching behaviour, so we need a new registry
 	// and image every iteration of benchmarking.
@@ -0,0 +1,26 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signature signs images and indexes, and verifies their signatures.
+//
+// A signature is an Envelope, in the style of DSSE, whose payload identifies
+// the signed manifest by digest. Envelopes are stored in the registry as OCI
+// artifacts that refer to the signed manifest through their subject, so they
+// can be found with the referrers API.
+//
+// Signing and verification are pluggable through the Signer and Verifier
+// interfaces. ECDSA and ed25519 keys are supported out of the box; other
+// implementations, e.g. backed by a KMS, only need to implement the
+// interfaces.
+package signature
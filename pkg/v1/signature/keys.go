@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// NewSigner returns a Signer for an *ecdsa.PrivateKey or an
+// ed25519.PrivateKey.
+//
+// ECDSA signatures are over the SHA-256 digest of the message.
+func NewSigner(key crypto.Signer) (Signer, error) {
+	id, err := keyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case *ecdsa.PrivateKey, ed25519.PrivateKey:
+		return &keySigner{key: key, id: id}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// NewVerifier returns a Verifier for an *ecdsa.PublicKey or an
+// ed25519.PublicKey.
+func NewVerifier(key crypto.PublicKey) (Verifier, error) {
+	id, err := keyID(key)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+		return &keyVerifier{key: key, id: id}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// LoadSigner returns a Signer for the PEM-encoded PKCS #8 or SEC 1 private
+// key in b.
+func LoadSigner(b []byte) (Signer, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	var key any
+	var err error
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	return NewSigner(s)
+}
+
+// LoadVerifier returns a Verifier for the PEM-encoded PKIX public key in b.
+func LoadVerifier(b []byte) (Verifier, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return NewVerifier(key)
+}
+
+// keyID is the hex SHA-256 of the PKIX encoding of key.
+func keyID(key crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+type keySigner struct {
+	key crypto.Signer
+	id  string
+}
+
+func (s *keySigner) KeyID() string { return s.id }
+
+func (s *keySigner) Sign(_ context.Context, msg []byte) ([]byte, error) {
+	if _, ok := s.key.(ed25519.PrivateKey); ok {
+		return s.key.Sign(rand.Reader, msg, crypto.Hash(0))
+	}
+	sum := sha256.Sum256(msg)
+	return s.key.Sign(rand.Reader, sum[:], crypto.SHA256)
+}
+
+type keyVerifier struct {
+	key crypto.PublicKey
+	id  string
+}
+
+func (v *keyVerifier) KeyID() string { return v.id }
+
+func (v *keyVerifier) Verify(_ context.Context, msg, sig []byte) error {
+	switch key := v.key.(type) {
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, msg, sig) {
+			return errors.New("invalid ed25519 signature")
+		}
+	case *ecdsa.PublicKey:
+		sum := sha256.Sum256(msg)
+		if !ecdsa.VerifyASN1(key, sum[:], sig) {
+			return errors.New("invalid ecdsa signature")
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// emptyJSON is the config blob of an artifact without a config.
+var emptyJSON = []byte("{}")
+
+// Artifact returns the OCI artifact that stores e as a referrer of subject.
+func Artifact(e *Envelope, subject v1.Descriptor) (v1.Image, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	layer := static.NewLayer(b, EnvelopeMediaType)
+	ld, err := partial.Descriptor(layer)
+	if err != nil {
+		return nil, err
+	}
+	cd, _, err := v1.SHA256(bytes.NewReader(emptyJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	m := v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		ArtifactType:  ArtifactType,
+		Config: v1.Descriptor{
+			MediaType: types.OCIEmptyJSON,
+			Size:      int64(len(emptyJSON)),
+			Digest:    cd,
+		},
+		Layers: []v1.Descriptor{*ld},
+		Subject: &v1.Descriptor{
+			MediaType: subject.MediaType,
+			Size:      subject.Size,
+			Digest:    subject.Digest,
+		},
+	}
+	rm, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return partial.CompressedToImage(&artifact{manifest: rm, layer: layer})
+}
+
+// artifact is the partial.CompressedImageCore of an Artifact.
+type artifact struct {
+	manifest []byte
+	layer    v1.Layer
+}
+
+func (a *artifact) RawConfigFile() ([]byte, error)      { return emptyJSON, nil }
+func (a *artifact) RawManifest() ([]byte, error)        { return a.manifest, nil }
+func (a *artifact) ArtifactType() (string, error)       { return ArtifactType, nil }
+func (a *artifact) MediaType() (types.MediaType, error) { return types.OCIManifestSchema1, nil }
+
+func (a *artifact) LayerByDigest(h v1.Hash) (partial.CompressedLayer, error) {
+	if d, err := a.layer.Digest(); err != nil {
+		return nil, err
+	} else if d == h {
+		return a.layer, nil
+	}
+	if cd, _, err := v1.SHA256(bytes.NewReader(emptyJSON)); err != nil {
+		return nil, err
+	} else if cd == h {
+		return static.NewLayer(emptyJSON, types.OCIEmptyJSON), nil
+	}
+	return nil, fmt.Errorf("blob %s not found", h)
+}
+
+// Attach signs the manifest ref with each of the signers and pushes the
+// signature to ref's repository as a referrer of it.
+func Attach(ctx context.Context, ref name.Digest, annotations map[string]string, signers []Signer, options ...remote.Option) (*Envelope, error) {
+	options = append(slices.Clip(options), remote.WithContext(ctx))
+	desc, err := remote.Head(ref, options...)
+	if err != nil {
+		return nil, err
+	}
+	e, err := Sign(ctx, *desc, annotations, signers...)
+	if err != nil {
+		return nil, err
+	}
+	img, err := Artifact(e, *desc)
+	if err != nil {
+		return nil, err
+	}
+	d, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+	if err := remote.Write(ref.Context().Digest(d.String()), img, options...); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Verify looks up the signatures that refer to ref, and returns the Payload of
+// the first one with a valid signature by v. It returns an error if there is
+// none.
+func Verify(ctx context.Context, ref name.Digest, v Verifier, options ...remote.Option) (*Payload, error) {
+	options = append(slices.Clip(options), remote.WithContext(ctx))
+	subject, err := v1.NewHash(ref.DigestStr())
+	if err != nil {
+		return nil, err
+	}
+	idx, err := remote.Referrers(ref, append(options, remote.WithFilter("artifactType", ArtifactType))...)
+	if err != nil {
+		return nil, err
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, desc := range im.Manifests {
+		// Not every registry filters referrers.
+		if desc.ArtifactType != ArtifactType {
+			continue
+		}
+		e, err := fetchEnvelope(ref.Context().Digest(desc.Digest.String()), options...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fetching %s: %w", desc.Digest, err))
+			continue
+		}
+		p, err := e.Verify(ctx, subject, v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", desc.Digest, err))
+			continue
+		}
+		return p, nil
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no signatures found for %s", ref)
+	}
+	return nil, fmt.Errorf("no valid signature for %s: %w", ref, errors.Join(errs...))
+}
+
+// Policy returns a remote.VerifyFunc, for remote.WithVerify, that only allows
+// pulling manifests with a valid signature by v. The signatures are looked up
+// with options, which must not include the returned policy itself, since the
+// signatures aren't signed.
+func Policy(v Verifier, options ...remote.Option) remote.VerifyFunc {
+	return func(ctx context.Context, ref name.Reference, desc v1.Descriptor) error {
+		_, err := Verify(ctx, ref.Context().Digest(desc.Digest.String()), v, options...)
+		return err
+	}
+}
+
+// fetchEnvelope returns the Envelope stored in the artifact ref.
+func fetchEnvelope(ref name.Digest, options ...remote.Option) (*Envelope, error) {
+	img, err := remote.Image(ref, options...)
+	if err != nil {
+		return nil, err
+	}
+	m, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Layers) != 1 || m.Layers[0].MediaType != EnvelopeMediaType {
+		return nil, fmt.Errorf("not a signature artifact")
+	}
+	l, err := img.LayerByDigest(m.Layers[0].Digest)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var e Envelope
+	if err := json.NewDecoder(rc).Decode(&e); err != nil {
+		return nil, fmt.Errorf("parsing envelope: %w", err)
+	}
+	return &e, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestAttachVerify(t *testing.T) {
+	ctx := context.Background()
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.NewDigest(u.Host + "/test@" + d.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref.Context().Tag("latest"), img); err != nil {
+		t.Fatal(err)
+	}
+
+	ks := keys(t)
+	signer, verifier := pair(t, ks["ecdsa"])
+	_, stranger := pair(t, ks["ed25519"])
+
+	if _, err := Verify(ctx, ref, verifier); err == nil {
+		t.Error("Verify: expected error before signing")
+	}
+
+	e, err := Attach(ctx, ref, nil, []Signer{signer})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Verify(ctx, ref, verifier)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if p.Subject.Digest != d {
+		t.Errorf("Verify: subject %s, want %s", p.Subject.Digest, d)
+	}
+	if _, err := Verify(ctx, ref, stranger); err == nil {
+		t.Error("Verify: expected error for another key")
+	}
+
+	// A policy only allows pulling signed images.
+	policy := remote.WithVerify(Policy(verifier))
+	if _, err := remote.Image(ref.Context().Tag("latest"), policy); err != nil {
+		t.Errorf("pulling a signed image: %v", err)
+	}
+	unsigned, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref.Context().Tag("unsigned"), unsigned); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := remote.Image(ref.Context().Tag("unsigned"), policy); err == nil {
+		t.Error("pulling an unsigned image: expected error")
+	}
+
+	// The artifact refers to the subject.
+	subject, err := remote.Head(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	art, err := Artifact(e, *subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := art.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ArtifactType != ArtifactType || m.Config.MediaType != types.OCIEmptyJSON || m.Subject == nil || m.Subject.Digest != d {
+		t.Errorf("Artifact manifest: %+v", m)
+	}
+	if _, err := art.LayerByDigest(m.Config.Digest); err != nil {
+		t.Errorf("LayerByDigest(config): %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	// ArtifactType is the artifactType of the manifests that hold envelopes.
+	ArtifactType = "application/vnd.dev.ggcr.signature.v1"
+
+	// EnvelopeMediaType is the mediaType of the layer that holds an envelope.
+	EnvelopeMediaType types.MediaType = "application/vnd.dev.ggcr.signature.envelope.v1+json"
+
+	// PayloadType is the payloadType of envelopes whose payload is a Payload.
+	PayloadType = "application/vnd.dev.ggcr.signature.payload.v1+json"
+)
+
+// Signer signs payloads with a key.
+type Signer interface {
+	// KeyID identifies the key, so that a Verifier can pick out the
+	// signatures it should check.
+	KeyID() string
+
+	// Sign returns the signature of msg.
+	Sign(ctx context.Context, msg []byte) ([]byte, error)
+}
+
+// Verifier verifies signatures made by a Signer.
+type Verifier interface {
+	// KeyID identifies the key; see Signer.
+	KeyID() string
+
+	// Verify returns an error unless sig is a valid signature of msg.
+	Verify(ctx context.Context, msg, sig []byte) error
+}
+
+// Envelope is a signed payload.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     []byte      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a signature of an Envelope's payload.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   []byte `json:"sig"`
+}
+
+// Payload is what an Envelope signs: the descriptor of the signed manifest,
+// and any annotations the signer wants to attest to.
+type Payload struct {
+	Subject     v1.Descriptor     `json:"subject"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Sign returns an Envelope signing subject with each of the signers.
+func Sign(ctx context.Context, subject v1.Descriptor, annotations map[string]string, signers ...Signer) (*Envelope, error) {
+	if len(signers) == 0 {
+		return nil, errors.New("no signers")
+	}
+	payload, err := json.Marshal(Payload{
+		Subject: v1.Descriptor{
+			MediaType: subject.MediaType,
+			Size:      subject.Size,
+			Digest:    subject.Digest,
+		},
+		Annotations: annotations,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Envelope{PayloadType: PayloadType, Payload: payload}
+	msg := pae(e.PayloadType, e.Payload)
+	for _, s := range signers {
+		sig, err := s.Sign(ctx, msg)
+		if err != nil {
+			return nil, fmt.Errorf("signing with %s: %w", s.KeyID(), err)
+		}
+		e.Signatures = append(e.Signatures, Signature{KeyID: s.KeyID(), Sig: sig})
+	}
+	return e, nil
+}
+
+// Verify checks that e has a valid signature by v, and that it signs
+// subject. It returns the signed Payload.
+func (e *Envelope) Verify(ctx context.Context, subject v1.Hash, v Verifier) (*Payload, error) {
+	if e.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unexpected payloadType %q", e.PayloadType)
+	}
+
+	msg := pae(e.PayloadType, e.Payload)
+	var errs []error
+	verified := false
+	for _, sig := range e.Signatures {
+		if sig.KeyID != v.KeyID() {
+			continue
+		}
+		if err := v.Verify(ctx, msg, sig.Sig); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		verified = true
+		break
+	}
+	if !verified {
+		if len(errs) == 0 {
+			return nil, fmt.Errorf("no signature by key %s", v.KeyID())
+		}
+		return nil, fmt.Errorf("no valid signature by key %s: %w", v.KeyID(), errors.Join(errs...))
+	}
+
+	var p Payload
+	if err := json.Unmarshal(e.Payload, &p); err != nil {
+		return nil, fmt.Errorf("parsing payload: %w", err)
+	}
+	if p.Subject.Digest != subject {
+		return nil, fmt.Errorf("signature is for %s, not %s", p.Subject.Digest, subject)
+	}
+	return &p, nil
+}
+
+// pae is the DSSE pre-authentication encoding of payload, which is what is
+// actually signed, so that the payloadType can't be swapped out.
+func pae(payloadType string, payload []byte) []byte {
+	b := []byte("DSSEv1 ")
+	b = strconv.AppendInt(b, int64(len(payloadType)), 10)
+	b = append(b, ' ')
+	b = append(b, payloadType...)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, int64(len(payload)), 10)
+	b = append(b, ' ')
+	return append(b, payload...)
+}
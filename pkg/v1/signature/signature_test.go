@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func keys(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ed, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]crypto.Signer{"ecdsa": ec, "ed25519": ed}
+}
+
+func pair(t *testing.T, key crypto.Signer) (Signer, Verifier) {
+	t.Helper()
+	s, err := NewSigner(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewVerifier(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, v
+}
+
+func TestSignVerify(t *testing.T) {
+	ctx := context.Background()
+	h, _, err := v1.SHA256(strings.NewReader("subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := v1.SHA256(strings.NewReader("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject := v1.Descriptor{
+		MediaType: types.OCIManifestSchema1,
+		Size:      123,
+		Digest:    h,
+	}
+
+	for name, key := range keys(t) {
+		t.Run(name, func(t *testing.T) {
+			s, v := pair(t, key)
+			if s.KeyID() != v.KeyID() {
+				t.Errorf("KeyID: signer %s != verifier %s", s.KeyID(), v.KeyID())
+			}
+
+			e, err := Sign(ctx, subject, map[string]string{"env": "prod"}, s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := e.Verify(ctx, subject.Digest, v)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if p.Subject.Digest != subject.Digest || p.Annotations["env"] != "prod" {
+				t.Errorf("Payload: %+v", p)
+			}
+
+			if _, err := e.Verify(ctx, other, v); err == nil {
+				t.Error("Verify: expected error for another subject")
+			}
+
+			tampered := *e
+			tampered.Payload = append([]byte{}, e.Payload...)
+			tampered.Payload[len(tampered.Payload)-2] ^= 1
+			if _, err := tampered.Verify(ctx, subject.Digest, v); err == nil {
+				t.Error("Verify: expected error for a tampered payload")
+			}
+
+			retyped := *e
+			retyped.PayloadType = "text/plain"
+			if _, err := retyped.Verify(ctx, subject.Digest, v); err == nil {
+				t.Error("Verify: expected error for another payloadType")
+			}
+		})
+	}
+
+	// A verifier for another key finds no signature.
+	ks := keys(t)
+	s, _ := pair(t, ks["ecdsa"])
+	_, v := pair(t, ks["ed25519"])
+	e, err := Sign(ctx, subject, nil, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Verify(ctx, subject.Digest, v); err == nil {
+		t.Error("Verify: expected error for another key")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	for name, key := range keys(t) {
+		t.Run(name, func(t *testing.T) {
+			priv, err := x509.MarshalPKCS8PrivateKey(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pub, err := x509.MarshalPKIXPublicKey(key.Public())
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := LoadSigner(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: priv}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := LoadVerifier(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.KeyID() != v.KeyID() {
+				t.Errorf("KeyID: signer %s != verifier %s", s.KeyID(), v.KeyID())
+			}
+		})
+	}
+
+	if _, err := LoadSigner([]byte("not a key")); err == nil {
+		t.Error("LoadSigner: expected error")
+	}
+}
@@ -18,7 +18,6 @@ package stream
 import (
 	"bufio"
 	"compress/gzip"
-	"crypto"
 	"encoding/hex"
 	"errors"
 	"hash"
@@ -26,6 +25,7 @@ import (
 	"os"
 	"sync"
 
+	"github.com/google/go-containerregistry/pkg/compression"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 )
@@ -44,6 +44,7 @@ var (
 type Layer struct {
 	blob        io.ReadCloser
 	consumed    bool
+	codec       compression.Compression
 	compression int
 
 	mu             sync.Mutex
@@ -57,7 +58,19 @@ var _ v1.Layer = (*Layer)(nil)
 // LayerOption applies options to layer
 type LayerOption func(*Layer)
 
-// WithCompressionLevel sets the gzip compression. See `gzip.NewWriterLevel` for possible values.
+// WithCompression sets the codec the layer is compressed with, which may be
+// any codec registered with the compression package. The default is gzip.
+//
+// Unless WithMediaType is also given, zstd layers have the OCI zstd media
+// type, and uncompressed layers the Docker uncompressed media type.
+func WithCompression(c compression.Compression) LayerOption {
+	return func(l *Layer) {
+		l.codec = c
+	}
+}
+
+// WithCompressionLevel sets the compression level, which is specific to the
+// codec. For gzip, see `gzip.NewWriterLevel` for possible values.
 func WithCompressionLevel(level int) LayerOption {
 	return func(l *Layer) {
 		l.compression = level
@@ -75,16 +88,26 @@ func WithMediaType(mt types.MediaType) LayerOption {
 func NewLayer(rc io.ReadCloser, opts ...LayerOption) *Layer {
 	layer := &Layer{
 		blob:        rc,
+		codec:       compression.GZip,
 		compression: gzip.BestSpeed,
-		// We use DockerLayer for now as uncompressed layers
-		// are unimplemented
-		mediaType: types.DockerLayer,
 	}
 
 	for _, opt := range opts {
 		opt(layer)
 	}
 
+	if layer.mediaType == "" {
+		layer.mediaType = types.DockerLayer
+		if c, err := compression.Lookup(layer.codec); err == nil {
+			switch c.Format() {
+			case compression.ZStd:
+				layer.mediaType = types.OCILayerZStd
+			case compression.None:
+				layer.mediaType = types.DockerUncompressedLayer
+			}
+		}
+	}
+
 	return layer
 }
 
@@ -168,8 +191,14 @@ type compressedReader struct {
 func newCompressedReader(l *Layer) (*compressedReader, error) {
 	// Collect digests of compressed and uncompressed stream and size of
 	// compressed stream.
-	h := crypto.SHA256.New()
-	zh := crypto.SHA256.New()
+	h, err := v1.Hasher("sha256")
+	if err != nil {
+		return nil, err
+	}
+	zh, err := v1.Hasher("sha256")
+	if err != nil {
+		return nil, err
+	}
 	count := &countWriter{}
 
 	// gzip.Writer writes to the output stream via pipe, a hasher to
@@ -183,7 +212,11 @@ func newCompressedReader(l *Layer) (*compressedReader, error) {
 	// Buffer the output of the gzip writer so we don't have to wait on pr to keep writing.
 	// 64K ought to be small enough for anybody.
 	bw := bufio.NewWriterSize(mw, 2<<16)
-	zw, err := gzip.NewWriterLevel(bw, l.compression)
+	codec, err := compression.Lookup(l.codec)
+	if err != nil {
+		return nil, err
+	}
+	zw, err := codec.NewWriter(bw, l.compression)
 	if err != nil {
 		return nil, err
 	}
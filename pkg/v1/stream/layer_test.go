@@ -24,6 +24,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/compression"
+	_ "github.com/google/go-containerregistry/pkg/compression/pgzip"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
@@ -293,3 +295,42 @@ func TestMediaTypeOption(t *testing.T) {
 		t.Errorf("MediaType(): want %q, got %q", want, got)
 	}
 }
+
+func TestWithCompression(t *testing.T) {
+	for _, tc := range []struct {
+		c     compression.Compression
+		mt    types.MediaType
+		magic []byte
+	}{
+		{compression.GZip, types.DockerLayer, []byte{0x1f, 0x8b}},
+		{compression.PGZip, types.DockerLayer, []byte{0x1f, 0x8b}},
+		{compression.ZStd, types.OCILayerZStd, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+		{compression.None, types.DockerUncompressedLayer, []byte("hello")},
+	} {
+		t.Run(string(tc.c), func(t *testing.T) {
+			l := NewLayer(io.NopCloser(strings.NewReader("hello")), WithCompression(tc.c))
+			if mt, err := l.MediaType(); err != nil || mt != tc.mt {
+				t.Errorf("MediaType() = %s, %v; want %s", mt, err, tc.mt)
+			}
+			rc, err := l.Compressed()
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := rc.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(b, tc.magic) {
+				t.Errorf("Compressed() = %x, want prefix %x", b, tc.magic)
+			}
+		})
+	}
+
+	l := NewLayer(io.NopCloser(strings.NewReader("hello")), WithCompression("lz4"))
+	if _, err := l.Compressed(); err == nil {
+		t.Error("Compressed() with an unknown codec = nil, want error")
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
 	"sync"
 
 	comp "github.com/google/go-containerregistry/internal/compression"
@@ -32,6 +33,7 @@ import (
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/types"
+	"golang.org/x/sync/errgroup"
 )
 
 type image struct {
@@ -350,7 +352,7 @@ func (c *compressedImage) Manifest() (*v1.Manifest, error) {
 		return nil, err
 	}
 
-	c.manifest = &v1.Manifest{
+	m := &v1.Manifest{
 		SchemaVersion: 2,
 		MediaType:     types.DockerManifestSchema2,
 		Config: v1.Descriptor{
@@ -360,33 +362,63 @@ func (c *compressedImage) Manifest() (*v1.Manifest, error) {
 		},
 	}
 
+	cfg, err := partial.ConfigFile(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hash the layers concurrently, once per file, since that dominates the
+	// time it takes to load a tarball.
+	layers := make([]v1.Descriptor, len(c.imgDescriptor.Layers))
+	var (
+		mu     sync.Mutex
+		hashed = map[string]*v1.Descriptor{}
+	)
+	g := errgroup.Group{}
+	g.SetLimit(runtime.GOMAXPROCS(0))
 	for i, p := range c.imgDescriptor.Layers {
-		cfg, err := partial.ConfigFile(c)
-		if err != nil {
-			return nil, err
-		}
 		diffid := cfg.RootFS.DiffIDs[i]
 		if d, ok := c.imgDescriptor.LayerSources[diffid]; ok {
 			// If it's a foreign layer, just append the descriptor so we can avoid
 			// reading the entire file.
-			c.manifest.Layers = append(c.manifest.Layers, d)
-		} else {
+			layers[i] = d
+			continue
+		}
+		mu.Lock()
+		_, ok := hashed[p]
+		if !ok {
+			hashed[p] = &v1.Descriptor{MediaType: types.DockerLayer}
+		}
+		mu.Unlock()
+		if ok {
+			continue
+		}
+		g.Go(func() error {
 			l, err := extractFileFromTar(c.opener, p)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			defer l.Close()
 			sha, size, err := v1.SHA256(l)
 			if err != nil {
-				return nil, err
+				return err
 			}
-			c.manifest.Layers = append(c.manifest.Layers, v1.Descriptor{
-				MediaType: types.DockerLayer,
-				Size:      size,
-				Digest:    sha,
-			})
+			mu.Lock()
+			defer mu.Unlock()
+			hashed[p].Digest, hashed[p].Size = sha, size
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	for i, p := range c.imgDescriptor.Layers {
+		if d, ok := hashed[p]; ok && layers[i].Digest == (v1.Hash{}) {
+			layers[i] = *d
 		}
 	}
+	m.Layers = layers
+	c.manifest = m
 	return c.manifest, nil
 }
 
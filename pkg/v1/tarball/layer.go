@@ -27,7 +27,6 @@ import (
 	comp "github.com/google/go-containerregistry/internal/compression"
 	gestargz "github.com/google/go-containerregistry/internal/estargz"
 	ggzip "github.com/google/go-containerregistry/internal/gzip"
-	"github.com/google/go-containerregistry/internal/zstd"
 	"github.com/google/go-containerregistry/pkg/compression"
 	"github.com/google/go-containerregistry/pkg/logs"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -95,23 +94,20 @@ func (l *layer) MediaType() (types.MediaType, error) {
 type LayerOption func(*layer)
 
 // WithCompression is a functional option for overriding the default
-// compression algorithm used for compressing uncompressed tarballs.
+// compression algorithm used for compressing uncompressed tarballs, which
+// may be any codec registered with the compression package.
 // Please note that WithCompression(compression.ZStd) should be used
 // in conjunction with WithMediaType(types.OCILayerZStd)
 func WithCompression(comp compression.Compression) LayerOption {
 	return func(l *layer) {
-		switch comp {
-		case compression.ZStd:
-			l.compression = compression.ZStd
-		case compression.GZip:
-			l.compression = compression.GZip
-		case compression.None:
+		if comp == compression.None {
 			logs.Warn.Printf("Compression type 'none' is not supported for tarball layers; using gzip compression.")
-			l.compression = compression.GZip
-		default:
+			comp = compression.GZip
+		} else if _, err := compression.Lookup(comp); err != nil {
 			logs.Warn.Printf("Unexpected compression type for WithCompression(): %s; using gzip compression instead.", comp)
-			l.compression = compression.GZip
+			comp = compression.GZip
 		}
+		l.compression = comp
 	}
 }
 
@@ -229,7 +225,7 @@ func LayerFromFile(path string, opts ...LayerOption) (v1.Layer, error) {
 // Since gzip can be expensive, we support an option to memoize the
 // compression that can be passed here: tarball.WithCompressedCaching
 func LayerFromOpener(opener Opener, opts ...LayerOption) (v1.Layer, error) {
-	comp, err := comp.GetCompression(opener)
+	cp, err := comp.GetCompression(opener)
 	if err != nil {
 		return nil, err
 	}
@@ -246,38 +242,31 @@ func LayerFromOpener(opener Opener, opts ...LayerOption) (v1.Layer, error) {
 		opts = append([]LayerOption{WithEstargz}, opts...)
 	}
 
-	switch comp {
-	case compression.GZip:
-		layer.compressedopener = opener
-		layer.uncompressedopener = func() (io.ReadCloser, error) {
-			urc, err := opener()
-			if err != nil {
-				return nil, err
-			}
-			return ggzip.UnzipReadCloser(urc)
+	if cp != compression.None {
+		codec, err := compression.Lookup(cp)
+		if err != nil {
+			return nil, err
 		}
-	case compression.ZStd:
 		layer.compressedopener = opener
 		layer.uncompressedopener = func() (io.ReadCloser, error) {
 			urc, err := opener()
 			if err != nil {
 				return nil, err
 			}
-			return zstd.UnzipReadCloser(urc)
+			return comp.UnzipReadCloser(urc, codec)
 		}
-	default:
+	} else {
 		layer.uncompressedopener = opener
 		layer.compressedopener = func() (io.ReadCloser, error) {
-			crc, err := opener()
+			codec, err := compression.Lookup(layer.compression)
 			if err != nil {
 				return nil, err
 			}
-
-			if layer.compression == compression.ZStd {
-				return zstd.ReadCloserLevel(crc, layer.compressionLevel), nil
+			crc, err := opener()
+			if err != nil {
+				return nil, err
 			}
-
-			return ggzip.ReadCloserLevel(crc, layer.compressionLevel), nil
+			return comp.ReadCloserLevel(crc, codec, layer.compressionLevel), nil
 		}
 	}
 
@@ -287,7 +276,11 @@ func LayerFromOpener(opener Opener, opts ...LayerOption) (v1.Layer, error) {
 
 	// Warn if media type does not match compression
 	var mediaTypeMismatch = false
-	switch layer.compression {
+	format := layer.compression
+	if codec, err := compression.Lookup(format); err == nil {
+		format = codec.Format()
+	}
+	switch format {
 	case compression.GZip:
 		mediaTypeMismatch =
 			layer.mediaType != types.OCILayer &&
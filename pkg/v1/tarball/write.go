@@ -22,12 +22,15 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"golang.org/x/sync/errgroup"
 )
 
 // WriteToFile writes in the compressed format to a tarball, on disk.
@@ -224,6 +227,11 @@ func calculateManifest(imageToTags map[v1.Image][]string) (m Manifest, err error
 		return nil, errors.New("set of images is empty")
 	}
 
+	digests, err := layerDigests(imageToTags)
+	if err != nil {
+		return nil, err
+	}
+
 	for img, tags := range imageToTags {
 		cfgName, err := img.ConfigName()
 		if err != nil {
@@ -234,16 +242,8 @@ func calculateManifest(imageToTags map[v1.Image][]string) (m Manifest, err error
 		layerSources := make(map[v1.Hash]v1.Descriptor)
 
 		// Write the layers.
-		layers, err := img.Layers()
-		if err != nil {
-			return nil, err
-		}
-		layerFiles := make([]string, len(layers))
-		for i, l := range layers {
-			d, err := l.Digest()
-			if err != nil {
-				return nil, err
-			}
+		layerFiles := make([]string, len(digests[img]))
+		for i, d := range digests[img] {
 			// Munge the file name to appease ancient technology.
 			//
 			// tar assumes anything with a colon is a remote tape drive:
@@ -286,6 +286,54 @@ func calculateManifest(imageToTags map[v1.Image][]string) (m Manifest, err error
 	return m, nil
 }
 
+// layerDigests computes the digests of the images' layers concurrently, once
+// for each layer that several images share, since for uncompressed layers
+// that means compressing and hashing each one.
+func layerDigests(imageToTags map[v1.Image][]string) (map[v1.Image][]v1.Hash, error) {
+	var (
+		mu      sync.Mutex
+		started = map[v1.Layer]bool{}
+		byLayer = map[v1.Layer]v1.Hash{}
+	)
+	g := errgroup.Group{}
+	g.SetLimit(runtime.GOMAXPROCS(0))
+	imageLayers := make(map[v1.Image][]v1.Layer, len(imageToTags))
+	for img := range imageToTags {
+		layers, err := img.Layers()
+		if err != nil {
+			return nil, err
+		}
+		imageLayers[img] = layers
+		for _, l := range layers {
+			if started[l] {
+				continue
+			}
+			started[l] = true
+			g.Go(func() error {
+				d, err := l.Digest()
+				if err != nil {
+					return err
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				byLayer[l] = d
+				return nil
+			})
+		}
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	digests := make(map[v1.Image][]v1.Hash, len(imageLayers))
+	for img, layers := range imageLayers {
+		for _, l := range layers {
+			digests[img] = append(digests[img], byLayer[l])
+		}
+	}
+	return digests, nil
+}
+
 // CalculateSize calculates the expected complete size of the output tar file
 func CalculateSize(refToImage map[name.Reference]v1.Image) (size int64, err error) {
 	imageToTags := dedupRefToImage(refToImage)
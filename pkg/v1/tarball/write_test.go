@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -377,6 +378,85 @@ func TestWriteSharedLayers(t *testing.T) {
 	}
 }
 
+func TestWriteRepeatedLayers(t *testing.T) {
+	// Layers are hashed concurrently when the tarball is read back, and the
+	// repeated one should only be hashed once but appear in every position.
+	img, err := random.Image(256, 4)
+	if err != nil {
+		t.Fatalf("random.Image: %v", err)
+	}
+	l, err := random.Layer(512, types.DockerLayer)
+	if err != nil {
+		t.Fatalf("random.Layer: %v", err)
+	}
+	img, err = mutate.AppendLayers(img, l, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag("gcr.io/foo/bar:latest", name.StrictValidation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fp := filepath.Join(t.TempDir(), "image.tar")
+	if err := tarball.WriteToFile(fp, tag, img); err != nil {
+		t.Fatalf("WriteToFile: %v", err)
+	}
+	tarImage, err := tarball.ImageFromPath(fp, &tag)
+	if err != nil {
+		t.Fatalf("ImageFromPath: %v", err)
+	}
+	if err := validate.Image(tarImage); err != nil {
+		t.Errorf("validate.Image: %v", err)
+	}
+	if err := compare.Images(img, tarImage); err != nil {
+		t.Errorf("compare.Images: %v", err)
+	}
+}
+
+// freshLayers returns new layer values from each call to Layers, like
+// remote images do.
+type freshLayers struct {
+	v1.Image
+}
+
+type freshLayer struct {
+	v1.Layer
+}
+
+func (i freshLayers) Layers() ([]v1.Layer, error) {
+	layers, err := i.Image.Layers()
+	if err != nil {
+		return nil, err
+	}
+	for n, l := range layers {
+		layers[n] = &freshLayer{l}
+	}
+	return layers, nil
+}
+
+func TestWriteFreshLayers(t *testing.T) {
+	img, err := random.Image(256, 2)
+	if err != nil {
+		t.Fatalf("random.Image: %v", err)
+	}
+	tag, err := name.NewTag("gcr.io/foo/bar:latest", name.StrictValidation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := filepath.Join(t.TempDir(), "image.tar")
+	if err := tarball.WriteToFile(fp, tag, freshLayers{img}); err != nil {
+		t.Fatalf("WriteToFile: %v", err)
+	}
+	tarImage, err := tarball.ImageFromPath(fp, &tag)
+	if err != nil {
+		t.Fatalf("ImageFromPath: %v", err)
+	}
+	if err := compare.Images(img, tarImage); err != nil {
+		t.Errorf("compare.Images: %v", err)
+	}
+}
+
 func TestComputeManifest(t *testing.T) {
 	var randomTag, mutatedTag = "ubuntu", "gcr.io/baz/bat:latest"
 
@@ -29,6 +29,9 @@ const (
 	OCIRestrictedLayer             MediaType = "application/vnd.oci.image.layer.nondistributable.v1.tar+gzip"
 	OCIUncompressedLayer           MediaType = "application/vnd.oci.image.layer.v1.tar"
 	OCIUncompressedRestrictedLayer MediaType = "application/vnd.oci.image.layer.nondistributable.v1.tar"
+	OCIRestrictedLayerZStd         MediaType = "application/vnd.oci.image.layer.nondistributable.v1.tar+zstd"
+	OCIEmptyJSON                   MediaType = "application/vnd.oci.empty.v1+json"
+	OCIArtifactManifest            MediaType = "application/vnd.oci.artifact.manifest.v1+json"
 
 	DockerManifestSchema1       MediaType = "application/vnd.docker.distribution.manifest.v1+json"
 	DockerManifestSchema1Signed MediaType = "application/vnd.docker.distribution.manifest.v1+prettyjws"
@@ -40,6 +43,11 @@ const (
 	DockerForeignLayer          MediaType = "application/vnd.docker.image.rootfs.foreign.diff.tar.gzip"
 	DockerUncompressedLayer     MediaType = "application/vnd.docker.image.rootfs.diff.tar"
 
+	WasmConfig       MediaType = "application/vnd.wasm.config.v1+json"
+	WasmContentLayer MediaType = "application/vnd.wasm.content.layer.v1+wasm"
+	WasmModuleLayer  MediaType = "application/vnd.module.wasm.content.layer.v1+wasm"
+	WasmModule       MediaType = "application/wasm"
+
 	OCIVendorPrefix    = "vnd.oci"
 	DockerVendorPrefix = "vnd.docker"
 )
@@ -48,16 +56,19 @@ const (
 // https://github.com/opencontainers/image-spec/blob/master/layer.md#non-distributable-layers
 func (m MediaType) IsDistributable() bool {
 	switch m {
-	case DockerForeignLayer, OCIRestrictedLayer, OCIUncompressedRestrictedLayer:
+	case DockerForeignLayer, OCIRestrictedLayer, OCIUncompressedRestrictedLayer, OCIRestrictedLayerZStd:
 		return false
 	}
 	return true
 }
 
 // IsImage returns true if the mediaType represents an image manifest, as opposed to something else, like an index.
+//
+// OCI artifact manifests are treated as image manifests, since they're fetched
+// and referenced in the same way; use IsArtifact to tell them apart.
 func (m MediaType) IsImage() bool {
 	switch m {
-	case OCIManifestSchema1, DockerManifestSchema2:
+	case OCIManifestSchema1, DockerManifestSchema2, OCIArtifactManifest:
 		return true
 	}
 	return false
@@ -91,8 +102,49 @@ func (m MediaType) IsSchema1() bool {
 
 func (m MediaType) IsLayer() bool {
 	switch m {
-	case DockerLayer, DockerUncompressedLayer, OCILayer, OCILayerZStd, OCIUncompressedLayer, DockerForeignLayer, OCIRestrictedLayer, OCIUncompressedRestrictedLayer:
+	case DockerLayer, DockerUncompressedLayer, OCILayer, OCILayerZStd, OCIUncompressedLayer, DockerForeignLayer, OCIRestrictedLayer, OCIUncompressedRestrictedLayer, OCIRestrictedLayerZStd:
+		return true
+	}
+	return false
+}
+
+// IsZstdLayer returns true if the mediaType represents a zstd-compressed layer.
+func (m MediaType) IsZstdLayer() bool {
+	switch m {
+	case OCILayerZStd, OCIRestrictedLayerZStd:
+		return true
+	}
+	return false
+}
+
+// IsWasm returns true if the mediaType represents a WebAssembly module or its
+// config, as opposed to a container image.
+func (m MediaType) IsWasm() bool {
+	switch m {
+	case WasmConfig, WasmContentLayer, WasmModuleLayer, WasmModule:
 		return true
 	}
 	return false
 }
+
+// IsArtifact returns true if the mediaType represents an OCI artifact manifest.
+//
+// Image manifests can also describe artifacts; see ArtifactTypeOf.
+func (m MediaType) IsArtifact() bool {
+	return m == OCIArtifactManifest
+}
+
+// ArtifactTypeOf returns the artifact type of a manifest with the given
+// artifactType field and config mediaType, or "" if it describes an image.
+//
+// Per the image-spec, artifactType takes precedence, otherwise the config's
+// mediaType is the artifact type unless it's an image config.
+func ArtifactTypeOf(artifactType string, config MediaType) string {
+	if artifactType != "" {
+		return artifactType
+	}
+	if config == "" || config.IsConfig() {
+		return ""
+	}
+	return string(config)
+}
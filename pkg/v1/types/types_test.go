@@ -51,7 +51,7 @@ func TestIsDistributable(t *testing.T) {
 
 func TestIsImage(t *testing.T) {
 	for _, mt := range []MediaType{
-		OCIManifestSchema1, DockerManifestSchema2,
+		OCIManifestSchema1, DockerManifestSchema2, OCIArtifactManifest,
 	} {
 		if !mt.IsImage() {
 			t.Errorf("%s: should be image", mt)
@@ -97,6 +97,7 @@ func TestIsIndex(t *testing.T) {
 		OCIUncompressedLayer,
 		OCIUncompressedRestrictedLayer,
 		OCIManifestSchema1,
+		OCIArtifactManifest,
 
 		DockerManifestSchema2,
 		DockerLayer,
@@ -110,3 +111,81 @@ func TestIsIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestIsZstdLayer(t *testing.T) {
+	for _, mt := range []MediaType{
+		OCILayerZStd, OCIRestrictedLayerZStd,
+	} {
+		if !mt.IsZstdLayer() {
+			t.Errorf("%s: should be zstd layer", mt)
+		}
+		if !mt.IsLayer() {
+			t.Errorf("%s: should be layer", mt)
+		}
+	}
+
+	for _, mt := range []MediaType{
+		OCILayer,
+		OCIUncompressedLayer,
+		DockerLayer,
+		WasmContentLayer,
+	} {
+		if mt.IsZstdLayer() {
+			t.Errorf("%s: should not be zstd layer", mt)
+		}
+	}
+}
+
+func TestIsWasm(t *testing.T) {
+	for _, mt := range []MediaType{
+		WasmConfig, WasmContentLayer, WasmModuleLayer, WasmModule,
+	} {
+		if !mt.IsWasm() {
+			t.Errorf("%s: should be wasm", mt)
+		}
+	}
+
+	for _, mt := range []MediaType{
+		OCIConfigJSON,
+		OCILayer,
+		OCIArtifactManifest,
+	} {
+		if mt.IsWasm() {
+			t.Errorf("%s: should not be wasm", mt)
+		}
+	}
+}
+
+func TestIsArtifact(t *testing.T) {
+	if !OCIArtifactManifest.IsArtifact() {
+		t.Errorf("%s: should be artifact", OCIArtifactManifest)
+	}
+	for _, mt := range []MediaType{
+		OCIManifestSchema1,
+		OCIImageIndex,
+		DockerManifestSchema2,
+	} {
+		if mt.IsArtifact() {
+			t.Errorf("%s: should not be artifact", mt)
+		}
+	}
+}
+
+func TestArtifactTypeOf(t *testing.T) {
+	for _, tt := range []struct {
+		artifactType string
+		config       MediaType
+		want         string
+	}{
+		{"", OCIConfigJSON, ""},
+		{"", DockerConfigJSON, ""},
+		{"", "", ""},
+		{"", WasmConfig, string(WasmConfig)},
+		{"application/vnd.example.sbom", OCIEmptyJSON, "application/vnd.example.sbom"},
+		{"application/vnd.example.sbom", OCIConfigJSON, "application/vnd.example.sbom"},
+	} {
+		if got := ArtifactTypeOf(tt.artifactType, tt.config); got != tt.want {
+			t.Errorf("ArtifactTypeOf(%q, %q) = %q, want %q", tt.artifactType, tt.config, got, tt.want)
+		}
+	}
+}
@@ -27,19 +27,36 @@ import (
 )
 
 // Image validates that img does not violate any invariants of the image format.
+//
+// If any checks fail, the returned error is a *Report listing every failure.
 func Image(img v1.Image, opt ...Option) error {
+	o := makeOptions(opt...)
+	r := &Report{}
+	if err := validateImage(img, &o, r); err != nil {
+		return err
+	}
+	return r.result()
+}
+
+func validateImage(img v1.Image, o *options, r *Report) error {
 	errs := []string{}
-	if err := validateLayers(img, opt...); err != nil {
+	layers := &Report{}
+	if err := validateLayers(img, o, layers); err != nil {
 		errs = append(errs, fmt.Sprintf("validating layers: %v", err))
 	}
+	r.merge("", "validating layers: ", layers)
 
-	if err := validateConfig(img); err != nil {
+	config := &Report{}
+	if err := validateConfig(img, o, config); err != nil {
 		errs = append(errs, fmt.Sprintf("validating config: %v", err))
 	}
+	r.merge("", "validating config: ", config)
 
-	if err := validateManifest(img); err != nil {
+	manifest := &Report{}
+	if err := validateManifest(img, o, manifest); err != nil {
 		errs = append(errs, fmt.Sprintf("validating manifest: %v", err))
 	}
+	r.merge("", "validating manifest: ", manifest)
 
 	if len(errs) != 0 {
 		return errors.New(strings.Join(errs, "\n\n"))
@@ -47,7 +64,7 @@ func Image(img v1.Image, opt ...Option) error {
 	return nil
 }
 
-func validateConfig(img v1.Image) error {
+func validateConfig(img v1.Image, o *options, r *Report) error {
 	cn, err := img.ConfigName()
 	if err != nil {
 		return err
@@ -78,40 +95,35 @@ func validateConfig(img v1.Image) error {
 		return err
 	}
 
-	errs := []string{}
-	if cn != hash {
-		errs = append(errs, fmt.Sprintf("mismatched config digest: ConfigName()=%s, SHA256(RawConfigFile())=%s", cn, hash))
-	}
-
-	if want, got := m.Config.Size, size; want != got {
-		errs = append(errs, fmt.Sprintf("mismatched config size: Manifest.Config.Size()=%d, len(RawConfigFile())=%d", want, got))
+	if o.enabled(CheckDigest) && cn != hash {
+		r.add(CheckDigest, "config", fmt.Sprintf("mismatched config digest: ConfigName()=%s, SHA256(RawConfigFile())=%s", cn, hash))
 	}
 
-	if diff := cmp.Diff(pcf, cf); diff != "" {
-		errs = append(errs, fmt.Sprintf("mismatched config content: (-ParseConfigFile(RawConfigFile()) +ConfigFile()) %s", diff))
+	if want, got := m.Config.Size, size; o.enabled(CheckSize) && want != got {
+		r.add(CheckSize, "config", fmt.Sprintf("mismatched config size: Manifest.Config.Size()=%d, len(RawConfigFile())=%d", want, got))
 	}
 
-	if cf.RootFS.Type != "layers" {
-		errs = append(errs, fmt.Sprintf("invalid ConfigFile.RootFS.Type: %q != %q", cf.RootFS.Type, "layers"))
-	}
+	if o.enabled(CheckContent) {
+		if diff := cmp.Diff(pcf, cf); diff != "" {
+			r.add(CheckContent, "config", fmt.Sprintf("mismatched config content: (-ParseConfigFile(RawConfigFile()) +ConfigFile()) %s", diff))
+		}
 
-	if len(errs) != 0 {
-		return errors.New(strings.Join(errs, "\n"))
+		if cf.RootFS.Type != "layers" {
+			r.add(CheckContent, "config", fmt.Sprintf("invalid ConfigFile.RootFS.Type: %q != %q", cf.RootFS.Type, "layers"))
+		}
 	}
 
 	return nil
 }
 
-func validateLayers(img v1.Image, opt ...Option) error {
-	o := makeOptions(opt...)
-
+func validateLayers(img v1.Image, o *options, r *Report) error {
 	layers, err := img.Layers()
 	if err != nil {
 		return err
 	}
 
 	if o.fast {
-		return layersExist(layers)
+		return layersExist(layers, o, r)
 	}
 
 	digests := []v1.Hash{}
@@ -119,13 +131,13 @@ func validateLayers(img v1.Image, opt ...Option) error {
 	udiffids := []v1.Hash{}
 	sizes := []int64{}
 	for i, layer := range layers {
-		cl, err := computeLayer(layer)
+		cl, err := computeLayer(layer, o.enabled(CheckUncompressed))
 		if errors.Is(err, io.ErrUnexpectedEOF) {
 			// Errored while reading tar content of layer because a header or
 			// content section was not the correct length. This is most likely
 			// due to an incomplete download or otherwise interrupted process.
 			m, err := img.Manifest()
-			if err != nil {
+			if err != nil || i >= len(m.Layers) {
 				return fmt.Errorf("undersized layer[%d] content", i)
 			}
 			return fmt.Errorf("undersized layer[%d] content: Manifest.Layers[%d].Size=%d", i, i, m.Layers[i].Size)
@@ -151,8 +163,18 @@ func validateLayers(img v1.Image, opt ...Option) error {
 		return err
 	}
 
-	errs := []string{}
+	// Layers that the manifest or config don't list can't be compared with
+	// them, but we still check everything else.
+	if len(m.Layers) != len(layers) || len(cf.RootFS.DiffIDs) != len(layers) {
+		if o.enabled(CheckLayerOrder) {
+			r.add(CheckLayerOrder, "layers", fmt.Sprintf("mismatched layer count: len(Layers())=%d, len(Manifest.Layers)=%d, len(ConfigFile.RootFS.DiffIDs)=%d", len(layers), len(m.Layers), len(cf.RootFS.DiffIDs)))
+		}
+	}
+
 	for i, layer := range layers {
+		subject := fmt.Sprintf("layer[%d]", i)
+		inManifest, inConfig := i < len(m.Layers), i < len(cf.RootFS.DiffIDs)
+
 		digest, err := layer.Digest()
 		if err != nil {
 			return err
@@ -178,46 +200,59 @@ func validateLayers(img v1.Image, opt ...Option) error {
 			return err
 		}
 
-		if digest != digests[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] digest: Digest()=%s, SHA256(Compressed())=%s", i, digest, digests[i]))
+		if o.enabled(CheckDigest) && digest != digests[i] {
+			r.add(CheckDigest, subject, fmt.Sprintf("mismatched layer[%d] digest: Digest()=%s, SHA256(Compressed())=%s", i, digest, digests[i]))
 		}
 
-		if m.Layers[i].Digest != digests[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] digest: Manifest.Layers[%d].Digest=%s, SHA256(Compressed())=%s", i, i, m.Layers[i].Digest, digests[i]))
+		if inManifest && m.Layers[i].Digest != digests[i] {
+			// A digest that belongs to another layer means the manifest lists
+			// the right layers in the wrong order.
+			if contains(digests, m.Layers[i].Digest) {
+				if o.enabled(CheckLayerOrder) {
+					r.add(CheckLayerOrder, subject, fmt.Sprintf("mismatched layer[%d] digest: Manifest.Layers[%d].Digest=%s, SHA256(Compressed())=%s", i, i, m.Layers[i].Digest, digests[i]))
+				}
+			} else if o.enabled(CheckDigest) {
+				r.add(CheckDigest, subject, fmt.Sprintf("mismatched layer[%d] digest: Manifest.Layers[%d].Digest=%s, SHA256(Compressed())=%s", i, i, m.Layers[i].Digest, digests[i]))
+			}
 		}
 
-		if diffid != diffids[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] diffid: DiffID()=%s, SHA256(Gunzip(Compressed()))=%s", i, diffid, diffids[i]))
+		if o.enabled(CheckDiffID) && diffid != diffids[i] {
+			r.add(CheckDiffID, subject, fmt.Sprintf("mismatched layer[%d] diffid: DiffID()=%s, SHA256(Gunzip(Compressed()))=%s", i, diffid, diffids[i]))
 		}
 
-		if diffid != udiffids[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] diffid: DiffID()=%s, SHA256(Uncompressed())=%s", i, diffid, udiffids[i]))
+		if o.enabled(CheckUncompressed) && diffid != udiffids[i] {
+			r.add(CheckUncompressed, subject, fmt.Sprintf("mismatched layer[%d] diffid: DiffID()=%s, SHA256(Uncompressed())=%s", i, diffid, udiffids[i]))
 		}
 
-		if cf.RootFS.DiffIDs[i] != diffids[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] diffid: ConfigFile.RootFS.DiffIDs[%d]=%s, SHA256(Gunzip(Compressed()))=%s", i, i, cf.RootFS.DiffIDs[i], diffids[i]))
+		if inConfig && cf.RootFS.DiffIDs[i] != diffids[i] {
+			if contains(diffids, cf.RootFS.DiffIDs[i]) {
+				if o.enabled(CheckLayerOrder) {
+					r.add(CheckLayerOrder, subject, fmt.Sprintf("mismatched layer[%d] diffid: ConfigFile.RootFS.DiffIDs[%d]=%s, SHA256(Gunzip(Compressed()))=%s", i, i, cf.RootFS.DiffIDs[i], diffids[i]))
+				}
+			} else if o.enabled(CheckDiffID) {
+				r.add(CheckDiffID, subject, fmt.Sprintf("mismatched layer[%d] diffid: ConfigFile.RootFS.DiffIDs[%d]=%s, SHA256(Gunzip(Compressed()))=%s", i, i, cf.RootFS.DiffIDs[i], diffids[i]))
+			}
 		}
 
-		if size != sizes[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] size: Size()=%d, len(Compressed())=%d", i, size, sizes[i]))
-		}
+		if o.enabled(CheckSize) {
+			if size != sizes[i] {
+				r.add(CheckSize, subject, fmt.Sprintf("mismatched layer[%d] size: Size()=%d, len(Compressed())=%d", i, size, sizes[i]))
+			}
 
-		if m.Layers[i].Size != sizes[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] size: Manifest.Layers[%d].Size=%d, len(Compressed())=%d", i, i, m.Layers[i].Size, sizes[i]))
+			if inManifest && m.Layers[i].Size != sizes[i] {
+				r.add(CheckSize, subject, fmt.Sprintf("mismatched layer[%d] size: Manifest.Layers[%d].Size=%d, len(Compressed())=%d", i, i, m.Layers[i].Size, sizes[i]))
+			}
 		}
 
-		if m.Layers[i].MediaType != mediaType {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] mediaType: Manifest.Layers[%d].MediaType=%s, layer.MediaType()=%s", i, i, m.Layers[i].MediaType, mediaType))
+		if o.enabled(CheckMediaType) && inManifest && m.Layers[i].MediaType != mediaType {
+			r.add(CheckMediaType, subject, fmt.Sprintf("mismatched layer[%d] mediaType: Manifest.Layers[%d].MediaType=%s, layer.MediaType()=%s", i, i, m.Layers[i].MediaType, mediaType))
 		}
 	}
-	if len(errs) != 0 {
-		return errors.New(strings.Join(errs, "\n"))
-	}
 
 	return nil
 }
 
-func validateManifest(img v1.Image) error {
+func validateManifest(img v1.Image, o *options, r *Report) error {
 	digest, err := img.Digest()
 	if err != nil {
 		return err
@@ -248,35 +283,37 @@ func validateManifest(img v1.Image) error {
 		return err
 	}
 
-	errs := []string{}
-	if digest != hash {
-		errs = append(errs, fmt.Sprintf("mismatched manifest digest: Digest()=%s, SHA256(RawManifest())=%s", digest, hash))
-	}
-
-	if diff := cmp.Diff(pm, m); diff != "" {
-		errs = append(errs, fmt.Sprintf("mismatched manifest content: (-ParseManifest(RawManifest()) +Manifest()) %s", diff))
+	if o.enabled(CheckDigest) && digest != hash {
+		r.add(CheckDigest, "manifest", fmt.Sprintf("mismatched manifest digest: Digest()=%s, SHA256(RawManifest())=%s", digest, hash))
 	}
 
-	if size != int64(len(rm)) {
-		errs = append(errs, fmt.Sprintf("mismatched manifest size: Size()=%d, len(RawManifest())=%d", size, len(rm)))
+	if o.enabled(CheckContent) {
+		if diff := cmp.Diff(pm, m); diff != "" {
+			r.add(CheckContent, "manifest", fmt.Sprintf("mismatched manifest content: (-ParseManifest(RawManifest()) +Manifest()) %s", diff))
+		}
 	}
 
-	if len(errs) != 0 {
-		return errors.New(strings.Join(errs, "\n"))
+	if o.enabled(CheckSize) && size != int64(len(rm)) {
+		r.add(CheckSize, "manifest", fmt.Sprintf("mismatched manifest size: Size()=%d, len(RawManifest())=%d", size, len(rm)))
 	}
 
 	return nil
 }
 
-func layersExist(layers []v1.Layer) error {
+func layersExist(layers []v1.Layer, o *options, r *Report) error {
+	if !o.enabled(CheckExists) {
+		return nil
+	}
+
 	errs := []string{}
-	for _, layer := range layers {
+	for i, layer := range layers {
 		ok, err := partial.Exists(layer)
 		if err != nil {
 			errs = append(errs, err.Error())
+			continue
 		}
 		if !ok {
-			errs = append(errs, "layer does not exist")
+			r.add(CheckExists, fmt.Sprintf("layer[%d]", i), "layer does not exist")
 		}
 	}
 
@@ -286,3 +323,12 @@ func layersExist(layers []v1.Layer) error {
 
 	return nil
 }
+
+func contains(hs []v1.Hash, h v1.Hash) bool {
+	for _, x := range hs {
+		if x == h {
+			return true
+		}
+	}
+	return false
+}
@@ -27,16 +27,31 @@ import (
 )
 
 // Index validates that idx does not violate any invariants of the index format.
+//
+// If any checks fail, the returned error is a *Report listing every failure.
 func Index(idx v1.ImageIndex, opt ...Option) error {
+	o := makeOptions(opt...)
+	r := &Report{}
+	if err := validateIndex(idx, &o, r); err != nil {
+		return err
+	}
+	return r.result()
+}
+
+func validateIndex(idx v1.ImageIndex, o *options, r *Report) error {
 	errs := []string{}
 
-	if err := validateChildren(idx, opt...); err != nil {
+	children := &Report{}
+	if err := validateChildren(idx, o, children); err != nil {
 		errs = append(errs, fmt.Sprintf("validating children: %v", err))
 	}
+	r.merge("", "validating children: ", children)
 
-	if err := validateIndexManifest(idx); err != nil {
+	manifest := &Report{}
+	if err := validateIndexManifest(idx, o, manifest); err != nil {
 		errs = append(errs, fmt.Sprintf("validating index manifest: %v", err))
 	}
+	r.merge("", "validating index manifest: ", manifest)
 
 	if len(errs) != 0 {
 		return errors.New(strings.Join(errs, "\n\n"))
@@ -48,7 +63,7 @@ type withLayer interface {
 	Layer(v1.Hash) (v1.Layer, error)
 }
 
-func validateChildren(idx v1.ImageIndex, opt ...Option) error {
+func validateChildren(idx v1.ImageIndex, o *options, r *Report) error {
 	manifest, err := idx.IndexManifest()
 	if err != nil {
 		return err
@@ -56,31 +71,42 @@ func validateChildren(idx v1.ImageIndex, opt ...Option) error {
 
 	errs := []string{}
 	for i, desc := range manifest.Manifests {
+		subject := fmt.Sprintf("Manifests[%d](%s)", i, desc.Digest)
 		switch desc.MediaType {
 		case types.OCIImageIndex, types.DockerManifestList:
 			idx, err := idx.ImageIndex(desc.Digest)
 			if err != nil {
 				return err
 			}
-			if err := Index(idx, opt...); err != nil {
-				errs = append(errs, fmt.Sprintf("failed to validate index Manifests[%d](%s): %v", i, desc.Digest, err))
+			child := &Report{}
+			if err := validateIndex(idx, o, child); err != nil {
+				errs = append(errs, fmt.Sprintf("failed to validate index %s: %v", subject, err))
 			}
-			if err := validateMediaType(idx, desc.MediaType); err != nil {
-				errs = append(errs, fmt.Sprintf("failed to validate index MediaType[%d](%s): %v", i, desc.Digest, err))
+			r.merge(subject, "failed to validate index "+subject+": ", child)
+			if o.enabled(CheckMediaType) {
+				if err := validateMediaType(idx, desc.MediaType, subject, fmt.Sprintf("failed to validate index MediaType[%d](%s)", i, desc.Digest), r); err != nil {
+					errs = append(errs, fmt.Sprintf("failed to validate index MediaType[%d](%s): %v", i, desc.Digest, err))
+				}
 			}
 		case types.OCIManifestSchema1, types.DockerManifestSchema2:
 			img, err := idx.Image(desc.Digest)
 			if err != nil {
 				return err
 			}
-			if err := Image(img, opt...); err != nil {
-				errs = append(errs, fmt.Sprintf("failed to validate image Manifests[%d](%s): %v", i, desc.Digest, err))
+			child := &Report{}
+			if err := validateImage(img, o, child); err != nil {
+				errs = append(errs, fmt.Sprintf("failed to validate image %s: %v", subject, err))
 			}
-			if err := validateMediaType(img, desc.MediaType); err != nil {
-				errs = append(errs, fmt.Sprintf("failed to validate image MediaType[%d](%s): %v", i, desc.Digest, err))
+			r.merge(subject, "failed to validate image "+subject+": ", child)
+			if o.enabled(CheckMediaType) {
+				if err := validateMediaType(img, desc.MediaType, subject, fmt.Sprintf("failed to validate image MediaType[%d](%s)", i, desc.Digest), r); err != nil {
+					errs = append(errs, fmt.Sprintf("failed to validate image MediaType[%d](%s): %v", i, desc.Digest, err))
+				}
 			}
-			if err := validatePlatform(img, desc.Platform); err != nil {
-				errs = append(errs, fmt.Sprintf("failed to validate image platform[%d](%s): %v", i, desc.Digest, err))
+			if o.enabled(CheckPlatform) {
+				if err := validatePlatform(img, desc.Platform); err != nil {
+					r.add(CheckPlatform, subject, fmt.Sprintf("failed to validate image platform[%d](%s): %v", i, desc.Digest, err))
+				}
 			}
 		default:
 			// Workaround for #819.
@@ -89,13 +115,14 @@ func validateChildren(idx v1.ImageIndex, opt ...Option) error {
 				if err != nil {
 					return fmt.Errorf("failed to get layer Manifests[%d]: %w", i, err)
 				}
-				if err := Layer(layer, opt...); err != nil {
-					lerr := fmt.Sprintf("failed to validate layer Manifests[%d](%s): %v", i, desc.Digest, err)
-					if desc.MediaType.IsDistributable() {
-						errs = append(errs, lerr)
-					} else {
-						logs.Warn.Printf("nondistributable layer failure: %v", lerr)
-					}
+				child := &Report{}
+				if err := validateLayer(layer, o, child); err != nil {
+					errs = append(errs, fmt.Sprintf("failed to validate layer %s: %v", subject, err))
+				}
+				if desc.MediaType.IsDistributable() {
+					r.merge(subject, "failed to validate layer "+subject+": ", child)
+				} else if len(child.Failures) != 0 {
+					logs.Warn.Printf("nondistributable layer failure: %s: %v", subject, child)
 				}
 			} else {
 				logs.Warn.Printf("Unexpected manifest: %s", desc.MediaType)
@@ -114,19 +141,19 @@ type withMediaType interface {
 	MediaType() (types.MediaType, error)
 }
 
-func validateMediaType(i withMediaType, want types.MediaType) error {
+func validateMediaType(i withMediaType, want types.MediaType, subject, prefix string, r *Report) error {
 	got, err := i.MediaType()
 	if err != nil {
 		return err
 	}
 	if want != got {
-		return fmt.Errorf("mismatched mediaType: MediaType() = %v != %v", got, want)
+		r.add(CheckMediaType, subject, fmt.Sprintf("%s: mismatched mediaType: MediaType() = %v != %v", prefix, got, want))
 	}
 
 	return nil
 }
 
-func validateIndexManifest(idx v1.ImageIndex) error {
+func validateIndexManifest(idx v1.ImageIndex, o *options, r *Report) error {
 	digest, err := idx.Digest()
 	if err != nil {
 		return err
@@ -157,21 +184,18 @@ func validateIndexManifest(idx v1.ImageIndex) error {
 		return err
 	}
 
-	errs := []string{}
-	if digest != hash {
-		errs = append(errs, fmt.Sprintf("mismatched manifest digest: Digest()=%s, SHA256(RawManifest())=%s", digest, hash))
-	}
-
-	if diff := cmp.Diff(pm, m); diff != "" {
-		errs = append(errs, fmt.Sprintf("mismatched manifest content: (-ParseIndexManifest(RawManifest()) +Manifest()) %s", diff))
+	if o.enabled(CheckDigest) && digest != hash {
+		r.add(CheckDigest, "manifest", fmt.Sprintf("mismatched manifest digest: Digest()=%s, SHA256(RawManifest())=%s", digest, hash))
 	}
 
-	if size != int64(len(rm)) {
-		errs = append(errs, fmt.Sprintf("mismatched manifest size: Size()=%d, len(RawManifest())=%d", size, len(rm)))
+	if o.enabled(CheckContent) {
+		if diff := cmp.Diff(pm, m); diff != "" {
+			r.add(CheckContent, "manifest", fmt.Sprintf("mismatched manifest content: (-ParseIndexManifest(RawManifest()) +Manifest()) %s", diff))
+		}
 	}
 
-	if len(errs) != 0 {
-		return errors.New(strings.Join(errs, "\n"))
+	if o.enabled(CheckSize) && size != int64(len(rm)) {
+		r.add(CheckSize, "manifest", fmt.Sprintf("mismatched manifest size: Size()=%d, len(RawManifest())=%d", size, len(rm)))
 	}
 
 	return nil
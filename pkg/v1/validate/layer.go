@@ -17,12 +17,10 @@ package validate
 import (
 	"archive/tar"
 	"compress/gzip"
-	"crypto"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
-	"strings"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/partial"
@@ -30,26 +28,37 @@ import (
 
 // Layer validates that the values return by its methods are consistent with the
 // contents returned by Compressed and Uncompressed.
+//
+// If any checks fail, the returned error is a *Report listing every failure.
 func Layer(layer v1.Layer, opt ...Option) error {
 	o := makeOptions(opt...)
+	r := &Report{}
+	if err := validateLayer(layer, &o, r); err != nil {
+		return err
+	}
+	return r.result()
+}
+
+func validateLayer(layer v1.Layer, o *options, r *Report) error {
 	if o.fast {
+		if !o.enabled(CheckExists) {
+			return nil
+		}
 		ok, err := partial.Exists(layer)
 		if err != nil {
 			return err
 		}
 		if !ok {
-			return fmt.Errorf("layer does not exist")
+			r.addf(CheckExists, "", "layer does not exist")
 		}
 		return nil
 	}
 
-	cl, err := computeLayer(layer)
+	cl, err := computeLayer(layer, o.enabled(CheckUncompressed))
 	if err != nil {
 		return err
 	}
 
-	errs := []string{}
-
 	digest, err := layer.Digest()
 	if err != nil {
 		return err
@@ -63,24 +72,20 @@ func Layer(layer v1.Layer, opt ...Option) error {
 		return err
 	}
 
-	if digest != cl.digest {
-		errs = append(errs, fmt.Sprintf("mismatched digest: Digest()=%s, SHA256(Compressed())=%s", digest, cl.digest))
-	}
-
-	if diffid != cl.diffid {
-		errs = append(errs, fmt.Sprintf("mismatched diffid: DiffID()=%s, SHA256(Gunzip(Compressed()))=%s", diffid, cl.diffid))
+	if o.enabled(CheckDigest) && digest != cl.digest {
+		r.addf(CheckDigest, "", "mismatched digest: Digest()=%s, SHA256(Compressed())=%s", digest, cl.digest)
 	}
 
-	if diffid != cl.uncompressedDiffid {
-		errs = append(errs, fmt.Sprintf("mismatched diffid: DiffID()=%s, SHA256(Uncompressed())=%s", diffid, cl.uncompressedDiffid))
+	if o.enabled(CheckDiffID) && diffid != cl.diffid {
+		r.addf(CheckDiffID, "", "mismatched diffid: DiffID()=%s, SHA256(Gunzip(Compressed()))=%s", diffid, cl.diffid)
 	}
 
-	if size != cl.size {
-		errs = append(errs, fmt.Sprintf("mismatched size: Size()=%d, len(Compressed())=%d", size, cl.size))
+	if o.enabled(CheckUncompressed) && diffid != cl.uncompressedDiffid {
+		r.addf(CheckUncompressed, "", "mismatched diffid: DiffID()=%s, SHA256(Uncompressed())=%s", diffid, cl.uncompressedDiffid)
 	}
 
-	if len(errs) != 0 {
-		return errors.New(strings.Join(errs, "\n"))
+	if o.enabled(CheckSize) && size != cl.size {
+		r.addf(CheckSize, "", "mismatched size: Size()=%d, len(Compressed())=%d", size, cl.size)
 	}
 
 	return nil
@@ -97,14 +102,19 @@ type computedLayer struct {
 	uncompressedSize   int64
 }
 
-func computeLayer(layer v1.Layer) (*computedLayer, error) {
+// computeLayer digests the layer's Compressed stream and, if
+// withUncompressed is true, its Uncompressed stream.
+func computeLayer(layer v1.Layer, withUncompressed bool) (*computedLayer, error) {
 	compressed, err := layer.Compressed()
 	if err != nil {
 		return nil, err
 	}
 
 	// Keep track of compressed digest.
-	digester := crypto.SHA256.New()
+	digester, err := v1.Hasher("sha256")
+	if err != nil {
+		return nil, err
+	}
 	// Everything read from compressed is written to digester to compute digest.
 	hashCompressed := io.TeeReader(compressed, digester)
 
@@ -132,7 +142,10 @@ func computeLayer(layer v1.Layer) (*computedLayer, error) {
 	if err != nil {
 		return nil, err
 	}
-	diffider := crypto.SHA256.New()
+	diffider, err := v1.Hasher("sha256")
+	if err != nil {
+		return nil, err
+	}
 	hashUncompressed := io.TeeReader(uncompressed, diffider)
 
 	// Ensure there aren't duplicate file paths.
@@ -171,21 +184,24 @@ func computeLayer(layer v1.Layer) (*computedLayer, error) {
 		Hex:       hex.EncodeToString(diffider.Sum(make([]byte, 0, diffider.Size()))),
 	}
 
+	cl := &computedLayer{
+		digest: digest,
+		diffid: diffid,
+		size:   size,
+	}
+	if !withUncompressed {
+		return cl, nil
+	}
+
 	ur, err := layer.Uncompressed()
 	if err != nil {
 		return nil, err
 	}
 	defer ur.Close()
-	udiffid, usize, err := v1.SHA256(ur)
+	cl.uncompressedDiffid, cl.uncompressedSize, err = v1.SHA256(ur)
 	if err != nil {
 		return nil, err
 	}
 
-	return &computedLayer{
-		digest:             digest,
-		diffid:             diffid,
-		size:               size,
-		uncompressedDiffid: udiffid,
-		uncompressedSize:   usize,
-	}, nil
+	return cl, nil
 }
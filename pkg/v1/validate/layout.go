@@ -0,0 +1,241 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// This intentionally doesn't use pkg/v1/layout, whose tests depend on this
+// package; the layout format is simple enough to read directly.
+
+const imageLayoutVersion = "1.0.0"
+
+// Layout validates that the OCI image layout at path does not violate any
+// invariants of the image-layout spec: oci-layout must declare a supported
+// version, index.json must parse, and every blob reachable from index.json
+// must exist with the size and digest its descriptor claims.
+//
+// With Deep, every image and index referenced by index.json is also validated
+// as with Image and Index. With Fast, blobs are checked for existence and
+// size but not digested.
+//
+// If any checks fail, the returned error is a *Report listing every failure.
+func Layout(path string, opt ...Option) error {
+	o := makeOptions(opt...)
+	r := &Report{}
+	if err := validateLayout(path, &o, r); err != nil {
+		return err
+	}
+	return r.result()
+}
+
+func validateLayout(path string, o *options, r *Report) error {
+	if err := validateLayoutFile(path, o, r); err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(filepath.Join(path, "index.json"))
+	if err != nil {
+		return err
+	}
+	idx, err := v1.ParseIndexManifest(bytes.NewReader(b))
+	if err != nil {
+		if o.enabled(CheckContent) {
+			r.addf(CheckContent, "index.json", "invalid index: %v", err)
+		}
+		return nil
+	}
+	if o.enabled(CheckMediaType) && idx.MediaType != "" && idx.MediaType != types.OCIImageIndex {
+		r.addf(CheckMediaType, "index.json", "unexpected mediaType: %s != %s", idx.MediaType, types.OCIImageIndex)
+	}
+
+	lb := layoutBlobs(path)
+	seen := map[v1.Hash]struct{}{}
+	for i, desc := range idx.Manifests {
+		subject := fmt.Sprintf("index.json Manifests[%d](%s)", i, desc.Digest)
+
+		// Only validate the image deeply if all of its blobs are present and
+		// intact, otherwise we'd just fail to read it.
+		before := len(r.Failures)
+		if err := lb.walk(desc, subject, o, r, seen); err != nil {
+			return err
+		}
+		if !o.deep || len(r.Failures) != before {
+			continue
+		}
+
+		child := &Report{}
+		switch {
+		case desc.MediaType.IsIndex():
+			ii, err := partial.IndexFromBlobs(lb, desc.Digest)
+			if err != nil {
+				return err
+			}
+			if err := validateIndex(ii, o, child); err != nil {
+				return fmt.Errorf("failed to validate index %s: %w", subject, err)
+			}
+		case desc.MediaType.IsImage():
+			img, err := partial.ImageFromBlobs(lb, desc.Digest)
+			if err != nil {
+				return err
+			}
+			if err := validateImage(img, o, child); err != nil {
+				return fmt.Errorf("failed to validate image %s: %w", subject, err)
+			}
+			if o.enabled(CheckPlatform) {
+				if err := validatePlatform(img, desc.Platform); err != nil {
+					r.addf(CheckPlatform, subject, "%v", err)
+				}
+			}
+		}
+		r.merge(subject, subject+": ", child)
+	}
+
+	return nil
+}
+
+func validateLayoutFile(path string, o *options, r *Report) error {
+	if !o.enabled(CheckLayout) {
+		return nil
+	}
+
+	b, err := os.ReadFile(filepath.Join(path, "oci-layout"))
+	if errors.Is(err, fs.ErrNotExist) {
+		r.addf(CheckLayout, "oci-layout", "missing oci-layout file")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var lf struct {
+		ImageLayoutVersion string `json:"imageLayoutVersion"`
+	}
+	if err := json.Unmarshal(b, &lf); err != nil {
+		r.addf(CheckLayout, "oci-layout", "invalid oci-layout: %v", err)
+		return nil
+	}
+	if lf.ImageLayoutVersion != imageLayoutVersion {
+		r.addf(CheckLayout, "oci-layout", "unsupported imageLayoutVersion: %q != %q", lf.ImageLayoutVersion, imageLayoutVersion)
+	}
+
+	return nil
+}
+
+// layoutBlobs implements partial.BlobProvider for the layout at its path.
+type layoutBlobs string
+
+func (l layoutBlobs) blobPath(h v1.Hash) string {
+	return filepath.Join(string(l), "blobs", h.Algorithm, h.Hex)
+}
+
+// Get implements partial.BlobProvider.
+func (l layoutBlobs) Get(h v1.Hash) (io.ReadCloser, error) {
+	return os.Open(l.blobPath(h))
+}
+
+// walk checks the blob that desc describes, then everything it references if
+// it is a manifest.
+func (l layoutBlobs) walk(desc v1.Descriptor, subject string, o *options, r *Report, seen map[v1.Hash]struct{}) error {
+	if _, ok := seen[desc.Digest]; ok {
+		return nil
+	}
+	seen[desc.Digest] = struct{}{}
+
+	isManifest := desc.MediaType.IsIndex() || desc.MediaType.IsImage()
+
+	fi, err := os.Stat(l.blobPath(desc.Digest))
+	if errors.Is(err, fs.ErrNotExist) {
+		if o.enabled(CheckExists) {
+			r.addf(CheckExists, subject, "missing blob %s", l.blobPath(desc.Digest))
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if o.enabled(CheckSize) && fi.Size() != desc.Size {
+		r.addf(CheckSize, subject, "mismatched size: Descriptor.Size=%d, len(blob)=%d", desc.Size, fi.Size())
+	}
+
+	// Manifests are small and we need to read them anyway, so only Fast
+	// skips digesting the other blobs.
+	if !isManifest && (o.fast || !o.enabled(CheckDigest)) {
+		return nil
+	}
+
+	b, err := os.ReadFile(l.blobPath(desc.Digest))
+	if err != nil {
+		return err
+	}
+	if o.enabled(CheckDigest) {
+		hash, _, err := v1.SHA256(bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		if hash != desc.Digest {
+			r.addf(CheckDigest, subject, "mismatched digest: Descriptor.Digest=%s, SHA256(blob)=%s", desc.Digest, hash)
+		}
+	}
+
+	switch {
+	case desc.MediaType.IsIndex():
+		im, err := v1.ParseIndexManifest(bytes.NewReader(b))
+		if err != nil {
+			if o.enabled(CheckContent) {
+				r.addf(CheckContent, subject, "invalid index: %v", err)
+			}
+			return nil
+		}
+		for i, child := range im.Manifests {
+			if err := l.walk(child, fmt.Sprintf("%s/Manifests[%d](%s)", subject, i, child.Digest), o, r, seen); err != nil {
+				return err
+			}
+		}
+	case desc.MediaType.IsImage():
+		m, err := v1.ParseManifest(bytes.NewReader(b))
+		if err != nil {
+			if o.enabled(CheckContent) {
+				r.addf(CheckContent, subject, "invalid manifest: %v", err)
+			}
+			return nil
+		}
+		if err := l.walk(m.Config, subject+"/config", o, r, seen); err != nil {
+			return err
+		}
+		for i, layer := range m.Layers {
+			// Foreign layers aren't expected to be in the layout.
+			if !layer.MediaType.IsDistributable() {
+				continue
+			}
+			if err := l.walk(layer, fmt.Sprintf("%s/layer[%d]", subject, i), o, r, seen); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
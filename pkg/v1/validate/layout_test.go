@@ -0,0 +1,77 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestLayout(t *testing.T) {
+	tmp := t.TempDir()
+	idx, err := random.Index(1024, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := layout.Write(tmp, mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: idx}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AppendImage(img); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Layout(tmp, Deep); err != nil {
+		t.Fatalf("Layout() = %v", err)
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := layers[0].Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(tmp, "blobs", h.Algorithm, h.Hex)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "oci-layout"), []byte(`{"imageLayoutVersion": "2.0.0"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = Layout(tmp, Deep)
+	var r *Report
+	if !errors.As(err, &r) {
+		t.Fatalf("Layout() = %v, want *Report", err)
+	}
+	if got := len(r.Failed(CheckExists)); got != 1 {
+		t.Errorf("len(Failed(CheckExists)) = %d, want 1: %v", got, r)
+	}
+	if got := len(r.Failed(CheckLayout)); got != 1 {
+		t.Errorf("len(Failed(CheckLayout)) = %d, want 1: %v", got, r)
+	}
+}
@@ -19,11 +19,19 @@ type Option func(*options)
 
 type options struct {
 	fast bool
+	deep bool
+
+	// only, if non-nil, restricts validation to the checks it contains.
+	only map[Check]struct{}
+	// skip contains checks that should not be performed.
+	skip map[Check]struct{}
 }
 
 func makeOptions(opts ...Option) options {
 	opt := options{
 		fast: false,
+		deep: false,
+		skip: map[Check]struct{}{},
 	}
 	for _, o := range opts {
 		o(&opt)
@@ -31,7 +39,47 @@ func makeOptions(opts ...Option) options {
 	return opt
 }
 
+// enabled returns true if the check c should be performed.
+func (o *options) enabled(c Check) bool {
+	if _, ok := o.skip[c]; ok {
+		return false
+	}
+	if o.only != nil {
+		_, ok := o.only[c]
+		return ok
+	}
+	return true
+}
+
 // Fast causes validate to skip reading and digesting layer bytes.
 func Fast(o *options) {
 	o.fast = true
 }
+
+// Deep causes Layout to also validate every image and index that the layout
+// references, as with Image and Index.
+func Deep(o *options) {
+	o.deep = true
+}
+
+// WithChecks restricts validation to only the given checks.
+func WithChecks(checks ...Check) Option {
+	return func(o *options) {
+		if o.only == nil {
+			o.only = map[Check]struct{}{}
+		}
+		for _, c := range checks {
+			o.only[c] = struct{}{}
+		}
+	}
+}
+
+// WithoutChecks disables the given checks, e.g. WithoutChecks(CheckUncompressed)
+// avoids reading each layer a second time through Uncompressed().
+func WithoutChecks(checks ...Check) Option {
+	return func(o *options) {
+		for _, c := range checks {
+			o.skip[c] = struct{}{}
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// mediaTypeRegexp matches the restricted-name form of RFC 6838, which the
+// image-spec requires of artifactType.
+var mediaTypeRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]{0,126}/[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]{0,126}$`)
+
+// Referrers validates that every manifest in idx refers to subject, has a
+// well-formed artifactType that matches its descriptor in idx, and that the
+// blobs it references exist.
+//
+// idx is a referrers index, e.g. as returned by remote.Referrers. To validate
+// the referrers stored in an OCI image layout, use LayoutReferrers.
+//
+// If any checks fail, the returned error is a *Report listing every failure.
+func Referrers(idx v1.ImageIndex, subject v1.Hash, opt ...Option) error {
+	o := makeOptions(opt...)
+	r := &Report{}
+
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return err
+	}
+
+	for i, desc := range im.Manifests {
+		sub := fmt.Sprintf("Manifests[%d](%s)", i, desc.Digest)
+
+		var (
+			rm     []byte
+			exists func(v1.Descriptor) (bool, error)
+		)
+		if desc.MediaType.IsIndex() {
+			ii, err := idx.ImageIndex(desc.Digest)
+			if err != nil {
+				return err
+			}
+			if rm, err = ii.RawManifest(); err != nil {
+				return err
+			}
+			exists = func(d v1.Descriptor) (bool, error) {
+				if d.MediaType.IsIndex() {
+					child, err := ii.ImageIndex(d.Digest)
+					if err != nil {
+						return false, err
+					}
+					_, err = child.RawManifest()
+					return err == nil, err
+				}
+				child, err := ii.Image(d.Digest)
+				if err != nil {
+					return false, err
+				}
+				_, err = child.RawManifest()
+				return err == nil, err
+			}
+		} else {
+			img, err := idx.Image(desc.Digest)
+			if err != nil {
+				return err
+			}
+			if rm, err = img.RawManifest(); err != nil {
+				return err
+			}
+			exists = func(d v1.Descriptor) (bool, error) {
+				l, err := img.LayerByDigest(d.Digest)
+				if err != nil {
+					return false, err
+				}
+				return partial.Exists(l)
+			}
+		}
+
+		validateReferrer(desc, rm, subject, exists, sub, &o, r)
+	}
+
+	return r.result()
+}
+
+// LayoutReferrers validates the manifests in the index.json of the OCI image
+// layout at path that refer to subject, as Referrers does.
+//
+// If any checks fail, the returned error is a *Report listing every failure.
+func LayoutReferrers(path string, subject v1.Hash, opt ...Option) error {
+	o := makeOptions(opt...)
+	r := &Report{}
+
+	b, err := os.ReadFile(filepath.Join(path, "index.json"))
+	if err != nil {
+		return err
+	}
+	im, err := v1.ParseIndexManifest(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	lb := layoutBlobs(path)
+	exists := func(d v1.Descriptor) (bool, error) {
+		_, err := os.Stat(lb.blobPath(d.Digest))
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	for i, desc := range im.Manifests {
+		if !desc.MediaType.IsImage() && !desc.MediaType.IsIndex() {
+			continue
+		}
+		rm, err := os.ReadFile(lb.blobPath(desc.Digest))
+		if errors.Is(err, fs.ErrNotExist) {
+			// Layout reports missing manifests; we can't know whether
+			// this one would have been a referrer.
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		var m struct {
+			Subject *v1.Descriptor `json:"subject,omitempty"`
+		}
+		if err := json.Unmarshal(rm, &m); err != nil {
+			return fmt.Errorf("parsing index.json Manifests[%d](%s): %w", i, desc.Digest, err)
+		}
+		if m.Subject == nil || m.Subject.Digest != subject {
+			continue
+		}
+
+		validateReferrer(desc, rm, subject, exists, fmt.Sprintf("index.json Manifests[%d](%s)", i, desc.Digest), &o, r)
+	}
+
+	return r.result()
+}
+
+// validateReferrer checks the raw manifest rm that desc describes as a
+// referrer of subject, using exists to check for the blobs it references.
+func validateReferrer(desc v1.Descriptor, rm []byte, subject v1.Hash, exists func(v1.Descriptor) (bool, error), sub string, o *options, r *Report) {
+	if o.enabled(CheckDigest) {
+		if hash, _, err := v1.SHA256(bytes.NewReader(rm)); err == nil && hash != desc.Digest {
+			r.addf(CheckDigest, sub, "mismatched digest: Descriptor.Digest=%s, SHA256(RawManifest())=%s", desc.Digest, hash)
+		}
+	}
+	if o.enabled(CheckSize) && desc.Size != int64(len(rm)) {
+		r.addf(CheckSize, sub, "mismatched size: Descriptor.Size=%d, len(RawManifest())=%d", desc.Size, len(rm))
+	}
+
+	var m struct {
+		MediaType    types.MediaType `json:"mediaType,omitempty"`
+		ArtifactType string          `json:"artifactType,omitempty"`
+		Config       *v1.Descriptor  `json:"config,omitempty"`
+		Layers       []v1.Descriptor `json:"layers,omitempty"`
+		Blobs        []v1.Descriptor `json:"blobs,omitempty"`
+		Manifests    []v1.Descriptor `json:"manifests,omitempty"`
+		Subject      *v1.Descriptor  `json:"subject,omitempty"`
+	}
+	if err := json.Unmarshal(rm, &m); err != nil {
+		if o.enabled(CheckContent) {
+			r.addf(CheckContent, sub, "invalid manifest: %v", err)
+		}
+		return
+	}
+
+	if o.enabled(CheckMediaType) && m.MediaType != "" && m.MediaType != desc.MediaType {
+		r.addf(CheckMediaType, sub, "mismatched mediaType: Descriptor.MediaType=%s, Manifest.MediaType=%s", desc.MediaType, m.MediaType)
+	}
+
+	if o.enabled(CheckSubject) {
+		if m.Subject == nil {
+			r.addf(CheckSubject, sub, "missing subject")
+		} else if m.Subject.Digest != subject {
+			r.addf(CheckSubject, sub, "mismatched subject: Manifest.Subject.Digest=%s, subject=%s", m.Subject.Digest, subject)
+		}
+	}
+
+	if o.enabled(CheckArtifactType) {
+		// Images without an artifactType use their config's mediaType.
+		artifactType := m.ArtifactType
+		if artifactType == "" && m.Config != nil {
+			if m.Config.MediaType == types.OCIEmptyJSON {
+				r.addf(CheckArtifactType, sub, "missing artifactType: required with config mediaType %s", types.OCIEmptyJSON)
+			}
+			artifactType = string(m.Config.MediaType)
+		}
+		if artifactType != "" && !mediaTypeRegexp.MatchString(artifactType) {
+			r.addf(CheckArtifactType, sub, "invalid artifactType: %q", artifactType)
+		}
+		if desc.ArtifactType != artifactType {
+			r.addf(CheckArtifactType, sub, "mismatched artifactType: Descriptor.ArtifactType=%q, Manifest=%q", desc.ArtifactType, artifactType)
+		}
+	}
+
+	if o.enabled(CheckExists) {
+		blobs := append(m.Manifests, m.Blobs...)
+		if m.Config != nil {
+			blobs = append([]v1.Descriptor{*m.Config}, m.Layers...)
+		}
+		for _, d := range blobs {
+			// Foreign layers aren't expected to be pushed alongside the artifact.
+			if !d.MediaType.IsDistributable() {
+				continue
+			}
+			ok, err := exists(d)
+			if err != nil {
+				r.addf(CheckExists, sub, "missing blob %s: %v", d.Digest, err)
+			} else if !ok {
+				r.addf(CheckExists, sub, "missing blob %s", d.Digest)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const sbomType = "application/vnd.example.sbom"
+
+func referrer(t *testing.T, subject v1.Image, artifactType types.MediaType) v1.Image {
+	t.Helper()
+	desc, err := partial.Descriptor(subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	art, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	art = mutate.ConfigMediaType(art, artifactType)
+	return mutate.Subject(art, *desc).(v1.Image)
+}
+
+func TestReferrers(t *testing.T) {
+	subject, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh, err := subject.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{
+		Add: referrer(t, subject, sbomType),
+	})
+	if err := Referrers(idx, sh); err != nil {
+		t.Fatalf("Referrers() = %v", err)
+	}
+
+	idx = mutate.AppendManifests(idx, mutate.IndexAddendum{
+		Add: referrer(t, other, "bogus"),
+	})
+	err = Referrers(idx, sh)
+	var r *Report
+	if !errors.As(err, &r) {
+		t.Fatalf("Referrers() = %v, want *Report", err)
+	}
+	if got := len(r.Failed(CheckSubject)); got != 1 {
+		t.Errorf("len(Failed(CheckSubject)) = %d, want 1: %v", got, r)
+	}
+	if got := len(r.Failed(CheckArtifactType)); got != 1 {
+		t.Errorf("len(Failed(CheckArtifactType)) = %d, want 1: %v", got, r)
+	}
+}
+
+func TestLayoutReferrers(t *testing.T) {
+	tmp := t.TempDir()
+	subject, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh, err := subject.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := layout.Write(tmp, empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AppendImage(subject); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AppendImage(referrer(t, subject, sbomType)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LayoutReferrers(tmp, sh); err != nil {
+		t.Fatalf("LayoutReferrers() = %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Check identifies a class of invariant that validate verifies.
+type Check string
+
+const (
+	// CheckDigest compares digests against the SHA256 of the content they describe.
+	CheckDigest Check = "digest"
+	// CheckDiffID compares layer DiffIDs against the SHA256 of the uncompressed layer.
+	CheckDiffID Check = "diffid"
+	// CheckUncompressed compares layer DiffIDs against the SHA256 of
+	// Uncompressed(), which requires reading each layer a second time.
+	CheckUncompressed Check = "uncompressed"
+	// CheckSize compares sizes against the length of the content they describe.
+	CheckSize Check = "size"
+	// CheckMediaType compares descriptor media types against the media type
+	// reported by the artifact.
+	CheckMediaType Check = "mediatype"
+	// CheckLayerOrder verifies that the manifest, config and Layers() agree on
+	// the number and order of layers.
+	CheckLayerOrder Check = "layer-order"
+	// CheckContent compares parsed structures against their raw bytes.
+	CheckContent Check = "content"
+	// CheckPlatform compares index descriptor platforms against the config file.
+	CheckPlatform Check = "platform"
+	// CheckExists verifies that layers exist; this is the only layer check
+	// performed with Fast.
+	CheckExists Check = "exists"
+	// CheckLayout verifies that an OCI image layout declares a supported
+	// imageLayoutVersion.
+	CheckLayout Check = "layout"
+	// CheckSubject verifies that referrers refer to the expected subject.
+	CheckSubject Check = "subject"
+	// CheckArtifactType verifies that a referrer's artifactType is a
+	// well-formed media type that matches its descriptor.
+	CheckArtifactType Check = "artifacttype"
+)
+
+// Failure describes a single failed check.
+type Failure struct {
+	// Check is the class of invariant that was violated.
+	Check Check
+	// Subject identifies what failed, e.g. "layer[0]", "config" or
+	// "Manifests[1](sha256:...)/manifest".
+	Subject string
+	// Message describes the mismatch, including what failed.
+	Message string
+}
+
+// Error implements error.
+func (f Failure) Error() string {
+	return f.Message
+}
+
+// Report lists every check that failed during validation.
+//
+// Image, Index and Layer return a *Report as their error when the artifact
+// violates one or more invariants; use errors.As to retrieve it.
+type Report struct {
+	Failures []Failure
+}
+
+// Error implements error.
+func (r *Report) Error() string {
+	lines := make([]string, 0, len(r.Failures))
+	for _, f := range r.Failures {
+		lines = append(lines, f.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Failed returns the failures for the given check.
+func (r *Report) Failed(c Check) []Failure {
+	var fs []Failure
+	for _, f := range r.Failures {
+		if f.Check == c {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// addf records a failure of c, prefixing its message with subject.
+func (r *Report) addf(c Check, subject, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if subject != "" {
+		msg = subject + ": " + msg
+	}
+	r.add(c, subject, msg)
+}
+
+// add records a failure of c with msg as is, for messages that already say
+// what failed.
+func (r *Report) add(c Check, subject, msg string) {
+	r.Failures = append(r.Failures, Failure{
+		Check:   c,
+		Subject: subject,
+		Message: msg,
+	})
+}
+
+// merge adds the failures of child to r, nesting their subjects under
+// subject, if set, and prefixing their messages with prefix.
+func (r *Report) merge(subject, prefix string, child *Report) {
+	for _, f := range child.Failures {
+		if subject != "" {
+			if f.Subject != "" {
+				f.Subject = subject + "/" + f.Subject
+			} else {
+				f.Subject = subject
+			}
+		}
+		f.Message = prefix + f.Message
+		r.Failures = append(r.Failures, f)
+	}
+}
+
+// result returns r as an error if any checks failed.
+func (r *Report) result() error {
+	if len(r.Failures) == 0 {
+		return nil
+	}
+	return r
+}
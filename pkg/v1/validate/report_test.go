@@ -0,0 +1,118 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+type badSize struct {
+	v1.Layer
+}
+
+func (badSize) Size() (int64, error) {
+	return 1, nil
+}
+
+func TestLayerReport(t *testing.T) {
+	l, err := random.Layer(1024, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Layer(l); err != nil {
+		t.Fatalf("Layer(valid) = %v", err)
+	}
+
+	err = Layer(badSize{l})
+	var r *Report
+	if !errors.As(err, &r) {
+		t.Fatalf("Layer(badSize) = %v, want *Report", err)
+	}
+	if got := len(r.Failed(CheckSize)); got != 1 {
+		t.Errorf("len(Failed(CheckSize)) = %d, want 1: %v", got, r)
+	}
+	if got := len(r.Failures); got != 1 {
+		t.Errorf("len(Failures) = %d, want 1: %v", got, r)
+	}
+
+	if err := Layer(badSize{l}, WithoutChecks(CheckSize)); err != nil {
+		t.Errorf("Layer(badSize, WithoutChecks(CheckSize)) = %v", err)
+	}
+	if err := Layer(badSize{l}, WithChecks(CheckDigest, CheckDiffID)); err != nil {
+		t.Errorf("Layer(badSize, WithChecks(CheckDigest, CheckDiffID)) = %v", err)
+	}
+}
+
+func TestIndexReport(t *testing.T) {
+	idx, err := random.Index(1024, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Index(idx, WithoutChecks(CheckUncompressed)); err != nil {
+		t.Errorf("Index() = %v", err)
+	}
+}
+
+// extraLayer is an image whose Layers() has one more layer than its manifest
+// and config, and whose first layer has the wrong size.
+type extraLayer struct {
+	v1.Image
+	extra v1.Layer
+}
+
+func (i extraLayer) Layers() ([]v1.Layer, error) {
+	ls, err := i.Image.Layers()
+	if err != nil {
+		return nil, err
+	}
+	return append([]v1.Layer{badSize{ls[0]}}, append(ls[1:], i.extra)...), nil
+}
+
+func (extraLayer) LayerByDigest(v1.Hash) (v1.Layer, error) { return nil, nil }
+func (extraLayer) LayerByDiffID(v1.Hash) (v1.Layer, error) { return nil, nil }
+
+func TestImageReportLayerCount(t *testing.T) {
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extra, err := random.Layer(1024, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Image(extraLayer{img, extra}, WithoutChecks(CheckUncompressed))
+	var r *Report
+	if !errors.As(err, &r) {
+		t.Fatalf("Image(extraLayer) = %v, want *Report", err)
+	}
+	if got := len(r.Failed(CheckLayerOrder)); got != 1 {
+		t.Errorf("len(Failed(CheckLayerOrder)) = %d, want 1: %v", got, r)
+	}
+	// The layers that can be compared are still checked after the mismatch.
+	size := r.Failed(CheckSize)
+	if len(size) != 1 {
+		t.Fatalf("len(Failed(CheckSize)) = %d, want 1: %v", len(size), r)
+	}
+	if want := "validating layers: mismatched layer[0] size: Size()=1, len(Compressed())="; !strings.HasPrefix(size[0].Error(), want) {
+		t.Errorf("Failed(CheckSize)[0] = %q, want prefix %q", size[0].Error(), want)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xdiff
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	opaqueWhiteout = ".wh..wh..opq"
+)
+
+// File describes an entry in an image's flattened filesystem.
+type File struct {
+	Typeflag byte   `json:"typeflag"`
+	Mode     int64  `json:"mode"`
+	UID      int    `json:"uid"`
+	GID      int    `json:"gid"`
+	Size     int64  `json:"size"`
+	Linkname string `json:"linkname,omitempty"`
+
+	// Digest is the digest of the contents of a regular file.
+	Digest v1.Hash `json:"digest,omitempty"`
+}
+
+// FileChange is a file that differs between the flattened filesystems.
+type FileChange struct {
+	Kind Kind   `json:"kind"`
+	Path string `json:"path"`
+
+	// Old is nil if the file was added, and New is nil if it was removed.
+	Old *File `json:"old,omitempty"`
+	New *File `json:"new,omitempty"`
+}
+
+func diffFiles(a, b v1.Image) ([]FileChange, error) {
+	af, err := flatten(a)
+	if err != nil {
+		return nil, err
+	}
+	bf, err := flatten(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FileChange
+	for p, old := range af {
+		switch new, ok := bf[p]; {
+		case !ok:
+			changes = append(changes, FileChange{Kind: Removed, Path: p, Old: old})
+		case *old != *new:
+			changes = append(changes, FileChange{Kind: Modified, Path: p, Old: old, New: new})
+		}
+	}
+	for p, new := range bf {
+		if _, ok := af[p]; !ok {
+			changes = append(changes, FileChange{Kind: Added, Path: p, New: new})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// flatten returns the files in img's filesystem by path, applying whiteouts
+// the same way mutate.Extract does.
+func flatten(img v1.Image) (map[string]*File, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving image layers: %w", err)
+	}
+
+	files := map[string]*File{}
+
+	// hidden holds the paths whose lower-layer entries, and their children,
+	// are shadowed by files, whiteouts and opaque directories. Like
+	// mutate.Extract, we walk the layers from the top down.
+	hidden := map[string]bool{}
+	for i := len(layers) - 1; i >= 0; i-- {
+		// Whiteouts in a layer only hide entries in the layers below it.
+		shadowed, err := flattenLayer(layers[i], files, hidden)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range shadowed {
+			hidden[p] = true
+		}
+	}
+	return files, nil
+}
+
+// flattenLayer adds the entries of l that aren't hidden to files, and returns
+// the paths that l hides from the layers below it.
+func flattenLayer(l v1.Layer, files map[string]*File, hidden map[string]bool) ([]string, error) {
+	rc, err := l.Uncompressed()
+	if err != nil {
+		return nil, fmt.Errorf("reading layer contents: %w", err)
+	}
+	defer rc.Close()
+
+	var shadowed []string
+	tr := tar.NewReader(rc)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return shadowed, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading tar: %w", err)
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if name == "" {
+			continue
+		}
+		dir, base := path.Split(name)
+		dir = strings.TrimSuffix(dir, "/")
+		if isHidden(hidden, name) {
+			continue
+		}
+
+		switch {
+		case base == opaqueWhiteout:
+			// An opaque directory hides its lower contents, but not itself.
+			shadowed = append(shadowed, dir+"/")
+			continue
+		case strings.HasPrefix(base, whiteoutPrefix):
+			shadowed = append(shadowed, path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)))
+			continue
+		}
+
+		if _, ok := files[name]; ok {
+			continue
+		}
+		f := &File{
+			Typeflag: header.Typeflag,
+			Mode:     header.Mode,
+			UID:      header.Uid,
+			GID:      header.Gid,
+			Size:     header.Size,
+			Linkname: header.Linkname,
+		}
+		if header.Typeflag == tar.TypeReg {
+			if f.Digest, _, err = v1.SHA256(tr); err != nil {
+				return nil, fmt.Errorf("reading %s: %w", name, err)
+			}
+		}
+		files[name] = f
+		if header.Typeflag != tar.TypeDir {
+			// A non-directory replaces anything below it in lower layers.
+			shadowed = append(shadowed, name)
+		}
+	}
+}
+
+// isHidden reports whether name or any of its parents is hidden, or it is
+// inside an opaque directory, which is recorded with a trailing slash.
+func isHidden(hidden map[string]bool, name string) bool {
+	if hidden[name] {
+		return true
+	}
+	for p := path.Dir(name); p != "."; p = path.Dir(p) {
+		if hidden[p] || hidden[p+"/"] {
+			return true
+		}
+	}
+	return false
+}
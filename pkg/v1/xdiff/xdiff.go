@@ -0,0 +1,256 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Kind is the kind of a change.
+type Kind string
+
+// The kinds of change.
+const (
+	Added    Kind = "added"
+	Removed  Kind = "removed"
+	Modified Kind = "modified"
+)
+
+// Diff is the difference between two images.
+type Diff struct {
+	// Config lists the config file fields that differ, sorted by Field.
+	Config []ConfigChange `json:"config,omitempty"`
+
+	// Layers lists the layers, by diffID, that are only in one image, in the
+	// order they appear in that image.
+	Layers []LayerChange `json:"layers,omitempty"`
+
+	// Files lists the files that differ in the flattened filesystems, sorted
+	// by Path. It's empty if WithoutFiles is passed.
+	Files []FileChange `json:"files,omitempty"`
+}
+
+// Empty reports whether the images are equivalent as far as the diff goes.
+func (d *Diff) Empty() bool {
+	return len(d.Config) == 0 && len(d.Layers) == 0 && len(d.Files) == 0
+}
+
+// ConfigChange is a config file field that differs.
+type ConfigChange struct {
+	Kind Kind `json:"kind"`
+
+	// Field is the path to the field in the config file's JSON, e.g.
+	// "config.Env" or "architecture".
+	Field string `json:"field"`
+
+	// Old and New are the JSON values of the field; Old is nil if the field
+	// was added, and New is nil if it was removed.
+	Old any `json:"old,omitempty"`
+	New any `json:"new,omitempty"`
+}
+
+// LayerChange is a layer that is only in one of the images.
+type LayerChange struct {
+	Kind Kind `json:"kind"`
+
+	Digest v1.Hash `json:"digest"`
+	DiffID v1.Hash `json:"diffID"`
+	Size   int64   `json:"size"`
+}
+
+// Option is a functional option for Images.
+type Option func(*options)
+
+type options struct {
+	files bool
+}
+
+// WithoutFiles is a functional option that skips diffing the flattened
+// filesystems of the images, which means reading every layer of both.
+func WithoutFiles() Option {
+	return func(o *options) {
+		o.files = false
+	}
+}
+
+// Images returns the difference between images a and b.
+func Images(a, b v1.Image, opts ...Option) (*Diff, error) {
+	o := &options{files: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	d := &Diff{}
+	ad, err := a.Digest()
+	if err != nil {
+		return nil, err
+	}
+	bd, err := b.Digest()
+	if err != nil {
+		return nil, err
+	}
+	if ad == bd {
+		return d, nil
+	}
+
+	if d.Config, err = diffConfigs(a, b); err != nil {
+		return nil, err
+	}
+	if d.Layers, err = diffLayers(a, b); err != nil {
+		return nil, err
+	}
+	if o.files {
+		if d.Files, err = diffFiles(a, b); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+func diffConfigs(a, b v1.Image) ([]ConfigChange, error) {
+	ac, err := configMap(a)
+	if err != nil {
+		return nil, err
+	}
+	bc, err := configMap(b)
+	if err != nil {
+		return nil, err
+	}
+	// The layers are diffed separately.
+	delete(ac, "rootfs")
+	delete(bc, "rootfs")
+
+	var changes []ConfigChange
+	diffValues("", ac, bc, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
+
+// configMap returns the generic JSON form of img's config file, so that any
+// field, including ones v1.ConfigFile doesn't know about, can be diffed.
+func configMap(img v1.Image) (map[string]any, error) {
+	b, err := img.RawConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]any{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("parsing config file: %w", err)
+	}
+	return m, nil
+}
+
+// diffValues appends the differences between the JSON values a and b at field
+// to changes, recursing into objects so that each differing leaf is reported
+// by its own path.
+func diffValues(field string, a, b any, changes *[]ConfigChange) {
+	am, aok := a.(map[string]any)
+	bm, bok := b.(map[string]any)
+	if aok && bok {
+		for k, av := range am {
+			diffValues(join(field, k), av, bm[k], changes)
+		}
+		for k, bv := range bm {
+			if _, ok := am[k]; !ok {
+				diffValues(join(field, k), nil, bv, changes)
+			}
+		}
+		return
+	}
+
+	switch {
+	case reflect.DeepEqual(a, b):
+	case a == nil:
+		*changes = append(*changes, ConfigChange{Kind: Added, Field: field, New: b})
+	case b == nil:
+		*changes = append(*changes, ConfigChange{Kind: Removed, Field: field, Old: a})
+	default:
+		*changes = append(*changes, ConfigChange{Kind: Modified, Field: field, Old: a, New: b})
+	}
+}
+
+func join(field, key string) string {
+	if field == "" {
+		return key
+	}
+	return field + "." + key
+}
+
+// diffLayers returns the layers of a that aren't in b, then the layers of b
+// that aren't in a, matching layers by diffID and counting duplicates.
+func diffLayers(a, b v1.Image) ([]LayerChange, error) {
+	al, err := a.Layers()
+	if err != nil {
+		return nil, err
+	}
+	bl, err := b.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []LayerChange
+	removed, err := subtractLayers(al, bl, Removed)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, removed...)
+	added, err := subtractLayers(bl, al, Added)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, added...), nil
+}
+
+// subtractLayers returns the layers in minuend that aren't in subtrahend as
+// changes of the given kind.
+func subtractLayers(minuend, subtrahend []v1.Layer, kind Kind) ([]LayerChange, error) {
+	have := map[v1.Hash]int{}
+	for _, l := range subtrahend {
+		diffID, err := l.DiffID()
+		if err != nil {
+			return nil, err
+		}
+		have[diffID]++
+	}
+
+	var changes []LayerChange
+	for _, l := range minuend {
+		diffID, err := l.DiffID()
+		if err != nil {
+			return nil, err
+		}
+		if have[diffID] > 0 {
+			have[diffID]--
+			continue
+		}
+		digest, err := l.Digest()
+		if err != nil {
+			return nil, err
+		}
+		size, err := l.Size()
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, LayerChange{Kind: kind, Digest: digest, DiffID: diffID, Size: size})
+	}
+	return changes, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xdiff
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// layer returns a layer containing the given files; entries ending in "/"
+// are directories.
+func layer(t *testing.T, files map[string]string) v1.Layer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, contents := range files {
+		hdr := &tar.Header{Name: name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(contents))}
+		if name[len(name)-1] == '/' {
+			hdr = &tar.Header{Name: name, Mode: 0755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func image(t *testing.T, cfg v1.Config, layers ...v1.Layer) v1.Image {
+	t.Helper()
+	img, err := mutate.AppendLayers(empty.Image, layers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err = mutate.Config(img, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestImages(t *testing.T) {
+	base := layer(t, map[string]string{
+		"etc/":         "",
+		"etc/os":       "debian",
+		"etc/passwd":   "root",
+		"opt/":         "",
+		"opt/a":        "a",
+		"opt/b":        "b",
+		"tmp/":         "",
+		"tmp/scratch":  "x",
+		"usr/":         "",
+		"usr/bin/":     "",
+		"usr/bin/tool": "v1",
+	})
+	app := layer(t, map[string]string{"app": "hello"})
+	a := image(t, v1.Config{Env: []string{"A=1"}, User: "root"}, base, app)
+
+	upgrade := layer(t, map[string]string{
+		"usr/bin/tool":     "v2",
+		"etc/.wh.passwd":   "",
+		"tmp/.wh.scratch":  "",
+		"opt/.wh..wh..opq": "",
+		"opt/c":            "c",
+		"srv/":             "",
+	})
+	b := image(t, v1.Config{Env: []string{"A=1", "B=2"}, WorkingDir: "/srv"}, base, upgrade)
+
+	d, err := Images(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]ConfigChange{{
+		Kind:  Modified,
+		Field: "config.Env",
+		Old:   []any{"A=1"},
+		New:   []any{"A=1", "B=2"},
+	}, {
+		Kind:  Removed,
+		Field: "config.User",
+		Old:   "root",
+	}, {
+		Kind:  Added,
+		Field: "config.WorkingDir",
+		New:   "/srv",
+	}}, d.Config); diff != "" {
+		t.Errorf("Config (-want +got):\n%s", diff)
+	}
+
+	var layers []Kind
+	for _, l := range d.Layers {
+		layers = append(layers, l.Kind)
+	}
+	if diff := cmp.Diff([]Kind{Removed, Added}, layers); diff != "" {
+		t.Errorf("Layers (-want +got):\n%s", diff)
+	}
+	if want, _ := app.DiffID(); d.Layers[0].DiffID != want {
+		t.Errorf("removed layer: got %s, want %s", d.Layers[0].DiffID, want)
+	}
+
+	var files []string
+	for _, f := range d.Files {
+		files = append(files, string(f.Kind)+" "+f.Path)
+	}
+	if diff := cmp.Diff([]string{
+		"removed app",
+		"removed etc/passwd",
+		"removed opt/a",
+		"removed opt/b",
+		"added opt/c",
+		"added srv",
+		"removed tmp/scratch",
+		"modified usr/bin/tool",
+	}, files); diff != "" {
+		t.Errorf("Files (-want +got):\n%s", diff)
+	}
+
+	d, err = Images(a, b, WithoutFiles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Files) != 0 {
+		t.Errorf("WithoutFiles: got %d file changes", len(d.Files))
+	}
+
+	d, err = Images(a, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Empty() {
+		t.Errorf("Images(a, a) is not empty: %+v", d)
+	}
+}